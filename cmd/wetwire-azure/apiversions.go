@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/internal/template"
+	"github.com/spf13/cobra"
+)
+
+// apiVersionEntry is one row of the api-versions report
+type apiVersionEntry struct {
+	resource string
+	typ      string
+	version  string
+	explicit bool // set with APIVersion in the source, rather than defaulted
+	outdated bool // older than the floor, or not a valid API version
+}
+
+// newAPIVersionsCmd creates the "api-versions" subcommand, which reports the
+// API version each resource builds with.
+func newAPIVersionsCmd() *cobra.Command {
+	var floor string
+
+	cmd := &cobra.Command{
+		Use:   "api-versions [path]",
+		Short: "Report the API version of each resource",
+		Long: `API-versions lists each discovered resource with its type and the API version
+it builds with: the explicit APIVersion set in the source, or the default for
+its type. Resources whose API version is older than --floor are flagged.
+
+This is a report for periodic API-currency audits; use build --min-api-version
+to reject old explicit versions.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runAPIVersions(cmd.OutOrStdout(), path, floor)
+		},
+	}
+
+	cmd.Flags().StringVar(&floor, "floor", template.RecommendedMinAPIVersion,
+		"Flag resources whose API version is older than this (YYYY-MM-DD)")
+	return cmd
+}
+
+// runAPIVersions discovers the resources in path and writes the API version
+// report to w
+func runAPIVersions(w io.Writer, path, floor string) error {
+	if !template.IsAPIVersion(floor) {
+		return fmt.Errorf("invalid floor %q (expected YYYY-MM-DD)", floor)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	resources, err := discover.DiscoverResources(absPath)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+	if len(resources) == 0 {
+		return fmt.Errorf("no Azure resources found in %s", path)
+	}
+
+	entries := apiVersionEntries(resources, floor)
+	outdated := 0
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE\tTYPE\tAPI VERSION\tSOURCE")
+	for _, e := range entries {
+		source := "default"
+		if e.explicit {
+			source = "explicit"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s", e.resource, e.typ, e.version, source)
+		if e.outdated {
+			outdated++
+			fmt.Fprintf(tw, "\t! older than %s", floor)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if outdated > 0 {
+		fmt.Fprintf(w, "\n%d of %d resources use API versions older than %s\n", outdated, len(entries), floor)
+	} else {
+		fmt.Fprintf(w, "\nAll %d resources use API versions from %s or later\n", len(entries), floor)
+	}
+	return nil
+}
+
+// apiVersionEntries resolves the API version of each resource, flagging those
+// older than floor
+func apiVersionEntries(resources []discover.DiscoveredResource, floor string) []apiVersionEntry {
+	entries := make([]apiVersionEntry, len(resources))
+	for i, res := range resources {
+		e := apiVersionEntry{
+			resource: res.Name,
+			typ:      res.Type,
+			version:  res.APIVersion,
+			explicit: res.APIVersion != "",
+		}
+		if !e.explicit {
+			e.version = template.DefaultAPIVersion(res.Type)
+		}
+		e.outdated = !template.IsAPIVersion(e.version) || template.APIVersionBefore(e.version, floor)
+		entries[i] = e
+	}
+	return entries
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/internal/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apiVersionsSource = `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var MyStorage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+
+var LegacyVM = compute.VirtualMachine{
+	Name:       "legacy",
+	APIVersion: "2019-07-01",
+	Location:   "eastus",
+}
+`
+
+func TestAPIVersions_Report(t *testing.T) {
+	dir := writeProject(t, map[string]string{"main.go": apiVersionsSource})
+
+	var out bytes.Buffer
+	require.NoError(t, runAPIVersions(&out, dir, template.RecommendedMinAPIVersion))
+
+	lines := strings.Split(out.String(), "\n")
+	require.GreaterOrEqual(t, len(lines), 3, out.String())
+	assert.Equal(t, []string{"RESOURCE", "TYPE", "API", "VERSION", "SOURCE"}, strings.Fields(lines[0]))
+
+	// Defaulted versions come from the default API version table
+	assert.Equal(t, []string{
+		"MyStorage", "Microsoft.Storage/storageAccounts",
+		template.DefaultAPIVersion("Microsoft.Storage/storageAccounts"), "default",
+	}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{
+		"LegacyVM", "Microsoft.Compute/virtualMachines", "2019-07-01", "explicit",
+		"!", "older", "than", "2021-01-01",
+	}, strings.Fields(lines[2]))
+	assert.Contains(t, out.String(), "1 of 2 resources use API versions older than 2021-01-01")
+}
+
+func TestAPIVersions_Floor(t *testing.T) {
+	dir := writeProject(t, map[string]string{"main.go": apiVersionsSource})
+
+	var out bytes.Buffer
+	require.NoError(t, runAPIVersions(&out, dir, "2019-01-01"))
+	assert.NotContains(t, out.String(), "older than")
+	assert.Contains(t, out.String(), "All 2 resources use API versions from 2019-01-01 or later")
+
+	err := runAPIVersions(&out, dir, "2019")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid floor")
+}
+
+func TestAPIVersions_InvalidExplicitVersion(t *testing.T) {
+	entries := apiVersionEntries([]discover.DiscoveredResource{
+		{Name: "Odd", Type: "Microsoft.Storage/storageAccounts", APIVersion: "latest"},
+	}, template.RecommendedMinAPIVersion)
+
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].explicit)
+	assert.True(t, entries[0].outdated)
+}
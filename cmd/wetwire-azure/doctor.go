@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/spf13/cobra"
+)
+
+// wetwireModule is the module a project must require to declare resources
+const wetwireModule = "github.com/lex00/wetwire-azure-go"
+
+// checkStatus is the outcome of a doctor check
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkFail
+	checkSkip
+)
+
+// doctorCheck is the result of one doctor check
+type doctorCheck struct {
+	name   string
+	status checkStatus
+	detail string // what was found, shown on failure or skip
+	hint   string // how to fix a failure
+}
+
+// doctorOpts configures a doctor run.
+type doctorOpts struct {
+	// compile builds the Go packages under dir, returning the compiler output on failure
+	compile func(dir string) error
+}
+
+// newDoctorCmd creates the "doctor" subcommand for diagnosing project setup.
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor [path]",
+		Short: "Diagnose common project misconfigurations",
+		Long: `Doctor checks that a project is ready to build:
+
+  - go.mod requires github.com/lex00/wetwire-azure-go
+  - the Go packages compile
+  - at least one resource is discovered
+  - no two resources share a name
+  - referenced parameters and variables are declared
+
+Parameters are declared in an ARM parameters file (*.parameters.json) next to
+the sources. Each failed check is printed with a hint for fixing it.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runDoctor(cmd.OutOrStdout(), path, doctorOpts{compile: goBuild})
+		},
+	}
+}
+
+// runDoctor runs every check against path and prints a checklist to w. It
+// returns an error if any check failed.
+func runDoctor(w io.Writer, path string, opts doctorOpts) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+
+	modCheck := checkGoMod(absPath)
+	checks := []doctorCheck{modCheck}
+	if modCheck.status == checkPass {
+		checks = append(checks, checkCompiles(absPath, opts.compile))
+	} else {
+		checks = append(checks, doctorCheck{
+			name:   "Packages compile",
+			status: checkSkip,
+			detail: "skipped until go.mod is fixed",
+		})
+	}
+
+	resources, err := discover.DiscoverResources(absPath)
+	if err != nil {
+		checks = append(checks, doctorCheck{
+			name:   "Resources discovered",
+			status: checkFail,
+			detail: err.Error(),
+			hint:   "fix the syntax error above",
+		})
+	} else {
+		checks = append(checks, checkDiscovered(resources), checkDuplicateNames(resources))
+	}
+	checks = append(checks, checkReferences(absPath))
+
+	failed := 0
+	for _, c := range checks {
+		switch c.status {
+		case checkPass:
+			fmt.Fprintf(w, "✓ %s\n", c.name)
+		case checkFail:
+			failed++
+			fmt.Fprintf(w, "✗ %s\n", c.name)
+		case checkSkip:
+			fmt.Fprintf(w, "- %s\n", c.name)
+		}
+		if c.status != checkPass && c.detail != "" {
+			for _, line := range strings.Split(strings.TrimRight(c.detail, "\n"), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+		if c.status == checkFail && c.hint != "" {
+			fmt.Fprintf(w, "    hint: %s\n", c.hint)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	fmt.Fprintln(w, "\nNo problems found")
+	return nil
+}
+
+// checkGoMod finds the go.mod governing dir and checks that it requires the
+// wetwire-azure-go module.
+func checkGoMod(dir string) doctorCheck {
+	check := doctorCheck{name: "go.mod requires " + wetwireModule}
+
+	goModPath := findGoMod(dir)
+	if goModPath == "" {
+		check.status = checkFail
+		check.detail = "no go.mod found in " + dir + " or its parents"
+		check.hint = "run `go mod init <module>` and `go get " + wetwireModule + "`"
+		return check
+	}
+
+	module, requires, err := readGoMod(goModPath)
+	if err != nil {
+		check.status = checkFail
+		check.detail = err.Error()
+		check.hint = "fix the syntax of " + goModPath
+		return check
+	}
+	if module == wetwireModule || requires[wetwireModule] {
+		return check
+	}
+
+	check.status = checkFail
+	check.detail = goModPath + " does not require " + wetwireModule
+	check.hint = "run `go get " + wetwireModule + "`"
+	return check
+}
+
+// findGoMod returns the path of the go.mod in dir or its nearest parent, or ""
+func findGoMod(dir string) string {
+	for {
+		goModPath := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(goModPath); err == nil {
+			return goModPath
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readGoMod returns the module path and required modules of a go.mod file
+func readGoMod(path string) (string, map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var module string
+	requires := make(map[string]bool)
+	inRequire := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case inRequire && fields[0] == ")":
+			inRequire = false
+		case inRequire:
+			requires[unquoteModule(fields[0])] = true
+		case fields[0] == "module" && len(fields) > 1:
+			module = unquoteModule(fields[1])
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) > 1:
+			requires[unquoteModule(fields[1])] = true
+		}
+	}
+	if module == "" {
+		return "", nil, fmt.Errorf("%s has no module directive", path)
+	}
+	return module, requires, nil
+}
+
+// unquoteModule strips the quotes from a quoted module path
+func unquoteModule(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// checkCompiles checks that the Go packages under dir build
+func checkCompiles(dir string, compile func(string) error) doctorCheck {
+	check := doctorCheck{name: "Packages compile"}
+	if err := compile(dir); err != nil {
+		check.status = checkFail
+		check.detail = err.Error()
+		check.hint = "fix the compile errors above, then run `go mod tidy` if imports are missing"
+	}
+	return check
+}
+
+// goBuild builds the packages under dir, discarding the results
+func goBuild(dir string) error {
+	cmd := exec.Command("go", "build", "-o", os.DevNull, "./...")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(out) > 0 {
+			return errors.New(string(out))
+		}
+		return fmt.Errorf("go build: %w", err)
+	}
+	return nil
+}
+
+// checkDiscovered checks that at least one resource was discovered
+func checkDiscovered(resources []discover.DiscoveredResource) doctorCheck {
+	check := doctorCheck{name: "Resources discovered"}
+	if len(resources) == 0 {
+		check.status = checkFail
+		check.detail = "no package-level resource variables found"
+		check.hint = "declare resources as package-level variables, e.g. var MyStorage = storage.StorageAccount{...}"
+		return check
+	}
+	check.name = fmt.Sprintf("Resources discovered (%d)", len(resources))
+	return check
+}
+
+// checkDuplicateNames checks that no two resources share a name, which ARM
+// resourceId references and dependsOn cannot tell apart
+func checkDuplicateNames(resources []discover.DiscoveredResource) doctorCheck {
+	check := doctorCheck{name: "Resource names are unique"}
+
+	declared := make(map[string][]discover.DiscoveredResource)
+	for _, res := range resources {
+		declared[res.Name] = append(declared[res.Name], res)
+	}
+
+	var details []string
+	for name, decls := range declared {
+		if len(decls) < 2 {
+			continue
+		}
+		locations := make([]string, len(decls))
+		for i, res := range decls {
+			locations[i] = fmt.Sprintf("%s:%d", res.File, res.Line)
+		}
+		details = append(details, fmt.Sprintf("%s is declared %d times: %s", name, len(decls), strings.Join(locations, ", ")))
+	}
+	if len(details) > 0 {
+		sort.Strings(details)
+		check.status = checkFail
+		check.detail = strings.Join(details, "\n")
+		check.hint = "rename the variables so each resource has its own name"
+	}
+	return check
+}
+
+// expressionReferencePattern matches parameters('x') and variables('x') in
+// ARM expression strings
+var expressionReferencePattern = regexp.MustCompile(`\b(parameters|variables)\('([^']+)'\)`)
+
+// checkReferences checks that every parameter referenced in the sources under
+// dir is declared in a *.parameters.json file there. Templates built from Go
+// declare no variables, so every variable reference is reported.
+func checkReferences(dir string) doctorCheck {
+	check := doctorCheck{name: "Parameters and variables are declared"}
+
+	refs, err := collectReferences(dir)
+	if err != nil {
+		check.status = checkFail
+		check.detail = err.Error()
+		check.hint = "fix the syntax error above"
+		return check
+	}
+	declared, err := declaredParameters(dir)
+	if err != nil {
+		check.status = checkFail
+		check.detail = err.Error()
+		check.hint = "fix the parameters file"
+		return check
+	}
+
+	var details []string
+	for _, ref := range refs {
+		if ref.kind == "parameters" && declared[ref.name] {
+			continue
+		}
+		details = append(details, fmt.Sprintf("%s: %s('%s') is not declared", ref.pos, ref.kind, ref.name))
+	}
+	if len(details) > 0 {
+		check.status = checkFail
+		check.detail = strings.Join(details, "\n")
+		check.hint = "declare parameters in a *.parameters.json file; replace variables with Go constants"
+	}
+	return check
+}
+
+// reference is a parameters() or variables() reference in a source file
+type reference struct {
+	kind string // "parameters" or "variables"
+	name string
+	pos  token.Position
+}
+
+// collectReferences returns the parameter and variable references in the Go
+// files under dir: intrinsics.Parameters/Variables calls and expression strings.
+func collectReferences(dir string) ([]reference, error) {
+	var refs []reference
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				sel, ok := node.Fun.(*ast.SelectorExpr)
+				if !ok || len(node.Args) != 1 || (sel.Sel.Name != "Parameters" && sel.Sel.Name != "Variables") {
+					return true
+				}
+				lit, ok := node.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return true
+				}
+				if name, err := strconv.Unquote(lit.Value); err == nil {
+					refs = append(refs, reference{kind: strings.ToLower(sel.Sel.Name), name: name, pos: fset.Position(node.Pos())})
+				}
+				return false
+			case *ast.BasicLit:
+				if node.Kind != token.STRING {
+					return true
+				}
+				value, err := strconv.Unquote(node.Value)
+				if err != nil || !strings.HasPrefix(value, "[") {
+					return true
+				}
+				for _, m := range expressionReferencePattern.FindAllStringSubmatch(value, -1) {
+					refs = append(refs, reference{kind: m[1], name: m[2], pos: fset.Position(node.Pos())})
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	return refs, err
+}
+
+// declaredParameters returns the parameter names in the *.parameters.json
+// files in dir
+func declaredParameters(dir string) (map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.parameters.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	declared := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var params struct {
+			Parameters map[string]json.RawMessage `json:"parameters"`
+		}
+		if err := json.Unmarshal(data, &params); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for name := range params.Parameters {
+			declared[name] = true
+		}
+	}
+	return declared, nil
+}
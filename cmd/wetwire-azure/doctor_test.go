@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const healthyGoMod = `module example.com/infra
+
+go 1.23.0
+
+require (
+	github.com/lex00/wetwire-azure-go v1.3.1 // resources
+)
+`
+
+const healthySource = `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorageaccount",
+	Location: "eastus",
+	Kind:     intrinsics.Parameters("storageKind").ARMExpression(),
+}
+`
+
+// compileOK is a compile step that always succeeds, so tests need no Go toolchain
+func compileOK(string) error { return nil }
+
+// writeProject writes files, keyed by name, into a new temp directory.
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestDoctor_HealthyProject(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod":                      healthyGoMod,
+		"main.go":                     healthySource,
+		"azuredeploy.parameters.json": `{"parameters": {"storageKind": {"value": "StorageV2"}}}`,
+	})
+
+	var out bytes.Buffer
+	err := runDoctor(&out, dir, doctorOpts{compile: compileOK})
+	require.NoError(t, err, out.String())
+
+	assert.Contains(t, out.String(), "✓ go.mod requires github.com/lex00/wetwire-azure-go")
+	assert.Contains(t, out.String(), "✓ Packages compile")
+	assert.Contains(t, out.String(), "✓ Resources discovered (1)")
+	assert.Contains(t, out.String(), "✓ Resource names are unique")
+	assert.Contains(t, out.String(), "✓ Parameters and variables are declared")
+	assert.Contains(t, out.String(), "No problems found")
+	assert.NotContains(t, out.String(), "✗")
+}
+
+func TestDoctor_MissingGoMod(t *testing.T) {
+	dir := writeProject(t, map[string]string{"main.go": healthySource})
+
+	compiled := false
+	var out bytes.Buffer
+	err := runDoctor(&out, dir, doctorOpts{compile: func(string) error {
+		compiled = true
+		return nil
+	}})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 of 5 checks failed")
+	assert.False(t, compiled, "Expected the compile check to be skipped")
+	assert.Contains(t, out.String(), "✗ go.mod requires github.com/lex00/wetwire-azure-go")
+	assert.Contains(t, out.String(), "hint: run `go mod init <module>`")
+	assert.Contains(t, out.String(), "- Packages compile")
+	assert.Contains(t, out.String(), "✓ Resources discovered (1)")
+	// No parameters file declares storageKind
+	assert.Contains(t, out.String(), "parameters('storageKind') is not declared")
+}
+
+func TestDoctor_Failures(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod": "module example.com/infra\n\ngo 1.23.0\n",
+		"main.go": `package main
+
+var Location = "[variables('region')]"
+`,
+	})
+	sub := filepath.Join(dir, "more")
+	require.NoError(t, os.Mkdir(sub, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "a.go"), []byte(`package more
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{Name: "logs"}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "b.go"), []byte(`package more
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{Name: "logs2"}
+`), 0644))
+
+	var out bytes.Buffer
+	err := runDoctor(&out, dir, doctorOpts{compile: func(string) error {
+		return errors.New("more/b.go:5:5: Logs redeclared in this block")
+	}})
+
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "does not require github.com/lex00/wetwire-azure-go")
+	assert.Contains(t, out.String(), "- Packages compile")
+	assert.Contains(t, out.String(), "✗ Resource names are unique")
+	assert.Contains(t, out.String(), "Logs is declared 2 times")
+	assert.Contains(t, out.String(), "variables('region') is not declared")
+}
+
+func TestDoctor_CompileFailure(t *testing.T) {
+	dir := writeProject(t, map[string]string{"go.mod": healthyGoMod, "main.go": `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{Name: "mystorage"}
+`})
+
+	var out bytes.Buffer
+	err := runDoctor(&out, dir, doctorOpts{compile: func(string) error {
+		return errors.New("./main.go:5:17: undefined: storage.StorageAccount")
+	}})
+
+	require.Error(t, err)
+	assert.Contains(t, out.String(), "✗ Packages compile")
+	assert.Contains(t, out.String(), "    ./main.go:5:17: undefined: storage.StorageAccount")
+	assert.Contains(t, out.String(), "hint: fix the compile errors above")
+}
+
+func TestReadGoMod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go.mod")
+	require.NoError(t, os.WriteFile(path, []byte(healthyGoMod+"require \"example.com/other\" v1.0.0\n"), 0644))
+
+	module, requires, err := readGoMod(path)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/infra", module)
+	assert.True(t, requires["github.com/lex00/wetwire-azure-go"])
+	assert.True(t, requires["example.com/other"])
+}
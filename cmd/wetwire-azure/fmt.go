@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lex00/wetwire-azure-go/domain"
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/internal/lint"
+	"github.com/spf13/cobra"
+)
+
+// newFmtCmd creates the "fmt" subcommand, which applies the fixable lint
+// rules and gofmt to infrastructure files.
+func newFmtCmd() *cobra.Command {
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:     "fmt [path]",
+		Aliases: []string{"format"},
+		Short:   "Normalize and gofmt infrastructure files",
+		Long: `Fmt rewrites the Go files under path (or the single file path) with every
+fixable lint rule applied, such as location normalization (WAZ001), and then
+formatted with gofmt. It prints the files it changed.
+
+With --check, fmt writes nothing: it prints the files that would change and
+exits with status 1 if there are any, for use in CI.`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			changed, err := runFmt(cmd.OutOrStdout(), path, check)
+			if err != nil {
+				return err
+			}
+			if check && changed > 0 {
+				return &domain.ExitError{Code: 1}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false,
+		"Write nothing; list the files that need formatting and exit 1 if there are any")
+	return cmd
+}
+
+// runFmt formats the Go files in path, or only the file path, writing the
+// name of each file that changed to w. With check set, files are not
+// written. It returns the number of files that changed, or would change.
+func runFmt(w io.Writer, path string, check bool) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		err := discover.WalkGoFiles(path, nil, func(file string) error {
+			files = append(files, file)
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	linter := lint.NewLinter()
+	changed := 0
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return changed, err
+		}
+		formatted, err := linter.Format(file)
+		if err != nil {
+			return changed, fmt.Errorf("%s: %w", file, err)
+		}
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+
+		changed++
+		name := file
+		if info.IsDir() {
+			if rel, err := filepath.Rel(path, file); err == nil {
+				name = rel
+			}
+		}
+		fmt.Fprintln(w, name)
+		if check {
+			continue
+		}
+		fileInfo, err := os.Stat(file)
+		if err != nil {
+			return changed, err
+		}
+		if err := os.WriteFile(file, formatted, fileInfo.Mode().Perm()); err != nil {
+			return changed, fmt.Errorf("write %s: %w", file, err)
+		}
+	}
+	return changed, nil
+}
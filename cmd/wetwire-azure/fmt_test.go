@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unformattedSource needs both location normalization and gofmt
+const unformattedSource = `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name: "mystorage",
+	Location: "East US",
+}
+`
+
+const formattedSource = `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorage",
+	Location: "eastus",
+}
+`
+
+func TestFmt_Writes(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"main.go": unformattedSource,
+		"tidy.go": formattedSource,
+	})
+
+	var out bytes.Buffer
+	changed, err := runFmt(&out, dir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.Equal(t, "main.go\n", out.String())
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, formattedSource, string(content))
+
+	// Formatting is idempotent
+	out.Reset()
+	changed, err = runFmt(&out, dir, false)
+	require.NoError(t, err)
+	assert.Zero(t, changed)
+	assert.Empty(t, out.String())
+}
+
+func TestFmt_Check(t *testing.T) {
+	dir := writeProject(t, map[string]string{"main.go": unformattedSource})
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"fmt", "--check", dir}, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "main.go\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, unformattedSource, string(content), "--check must not write files")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(formattedSource), 0644))
+	stdout.Reset()
+	code = run([]string{"fmt", "--check", dir}, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout.String())
+}
+
+func TestFmt_InvalidSource(t *testing.T) {
+	dir := writeProject(t, map[string]string{"main.go": "package main\nvar x = \n"})
+
+	var out bytes.Buffer
+	_, err := runFmt(&out, dir, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "main.go")
+}
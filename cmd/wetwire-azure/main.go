@@ -1,31 +1,110 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/lex00/wetwire-azure-go/domain"
+	"github.com/spf13/cobra"
 )
 
 // Version information set via ldflags
-var version = "dev"
+var (
+	version = "dev"
+	commit  = ""
+)
+
+// commandError is the --error-format json report of a fatal error
+type commandError struct {
+	Command  string `json:"command"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exitCode"`
+}
 
 func main() {
 	// Set domain version from ldflags
 	domain.Version = version
 
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the CLI with args and returns the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
 	d := &domain.AzureDomain{}
 	cmd := domain.CreateRootCommand(d)
+	domain.ExtendCommands(cmd, d)
 
 	// Add custom commands
 	cmd.AddCommand(mcpCmd)
 	cmd.AddCommand(newDesignCmd())
 	cmd.AddCommand(newTestCmd())
 	cmd.AddCommand(newDiffCmd())
-	cmd.AddCommand(newWatchCmd())
+	cmd.AddCommand(newWatchCmd(d))
+	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newAPIVersionsCmd())
+	cmd.AddCommand(newFmtCmd())
+	cmd.AddCommand(newVersionCmd())
+
+	// --version prints the same details as the version command
+	cmd.SetVersionTemplate(versionInfo())
+
+	cmd.PersistentFlags().String("error-format", "text", "Format of fatal errors on stderr (text, json)")
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("error-format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("invalid --error-format %q (expected text or json)", format)
+		}
+		return nil
+	}
+
+	cmd.SetArgs(args)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	errorFormat := errorFormatArg(args)
+	if errorFormat == "json" {
+		// Leave stderr to the JSON report
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+
+	executed, err := cmd.ExecuteC()
+	if err == nil {
+		return 0
+	}
+
+	// The command has already reported the failure in its output
+	var exitErr *domain.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	printError(stderr, errorFormat, executed.Name(), err, 1)
+	return 1
+}
+
+// errorFormatArg returns the --error-format given in args. It is read before
+// the command runs, so that cobra's own error and usage output can be
+// silenced for json even when flag parsing fails.
+func errorFormatArg(args []string) string {
+	probe := &cobra.Command{FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true}}
+	format := probe.Flags().String("error-format", "text", "")
+	probe.Flags().SetOutput(io.Discard)
+	_ = probe.ParseFlags(args)
+	return *format
+}
 
-	if err := cmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+// printError writes a fatal error of command to w in format: the message
+// alone for text, or a commandError object for json
+func printError(w io.Writer, format, command string, err error, exitCode int) {
+	if format != "json" {
+		fmt.Fprintln(w, err)
+		return
 	}
+	json.NewEncoder(w).Encode(commandError{
+		Command:  command,
+		Message:  err.Error(),
+		ExitCode: exitCode,
+	})
 }
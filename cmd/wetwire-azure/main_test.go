@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_ErrorFormatJSON(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name    string
+		args    []string
+		command string
+		message string
+	}{
+		{
+			name:    "build failure",
+			args:    []string{"--error-format", "json", "build", missing},
+			command: "build",
+			message: "build failed: discovery failed",
+		},
+		{
+			name:    "invalid argument",
+			args:    []string{"api-versions", "a", "b", "--error-format=json"},
+			command: "api-versions",
+			message: "accepts at most 1 arg(s), received 2",
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"lint", "--no-such-flag", "--error-format", "json"},
+			command: "lint",
+			message: "unknown flag: --no-such-flag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			assert.Equal(t, 1, code)
+
+			// stderr holds only the JSON report
+			var report commandError
+			require.NoError(t, json.Unmarshal(stderr.Bytes(), &report), stderr.String())
+			assert.Equal(t, tt.command, report.Command)
+			assert.Contains(t, report.Message, tt.message)
+			assert.Equal(t, 1, report.ExitCode)
+		})
+	}
+}
+
+func TestRun_ErrorFormatText(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"api-versions", "a", "b"}, &stdout, &stderr)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "accepts at most 1 arg(s), received 2")
+	assert.False(t, json.Valid(stderr.Bytes()), stderr.String())
+
+	stderr.Reset()
+	code = run([]string{"--error-format", "yaml", "api-versions", "."}, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), `invalid --error-format "yaml"`)
+}
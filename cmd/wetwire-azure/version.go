@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/domain"
+	"github.com/spf13/cobra"
+)
+
+// newVersionCmd creates the "version" subcommand, which prints the same
+// version information as --version.
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, Go version, and git commit",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprint(cmd.OutOrStdout(), versionInfo())
+		},
+	}
+}
+
+// versionInfo returns the version of wetwire-azure, the Go version it was
+// built with, and the git commit it was built from if known, one per line
+func versionInfo() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "wetwire-azure version %s\n", domain.Version)
+	fmt.Fprintf(&b, "go version %s\n", runtime.Version())
+	if rev := buildCommit(); rev != "" {
+		fmt.Fprintf(&b, "commit %s\n", rev)
+	}
+	return b.String()
+}
+
+// buildCommit returns the git commit stamped via ldflags, or else the one the
+// Go toolchain recorded when building from a checkout; empty if neither is
+// available
+func buildCommit() string {
+	if commit != "" {
+		return commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision != "" && modified == "true" {
+		revision += " (modified)"
+	}
+	return revision
+}
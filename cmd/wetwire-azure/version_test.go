@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_Version(t *testing.T) {
+	saved := domain.Version
+	domain.Version = "1.2.3-test"
+	t.Cleanup(func() { domain.Version = saved })
+
+	for _, args := range [][]string{{"version"}, {"--version"}} {
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Contains(t, stdout.String(), "wetwire-azure version 1.2.3-test", args)
+		assert.Contains(t, stdout.String(), "go version "+runtime.Version(), args)
+	}
+}
+
+func TestVersionInfo_Commit(t *testing.T) {
+	saved := commit
+	commit = "abc1234"
+	t.Cleanup(func() { commit = saved })
+
+	assert.Contains(t, versionInfo(), "commit abc1234\n")
+}
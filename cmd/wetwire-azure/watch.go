@@ -1,20 +1,308 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/lex00/wetwire-azure-go/domain"
+	"github.com/lex00/wetwire-azure-go/internal/lint"
+	"github.com/lex00/wetwire-azure-go/pkg/synth"
 	"github.com/spf13/cobra"
 )
 
+// watchOpts configures a watch loop.
+type watchOpts struct {
+	fix      bool
+	lint     bool
+	output   string
+	interval time.Duration
+	debounce time.Duration
+	testRun  bool
+}
+
 // newWatchCmd creates the "watch" subcommand for auto-rebuilding on file changes.
-func newWatchCmd() *cobra.Command {
-	return &cobra.Command{
+func newWatchCmd(d *domain.AzureDomain) *cobra.Command {
+	var opts watchOpts
+
+	cmd := &cobra.Command{
 		Use:   "watch [path]",
 		Short: "Auto-rebuild on source file changes",
-		Long:  `Watch monitors source files for changes and automatically rebuilds.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Watch monitors source files for changes and automatically rebuilds.
+
+With --fix, fixable lint issues in changed files are applied before each
+rebuild, and every fix is printed. Files written by a fix do not trigger
+another rebuild.
+
+With --lint, each cycle lints the files that changed, reuses the issues
+found earlier in the others, and prints every current issue.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return fmt.Errorf("watch command not yet implemented for azure domain")
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return runWatch(ctx, cmd.OutOrStdout(), d, args[0], opts)
 		},
 	}
+
+	cmd.Flags().BoolVar(&opts.fix, "fix", false, "Apply fixable lint issues before each rebuild")
+	cmd.Flags().BoolVar(&opts.lint, "lint", false, "Lint changed files on each cycle and print all current issues")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Write the template to this file on each rebuild")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 500*time.Millisecond, "How often to check for changes")
+	cmd.Flags().DurationVar(&opts.debounce, "debounce", 300*time.Millisecond, "How long changes must settle before rebuilding")
+	cmd.Flags().BoolVar(&opts.testRun, "test-run", false, "Run a single fix and build cycle, then exit")
+
+	return cmd
+}
+
+// runWatch builds path, then polls it and rebuilds whenever its Go files
+// change. The first cycle covers every file; later cycles only fix, lint, and
+// reparse for the build the files that changed since the previous cycle. It
+// returns when ctx is canceled, or after the first cycle with testRun set.
+func runWatch(ctx context.Context, w io.Writer, d *domain.AzureDomain, path string, opts watchOpts) error {
+	modTimes, err := snapshotModTimes(path)
+	if err != nil {
+		return err
+	}
+	changed := make([]string, 0, len(modTimes))
+	for file := range modTimes {
+		changed = append(changed, file)
+	}
+	sort.Strings(changed)
+
+	if d.BuildCache == nil {
+		d.BuildCache = synth.NewCache()
+	}
+
+	var issues *lintCache
+	if opts.lint {
+		issues = newLintCache(lint.NewLinter())
+	}
+
+	for {
+		written, err := watchCycle(ctx, w, d, path, changed, issues, opts)
+		if err != nil {
+			if opts.testRun {
+				return err
+			}
+			fmt.Fprintf(w, "✗ %v\n", err)
+		}
+		if opts.testRun {
+			return nil
+		}
+
+		// Files written by fixes join the baseline so that they do not
+		// trigger another cycle; other files saved during the cycle still do
+		for file, t := range written {
+			modTimes[file] = t
+		}
+
+		changed, modTimes, err = waitForChanges(ctx, path, modTimes, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		fmt.Fprintf(w, "\n%d file(s) changed, rebuilding\n", len(changed))
+	}
+}
+
+// watchCycle applies lint fixes to changed (with opts.fix), lints changed
+// with issues if it is not nil, and rebuilds path. It returns the mod times
+// of the files the fixes wrote.
+func watchCycle(ctx context.Context, w io.Writer, d *domain.AzureDomain, path string, changed []string, issues *lintCache, opts watchOpts) (map[string]time.Time, error) {
+	written := make(map[string]time.Time)
+	if opts.fix {
+		linter := lint.NewLinter()
+		for _, file := range changed {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			before, err := os.ReadFile(file)
+			if err != nil {
+				return written, fmt.Errorf("fix %s: %w", file, err)
+			}
+			fixed, err := linter.FixFile(file)
+			if err != nil {
+				return written, fmt.Errorf("fix %s: %w", file, err)
+			}
+			for _, issue := range fixed {
+				fmt.Fprintf(w, "Fixed %s\n", lint.FormatResult(issue))
+			}
+			after, err := os.ReadFile(file)
+			if err != nil {
+				return written, fmt.Errorf("fix %s: %w", file, err)
+			}
+			if !bytes.Equal(after, before) {
+				info, err := os.Stat(file)
+				if err != nil {
+					return written, fmt.Errorf("fix %s: %w", file, err)
+				}
+				written[file] = info.ModTime()
+			}
+		}
+	}
+
+	if issues != nil {
+		// Fixes above may have written files, so the set of files is read
+		// after them
+		files, err := snapshotModTimes(path)
+		if err != nil {
+			return written, err
+		}
+		results, err := issues.update(changed, files)
+		if err != nil {
+			return written, err
+		}
+		for _, issue := range results {
+			fmt.Fprintln(w, lint.FormatResult(issue))
+		}
+		fmt.Fprintf(w, "%d lint issue(s)\n", len(results))
+	}
+
+	buildCtx := domain.NewContext(ctx, path)
+	result, err := d.Builder().Build(buildCtx, path, domain.BuildOpts{Output: opts.output})
+	if err != nil {
+		return written, fmt.Errorf("build failed: %w", err)
+	}
+	if !result.Success {
+		fmt.Fprintf(w, "✗ %s\n", result.Message)
+		for _, e := range result.Errors {
+			fmt.Fprintf(w, "  %s\n", e.String())
+		}
+		return written, nil
+	}
+	fmt.Fprintf(w, "✓ %s\n", result.Message)
+	return written, nil
+}
+
+// lintCache holds the lint issues of each file under watch, so that a cycle
+// only re-lints the files that changed. Rules that look at other files of the
+// package are only re-run for those files too.
+type lintCache struct {
+	check  func(file string) ([]lint.LintResult, error)
+	issues map[string][]lint.LintResult
+}
+
+// newLintCache creates an empty cache that lints files with linter
+func newLintCache(linter *lint.Linter) *lintCache {
+	return &lintCache{
+		check:  linter.CheckFile,
+		issues: make(map[string][]lint.LintResult),
+	}
+}
+
+// update re-lints the changed files, drops the issues of files no longer in
+// files, and returns every current issue sorted by file and line. Test files
+// are not linted.
+func (c *lintCache) update(changed []string, files map[string]time.Time) ([]lint.LintResult, error) {
+	for file := range c.issues {
+		if _, ok := files[file]; !ok {
+			delete(c.issues, file)
+		}
+	}
+	for _, file := range changed {
+		if _, ok := files[file]; !ok || strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		results, err := c.check(file)
+		if err != nil {
+			return nil, fmt.Errorf("lint %s: %w", file, err)
+		}
+		c.issues[file] = results
+	}
+
+	var all []lint.LintResult
+	for _, results := range c.issues {
+		all = append(all, results...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].File != all[j].File {
+			return all[i].File < all[j].File
+		}
+		return all[i].Line < all[j].Line
+	})
+	return all, nil
+}
+
+// waitForChanges polls path every opts.interval until its Go files differ from
+// last and then stay unchanged for opts.debounce. It returns the changed or
+// added files and the settled mod times.
+func waitForChanges(ctx context.Context, path string, last map[string]time.Time, opts watchOpts) ([]string, map[string]time.Time, error) {
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	current := last
+	var settleAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		next, err := snapshotModTimes(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !sameModTimes(current, next) {
+			current = next
+			settleAt = time.Now().Add(opts.debounce)
+			continue
+		}
+		if !settleAt.IsZero() && !time.Now().Before(settleAt) {
+			return changedFiles(last, current), current, nil
+		}
+	}
+}
+
+// snapshotModTimes returns the modification time of every Go file under path.
+func snapshotModTimes(path string) (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time)
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(file, ".go") {
+			modTimes[file] = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return modTimes, nil
+}
+
+// sameModTimes reports whether a and b hold the same files and mod times.
+func sameModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, t := range a {
+		if other, ok := b[file]; !ok || !other.Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// changedFiles returns the files in current that are new or modified since
+// last, sorted. Deleted files are not included.
+func changedFiles(last, current map[string]time.Time) []string {
+	var changed []string
+	for file, t := range current {
+		if prev, ok := last[file]; !ok || !prev.Equal(t) {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-azure-go/domain"
+	"github.com/lex00/wetwire-azure-go/internal/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const badLocationSource = `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorageaccount",
+	Location: "East US",
+}
+`
+
+// runWatchCmd runs the watch command with args and returns its output.
+func runWatchCmd(t *testing.T, args ...string) string {
+	t.Helper()
+
+	cmd := newWatchCmd(&domain.AzureDomain{})
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+	return out.String()
+}
+
+func TestWatchCmd_FixNormalizesLocation(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte(badLocationSource), 0644))
+
+	out := runWatchCmd(t, dir, "--fix", "--test-run")
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `Location: "eastus"`)
+	assert.Contains(t, out, "Fixed main.go:7:")
+	assert.Contains(t, out, "(WAZ001)")
+	assert.Contains(t, out, "✓ Build completed")
+}
+
+func TestWatchCmd_WithoutFixLeavesSource(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte(badLocationSource), 0644))
+
+	out := runWatchCmd(t, dir, "--test-run")
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, badLocationSource, string(content))
+	assert.NotContains(t, out, "Fixed")
+	assert.Contains(t, out, "✓ Build completed")
+}
+
+// TestWatchCycle_ReturnsWrittenFiles tests that a cycle reports the mod
+// times of the files its fixes wrote and of no others, so saves to other files
+// during the cycle still trigger the next one
+func TestWatchCycle_ReturnsWrittenFiles(t *testing.T) {
+	dir := t.TempDir()
+	fixedFile := filepath.Join(dir, "main.go")
+	cleanFile := filepath.Join(dir, "clean.go")
+	require.NoError(t, os.WriteFile(fixedFile, []byte(badLocationSource), 0644))
+	require.NoError(t, os.WriteFile(cleanFile, []byte("package main\n"), 0644))
+
+	var out bytes.Buffer
+	d := &domain.AzureDomain{}
+	written, err := watchCycle(context.Background(), &out, d, dir, []string{cleanFile, fixedFile}, nil, watchOpts{fix: true})
+	require.NoError(t, err)
+
+	info, err := os.Stat(fixedFile)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{fixedFile: info.ModTime()}, written)
+}
+
+func TestWaitForChanges_Debounces(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte(badLocationSource), 0644))
+
+	last, err := snapshotModTimes(dir)
+	require.NoError(t, err)
+
+	opts := watchOpts{interval: 10 * time.Millisecond, debounce: 200 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Two saves in quick succession are reported as one change
+	go func() {
+		later := time.Now().Add(time.Second)
+		_ = os.Chtimes(file, later, later)
+		time.Sleep(20 * time.Millisecond)
+		added := filepath.Join(dir, "extra.go")
+		_ = os.WriteFile(added, []byte("package main\n"), 0644)
+	}()
+
+	changed, current, err := waitForChanges(ctx, dir, last, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "extra.go"), file}, changed)
+	assert.Len(t, current, 2)
+
+	// Without further changes, waiting only ends when the context does
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _, err = waitForChanges(ctx, dir, current, opts)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWatchCmd_LintPrintsIssues(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(badLocationSource), 0644))
+
+	out := runWatchCmd(t, dir, "--lint", "--test-run")
+
+	assert.Contains(t, out, "main.go:7:")
+	assert.Contains(t, out, "(WAZ001)")
+	assert.Contains(t, out, "✓ Build completed")
+}
+
+func TestLintCache_RelintsOnlyChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.go")
+	second := filepath.Join(dir, "second.go")
+	require.NoError(t, os.WriteFile(first, []byte(badLocationSource), 0644))
+	require.NoError(t, os.WriteFile(second, []byte(strings.Replace(badLocationSource, "MyStorage", "OtherStorage", 1)), 0644))
+
+	cache := newLintCache(lint.NewLinterWithOptions(lint.Options{OnlyRules: []string{"WAZ001"}}))
+	check := cache.check
+	var checked []string
+	cache.check = func(file string) ([]lint.LintResult, error) {
+		checked = append(checked, file)
+		return check(file)
+	}
+
+	files, err := snapshotModTimes(dir)
+	require.NoError(t, err)
+	issues, err := cache.update([]string{first, second}, files)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, first, issues[0].File)
+	assert.Equal(t, second, issues[1].File)
+
+	// Fixing the first file re-lints only that file; the second file's issue
+	// is kept from the previous cycle
+	checked = nil
+	fixed := strings.Replace(badLocationSource, `"East US"`, `"eastus"`, 1)
+	require.NoError(t, os.WriteFile(first, []byte(fixed), 0644))
+	files, err = snapshotModTimes(dir)
+	require.NoError(t, err)
+	issues, err = cache.update([]string{first}, files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{first}, checked)
+	require.Len(t, issues, 1)
+	assert.Equal(t, second, issues[0].File)
+	assert.Equal(t, "WAZ001", issues[0].Rule)
+
+	// Deleting the second file drops its issues without linting anything
+	checked = nil
+	require.NoError(t, os.Remove(second))
+	files, err = snapshotModTimes(dir)
+	require.NoError(t, err)
+	issues, err = cache.update(nil, files)
+	require.NoError(t, err)
+	assert.Empty(t, checked)
+	assert.Empty(t, issues)
+}
@@ -2,27 +2,156 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
-	coredomain "github.com/lex00/wetwire-core-go/domain"
 	"github.com/lex00/wetwire-azure-go/internal/differ"
 	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/internal/estimate"
+	"github.com/lex00/wetwire-azure-go/internal/importer"
 	"github.com/lex00/wetwire-azure-go/internal/lint"
-	"github.com/lex00/wetwire-azure-go/internal/template"
+	"github.com/lex00/wetwire-azure-go/internal/validator"
+	"github.com/lex00/wetwire-azure-go/pkg/synth"
+	coredomain "github.com/lex00/wetwire-core-go/domain"
 )
 
 // AzureDomain implements the Domain interface for Azure infrastructure.
-type AzureDomain struct{}
+// Its fields hold Azure-specific options that the core option structs do not
+// carry; ExtendCommands binds them to CLI flags.
+type AzureDomain struct {
+	// Scope is the ARM deployment scope for build (default: resourceGroup)
+	Scope string
+
+	// MinAPIVersion rejects resources whose explicit APIVersion predates it
+	MinAPIVersion string
+
+	// FromBicep makes import parse the source as Bicep regardless of its extension
+	FromBicep bool
+
+	// ImportMerge is an existing Go file that import appends new resources to
+	ImportMerge string
+
+	// ImportRaw makes import declare every resource as a template.RawResource
+	// instead of the typed struct of its resources package
+	ImportRaw bool
+
+	// Compact makes build emit single-line JSON instead of indented JSON
+	Compact bool
+
+	// SortKeys makes build sort the keys of every JSON object, including
+	// the fields of the template and its resources, instead of keeping
+	// their conventional ARM order
+	SortKeys bool
+
+	// OmitEmptySections makes build leave out the parameters, variables, and
+	// outputs sections when they are empty
+	OmitEmptySections bool
+
+	// BuildMerge is an existing ARM template that build merges the generated
+	// resources into
+	BuildMerge string
+
+	// BuildSubscription and BuildResourceGroup are the subscription ID and
+	// resource group that build substitutes for the placeholders of resource
+	// IDs, such as /subscriptions/{sub}/resourceGroups/{rg}/..., in the
+	// scopes and properties it writes
+	BuildSubscription  string
+	BuildResourceGroup string
+
+	// BuildMaxResources makes build fail when the template has more
+	// resources, counting copy loop instances; zero means no limit
+	BuildMaxResources int
+
+	// BuildCache, if set, makes build reparse only the files that changed
+	// since an earlier build with the same cache, as watch does
+	BuildCache *synth.Cache
+
+	// BuildCountOnly makes build only discover the resources and report how
+	// many there are, without generating the template
+	BuildCountOnly bool
+
+	// BuildAllowEmpty makes a count-only build succeed when no resources are
+	// found
+	BuildAllowEmpty bool
+
+	// BuildTrace makes build write to stderr whether discovery accepted each
+	// package-level variable as a resource, and why not (see
+	// discover.TraceResources); WETWIRE_DEBUG being set does the same
+	BuildTrace bool
+
+	// Exclude holds glob patterns for files and directories that build, lint,
+	// and list skip (see discover.Excludes)
+	Exclude []string
+
+	// Profile makes build write a profile of discovery and generation, given
+	// as <kind>=<file>; only cpu is supported
+	Profile string
+
+	// Metadata holds extra entries for the top-level metadata of built
+	// templates, alongside the _generator stamp
+	Metadata map[string]string
+
+	// OnlyRules restricts lint to these rule IDs; empty runs all rules
+	OnlyRules []string
+
+	// CheckGlobalNames makes lint run WAZ402, and build warn on stderr, for
+	// hardcoded names of resources whose names must be unique across Azure
+	CheckGlobalNames bool
+
+	// InitModule is the Go module name for init (default: the directory name)
+	InitModule string
+
+	// InitLocation is the Azure region used in the init starter template
+	InitLocation string
+
+	// InitTemplate is the name of the init starter template (default: storage)
+	InitTemplate string
+
+	// GraphGroupByFile makes graph cluster resources by the file declaring them
+	GraphGroupByFile bool
+
+	// GraphRoots limits graph to these resources and those they depend on
+	GraphRoots []string
+
+	// GraphDepth limits graph to resources within this many dependency hops
+	// of GraphRoots; 0 means no limit
+	GraphDepth int
+
+	// ListDependsOn makes list show each resource's dependency tree
+	ListDependsOn bool
+
+	// ListEstimate makes list annotate each resource with a rough relative
+	// cost tier (see estimate.Tier)
+	ListEstimate bool
+
+	// ValidateParameters is an ARM parameters file that validate checks
+	// against the template instead of linting
+	ValidateParameters string
+
+	// DiffIgnorePaths are the template paths that diff removes from both
+	// templates before comparing them (see differ.ARMDiffer.WithIgnorePaths)
+	DiffIgnorePaths []string
+}
+
+// excludes returns the exclude patterns of d, which may be nil
+func (d *AzureDomain) excludes() []string {
+	if d == nil {
+		return nil
+	}
+	return d.Exclude
+}
 
 // Compile-time checks
 var (
-	_ coredomain.Domain        = (*AzureDomain)(nil)
-	_ coredomain.ListerDomain  = (*AzureDomain)(nil)
-	_ coredomain.GrapherDomain = (*AzureDomain)(nil)
-	_ coredomain.DifferDomain  = (*AzureDomain)(nil)
+	_ coredomain.Domain         = (*AzureDomain)(nil)
+	_ coredomain.ListerDomain   = (*AzureDomain)(nil)
+	_ coredomain.GrapherDomain  = (*AzureDomain)(nil)
+	_ coredomain.DifferDomain   = (*AzureDomain)(nil)
+	_ coredomain.ImporterDomain = (*AzureDomain)(nil)
 )
 
 // Name returns "azure"
@@ -37,89 +166,192 @@ func (d *AzureDomain) Version() string {
 
 // Builder returns the Azure builder implementation
 func (d *AzureDomain) Builder() coredomain.Builder {
-	return &azureBuilder{}
+	return &azureBuilder{domain: d}
 }
 
 // Linter returns the Azure linter implementation
 func (d *AzureDomain) Linter() coredomain.Linter {
-	return &azureLinter{}
+	return &azureLinter{domain: d}
 }
 
 // Initializer returns the Azure initializer implementation
 func (d *AzureDomain) Initializer() coredomain.Initializer {
-	return &azureInitializer{}
+	return &azureInitializer{domain: d}
 }
 
 // Validator returns the Azure validator implementation
 func (d *AzureDomain) Validator() coredomain.Validator {
-	return &azureValidator{}
+	return &azureValidator{domain: d}
 }
 
 // Lister returns the Azure lister implementation
 func (d *AzureDomain) Lister() coredomain.Lister {
-	return &azureLister{}
+	return &azureLister{domain: d}
 }
 
 // Grapher returns the Azure grapher implementation
 func (d *AzureDomain) Grapher() coredomain.Grapher {
-	return &azureGrapher{}
+	return &azureGrapher{domain: d}
 }
 
 // Differ returns the Azure differ implementation
 func (d *AzureDomain) Differ() coredomain.Differ {
-	return differ.New()
+	return differ.New().WithIgnorePaths(d.DiffIgnorePaths...)
+}
+
+// Importer returns the Azure importer implementation
+func (d *AzureDomain) Importer() coredomain.Importer {
+	return &azureImporter{domain: d}
 }
 
 // azureBuilder implements domain.Builder
-type azureBuilder struct{}
+type azureBuilder struct {
+	domain *AzureDomain
+}
 
+// Build discovers the resources in path and generates the ARM template. With
+// AzureDomain.Profile set, discovery and generation run under that profile.
 func (b *azureBuilder) Build(ctx *Context, path string, opts BuildOpts) (*Result, error) {
+	profile := ""
+	if b.domain != nil {
+		profile = b.domain.Profile
+	}
+	stop, err := startProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.build(ctx, path, opts)
+	if stopErr := stop(); stopErr != nil && err == nil {
+		err = fmt.Errorf("write profile: %w", stopErr)
+	}
+	return result, err
+}
+
+func (b *azureBuilder) build(ctx *Context, path string, opts BuildOpts) (*Result, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return nil, fmt.Errorf("resolve path: %w", err)
 	}
 
-	// Discover all resources
-	resources, err := discover.DiscoverResources(absPath)
-	if err != nil {
-		return nil, fmt.Errorf("discovery failed: %w", err)
+	if b.domain != nil && b.domain.BuildCountOnly {
+		return b.countResources(absPath), nil
 	}
 
-	if len(resources) == 0 {
+	synthOpts := b.domain.synthOptions()
+	if b.domain != nil && b.domain.BuildMerge != "" {
+		if synthOpts.Base, err = os.ReadFile(b.domain.BuildMerge); err != nil {
+			return nil, fmt.Errorf("read merge template: %w", err)
+		}
+	}
+
+	tmpl, err := synth.Synthesize(absPath, synthOpts)
+	var invalid *synth.ValidationError
+	switch {
+	case errors.Is(err, synth.ErrNoResources):
 		return NewErrorResult("no resources found", Error{
 			Path:    absPath,
 			Message: "no Azure resources found",
 		}), nil
+	case errors.Is(err, synth.ErrReservedMetadata):
+		return NewErrorResult("invalid metadata", Error{
+			Message: err.Error(),
+		}), nil
+	case errors.Is(err, synth.ErrTooManyResources):
+		return NewErrorResult("too many resources", Error{
+			Path:    absPath,
+			Message: err.Error(),
+		}), nil
+	case errors.Is(err, synth.ErrMergeConflict):
+		return NewErrorResult("merge failed", Error{
+			Path:    b.domain.BuildMerge,
+			Message: err.Error(),
+		}), nil
+	case errors.As(err, &invalid):
+		validationErrors := make([]Error, len(invalid.Errors))
+		for i, e := range invalid.Errors {
+			validationErrors[i] = Error{
+				Path:     e.File,
+				Line:     e.Line,
+				Severity: "error",
+				Message:  e.Error(),
+			}
+		}
+		return NewErrorResultMultiple("invalid resources", validationErrors), nil
+	case err != nil:
+		return nil, err
 	}
 
-	// Build template
-	builder := template.NewTemplateBuilder()
-	for _, res := range resources {
-		if err := builder.AddResource(res); err != nil {
-			return nil, fmt.Errorf("failed to add resource %s: %w", res.Name, err)
+	// Note the base template resources that generated ones replaced
+	replaced := ""
+	if len(tmpl.Replaced) > 0 {
+		replaced = fmt.Sprintf("; replaced %s of %s", strings.Join(tmpl.Replaced, ", "), b.domain.BuildMerge)
+	}
+
+	// Handle output file; "-" leaves the template in the result, for stdout
+	if !opts.DryRun && opts.Output != "" && opts.Output != "-" {
+		if err := writeOutputFile(opts.Output, tmpl); err != nil {
+			return nil, fmt.Errorf("write output: %w", err)
 		}
+		return NewResult(fmt.Sprintf("Wrote %s%s", opts.Output, replaced)), nil
 	}
 
-	// Generate ARM template JSON
-	templateJSON, err := builder.Build()
+	return NewResultWithData("Build completed"+replaced, string(tmpl.JSON)), nil
+}
+
+// countResources discovers the resources in absPath and reports their number,
+// for checks that only need discovery to succeed. Discovery errors, and
+// finding no resources unless AzureDomain.BuildAllowEmpty is set, fail the
+// result.
+func (b *azureBuilder) countResources(absPath string) *Result {
+	resources, err := discover.DiscoverResources(absPath, b.domain.excludes()...)
 	if err != nil {
-		return nil, fmt.Errorf("template build failed: %w", err)
+		return NewErrorResult("discovery failed", Error{
+			Path:    absPath,
+			Message: err.Error(),
+		})
+	}
+	if len(resources) == 0 && !b.domain.BuildAllowEmpty {
+		return NewErrorResult("no resources found", Error{
+			Path:    absPath,
+			Message: "no Azure resources found",
+		})
 	}
 
-	// Handle output file
-	if !opts.DryRun && opts.Output != "" {
-		if err := os.WriteFile(opts.Output, []byte(templateJSON), 0644); err != nil {
-			return nil, fmt.Errorf("write output: %w", err)
-		}
-		return NewResult(fmt.Sprintf("Wrote %s", opts.Output)), nil
+	noun := "resources"
+	if len(resources) == 1 {
+		noun = "resource"
 	}
+	return NewResult(fmt.Sprintf("Found %d %s", len(resources), noun))
+}
 
-	return NewResultWithData("Build completed", templateJSON), nil
+// synthOptions returns the synth options for the build options of d, which
+// may be nil
+func (d *AzureDomain) synthOptions() synth.Options {
+	opts := synth.Options{Version: Version}
+	if d != nil {
+		opts.Scope = d.Scope
+		opts.MinAPIVersion = d.MinAPIVersion
+		opts.MaxResources = d.BuildMaxResources
+		opts.Compact = d.Compact
+		opts.SortKeys = d.SortKeys
+		opts.OmitEmptySections = d.OmitEmptySections
+		opts.Exclude = d.Exclude
+		opts.Metadata = d.Metadata
+		opts.SubscriptionID = d.BuildSubscription
+		opts.ResourceGroup = d.BuildResourceGroup
+		opts.Cache = d.BuildCache
+	}
+	return opts
 }
 
 // azureLinter implements domain.Linter
-type azureLinter struct{}
+type azureLinter struct {
+	domain *AzureDomain
+}
 
+// Lint runs the lint rules on path. With AzureDomain.OnlyRules set, only
+// those rules run; unknown IDs are reported as warnings.
 func (l *azureLinter) Lint(ctx *Context, path string, opts LintOpts) (*Result, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
@@ -131,6 +363,19 @@ func (l *azureLinter) Lint(ctx *Context, path string, opts LintOpts) (*Result, e
 		DisabledRules: opts.Disable,
 		Fix:           opts.Fix,
 	}
+	if l.domain != nil {
+		lintOpts.CheckGlobalNames = l.domain.CheckGlobalNames
+	}
+	var warnings []Error
+	if l.domain != nil && len(l.domain.OnlyRules) > 0 {
+		lintOpts.OnlyRules = l.domain.OnlyRules
+		for _, id := range lint.UnknownRuleIDs(l.domain.OnlyRules) {
+			warnings = append(warnings, Error{
+				Severity: "warning",
+				Message:  fmt.Sprintf("unknown rule %s in --only", id),
+			})
+		}
+	}
 
 	// Create linter with options
 	azureLint := lint.NewLinterWithOptions(lintOpts)
@@ -144,7 +389,7 @@ func (l *azureLinter) Lint(ctx *Context, path string, opts LintOpts) (*Result, e
 	}
 
 	if info.IsDir() {
-		results, err = azureLint.CheckDirectory(absPath)
+		results, err = azureLint.CheckDirectory(absPath, l.domain.excludes()...)
 	} else {
 		results, err = azureLint.CheckFile(absPath)
 	}
@@ -154,11 +399,14 @@ func (l *azureLinter) Lint(ctx *Context, path string, opts LintOpts) (*Result, e
 	}
 
 	if len(results) == 0 {
-		return NewResult("No lint issues found"), nil
+		result := NewResult("No lint issues found")
+		result.Errors = warnings
+		return result, nil
 	}
 
 	// Convert to domain errors
-	errs := make([]Error, 0, len(results))
+	errs := make([]Error, 0, len(warnings)+len(results))
+	errs = append(errs, warnings...)
 	for _, r := range results {
 		errs = append(errs, Error{
 			Path:     r.File,
@@ -177,7 +425,9 @@ func (l *azureLinter) Lint(ctx *Context, path string, opts LintOpts) (*Result, e
 }
 
 // azureInitializer implements domain.Initializer
-type azureInitializer struct{}
+type azureInitializer struct {
+	domain *AzureDomain
+}
 
 func (i *azureInitializer) Init(ctx *Context, path string, opts InitOpts) (*Result, error) {
 	// Use opts.Path if provided, otherwise fall back to path argument
@@ -227,8 +477,28 @@ func (i *azureInitializer) Init(ctx *Context, path string, opts InitOpts) (*Resu
 		}), nil
 	}
 
-	// Get module name from directory name
-	moduleName := filepath.Base(path)
+	// Resolve the module name, location, and starter template
+	moduleName := defaultModuleName(targetPath)
+	location := DefaultInitLocation
+	templateName := DefaultInitTemplate
+	if i.domain != nil {
+		if i.domain.InitModule != "" {
+			moduleName = i.domain.InitModule
+		}
+		if i.domain.InitLocation != "" {
+			location = i.domain.InitLocation
+		}
+		if i.domain.InitTemplate != "" {
+			templateName = i.domain.InitTemplate
+		}
+	}
+	starter, err := lookupStarterTemplate(templateName)
+	if err != nil {
+		return NewErrorResult(err.Error(), Error{
+			Path:    targetPath,
+			Message: err.Error(),
+		}), nil
+	}
 
 	// Create go.mod
 	goModContent := fmt.Sprintf(`module %s
@@ -242,26 +512,8 @@ require github.com/lex00/wetwire-azure-go v1.3.1
 		return nil, fmt.Errorf("write go.mod: %w", err)
 	}
 
-	// Create example main.go
-	mainGoContent := `package main
-
-import (
-	"github.com/lex00/wetwire-azure-go/resources/storage"
-)
-
-// Example storage account resource
-var MyStorage = storage.StorageAccount{
-	Name:     "mystorageaccount",
-	Location: "eastus",
-	SKU: storage.SKU{
-		Name: "Standard_LRS",
-	},
-	Kind: "StorageV2",
-	Properties: storage.StorageAccountProperties{
-		AccessTier: "Hot",
-	},
-}
-`
+	// Create example main.go from the starter template
+	mainGoContent := starter.render(location)
 	mainGoPath := filepath.Join(targetPath, "main.go")
 	if err := os.WriteFile(mainGoPath, []byte(mainGoContent), 0644); err != nil {
 		return nil, fmt.Errorf("write main.go: %w", err)
@@ -296,17 +548,268 @@ var MyStorage = storage.StorageAccount{
 	return NewResult(fmt.Sprintf("Initialized wetwire-azure project in %s", targetPath)), nil
 }
 
+// defaultModuleName returns the name of the directory at path, for use as a
+// Go module name
+func defaultModuleName(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return filepath.Base(path)
+}
+
 // azureValidator implements domain.Validator
-type azureValidator struct{}
+type azureValidator struct {
+	domain *AzureDomain
+}
 
+// Validate lints path, or checks it against the ARM template rules if it is
+// a template JSON file. With AzureDomain.ValidateParameters set, it instead
+// checks that parameters file against the template at path, which is either
+// an ARM template JSON file or a directory of Go source that is built first.
+// Every reported error carries the rule ID or validator check as its Code.
 func (v *azureValidator) Validate(ctx *Context, path string, opts ValidateOpts) (*Result, error) {
-	// For Azure, validation is the same as linting
+	if v.domain != nil && v.domain.ValidateParameters != "" {
+		return v.validateParameters(ctx, path, v.domain.ValidateParameters)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return v.validateTemplate(path)
+	}
+
+	// Otherwise, for Azure, validation is the same as linting
 	linter := &azureLinter{}
 	return linter.Lint(ctx, path, LintOpts{})
 }
 
+// validateTemplate checks the ARM template JSON file at path. Warnings and
+// information are listed but only errors fail the validation.
+func (v *azureValidator) validateTemplate(path string) (*Result, error) {
+	results, err := validator.NewValidator().ValidateFile(path)
+	if err != nil {
+		return NewErrorResult("invalid template", Error{
+			Path:    path,
+			Message: err.Error(),
+		}), nil
+	}
+	if len(results) == 0 {
+		return NewResult("Template is valid"), nil
+	}
+	if !hasValidationError(results) {
+		result := NewResult("Template is valid, with warnings")
+		result.Errors = validationErrors(path, results)
+		return result, nil
+	}
+	return NewErrorResultMultiple("template validation failed", validationErrors(path, results)), nil
+}
+
+// hasValidationError reports whether any of results is an error rather than
+// a warning or information
+func hasValidationError(results []validator.ValidationResult) bool {
+	for _, r := range results {
+		if r.Severity == validator.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// validateParameters reports parameters in paramsPath that the template does
+// not declare, and required template parameters that paramsPath does not set
+func (v *azureValidator) validateParameters(ctx *Context, path, paramsPath string) (*Result, error) {
+	var templateJSON []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read template: %w", err)
+		}
+		templateJSON = data
+	} else {
+		builder := &azureBuilder{domain: v.domain}
+		result, err := builder.Build(ctx, path, BuildOpts{DryRun: true})
+		if err != nil {
+			return nil, err
+		}
+		if !result.Success {
+			return result, nil
+		}
+		templateJSON = []byte(result.Data.(string))
+	}
+
+	paramsJSON, err := os.ReadFile(paramsPath)
+	if err != nil {
+		return nil, fmt.Errorf("read parameters: %w", err)
+	}
+
+	results, err := validator.NewValidator().ValidateParameters(templateJSON, paramsJSON)
+	if err != nil {
+		return NewErrorResult("invalid parameters", Error{
+			Path:    paramsPath,
+			Message: err.Error(),
+		}), nil
+	}
+	if len(results) == 0 {
+		return NewResult("Parameters match the template"), nil
+	}
+
+	return NewErrorResultMultiple("parameters do not match the template", validationErrors(paramsPath, results)), nil
+}
+
+// validationErrors converts validator findings in the file path to domain errors
+func validationErrors(path string, results []validator.ValidationResult) []Error {
+	errs := make([]Error, len(results))
+	for i, r := range results {
+		errs[i] = Error{
+			Path:     path,
+			Severity: r.Severity.String(),
+			Message:  fmt.Sprintf("%s: %s", r.Field, r.Message),
+			Code:     r.Code,
+		}
+	}
+	return errs
+}
+
+// azureImporter implements domain.Importer
+type azureImporter struct {
+	domain *AzureDomain
+}
+
+// Import converts an ARM JSON template or, for .bicep files and with
+// FromBicep set, a Bicep file to Go code. The code is written to
+// <target>/<source name>.go, or returned as data if no target is given.
+// Unsupported Bicep constructs, including any that cause a resource to be
+// skipped, are reported as warnings and do not fail the import.
+func (i *azureImporter) Import(ctx *Context, source string, opts ImportOpts) (*Result, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("read source: %w", err)
+	}
+
+	fromBicep := strings.EqualFold(filepath.Ext(source), ".bicep")
+	if i.domain != nil && i.domain.FromBicep {
+		fromBicep = true
+	}
+
+	var armTemplate *importer.ARMTemplate
+	var warnings []Error
+	skipped := 0
+	if fromBicep {
+		var bicepWarnings []importer.BicepWarning
+		armTemplate, bicepWarnings, err = importer.ParseBicep(data)
+		for _, w := range bicepWarnings {
+			if w.Skipped {
+				skipped++
+			}
+			warnings = append(warnings, Error{
+				Path:     source,
+				Line:     w.Line,
+				Severity: "warning",
+				Message:  w.Message,
+			})
+		}
+	} else {
+		armTemplate, err = importer.ParseARMTemplate(data)
+	}
+	var templateErr *importer.TemplateError
+	if errors.As(err, &templateErr) {
+		// One error per invalid resource, so every problem is listed at once
+		errs := make([]Error, len(templateErr.Problems))
+		for i, p := range templateErr.Problems {
+			errs[i] = Error{
+				Path:    source,
+				Message: p.Error(),
+			}
+		}
+		return NewErrorResultMultiple("import failed", errs), nil
+	}
+	if err != nil {
+		return NewErrorResult("import failed", Error{
+			Path:    source,
+			Message: err.Error(),
+		}), nil
+	}
+
+	var genOpts importer.GenerateOptions
+	if i.domain != nil {
+		genOpts.Raw = i.domain.ImportRaw
+	}
+	code, err := importer.GenerateGoCodeWithOptions(armTemplate, "main", genOpts)
+	if err != nil {
+		return NewErrorResult("import failed", Error{
+			Path:    source,
+			Message: err.Error(),
+		}), nil
+	}
+
+	message := fmt.Sprintf("Imported %d resources", len(armTemplate.Resources))
+	if skipped > 0 {
+		message = fmt.Sprintf("%s, %d skipped", message, skipped)
+	}
+	var result *Result
+	if i.domain != nil && i.domain.ImportMerge != "" {
+		result, err = mergeImport(i.domain.ImportMerge, code)
+		if err != nil {
+			return nil, err
+		}
+	} else if opts.Target == "" {
+		result = NewResultWithData(message, code)
+	} else {
+		if err := os.MkdirAll(opts.Target, 0755); err != nil {
+			return nil, fmt.Errorf("create directory: %w", err)
+		}
+		base := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+		outPath := filepath.Join(opts.Target, base+".go")
+		if err := os.WriteFile(outPath, []byte(code), 0644); err != nil {
+			return nil, fmt.Errorf("write output: %w", err)
+		}
+		result = NewResult(fmt.Sprintf("%s to %s", message, outPath))
+	}
+	result.Errors = append(result.Errors, warnings...)
+	return result, nil
+}
+
+// mergeImport appends the resources in code that path does not already
+// declare. Resources that path declares differently are reported as errors
+// and the file is left unchanged.
+func mergeImport(path, code string) (*Result, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read merge target: %w", err)
+	}
+
+	merged, err := importer.MergeGoCode(existing, code)
+	if err != nil {
+		return NewErrorResult("import merge failed", Error{
+			Path:    path,
+			Message: err.Error(),
+		}), nil
+	}
+	if len(merged.Conflicts) > 0 {
+		errs := make([]Error, len(merged.Conflicts))
+		for i, c := range merged.Conflicts {
+			errs[i] = Error{
+				Path:     path,
+				Line:     c.Line,
+				Severity: "error",
+				Message:  c.Error(),
+			}
+		}
+		return NewErrorResultMultiple("import merge failed", errs), nil
+	}
+
+	if len(merged.Added) > 0 {
+		if err := os.WriteFile(path, merged.Source, 0644); err != nil {
+			return nil, fmt.Errorf("write output: %w", err)
+		}
+	}
+	return NewResultWithData(
+		fmt.Sprintf("Merged %d new resources into %s (%d already present)", len(merged.Added), path, len(merged.Unchanged)),
+		merged.Added,
+	), nil
+}
+
 // azureLister implements domain.Lister
-type azureLister struct{}
+type azureLister struct {
+	domain *AzureDomain
+}
 
 func (l *azureLister) List(ctx *Context, path string, opts ListOpts) (*Result, error) {
 	absPath, err := filepath.Abs(path)
@@ -315,27 +818,83 @@ func (l *azureLister) List(ctx *Context, path string, opts ListOpts) (*Result, e
 	}
 
 	// Discover all resources
-	resources, err := discover.DiscoverResources(absPath)
+	resources, err := discover.DiscoverResources(absPath, l.domain.excludes()...)
 	if err != nil {
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
 
+	if l.domain != nil && l.domain.ListDependsOn {
+		return NewResultWithData(fmt.Sprintf("Discovered %d resources", len(resources)), dependencyTrees(resources)), nil
+	}
+
 	// Build list
 	list := make([]map[string]string, 0, len(resources))
 	for _, res := range resources {
-		list = append(list, map[string]string{
+		entry := map[string]string{
 			"name": res.Name,
 			"type": res.Type,
 			"file": res.File,
 			"line": fmt.Sprintf("%d", res.Line),
-		})
+		}
+		if l.domain != nil && l.domain.ListEstimate {
+			entry["tier"] = estimate.Tier(res.Type, res.SKU)
+		}
+		list = append(list, entry)
 	}
 
 	return NewResultWithData(fmt.Sprintf("Discovered %d resources", len(list)), list), nil
 }
 
+// dependencyNode is a resource in list --depends-on output, with the
+// resources it depends on as children
+type dependencyNode struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Cycle marks a resource that is already one of its own ancestors; its
+	// dependencies are not expanded again
+	Cycle     bool              `json:"cycle,omitempty"`
+	DependsOn []*dependencyNode `json:"dependsOn,omitempty"`
+}
+
+// dependencyTrees returns the dependency tree of each resource, in discovery
+// order. Dependencies that are not discovered resources are left out.
+func dependencyTrees(resources []discover.DiscoveredResource) []*dependencyNode {
+	byName := make(map[string]discover.DiscoveredResource, len(resources))
+	for _, res := range resources {
+		byName[res.Name] = res
+	}
+
+	var expand func(res discover.DiscoveredResource, ancestors map[string]bool) *dependencyNode
+	expand = func(res discover.DiscoveredResource, ancestors map[string]bool) *dependencyNode {
+		node := &dependencyNode{Name: res.Name, Type: res.Type}
+		if ancestors[res.Name] {
+			node.Cycle = true
+			return node
+		}
+		ancestors[res.Name] = true
+		defer delete(ancestors, res.Name)
+
+		deps := append([]string(nil), res.Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if depRes, ok := byName[dep]; ok {
+				node.DependsOn = append(node.DependsOn, expand(depRes, ancestors))
+			}
+		}
+		return node
+	}
+
+	trees := make([]*dependencyNode, 0, len(resources))
+	for _, res := range resources {
+		trees = append(trees, expand(res, make(map[string]bool)))
+	}
+	return trees
+}
+
 // azureGrapher implements domain.Grapher
-type azureGrapher struct{}
+type azureGrapher struct {
+	domain *AzureDomain
+}
 
 func (g *azureGrapher) Graph(ctx *Context, path string, opts GraphOpts) (*Result, error) {
 	absPath, err := filepath.Abs(path)
@@ -349,13 +908,28 @@ func (g *azureGrapher) Graph(ctx *Context, path string, opts GraphOpts) (*Result
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
 
+	// Keep only what the roots reach, if any are given
+	if len(g.domain.GraphRoots) > 0 {
+		resources, err = reachableResources(resources, g.domain.GraphRoots, g.domain.GraphDepth)
+		if err != nil {
+			return nil, err
+		}
+	} else if g.domain.GraphDepth > 0 {
+		return nil, fmt.Errorf("depth requires roots")
+	}
+
 	// Generate graph
 	var graph string
 	switch opts.Format {
 	case "dot", "":
-		graph = generateDOTGraph(resources)
+		graph = generateDOTGraph(resources, g.domain.GraphGroupByFile)
 	case "mermaid":
-		graph = generateMermaidGraph(resources)
+		graph = generateMermaidGraph(resources, g.domain.GraphGroupByFile)
+	case "svg":
+		graph, err = renderSVG(generateDOTGraph(resources, g.domain.GraphGroupByFile))
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("unknown format: %s", opts.Format)
 	}
@@ -365,8 +939,10 @@ func (g *azureGrapher) Graph(ctx *Context, path string, opts GraphOpts) (*Result
 
 // Helper functions
 
-// generateDOTGraph generates a Graphviz DOT format graph
-func generateDOTGraph(resources []discover.DiscoveredResource) string {
+// generateDOTGraph generates a Graphviz DOT format graph. With groupByFile,
+// nodes are wrapped in a cluster per source file; edges are written after the
+// clusters so they may cross them.
+func generateDOTGraph(resources []discover.DiscoveredResource, groupByFile bool) string {
 	var sb strings.Builder
 
 	sb.WriteString("digraph \"Azure Resources\" {\n")
@@ -375,10 +951,30 @@ func generateDOTGraph(resources []discover.DiscoveredResource) string {
 	sb.WriteString("\n")
 
 	// Add nodes
-	for _, res := range resources {
+	writeNode := func(indent string, res discover.DiscoveredResource) {
 		// Escape quotes in labels
 		label := fmt.Sprintf("%s\\n%s", res.Name, res.Type)
-		sb.WriteString(fmt.Sprintf("  \"%s\" [label=\"%s\"];\n", res.Name, label))
+		// Geo-redundant resources get a suffix and a double border
+		attrs := ""
+		if redundancy := geoRedundancy(res); redundancy != "" {
+			label += fmt.Sprintf(" (%s)", redundancy)
+			attrs = ", peripheries=2"
+		}
+		sb.WriteString(fmt.Sprintf("%s\"%s\" [label=\"%s\"%s];\n", indent, res.Name, label, attrs))
+	}
+	if groupByFile {
+		for i, group := range groupResourcesByFile(resources) {
+			sb.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
+			sb.WriteString(fmt.Sprintf("    label=\"%s\";\n", filepath.Base(group.file)))
+			for _, res := range group.resources {
+				writeNode("    ", res)
+			}
+			sb.WriteString("  }\n")
+		}
+	} else {
+		for _, res := range resources {
+			writeNode("  ", res)
+		}
 	}
 
 	// Add edges (dependencies)
@@ -396,17 +992,34 @@ func generateDOTGraph(resources []discover.DiscoveredResource) string {
 	return sb.String()
 }
 
-// generateMermaidGraph generates a Mermaid format graph
-func generateMermaidGraph(resources []discover.DiscoveredResource) string {
+// generateMermaidGraph generates a Mermaid format graph. With groupByFile,
+// nodes are wrapped in a subgraph per source file.
+func generateMermaidGraph(resources []discover.DiscoveredResource, groupByFile bool) string {
 	var sb strings.Builder
 
 	sb.WriteString("graph TD\n")
 
 	// Add nodes
-	for _, res := range resources {
+	writeNode := func(indent string, res discover.DiscoveredResource) {
 		// Sanitize for Mermaid (replace spaces and special chars)
 		label := fmt.Sprintf("%s<br/>%s", res.Name, res.Type)
-		sb.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", res.Name, label))
+		if redundancy := geoRedundancy(res); redundancy != "" {
+			label += fmt.Sprintf(" (%s)", redundancy)
+		}
+		sb.WriteString(fmt.Sprintf("%s%s[\"%s\"]\n", indent, res.Name, label))
+	}
+	if groupByFile {
+		for i, group := range groupResourcesByFile(resources) {
+			sb.WriteString(fmt.Sprintf("  subgraph file_%d [\"%s\"]\n", i, filepath.Base(group.file)))
+			for _, res := range group.resources {
+				writeNode("    ", res)
+			}
+			sb.WriteString("  end\n")
+		}
+	} else {
+		for _, res := range resources {
+			writeNode("  ", res)
+		}
 	}
 
 	// Add edges (dependencies)
@@ -422,6 +1035,89 @@ func generateMermaidGraph(resources []discover.DiscoveredResource) string {
 	return sb.String()
 }
 
+// geoRedundancy returns the geo-replication of a resource's SKU (GRS, RAGRS,
+// GZRS or RAGZRS), or "" if the resource is not replicated to a secondary region
+func geoRedundancy(res discover.DiscoveredResource) string {
+	_, redundancy, ok := strings.Cut(res.SKU, "_")
+	if !ok {
+		return ""
+	}
+	switch redundancy {
+	case "GRS", "RAGRS", "GZRS", "RAGZRS":
+		return redundancy
+	}
+	return ""
+}
+
+// fileGroup is the resources declared in one source file
+type fileGroup struct {
+	file      string
+	resources []discover.DiscoveredResource
+}
+
+// groupResourcesByFile groups resources by file, in order of first appearance
+func groupResourcesByFile(resources []discover.DiscoveredResource) []fileGroup {
+	var groups []fileGroup
+	index := make(map[string]int)
+	for _, res := range resources {
+		i, ok := index[res.File]
+		if !ok {
+			i = len(groups)
+			index[res.File] = i
+			groups = append(groups, fileGroup{file: res.File})
+		}
+		groups[i].resources = append(groups[i].resources, res)
+	}
+	return groups
+}
+
+// reachableResources returns the resources reachable from roots by following
+// dependency edges, in discovery order. With depth > 0, only resources within
+// depth hops of a root are kept; a root is 0 hops from itself.
+func reachableResources(resources []discover.DiscoveredResource, roots []string, depth int) ([]discover.DiscoveredResource, error) {
+	byName := make(map[string]discover.DiscoveredResource, len(resources))
+	for _, res := range resources {
+		byName[res.Name] = res
+	}
+
+	hops := make(map[string]int, len(roots))
+	var queue []string
+	for _, root := range roots {
+		if _, ok := byName[root]; !ok {
+			return nil, fmt.Errorf("unknown root resource: %s", root)
+		}
+		if _, seen := hops[root]; !seen {
+			hops[root] = 0
+			queue = append(queue, root)
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if depth > 0 && hops[name] >= depth {
+			continue
+		}
+		for _, dep := range byName[name].Dependencies {
+			if _, ok := byName[dep]; !ok {
+				continue
+			}
+			if _, seen := hops[dep]; !seen {
+				hops[dep] = hops[name] + 1
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	reachable := make([]discover.DiscoveredResource, 0, len(hops))
+	for _, res := range resources {
+		if _, ok := hops[res.Name]; ok {
+			reachable = append(reachable, res)
+		}
+	}
+	return reachable, nil
+}
+
 // isResource checks if a name corresponds to a discovered resource
 func isResource(name string, resources []discover.DiscoveredResource) bool {
 	for _, res := range resources {
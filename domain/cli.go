@@ -0,0 +1,652 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/internal/lint"
+	"github.com/lex00/wetwire-azure-go/internal/template"
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/spf13/cobra"
+)
+
+// DiffExitCode is the exit status of diff --exit-code when the inputs
+// differ. It is distinct from the status 1 used for errors.
+const DiffExitCode = 3
+
+// ExitError signals that a command finished and the process should exit with
+// Code. The command has already written its output, so main should not print
+// anything further.
+type ExitError struct {
+	Code int
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExtendCommands adds Azure-specific flags and behavior to the commands
+// generated by wetwire-core-go. It must be called with the root command
+// returned by CreateRootCommand for the same domain.
+func ExtendCommands(root *cobra.Command, d *AzureDomain) {
+	for _, cmd := range root.Commands() {
+		switch cmd.Name() {
+		case "build":
+			extendBuildCmd(cmd, d)
+		case "lint":
+			extendLintCmd(cmd, d)
+		case "diff":
+			extendDiffCmd(cmd, d)
+		case "import":
+			extendImportCmd(cmd, d)
+		case "init":
+			extendInitCmd(cmd, d)
+		case "graph":
+			extendGraphCmd(cmd, d)
+		case "list":
+			extendListCmd(cmd, d)
+		case "validate":
+			extendValidateCmd(cmd, d)
+		}
+	}
+}
+
+// extendBuildCmd adds Azure build flags, bound to fields on d, and writes the
+// result to the command's output.
+func extendBuildCmd(cmd *cobra.Command, d *AzureDomain) {
+	var pretty, includeEmpty bool
+
+	cmd.Flags().StringVar(&d.Scope, "scope", string(template.ScopeResourceGroup),
+		"Deployment scope (resourceGroup, subscription, managementGroup, tenant)")
+	cmd.Flags().StringVar(&d.MinAPIVersion, "min-api-version", "",
+		"Reject resources whose explicit APIVersion is older than this (e.g. 2021-01-01)")
+	cmd.Flags().IntVar(&d.BuildMaxResources, "max-resources", template.MaxDeploymentResources,
+		"Fail if the template has more resources than this, counting copy loop instances (0 for no limit)")
+	cmd.Flags().BoolVar(&pretty, "pretty", true,
+		"Indent the generated JSON; --pretty=false emits compact single-line JSON")
+	cmd.Flags().BoolVar(&d.SortKeys, "sort-keys", false,
+		"Sort the keys of every JSON object, including $schema and the resource fields, instead of keeping ARM's conventional order")
+	cmd.Flags().BoolVar(&includeEmpty, "include-empty-sections", true,
+		"Emit empty parameters, variables, and outputs sections; --include-empty-sections=false omits them")
+	cmd.Flags().StringToStringVar(&d.Metadata, "metadata", nil,
+		"Add entries to the template metadata (e.g. author=team,description=...)")
+	cmd.Flags().StringVar(&d.BuildMerge, "merge", "",
+		"Merge the generated resources into this existing ARM template")
+	cmd.Flags().StringVar(&d.BuildSubscription, "subscription", "",
+		"Subscription ID replacing the {sub} placeholders of the resource IDs build writes: scopes and the properties of expanded and template.RawResource resources (typed resource properties are not written)")
+	cmd.Flags().StringVar(&d.BuildResourceGroup, "resource-group", "",
+		"Resource group name replacing the {rg} placeholders of the resource IDs build writes, like --subscription")
+	cmd.Flags().StringVar(&d.Profile, "profile", "",
+		"Write a profile of discovery and template generation (cpu=<file>)")
+	cmd.Flags().BoolVar(&d.BuildCountOnly, "count-only", false,
+		"Only discover resources and print how many there are; fails on discovery errors or no resources")
+	cmd.Flags().BoolVar(&d.BuildAllowEmpty, "allow-empty", false,
+		"With --count-only, succeed when no resources are found")
+	cmd.Flags().BoolVar(&d.CheckGlobalNames, "check-names-global", false,
+		"Warn on stderr about hardcoded names of resources whose names must be unique across Azure (WAZ402)")
+	cmd.Flags().BoolVar(&d.BuildTrace, "trace", false,
+		"Log to stderr whether each package-level var was discovered as a resource, and why not (also WETWIRE_DEBUG)")
+	addExcludeFlag(cmd, d)
+
+	// d.Compact and d.OmitEmptySections are the inverses of --pretty and
+	// --include-empty-sections, so they are set once flags are parsed
+	cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		d.Compact = !pretty
+		d.OmitEmptySections = !includeEmpty
+	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	// The template is written to the command's output rather than os.Stdout;
+	// --output writes it to a file instead, or to stdout when it is "-"
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		format, _ := cmd.Flags().GetString("format")
+		buildType, _ := cmd.Flags().GetString("type")
+		output, _ := cmd.Flags().GetString("output")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if d.BuildTrace || os.Getenv("WETWIRE_DEBUG") != "" {
+			if err := discover.TraceResources(path, cmd.ErrOrStderr(), d.Exclude...); err != nil {
+				return err
+			}
+		}
+
+		if d.CheckGlobalNames {
+			if err := writeGlobalNameWarnings(cmd.ErrOrStderr(), path, d.Exclude); err != nil {
+				return err
+			}
+		}
+
+		ctx := NewContextWithVerbose(context.Background(), path, verbose)
+		result, err := d.Builder().Build(ctx, path, BuildOpts{
+			Format: format,
+			Type:   buildType,
+			Output: output,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+		return writeResult(cmd.OutOrStdout(), result, format)
+	}
+}
+
+// writeGlobalNameWarnings writes a line to w for each hardcoded name of a
+// resource whose name must be unique across Azure in path (see lint rule
+// WAZ402). The warnings do not fail the build.
+func writeGlobalNameWarnings(w io.Writer, path string, exclude []string) error {
+	linter := lint.NewLinterWithOptions(lint.Options{OnlyRules: []string{"WAZ402"}})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	var results []lint.LintResult
+	if info.IsDir() {
+		results, err = linter.CheckDirectory(path, exclude...)
+	} else {
+		results, err = linter.CheckFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(w, "%s:%d: warning: %s (%s)\n", r.File, r.Line, r.Message, r.Rule)
+	}
+	return nil
+}
+
+// extendInitCmd adds the starter template flags, bound to fields on d, and
+// --wizard, which prompts for them on stdin. When stdin is not a terminal the
+// wizard is skipped so scripted runs never block on input.
+func extendInitCmd(cmd *cobra.Command, d *AzureDomain) {
+	var wizard bool
+
+	cmd.Flags().StringVar(&d.InitModule, "module", "", "Go module name (default: the directory name)")
+	cmd.Flags().StringVar(&d.InitLocation, "location", DefaultInitLocation, "Azure region used in the starter template")
+	cmd.Flags().StringVar(&d.InitTemplate, "template", DefaultInitTemplate,
+		fmt.Sprintf("Starter template (%s)", strings.Join(starterTemplateNames(), ", ")))
+	cmd.Flags().BoolVar(&wizard, "wizard", false, "Prompt for the module name, location, and starter template")
+
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if wizard {
+			in, ok := cmd.InOrStdin().(*os.File)
+			if !ok || !isTerminal(in) {
+				fmt.Fprintln(cmd.ErrOrStderr(), "init --wizard: stdin is not a terminal; using flags and defaults")
+			} else {
+				path, _ := cmd.Flags().GetString("path")
+				module := d.InitModule
+				if module == "" {
+					module = defaultModuleName(path)
+				}
+
+				opts, err := runInitWizard(in, cmd.OutOrStdout(), initOptions{
+					Module:   module,
+					Location: d.InitLocation,
+					Template: d.InitTemplate,
+				})
+				if err != nil {
+					return err
+				}
+				d.InitModule, d.InitLocation, d.InitTemplate = opts.Module, opts.Location, opts.Template
+			}
+		}
+
+		_, err := lookupStarterTemplate(d.InitTemplate)
+		return err
+	}
+}
+
+// extendImportCmd adds the --from-bicep, --merge and --raw flags, bound to
+// d.FromBicep, d.ImportMerge and d.ImportRaw.
+func extendImportCmd(cmd *cobra.Command, d *AzureDomain) {
+	cmd.Flags().BoolVar(&d.FromBicep, "from-bicep", false,
+		"Parse the source as Bicep (implied by a .bicep extension)")
+	cmd.Flags().StringVar(&d.ImportMerge, "merge", "",
+		"Append resources not already declared to this existing Go file")
+	cmd.Flags().BoolVar(&d.ImportRaw, "raw", false,
+		"Declare every resource as a template.RawResource with generic maps instead of its typed struct")
+}
+
+// addExcludeFlag adds the --exclude flag, bound to d.Exclude.
+func addExcludeFlag(cmd *cobra.Command, d *AzureDomain) {
+	cmd.Flags().StringSliceVar(&d.Exclude, "exclude", nil,
+		"Skip files and directories matching these globs (e.g. 'generated_*.go,testdata/**')")
+}
+
+// extendListCmd adds the --depends-on and --exclude flags, bound to
+// d.ListDependsOn and d.Exclude, and prints the dependency trees as indented
+// text for the text format.
+func extendListCmd(cmd *cobra.Command, d *AzureDomain) {
+	cmd.Flags().BoolVar(&d.ListDependsOn, "depends-on", false,
+		"Show the resolved dependency tree of each resource, marking cycles")
+	cmd.Flags().BoolVar(&d.ListEstimate, "estimate", false,
+		"Annotate each resource with a rough relative cost tier (free, low, medium, high)")
+	addExcludeFlag(cmd, d)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		format, _ := cmd.Flags().GetString("format")
+		listType, _ := cmd.Flags().GetString("type")
+
+		ctx := NewContextWithVerbose(context.Background(), path, verbose)
+		result, err := d.Lister().List(ctx, path, ListOpts{Format: format, Type: listType})
+		if err != nil {
+			return fmt.Errorf("list failed: %w", err)
+		}
+
+		w := cmd.OutOrStdout()
+		if trees, ok := result.Data.([]*dependencyNode); ok && (format == "text" || format == "") {
+			fmt.Fprintf(w, "✓ Success: %s\n\n", result.Message)
+			writeDependencyTrees(w, trees)
+			return nil
+		}
+		output, err := coredomain.FormatResult(result, format)
+		if err != nil {
+			return fmt.Errorf("failed to format result: %w", err)
+		}
+		fmt.Fprint(w, output)
+		if !result.Success {
+			return &ExitError{Code: 1}
+		}
+		return nil
+	}
+}
+
+// writeDependencyTrees writes dependency trees as indented text, one tree per
+// resource, marking dependencies that close a cycle.
+func writeDependencyTrees(w io.Writer, trees []*dependencyNode) {
+	var writeChildren func(children []*dependencyNode, prefix string)
+	writeChildren = func(children []*dependencyNode, prefix string) {
+		for i, child := range children {
+			branch, indent := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, indent = "└── ", "    "
+			}
+			fmt.Fprintf(w, "%s%s%s\n", prefix, branch, dependencyLabel(child))
+			writeChildren(child.DependsOn, prefix+indent)
+		}
+	}
+
+	for _, tree := range trees {
+		fmt.Fprintln(w, dependencyLabel(tree))
+		writeChildren(tree.DependsOn, "")
+	}
+}
+
+// dependencyLabel returns the text shown for a node in a dependency tree
+func dependencyLabel(node *dependencyNode) string {
+	label := fmt.Sprintf("%s (%s)", node.Name, node.Type)
+	if node.Cycle {
+		label += " [cycle]"
+	}
+	return label
+}
+
+// extendGraphCmd adds the --group-by-file, --roots, and --depth flags, bound
+// to d.GraphGroupByFile, d.GraphRoots, and d.GraphDepth, and writes the graph
+// in DOT, Mermaid (-f mermaid), or SVG rendered by Graphviz (-f svg) to the
+// command's output.
+func extendGraphCmd(cmd *cobra.Command, d *AzureDomain) {
+	cmd.Flags().BoolVar(&d.GraphGroupByFile, "group-by-file", false,
+		"Cluster resources by the source file that declares them")
+	cmd.Flags().StringSliceVar(&d.GraphRoots, "roots", nil,
+		"Only graph these resources and their dependencies (comma-separated)")
+	cmd.Flags().IntVar(&d.GraphDepth, "depth", 0,
+		"With --roots, limit the graph to this many dependency hops (0 for no limit)")
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		format, _ := cmd.Flags().GetString("format")
+		if format == "text" {
+			// The root --format default; graphs are DOT unless -f mermaid or svg
+			format = "dot"
+		}
+
+		ctx := NewContextWithVerbose(context.Background(), path, verbose)
+		result, err := d.Grapher().Graph(ctx, path, GraphOpts{Format: format})
+		if err != nil {
+			return fmt.Errorf("graph failed: %w", err)
+		}
+
+		// The graph is written as is, so it can be piped to dot or saved as an SVG file
+		if graph, ok := result.Data.(string); ok && result.Success {
+			fmt.Fprint(cmd.OutOrStdout(), graph)
+			return nil
+		}
+		return writeResult(cmd.OutOrStdout(), result, "text")
+	}
+}
+
+// extendValidateCmd adds the --parameters flag, bound to d.ValidateParameters,
+// and --count, which ends text output with the number of findings per rule.
+func extendValidateCmd(cmd *cobra.Command, d *AzureDomain) {
+	var count bool
+
+	cmd.Flags().StringVar(&d.ValidateParameters, "parameters", "",
+		"Check this ARM parameters file against the template instead of linting")
+	cmd.Flags().BoolVar(&count, "count", false,
+		"Summarize findings by rule, most frequent first")
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		format, _ := cmd.Flags().GetString("format")
+
+		ctx := NewContextWithVerbose(context.Background(), path, verbose)
+		result, err := d.Validator().Validate(ctx, path, ValidateOpts{})
+		if err != nil {
+			return fmt.Errorf("validate failed: %w", err)
+		}
+
+		w := cmd.OutOrStdout()
+		output, err := coredomain.FormatResult(result, format)
+		if err != nil {
+			return fmt.Errorf("failed to format result: %w", err)
+		}
+		fmt.Fprint(w, output)
+		if count && (format == "text" || format == "") {
+			writeCodeCounts(w, result.Errors)
+		}
+
+		if !result.Success {
+			return &ExitError{Code: 1}
+		}
+		return nil
+	}
+}
+
+// codeCount is the number of errors reported with a code
+type codeCount struct {
+	Code  string
+	Count int
+}
+
+// countByCode counts errs by code, most frequent first and then by code.
+// Errors without a code are counted under "(none)".
+func countByCode(errs []Error) []codeCount {
+	index := make(map[string]int)
+	var counts []codeCount
+	for _, e := range errs {
+		code := e.Code
+		if code == "" {
+			code = "(none)"
+		}
+		i, ok := index[code]
+		if !ok {
+			i = len(counts)
+			index[code] = i
+			counts = append(counts, codeCount{Code: code})
+		}
+		counts[i].Count++
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Code < counts[j].Code
+	})
+	return counts
+}
+
+// writeCodeCounts writes the number of errors per code, aligned in columns
+func writeCodeCounts(w io.Writer, errs []Error) {
+	counts := countByCode(errs)
+	if len(counts) == 0 {
+		return
+	}
+	width := 0
+	for _, c := range counts {
+		width = max(width, len(c.Code))
+	}
+	fmt.Fprint(w, "\nBy rule:\n")
+	for _, c := range counts {
+		fmt.Fprintf(w, "  %-*s  %d\n", width, c.Code, c.Count)
+	}
+}
+
+// extendLintCmd colorizes text output by severity when writing to a terminal,
+// adds --format sarif for code scanning, and adds the --only and --exclude
+// flags, bound to d.OnlyRules and d.Exclude.
+func extendLintCmd(cmd *cobra.Command, d *AzureDomain) {
+	var noColor bool
+
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	cmd.Flags().StringSliceVar(&d.OnlyRules, "only", nil,
+		"Run only these rules (e.g. WAZ301,WAZ306); unknown IDs are warned about")
+	cmd.Flags().BoolVar(&d.CheckGlobalNames, "check-names-global", false,
+		"Also run WAZ402, which flags hardcoded names of resources whose names must be unique across Azure")
+	addExcludeFlag(cmd, d)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		format, _ := cmd.Flags().GetString("format")
+		fix, _ := cmd.Flags().GetBool("fix")
+		disable, _ := cmd.Flags().GetStringSlice("disable")
+
+		ctx := NewContextWithVerbose(context.Background(), path, verbose)
+		result, err := d.Linter().Lint(ctx, path, LintOpts{
+			Format:  format,
+			Fix:     fix,
+			Disable: disable,
+		})
+		if err != nil {
+			return fmt.Errorf("lint failed: %w", err)
+		}
+
+		w := cmd.OutOrStdout()
+		switch format {
+		case "text", "":
+			writeLintResult(w, result, !noColor && isTerminal(w))
+		case "sarif":
+			if err := writeLintSARIF(w, result); err != nil {
+				return err
+			}
+		default:
+			output, err := coredomain.FormatResult(result, format)
+			if err != nil {
+				return fmt.Errorf("failed to format result: %w", err)
+			}
+			fmt.Fprint(w, output)
+		}
+
+		if !result.Success {
+			return &ExitError{Code: 1}
+		}
+		return nil
+	}
+}
+
+// writeLintResult writes a lint result in the core text format, coloring
+// each issue by severity when color is true.
+func writeLintResult(w io.Writer, result *Result, color bool) {
+	if result.Success {
+		fmt.Fprint(w, colorize("✓ Success", colorGreen, color))
+	} else {
+		fmt.Fprint(w, colorize("✗ Failed", colorRed, color))
+	}
+	if result.Message != "" {
+		fmt.Fprintf(w, ": %s", result.Message)
+	}
+	fmt.Fprintln(w)
+
+	if len(result.Errors) > 0 {
+		fmt.Fprint(w, "\nErrors:\n")
+		for i, e := range result.Errors {
+			fmt.Fprintf(w, "  %d. %s\n", i+1, colorize(e.String(), severityColor(e.Severity), color))
+		}
+	}
+}
+
+// writeLintSARIF writes the lint issues in a result as a SARIF 2.1.0 log,
+// with file paths relative to the working directory. Messages that are not
+// tied to a rule, such as unknown --only IDs, are left out.
+func writeLintSARIF(w io.Writer, result *Result) error {
+	var issues []lint.LintResult
+	for _, e := range result.Errors {
+		if e.Code == "" {
+			continue
+		}
+		issues = append(issues, lint.LintResult{
+			Rule:     e.Code,
+			File:     e.Path,
+			Line:     e.Line,
+			Column:   e.Column,
+			Message:  e.Message,
+			Severity: parseSeverity(e.Severity),
+		})
+	}
+
+	baseDir, _ := os.Getwd()
+	data, err := json.MarshalIndent(lint.NewSARIFLog(issues, Version, baseDir), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format result: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// parseSeverity converts a domain error severity back to a lint severity
+func parseSeverity(severity string) lint.Severity {
+	switch severity {
+	case "error":
+		return lint.SeverityError
+	case "warning":
+		return lint.SeverityWarning
+	default:
+		return lint.SeverityInfo
+	}
+}
+
+// extendDiffCmd replaces the generic diff output with one that understands
+// Azure-specific entries such as renamed resources.
+func extendDiffCmd(cmd *cobra.Command, d *AzureDomain) {
+	var summary, exitCode bool
+
+	cmd.Flags().BoolVar(&summary, "summary", false, "Print only the summary counts")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false,
+		fmt.Sprintf("Exit with status %d if there are differences and 0 otherwise", DiffExitCode))
+	cmd.Flags().StringSliceVar(&d.DiffIgnorePaths, "ignore-paths", nil,
+		"Template paths to leave out of the comparison, with * matching any key or index (e.g. resources.*.properties.provisioningState)")
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		file1, file2 := args[0], args[1]
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		format, _ := cmd.Flags().GetString("format")
+		ignoreOrder, _ := cmd.Flags().GetBool("ignore-order")
+
+		ctx := NewContextWithVerbose(context.Background(), ".", verbose)
+		result, err := d.Differ().Diff(ctx, file1, file2, DiffOpts{IgnoreOrder: ignoreOrder})
+		if err != nil {
+			return fmt.Errorf("diff failed: %w", err)
+		}
+
+		if err := writeDiffResult(cmd.OutOrStdout(), result, format, file1, file2, summary); err != nil {
+			return err
+		}
+
+		if result.Summary.Total > 0 {
+			if exitCode {
+				return &ExitError{Code: DiffExitCode}
+			}
+			return &ExitError{Code: 1}
+		}
+		return nil
+	}
+}
+
+// writeDiffResult writes a diff result in text or JSON form.
+func writeDiffResult(w io.Writer, result *DiffResult, format, file1, file2 string, summaryOnly bool) error {
+	if format == "json" {
+		output, err := coredomain.FormatDiffResult(result, format)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, output)
+		return nil
+	}
+
+	if result.Summary.Total == 0 {
+		fmt.Fprintf(w, "No differences between %s and %s\n", file1, file2)
+		return nil
+	}
+
+	renamed := 0
+	for _, entry := range result.Entries {
+		if entry.Action == "renamed" {
+			renamed++
+		}
+	}
+
+	if !summaryOnly {
+		fmt.Fprintf(w, "Comparing %s vs %s\n\n", file1, file2)
+
+		for _, entry := range result.Entries {
+			switch entry.Action {
+			case "added":
+				fmt.Fprintf(w, "  + %s (%s)\n", entry.Resource, entry.Type)
+			case "removed":
+				fmt.Fprintf(w, "  - %s (%s)\n", entry.Resource, entry.Type)
+			case "renamed":
+				fmt.Fprintf(w, "  > %s (%s)\n", entry.Resource, entry.Type)
+				for _, change := range entry.Changes {
+					fmt.Fprintf(w, "      %s\n", change)
+				}
+			case "modified":
+				fmt.Fprintf(w, "  ~ %s (%s)\n", entry.Resource, entry.Type)
+				for _, change := range entry.Changes {
+					fmt.Fprintf(w, "      %s\n", change)
+				}
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Summary: %d added, %d removed, %d modified, %d renamed\n",
+		result.Summary.Added, result.Summary.Removed, result.Summary.Modified, renamed)
+	return nil
+}
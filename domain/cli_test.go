@@ -0,0 +1,1000 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWriteDiffResult_Renamed tests that renamed entries appear in text output
+func TestWriteDiffResult_Renamed(t *testing.T) {
+	result := &DiffResult{
+		Entries: []DiffEntry{
+			{
+				Resource: "newstorage",
+				Type:     "Microsoft.Storage/storageAccounts",
+				Action:   "renamed",
+				Changes:  []string{"renamed from oldstorage"},
+			},
+		},
+		Summary: DiffSummary{Total: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDiffResult(&buf, result, "text", "a.json", "b.json", false); err != nil {
+		t.Fatalf("writeDiffResult() error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "> newstorage (Microsoft.Storage/storageAccounts)") {
+		t.Errorf("expected renamed entry in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "renamed from oldstorage") {
+		t.Errorf("expected rename detail in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "0 added, 0 removed, 0 modified, 1 renamed") {
+		t.Errorf("expected summary with renamed count, got:\n%s", out)
+	}
+}
+
+// TestWriteDiffResult_SummaryOnly tests that --summary omits individual entries
+func TestWriteDiffResult_SummaryOnly(t *testing.T) {
+	result := &DiffResult{
+		Entries: []DiffEntry{
+			{Resource: "storage2", Type: "Microsoft.Storage/storageAccounts", Action: "added"},
+		},
+		Summary: DiffSummary{Added: 1, Total: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDiffResult(&buf, result, "text", "a.json", "b.json", true); err != nil {
+		t.Fatalf("writeDiffResult() error: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, "storage2") {
+		t.Errorf("summary output should not list entries, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Summary: 1 added") {
+		t.Errorf("expected summary line, got:\n%s", out)
+	}
+}
+
+// TestWriteLintResult_Color tests that severity colors appear only when color is enabled
+func TestWriteLintResult_Color(t *testing.T) {
+	result := NewErrorResultMultiple("lint issues found", []Error{
+		{Path: "main.go", Line: 3, Severity: "error", Message: "bad", Code: "WAZ006"},
+		{Path: "main.go", Line: 5, Severity: "warning", Message: "meh", Code: "WAZ001"},
+		{Path: "main.go", Line: 7, Severity: "info", Message: "fyi", Code: "WAZ020"},
+	})
+
+	var colored bytes.Buffer
+	writeLintResult(&colored, result, true)
+	out := colored.String()
+	for _, want := range []string{
+		colorRed + "main.go:3 [error]: bad (WAZ006)" + colorReset,
+		colorYellow + "main.go:5 [warning]: meh (WAZ001)" + colorReset,
+		colorCyan + "main.go:7 [info]: fyi (WAZ020)" + colorReset,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in colored output, got:\n%s", want, out)
+		}
+	}
+
+	var plain bytes.Buffer
+	writeLintResult(&plain, result, false)
+	if strings.Contains(plain.String(), "\033[") {
+		t.Errorf("expected no ANSI codes in plain output, got:\n%q", plain.String())
+	}
+	if !strings.Contains(plain.String(), "1. main.go:3 [error]: bad (WAZ006)") {
+		t.Errorf("expected plain issue line, got:\n%s", plain.String())
+	}
+}
+
+// TestIsTerminal tests that buffers and regular files are not treated as terminals
+func TestIsTerminal(t *testing.T) {
+	if isTerminal(&bytes.Buffer{}) {
+		t.Error("bytes.Buffer should not be a terminal")
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("regular file should not be a terminal")
+	}
+}
+
+// runDiffCmd runs the extended diff command with args and returns its exit status
+func runDiffCmd(t *testing.T, args ...string) int {
+	t.Helper()
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(append([]string{"diff"}, args...))
+
+	err := root.Execute()
+	if err == nil {
+		return 0
+	}
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("diff %v error: %v", args, err)
+	}
+	return exitErr.Code
+}
+
+// TestDiffCmd_ExitCode tests the exit status of diff with and without --exit-code
+func TestDiffCmd_ExitCode(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	write := func(name, storageName string) string {
+		path := filepath.Join(tmpDir, name)
+		template := `{"resources": [{"type": "Microsoft.Storage/storageAccounts", "name": "` + storageName + `", "location": "eastus"}]}`
+		if err := os.WriteFile(path, []byte(template), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := write("a.json", "storage1")
+	same := write("same.json", "storage1")
+	b := write("b.json", "storage2")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"identical", []string{a, same}, 0},
+		{"identical with --exit-code", []string{"--exit-code", a, same}, 0},
+		{"differing", []string{a, b}, 1},
+		{"differing with --exit-code", []string{"--exit-code", a, b}, DiffExitCode},
+		{"differing with --exit-code --summary", []string{"--exit-code", "--summary", a, b}, DiffExitCode},
+		{"differing with --exit-code json", []string{"--exit-code", "--format", "json", a, b}, DiffExitCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runDiffCmd(t, tt.args...); got != tt.want {
+				t.Errorf("exit status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiffCmd_IgnorePaths tests that --ignore-paths leaves volatile fields out of the comparison
+func TestDiffCmd_IgnorePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	write := func(name, state string) string {
+		path := filepath.Join(tmpDir, name)
+		template := `{"resources": [{"type": "Microsoft.Storage/storageAccounts", "name": "storage1", "properties": {"provisioningState": "` + state + `"}}]}`
+		if err := os.WriteFile(path, []byte(template), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := write("a.json", "Creating")
+	b := write("b.json", "Succeeded")
+
+	if got := runDiffCmd(t, "--exit-code", a, b); got != DiffExitCode {
+		t.Errorf("exit status without --ignore-paths = %d, want %d", got, DiffExitCode)
+	}
+	if got := runDiffCmd(t, "--exit-code", "--ignore-paths", "resources.*.properties.provisioningState", a, b); got != 0 {
+		t.Errorf("exit status with --ignore-paths = %d, want 0", got)
+	}
+}
+
+// TestBuildCmd_Pretty tests that --pretty=false emits compact JSON equivalent to the default output
+func TestBuildCmd_Pretty(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorage",
+	Location: "[resourceGroup().location]",
+}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	build := func(args ...string) string {
+		t.Helper()
+		output := filepath.Join(t.TempDir(), "template.json")
+
+		d := &AzureDomain{}
+		root := CreateRootCommand(d)
+		ExtendCommands(root, d)
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(append([]string{"build", srcDir, "-o", output}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("build %v error: %v\n%s", args, err, out.String())
+		}
+
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	pretty := build()
+	compact := build("--pretty=false")
+
+	if !strings.Contains(pretty, "\n") {
+		t.Error("Expected default output to be indented")
+	}
+	if strings.Contains(compact, "\n") {
+		t.Errorf("Expected --pretty=false output on a single line, got:\n%s", compact)
+	}
+	if !strings.Contains(compact, `"[resourceGroup().location]"`) {
+		t.Errorf("Expected location expression to be intact, got: %s", compact)
+	}
+
+	var prettyTemplate, compactTemplate map[string]interface{}
+	if err := json.Unmarshal([]byte(pretty), &prettyTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(compact), &compactTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(prettyTemplate, compactTemplate) {
+		t.Errorf("Expected equivalent templates:\npretty:  %v\ncompact: %v", prettyTemplate, compactTemplate)
+	}
+}
+
+// TestBuildCmd_Stdout tests that build writes the template to the command's
+// output without --output and with --output -
+func TestBuildCmd_Stdout(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{nil, {"-o", "-"}} {
+		d := &AzureDomain{}
+		root := CreateRootCommand(d)
+		ExtendCommands(root, d)
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(append([]string{"build", srcDir}, args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("build %v error: %v\n%s", args, err, out.String())
+		}
+
+		if !strings.Contains(out.String(), "Microsoft.Storage/storageAccounts") {
+			t.Errorf("build %v: expected the template in the command output, got:\n%s", args, out.String())
+		}
+		if _, err := os.Stat("-"); err == nil {
+			os.Remove("-")
+			t.Errorf("build %v: wrote a file named -", args)
+		}
+	}
+}
+
+// TestLintCmd_Only tests that --only runs just the listed rules and warns about unknown IDs
+func TestLintCmd_Only(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorage",
+	Location: "East US",
+}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"lint", srcDir, "--no-color", "--only", "WAZ301,WAZ306"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("lint --only error: %v\n%s", err, out.String())
+	}
+
+	if !reflect.DeepEqual(d.OnlyRules, []string{"WAZ301", "WAZ306"}) {
+		t.Errorf("OnlyRules = %v, want [WAZ301 WAZ306]", d.OnlyRules)
+	}
+	if strings.Contains(out.String(), "WAZ001") {
+		t.Errorf("Expected WAZ001 not to run, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "unknown rule WAZ306") {
+		t.Errorf("Expected a warning about WAZ306, got:\n%s", out.String())
+	}
+}
+
+// TestCmds_CheckNamesGlobal tests that --check-names-global makes lint report
+// WAZ402 and build warn on stderr about hardcoded global names, and that
+// generated names pass
+func TestCmds_CheckNamesGlobal(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/naming"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Static = storage.StorageAccount{Name: "corplogs", Location: "eastus"}
+
+var Generated = storage.StorageAccount{
+	Name:     naming.Unique("data").For("Microsoft.Storage/storageAccounts").String(),
+	Location: "eastus",
+}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (stdout, stderr string, err error) {
+		d := &AzureDomain{}
+		root := CreateRootCommand(d)
+		ExtendCommands(root, d)
+		var out, errOut bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&errOut)
+		root.SetArgs(args)
+		err = root.Execute()
+		return out.String(), errOut.String(), err
+	}
+
+	out, _, _ := run("lint", srcDir, "--no-color")
+	if strings.Contains(out, "WAZ402") {
+		t.Errorf("Expected WAZ402 to be off by default, got:\n%s", out)
+	}
+	out, _, _ = run("lint", srcDir, "--no-color", "--check-names-global")
+	if strings.Count(out, "WAZ402") != 1 || !strings.Contains(out, `name "corplogs" is hardcoded`) {
+		t.Errorf("Expected one WAZ402 issue for corplogs, got:\n%s", out)
+	}
+
+	out, errOut, err := run("build", srcDir, "--check-names-global")
+	if err != nil {
+		t.Fatalf("build --check-names-global error: %v\n%s", err, errOut)
+	}
+	if strings.Count(errOut, "warning:") != 1 || !strings.Contains(errOut, "main.go:8: warning: Microsoft.Storage/storageAccounts name \"corplogs\" is hardcoded") {
+		t.Errorf("Expected one warning for corplogs on stderr, got:\n%s", errOut)
+	}
+	if strings.Contains(out, "WAZ402") || !strings.Contains(out, "$schema") {
+		t.Errorf("Expected only the template on stdout, got:\n%s", out)
+	}
+}
+
+// TestCmds_Exclude tests that build, lint, and list skip files matching --exclude
+func TestCmds_Exclude(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var %s = storage.StorageAccount{
+	Name:     "%s",
+	Location: "%s",
+}
+`
+	files := map[string]string{
+		"main.go":              fmt.Sprintf(code, "AppStorage", "appstorage", "eastus"),
+		"generated_storage.go": fmt.Sprintf(code, "GeneratedStorage", "generatedstorage", "East US"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"build", srcDir},
+		{"lint", srcDir, "--no-color", "--only", "WAZ001"},
+		{"list", srcDir},
+	} {
+		t.Run(args[0], func(t *testing.T) {
+			d := &AzureDomain{}
+			root := CreateRootCommand(d)
+			ExtendCommands(root, d)
+			var out bytes.Buffer
+			root.SetOut(&out)
+			root.SetErr(&out)
+			root.SetArgs(append(args, "--exclude", "generated_*.go"))
+			if err := root.Execute(); err != nil {
+				t.Fatalf("%s --exclude error: %v\n%s", args[0], err, out.String())
+			}
+			if strings.Contains(out.String(), "GeneratedStorage") || strings.Contains(out.String(), "generated_storage.go") {
+				t.Errorf("Expected the excluded file to be skipped, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+// TestLintCmd_SARIF tests that --format sarif emits a SARIF log of the lint issues
+func TestLintCmd_SARIF(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorage",
+	Location: "East US",
+}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"lint", srcDir, "--format", "sarif"})
+
+	var exitErr *ExitError
+	if err := root.Execute(); !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("Expected exit status 1, got %v\n%s", err, out.String())
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("Expected SARIF JSON, got %v:\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %s", out.String())
+	}
+	if len(log.Runs[0].Tool.Driver.Rules) == 0 {
+		t.Error("Expected a rules section")
+	}
+
+	found := false
+	for _, r := range log.Runs[0].Results {
+		if r.RuleID == "WAZ001" {
+			found = true
+			if len(r.Locations) != 1 || !strings.HasSuffix(r.Locations[0].PhysicalLocation.ArtifactLocation.URI, "main.go") {
+				t.Errorf("Expected a location in main.go, got %+v", r.Locations)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected a WAZ001 result, got:\n%s", out.String())
+	}
+}
+
+// TestGraphCmd_GroupByFileFlag tests that --group-by-file sets GraphGroupByFile
+func TestGraphCmd_GroupByFileFlag(t *testing.T) {
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+
+	cmd, _, err := root.Find([]string{"graph"})
+	if err != nil {
+		t.Fatalf("graph command not found: %v", err)
+	}
+	if err := cmd.ParseFlags([]string{"--group-by-file"}); err != nil {
+		t.Fatalf("ParseFlags() error: %v", err)
+	}
+	if !d.GraphGroupByFile {
+		t.Error("Expected GraphGroupByFile to be set")
+	}
+}
+
+// TestGraphCmd_Output tests that graph writes the graph itself to the
+// command's output, in DOT by default
+func TestGraphCmd_Output(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		args   []string
+		prefix string
+	}{
+		{nil, "digraph"},
+		{[]string{"-f", "mermaid"}, "graph TD"},
+	} {
+		d := &AzureDomain{}
+		root := CreateRootCommand(d)
+		ExtendCommands(root, d)
+		var out bytes.Buffer
+		root.SetOut(&out)
+		root.SetErr(&out)
+		root.SetArgs(append([]string{"graph", srcDir}, tt.args...))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("graph %v error: %v\n%s", tt.args, err, out.String())
+		}
+
+		if !strings.HasPrefix(out.String(), tt.prefix) || !strings.Contains(out.String(), "MyStorage") {
+			t.Errorf("graph %v: expected a graph starting with %q, got:\n%s", tt.args, tt.prefix, out.String())
+		}
+	}
+}
+
+// TestValidateCmd_ParametersFlag tests that --parameters sets ValidateParameters
+func TestValidateCmd_ParametersFlag(t *testing.T) {
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+
+	cmd, _, err := root.Find([]string{"validate"})
+	if err != nil {
+		t.Fatalf("validate command not found: %v", err)
+	}
+	if err := cmd.ParseFlags([]string{"--parameters", "params.json"}); err != nil {
+		t.Fatalf("ParseFlags() error: %v", err)
+	}
+	if d.ValidateParameters != "params.json" {
+		t.Errorf("ValidateParameters = %q, want params.json", d.ValidateParameters)
+	}
+}
+
+// TestValidateCmd_Count tests that --count summarizes findings by code,
+// most frequent first
+func TestValidateCmd_Count(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "azuredeploy.json")
+	template := `{
+  "$schema": "https://example.com/template.json",
+  "contentVersion": "1.0.0.0",
+  "resources": [
+    {"name": "a"},
+    {"name": "b", "type": "Microsoft.Storage/storageAccounts"},
+    {"name": "c", "apiVersion": "2021-04-01"}
+  ]
+}`
+	if err := os.WriteFile(templatePath, []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"validate", templatePath, "--count"})
+
+	var exitErr *ExitError
+	if err := root.Execute(); !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("Expected exit status 1, got %v\n%s", err, out.String())
+	}
+
+	want := "\nBy rule:\n" +
+		"  missing-field        4\n" +
+		"  unrecognized-schema  1\n"
+	if !strings.HasSuffix(out.String(), want) {
+		t.Errorf("Expected summary %q, got:\n%s", want, out.String())
+	}
+}
+
+// TestValidateCmd_WarningsOnly tests that a template with only warnings and
+// information passes validation and still lists the findings
+func TestValidateCmd_WarningsOnly(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "azuredeploy.json")
+	template := `{
+  "$schema": "https://example.com/template.json",
+  "contentVersion": "1.0.0.0",
+  "resources": [
+    {
+      "type": "Microsoft.Network/networkInterfaces",
+      "apiVersion": "2023-05-01",
+      "name": "nic",
+      "properties": {
+        "networkSecurityGroup": {"id": "[resourceId('Microsoft.Network/networkSecurityGroups', 'shared-nsg')]"}
+      }
+    }
+  ]
+}`
+	if err := os.WriteFile(templatePath, []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"validate", templatePath})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Expected validation to pass, got %v\n%s", err, out.String())
+	}
+	if !strings.HasPrefix(out.String(), "✓ Success") {
+		t.Errorf("Expected success, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "unrecognized-schema") {
+		t.Errorf("Expected the schema finding to be listed, got:\n%s", out.String())
+	}
+}
+
+// TestCountByCode tests that errors without a code are counted together and
+// ties are ordered by code
+func TestCountByCode(t *testing.T) {
+	counts := countByCode([]Error{
+		{Code: "WAZ307"}, {Code: "WAZ303"}, {}, {Code: "WAZ307"}, {Code: "WAZ001"},
+	})
+	want := []codeCount{{"WAZ307", 2}, {"(none)", 1}, {"WAZ001", 1}, {"WAZ303", 1}}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("countByCode() = %v, want %v", counts, want)
+	}
+}
+
+// TestBuildCmd_MetadataFlag tests that --metadata key=value pairs are bound to d.Metadata
+func TestBuildCmd_MetadataFlag(t *testing.T) {
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+
+	cmd, _, err := root.Find([]string{"build"})
+	if err != nil {
+		t.Fatalf("build command not found: %v", err)
+	}
+	if err := cmd.ParseFlags([]string{"--metadata", "author=platform-team,description=Core network", "--metadata", "ticket=OPS-42"}); err != nil {
+		t.Fatalf("ParseFlags() error: %v", err)
+	}
+	want := map[string]string{"author": "platform-team", "description": "Core network", "ticket": "OPS-42"}
+	if !reflect.DeepEqual(d.Metadata, want) {
+		t.Errorf("Metadata = %v, want %v", d.Metadata, want)
+	}
+}
+
+// TestBuildCmd_SortKeysFlag tests that --sort-keys defaults to false and
+// sets SortKeys
+func TestBuildCmd_SortKeysFlag(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"--sort-keys"}, true},
+	} {
+		d := &AzureDomain{}
+		root := CreateRootCommand(d)
+		ExtendCommands(root, d)
+
+		cmd, _, err := root.Find([]string{"build"})
+		if err != nil {
+			t.Fatalf("build command not found: %v", err)
+		}
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatalf("ParseFlags() error: %v", err)
+		}
+		cmd.PreRun(cmd, nil)
+		if d.SortKeys != tt.want {
+			t.Errorf("args %v: SortKeys = %v, want %v", tt.args, d.SortKeys, tt.want)
+		}
+	}
+}
+
+// TestBuildCmd_IncludeEmptySectionsFlag tests that --include-empty-sections
+// defaults to true and --include-empty-sections=false sets OmitEmptySections
+func TestBuildCmd_IncludeEmptySectionsFlag(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"--include-empty-sections=false"}, true},
+	} {
+		d := &AzureDomain{}
+		root := CreateRootCommand(d)
+		ExtendCommands(root, d)
+
+		cmd, _, err := root.Find([]string{"build"})
+		if err != nil {
+			t.Fatalf("build command not found: %v", err)
+		}
+		if err := cmd.ParseFlags(tt.args); err != nil {
+			t.Fatalf("ParseFlags() error: %v", err)
+		}
+		cmd.PreRun(cmd, nil)
+		if d.OmitEmptySections != tt.want {
+			t.Errorf("args %v: OmitEmptySections = %v, want %v", tt.args, d.OmitEmptySections, tt.want)
+		}
+	}
+}
+
+// TestListCmd_DependsOn tests that list --depends-on prints an indented tree
+func TestListCmd_DependsOn(t *testing.T) {
+	tmpDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var VNet = network.VirtualNetwork{Name: "vnet", Location: "eastus"}
+
+var AppSubnet = network.Subnet{Name: VNet.Name + "/app"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"list", tmpDir, "--depends-on"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("list --depends-on error: %v", err)
+	}
+
+	want := "AppSubnet (Microsoft.Network/subnets)\n└── VNet (Microsoft.Network/virtualNetworks)\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("Expected output to contain:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
+// TestBuildCmd_CountOnly tests that build --count-only prints the number of
+// resources without a template, and exits non-zero when discovery fails or
+// finds nothing unless --allow-empty is given
+func TestBuildCmd_CountOnly(t *testing.T) {
+	valid := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{Name: "logs", Location: "eastus"}
+
+var Data = storage.StorageAccount{Name: "data", Location: "eastus"}
+`
+	broken := `package main
+
+var Logs = storage.StorageAccount{Name: "logs",
+`
+	empty := "package main\n"
+
+	for _, tt := range []struct {
+		name     string
+		code     string
+		args     []string
+		wantExit bool
+		want     string
+	}{
+		{"valid", valid, nil, false, "Found 2 resources"},
+		{"broken", broken, nil, true, "discovery failed"},
+		{"empty", empty, nil, true, "no resources found"},
+		{"empty allowed", empty, []string{"--allow-empty"}, false, "Found 0 resources"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(tt.code), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			d := &AzureDomain{}
+			root := CreateRootCommand(d)
+			ExtendCommands(root, d)
+			var out bytes.Buffer
+			root.SetOut(&out)
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(append([]string{"build", srcDir, "--count-only"}, tt.args...))
+
+			err := root.Execute()
+			var exitErr *ExitError
+			if tt.wantExit {
+				if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+					t.Fatalf("Execute() error = %v, want exit code 1", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Execute() error: %v", err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("Expected %q in output, got:\n%s", tt.want, out.String())
+			}
+			if strings.Contains(out.String(), "$schema") {
+				t.Errorf("Expected no template in output, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+// TestBuildCmd_MaxResources tests that build fails when copy loops take the
+// template over --max-resources, which defaults to the ARM limit
+func TestBuildCmd_MaxResources(t *testing.T) {
+	code := func(count int) string {
+		return fmt.Sprintf(`package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = storage.StorageAccount{Name: "logs", Location: "eastus"}
+
+var Shards = intrinsics.Copy{
+	Count:    %d,
+	Resource: storage.StorageAccount{Name: "shard", Location: "eastus"},
+}
+`, count)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		count    int
+		args     []string
+		wantExit bool
+	}{
+		{"under the default", 799, nil, false},
+		{"over the default", 800, nil, true},
+		{"under the limit", 9, []string{"--max-resources", "10"}, false},
+		{"over the limit", 10, []string{"--max-resources", "10"}, true},
+		{"no limit", 5000, []string{"--max-resources", "0"}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code(tt.count)), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			d := &AzureDomain{}
+			root := CreateRootCommand(d)
+			ExtendCommands(root, d)
+			var out bytes.Buffer
+			root.SetOut(&out)
+			root.SetErr(&bytes.Buffer{})
+			root.SetArgs(append([]string{"build", srcDir}, tt.args...))
+
+			err := root.Execute()
+			if !tt.wantExit {
+				if err != nil {
+					t.Fatalf("Execute() error: %v\n%s", err, out.String())
+				}
+				if !strings.Contains(out.String(), "$schema") {
+					t.Errorf("Expected a template in output, got:\n%s", out.String())
+				}
+				return
+			}
+
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+				t.Fatalf("Execute() error = %v, want exit code 1", err)
+			}
+			want := fmt.Sprintf("the template has %d resources", tt.count+1)
+			if !strings.Contains(out.String(), want) || !strings.Contains(out.String(), "limited to 800 resources") {
+				t.Errorf("Expected %q and the ARM limit in output, got:\n%s", want, out.String())
+			}
+		})
+	}
+}
+
+func TestBuildCmd_Trace(t *testing.T) {
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = storage.StorageAccount{Name: "logs", Location: "eastus"}
+
+var VM = compute.NewVirtualMachine("vm", "eastus")
+`
+	for _, tt := range []struct {
+		name  string
+		args  []string
+		debug string
+	}{
+		{"flag", []string{"--trace"}, ""},
+		{"env", nil, "1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WETWIRE_DEBUG", tt.debug)
+			srcDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			d := &AzureDomain{}
+			root := CreateRootCommand(d)
+			ExtendCommands(root, d)
+			var out, stderr bytes.Buffer
+			root.SetOut(&out)
+			root.SetErr(&stderr)
+			root.SetArgs(append([]string{"build", srcDir, "--count-only"}, tt.args...))
+
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Execute() error: %v", err)
+			}
+			for _, want := range []string{
+				"trace: main.go:8: var Logs accepted as Microsoft.Storage/storageAccounts",
+				"trace: main.go:10: var VM rejected: value compute.NewVirtualMachine is a function call",
+			} {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("Expected %q in stderr, got:\n%s", want, stderr.String())
+				}
+			}
+			if strings.Contains(out.String(), "trace:") {
+				t.Errorf("Expected no trace in output, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestBuildCmd_ResourceContext(t *testing.T) {
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Orders = (&storage.StorageAccount{Name: "orders", Location: "eastus"}).
+	WithPrivateEndpoint("/subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pe", "blob")
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"build", srcDir, "--subscription", "00000000-0000-0000-0000-000000000001", "--resource-group", "orders-rg"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	want := "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/orders-rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pe"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("Expected %q in output, got:\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"io"
+	"os"
+)
+
+// ANSI escape codes for terminal output
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+)
+
+// isTerminal reports whether w is a character device such as a terminal.
+// Pipes, files, and in-memory writers are not terminals.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// severityColor returns the color for a lint severity (error, warning, info)
+func severityColor(severity string) string {
+	switch severity {
+	case "error":
+		return colorRed
+	case "warning":
+		return colorYellow
+	case "info":
+		return colorCyan
+	default:
+		return ""
+	}
+}
+
+// colorize wraps s in color when enabled and color is non-empty
+func colorize(s, color string, enabled bool) string {
+	if !enabled || color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
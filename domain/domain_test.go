@@ -1,11 +1,17 @@
 package domain
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/internal/validator"
 	coredomain "github.com/lex00/wetwire-core-go/domain"
 )
 
@@ -111,6 +117,82 @@ var MyStorage = storage.StorageAccount{
 	}
 }
 
+// TestLint_OnlyRules tests that AzureDomain.OnlyRules runs only the listed rules
+func TestLint_OnlyRules(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// WAZ001 (invalid location format) and WAZ004 (duplicate names) both trigger
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Location: "East US",
+}
+
+var MyStorage = storage.StorageAccount{
+	Location: "West US",
+}
+`
+	testFile := filepath.Join(tmpDir, "test.go")
+	if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{OnlyRules: []string{"WAZ004"}}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Linter().Lint(ctx, testFile, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	if len(result.Errors) == 0 {
+		t.Fatal("Expected WAZ004 to be triggered")
+	}
+	for _, e := range result.Errors {
+		if e.Code != "WAZ004" {
+			t.Errorf("Only WAZ004 should run, got %s: %s", e.Code, e.Message)
+		}
+	}
+}
+
+// TestLint_OnlyRulesUnknown tests that unknown --only IDs warn without failing
+func TestLint_OnlyRulesUnknown(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Location: "East US",
+}
+`
+	testFile := filepath.Join(tmpDir, "test.go")
+	if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// WAZ001 would fail this file, but only the security rules run
+	domain := &AzureDomain{OnlyRules: []string{"WAZ301", "WAZ306"}}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Linter().Lint(ctx, testFile, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected success, got errors: %v", result.Errors)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %v", len(result.Errors), result.Errors)
+	}
+	warning := result.Errors[0]
+	if warning.Severity != "warning" || !strings.Contains(warning.Message, "WAZ306") {
+		t.Errorf("Expected a warning about WAZ306, got %+v", warning)
+	}
+}
+
 // TestLintOpts_Fix tests that LintOpts.Fix is accepted and produces appropriate message
 func TestLintOpts_Fix(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -196,3 +278,2571 @@ var MyStorage = storage.StorageAccount{
 		}
 	}
 }
+
+// TestBuild_Scope tests that AzureDomain.Scope is passed through to the template builder
+func TestBuild_Scope(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorageaccount",
+	Location: "eastus",
+}
+`
+	testFile := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(testFile, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+
+	// Storage accounts are legal at the default resourceGroup scope
+	domain := &AzureDomain{}
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err != nil {
+		t.Fatalf("Build() at resourceGroup scope error: %v", err)
+	}
+
+	// ...but not at subscription scope
+	domain = &AzureDomain{Scope: "subscription"}
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err == nil {
+		t.Error("Expected Build() at subscription scope to reject a storage account")
+	}
+
+	// Unknown scopes are rejected
+	domain = &AzureDomain{Scope: "region"}
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err == nil {
+		t.Error("Expected Build() to reject an unknown scope")
+	}
+}
+
+// TestGraph_PolicyAssignmentEdge tests that an assignment referencing a definition produces a graph edge
+func TestGraph_PolicyAssignmentEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/policy"
+
+var RequireTag = policy.PolicyDefinition{
+	Name: "require-tag",
+}
+
+var RequireEnvTag = policy.PolicyAssignment{
+	Name: "require-env-tag",
+	Properties: policy.PolicyAssignmentProperties{
+		PolicyDefinitionID: RequireTag.ID(),
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+
+	graph, ok := result.Data.(string)
+	if !ok {
+		t.Fatalf("Expected graph data to be a string, got %T", result.Data)
+	}
+	if !strings.Contains(graph, `"RequireEnvTag" -> "RequireTag"`) {
+		t.Errorf("Expected edge from assignment to definition, got:\n%s", graph)
+	}
+}
+
+// TestGraph_ContainerAppEnvironmentEdge tests that a container app has an
+// edge to its environment and is built after it
+func TestGraph_ContainerAppEnvironmentEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/app"
+
+var AppsEnv = app.ManagedEnvironment{Name: "apps-env", Location: "eastus"}
+
+var API = app.ContainerApp{
+	Name:     "api",
+	Location: "eastus",
+	Properties: app.ContainerAppProperties{
+		EnvironmentID: AppsEnv.ID(),
+		Template: &app.Template{
+			Containers: []app.Container{{Name: "api", Image: "nginx"}},
+			Scale:      &app.Scale{Rules: []app.ScaleRule{{Name: "queue", Custom: &app.CustomScaleRule{Type: "azure-queue"}}}},
+		},
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	if !strings.Contains(graph, `"API" -> "AppsEnv"`) {
+		t.Errorf("Expected edge from the container app to its environment, got:\n%s", graph)
+	}
+
+	built, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	var template struct {
+		Resources []map[string]any `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(built.Data.(string)), &template); err != nil {
+		t.Fatal(err)
+	}
+	for _, resource := range template.Resources {
+		if resource["type"] == "Microsoft.App/containerApps" {
+			if resource["apiVersion"] != "2023-05-01" {
+				t.Errorf("Expected API version 2023-05-01, got %v", resource["apiVersion"])
+			}
+			deps, _ := resource["dependsOn"].([]any)
+			if len(deps) != 1 {
+				t.Errorf("Expected the container app to depend on its environment, got %v", resource["dependsOn"])
+			}
+			return
+		}
+	}
+	t.Errorf("Expected a container app in the template, got %v", template.Resources)
+}
+
+// TestBuild_MinAPIVersion tests that AzureDomain.MinAPIVersion rejects old explicit API versions
+func TestBuild_MinAPIVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:       "mystorageaccount",
+	APIVersion: "2019-06-01",
+	Location:   "eastus",
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+
+	domain := &AzureDomain{}
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err != nil {
+		t.Fatalf("Build() without minimum error: %v", err)
+	}
+
+	domain = &AzureDomain{MinAPIVersion: "2021-01-01"}
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err == nil {
+		t.Error("Expected Build() to reject API version 2019-06-01 with minimum 2021-01-01")
+	}
+}
+
+func TestImport_Bicep(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	bicep := `param location string = 'eastus'
+
+resource vnet 'Microsoft.Network/virtualNetworks@2021-02-01' = {
+  name: 'myVNet'
+  location: location
+  properties: {
+    addressSpace: {
+      addressPrefixes: [
+        '10.0.0.0/16'
+      ]
+    }
+    label: '${location}-vnet'
+  }
+}
+`
+	source := filepath.Join(tmpDir, "network.txt")
+	if err := os.WriteFile(source, []byte(bicep), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+	target := filepath.Join(tmpDir, "infra")
+
+	// Without a .bicep extension the source is only parsed as Bicep with FromBicep
+	domain := &AzureDomain{}
+	result, err := domain.Importer().Import(ctx, source, ImportOpts{Target: target})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if result.Success {
+		t.Error("Expected importing Bicep as ARM JSON to fail")
+	}
+
+	domain = &AzureDomain{FromBicep: true}
+	result, err = domain.Importer().Import(ctx, source, ImportOpts{Target: target})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Import() failed: %s", result.Message)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no warnings, got %+v", result.Errors)
+	}
+
+	code, err := os.ReadFile(filepath.Join(target, "network.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), "var VNet = network.VirtualNetwork{") {
+		t.Errorf("Expected generated code to declare VNet after the Bicep symbol, got:\n%s", code)
+	}
+	if !strings.Contains(string(code), "[format('{0}-vnet', parameters('location'))]") {
+		t.Errorf("Expected the interpolated label as a format() expression, got:\n%s", code)
+	}
+}
+
+func TestImport_BicepSkippedResourceWarns(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	bicep := `param prefix string = 'app'
+
+resource sa 'Microsoft.Storage/storageAccounts@2021-04-01' = [for i in range(0, 2): {
+  name: '${prefix}store${i}'
+  location: 'eastus'
+}]
+
+resource vnet 'Microsoft.Network/virtualNetworks@2021-02-01' = {
+  name: '${prefix}-vnet'
+  location: 'eastus'
+}
+`
+	source := filepath.Join(tmpDir, "storage.bicep")
+	if err := os.WriteFile(source, []byte(bicep), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&AzureDomain{}).Importer().Import(NewContext(context.Background(), tmpDir), source, ImportOpts{})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected import with a skipped resource to succeed, got %q: %+v", result.Message, result.Errors)
+	}
+	if result.Message != "Imported 1 resources, 1 skipped" {
+		t.Errorf("Expected the skipped resource to be counted, got %q", result.Message)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Severity != "warning" || result.Errors[0].Line != 3 {
+		t.Errorf("Expected a warning for the skipped resource sa, got %+v", result.Errors)
+	}
+	if code, _ := result.Data.(string); !strings.Contains(code, "[format('{0}-vnet', parameters('prefix'))]") {
+		t.Errorf("Expected vnet to be imported with its interpolated name, got:\n%s", code)
+	}
+}
+
+func TestImport_InvalidResources(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	armTemplate := `{
+	"resources": [
+		{"apiVersion": "2021-04-01", "name": "notype"},
+		{"type": "Microsoft.Storage/storageAccounts", "name": "noversion"}
+	]
+}`
+	source := filepath.Join(tmpDir, "template.json")
+	if err := os.WriteFile(source, []byte(armTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+	domain := &AzureDomain{}
+	result, err := domain.Importer().Import(ctx, source, ImportOpts{})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected import of invalid resources to fail")
+	}
+	if len(result.Errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %v", len(result.Errors), result.Errors)
+	}
+	if !strings.HasPrefix(result.Errors[0].Message, "resources[0] (notype).type:") {
+		t.Errorf("Unexpected first error: %s", result.Errors[0].Message)
+	}
+	if !strings.HasPrefix(result.Errors[1].Message, "resources[1] (noversion).apiVersion:") {
+		t.Errorf("Unexpected second error: %s", result.Errors[1].Message)
+	}
+}
+
+func TestImport_RawResourceRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	armTemplate := `{
+	"resources": [
+		{
+			"type": "Microsoft.HealthcareApis/workspaces",
+			"apiVersion": "2023-11-01",
+			"name": "health",
+			"location": "westeurope",
+			"tags": {"env": "prod"},
+			"properties": {
+				"publicNetworkAccess": "Disabled",
+				"limits": {"maxServices": 3, "tiers": ["basic", "premium"]}
+			}
+		}
+	]
+}`
+	source := filepath.Join(tmpDir, "template.json")
+	if err := os.WriteFile(source, []byte(armTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(tmpDir, "infra")
+	ctx := NewContext(context.Background(), tmpDir)
+	domain := &AzureDomain{}
+	result, err := domain.Importer().Import(ctx, source, ImportOpts{Target: target})
+	if err != nil || !result.Success {
+		t.Fatalf("Import() failed: %v %+v", err, result)
+	}
+
+	result, err = domain.Builder().Build(ctx, target, BuildOpts{})
+	if err != nil || !result.Success {
+		t.Fatalf("Build() failed: %v %+v", err, result)
+	}
+
+	var built struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &built); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	var original struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(armTemplate), &original); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(built.Resources, original.Resources) {
+		t.Errorf("Expected the resource to round-trip unchanged\ngot:  %v\nwant: %v", built.Resources, original.Resources)
+	}
+}
+
+// TestBuild_DependsOnRawResource tests that a resource depending on a raw
+// resource depends on the ARM name the raw resource is built with
+func TestBuild_DependsOnRawResource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+var Bastion = template.RawResource{
+	Type:       "Microsoft.Network/bastionHosts",
+	APIVersion: "2023-09-01",
+	Name:       "my-bastion",
+	Location:   "eastus",
+}
+
+var Logs = storage.StorageAccount{
+	Name:     "logs",
+	Location: "eastus",
+	Tags:     map[string]string{"bastion": Bastion.ID()},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+	domain := &AzureDomain{}
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil || !result.Success {
+		t.Fatalf("Build() failed: %v %+v", err, result)
+	}
+
+	var built struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &built); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(built.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(built.Resources))
+	}
+	if built.Resources[0]["name"] != "my-bastion" {
+		t.Errorf("Expected the raw resource first, named my-bastion, got %v", built.Resources[0]["name"])
+	}
+	want := []interface{}{"[resourceId('Microsoft.Network/bastionHosts', 'my-bastion')]"}
+	if !reflect.DeepEqual(built.Resources[1]["dependsOn"], want) {
+		t.Errorf("Expected dependsOn %v, got %v", want, built.Resources[1]["dependsOn"])
+	}
+}
+
+// TestBuild_RawResourceVerbatim tests that a raw resource is built with the
+// fields it declares, intrinsics included, and no location it does not set
+func TestBuild_RawResourceVerbatim(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var Watcher = template.RawResource{
+	Type:       "Microsoft.Contoso/watchers",
+	APIVersion: "2024-01-01",
+	Name:       "watcher",
+	Properties: map[string]any{
+		"vnetId": intrinsics.ResourceRef("AppVNet"),
+		"size":   3,
+		"prefix": intrinsics.Parameters("prefix"),
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+	domain := &AzureDomain{}
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil || !result.Success {
+		t.Fatalf("Build() failed: %v %+v", err, result)
+	}
+
+	var built struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &built); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(built.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(built.Resources))
+	}
+	watcher := built.Resources[1]
+	wantProperties := map[string]interface{}{
+		"vnetId": "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]",
+		"size":   float64(3),
+		"prefix": "[parameters('prefix')]",
+	}
+	if !reflect.DeepEqual(watcher["properties"], wantProperties) {
+		t.Errorf("properties = %v, want %v", watcher["properties"], wantProperties)
+	}
+	if location, ok := watcher["location"]; ok {
+		t.Errorf("Expected no location, got %v", location)
+	}
+}
+
+// TestBuild_RawResourceRef tests that an intrinsics.ResourceRef call in the
+// properties of a raw resource is built as a resourceId() expression
+func TestBuild_RawResourceRef(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+var MyVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var Workspace = template.RawResource{
+	Type:       "Microsoft.HealthcareApis/workspaces",
+	APIVersion: "2023-11-01",
+	Name:       "health",
+	Properties: map[string]any{"subnetId": intrinsics.ResourceRef("MyVNet", "subnets", "app")},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+	domain := &AzureDomain{}
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil || !result.Success {
+		t.Fatalf("Build() failed: %v %+v", err, result)
+	}
+
+	var built struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &built); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(built.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(built.Resources))
+	}
+	workspace := built.Resources[1]
+	wantProperties := map[string]interface{}{
+		"subnetId": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'app')]",
+	}
+	if !reflect.DeepEqual(workspace["properties"], wantProperties) {
+		t.Errorf("properties = %v, want %v", workspace["properties"], wantProperties)
+	}
+	wantDependsOn := []interface{}{"[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"}
+	if !reflect.DeepEqual(workspace["dependsOn"], wantDependsOn) {
+		t.Errorf("dependsOn = %v, want %v", workspace["dependsOn"], wantDependsOn)
+	}
+}
+
+func TestImport_Merge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	armTemplate := `{
+	"resources": [
+		{"type": "Microsoft.Storage/storageAccounts", "apiVersion": "2023-01-01", "name": "logs", "location": "eastus"},
+		{"type": "Microsoft.Network/networkSecurityGroups", "apiVersion": "2021-05-01", "name": "web-nsg", "location": "eastus"},
+		{"type": "Microsoft.Network/virtualNetworks", "apiVersion": "2021-05-01", "name": "main-vnet", "location": "eastus"}
+	]
+}`
+	source := filepath.Join(tmpDir, "template.json")
+	if err := os.WriteFile(source, []byte(armTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	existing := `package infra
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = storage.StorageAccount{Name: "logs", Location: "eastus"}
+
+var WebNsg = network.NetworkSecurityGroup{Name: "web-nsg", Location: "eastus"}
+`
+	mergePath := filepath.Join(tmpDir, "infra.go")
+	if err := os.WriteFile(mergePath, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), tmpDir)
+	domain := &AzureDomain{ImportMerge: mergePath}
+	result, err := domain.Importer().Import(ctx, source, ImportOpts{})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Import() failed: %s %v", result.Message, result.Errors)
+	}
+	if !strings.Contains(result.Message, "Merged 1 new resources") || !strings.Contains(result.Message, "(2 already present)") {
+		t.Errorf("Unexpected message: %s", result.Message)
+	}
+
+	merged, err := os.ReadFile(mergePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(merged), existing) || !strings.Contains(string(merged), "var MainVNet = network.VirtualNetwork{") {
+		t.Errorf("Expected MainVNet to be appended, got:\n%s", merged)
+	}
+
+	// The merged file still builds, with all three resources
+	buildResult, err := domain.Builder().Build(NewContext(context.Background(), tmpDir), tmpDir, BuildOpts{DryRun: true})
+	if err != nil || !buildResult.Success {
+		t.Fatalf("Expected the merged file to build, got %v %+v", err, buildResult)
+	}
+
+	// A resource declared differently is a conflict, and the file is untouched
+	conflicting := strings.Replace(string(merged), `Name: "web-nsg", Location: "eastus"`, `Name: "web-nsg", Location: "westus"`, 1)
+	if err := os.WriteFile(mergePath, []byte(conflicting), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = domain.Importer().Import(ctx, source, ImportOpts{})
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected a conflicting merge to fail")
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 10 || !strings.Contains(result.Errors[0].Message, `"web-nsg"`) {
+		t.Errorf("Expected one conflict for web-nsg on line 10, got %+v", result.Errors)
+	}
+	after, err := os.ReadFile(mergePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != conflicting {
+		t.Error("Expected the file to be left unchanged after a conflict")
+	}
+}
+
+// TestBuild_CopyLoop tests that an intrinsics.Copy declaration builds into a resource with a copy element
+func TestBuild_CopyLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+)
+
+var AppVNet = network.VirtualNetwork{
+	Name:     "app-vnet",
+	Location: "eastus",
+}
+
+var WebNICs = intrinsics.Copy{
+	Count: 3,
+	Resource: network.NetworkInterface{
+		Name:     "web-nic",
+		Location: "eastus",
+		Tags:     map[string]string{"vnet": AppVNet.Name},
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	var template struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &template); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(template.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(template.Resources))
+	}
+
+	nic := template.Resources[1]
+	if nic["type"] != "Microsoft.Network/networkInterfaces" {
+		t.Errorf("Expected network interface, got %v", nic["type"])
+	}
+	if nic["name"] != "[concat('web-nic', copyIndex())]" {
+		t.Errorf("Expected name indexed by copyIndex(), got %v", nic["name"])
+	}
+	wantCopy := map[string]interface{}{"name": "WebNICs", "count": float64(3)}
+	if !reflect.DeepEqual(nic["copy"], wantCopy) {
+		t.Errorf("copy = %v, want %v", nic["copy"], wantCopy)
+	}
+}
+
+// TestBuild_Profile tests that Profile writes a non-empty CPU profile
+func TestBuild_Profile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	profilePath := filepath.Join(t.TempDir(), "cpu.out")
+
+	domain := &AzureDomain{Profile: "cpu=" + profilePath}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{DryRun: true})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Build() failed: %v", result.Errors)
+	}
+
+	info, err := os.Stat(profilePath)
+	if err != nil {
+		t.Fatalf("Expected profile to be written: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("Expected a non-empty profile")
+	}
+}
+
+// TestBuild_ProfileInvalid tests that unsupported profile specs are rejected
+func TestBuild_ProfileInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx := NewContext(context.Background(), tmpDir)
+
+	for _, spec := range []string{"cpu", "cpu=", "mem=mem.out"} {
+		domain := &AzureDomain{Profile: spec}
+		if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{DryRun: true}); err == nil {
+			t.Errorf("Profile %q: expected error", spec)
+		}
+	}
+}
+
+// TestBuild_OmitEmptySections tests that OmitEmptySections drops the empty
+// optional sections and the template still validates
+func TestBuild_OmitEmptySections(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	for _, omit := range []bool{false, true} {
+		domain := &AzureDomain{OmitEmptySections: omit}
+		result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{DryRun: true})
+		if err != nil {
+			t.Fatalf("Build() error: %v", err)
+		}
+		templateJSON := result.Data.(string)
+
+		for _, section := range []string{`"parameters"`, `"variables"`, `"outputs"`} {
+			if strings.Contains(templateJSON, section) == omit {
+				t.Errorf("OmitEmptySections=%v: unexpected presence of %s in:\n%s", omit, section, templateJSON)
+			}
+		}
+
+		results, err := validator.NewValidator().ValidateTemplate([]byte(templateJSON))
+		if err != nil {
+			t.Fatalf("ValidateTemplate() error: %v", err)
+		}
+		for _, r := range results {
+			if r.Severity == validator.SeverityError {
+				t.Errorf("OmitEmptySections=%v: template does not validate: %s", omit, r)
+			}
+		}
+	}
+}
+
+// TestBuild_Merge tests that BuildMerge merges the generated resources into
+// an existing template and reports conflicting definitions
+func TestBuild_Merge(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	if err := os.Mkdir(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(tmpDir, "base.json")
+	baseJSON := `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "parameters": {"vnetPrefix": {"type": "string", "defaultValue": "10.0.0.0/16"}},
+  "resources": [
+    {"type": "Microsoft.Network/virtualNetworks", "apiVersion": "2021-05-01", "name": "hub-vnet", "location": "eastus",
+     "properties": {"addressSpace": {"addressPrefixes": ["[parameters('vnetPrefix')]"]}}},
+    {"type": "Microsoft.Storage/storageAccounts", "apiVersion": "2019-06-01", "name": "mystorage", "location": "eastus"}
+  ]
+}`
+	if err := os.WriteFile(base, []byte(baseJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{BuildMerge: base}
+	ctx := NewContext(context.Background(), srcDir)
+	output := filepath.Join(tmpDir, "azuredeploy.json")
+	result, err := domain.Builder().Build(ctx, srcDir, BuildOpts{Output: output})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Build() failed: %+v", result)
+	}
+	if !strings.Contains(result.Message, "replaced Microsoft.Storage/storageAccounts/mystorage of "+base) {
+		t.Errorf("message = %q, want the replaced base resource", result.Message)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var template struct {
+		Metadata   map[string]interface{}   `json:"metadata"`
+		Parameters map[string]interface{}   `json:"parameters"`
+		Resources  []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &template); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(template.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d:\n%s", len(template.Resources), data)
+	}
+	if template.Resources[0]["name"] != "hub-vnet" || template.Resources[1]["name"] != "mystorage" {
+		t.Errorf("Expected the base VNet then the generated storage account, got %v", template.Resources)
+	}
+	if template.Resources[1]["apiVersion"] == "2019-06-01" {
+		t.Error("Expected the generated storage account to replace the base one")
+	}
+	if _, ok := template.Parameters["vnetPrefix"]; !ok {
+		t.Errorf("Expected the base parameters to be kept, got %v", template.Parameters)
+	}
+	if _, ok := template.Metadata["_generator"]; !ok {
+		t.Errorf("Expected the generator stamp in the metadata, got %v", template.Metadata)
+	}
+
+	// A base template for another deployment scope cannot be merged
+	scoped := strings.Replace(baseJSON, "deploymentTemplate.json", "subscriptionDeploymentTemplate.json", 1)
+	if err := os.WriteFile(base, []byte(scoped), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = domain.Builder().Build(ctx, srcDir, BuildOpts{DryRun: true})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if result.Success || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "conflicts with the base template") {
+		t.Errorf("Expected a merge conflict, got %+v", result)
+	}
+}
+
+// TestBuild_Deterministic tests that building the same package twice gives
+// byte-identical templates with sorted keys
+func TestBuild_Deterministic(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = storage.StorageAccount{Name: "logs", Location: "eastus"}
+
+var Data = storage.StorageAccount{Name: "data", Location: "eastus"}
+
+var WebNSG = network.NetworkSecurityGroup{Name: "web-nsg", Location: "eastus"}
+
+var AppVNet = network.VirtualNetwork{
+	Name:     "app-vnet",
+	Location: "eastus",
+	Tags: map[string]string{
+		"logs": Logs.Name,
+		"data": Data.Name,
+		"nsg":  WebNSG.Name,
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	build := func() string {
+		domain := &AzureDomain{}
+		ctx := NewContext(context.Background(), tmpDir)
+		result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+		if err != nil {
+			t.Fatalf("Build() error: %v", err)
+		}
+		return result.Data.(string)
+	}
+
+	first := build()
+	if second := build(); second != first {
+		t.Fatalf("Builds differ:\n%s\n---\n%s", first, second)
+	}
+	if !strings.Contains(first, `"name": "app-vnet",
+      "type": "Microsoft.Network/virtualNetworks",
+      "apiVersion": "2021-02-01",
+      "location": "[resourceGroup().location]",
+      "dependsOn": [
+        "[resourceId('Microsoft.Storage/storageAccounts', 'data')]",
+        "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]",
+        "[resourceId('Microsoft.Network/networkSecurityGroups', 'web-nsg')]"
+      ]`) {
+		t.Errorf("Expected sorted dependencies in ARM field order, got:\n%s", first)
+	}
+}
+
+// TestBuild_ChildResourceParent tests that a child resource only builds
+// when it references its parent, and is named under it
+// TestBuild_InlineSubnets tests that network.Subnet variables listed in the
+// Subnets of a virtual network build without a parent of their own
+func TestBuild_InlineSubnets(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.VirtualNetwork{
+	Name:     "app-vnet",
+	Location: "eastus",
+	Properties: network.VirtualNetworkProperties{
+		Subnets: []network.Subnet{AppSubnet},
+	},
+}
+
+var AppSubnet = network.Subnet{
+	Name: "app",
+	Properties: network.SubnetProperties{
+		AddressPrefix: "10.0.1.0/24",
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Build() failed: %+v", result.Errors)
+	}
+}
+
+func TestBuild_ChildResourceParent(t *testing.T) {
+	const server = `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/sql"
+
+var AppServer = sql.Server{
+	Name:     "app-sql",
+	Location: "eastus",
+}
+`
+	tests := []struct {
+		name     string
+		database string
+		wantErr  bool
+	}{
+		{
+			name: "orphan",
+			database: `
+var AppDB = sql.Database{
+	Name:     "appdb",
+	Location: "eastus",
+}
+`,
+			wantErr: true,
+		},
+		{
+			name: "parented",
+			database: `
+var AppDB = sql.Database{
+	Name:     "appdb",
+	Location: "eastus",
+	Tags:     map[string]string{"server": AppServer.Name},
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(server+tt.database), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			domain := &AzureDomain{}
+			ctx := NewContext(context.Background(), tmpDir)
+			result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Build() error: %v", err)
+				}
+				if !strings.Contains(result.Data.(string), `"name": "app-sql/appdb"`) {
+					t.Errorf("Expected the database to be named under its server, got:\n%s", result.Data)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected an error for a database without a server")
+			}
+			if !strings.Contains(err.Error(), "resource AppDB of type Microsoft.Sql/servers/databases has no parent") {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestBuild_VMBackup tests that a backup-enabled VM builds into both the VM
+// and its protected item
+func TestBuild_VMBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+)
+
+var WebVM = (&compute.VirtualMachine{
+	Name:     "web-vm",
+	Location: "eastus",
+}).EnableBackup("backup-vault", "DefaultPolicy")
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	var template struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &template); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(template.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(template.Resources))
+	}
+
+	if template.Resources[0]["type"] != "Microsoft.Compute/virtualMachines" {
+		t.Errorf("Expected the VM first, got %v", template.Resources[0]["type"])
+	}
+
+	item := template.Resources[1]
+	if item["type"] != "Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems" {
+		t.Errorf("Expected protected item, got %v", item["type"])
+	}
+	wantName := "[concat('backup-vault/Azure/iaasvmcontainer;iaasvmcontainerv2;', resourceGroup().name, ';web-vm/vm;iaasvmcontainerv2;', resourceGroup().name, ';web-vm')]"
+	if item["name"] != wantName {
+		t.Errorf("name = %v, want %v", item["name"], wantName)
+	}
+	wantDependsOn := []interface{}{"[resourceId('Microsoft.Compute/virtualMachines', 'web-vm')]"}
+	if !reflect.DeepEqual(item["dependsOn"], wantDependsOn) {
+		t.Errorf("dependsOn = %v, want %v", item["dependsOn"], wantDependsOn)
+	}
+	props, _ := item["properties"].(map[string]interface{})
+	if props["policyId"] != "[resourceId('Microsoft.RecoveryServices/vaults/backupPolicies', 'backup-vault', 'DefaultPolicy')]" {
+		t.Errorf("Unexpected policyId: %v", props["policyId"])
+	}
+}
+
+// TestBuild_StoragePrivateEndpoint tests that a storage account with a
+// private endpoint builds into both the account and the endpoint
+func TestBuild_StoragePrivateEndpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Orders = (&storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+}).WithPrivateEndpoint("[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'pe')]", "blob")
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	var template struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &template); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(template.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(template.Resources))
+	}
+
+	endpoint := template.Resources[1]
+	if endpoint["type"] != "Microsoft.Network/privateEndpoints" || endpoint["name"] != "orders-blob-pe" {
+		t.Errorf("Expected private endpoint orders-blob-pe, got %v %v", endpoint["type"], endpoint["name"])
+	}
+	wantDependsOn := []interface{}{"[resourceId('Microsoft.Storage/storageAccounts', 'orders')]"}
+	if !reflect.DeepEqual(endpoint["dependsOn"], wantDependsOn) {
+		t.Errorf("dependsOn = %v, want %v", endpoint["dependsOn"], wantDependsOn)
+	}
+	props, _ := endpoint["properties"].(map[string]interface{})
+	connections, _ := props["privateLinkServiceConnections"].([]interface{})
+	if len(connections) != 1 {
+		t.Fatalf("Expected 1 private link service connection, got %v", props["privateLinkServiceConnections"])
+	}
+	connProps, _ := connections[0].(map[string]interface{})["properties"].(map[string]interface{})
+	if connProps["privateLinkServiceId"] != "[resourceId('Microsoft.Storage/storageAccounts', 'orders')]" {
+		t.Errorf("Unexpected privateLinkServiceId: %v", connProps["privateLinkServiceId"])
+	}
+}
+
+// TestBuild_UniqueName tests that a resource named with naming.Unique is
+// built with the uniqueString name, and referenced by it
+func TestBuild_UniqueName(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/naming"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Store = (&storage.StorageAccount{
+	Name:     naming.Unique("Prod-Logs").For("Microsoft.Storage/storageAccounts").String(),
+	Location: "eastus",
+}).WithDeleteLock()
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil || !result.Success {
+		t.Fatalf("Build() failed: %v %+v", err, result)
+	}
+
+	var template struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &template); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(template.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(template.Resources))
+	}
+	if name := template.Resources[0]["name"]; name != "[concat('prodlogs', uniqueString(resourceGroup().id))]" {
+		t.Errorf("Expected the unique name, got %v", name)
+	}
+	storeID := "[resourceId('Microsoft.Storage/storageAccounts', concat('prodlogs', uniqueString(resourceGroup().id)))]"
+	lock := template.Resources[1]
+	if lock["scope"] != storeID {
+		t.Errorf("Expected the lock scoped to %s, got %v", storeID, lock["scope"])
+	}
+	if !reflect.DeepEqual(lock["dependsOn"], []interface{}{storeID}) {
+		t.Errorf("Expected the lock to depend on %s, got %v", storeID, lock["dependsOn"])
+	}
+}
+
+// TestBuild_ResourceRef tests that a NIC linked to a subnet with
+// intrinsics.ResourceRef depends on its virtual network, and that references
+// in generated properties become resourceId expressions. The NIC's own
+// properties are typed, which build does not write, so only its dependsOn
+// carries the reference.
+func TestBuild_ResourceRef(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var AppNIC = network.NetworkInterface{
+	Name:     "app-nic",
+	Location: "eastus",
+	Properties: network.NetworkInterfaceProperties{
+		IPConfigurations: []network.IPConfiguration{{
+			Name: "ipconfig1",
+			Properties: network.IPConfigurationProperties{
+				Subnet: network.NewSubResource(intrinsics.ResourceRef("AppVNet", "subnets", "app")),
+			},
+		}},
+	},
+}
+
+var Orders = (&storage.StorageAccount{Name: "orders", Location: "eastus"}).
+	WithPrivateEndpoint(intrinsics.ResourceRef("AppVNet", "subnets", "pe"), "blob")
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Build() failed: %+v", result.Errors)
+	}
+
+	var template struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &template); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	byName := make(map[string]map[string]interface{})
+	for _, res := range template.Resources {
+		byName[res["name"].(string)] = res
+	}
+
+	vnetID := "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"
+	nic := byName["app-nic"]
+	if !reflect.DeepEqual(nic["dependsOn"], []interface{}{vnetID}) {
+		t.Errorf("AppNIC dependsOn = %v, want %v", nic["dependsOn"], vnetID)
+	}
+
+	endpoint := byName["orders-blob-pe"]
+	if endpoint == nil {
+		t.Fatalf("Expected private endpoint orders-blob-pe, got %v", template.Resources)
+	}
+	wantDependsOn := []interface{}{vnetID, "[resourceId('Microsoft.Storage/storageAccounts', 'orders')]"}
+	if !reflect.DeepEqual(endpoint["dependsOn"], wantDependsOn) {
+		t.Errorf("dependsOn = %v, want %v", endpoint["dependsOn"], wantDependsOn)
+	}
+	props, _ := endpoint["properties"].(map[string]interface{})
+	subnet, _ := props["subnet"].(map[string]interface{})
+	if subnet["id"] != "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'pe')]" {
+		t.Errorf("Unexpected subnet id: %v", subnet["id"])
+	}
+}
+
+// TestGraph_GroupByFile tests that GraphGroupByFile clusters resources by source file
+func TestGraph_GroupByFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"network.go": `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.VirtualNetwork{
+	Name:     "app-vnet",
+	Location: "eastus",
+}
+`,
+		"storage.go": `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var AppStorage = storage.StorageAccount{
+	Name:     "appstorage",
+	Location: "eastus",
+	Tags:     map[string]string{"vnet": AppVNet.Name},
+}
+`,
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	domain := &AzureDomain{GraphGroupByFile: true}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+
+	if n := strings.Count(graph, "subgraph cluster_"); n != 2 {
+		t.Errorf("Expected 2 clusters, got %d:\n%s", n, graph)
+	}
+	for _, label := range []string{`label="network.go"`, `label="storage.go"`} {
+		if !strings.Contains(graph, label) {
+			t.Errorf("Expected cluster %s, got:\n%s", label, graph)
+		}
+	}
+	// The edge crosses from the storage cluster to the network cluster
+	if !strings.Contains(graph, `"AppStorage" -> "AppVNet"`) {
+		t.Errorf("Expected edge between clusters, got:\n%s", graph)
+	}
+
+	result, err = domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "mermaid"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	mermaid := result.Data.(string)
+	if n := strings.Count(mermaid, "subgraph file_"); n != 2 || strings.Count(mermaid, "  end\n") != 2 {
+		t.Errorf("Expected 2 Mermaid subgraphs, got:\n%s", mermaid)
+	}
+	if !strings.Contains(mermaid, "AppStorage --> AppVNet") {
+		t.Errorf("Expected edge between subgraphs, got:\n%s", mermaid)
+	}
+}
+
+// TestGraph_ApplicationSecurityGroupEdges tests that NSG rules referencing
+// application security groups produce graph edges
+func TestGraph_ApplicationSecurityGroupEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var WebASG = network.ApplicationSecurityGroup{Name: "web-asg", Location: "eastus"}
+
+var AppNSG = network.NetworkSecurityGroup{
+	Name:     "app-nsg",
+	Location: "eastus",
+	Properties: network.NetworkSecurityGroupProperties{
+		SecurityRules: []network.SecurityRule{{
+			Name: "allow-web",
+			Properties: network.SecurityRuleProperties{
+				Priority:                             100,
+				Direction:                            "Inbound",
+				Access:                               "Allow",
+				Protocol:                             "Tcp",
+				DestinationPortRange:                 "443",
+				SourceAddressPrefix:                  "Internet",
+				DestinationApplicationSecurityGroups: []network.SubResource{*network.NewSubResource(WebASG.ID())},
+			},
+		}},
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	if graph := result.Data.(string); !strings.Contains(graph, `"AppNSG" -> "WebASG"`) {
+		t.Errorf("Expected edge from NSG to ASG, got:\n%s", graph)
+	}
+}
+
+// TestBuild_InvalidResourceValues tests that Validate errors of literal
+// resources are reported in the build result
+func TestBuild_InvalidResourceValues(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var BlobStore = storage.StorageAccount{
+	Name:     "blobstore",
+	Location: "eastus",
+	Kind:     "BlockBlobStorage",
+	SKU:      storage.SKU{Name: "Standard_LRS"},
+}
+
+var WebNSG = network.NetworkSecurityGroup{
+	Name:     "web-nsg",
+	Location: "eastus",
+	Properties: network.NetworkSecurityGroupProperties{
+		SecurityRules: []network.SecurityRule{{
+			Name: "allow-https",
+			Properties: network.SecurityRuleProperties{
+				Priority:  50,
+				Direction: "Inbound",
+				Access:    "Allow",
+				Protocol:  "Tcp",
+			},
+		}},
+	},
+}
+`
+	source := filepath.Join(tmpDir, "main.go")
+	if err := os.WriteFile(source, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected build to fail for invalid resources")
+	}
+	if len(result.Errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", result.Errors)
+	}
+
+	skuErr := result.Errors[0]
+	if skuErr.Path != source || skuErr.Line != 8 || !strings.Contains(skuErr.Message, "BlobStore: kind BlockBlobStorage requires a Premium SKU") {
+		t.Errorf("Unexpected SKU/kind error: %+v", skuErr)
+	}
+	if !strings.Contains(result.Errors[1].Message, "WebNSG: security rule priority 50") {
+		t.Errorf("Unexpected priority error: %+v", result.Errors[1])
+	}
+}
+
+// TestBuild_StorageFeatureDependencies tests that SFTP and NFSv3 without the
+// features they depend on fail the build
+func TestBuild_StorageFeatureDependencies(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var DataLake = storage.StorageAccount{
+	Name:     "datalake",
+	Location: "eastus",
+	Kind:     "StorageV2",
+	SKU:      storage.SKU{Name: "Standard_LRS"},
+	Properties: &storage.StorageAccountProperties{
+		IsHnsEnabled:   boolPtr(true),
+		IsSftpEnabled:  boolPtr(true),
+		IsNfsV3Enabled: boolPtr(true),
+	},
+}
+
+func boolPtr(b bool) *bool { return &b }
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if result.Success || len(result.Errors) != 1 {
+		t.Fatalf("Expected one validation error, got %+v", result)
+	}
+	if !strings.Contains(result.Errors[0].Message, "DataLake: NFSv3 (IsNfsV3Enabled) requires HTTPS-only traffic to be off") {
+		t.Errorf("Unexpected error: %+v", result.Errors[0])
+	}
+}
+
+func TestBuild_Metadata(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var AppData = storage.StorageAccount{Name: "appdata", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{Metadata: map[string]string{"author": "platform-team", "description": "Core storage"}}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Build() failed: %s", result.Message)
+	}
+
+	var template struct {
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &template); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	generator, ok := template.Metadata["_generator"].(map[string]interface{})
+	if !ok || generator["name"] != "wetwire-azure" || generator["version"] != Version {
+		t.Errorf("Expected the _generator stamp, got %v", template.Metadata["_generator"])
+	}
+	if template.Metadata["author"] != "platform-team" || template.Metadata["description"] != "Core storage" {
+		t.Errorf("Expected custom metadata, got %v", template.Metadata)
+	}
+
+	// The generator stamp cannot be overridden
+	domain.Metadata = map[string]string{"_generator": "hand-written"}
+	result, err = domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if result.Success || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "_generator is reserved") {
+		t.Errorf("Expected a reserved key error, got %+v", result)
+	}
+}
+
+// TestGraph_DiagnosticSettingEdges tests that a diagnostic setting is linked
+// to the resource it monitors and to its destinations
+func TestGraph_DiagnosticSettingEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/insights"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var AppData = storage.StorageAccount{Name: "appdata", Location: "eastus"}
+
+var Archive = storage.StorageAccount{Name: "archive", Location: "eastus"}
+
+var AppDataDiagnostics = insights.DiagnosticSetting{
+	Name:             "send-to-archive",
+	TargetResourceID: AppData.ID(),
+	Properties: insights.DiagnosticSettingProperties{
+		StorageAccountID: Archive.ID(),
+		Metrics:          []insights.MetricSettings{{Category: "AllMetrics", Enabled: true}},
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{`"AppDataDiagnostics" -> "AppData"`, `"AppDataDiagnostics" -> "Archive"`} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+}
+
+// TestBuild_DiagnosticSettingScope tests that a diagnostic setting is built
+// as an extension resource scoped to its target, with no location
+func TestBuild_DiagnosticSettingScope(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/insights"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var AppData = storage.StorageAccount{Name: "appdata", Location: "eastus"}
+
+var AppDataDiagnostics = insights.DiagnosticSetting{
+	Name:             "send-to-workspace",
+	TargetResourceID: AppData.ID(),
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+	result, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil || !result.Success {
+		t.Fatalf("Build() failed: %v %+v", err, result)
+	}
+
+	var built struct {
+		Resources []map[string]interface{} `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &built); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if len(built.Resources) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(built.Resources))
+	}
+	setting := built.Resources[1]
+	if setting["type"] != "Microsoft.Insights/diagnosticSettings" {
+		t.Fatalf("Expected the diagnostic setting second, got %v", setting["type"])
+	}
+	if want := "[resourceId('Microsoft.Storage/storageAccounts', 'appdata')]"; setting["scope"] != want {
+		t.Errorf("scope = %v, want %v", setting["scope"], want)
+	}
+	if location, ok := setting["location"]; ok {
+		t.Errorf("Expected no location, got %v", location)
+	}
+}
+
+// TestGraph_AgentPoolEdge tests that a standalone agent pool is drawn with an
+// edge to its cluster
+func TestGraph_AgentPoolEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/aks"
+
+var AppAKS = aks.ManagedCluster{Name: "app-aks", Location: "eastus"}
+
+var UserPool = aks.AgentPool{
+	Name: AppAKS.Name + "/user",
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	if !strings.Contains(graph, `"UserPool" -> "AppAKS"`) {
+		t.Errorf("Expected edge from the pool to its cluster, got:\n%s", graph)
+	}
+
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err != nil {
+		t.Errorf("Build() error: %v", err)
+	}
+}
+
+// TestGraph_MaintenanceConfigurationEdge tests that a maintenance
+// configuration has an edge to its cluster and builds without a location
+func TestGraph_MaintenanceConfigurationEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/aks"
+
+var AppAKS = aks.ManagedCluster{
+	Name:     "app-aks",
+	Location: "eastus",
+	Properties: aks.ManagedClusterProperties{
+		AutoUpgradeProfile: &aks.ManagedClusterAutoUpgradeProfile{},
+	},
+}
+
+var UpgradeWindow = aks.MaintenanceConfiguration{
+	Name: AppAKS.Name + "/" + aks.MaintenanceConfigurationAutoUpgrade,
+	Properties: aks.MaintenanceConfigurationProperties{
+		MaintenanceWindow: &aks.MaintenanceWindow{
+			Schedule:      aks.MaintenanceSchedule{Weekly: &aks.WeeklySchedule{IntervalWeeks: 1, DayOfWeek: "Sunday"}},
+			DurationHours: 4,
+			StartTime:     "02:00",
+		},
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	if !strings.Contains(graph, `"UpgradeWindow" -> "AppAKS"`) {
+		t.Errorf("Expected edge from the maintenance configuration to its cluster, got:\n%s", graph)
+	}
+
+	built, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	var template struct {
+		Resources []map[string]any `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(built.Data.(string)), &template); err != nil {
+		t.Fatal(err)
+	}
+	for _, resource := range template.Resources {
+		if resource["type"] == "Microsoft.ContainerService/managedClusters/maintenanceConfigurations" {
+			if resource["apiVersion"] != "2023-10-01" {
+				t.Errorf("Expected API version 2023-10-01, got %v", resource["apiVersion"])
+			}
+			if _, ok := resource["location"]; ok {
+				t.Errorf("Expected a maintenance configuration without a location, got %v", resource)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected a maintenance configuration in the template, got %v", template.Resources)
+}
+
+// TestGraph_GeoRedundantAnnotation tests that geo-redundant storage nodes are annotated
+func TestGraph_GeoRedundantAnnotation(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Replicated = storage.StorageAccount{
+	Name: "replicated",
+	SKU:  storage.SKU{Name: "Standard_RAGRS"},
+}
+
+var Local = storage.StorageAccount{
+	Name: "local",
+	SKU:  storage.SKU{Name: "Standard_LRS"},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	dot := result.Data.(string)
+	if !strings.Contains(dot, `"Replicated" [label="Replicated\nMicrosoft.Storage/storageAccounts (RAGRS)", peripheries=2];`) {
+		t.Errorf("Expected annotated node for geo-redundant account, got:\n%s", dot)
+	}
+	if !strings.Contains(dot, `"Local" [label="Local\nMicrosoft.Storage/storageAccounts"];`) {
+		t.Errorf("Expected plain node for locally redundant account, got:\n%s", dot)
+	}
+
+	result, err = domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "mermaid"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	mermaid := result.Data.(string)
+	if !strings.Contains(mermaid, `Replicated["Replicated<br/>Microsoft.Storage/storageAccounts (RAGRS)"]`) {
+		t.Errorf("Expected annotated Mermaid node, got:\n%s", mermaid)
+	}
+}
+
+// TestValidate_Parameters tests that validate --parameters reports extra and
+// missing parameters relative to a template file
+func TestValidate_Parameters(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	templatePath := filepath.Join(tmpDir, "azuredeploy.json")
+	template := `{
+  "parameters": {
+    "location": {"type": "string", "defaultValue": "eastus"},
+    "storageName": {"type": "string"}
+  },
+  "resources": []
+}`
+	if err := os.WriteFile(templatePath, []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paramsPath := filepath.Join(tmpDir, "azuredeploy.parameters.json")
+	params := `{"parameters": {"location": {"value": "westus"}, "skuName": {"value": "Standard_LRS"}}}`
+	if err := os.WriteFile(paramsPath, []byte(params), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{ValidateParameters: paramsPath}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Validator().Validate(ctx, templatePath, ValidateOpts{})
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Expected validation to fail")
+	}
+	var messages []string
+	for _, e := range result.Errors {
+		messages = append(messages, e.Message)
+	}
+	want := []string{
+		"parameters.skuName: parameter is not declared in the template",
+		"parameters.storageName: required parameter has no value in the parameters file",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("Errors = %v, want %v", messages, want)
+	}
+}
+
+// TestValidate_ParametersFromSource tests that validate --parameters builds
+// the template when given a source directory
+func TestValidate_ParametersFromSource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	paramsPath := filepath.Join(t.TempDir(), "params.json")
+	if err := os.WriteFile(paramsPath, []byte(`{"parameters": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{ValidateParameters: paramsPath}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Validator().Validate(ctx, tmpDir, ValidateOpts{})
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected validation to pass, got errors: %v", result.Errors)
+	}
+}
+
+// TestGraph_SSHKeyResourceEdge tests that a VM using a shared SSH key
+// resource has an edge to it
+func TestGraph_SSHKeyResourceEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/compute"
+
+var DeployKey = compute.SSHPublicKeyResource{Name: "deploy-key", Location: "eastus"}
+
+var WebVM = (&compute.VirtualMachine{Name: "web-vm", Location: "eastus"}).WithSSHKeyResource(DeployKey.Name)
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	if !strings.Contains(graph, `"WebVM" -> "DeployKey"`) {
+		t.Errorf("Expected edge from the VM to its SSH key, got:\n%s", graph)
+	}
+}
+
+// TestGraph_VirtualNetworkSubnetEdges tests that a standalone subnet has edges
+// to its virtual network and network security group, and builds
+func TestGraph_VirtualNetworkSubnetEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var WebNSG = network.NetworkSecurityGroup{Name: "web-nsg", Location: "eastus"}
+
+var WebSubnet = network.VirtualNetworkSubnet{
+	Name: AppVNet.Name + "/web",
+	Properties: network.SubnetProperties{
+		AddressPrefix:        "10.0.1.0/24",
+		NetworkSecurityGroup: network.NewSubResource(WebNSG.ID()),
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{`"WebSubnet" -> "AppVNet"`, `"WebSubnet" -> "WebNSG"`} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+
+	result, err = domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Build() failed: %v", result.Errors)
+	}
+}
+
+// TestGraph_CognitiveServicesDeploymentEdge tests that a model deployment
+// has an edge to its account, and that a deployment without one fails to build
+func TestGraph_CognitiveServicesDeploymentEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/cognitiveservices"
+
+var OpenAI = cognitiveservices.Account{Name: "my-openai", Location: "eastus", Kind: "OpenAI"}
+
+var Chat = cognitiveservices.Deployment{Name: OpenAI.Name + "/chat"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	if !strings.Contains(graph, `"Chat" -> "OpenAI"`) {
+		t.Errorf("Expected edge from the deployment to its account, got:\n%s", graph)
+	}
+
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err != nil {
+		t.Errorf("Build() error: %v", err)
+	}
+
+	orphan := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/cognitiveservices"
+
+var Chat = cognitiveservices.Deployment{Name: "chat"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(orphan), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err == nil || !strings.Contains(err.Error(), "has no parent") {
+		t.Errorf("Expected a missing parent error, got %v", err)
+	}
+}
+
+// TestGraph_GrafanaWorkspaceEdge tests that a Grafana instance has an edge to
+// the Azure Monitor workspace it integrates
+func TestGraph_GrafanaWorkspaceEdge(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/dashboard"
+	"github.com/lex00/wetwire-azure-go/resources/insights"
+)
+
+var Prometheus = insights.AzureMonitorWorkspace{Name: "prometheus", Location: "eastus"}
+
+var Grafana = (&dashboard.Grafana{
+	Name:     "ops-grafana",
+	Location: "eastus",
+	SKU:      dashboard.SKU{Name: "Standard"},
+}).WithAzureMonitorWorkspace(Prometheus.ID())
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	if !strings.Contains(graph, `"Grafana" -> "Prometheus"`) {
+		t.Errorf("Expected edge from Grafana to the workspace, got:\n%s", graph)
+	}
+}
+
+// TestGraph_WebSlotAndBinding tests that a deployment slot and a host name
+// binding are drawn depending on their app, the binding also on its
+// certificate, and that the app satisfies their parent requirement on build
+func TestGraph_WebSlotAndBinding(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/web"
+
+var Shop = web.Site{Name: "shop-web", Location: "eastus"}
+
+var ShopStaging = web.SiteSlot{Name: Shop.Name + "/staging", Location: "eastus"}
+
+var ShopCert = web.Certificate{Name: "shop-cert", Location: "eastus"}
+
+var ShopDomain = web.HostNameBinding{
+	Name: Shop.Name + "/shop.example.com",
+	Properties: web.HostNameBindingProperties{
+		SSLState:   "SniEnabled",
+		Thumbprint: ShopCert.Thumbprint(),
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{
+		`"ShopStaging" -> "Shop"`,
+		`"ShopDomain" -> "Shop"`,
+		`"ShopDomain" -> "ShopCert"`,
+	} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+
+	result, err = domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected build to succeed, got %+v", result)
+	}
+}
+
+// TestGraph_VirtualWANEdges tests that a hub connection is drawn depending on
+// its hub and virtual network, and the hub on its virtual WAN
+func TestGraph_VirtualWANEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var WAN = network.VirtualWAN{Name: "corp-wan", Location: "eastus"}
+
+var Hub = network.VirtualHub{
+	Name:       "eastus-hub",
+	Location:   "eastus",
+	Properties: network.VirtualHubProperties{VirtualWan: network.NewSubResource(WAN.ID())},
+}
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var AppConnection = network.HubVirtualNetworkConnection{
+	Name:       Hub.Name + "/app-vnet",
+	Properties: network.HubVirtualNetworkConnectionProperties{RemoteVirtualNetwork: network.NewSubResource(AppVNet.ID())},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{
+		`"Hub" -> "WAN"`,
+		`"AppConnection" -> "Hub"`,
+		`"AppConnection" -> "AppVNet"`,
+	} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+}
+
+// TestGraph_PrivateDNSZoneLinkEdges tests that a private DNS zone link is
+// drawn depending on its zone and virtual network
+func TestGraph_PrivateDNSZoneLinkEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var BlobZone = network.PrivateDNSZone{Name: "privatelink.blob.core.windows.net", Location: "global"}
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var AppLink = network.PrivateDnsZoneVirtualNetworkLink{
+	Name:       BlobZone.Name + "/app-vnet",
+	Location:   "global",
+	Properties: network.PrivateDnsZoneVirtualNetworkLinkProperties{VirtualNetwork: network.NewSubResource(AppVNet.ID())},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{
+		`"AppLink" -> "BlobZone"`,
+		`"AppLink" -> "AppVNet"`,
+	} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+}
+
+// TestGraph_KeyVaultChildEdges tests that keys have edges to their vault, and that a key without one fails to build
+func TestGraph_KeyVaultChildEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var CorpVault = keyvault.Vault{Name: "corp-kv", Location: "eastus"}
+
+var StorageKey = keyvault.Key{
+	Name:       CorpVault.Name + "/storage-key",
+	Properties: keyvault.KeyProperties{Kty: "RSA"},
+}
+
+var CorpHSM = keyvault.ManagedHSM{Name: "corp-hsm", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{
+		`"StorageKey" -> "CorpVault"`,
+	} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+	if strings.Contains(graph, `"CorpHSM" ->`) {
+		t.Errorf("Expected no edges from the HSM pool, got:\n%s", graph)
+	}
+
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err != nil {
+		t.Errorf("Build() error: %v", err)
+	}
+
+	orphan := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var StorageKey = keyvault.Key{Name: "storage-key"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(orphan), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{}); err == nil || !strings.Contains(err.Error(), "has no parent") {
+		t.Errorf("Expected a missing parent error, got %v", err)
+	}
+}
+
+// TestGraph_ManagementLockEdges tests that locks have edges to the resources
+// they lock, whether declared or expanded from WithDeleteLock
+func TestGraph_ManagementLockEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/authorization"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = (&storage.StorageAccount{Name: "logs", Location: "eastus"}).WithDeleteLock()
+
+var Archive = storage.StorageAccount{Name: "archive", Location: "eastus"}
+
+var ArchiveReadOnly = authorization.ManagementLock{
+	Name:       "archive-read-only",
+	Scope:      Archive.ID(),
+	Properties: authorization.ManagementLockProperties{Level: authorization.LockLevelReadOnly},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{
+		`"LogsDeleteLock" -> "Logs"`,
+		`"ArchiveReadOnly" -> "Archive"`,
+	} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+
+	built, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	template := built.Data.(string)
+	for _, want := range []string{
+		`"scope": "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]"`,
+		`"level": "CanNotDelete"`,
+	} {
+		if !strings.Contains(template, want) {
+			t.Errorf("Expected template to contain %s, got:\n%s", want, template)
+		}
+	}
+}
+
+// TestGraph_SQLHighAvailabilityEdges tests that pools have edges to their
+// server, databases to their pool, and failover groups to their partner
+// server and databases, and that the group builds without a location
+func TestGraph_SQLHighAvailabilityEdges(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/sql"
+
+var Primary = sql.Server{Name: "orders-sql", Location: "eastus"}
+
+var Secondary = sql.Server{Name: "orders-sql-west", Location: "westus"}
+
+var Pool = sql.ElasticPool{
+	Name:     Primary.Name + "/orders-pool",
+	Location: "eastus",
+	SKU:      sql.SKU{Name: "StandardPool", Tier: "Standard"},
+}
+
+var Orders = sql.Database{
+	Name:       Primary.Name + "/orders",
+	Location:   "eastus",
+	Properties: sql.DatabaseProperties{ElasticPoolID: Pool.ID()},
+}
+
+var OrdersFailover = sql.FailoverGroup{
+	Name: Primary.Name + "/orders-fog",
+	Properties: sql.FailoverGroupProperties{
+		PartnerServers: []sql.PartnerServer{{ID: Secondary.ID()}},
+		Databases:      []string{Orders.ID()},
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{}
+	ctx := NewContext(context.Background(), tmpDir)
+
+	result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+	if err != nil {
+		t.Fatalf("Graph() error: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, edge := range []string{
+		`"Pool" -> "Primary"`,
+		`"Orders" -> "Pool"`,
+		`"OrdersFailover" -> "Secondary"`,
+		`"OrdersFailover" -> "Orders"`,
+	} {
+		if !strings.Contains(graph, edge) {
+			t.Errorf("Expected edge %s, got:\n%s", edge, graph)
+		}
+	}
+
+	built, err := domain.Builder().Build(ctx, tmpDir, BuildOpts{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	var template struct {
+		Resources []map[string]any `json:"resources"`
+	}
+	if err := json.Unmarshal([]byte(built.Data.(string)), &template); err != nil {
+		t.Fatal(err)
+	}
+	for _, resource := range template.Resources {
+		if resource["type"] == "Microsoft.Sql/servers/failoverGroups" {
+			if _, ok := resource["location"]; ok {
+				t.Errorf("Expected a failover group without a location, got %v", resource)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected a failover group in the template, got %v", template.Resources)
+}
+
+// TestList_DependsOn tests that list --depends-on resolves a NIC -> subnet -> VNet chain
+func TestList_DependsOn(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var WebNIC = network.NetworkInterface{
+	Name:     "web-nic",
+	Location: "eastus",
+	Properties: network.NetworkInterfaceProperties{
+		IPConfigurations: []network.IPConfiguration{{
+			Name: "ipconfig1",
+			Properties: network.IPConfigurationProperties{
+				Subnet: network.NewSubResource(AppSubnet.ID()),
+			},
+		}},
+	},
+}
+
+var AppSubnet = network.Subnet{Name: VNet.Name + "/app"}
+
+var VNet = network.VirtualNetwork{Name: "vnet", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	domain := &AzureDomain{ListDependsOn: true}
+	result, err := domain.Lister().List(NewContext(context.Background(), tmpDir), tmpDir, ListOpts{})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	trees, ok := result.Data.([]*dependencyNode)
+	if !ok || len(trees) != 3 {
+		t.Fatalf("Expected 3 dependency trees, got %#v", result.Data)
+	}
+
+	nic := trees[0]
+	if nic.Name != "WebNIC" || len(nic.DependsOn) != 1 {
+		t.Fatalf("Expected WebNIC to depend on one resource, got %+v", nic)
+	}
+	subnet := nic.DependsOn[0]
+	if subnet.Name != "AppSubnet" || len(subnet.DependsOn) != 1 || subnet.DependsOn[0].Name != "VNet" {
+		t.Errorf("Expected WebNIC -> AppSubnet -> VNet, got %+v", subnet)
+	}
+	if len(trees[2].DependsOn) != 0 {
+		t.Errorf("Expected VNet to have no dependencies, got %+v", trees[2].DependsOn)
+	}
+
+	// The nested JSON form keeps the tree
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"name":"WebNIC","type":"Microsoft.Network/networkInterfaces","dependsOn":[{"name":"AppSubnet"`) {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	var out bytes.Buffer
+	writeDependencyTrees(&out, trees[:1])
+	want := "WebNIC (Microsoft.Network/networkInterfaces)\n" +
+		"└── AppSubnet (Microsoft.Network/subnets)\n" +
+		"    └── VNet (Microsoft.Network/virtualNetworks)\n"
+	if out.String() != want {
+		t.Errorf("Unexpected tree:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+// TestList_Estimate tests that --estimate annotates resources with cost tiers
+// from their SKU or VM size
+func TestList_Estimate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = storage.StorageAccount{
+	Name: "logs",
+	SKU:  storage.SKU{Name: "Standard_LRS"},
+}
+
+var Worker = compute.VirtualMachine{
+	Name: "worker",
+	Properties: compute.VirtualMachineProperties{
+		HardwareProfile: compute.HardwareProfile{VMSize: "Standard_D2s_v3"},
+	},
+}
+
+var Jumpbox = compute.VirtualMachine{
+	Name: "jumpbox",
+	Properties: compute.VirtualMachineProperties{
+		HardwareProfile: compute.HardwareProfile{VMSize: "Standard_B2s"},
+	},
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	tiers := func(d *AzureDomain) map[string]string {
+		t.Helper()
+		result, err := d.Lister().List(NewContext(context.Background(), tmpDir), tmpDir, ListOpts{})
+		if err != nil {
+			t.Fatalf("List() error: %v", err)
+		}
+		tiers := make(map[string]string)
+		for _, entry := range result.Data.([]map[string]string) {
+			if tier, ok := entry["tier"]; ok {
+				tiers[entry["name"]] = tier
+			}
+		}
+		return tiers
+	}
+
+	if got := tiers(&AzureDomain{}); len(got) != 0 {
+		t.Errorf("Expected no tiers without ListEstimate, got %v", got)
+	}
+
+	want := map[string]string{"Logs": "low", "Worker": "medium", "Jumpbox": "low"}
+	got := tiers(&AzureDomain{ListEstimate: true})
+	for name, tier := range want {
+		if got[name] != tier {
+			t.Errorf("Expected %s tier %q, got %q", name, tier, got[name])
+		}
+	}
+}
+
+// TestList_DependsOnCycle tests that a dependency cycle is marked rather than expanded forever
+func TestList_DependsOnCycle(t *testing.T) {
+	trees := dependencyTrees([]discover.DiscoveredResource{
+		{Name: "A", Type: "Microsoft.Network/virtualNetworks", Dependencies: []string{"B", "Missing"}},
+		{Name: "B", Type: "Microsoft.Network/virtualNetworks", Dependencies: []string{"A"}},
+	})
+
+	var out bytes.Buffer
+	writeDependencyTrees(&out, trees)
+	want := "A (Microsoft.Network/virtualNetworks)\n" +
+		"└── B (Microsoft.Network/virtualNetworks)\n" +
+		"    └── A (Microsoft.Network/virtualNetworks) [cycle]\n" +
+		"B (Microsoft.Network/virtualNetworks)\n" +
+		"└── A (Microsoft.Network/virtualNetworks)\n" +
+		"    └── B (Microsoft.Network/virtualNetworks) [cycle]\n"
+	if out.String() != want {
+		t.Errorf("Unexpected tree:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if !trees[0].DependsOn[0].DependsOn[0].Cycle {
+		t.Error("Expected the repeated resource to be marked as a cycle")
+	}
+}
+
+// TestGraph_RootsDepth tests that graph --roots --depth keeps only resources
+// within depth hops of the roots, on an A -> B -> C -> D chain
+func TestGraph_RootsDepth(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var A = storage.StorageAccount{Name: "a", Location: "eastus", Tags: map[string]string{"next": B.Name}}
+
+var B = storage.StorageAccount{Name: "b", Location: "eastus", Tags: map[string]string{"next": C.Name}}
+
+var C = storage.StorageAccount{Name: "c", Location: "eastus", Tags: map[string]string{"next": D.Name}}
+
+var D = storage.StorageAccount{Name: "d", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		roots   []string
+		depth   int
+		nodes   []string
+		missing []string
+	}{
+		{"no roots", nil, 0, []string{"A", "B", "C", "D"}, nil},
+		{"unlimited", []string{"B"}, 0, []string{"B", "C", "D"}, []string{"A"}},
+		{"whole chain", []string{"A"}, 0, []string{"A", "B", "C", "D"}, nil},
+		{"depth 1", []string{"A"}, 1, []string{"A", "B"}, []string{"C", "D"}},
+		{"depth 2", []string{"A"}, 2, []string{"A", "B", "C"}, []string{"D"}},
+		{"two roots", []string{"A", "C"}, 1, []string{"A", "B", "C", "D"}, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			domain := &AzureDomain{GraphRoots: tt.roots, GraphDepth: tt.depth}
+			ctx := NewContext(context.Background(), tmpDir)
+
+			result, err := domain.Grapher().Graph(ctx, tmpDir, GraphOpts{Format: "dot"})
+			if err != nil {
+				t.Fatalf("Graph() error: %v", err)
+			}
+			graph := result.Data.(string)
+			for i, node := range tt.nodes {
+				if !strings.Contains(graph, `"`+node+`" [label=`) {
+					t.Errorf("Expected node %s, got:\n%s", node, graph)
+				}
+				// Each kept node but the last depends on the next one
+				if i > 0 && !strings.Contains(graph, `"`+tt.nodes[i-1]+`" -> "`+node+`"`) {
+					t.Errorf("Expected edge %s -> %s, got:\n%s", tt.nodes[i-1], node, graph)
+				}
+			}
+			for _, node := range tt.missing {
+				if strings.Contains(graph, `"`+node+`"`) {
+					t.Errorf("Expected no node or edge for %s, got:\n%s", node, graph)
+				}
+			}
+		})
+	}
+
+	domain := &AzureDomain{GraphRoots: []string{"Missing"}}
+	if _, err := domain.Grapher().Graph(NewContext(context.Background(), tmpDir), tmpDir, GraphOpts{Format: "dot"}); err == nil {
+		t.Error("Expected an error for an unknown root")
+	}
+	domain = &AzureDomain{GraphDepth: 1}
+	if _, err := domain.Grapher().Graph(NewContext(context.Background(), tmpDir), tmpDir, GraphOpts{Format: "dot"}); err == nil {
+		t.Error("Expected an error for --depth without --roots")
+	}
+}
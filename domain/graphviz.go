@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// lookPath and execCommand find and run the Graphviz dot binary. Tests
+// replace them to run without Graphviz installed.
+var (
+	lookPath    = exec.LookPath
+	execCommand = exec.Command
+)
+
+// renderSVG renders a DOT graph to SVG with the Graphviz dot binary on PATH.
+// It returns an error naming the missing binary if Graphviz is not installed.
+func renderSVG(dot string) (string, error) {
+	path, err := lookPath("dot")
+	if err != nil {
+		return "", fmt.Errorf("svg output requires the Graphviz dot binary on PATH (https://graphviz.org/download/); " +
+			"install Graphviz, or use -f dot and render the graph yourself")
+	}
+
+	cmd := execCommand(path, "-Tsvg")
+	cmd.Stdin = strings.NewReader(dot)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("dot -Tsvg failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("dot -Tsvg failed: %w", err)
+	}
+	return string(out), nil
+}
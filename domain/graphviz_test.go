@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDotHelperProcess stands in for the dot binary in tests that replace
+// execCommand. It writes its arguments and the DOT it reads as an SVG comment.
+func TestDotHelperProcess(t *testing.T) {
+	if os.Getenv("WETWIRE_WANT_DOT_HELPER") != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	dot, _ := io.ReadAll(os.Stdin)
+	fmt.Printf("<svg><!-- %s\n%s --></svg>\n", strings.Join(args, " "), dot)
+	os.Exit(0)
+}
+
+// fakeDot replaces lookPath and execCommand with a dot binary at
+// /usr/bin/dot that runs TestDotHelperProcess, recording the commands run
+func fakeDot(t *testing.T) *[][]string {
+	t.Helper()
+	origLookPath, origExecCommand := lookPath, execCommand
+	t.Cleanup(func() { lookPath, execCommand = origLookPath, origExecCommand })
+
+	var commands [][]string
+	lookPath = func(file string) (string, error) {
+		return "/usr/bin/" + file, nil
+	}
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		commands = append(commands, append([]string{name}, args...))
+		cs := append([]string{"-test.run=TestDotHelperProcess", "--", name}, args...)
+		cmd := exec.Command(os.Args[0], cs...)
+		cmd.Env = append(os.Environ(), "WETWIRE_WANT_DOT_HELPER=1")
+		return cmd
+	}
+	return &commands
+}
+
+func writeGraphSource(t *testing.T) string {
+	t.Helper()
+	srcDir := t.TempDir()
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return srcDir
+}
+
+// TestGraphCmd_SVG tests that -f svg pipes the DOT graph to dot -Tsvg and
+// writes what it renders
+func TestGraphCmd_SVG(t *testing.T) {
+	commands := fakeDot(t)
+	srcDir := writeGraphSource(t)
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"graph", srcDir, "-f", "svg"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("graph -f svg error: %v\n%s", err, out.String())
+	}
+
+	if len(*commands) != 1 || strings.Join((*commands)[0], " ") != "/usr/bin/dot -Tsvg" {
+		t.Fatalf("expected one dot -Tsvg invocation, got %v", *commands)
+	}
+	if !strings.HasPrefix(out.String(), "<svg><!-- /usr/bin/dot -Tsvg\ndigraph") || !strings.Contains(out.String(), "MyStorage") {
+		t.Errorf("expected the rendered DOT graph, got:\n%s", out.String())
+	}
+}
+
+// TestGraph_SVGWithoutGraphviz tests that -f svg explains how to install
+// Graphviz when dot is not on PATH
+func TestGraph_SVGWithoutGraphviz(t *testing.T) {
+	origLookPath := lookPath
+	t.Cleanup(func() { lookPath = origLookPath })
+	lookPath = func(file string) (string, error) {
+		return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+	}
+	srcDir := writeGraphSource(t)
+
+	domain := &AzureDomain{}
+	_, err := domain.Grapher().Graph(NewContext(context.Background(), srcDir), srcDir, GraphOpts{Format: "svg"})
+	if err == nil || !strings.Contains(err.Error(), "requires the Graphviz dot binary") {
+		t.Errorf("expected a missing Graphviz error, got %v", err)
+	}
+}
+
+// TestRenderSVG_Graphviz renders a graph with the installed dot binary
+func TestRenderSVG_Graphviz(t *testing.T) {
+	if _, err := exec.LookPath("dot"); err != nil {
+		t.Skip("Graphviz dot is not installed")
+	}
+
+	svg, err := renderSVG("digraph {\n  MyStorage -> MyVNet;\n}\n")
+	if err != nil {
+		t.Fatalf("renderSVG() error: %v", err)
+	}
+	if !strings.Contains(svg, "<svg") || !strings.Contains(svg, "MyStorage") {
+		t.Errorf("expected an SVG of the graph, got:\n%s", svg)
+	}
+
+	if _, err := renderSVG("digraph {"); err == nil || !strings.Contains(err.Error(), "dot -Tsvg failed") {
+		t.Errorf("expected dot to reject an invalid graph, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultInitTemplate is the starter template used when init is not given one
+const DefaultInitTemplate = "storage"
+
+// DefaultInitLocation is the Azure region written into starter templates
+const DefaultInitLocation = "eastus"
+
+// starterTemplate is example code that init writes to main.go
+type starterTemplate struct {
+	Name        string
+	Description string
+	// Source is the main.go content; $LOCATION is replaced with the default location
+	Source string
+}
+
+// starterTemplates are the templates available to init --template, in the
+// order the wizard lists them
+var starterTemplates = []starterTemplate{
+	{
+		Name:        "storage",
+		Description: "A general-purpose v2 storage account",
+		Source: `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+// Example storage account resource
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorageaccount",
+	Location: "$LOCATION",
+	SKU: storage.SKU{
+		Name: "Standard_LRS",
+	},
+	Kind: "StorageV2",
+}
+`,
+	},
+	{
+		Name:        "network",
+		Description: "A virtual network and a network security group",
+		Source: `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+)
+
+// Example network security group
+var MyNSG = network.NetworkSecurityGroup{
+	Name:     "my-nsg",
+	Location: "$LOCATION",
+}
+
+// Example virtual network
+var MyVNet = network.VirtualNetwork{
+	Name:     "my-vnet",
+	Location: "$LOCATION",
+	Properties: network.VirtualNetworkProperties{
+		AddressSpace: network.AddressSpace{
+			AddressPrefixes: []string{"10.0.0.0/16"},
+		},
+	},
+}
+`,
+	},
+	{
+		Name:        "empty",
+		Description: "No resources",
+		Source: `package main
+
+// Declare resources as package-level variables, for example:
+//
+//	var MyStorage = storage.StorageAccount{Name: "mystorageaccount", Location: "$LOCATION"}
+`,
+	},
+}
+
+// lookupStarterTemplate returns the starter template with the given name
+func lookupStarterTemplate(name string) (starterTemplate, error) {
+	for _, t := range starterTemplates {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return starterTemplate{}, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(starterTemplateNames(), ", "))
+}
+
+// starterTemplateNames returns the names of the starter templates
+func starterTemplateNames() []string {
+	names := make([]string, len(starterTemplates))
+	for i, t := range starterTemplates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// render returns the template source for location
+func (t starterTemplate) render(location string) string {
+	return strings.ReplaceAll(t.Source, "$LOCATION", location)
+}
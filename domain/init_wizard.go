@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// initOptions are the project options init --wizard prompts for
+type initOptions struct {
+	Module   string
+	Location string
+	Template string
+}
+
+// runInitWizard prompts on out for each project option and reads the answers
+// from in, one per line. An empty answer, or the end of input, keeps the value
+// in defaults. The template may be given by name or by its number in the list.
+func runInitWizard(in io.Reader, out io.Writer, defaults initOptions) (initOptions, error) {
+	reader := bufio.NewReader(in)
+	opts := defaults
+
+	var err error
+	if opts.Module, err = promptLine(reader, out, "Module name", defaults.Module); err != nil {
+		return opts, err
+	}
+	if opts.Location, err = promptLine(reader, out, "Default location", defaults.Location); err != nil {
+		return opts, err
+	}
+
+	fmt.Fprintln(out, "Starter templates:")
+	for i, t := range starterTemplates {
+		fmt.Fprintf(out, "  %d. %-8s %s\n", i+1, t.Name, t.Description)
+	}
+	for {
+		answer, err := promptLine(reader, out, "Starter template", defaults.Template)
+		if err != nil {
+			return opts, err
+		}
+		if name, ok := resolveTemplateAnswer(answer); ok {
+			opts.Template = name
+			return opts, nil
+		}
+		fmt.Fprintf(out, "Unknown template %q; choose one of %s\n", answer, strings.Join(starterTemplateNames(), ", "))
+		if answer == defaults.Template {
+			// The default itself is invalid; asking again cannot help
+			return opts, fmt.Errorf("unknown template %q", answer)
+		}
+	}
+}
+
+// promptLine prints a prompt showing def and reads one line. It returns def
+// for an empty line or at the end of input.
+func promptLine(reader *bufio.Reader, out io.Writer, label, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", label)
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read %s: %w", strings.ToLower(label), err)
+	}
+	if errors.Is(err, io.EOF) && line == "" {
+		// Keep the rest of the output on its own line
+		fmt.Fprintln(out)
+	}
+
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// resolveTemplateAnswer maps a template name or list number to a template name
+func resolveTemplateAnswer(answer string) (string, bool) {
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n >= 1 && n <= len(starterTemplates) {
+			return starterTemplates[n-1].Name, true
+		}
+		return "", false
+	}
+	if _, err := lookupStarterTemplate(answer); err != nil {
+		return "", false
+	}
+	return answer, true
+}
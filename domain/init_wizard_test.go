@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunInitWizard tests that answers from the reader override the defaults
+func TestRunInitWizard(t *testing.T) {
+	in := strings.NewReader("github.com/acme/infra\nwesteurope\n2\n")
+	var out bytes.Buffer
+
+	opts, err := runInitWizard(in, &out, initOptions{
+		Module:   "infra",
+		Location: DefaultInitLocation,
+		Template: DefaultInitTemplate,
+	})
+	if err != nil {
+		t.Fatalf("runInitWizard() error: %v", err)
+	}
+
+	want := initOptions{Module: "github.com/acme/infra", Location: "westeurope", Template: "network"}
+	if opts != want {
+		t.Errorf("runInitWizard() = %+v, want %+v", opts, want)
+	}
+	for _, prompt := range []string{"Module name [infra]: ", "Default location [eastus]: ", "Starter template [storage]: ", "2. network"} {
+		if !strings.Contains(out.String(), prompt) {
+			t.Errorf("Expected output to contain %q, got:\n%s", prompt, out.String())
+		}
+	}
+}
+
+// TestRunInitWizard_Defaults tests that empty answers and end of input keep the defaults
+func TestRunInitWizard_Defaults(t *testing.T) {
+	defaults := initOptions{Module: "infra", Location: "eastus", Template: "storage"}
+
+	for _, input := range []string{"\n\n\n", "", "\n"} {
+		opts, err := runInitWizard(strings.NewReader(input), &bytes.Buffer{}, defaults)
+		if err != nil {
+			t.Fatalf("runInitWizard(%q) error: %v", input, err)
+		}
+		if opts != defaults {
+			t.Errorf("runInitWizard(%q) = %+v, want %+v", input, opts, defaults)
+		}
+	}
+}
+
+// TestRunInitWizard_InvalidTemplate tests that an unknown template is asked for again
+func TestRunInitWizard_InvalidTemplate(t *testing.T) {
+	in := strings.NewReader("\n\nkubernetes\n9\nempty\n")
+	var out bytes.Buffer
+
+	opts, err := runInitWizard(in, &out, initOptions{Module: "infra", Location: "eastus", Template: "storage"})
+	if err != nil {
+		t.Fatalf("runInitWizard() error: %v", err)
+	}
+	if opts.Template != "empty" {
+		t.Errorf("Template = %q, want empty", opts.Template)
+	}
+	if !strings.Contains(out.String(), `Unknown template "kubernetes"`) || !strings.Contains(out.String(), `Unknown template "9"`) {
+		t.Errorf("Expected unknown template messages, got:\n%s", out.String())
+	}
+}
+
+// TestInit_WizardAnswers tests that the wizard's answers shape the scaffolded files
+func TestInit_WizardAnswers(t *testing.T) {
+	tmpDir := t.TempDir()
+	targetPath := filepath.Join(tmpDir, "infra")
+
+	opts, err := runInitWizard(strings.NewReader("github.com/acme/infra\nwestus2\nnetwork\n"), &bytes.Buffer{},
+		initOptions{Module: "infra", Location: DefaultInitLocation, Template: DefaultInitTemplate})
+	if err != nil {
+		t.Fatalf("runInitWizard() error: %v", err)
+	}
+
+	d := &AzureDomain{InitModule: opts.Module, InitLocation: opts.Location, InitTemplate: opts.Template}
+	result, err := d.Initializer().Init(NewContext(context.Background(), tmpDir), tmpDir, InitOpts{Path: targetPath})
+	if err != nil {
+		t.Fatalf("Init() error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Init() failed: %s", result.Message)
+	}
+
+	goMod, err := os.ReadFile(filepath.Join(targetPath, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(goMod), "module github.com/acme/infra\n") {
+		t.Errorf("Expected go.mod to declare the module, got:\n%s", goMod)
+	}
+
+	mainGo, err := os.ReadFile(filepath.Join(targetPath, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(mainGo), "network.VirtualNetwork{") {
+		t.Errorf("Expected the network template, got:\n%s", mainGo)
+	}
+	if strings.Contains(string(mainGo), "$LOCATION") || !strings.Contains(string(mainGo), `Location: "westus2"`) {
+		t.Errorf("Expected location westus2, got:\n%s", mainGo)
+	}
+
+	// The scaffolded resources are discoverable
+	buildResult, err := d.Builder().Build(NewContext(context.Background(), targetPath), targetPath, BuildOpts{DryRun: true})
+	if err != nil || !buildResult.Success {
+		t.Errorf("Expected the scaffolded project to build, got %v %+v", err, buildResult)
+	}
+}
+
+// TestInitCmd_WizardNonTTY tests that --wizard falls back to flags without reading stdin
+func TestInitCmd_WizardNonTTY(t *testing.T) {
+	targetPath := filepath.Join(t.TempDir(), "infra")
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	// Answers that would change the template if they were read
+	root.SetIn(strings.NewReader("other\nwestus\nempty\n"))
+	root.SetArgs([]string{"init", "--wizard", "--path", targetPath, "--template", "network", "--location", "northeurope"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("init --wizard error: %v\n%s", err, errOut.String())
+	}
+
+	if !strings.Contains(errOut.String(), "stdin is not a terminal") {
+		t.Errorf("Expected a non-terminal notice, got: %s", errOut.String())
+	}
+	mainGo, err := os.ReadFile(filepath.Join(targetPath, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(mainGo), "network.VirtualNetwork{") || !strings.Contains(string(mainGo), `"northeurope"`) {
+		t.Errorf("Expected the flag values to be used, got:\n%s", mainGo)
+	}
+}
+
+// TestInitCmd_UnknownTemplate tests that an unknown --template is rejected
+func TestInitCmd_UnknownTemplate(t *testing.T) {
+	targetPath := filepath.Join(t.TempDir(), "infra")
+
+	d := &AzureDomain{}
+	root := CreateRootCommand(d)
+	ExtendCommands(root, d)
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"init", "--path", targetPath, "--template", "kubernetes"})
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), `unknown template "kubernetes"`) {
+		t.Errorf("Expected an unknown template error, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(targetPath, "go.mod")); statErr == nil {
+		t.Error("Expected no files to be written")
+	}
+}
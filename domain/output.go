@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+)
+
+// writeOutputFile writes data to the file at path, replacing any existing file
+func writeOutputFile(path string, data io.WriterTo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := data.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeResult writes result to w in format, returning an ExitError if the
+// result is a failure. Commands write through it rather than to os.Stdout so
+// their output can be redirected with cobra.Command.SetOut.
+func writeResult(w io.Writer, result *Result, format string) error {
+	output, err := coredomain.FormatResult(result, format)
+	if err != nil {
+		return fmt.Errorf("failed to format result: %w", err)
+	}
+	fmt.Fprint(w, output)
+
+	if !result.Success {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
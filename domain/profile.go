@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"strings"
+)
+
+// startProfile starts the profile described by spec, in the form
+// <kind>=<path>, and returns a function that stops it and closes the output.
+// Only the cpu kind is supported. An empty spec profiles nothing.
+func startProfile(spec string) (stop func() error, err error) {
+	if spec == "" {
+		return func() error { return nil }, nil
+	}
+
+	kind, path, ok := strings.Cut(spec, "=")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid profile %q: want cpu=<file>", spec)
+	}
+	if kind != "cpu" {
+		return nil, fmt.Errorf("unsupported profile kind %q: only cpu is supported", kind)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("start cpu profile: %w", err)
+	}
+	return func() error {
+		pprof.StopCPUProfile()
+		return f.Close()
+	}, nil
+}
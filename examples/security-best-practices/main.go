@@ -273,6 +273,10 @@ var EncryptedStorage = storage.StorageAccount{
 		EnableHTTPSTrafficOnly: boolPtr(true),
 		MinimumTLSVersion:      strPtr("TLS1_2"),
 		AllowBlobPublicAccess:  boolPtr(false),
+		NetworkRuleSet: &storage.NetworkRuleSet{
+			DefaultAction: "Deny", // Deny by default
+			Bypass:        strPtr("AzureServices"),
+		},
 		// Note: Customer-managed keys require additional KeyVault configuration
 		// This demonstrates the security baseline
 		Encryption: &storage.Encryption{
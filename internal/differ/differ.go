@@ -7,6 +7,8 @@ import (
 	"os"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/lex00/wetwire-azure-go/internal/template"
 	coredomain "github.com/lex00/wetwire-core-go/domain"
@@ -14,7 +16,9 @@ import (
 )
 
 // ARMDiffer implements coredomain.Differ for ARM templates.
-type ARMDiffer struct{}
+type ARMDiffer struct {
+	ignorePaths []string
+}
 
 // Compile-time check that ARMDiffer implements Differ.
 var _ coredomain.Differ = (*ARMDiffer)(nil)
@@ -24,14 +28,29 @@ func New() *ARMDiffer {
 	return &ARMDiffer{}
 }
 
+// WithIgnorePaths makes Diff remove the fields at paths from both templates
+// before comparing them, for fields that legitimately differ such as
+// provisioningState. A path is a dot-separated list of object keys and array
+// indexes from the template root, where * matches every key or element (e.g.
+// "resources.*.properties.provisioningState").
+func (d *ARMDiffer) WithIgnorePaths(paths ...string) *ARMDiffer {
+	d.ignorePaths = paths
+	return d
+}
+
 // Diff compares two ARM templates and returns a domain DiffResult.
 func (d *ARMDiffer) Diff(ctx *coredomain.Context, file1, file2 string, opts coredomain.DiffOpts) (*coredomain.DiffResult, error) {
-	t1, err := loadTemplate(file1)
+	ignore, err := parseIgnorePaths(d.ignorePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	t1, err := loadTemplate(file1, ignore)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load %s: %w", file1, err)
 	}
 
-	t2, err := loadTemplate(file2)
+	t2, err := loadTemplate(file2, ignore)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load %s: %w", file2, err)
 	}
@@ -39,13 +58,35 @@ func (d *ARMDiffer) Diff(ctx *coredomain.Context, file1, file2 string, opts core
 	return compare(t1, t2, opts)
 }
 
-// loadTemplate loads an ARM template from a file (supports JSON and YAML).
-func loadTemplate(path string) (*template.ARMTemplate, error) {
+// parseIgnorePaths splits each ignore path into its segments.
+func parseIgnorePaths(paths []string) ([][]string, error) {
+	parsed := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		for _, segment := range segments {
+			if segment == "" {
+				return nil, fmt.Errorf("invalid ignore path %q: empty segment", path)
+			}
+		}
+		parsed = append(parsed, segments)
+	}
+	return parsed, nil
+}
+
+// loadTemplate loads an ARM template from a file (supports JSON and YAML),
+// removing the fields at the ignore paths.
+func loadTemplate(path string, ignore [][]string) (*template.ARMTemplate, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(ignore) > 0 {
+		if data, err = pruneTemplate(data, ignore); err != nil {
+			return nil, err
+		}
+	}
+
 	var t template.ARMTemplate
 
 	// Try JSON first
@@ -59,6 +100,58 @@ func loadTemplate(path string) (*template.ARMTemplate, error) {
 	return &t, nil
 }
 
+// pruneTemplate returns the template in data as JSON with the fields at the
+// ignore paths removed.
+func pruneTemplate(data []byte, ignore [][]string) ([]byte, error) {
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("failed to parse as JSON or YAML: %w", err)
+		}
+	}
+	for _, segments := range ignore {
+		prunePath(generic, segments)
+	}
+	return json.Marshal(generic)
+}
+
+// prunePath removes the fields matching segments from v, along with any
+// object that is left empty by their removal. It reports whether it removed
+// anything.
+func prunePath(v interface{}, segments []string) bool {
+	segment, rest := segments[0], segments[1:]
+	removed := false
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			if segment != "*" && segment != key {
+				continue
+			}
+			if len(rest) == 0 {
+				delete(val, key)
+				removed = true
+			} else if prunePath(child, rest) {
+				removed = true
+				if m, ok := child.(map[string]interface{}); ok && len(m) == 0 {
+					delete(val, key)
+				}
+			}
+		}
+	case []interface{}:
+		// Array elements are only descended into; removing one would shift
+		// the others and pair them up differently.
+		if len(rest) == 0 {
+			return false
+		}
+		for i, child := range val {
+			if segment == "*" || segment == strconv.Itoa(i) {
+				removed = prunePath(child, rest) || removed
+			}
+		}
+	}
+	return removed
+}
+
 // compare compares two ARM templates and returns differences.
 func compare(t1, t2 *template.ARMTemplate, opts coredomain.DiffOpts) (*coredomain.DiffResult, error) {
 	result := &coredomain.DiffResult{}
@@ -89,6 +182,9 @@ func compare(t1, t2 *template.ARMTemplate, opts coredomain.DiffOpts) (*coredomai
 		}
 	}
 
+	// Pair removed and added resources that differ only by name
+	detectRenames(result, res1, res2, opts)
+
 	// Find modified resources
 	for name, r1 := range res1 {
 		if r2, exists := res2[name]; exists {
@@ -107,13 +203,14 @@ func compare(t1, t2 *template.ARMTemplate, opts coredomain.DiffOpts) (*coredomai
 	// Sort entries for consistent output (added, modified, removed)
 	sort.Slice(result.Entries, func(i, j int) bool {
 		if result.Entries[i].Action != result.Entries[j].Action {
-			order := map[string]int{"added": 0, "modified": 1, "removed": 2}
+			order := map[string]int{"added": 0, "modified": 1, "renamed": 2, "removed": 3}
 			return order[result.Entries[i].Action] < order[result.Entries[j].Action]
 		}
 		return result.Entries[i].Resource < result.Entries[j].Resource
 	})
 
 	// Calculate summary
+	renamed := 0
 	for _, e := range result.Entries {
 		switch e.Action {
 		case "added":
@@ -122,13 +219,110 @@ func compare(t1, t2 *template.ARMTemplate, opts coredomain.DiffOpts) (*coredomai
 			result.Summary.Removed++
 		case "modified":
 			result.Summary.Modified++
+		case "renamed":
+			renamed++
 		}
 	}
-	result.Summary.Total = result.Summary.Added + result.Summary.Removed + result.Summary.Modified
+	result.Summary.Total = result.Summary.Added + result.Summary.Removed + result.Summary.Modified + renamed
 
 	return result, nil
 }
 
+// detectRenames replaces removed/added pairs that share a type and have
+// identical content (ignoring the name, and array order with
+// opts.IgnoreOrder) with a single "renamed" entry.
+func detectRenames(result *coredomain.DiffResult, res1, res2 map[string]template.ARMResource, opts coredomain.DiffOpts) {
+	var removed, added []coredomain.DiffEntry
+	var others []coredomain.DiffEntry
+	for _, e := range result.Entries {
+		switch e.Action {
+		case "removed":
+			removed = append(removed, e)
+		case "added":
+			added = append(added, e)
+		default:
+			others = append(others, e)
+		}
+	}
+	if len(removed) == 0 || len(added) == 0 {
+		return
+	}
+
+	// Iterate in name order so pairing is deterministic
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Resource < removed[j].Resource })
+	sort.Slice(added, func(i, j int) bool { return added[i].Resource < added[j].Resource })
+
+	matched := make(map[string]bool)
+	var remaining []coredomain.DiffEntry
+	for _, rem := range removed {
+		oldRes := res1[rem.Resource]
+		oldJSON, err := normalizeJSON(withoutName(oldRes), opts.IgnoreOrder)
+		if err != nil {
+			remaining = append(remaining, rem)
+			continue
+		}
+
+		found := false
+		for _, add := range added {
+			if matched[add.Resource] || add.Type != rem.Type {
+				continue
+			}
+			newJSON, err := normalizeJSON(withoutName(res2[add.Resource]), opts.IgnoreOrder)
+			if err != nil || newJSON != oldJSON {
+				continue
+			}
+			matched[add.Resource] = true
+			others = append(others, coredomain.DiffEntry{
+				Resource: add.Resource,
+				Type:     add.Type,
+				Action:   "renamed",
+				Changes:  []string{fmt.Sprintf("renamed from %s", rem.Resource)},
+			})
+			found = true
+			break
+		}
+		if !found {
+			remaining = append(remaining, rem)
+		}
+	}
+
+	for _, add := range added {
+		if !matched[add.Resource] {
+			remaining = append(remaining, add)
+		}
+	}
+
+	result.Entries = append(others, remaining...)
+}
+
+// withoutName returns a copy of the resource with its name cleared.
+func withoutName(r template.ARMResource) template.ARMResource {
+	r.Name = ""
+	return r
+}
+
+// normalizeJSON returns a canonical JSON encoding of v. Map keys are sorted
+// by encoding/json, so semantically identical values produce identical bytes.
+// With ignoreOrder, arrays are sorted too.
+func normalizeJSON(v interface{}, ignoreOrder bool) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return "", err
+	}
+	if ignoreOrder {
+		generic = normalizeValue(generic)
+	}
+	normalized, err := json.Marshal(generic)
+	if err != nil {
+		return "", err
+	}
+	return string(normalized), nil
+}
+
 // buildResourceMap creates a map of resources by name.
 func buildResourceMap(resources []template.ARMResource) map[string]template.ARMResource {
 	m := make(map[string]template.ARMResource)
@@ -270,12 +464,28 @@ func deepEqual(a, b interface{}, opts coredomain.DiffOpts) bool {
 	return reflect.DeepEqual(a, b)
 }
 
-// normalizeValue normalizes a value for comparison (e.g., sorting slices).
+// normalizeValue normalizes a value for comparison by sorting the elements of
+// every array by their JSON encoding.
 func normalizeValue(v interface{}) interface{} {
 	switch val := v.(type) {
 	case []interface{}:
-		result := make([]interface{}, len(val))
-		copy(result, val)
+		type element struct {
+			value interface{}
+			key   string
+		}
+		elements := make([]element, len(val))
+		for i, item := range val {
+			normalized := normalizeValue(item)
+			data, _ := json.Marshal(normalized)
+			elements[i] = element{value: normalized, key: string(data)}
+		}
+		sort.SliceStable(elements, func(i, j int) bool {
+			return elements[i].key < elements[j].key
+		})
+		result := make([]interface{}, len(elements))
+		for i, e := range elements {
+			result[i] = e.value
+		}
 		return result
 	case map[string]interface{}:
 		result := make(map[string]interface{})
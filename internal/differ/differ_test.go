@@ -1,8 +1,11 @@
 package differ
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	coredomain "github.com/lex00/wetwire-core-go/domain"
@@ -277,6 +280,333 @@ func TestDiff_PropertyChanges(t *testing.T) {
 	}
 }
 
+func TestDiff_RenamedResource(t *testing.T) {
+	dir := t.TempDir()
+
+	// Template 1: storage account under its original name
+	t1 := filepath.Join(dir, "template1.json")
+	writeJSON(t, t1, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "oldstorage",
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"location": "eastus",
+				"sku": {"name": "Standard_LRS"},
+				"properties": {"supportsHttpsTrafficOnly": true}
+			}
+		]
+	}`)
+
+	// Template 2: same storage account, renamed
+	t2 := filepath.Join(dir, "template2.json")
+	writeJSON(t, t2, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "newstorage",
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"location": "eastus",
+				"sku": {"name": "Standard_LRS"},
+				"properties": {"supportsHttpsTrafficOnly": true}
+			}
+		]
+	}`)
+
+	d := New()
+	result, err := d.Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	if result.Summary.Added != 0 || result.Summary.Removed != 0 {
+		t.Errorf("expected no added/removed, got %d added, %d removed", result.Summary.Added, result.Summary.Removed)
+	}
+	if result.Summary.Total != 1 {
+		t.Errorf("expected 1 difference, got %d", result.Summary.Total)
+	}
+
+	if len(result.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(result.Entries))
+	}
+	entry := result.Entries[0]
+	if entry.Action != "renamed" {
+		t.Errorf("expected action 'renamed', got %q", entry.Action)
+	}
+	if entry.Resource != "newstorage" {
+		t.Errorf("expected resource 'newstorage', got %q", entry.Resource)
+	}
+	if len(entry.Changes) != 1 || entry.Changes[0] != "renamed from oldstorage" {
+		t.Errorf("unexpected changes: %v", entry.Changes)
+	}
+}
+
+func TestDiff_RenamedResourceIgnoreOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	t1 := filepath.Join(dir, "template1.json")
+	writeJSON(t, t1, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "old-vnet",
+				"type": "Microsoft.Network/virtualNetworks",
+				"apiVersion": "2021-02-01",
+				"properties": {"addressSpace": {"addressPrefixes": ["10.0.0.0/16", "10.1.0.0/16"]}}
+			}
+		]
+	}`)
+
+	// Renamed, with the address prefixes reordered
+	t2 := filepath.Join(dir, "template2.json")
+	writeJSON(t, t2, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "new-vnet",
+				"type": "Microsoft.Network/virtualNetworks",
+				"apiVersion": "2021-02-01",
+				"properties": {"addressSpace": {"addressPrefixes": ["10.1.0.0/16", "10.0.0.0/16"]}}
+			}
+		]
+	}`)
+
+	d := New()
+	result, err := d.Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result.Summary.Added != 1 || result.Summary.Removed != 1 {
+		t.Errorf("expected 1 added and 1 removed without IgnoreOrder, got %d added, %d removed", result.Summary.Added, result.Summary.Removed)
+	}
+
+	result, err = d.Diff(nil, t1, t2, coredomain.DiffOpts{IgnoreOrder: true})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(result.Entries) != 1 {
+		t.Fatalf("expected 1 entry, got %+v", result.Entries)
+	}
+	entry := result.Entries[0]
+	if entry.Action != "renamed" || entry.Resource != "new-vnet" {
+		t.Errorf("expected new-vnet to be renamed, got %+v", entry)
+	}
+	if len(entry.Changes) != 1 || entry.Changes[0] != "renamed from old-vnet" {
+		t.Errorf("unexpected changes: %v", entry.Changes)
+	}
+}
+
+func TestDiff_RenameWithPropertyChangeIsNotRenamed(t *testing.T) {
+	dir := t.TempDir()
+
+	t1 := filepath.Join(dir, "template1.json")
+	writeJSON(t, t1, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "oldstorage",
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"sku": {"name": "Standard_LRS"}
+			}
+		]
+	}`)
+
+	// Renamed and SKU changed: must remain an add + remove
+	t2 := filepath.Join(dir, "template2.json")
+	writeJSON(t, t2, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "newstorage",
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"sku": {"name": "Standard_GRS"}
+			}
+		]
+	}`)
+
+	d := New()
+	result, err := d.Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	if result.Summary.Added != 1 || result.Summary.Removed != 1 {
+		t.Errorf("expected 1 added and 1 removed, got %d added, %d removed", result.Summary.Added, result.Summary.Removed)
+	}
+	for _, e := range result.Entries {
+		if e.Action == "renamed" {
+			t.Errorf("did not expect a renamed entry: %+v", e)
+		}
+	}
+}
+
+func TestDiff_IgnorePaths(t *testing.T) {
+	dir := t.TempDir()
+
+	resource := `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "storage1",
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"properties": {
+					"accessTier": "Hot",
+					"provisioningState": "%s"
+				}
+			}
+		]
+	}`
+
+	t1 := filepath.Join(dir, "template1.json")
+	t2 := filepath.Join(dir, "template2.json")
+	writeJSON(t, t1, fmt.Sprintf(resource, "Creating"))
+	writeJSON(t, t2, fmt.Sprintf(resource, "Succeeded"))
+
+	result, err := New().Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result.Summary.Modified != 1 {
+		t.Fatalf("expected provisioningState to differ, got %+v", result.Summary)
+	}
+
+	d := New().WithIgnorePaths("resources.*.properties.provisioningState")
+	result, err = d.Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result.Summary.Total != 0 {
+		t.Errorf("expected 0 differences, got %+v", result.Entries)
+	}
+
+	// Ignoring one path leaves the others compared
+	writeJSON(t, t2, strings.Replace(fmt.Sprintf(resource, "Succeeded"), "Hot", "Cool", 1))
+	result, err = d.Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result.Summary.Modified != 1 {
+		t.Errorf("expected accessTier to differ, got %+v", result.Summary)
+	}
+}
+
+func TestDiff_IgnorePathsArrayIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	t1 := filepath.Join(dir, "template1.yaml")
+	writeFile(t, t1, `resources:
+  - name: storage1
+    type: Microsoft.Storage/storageAccounts
+    apiVersion: "2021-04-01"
+    tags:
+      deployedAt: monday
+  - name: storage2
+    type: Microsoft.Storage/storageAccounts
+    apiVersion: "2021-04-01"
+    tags:
+      deployedAt: monday
+`)
+	t2 := filepath.Join(dir, "template2.yaml")
+	writeFile(t, t2, `resources:
+  - name: storage1
+    type: Microsoft.Storage/storageAccounts
+    apiVersion: "2021-04-01"
+    tags:
+      deployedAt: tuesday
+  - name: storage2
+    type: Microsoft.Storage/storageAccounts
+    apiVersion: "2021-04-01"
+    tags:
+      deployedAt: tuesday
+`)
+
+	result, err := New().WithIgnorePaths("resources.0.tags").Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result.Summary.Modified != 1 || result.Entries[0].Resource != "storage2" {
+		t.Errorf("expected only storage2 to differ, got %+v", result.Entries)
+	}
+}
+
+func TestDiff_IgnorePathsRemovesEmptiedObjects(t *testing.T) {
+	dir := t.TempDir()
+
+	// Only the ignored field is in properties, and the other template has none
+	t1 := filepath.Join(dir, "template1.json")
+	writeJSON(t, t1, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "storage1",
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"properties": {"provisioningState": "Succeeded"}
+			}
+		]
+	}`)
+	t2 := filepath.Join(dir, "template2.json")
+	writeJSON(t, t2, `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"name": "storage1",
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01"
+			}
+		]
+	}`)
+
+	d := New().WithIgnorePaths("resources.*.properties.provisioningState")
+	result, err := d.Diff(nil, t1, t2, coredomain.DiffOpts{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if result.Summary.Total != 0 {
+		t.Errorf("expected 0 differences, got %+v", result.Entries)
+	}
+}
+
+func TestPrunePath_KeepsObjectsThatWereEmpty(t *testing.T) {
+	v := map[string]interface{}{
+		"tags":       map[string]interface{}{},
+		"properties": map[string]interface{}{"state": "x", "nested": map[string]interface{}{"state": "y"}},
+	}
+
+	prunePath(v, []string{"*", "state"})
+	prunePath(v, []string{"properties", "nested", "state"})
+
+	want := map[string]interface{}{"tags": map[string]interface{}{}}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("prunePath() left %v, want %v", v, want)
+	}
+}
+
+func TestDiff_InvalidIgnorePath(t *testing.T) {
+	dir := t.TempDir()
+	t1 := filepath.Join(dir, "template1.json")
+	writeJSON(t, t1, `{"resources": []}`)
+
+	_, err := New().WithIgnorePaths("resources..properties").Diff(nil, t1, t1, coredomain.DiffOpts{})
+	if err == nil || !strings.Contains(err.Error(), "invalid ignore path") {
+		t.Errorf("expected invalid ignore path error, got %v", err)
+	}
+}
+
 func writeJSON(t *testing.T, path, content string) {
 	t.Helper()
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
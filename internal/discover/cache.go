@@ -0,0 +1,105 @@
+package discover
+
+import (
+	"crypto/sha256"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Cache reuses the parsed results of unchanged files across repeated
+// discoveries of the same tree, as in watch mode. A file is reparsed only
+// when both its modification time and its content hash have changed.
+// A Cache is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+
+	// parsed counts files parsed (cache misses), for tests and benchmarks
+	parsed int
+}
+
+// cacheEntry holds the parsed resources of a file and the state it was parsed from
+type cacheEntry struct {
+	modTime   time.Time
+	hash      [sha256.Size]byte
+	resources []DiscoveredResource
+}
+
+// NewCache creates an empty discovery cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]cacheEntry)}
+}
+
+// DiscoverResources discovers Azure resources in srcDir like the package-level
+// DiscoverResources, reparsing only files that changed since the last call.
+func (c *Cache) DiscoverResources(srcDir string, exclude ...string) ([]DiscoveredResource, error) {
+	paths, err := collectGoFiles(srcDir, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	perFile := make([][]DiscoveredResource, len(paths))
+	fresh := make(map[string]cacheEntry, len(paths))
+
+	var stale []string
+	var staleIndex []int
+	staleState := make(map[string]cacheEntry)
+
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		entry, ok := c.entries[path]
+		if ok && entry.modTime.Equal(info.ModTime()) {
+			perFile[i] = entry.resources
+			fresh[path] = entry
+			continue
+		}
+
+		// The mod time changed (or the file is new); compare content
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(data)
+		if ok && entry.hash == hash {
+			entry.modTime = info.ModTime()
+			perFile[i] = entry.resources
+			fresh[path] = entry
+			continue
+		}
+
+		stale = append(stale, path)
+		staleIndex = append(staleIndex, i)
+		staleState[path] = cacheEntry{modTime: info.ModTime(), hash: hash}
+	}
+
+	parsed, err := parseEach(stale, runtime.GOMAXPROCS(0))
+	if err != nil {
+		return nil, err
+	}
+	c.parsed += len(stale)
+
+	for j, path := range stale {
+		entry := staleState[path]
+		entry.resources = parsed[j]
+		perFile[staleIndex[j]] = parsed[j]
+		fresh[path] = entry
+	}
+
+	// Replacing the entries drops files that no longer exist
+	c.entries = fresh
+
+	resources := mergeResources(perFile)
+	if err := linkResourceRefs(resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
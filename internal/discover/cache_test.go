@@ -0,0 +1,134 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_MatchesDiscoverResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeManyResourceFiles(t, tmpDir, 10)
+
+	cache := NewCache()
+	cached, err := cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 10, cache.parsed)
+
+	expected, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, cached)
+
+	// A second run reuses every file
+	cached, err = cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 10, cache.parsed)
+	assert.Equal(t, expected, cached)
+}
+
+func TestCache_TouchedFileIsNotReparsed(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeManyResourceFiles(t, tmpDir, 3)
+
+	cache := NewCache()
+	_, err := cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+
+	path := filepath.Join(tmpDir, "pkg0", "file000.go")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	_, err = cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 3, cache.parsed)
+}
+
+func TestCache_ModifiedFileIsReparsed(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeManyResourceFiles(t, tmpDir, 3)
+
+	cache := NewCache()
+	_, err := cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+
+	path := filepath.Join(tmpDir, "pkg0", "file000.go")
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var renamedStorage = storage.StorageAccount{
+	Name: "renamed",
+}
+`
+	require.NoError(t, os.WriteFile(path, []byte(code), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	cached, err := cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, 4, cache.parsed)
+
+	expected, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, cached)
+}
+
+func TestCache_DeletedFileIsDropped(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeManyResourceFiles(t, tmpDir, 3)
+
+	cache := NewCache()
+	_, err := cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "pkg0", "file000.go")))
+
+	cached, err := cache.DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, cached, 4)
+	assert.Len(t, cache.entries, 2)
+}
+
+func TestCache_ParseErrorIsNotCached(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "bad.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nvar x = {"), 0644))
+
+	cache := NewCache()
+	_, err := cache.DiscoverResources(tmpDir)
+	require.Error(t, err)
+
+	// The broken file must be parsed again, not served from the cache
+	_, err = cache.DiscoverResources(tmpDir)
+	require.Error(t, err)
+}
+
+func BenchmarkCache_DiscoverResources(b *testing.B) {
+	tmpDir := b.TempDir()
+	writeManyResourceFiles(b, tmpDir, 200)
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := DiscoverResources(tmpDir); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("warm", func(b *testing.B) {
+		cache := NewCache()
+		if _, err := cache.DiscoverResources(tmpDir); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := cache.DiscoverResources(tmpDir); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,259 @@
+package discover
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	coreast "github.com/lex00/wetwire-core-go/ast"
+)
+
+// IntrinsicsImportPath is the import path of the intrinsics package
+const IntrinsicsImportPath = "github.com/lex00/wetwire-azure-go/intrinsics"
+
+// CopyLoop describes an ARM copy loop declared with intrinsics.Copy
+type CopyLoop struct {
+	Name      string // Loop name, the variable name unless set explicitly
+	Count     any    // An int, or an ARM expression string such as "[parameters('vmCount')]"
+	Mode      string // "serial" or "parallel", empty for the ARM default
+	BatchSize int    // Instances deployed at a time in serial mode, 0 if not set
+}
+
+// copyLiteral returns expr as an intrinsics.Copy composite literal, or nil if
+// it is something else.
+func copyLiteral(expr ast.Expr, imports map[string]string) *ast.CompositeLit {
+	compLit, ok := expr.(*ast.CompositeLit)
+	if !ok || compLit.Type == nil {
+		return nil
+	}
+
+	typeName, pkgAlias := coreast.ExtractTypeName(compLit.Type)
+	if typeName != "Copy" || pkgAlias == "" {
+		return nil
+	}
+	if !isIntrinsicsPackage(imports[pkgAlias]) {
+		return nil
+	}
+	return compLit
+}
+
+// parseCopy extracts the copy loop and the repeated resource literal from an
+// intrinsics.Copy literal declared as varName.
+func parseCopy(compLit *ast.CompositeLit, varName string, imports map[string]string) (*CopyLoop, ast.Expr, error) {
+	loop := &CopyLoop{Name: varName}
+	var resource ast.Expr
+
+	for _, elt := range compLit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		switch key.Name {
+		case "Name":
+			if name := stringLiteral(kv.Value); name != "" {
+				loop.Name = name
+			}
+		case "Count":
+			count, err := copyCount(kv.Value, imports)
+			if err != nil {
+				return nil, nil, fmt.Errorf("copy loop %s: %w", varName, err)
+			}
+			loop.Count = count
+		case "Mode":
+			loop.Mode = stringLiteral(kv.Value)
+		case "BatchSize":
+			if lit, ok := kv.Value.(*ast.BasicLit); ok && lit.Kind == token.INT {
+				loop.BatchSize, _ = strconv.Atoi(lit.Value)
+			}
+		case "Resource":
+			resource = kv.Value
+		}
+	}
+
+	if loop.Count == nil {
+		return nil, nil, fmt.Errorf("copy loop %s: missing Count", varName)
+	}
+
+	// Resource may be given as &Type{...}
+	if unary, ok := resource.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		resource = unary.X
+	}
+	return loop, resource, nil
+}
+
+// copyCount evaluates the Count of a copy loop: an int literal, a string
+// expression, or a call of intrinsics functions such as Parameters or
+// Add(Length(Parameters("zones")), 1).
+func copyCount(expr ast.Expr, imports map[string]string) (any, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return strconv.Atoi(e.Value)
+		case token.STRING:
+			return strconv.Unquote(e.Value)
+		}
+
+	case *ast.CallExpr:
+		if value, ok := intrinsicValue(e, imports); ok {
+			return value.ARMExpression(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported Count; use an int, a string expression, or intrinsics functions such as Parameters or Add")
+}
+
+// intrinsicFunctions maps the intrinsics functions that discovery evaluates
+// to their ARM function names
+var intrinsicFunctions = map[string]string{
+	"Add":         "add",
+	"Sub":         "sub",
+	"Mul":         "mul",
+	"Div":         "div",
+	"Mod":         "mod",
+	"Length":      "length",
+	"First":       "first",
+	"Last":        "last",
+	"Skip":        "skip",
+	"Take":        "take",
+	"Union":       "union",
+	"CreateArray": "createArray",
+	"Range":       "range",
+}
+
+// intrinsicConstructors are the other intrinsics functions that discovery
+// evaluates; their arguments must be string or int literals
+var intrinsicConstructors = map[string]any{
+	"CopyIndex":        intrinsics.CopyIndex,
+	"Format":           intrinsics.Format,
+	"ListKeys":         intrinsics.ListKeys,
+	"ListKeysProperty": intrinsics.ListKeysProperty,
+	"Ref":              intrinsics.Ref,
+	"RefFull":          intrinsics.RefFull,
+	"RefFullProperty":  intrinsics.RefFullProperty,
+	"RefProperty":      intrinsics.RefProperty,
+	"ResourceGroup":    intrinsics.ResourceGroup,
+	"ResourceId":       intrinsics.ResourceId,
+}
+
+// intrinsicValue evaluates a call of the intrinsics package whose arguments
+// are int or string literals or other such calls
+func intrinsicValue(call *ast.CallExpr, imports map[string]string) (intrinsics.Intrinsic, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || !isIntrinsicsPackage(imports[pkg.Name]) {
+		return nil, false
+	}
+
+	switch sel.Sel.Name {
+	case "Parameters", "Variables":
+		if len(call.Args) != 1 {
+			return nil, false
+		}
+		name := stringLiteral(call.Args[0])
+		if name == "" {
+			return nil, false
+		}
+		if sel.Sel.Name == "Parameters" {
+			return intrinsics.Parameters(name), true
+		}
+		return intrinsics.Variables(name), true
+	}
+
+	if constructor, ok := intrinsicConstructors[sel.Sel.Name]; ok {
+		return callIntrinsic(reflect.ValueOf(constructor), call)
+	}
+
+	name, ok := intrinsicFunctions[sel.Sel.Name]
+	if !ok || call.Ellipsis.IsValid() {
+		return nil, false
+	}
+	args := make([]any, len(call.Args))
+	for i, arg := range call.Args {
+		switch a := arg.(type) {
+		case *ast.BasicLit:
+			switch a.Kind {
+			case token.INT:
+				n, err := strconv.Atoi(a.Value)
+				if err != nil {
+					return nil, false
+				}
+				args[i] = n
+			case token.STRING:
+				s, err := strconv.Unquote(a.Value)
+				if err != nil {
+					return nil, false
+				}
+				args[i] = s
+			default:
+				return nil, false
+			}
+		case *ast.CallExpr:
+			value, ok := intrinsicValue(a, imports)
+			if !ok {
+				return nil, false
+			}
+			args[i] = value
+		default:
+			return nil, false
+		}
+	}
+	return intrinsics.Function{Name: name, Args: args}, true
+}
+
+// callIntrinsic calls the intrinsics function fn with the literal arguments
+// of call
+func callIntrinsic(fn reflect.Value, call *ast.CallExpr) (intrinsics.Intrinsic, bool) {
+	t := fn.Type()
+	if call.Ellipsis.IsValid() || len(call.Args) < t.NumIn()-1 || (!t.IsVariadic() && len(call.Args) != t.NumIn()) {
+		return nil, false
+	}
+	args := make([]reflect.Value, len(call.Args))
+	for i, arg := range call.Args {
+		var argType reflect.Type
+		if t.IsVariadic() && i >= t.NumIn()-1 {
+			argType = t.In(t.NumIn() - 1).Elem()
+		} else {
+			argType = t.In(i)
+		}
+		lit, ok := arg.(*ast.BasicLit)
+		if !ok {
+			return nil, false
+		}
+		args[i] = reflect.New(argType).Elem()
+		if !setBasic(args[i], lit) {
+			return nil, false
+		}
+	}
+	return fn.Call(args)[0].Interface().(intrinsics.Intrinsic), true
+}
+
+// isIntrinsicsPackage reports whether importPath is the intrinsics package or a fork of it
+func isIntrinsicsPackage(importPath string) bool {
+	return importPath == IntrinsicsImportPath || strings.HasSuffix(importPath, "/wetwire-azure-go/intrinsics")
+}
+
+// stringLiteral returns the value of a string literal, or "" for anything else
+func stringLiteral(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return value
+}
@@ -5,62 +5,62 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"os"
-	"path/filepath"
-	"strings"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
 
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/template"
 	coreast "github.com/lex00/wetwire-core-go/ast"
 )
 
 // DiscoveredResource represents a discovered Azure resource with metadata
 type DiscoveredResource struct {
-	Name         string   // Variable name
-	Type         string   // Azure resource type (e.g., "Microsoft.Storage/storageAccounts")
-	File         string   // Absolute path to the file
-	Line         int      // Line number where the resource is declared
-	Dependencies []string // Names of other resources this resource depends on
-}
-
-// azureResourceMap maps Go package paths to Azure resource types
-var azureResourceMap = map[string]string{
-	"storage.StorageAccount":      "Microsoft.Storage/storageAccounts",
-	"compute.VirtualMachine":      "Microsoft.Compute/virtualMachines",
-	"network.VirtualNetwork":      "Microsoft.Network/virtualNetworks",
-	"network.NetworkInterface":    "Microsoft.Network/networkInterfaces",
-	"network.Subnet":              "Microsoft.Network/subnets",
-	"network.PublicIPAddress":     "Microsoft.Network/publicIPAddresses",
-	"network.NetworkSecurityGroup": "Microsoft.Network/networkSecurityGroups",
-	"keyvault.Vault":              "Microsoft.KeyVault/vaults",
-	"sql.Server":                  "Microsoft.Sql/servers",
-	"sql.Database":                "Microsoft.Sql/servers/databases",
-	"web.Site":                    "Microsoft.Web/sites",
-	"containerregistry.Registry":  "Microsoft.ContainerRegistry/registries",
-	"aks.ManagedCluster":          "Microsoft.ContainerService/managedClusters",
+	Name         string    // Variable name
+	Type         string    // Azure resource type (e.g., "Microsoft.Storage/storageAccounts")
+	File         string    // Absolute path to the file
+	Line         int       // Line number where the resource is declared
+	Dependencies []string  // Names of other resources this resource depends on
+	APIVersion   string    // Explicit APIVersion literal from the declaration, empty if not set
+	Copy         *CopyLoop // Copy loop for resources declared with intrinsics.Copy, nil otherwise
+	SKU          string    // SKU.Name (or a VM's HardwareProfile.VMSize) literal from the declaration, empty if not set
+	Scope        string    // ARM scope of an extension resource such as a lock (its Scope field), empty if not set
+
+	// Set on resources expanded from another declaration, such as the
+	// protected item of a VM declared with EnableBackup
+	ARMName    string         // ARM resource name, the variable name if empty
+	Properties map[string]any // ARM properties, omitted if nil
+
+	// Value is the resource evaluated from a declaration made only of
+	// literals, for types with Validate methods; nil otherwise
+	Value any
+
+	// Raw is a template.RawResource declaration made only of literals, which
+	// the builder writes to the template as is; nil otherwise
+	Raw *template.RawResource
 }
 
 // DiscoverResources discovers Azure resources in the given source directory
 // by parsing Go AST and finding top-level variable declarations with Azure resource types.
-func DiscoverResources(srcDir string) ([]DiscoveredResource, error) {
-	var resources []DiscoveredResource
-
-	// Walk through all Go files in the directory recursively
-	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories and non-Go files
-		if info.IsDir() || !strings.HasSuffix(path, ".go") {
-			return nil
-		}
+// Test files and files matching the exclude patterns are skipped (see Excludes).
+// Files are parsed concurrently, up to GOMAXPROCS at a time; results are ordered
+// by file then line.
+func DiscoverResources(srcDir string, exclude ...string) ([]DiscoveredResource, error) {
+	paths, err := collectGoFiles(srcDir, exclude)
+	if err != nil {
+		return nil, err
+	}
+	return parseFiles(paths, runtime.GOMAXPROCS(0))
+}
 
-		// Parse the file
-		fileResources, err := parseFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to parse %s: %w", path, err)
-		}
+// collectGoFiles walks srcDir recursively and returns the paths of all Go
+// files other than test files and those matched by exclude
+func collectGoFiles(srcDir string, exclude Excludes) ([]string, error) {
+	var paths []string
 
-		resources = append(resources, fileResources...)
+	err := WalkGoFiles(srcDir, exclude, func(path string) error {
+		paths = append(paths, path)
 		return nil
 	})
 
@@ -68,11 +68,88 @@ func DiscoverResources(srcDir string) ([]DiscoveredResource, error) {
 		return nil, err
 	}
 
+	return paths, nil
+}
+
+// parseFiles parses paths with a pool of workers and merges the results in
+// file-then-line order. If any files fail to parse, the error for the first
+// such path is returned, so the outcome does not depend on scheduling.
+func parseFiles(paths []string, workers int) ([]DiscoveredResource, error) {
+	perFile, err := parseEach(paths, workers)
+	if err != nil {
+		return nil, err
+	}
+	resources := mergeResources(perFile)
+	if err := linkResourceRefs(resources); err != nil {
+		return nil, err
+	}
 	return resources, nil
 }
 
+// parseEach parses paths with a pool of workers and returns the resources of
+// each file, indexed like paths.
+func parseEach(paths []string, workers int) ([][]DiscoveredResource, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	// Each worker writes only to the slots of the indices it receives
+	results := make([][]DiscoveredResource, len(paths))
+	errs := make([]error, len(paths))
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i], errs[i] = parseFile(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for i, path := range paths {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, errs[i])
+		}
+	}
+	return results, nil
+}
+
+// mergeResources flattens per-file results and sorts them by file, then line.
+func mergeResources(perFile [][]DiscoveredResource) []DiscoveredResource {
+	var resources []DiscoveredResource
+	for _, fileResources := range perFile {
+		resources = append(resources, fileResources...)
+	}
+
+	sort.SliceStable(resources, func(a, b int) bool {
+		if resources[a].File != resources[b].File {
+			return resources[a].File < resources[b].File
+		}
+		return resources[a].Line < resources[b].Line
+	})
+
+	return resources
+}
+
 // parseFile parses a single Go file and extracts Azure resource declarations
 func parseFile(filePath string) ([]DiscoveredResource, error) {
+	return parseFileTraced(filePath, nil)
+}
+
+// parseFileTraced is parseFile, reporting whether each package-level variable
+// was accepted as a resource, or why not, to trace if it is not nil
+func parseFileTraced(filePath string, trace traceFunc) ([]DiscoveredResource, error) {
 	fset := token.NewFileSet()
 	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
 	if err != nil {
@@ -99,38 +176,93 @@ func parseFile(filePath string) ([]DiscoveredResource, error) {
 			// Process each name in the value spec
 			for i, name := range valueSpec.Names {
 				if name.Name == "_" {
+					trace.reject(fset.Position(name.Pos()), name.Name, "blank identifier")
 					continue
 				}
 
+				var value ast.Expr
+				if i < len(valueSpec.Values) {
+					value = valueSpec.Values[i]
+				}
+
+				// A copy loop repeats its Resource literal, which supplies the type
+				// and API version
+				var loop *CopyLoop
+				resourceValue := value
+				if compLit := copyLiteral(value, packageImports); compLit != nil {
+					loop, resourceValue, err = parseCopy(compLit, name.Name, packageImports)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", fset.Position(name.Pos()), err)
+					}
+				}
+
 				// Check if this is an Azure resource type
 				// First try the explicit type, then infer from the value
 				var azureType string
-				if valueSpec.Type != nil {
+				var typeExpr ast.Expr
+				if valueSpec.Type != nil && loop == nil {
+					typeExpr = valueSpec.Type
 					azureType = getAzureResourceType(valueSpec.Type, packageImports)
-				} else if i < len(valueSpec.Values) {
-					azureType = inferAzureResourceType(valueSpec.Values[i], packageImports)
+				} else if resourceValue != nil {
+					// Look through fluent calls such as (&compute.VirtualMachine{...}).EnableBackup(...)
+					resourceValue = unwrapLiteral(resourceValue)
+					if compLit, ok := resourceValue.(*ast.CompositeLit); ok {
+						typeExpr = compLit.Type
+					}
+					azureType = inferAzureResourceType(resourceValue, packageImports)
+				}
+
+				// A RawResource names its resource type in its Type field
+				rawResource := isRawResource(typeExpr, packageImports)
+				if rawResource {
+					resourceValue = unwrapLiteral(resourceValue)
+					azureType = extractStringField(resourceValue, "Type")
 				}
 
 				if azureType == "" {
+					trace.reject(fset.Position(name.Pos()), name.Name, rejectionReason(typeExpr, value, packageImports))
 					continue
 				}
 
-				// Extract dependencies from the value expression
+				// Extract dependencies and the explicit API version from the value expression
 				var dependencies []string
-				if i < len(valueSpec.Values) {
-					dependencies = extractDependencies(valueSpec.Values[i])
+				var apiVersion, sku, scope string
+				if value != nil {
+					dependencies = extractDependencies(value, packageImports)
+					dependencies = appendDependencies(dependencies, resourceRefs(value, packageImports)...)
+					apiVersion = extractStringField(resourceValue, "APIVersion")
+					sku = extractSKU(resourceValue)
+					scope = extractScope(resourceValue, packageImports)
 				}
 
 				// Get the line number
 				pos := fset.Position(name.Pos())
 
-				resources = append(resources, DiscoveredResource{
+				resource := DiscoveredResource{
 					Name:         name.Name,
 					Type:         azureType,
 					File:         filePath,
 					Line:         pos.Line,
 					Dependencies: dependencies,
-				})
+					APIVersion:   apiVersion,
+					Copy:         loop,
+					SKU:          sku,
+					Scope:        scope,
+					Value:        evaluateResource(typeExpr, resourceValue, packageImports),
+				}
+				if rawResource {
+					resource.ARMName = extractStringField(resourceValue, "Name")
+					raw, unsupported := evaluateRawResource(resourceValue, packageImports)
+					if unsupported != nil {
+						return nil, fmt.Errorf("%s: unsupported value in template.RawResource %s; use literals, intrinsics.ResourceRef calls, or intrinsics values", fset.Position(unsupported.Pos()), name.Name)
+					}
+					resource.Raw = raw
+				} else {
+					resource.ARMName = extractName(resourceValue, packageImports)
+				}
+				resources = append(resources, resource)
+				trace.accept(pos, name.Name, azureType)
+				resources = append(resources, expandResource(value, resource, packageImports)...)
 			}
 		}
 	}
@@ -138,7 +270,6 @@ func parseFile(filePath string) ([]DiscoveredResource, error) {
 	return resources, nil
 }
 
-
 // inferAzureResourceType infers the Azure resource type from a value expression
 // (e.g., from a composite literal like storage.StorageAccount{...})
 func inferAzureResourceType(valueExpr ast.Expr, imports map[string]string) string {
@@ -151,6 +282,28 @@ func inferAzureResourceType(valueExpr ast.Expr, imports map[string]string) strin
 	return ""
 }
 
+// evaluateResource returns the value of a resource declared with the literal
+// expr, or nil if the type is not evaluated or expr is not entirely literal.
+func evaluateResource(typeExpr, expr ast.Expr, imports map[string]string) any {
+	if typeExpr == nil || expr == nil {
+		return nil
+	}
+	typeName, pkgAlias := coreast.ExtractTypeName(typeExpr)
+	t := lookupValueType(imports[pkgAlias], typeName)
+	if t == nil {
+		return nil
+	}
+	// Fluent calls such as EnableBackup are not evaluated
+	if _, ok := expr.(*ast.CompositeLit); !ok {
+		return nil
+	}
+	v, unsupported := evaluateLiteral(t, expr, imports)
+	if unsupported != nil {
+		return nil
+	}
+	return v.Addr().Interface()
+}
+
 // getAzureResourceType checks if the type expression represents an Azure resource
 // and returns the Azure resource type string
 func getAzureResourceType(typeExpr ast.Expr, imports map[string]string) string {
@@ -160,29 +313,121 @@ func getAzureResourceType(typeExpr ast.Expr, imports map[string]string) string {
 		return ""
 	}
 
-	key := fmt.Sprintf("%s.%s", pkgAlias, typeName)
+	importPath, ok := imports[pkgAlias]
+	if !ok {
+		return ""
+	}
 
-	// Check if this is a known Azure resource type
-	if azureType, ok := azureResourceMap[key]; ok {
-		// Verify it's from the wetwire-azure-go package
-		if importPath, exists := imports[pkgAlias]; exists {
-			if strings.Contains(importPath, "wetwire-azure-go/resources") {
-				return azureType
-			}
+	// Check if this is a registered Azure resource type
+	azureType, _ := LookupResourceType(importPath, typeName)
+	return azureType
+}
+
+// extractStringField returns the value of a string literal field in a composite
+// literal, or "" if the field is absent or not a literal.
+func extractStringField(expr ast.Expr, field string) string {
+	compLit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+
+	for _, elt := range compLit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || key.Name != field {
+			continue
+		}
+		lit, ok := kv.Value.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return ""
+		}
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return ""
 		}
+		return value
 	}
 
 	return ""
 }
 
-// extractDependencies extracts references to other variables from an expression
-func extractDependencies(expr ast.Expr) []string {
+// extractName returns the ARM name set in the Name field of a resource
+// literal: a string literal, or the expression of a naming.Unique call chain
+// such as naming.Unique("logs").For("Microsoft.Storage/storageAccounts").String().
+// It returns "" if Name is absent or set any other way.
+func extractName(expr ast.Expr, imports map[string]string) string {
+	compLit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	name := fieldValue(compLit, "Name")
+	if name == nil {
+		return ""
+	}
+	if value := stringLiteral(name); value != "" {
+		return value
+	}
+	return uniqueNameValue(name, imports)
+}
+
+// extractScope returns the Scope field of a composite literal, or its
+// TargetResourceID field, which extension resources such as diagnostic
+// settings are scoped by: a string literal, or a reference to the resource of
+// a variable given as X.ID() or intrinsics.ResourceRef("X"), which the
+// builder resolves. It is "" if the field is absent or anything else.
+func extractScope(expr ast.Expr, imports map[string]string) string {
+	compLit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	scope := fieldValue(compLit, "Scope")
+	if scope == nil {
+		scope = fieldValue(compLit, "TargetResourceID")
+	}
+	if call, ok := scope.(*ast.CallExpr); ok && len(call.Args) == 0 {
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "ID" {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				return intrinsics.ResourceRef(ident.Name)
+			}
+		}
+	}
+	return stringOrResourceRef(scope, imports)
+}
+
+// extractSKU returns the Name of the SKU field in a composite literal, such
+// as "Standard_GRS" in storage.StorageAccount{SKU: storage.SKU{Name: "Standard_GRS"}},
+// or "" if the SKU is absent or its name is not a literal. Virtual machines,
+// which have no SKU field, give their Properties.HardwareProfile.VMSize.
+func extractSKU(expr ast.Expr) string {
+	compLit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	if sku := fieldValue(compLit, "SKU"); sku != nil {
+		return extractStringField(unwrapLiteral(sku), "Name")
+	}
+	props, ok := unwrapLiteral(fieldValue(compLit, "Properties")).(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	return extractStringField(unwrapLiteral(fieldValue(props, "HardwareProfile")), "VMSize")
+}
+
+// extractDependencies extracts references to other variables from an expression.
+// Package names from imports (e.g. intrinsics in intrinsics.ResourceId(...)) are not dependencies.
+func extractDependencies(expr ast.Expr, imports map[string]string) []string {
 	deps := make(map[string]bool)
 	extractDependenciesRecursive(expr, deps)
 
 	// Convert map to slice
 	result := make([]string, 0, len(deps))
 	for dep := range deps {
+		if _, isPackage := imports[dep]; isPackage {
+			continue
+		}
 		result = append(result, dep)
 	}
 	return result
@@ -222,7 +467,10 @@ func extractDependenciesRecursive(expr ast.Expr, deps map[string]bool) {
 		extractDependenciesRecursive(e.Value, deps)
 
 	case *ast.CallExpr:
-		// Function calls
+		// Function calls; for method calls like myDef.ID() the receiver is a reference
+		if sel, ok := e.Fun.(*ast.SelectorExpr); ok {
+			extractDependenciesRecursive(sel.X, deps)
+		}
 		for _, arg := range e.Args {
 			extractDependenciesRecursive(arg, deps)
 		}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -108,6 +110,60 @@ var mySqlDb = sql.Database{
 	assert.Equal(t, "Microsoft.Sql/servers/databases", resourceMap["mySqlDb"].Type)
 }
 
+// TestDiscoverResources_SQLHighAvailability tests that elastic pools and
+// failover groups are discovered, and depend on the resources they reference
+func TestDiscoverResources_SQLHighAvailability(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/sql"
+
+var Primary = sql.Server{Name: "orders-sql", Location: "eastus"}
+
+var Secondary = sql.Server{Name: "orders-sql-west", Location: "westus"}
+
+var Pool = sql.ElasticPool{
+	Name:     Primary.Name + "/orders-pool",
+	Location: "eastus",
+	SKU:      sql.SKU{Name: "StandardPool", Tier: "Standard"},
+}
+
+var Orders = sql.Database{
+	Name:       Primary.Name + "/orders",
+	Location:   "eastus",
+	Properties: sql.DatabaseProperties{ElasticPoolID: Pool.ID()},
+}
+
+var OrdersFailover = sql.FailoverGroup{
+	Name: Primary.Name + "/orders-fog",
+	Properties: sql.FailoverGroupProperties{
+		ReadWriteEndpoint: sql.ReadWriteEndpoint{FailoverPolicy: sql.FailoverPolicyManual},
+		PartnerServers:    []sql.PartnerServer{{ID: Secondary.ID()}},
+		Databases:         []string{Orders.ID()},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 5)
+
+	resourceMap := make(map[string]DiscoveredResource)
+	for _, r := range resources {
+		resourceMap[r.Name] = r
+	}
+
+	assert.Equal(t, "Microsoft.Sql/servers/elasticPools", resourceMap["Pool"].Type)
+	assert.Equal(t, "StandardPool", resourceMap["Pool"].SKU)
+	assert.Equal(t, []string{"Primary"}, resourceMap["Pool"].Dependencies)
+	assert.ElementsMatch(t, []string{"Primary", "Pool"}, resourceMap["Orders"].Dependencies)
+	assert.Equal(t, "Microsoft.Sql/servers/failoverGroups", resourceMap["OrdersFailover"].Type)
+	assert.ElementsMatch(t, []string{"Primary", "Secondary", "Orders"}, resourceMap["OrdersFailover"].Dependencies)
+}
+
 // TestDiscoverResources_WebApp tests web site discovery
 func TestDiscoverResources_WebApp(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -126,43 +182,1379 @@ var myWebApp = web.Site{
 
 	resources, err := DiscoverResources(tmpDir)
 	require.NoError(t, err)
-	require.Len(t, resources, 1)
-	assert.Equal(t, "Microsoft.Web/sites", resources[0].Type)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "Microsoft.Web/sites", resources[0].Type)
+}
+
+// TestDiscoverResources_ContainerRegistry tests container registry discovery
+func TestDiscoverResources_ContainerRegistry(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/containerregistry"
+
+var myRegistry = containerregistry.Registry{
+	Name:     "myregistry",
+	Location: "eastus",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "Microsoft.ContainerRegistry/registries", resources[0].Type)
+}
+
+// TestDiscoverResources_AKS tests managed cluster discovery
+func TestDiscoverResources_AKS(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/aks"
+
+var myCluster = aks.ManagedCluster{
+	Name:     "myakscluster",
+	Location: "eastus",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "Microsoft.ContainerService/managedClusters", resources[0].Type)
+}
+
+// TestDiscoverResources_Policy tests policy definition and assignment discovery,
+// including the assignment's dependency on its definition via a method call
+func TestDiscoverResources_Policy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/policy"
+)
+
+var requireTag = policy.PolicyDefinition{
+	Name: "require-tag",
+}
+
+var requireEnvTag = policy.PolicyAssignment{
+	Name: "require-env-tag",
+	Properties: policy.PolicyAssignmentProperties{
+		PolicyDefinitionID: requireTag.ID(),
+		DisplayName:        intrinsics.Parameters("displayName"),
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	byName := make(map[string]DiscoveredResource)
+	for _, r := range resources {
+		byName[r.Name] = r
+	}
+
+	assert.Equal(t, "Microsoft.Authorization/policyDefinitions", byName["requireTag"].Type)
+	assert.Equal(t, "Microsoft.Authorization/policyAssignments", byName["requireEnvTag"].Type)
+	assert.Equal(t, []string{"requireTag"}, byName["requireEnvTag"].Dependencies)
+}
+
+// TestDiscoverResources_APIVersion tests that an explicit APIVersion literal is captured
+func TestDiscoverResources_APIVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var explicit = storage.StorageAccount{
+	Name:       "explicit",
+	APIVersion: "2023-01-01",
+}
+
+var defaulted = storage.StorageAccount{
+	Name: "defaulted",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	byName := make(map[string]DiscoveredResource)
+	for _, r := range resources {
+		byName[r.Name] = r
+	}
+	assert.Equal(t, "2023-01-01", byName["explicit"].APIVersion)
+	assert.Empty(t, byName["defaulted"].APIVersion)
+}
+
+// TestDiscoverResources_SKU tests that a literal SKU name, or VM size, is captured
+func TestDiscoverResources_SKU(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var replicated = storage.StorageAccount{
+	Name: "replicated",
+	SKU:  storage.SKU{Name: "Standard_GRS"},
+}
+
+var local = storage.StorageAccount{
+	Name: "local",
+}
+
+var vm = compute.VirtualMachine{
+	Name: "vm",
+	Properties: compute.VirtualMachineProperties{
+		HardwareProfile: compute.HardwareProfile{VMSize: "Standard_D2s_v3"},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	byName := make(map[string]DiscoveredResource)
+	for _, r := range resources {
+		byName[r.Name] = r
+	}
+	assert.Equal(t, "Standard_GRS", byName["replicated"].SKU)
+	assert.Empty(t, byName["local"].SKU)
+	assert.Equal(t, "Standard_D2s_v3", byName["vm"].SKU)
+}
+
+// TestDiscoverResources_LogicWorkflow tests Logic App workflow discovery
+func TestDiscoverResources_LogicWorkflow(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/logic"
+
+var myWorkflow = logic.Workflow{
+	Name:     "my-workflow",
+	Location: "eastus",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "Microsoft.Logic/workflows", resources[0].Type)
+}
+
+// TestDiscoverResources_ManagementPolicy tests that a lifecycle policy is discovered
+// and depends on the storage account it references
+func TestDiscoverResources_ManagementPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var auditStorage = storage.StorageAccount{
+	Name:     "auditstorage",
+	Location: "eastus",
+}
+
+var auditRetention = storage.ManagementPolicy{
+	Name: auditStorage.Name + "/default",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	policy := resources[1]
+	assert.Equal(t, "auditRetention", policy.Name)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/managementPolicies", policy.Type)
+	assert.Equal(t, []string{"auditStorage"}, policy.Dependencies)
+}
+
+// TestDiscoverResources_FlowLog tests that a flow log depends on its NSG and storage account
+func TestDiscoverResources_FlowLog(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var webNSG = network.NetworkSecurityGroup{Name: "web-nsg"}
+
+var flowLogStorage = storage.StorageAccount{Name: "flowlogs"}
+
+var watcher = network.NetworkWatcher{Name: "NetworkWatcher_eastus"}
+
+var webFlowLog = network.FlowLog{
+	Name: watcher.Name + "/web-nsg",
+	Properties: network.FlowLogProperties{
+		TargetResourceID: intrinsics.ResourceId("Microsoft.Network/networkSecurityGroups", webNSG.Name).ARMExpression(),
+		StorageID:        intrinsics.ResourceId("Microsoft.Storage/storageAccounts", flowLogStorage.Name).ARMExpression(),
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 4)
+
+	assert.Equal(t, "Microsoft.Network/networkWatchers", resources[2].Type)
+
+	flowLog := resources[3]
+	assert.Equal(t, "Microsoft.Network/networkWatchers/flowLogs", flowLog.Type)
+	assert.ElementsMatch(t, []string{"watcher", "webNSG", "flowLogStorage"}, flowLog.Dependencies)
+}
+
+// TestDiscoverResources_VirtualNetworkPeering tests that a peering depends on
+// both its parent and its remote virtual network
+func TestDiscoverResources_VirtualNetworkPeering(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var hubVNet = network.VirtualNetwork{
+	Name:     "hub-vnet",
+	Location: "eastus",
+}
+
+var spokeVNet = network.VirtualNetwork{
+	Name:     "spoke-vnet",
+	Location: "eastus",
+}
+
+var hubToSpoke = network.VirtualNetworkPeering{
+	Name: hubVNet.Name + "/hub-to-spoke",
+	Properties: network.VirtualNetworkPeeringProperties{
+		RemoteVirtualNetwork: network.NewSubResource(spokeVNet.ID()),
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	peering := resources[2]
+	assert.Equal(t, "hubToSpoke", peering.Name)
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/virtualNetworkPeerings", peering.Type)
+	assert.ElementsMatch(t, []string{"hubVNet", "spokeVNet"}, peering.Dependencies)
+}
+
+// TestDiscoverResources_SynapseWorkspace tests that a workspace depends on its Data Lake storage account
+func TestDiscoverResources_SynapseWorkspace(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+	"github.com/lex00/wetwire-azure-go/resources/synapse"
+)
+
+var analyticsLake = storage.StorageAccount{
+	Name:     "analyticslake",
+	Location: "eastus",
+}
+
+var analytics = synapse.Workspace{
+	Name:     "analytics",
+	Location: "eastus",
+	Properties: synapse.WorkspaceProperties{
+		DefaultDataLakeStorage: synapse.DataLakeStorageAccountDetails{
+			AccountURL: analyticsLake.DFSEndpoint(),
+			Filesystem: "workspace",
+		},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	workspace := resources[1]
+	assert.Equal(t, "analytics", workspace.Name)
+	assert.Equal(t, "Microsoft.Synapse/workspaces", workspace.Type)
+	assert.Equal(t, []string{"analyticsLake"}, workspace.Dependencies)
+}
+
+// TestDiscoverResources_ManagedIdentity tests that ARM identities are discovered and
+// that a federated credential depends on its identity
+func TestDiscoverResources_ManagedIdentity(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/managedidentity"
+
+var appIdentity = managedidentity.UserAssignedIdentity{
+	Name:     "app-identity",
+	Location: "eastus",
+}
+
+var aksCredential = managedidentity.FederatedCredential{
+	Name: appIdentity.Name + "/aks",
+	Properties: managedidentity.FederatedCredentialProperties{
+		Issuer:  "https://oidc.example.com/",
+		Subject: "system:serviceaccount:default:app",
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	assert.Equal(t, "Microsoft.ManagedIdentity/userAssignedIdentities", resources[0].Type)
+	assert.Equal(t, "Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials", resources[1].Type)
+	assert.Equal(t, []string{"appIdentity"}, resources[1].Dependencies)
+}
+
+// TestDiscoverResources_Alerts tests that alerts depend on their action group and
+// the resource they monitor
+func TestDiscoverResources_Alerts(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/insights"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var appStorage = storage.StorageAccount{
+	Name:     "appstorage",
+	Location: "eastus",
+}
+
+var opsTeam = insights.ActionGroup{
+	Name:     "ops-team",
+	Location: "global",
+	Properties: insights.ActionGroupProperties{
+		GroupShortName: "ops",
+		Enabled:        true,
+	},
+}
+
+var lowAvailability = insights.MetricAlert{
+	Name:     "low-availability",
+	Location: "global",
+	Properties: insights.MetricAlertProperties{
+		Scopes:  []string{appStorage.ID()},
+		Actions: []insights.MetricAlertAction{{ActionGroupID: opsTeam.ID()}},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "Microsoft.Insights/actionGroups", resources[1].Type)
+	alert := resources[2]
+	assert.Equal(t, "Microsoft.Insights/metricAlerts", alert.Type)
+	assert.ElementsMatch(t, []string{"appStorage", "opsTeam"}, alert.Dependencies)
+}
+
+// TestDiscoverResources_CopyLoop tests that intrinsics.Copy declarations are
+// discovered with the type of their Resource and a copy loop
+func TestDiscoverResources_CopyLoop(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+)
+
+var webNICs = intrinsics.Copy{
+	Count: 3,
+	Resource: network.NetworkInterface{
+		Name:     "web-nic",
+		Location: "eastus",
+	},
+}
+
+var webVMs = intrinsics.Copy{
+	Name:      "vmLoop",
+	Count:     intrinsics.Parameters("vmCount"),
+	Mode:      "serial",
+	BatchSize: 1,
+	Resource: &compute.VirtualMachine{
+		Name:       "web-vm",
+		APIVersion: "2021-07-01",
+		Tags:       map[string]string{"nic": webNICs.Name},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	nics := resources[0]
+	assert.Equal(t, "Microsoft.Network/networkInterfaces", nics.Type)
+	assert.Equal(t, &CopyLoop{Name: "webNICs", Count: 3}, nics.Copy)
+	assert.Equal(t, "web-nic", nics.ARMName)
+
+	vms := resources[1]
+	assert.Equal(t, "Microsoft.Compute/virtualMachines", vms.Type)
+	assert.Equal(t, "2021-07-01", vms.APIVersion)
+	assert.Equal(t, &CopyLoop{Name: "vmLoop", Count: "[parameters('vmCount')]", Mode: "serial", BatchSize: 1}, vms.Copy)
+	assert.Equal(t, []string{"webNICs"}, vms.Dependencies)
+}
+
+// TestDiscoverResources_CopyLoopComputedCount tests that a count computed with
+// intrinsics functions is evaluated to its ARM expression
+func TestDiscoverResources_CopyLoopComputedCount(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+)
+
+var zoneNICs = intrinsics.Copy{
+	Count:    intrinsics.Add(intrinsics.Length(intrinsics.Parameters("zones")), 1),
+	Resource: network.NetworkInterface{Name: "zone-nic"},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, &CopyLoop{Name: "zoneNICs", Count: "[add(length(parameters('zones')), 1)]"}, resources[0].Copy)
+}
+
+// TestDiscoverResources_CopyLoopUnsupportedCount tests that a count discovery cannot evaluate is an error
+func TestDiscoverResources_CopyLoopUnsupportedCount(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+)
+
+var webNICs = intrinsics.Copy{
+	Count:    nicCount(),
+	Resource: network.NetworkInterface{Name: "web-nic"},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	_, err = DiscoverResources(tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "copy loop webNICs: unsupported Count")
+}
+
+// TestDiscoverResources_VirtualNetworkGateway tests that a gateway depends on
+// the virtual network holding its GatewaySubnet and on its public IP
+func TestDiscoverResources_VirtualNetworkGateway(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var hubVNet = network.VirtualNetwork{
+	Name:     "hub-vnet",
+	Location: "eastus",
+}
+
+var gatewayIP = network.PublicIPAddress{
+	Name:     "gw-pip",
+	Location: "eastus",
+}
+
+var hubGateway = network.VirtualNetworkGateway{
+	Name:     "hub-gw",
+	Location: "eastus",
+	Properties: network.VirtualNetworkGatewayProperties{
+		GatewayType: "ExpressRoute",
+		IPConfigurations: []network.IPConfiguration{{
+			Name: "default",
+			Properties: network.IPConfigurationProperties{
+				Subnet:          network.NewSubResource(hubVNet.SubnetID("GatewaySubnet")),
+				PublicIPAddress: network.NewSubResource(gatewayIP.ID()),
+			},
+		}},
+	},
+}
+
+var circuit = network.ExpressRouteCircuit{
+	Name:     "er-circuit",
+	Location: "eastus",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 4)
+
+	gateway := resources[2]
+	assert.Equal(t, "hubGateway", gateway.Name)
+	assert.Equal(t, "Microsoft.Network/virtualNetworkGateways", gateway.Type)
+	assert.ElementsMatch(t, []string{"hubVNet", "gatewayIP"}, gateway.Dependencies)
+
+	assert.Equal(t, "Microsoft.Network/expressRouteCircuits", resources[3].Type)
+}
+
+// TestDiscoverResources_VMBackup tests that a backup-enabled VM expands into
+// the VM and its Recovery Services protected item
+func TestDiscoverResources_VMBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+)
+
+var webVM = (&compute.VirtualMachine{
+	Name:     "web-vm",
+	Location: "eastus",
+}).EnableBackup("backup-vault", "DefaultPolicy")
+
+var dbVM = compute.VirtualMachine{
+	Name:     "db-vm",
+	Location: "eastus",
+	Backup:   &compute.Backup{VaultName: "backup-vault", PolicyName: "Daily"},
+}
+
+var plainVM = compute.VirtualMachine{
+	Name:     "plain-vm",
+	Location: "eastus",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 5)
+
+	assert.Equal(t, "webVM", resources[0].Name)
+	assert.Equal(t, "Microsoft.Compute/virtualMachines", resources[0].Type)
+
+	item := resources[1]
+	assert.Equal(t, "webVMBackup", item.Name)
+	assert.Equal(t, "Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems", item.Type)
+	assert.Equal(t, []string{"webVM"}, item.Dependencies)
+	assert.Equal(t, resources[0].Line, item.Line)
+	assert.Contains(t, item.ARMName, "backup-vault/Azure/iaasvmcontainer;")
+	assert.Equal(t, "[resourceId('Microsoft.Compute/virtualMachines', 'web-vm')]", item.Properties["sourceResourceId"])
+
+	assert.Equal(t, "dbVMBackup", resources[3].Name)
+	assert.Equal(t,
+		"[resourceId('Microsoft.RecoveryServices/vaults/backupPolicies', 'backup-vault', 'Daily')]",
+		resources[3].Properties["policyId"])
+
+	assert.Equal(t, "plainVM", resources[4].Name)
+}
+
+// TestDiscoverResources_BlobServices tests that a storage account with blob
+// data protection expands into the account and its default blob service
+func TestDiscoverResources_BlobServices(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var orders = (&storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+}).WithBlobDataProtection(14)
+
+var invoices = storage.StorageAccount{
+	Name:     "invoices",
+	Location: "eastus",
+	Properties: &storage.StorageAccountProperties{
+		BlobServices: &storage.BlobServiceProperties{
+			DeleteRetentionPolicy: &storage.DeleteRetentionPolicy{Enabled: true, Days: 7},
+		},
+	},
+}
+
+var scratch = storage.StorageAccount{
+	Name:     "scratch",
+	Location: "eastus",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 5)
+
+	blob := resources[1]
+	assert.Equal(t, "ordersBlobServices", blob.Name)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/blobServices", blob.Type)
+	assert.Equal(t, "orders/default", blob.ARMName)
+	assert.Equal(t, []string{"orders"}, blob.Dependencies)
+	assert.Equal(t, true, blob.Properties["isVersioningEnabled"])
+	assert.Equal(t, map[string]any{"enabled": true, "days": float64(14)}, blob.Properties["deleteRetentionPolicy"])
+
+	assert.Equal(t, "invoicesBlobServices", resources[3].Name)
+	assert.Equal(t, map[string]any{
+		"deleteRetentionPolicy": map[string]any{"enabled": true, "days": float64(7)},
+	}, resources[3].Properties)
+
+	assert.Equal(t, "scratch", resources[4].Name)
+}
+
+// TestDiscoverResources_PrivateEndpoints tests that storage accounts with
+// private endpoints expand into Microsoft.Network/privateEndpoints resources
+func TestDiscoverResources_PrivateEndpoints(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+const peSubnet = "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'private-endpoints')]"
+
+var orders = (&storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+}).WithPrivateEndpoint("[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'private-endpoints')]", "blob").
+	WithPrivateEndpoint("[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'private-endpoints')]", "file")
+
+var invoices = storage.StorageAccount{
+	Name:     "invoices",
+	Location: "eastus",
+	Properties: &storage.StorageAccountProperties{
+		PrivateEndpoints: []storage.PrivateEndpointSettings{
+			{SubnetID: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pe", GroupID: "blob_secondary"},
+		},
+	},
+}
+
+var unresolved = (&storage.StorageAccount{
+	Name:     "unresolved",
+	Location: "eastus",
+}).WithPrivateEndpoint(peSubnet, "blob")
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 6)
+
+	blob := resources[1]
+	assert.Equal(t, "ordersBlobPrivateEndpoint", blob.Name)
+	assert.Equal(t, "Microsoft.Network/privateEndpoints", blob.Type)
+	assert.Equal(t, "orders-blob-pe", blob.ARMName)
+	assert.Equal(t, "2023-04-01", blob.APIVersion)
+	assert.Equal(t, []string{"orders"}, blob.Dependencies)
+	assert.Equal(t, map[string]any{
+		"subnet": map[string]any{"id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'private-endpoints')]"},
+		"privateLinkServiceConnections": []any{map[string]any{
+			"name": "orders-blob-pe",
+			"properties": map[string]any{
+				"privateLinkServiceId": "[resourceId('Microsoft.Storage/storageAccounts', 'orders')]",
+				"groupIds":             []any{"blob"},
+			},
+		}},
+	}, blob.Properties)
+
+	assert.Equal(t, "ordersFilePrivateEndpoint", resources[2].Name)
+	assert.Equal(t, "invoicesBlobSecondaryPrivateEndpoint", resources[4].Name)
+	assert.Equal(t, "invoices-blob_secondary-pe", resources[4].ARMName)
+
+	// Subnet IDs given as identifiers are not resolved, so no endpoint is emitted
+	assert.Equal(t, "unresolved", resources[5].Name)
+}
+
+// TestDiscoverResources_ManagementLocks tests that locks declared on a
+// resource, and delete locks on storage accounts, are scoped to and depend on
+// the locked resource
+func TestDiscoverResources_ManagementLocks(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/authorization"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = (&storage.StorageAccount{
+	Name:     "logs",
+	Location: "eastus",
+}).WithDeleteLock()
+
+var Invoices = storage.StorageAccount{
+	Name:       "invoices",
+	Location:   "eastus",
+	Properties: &storage.StorageAccountProperties{DeleteLock: true},
+}
+
+var LogsReadOnly = authorization.ManagementLock{
+	Name:       "logs-read-only",
+	Scope:      Logs.ID(),
+	Properties: authorization.ManagementLockProperties{Level: authorization.LockLevelReadOnly},
+}
+
+var InvoicesReadOnly = authorization.ManagementLock{
+	Name:  "invoices-read-only",
+	Scope: intrinsics.ResourceRef("Invoices"),
+}
+
+var GroupLock = authorization.ManagementLock{
+	Name: "group-lock",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 7)
+
+	byName := make(map[string]DiscoveredResource)
+	for _, r := range resources {
+		byName[r.Name] = r
+	}
+
+	lock := byName["LogsDeleteLock"]
+	assert.Equal(t, "Microsoft.Authorization/locks", lock.Type)
+	assert.Equal(t, "logs-delete-lock", lock.ARMName)
+	assert.Equal(t, "2020-05-01", lock.APIVersion)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]", lock.Scope)
+	assert.Equal(t, []string{"Logs"}, lock.Dependencies)
+	assert.Equal(t, map[string]any{
+		"level": "CanNotDelete",
+		"notes": "Protects the storage account from deletion",
+	}, lock.Properties)
+
+	assert.Contains(t, byName, "InvoicesDeleteLock")
+
+	// Scopes referencing another resource are resolved by the builder
+	readOnly, err := byName["LogsReadOnly"].ResolveResourceRefs(byName)
+	require.NoError(t, err)
+	assert.Equal(t, "Microsoft.Authorization/locks", readOnly.Type)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]", readOnly.Scope)
+	assert.Equal(t, []string{"Logs"}, readOnly.Dependencies)
+
+	invoicesReadOnly, err := byName["InvoicesReadOnly"].ResolveResourceRefs(byName)
+	require.NoError(t, err)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'invoices')]", invoicesReadOnly.Scope)
+	assert.Equal(t, []string{"Invoices"}, invoicesReadOnly.Dependencies)
+
+	assert.Empty(t, byName["GroupLock"].Scope)
+	assert.Empty(t, byName["GroupLock"].Dependencies)
+}
+
+// TestDiscoverResources_ContainerGroup tests that a container group declared
+// with a nested containers array is discovered
+func TestDiscoverResources_ContainerGroup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/containerinstance"
+
+var helloACI = containerinstance.ContainerGroup{
+	Name:     "hello-aci",
+	Location: "eastus",
+	Properties: containerinstance.ContainerGroupProperties{
+		OSType: "Linux",
+		Containers: []containerinstance.Container{{
+			Name: "hello",
+			Properties: containerinstance.ContainerProperties{
+				Image: "mcr.microsoft.com/azuredocs/aci-helloworld",
+				Resources: containerinstance.ResourceRequirements{
+					Requests: containerinstance.ResourceRequests{CPU: 1, MemoryInGB: 1.5},
+				},
+			},
+		}},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+
+	assert.Equal(t, "helloACI", resources[0].Name)
+	assert.Equal(t, "Microsoft.ContainerInstance/containerGroups", resources[0].Type)
+	assert.Empty(t, resources[0].Dependencies)
+}
+
+// TestDiscoverResources_ApplicationSecurityGroup tests that NSG rules
+// referencing application security groups depend on them
+func TestDiscoverResources_ApplicationSecurityGroup(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var webASG = network.ApplicationSecurityGroup{
+	Name:     "web-asg",
+	Location: "eastus",
+}
+
+var dbASG = network.ApplicationSecurityGroup{
+	Name:     "db-asg",
+	Location: "eastus",
+}
+
+var appNSG = network.NetworkSecurityGroup{
+	Name:     "app-nsg",
+	Location: "eastus",
+	Properties: network.NetworkSecurityGroupProperties{
+		SecurityRules: []network.SecurityRule{{
+			Name: "web-to-db",
+			Properties: network.SecurityRuleProperties{
+				Priority:                             200,
+				Direction:                            "Inbound",
+				Access:                               "Allow",
+				Protocol:                             "Tcp",
+				DestinationPortRange:                 "1433",
+				SourceApplicationSecurityGroups:      []network.SubResource{*network.NewSubResource(webASG.ID())},
+				DestinationApplicationSecurityGroups: []network.SubResource{*network.NewSubResource(dbASG.ID())},
+			},
+		}},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "Microsoft.Network/applicationSecurityGroups", resources[0].Type)
+	assert.Equal(t, "Microsoft.Network/applicationSecurityGroups", resources[1].Type)
+	assert.Equal(t, "appNSG", resources[2].Name)
+	assert.ElementsMatch(t, []string{"webASG", "dbASG"}, resources[2].Dependencies)
+}
+
+// TestDiscoverResources_Value tests that literal declarations of validated
+// types are evaluated into Value, and others are not
+func TestDiscoverResources_Value(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var literalStorage = storage.StorageAccount{
+	Name:     "mystorage",
+	Location: "eastus",
+	Kind:     "BlockBlobStorage",
+	SKU:      storage.SKU{Name: "Standard_LRS"},
+	Tags:     map[string]string{"env": "prod"},
+}
+
+var referencingStorage = storage.StorageAccount{
+	Name:     "other",
+	Location: literalStorage.Location,
+}
+
+var webNSG = &network.NetworkSecurityGroup{
+	Name: "web-nsg",
+	Properties: network.NetworkSecurityGroupProperties{
+		SecurityRules: []network.SecurityRule{{
+			Name:       "allow-https",
+			Properties: network.SecurityRuleProperties{Priority: 5000, Access: "Allow"},
+		}},
+	},
+}
+
+var myVNet = network.VirtualNetwork{Name: "vnet"}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 4)
+
+	account, ok := resources[0].Value.(*storage.StorageAccount)
+	require.True(t, ok, "Expected a *storage.StorageAccount, got %T", resources[0].Value)
+	assert.Equal(t, "BlockBlobStorage", account.Kind)
+	assert.Equal(t, "Standard_LRS", account.SKU.Name)
+	assert.Equal(t, map[string]string{"env": "prod"}, account.Tags)
+
+	// A field referring to another variable has no literal value
+	assert.Nil(t, resources[1].Value)
+
+	nsg, ok := resources[2].Value.(*network.NetworkSecurityGroup)
+	require.True(t, ok, "Expected a *network.NetworkSecurityGroup, got %T", resources[2].Value)
+	require.Len(t, nsg.Properties.SecurityRules, 1)
+	assert.Equal(t, 5000, nsg.Properties.SecurityRules[0].Properties.Priority)
+
+	// Types without Validate methods are not evaluated
+	assert.Nil(t, resources[3].Value)
+}
+
+// TestDiscoverResources_PointerHelpers tests that pointer fields set with
+// helpers such as boolPtr(true) are evaluated, and other calls are not
+func TestDiscoverResources_PointerHelpers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var dataLake = storage.StorageAccount{
+	Name:     "datalake",
+	Location: "eastus",
+	Properties: &storage.StorageAccountProperties{
+		IsHnsEnabled:  boolPtr(true),
+		IsSftpEnabled: boolPtr(true),
+		AccessTier:    strPtr("Hot"),
+	},
+}
+
+var computed = storage.StorageAccount{
+	Name:       "computed",
+	Location:   "eastus",
+	Properties: newProperties(),
+}
+
+func boolPtr(b bool) *bool    { return &b }
+func strPtr(s string) *string { return &s }
+
+func newProperties() *storage.StorageAccountProperties { return nil }
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	account, ok := resources[0].Value.(*storage.StorageAccount)
+	require.True(t, ok, "Expected a *storage.StorageAccount, got %T", resources[0].Value)
+	require.NotNil(t, account.Properties)
+	require.NotNil(t, account.Properties.IsSftpEnabled)
+	assert.True(t, *account.Properties.IsSftpEnabled)
+	require.NotNil(t, account.Properties.AccessTier)
+	assert.Equal(t, "Hot", *account.Properties.AccessTier)
+
+	// A call without arguments is not a pointer helper
+	assert.Nil(t, resources[1].Value)
+}
+
+// TestDiscoverResources_DiagnosticSetting tests that a diagnostic setting
+// depends on its target and destination resources
+func TestDiscoverResources_DiagnosticSetting(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/insights"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var appData = storage.StorageAccount{Name: "appdata", Location: "eastus"}
+
+var archive = storage.StorageAccount{Name: "archive", Location: "eastus"}
+
+var appDataDiagnostics = insights.DiagnosticSetting{
+	Name:             "send-to-archive",
+	TargetResourceID: appData.ID(),
+	Properties: insights.DiagnosticSettingProperties{
+		StorageAccountID: archive.ID(),
+		Logs:             []insights.LogSettings{{CategoryGroup: "allLogs", Enabled: true}},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "appDataDiagnostics", resources[2].Name)
+	assert.Equal(t, "Microsoft.Insights/diagnosticSettings", resources[2].Type)
+	assert.ElementsMatch(t, []string{"appData", "archive"}, resources[2].Dependencies)
+}
+
+// TestDiscoverResources_FrontDoorWAFPolicy tests discovery of Front Door WAF policies
+func TestDiscoverResources_FrontDoorWAFPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var edgeWAF = network.FrontDoorWebApplicationFirewallPolicy{
+	Name:     "edgewaf",
+	Location: "Global",
+	SKU:      network.FrontDoorWAFSKU{Name: "Premium_AzureFrontDoor"},
+	Properties: network.FrontDoorWAFPolicyProperties{
+		PolicySettings: network.FrontDoorWAFPolicySettings{EnabledState: "Enabled", Mode: "Prevention"},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+
+	assert.Equal(t, "edgeWAF", resources[0].Name)
+	assert.Equal(t, "Microsoft.Network/FrontDoorWebApplicationFirewallPolicies", resources[0].Type)
+}
+
+// TestDiscoverResources_AgentPool tests that a standalone agent pool depends
+// on the cluster named in its name path
+func TestDiscoverResources_AgentPool(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/aks"
+
+var appAKS = aks.ManagedCluster{
+	Name:     "app-aks",
+	Location: "eastus",
+}
+
+var userPool = aks.AgentPool{
+	Name: appAKS.Name + "/user",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	assert.Equal(t, "userPool", resources[1].Name)
+	assert.Equal(t, "Microsoft.ContainerService/managedClusters/agentPools", resources[1].Type)
+	assert.Equal(t, []string{"appAKS"}, resources[1].Dependencies)
+}
+
+// TestDiscoverResources_MaintenanceConfiguration tests that a maintenance
+// configuration depends on the cluster named in its name path
+func TestDiscoverResources_MaintenanceConfiguration(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/aks"
+
+var appAKS = aks.ManagedCluster{
+	Name:     "app-aks",
+	Location: "eastus",
+}
+
+var releaseWindow = aks.MaintenanceConfiguration{
+	Name: appAKS.Name + "/" + aks.MaintenanceConfigurationDefault,
+	Properties: aks.MaintenanceConfigurationProperties{
+		TimeInWeek: []aks.TimeInWeek{{Day: "Saturday", HourSlots: []int{1, 2}}},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	assert.Equal(t, "releaseWindow", resources[1].Name)
+	assert.Equal(t, "Microsoft.ContainerService/managedClusters/maintenanceConfigurations", resources[1].Type)
+	assert.Equal(t, []string{"appAKS"}, resources[1].Dependencies)
+}
+
+// TestDiscoverResources_SSHKeyResource tests that a VM using a shared SSH key
+// resource depends on it
+func TestDiscoverResources_SSHKeyResource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/compute"
+
+var deployKey = compute.SSHPublicKeyResource{
+	Name:     "deploy-key",
+	Location: "eastus",
+}
+
+var webVM = (&compute.VirtualMachine{
+	Name:     "web-vm",
+	Location: "eastus",
+}).WithSSHKeyResource(deployKey.Name)
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	assert.Equal(t, "Microsoft.Compute/sshPublicKeys", resources[0].Type)
+	assert.Equal(t, "webVM", resources[1].Name)
+	assert.Equal(t, "Microsoft.Compute/virtualMachines", resources[1].Type)
+	assert.Equal(t, []string{"deployKey"}, resources[1].Dependencies)
+}
+
+// TestDiscoverResources_VirtualNetworkSubnet tests that a standalone subnet
+// depends on its virtual network and network security group
+func TestDiscoverResources_VirtualNetworkSubnet(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var appVNet = network.VirtualNetwork{
+	Name:     "app-vnet",
+	Location: "eastus",
+}
+
+var webNSG = network.NetworkSecurityGroup{
+	Name:     "web-nsg",
+	Location: "eastus",
+}
+
+var webSubnet = network.VirtualNetworkSubnet{
+	Name: appVNet.Name + "/web",
+	Properties: network.SubnetProperties{
+		AddressPrefix:        "10.0.1.0/24",
+		NetworkSecurityGroup: network.NewSubResource(webNSG.ID()),
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "webSubnet", resources[2].Name)
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/subnets", resources[2].Type)
+	assert.ElementsMatch(t, []string{"appVNet", "webNSG"}, resources[2].Dependencies)
+}
+
+// TestDiscoverResources_Grafana tests that a Grafana instance depends on the
+// Azure Monitor workspace it integrates
+func TestDiscoverResources_Grafana(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/dashboard"
+	"github.com/lex00/wetwire-azure-go/resources/insights"
+)
+
+var prometheus = insights.AzureMonitorWorkspace{
+	Name:     "prometheus",
+	Location: "eastus",
+}
+
+var grafana = dashboard.Grafana{
+	Name:     "ops-grafana",
+	Location: "eastus",
+	SKU:      dashboard.SKU{Name: "Standard"},
+	Identity: &dashboard.Identity{Type: "SystemAssigned"},
+	Properties: dashboard.GrafanaProperties{
+		GrafanaIntegrations: &dashboard.GrafanaIntegrations{
+			AzureMonitorWorkspaceIntegrations: []dashboard.AzureMonitorWorkspaceIntegration{
+				{AzureMonitorWorkspaceResourceID: prometheus.ID()},
+			},
+		},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	assert.Equal(t, "Microsoft.Monitor/accounts", resources[0].Type)
+	assert.Equal(t, "Microsoft.Dashboard/grafana", resources[1].Type)
+	assert.Equal(t, "Standard", resources[1].SKU)
+	assert.Equal(t, []string{"prometheus"}, resources[1].Dependencies)
+}
+
+// TestDiscoverResources_CognitiveServices tests that a model deployment
+// depends on the account it belongs to
+func TestDiscoverResources_CognitiveServices(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/cognitiveservices"
+
+var openAI = cognitiveservices.Account{
+	Name:     "my-openai",
+	Location: "eastus",
+	Kind:     "OpenAI",
+	SKU:      cognitiveservices.SKU{Name: "S0"},
+}
+
+var chat = cognitiveservices.Deployment{
+	Name: openAI.Name + "/chat",
+	Properties: cognitiveservices.DeploymentProperties{
+		Model: cognitiveservices.DeploymentModel{Format: "OpenAI", Name: "gpt-4o"},
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	assert.Equal(t, "Microsoft.CognitiveServices/accounts", resources[0].Type)
+	assert.Equal(t, "S0", resources[0].SKU)
+	assert.Equal(t, "chat", resources[1].Name)
+	assert.Equal(t, "Microsoft.CognitiveServices/accounts/deployments", resources[1].Type)
+	assert.Equal(t, []string{"openAI"}, resources[1].Dependencies)
 }
 
-// TestDiscoverResources_ContainerRegistry tests container registry discovery
-func TestDiscoverResources_ContainerRegistry(t *testing.T) {
+// TestDiscoverResources_DataFactory tests that linked services and pipelines
+// depend on the data factory they belong to
+func TestDiscoverResources_DataFactory(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	code := `package main
 
-import "github.com/lex00/wetwire-azure-go/resources/containerregistry"
+import "github.com/lex00/wetwire-azure-go/resources/datafactory"
 
-var myRegistry = containerregistry.Registry{
-	Name:     "myregistry",
+var etlFactory = datafactory.Factory{
+	Name:     "etl-factory",
 	Location: "eastus",
 }
+
+var blobLink = datafactory.LinkedService{
+	Name: etlFactory.Name + "/blob",
+}
+
+var nightlyCopy = datafactory.Pipeline{
+	Name: etlFactory.Name + "/nightly-copy",
+}
 `
 	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
 	require.NoError(t, err)
 
 	resources, err := DiscoverResources(tmpDir)
 	require.NoError(t, err)
-	require.Len(t, resources, 1)
-	assert.Equal(t, "Microsoft.ContainerRegistry/registries", resources[0].Type)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "Microsoft.DataFactory/factories", resources[0].Type)
+	assert.Equal(t, "Microsoft.DataFactory/factories/linkedservices", resources[1].Type)
+	assert.Equal(t, []string{"etlFactory"}, resources[1].Dependencies)
+	assert.Equal(t, "Microsoft.DataFactory/factories/pipelines", resources[2].Type)
+	assert.Equal(t, []string{"etlFactory"}, resources[2].Dependencies)
 }
 
-// TestDiscoverResources_AKS tests managed cluster discovery
-func TestDiscoverResources_AKS(t *testing.T) {
+// TestDiscoverResources_ContainerApp tests that a container app with nested
+// containers and scale rules is discovered and depends on its environment
+func TestDiscoverResources_ContainerApp(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	code := `package main
 
-import "github.com/lex00/wetwire-azure-go/resources/aks"
+import "github.com/lex00/wetwire-azure-go/resources/app"
 
-var myCluster = aks.ManagedCluster{
-	Name:     "myakscluster",
+var AppsEnv = app.ManagedEnvironment{Name: "apps-env", Location: "eastus"}
+
+var API = app.ContainerApp{
+	Name:     "api",
 	Location: "eastus",
+	Properties: app.ContainerAppProperties{
+		EnvironmentID: AppsEnv.ID(),
+		Configuration: &app.Configuration{
+			Ingress: &app.Ingress{External: true, TargetPort: 8080},
+		},
+		Template: &app.Template{
+			Containers: []app.Container{{
+				Name:      "api",
+				Image:     "mcr.microsoft.com/k8se/quickstart:latest",
+				Resources: &app.ContainerResources{CPU: 0.5, Memory: "1Gi"},
+			}},
+			Scale: &app.Scale{
+				Rules: []app.ScaleRule{{
+					Name: "http-load",
+					HTTP: &app.HTTPScaleRule{Metadata: map[string]string{"concurrentRequests": "50"}},
+				}},
+			},
+		},
+	},
 }
 `
 	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
@@ -170,8 +1562,15 @@ var myCluster = aks.ManagedCluster{
 
 	resources, err := DiscoverResources(tmpDir)
 	require.NoError(t, err)
-	require.Len(t, resources, 1)
-	assert.Equal(t, "Microsoft.ContainerService/managedClusters", resources[0].Type)
+	require.Len(t, resources, 2)
+
+	byName := map[string]DiscoveredResource{}
+	for _, r := range resources {
+		byName[r.Name] = r
+	}
+	assert.Equal(t, "Microsoft.App/managedEnvironments", byName["AppsEnv"].Type)
+	assert.Equal(t, "Microsoft.App/containerApps", byName["API"].Type)
+	assert.Equal(t, []string{"AppsEnv"}, byName["API"].Dependencies)
 }
 
 // TestDiscoverResources_AllNetworkTypes tests all network resource types
@@ -591,3 +1990,440 @@ var myVNet = network.VirtualNetwork{
 	require.Len(t, resources, 1) // Only VirtualNetwork should be discovered
 }
 
+// TestDiscoverResources_WebSlotsAndBindings tests that deployment slots and
+// host name bindings depend on their app, and bindings on their certificate
+func TestDiscoverResources_WebSlotsAndBindings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/web"
+
+var shop = web.Site{
+	Name:     "shop-web",
+	Location: "eastus",
+}
+
+var shopStaging = web.SiteSlot{
+	Name:     shop.Name + "/staging",
+	Location: "eastus",
+}
+
+var shopCert = web.Certificate{
+	Name:     "shop-cert",
+	Location: "eastus",
+}
+
+var shopDomain = web.HostNameBinding{
+	Name: shop.Name + "/shop.example.com",
+	Properties: web.HostNameBindingProperties{
+		SiteName:   shop.Name,
+		Thumbprint: shopCert.Thumbprint(),
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 4)
+
+	assert.Equal(t, "Microsoft.Web/sites", resources[0].Type)
+	assert.Equal(t, "Microsoft.Web/sites/slots", resources[1].Type)
+	assert.Equal(t, []string{"shop"}, resources[1].Dependencies)
+	assert.Equal(t, "Microsoft.Web/certificates", resources[2].Type)
+	assert.Equal(t, "Microsoft.Web/sites/hostNameBindings", resources[3].Type)
+	assert.ElementsMatch(t, []string{"shop", "shopCert"}, resources[3].Dependencies)
+}
+
+// TestDiscoverResources_StorageServices tests that blob, queue, and table
+// services declared apart from their account depend on it
+func TestDiscoverResources_StorageServices(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var orders = storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+}
+
+var ordersBlobs = storage.BlobService{
+	Name: orders.Name + "/default",
+	Properties: storage.BlobServiceProperties{
+		IsVersioningEnabled: true,
+	},
+}
+
+var ordersQueues = storage.QueueService{
+	Name: orders.Name + "/default",
+	Properties: storage.QueueServiceProperties{
+		Cors: &storage.CORSRules{CORSRules: []storage.CORSRule{{AllowedOrigins: []string{"*"}}}},
+	},
+}
+
+var ordersTables = storage.TableService{Name: orders.Name + "/default"}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 4)
+
+	assert.Equal(t, "Microsoft.Storage/storageAccounts", resources[0].Type)
+	for i, want := range []string{
+		"Microsoft.Storage/storageAccounts/blobServices",
+		"Microsoft.Storage/storageAccounts/queueServices",
+		"Microsoft.Storage/storageAccounts/tableServices",
+	} {
+		assert.Equal(t, want, resources[i+1].Type)
+		assert.Equal(t, []string{"orders"}, resources[i+1].Dependencies)
+	}
+}
+
+// TestDiscoverResources_VirtualWAN tests that a hub depends on its virtual
+// WAN, and a hub connection on its hub and virtual network
+func TestDiscoverResources_VirtualWAN(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var wan = network.VirtualWAN{Name: "corp-wan", Location: "eastus"}
+
+var hub = network.VirtualHub{
+	Name:     "eastus-hub",
+	Location: "eastus",
+	Properties: network.VirtualHubProperties{
+		VirtualWan:    network.NewSubResource(wan.ID()),
+		AddressPrefix: "10.100.0.0/23",
+	},
+}
+
+var appVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var appConnection = network.HubVirtualNetworkConnection{
+	Name: hub.Name + "/app-vnet",
+	Properties: network.HubVirtualNetworkConnectionProperties{
+		RemoteVirtualNetwork: network.NewSubResource(appVNet.ID()),
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 4)
+
+	assert.Equal(t, "Microsoft.Network/virtualWans", resources[0].Type)
+	assert.Equal(t, "Microsoft.Network/virtualHubs", resources[1].Type)
+	assert.Equal(t, []string{"wan"}, resources[1].Dependencies)
+	assert.Equal(t, "Microsoft.Network/virtualHubs/hubVirtualNetworkConnections", resources[3].Type)
+	assert.ElementsMatch(t, []string{"hub", "appVNet"}, resources[3].Dependencies)
+}
+
+// TestDiscoverResources_ResourceRef tests that intrinsics.ResourceRef links a
+// NIC to a subnet by variable name and resolves to resourceId expressions
+func TestDiscoverResources_ResourceRef(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var MyVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var AppNIC = network.NetworkInterface{
+	Name:     "app-nic",
+	Location: "eastus",
+	Properties: network.NetworkInterfaceProperties{
+		IPConfigurations: []network.IPConfiguration{{
+			Name: "ipconfig1",
+			Properties: network.IPConfigurationProperties{
+				Subnet: network.NewSubResource(intrinsics.ResourceRef("MyVNet", "subnets", "app")),
+			},
+		}},
+	},
+}
+
+var Orders = (&storage.StorageAccount{Name: "orders", Location: "eastus"}).
+	WithPrivateEndpoint(intrinsics.ResourceRef("MyVNet", "subnets", "pe"), "blob")
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 4)
+
+	assert.Equal(t, "AppNIC", resources[1].Name)
+	assert.Equal(t, []string{"MyVNet"}, resources[1].Dependencies)
+
+	endpoint := resources[3]
+	assert.Equal(t, "Microsoft.Network/privateEndpoints", endpoint.Type)
+	assert.ElementsMatch(t, []string{"Orders", "MyVNet"}, endpoint.Dependencies)
+	subnet, ok := endpoint.Properties["subnet"].(map[string]any)
+	require.True(t, ok, "endpoint properties: %v", endpoint.Properties)
+	assert.Equal(t, "[wetwire.resourceRef('MyVNet', 'subnets', 'pe')]", subnet["id"])
+
+	// The builder resolves the reference once child resources are named
+	resolved, err := endpoint.ResolveResourceRefs(map[string]DiscoveredResource{"MyVNet": resources[0]})
+	require.NoError(t, err)
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'pe')]", resolved.Properties["subnet"].(map[string]any)["id"])
+}
+
+// TestDiscoverResources_RawResourceRef tests that intrinsics.ResourceRef calls
+// in the properties of a template.RawResource are resolved
+func TestDiscoverResources_RawResourceRef(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+var MyVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var Workspace = template.RawResource{
+	Type:       "Microsoft.HealthcareApis/workspaces",
+	APIVersion: "2023-11-01",
+	Name:       "health",
+	Properties: map[string]any{"subnetId": intrinsics.ResourceRef("MyVNet", "subnets", "app")},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	workspace := resources[1]
+	require.NotNil(t, workspace.Raw)
+	assert.Equal(t, []string{"MyVNet"}, workspace.Dependencies)
+	assert.Equal(t, "[wetwire.resourceRef('MyVNet', 'subnets', 'app')]", workspace.Raw.Properties["subnetId"])
+
+	byName := map[string]DiscoveredResource{"MyVNet": resources[0]}
+	resolved, err := workspace.ResolveResourceRefs(byName)
+	require.NoError(t, err)
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'app')]", resolved.Raw.Properties["subnetId"])
+	assert.Equal(t, "[wetwire.resourceRef('MyVNet', 'subnets', 'app')]", workspace.Raw.Properties["subnetId"], "discovery results are not changed")
+}
+
+// TestDiscoverResources_Names tests that the ARM name of a resource is taken
+// from a literal or naming.Unique Name, and left to the variable otherwise
+func TestDiscoverResources_Names(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/naming"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Literal = storage.StorageAccount{Name: "logs"}
+
+var Parameter = storage.StorageAccount{Name: "[parameters('dataName')]"}
+
+var Unique = storage.StorageAccount{
+	Name: naming.Unique("Prod-Logs").For("Microsoft.Storage/storageAccounts").String(),
+}
+
+var Computed = storage.StorageAccount{Name: strings.ToLower("Archive")}
+
+var Unnamed = storage.StorageAccount{Location: "eastus"}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 5)
+
+	names := make(map[string]string)
+	for _, r := range resources {
+		names[r.Name] = r.TemplateName()
+	}
+	assert.Equal(t, map[string]string{
+		"Literal":   "logs",
+		"Parameter": "[parameters('dataName')]",
+		"Unique":    "[concat('prodlogs', uniqueString(resourceGroup().id))]",
+		"Computed":  "Computed",
+		"Unnamed":   "Unnamed",
+	}, names)
+}
+
+// TestDiscoverResources_ResourceRefErrors tests references that cannot be
+// resolved
+func TestDiscoverResources_ResourceRefErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		wantErr string
+	}{
+		{"unknown resource", `intrinsics.ResourceRef("OtherVNet", "subnets", "pe")`, "ResourceRef to unknown resource OtherVNet"},
+		{"odd sub-path", `intrinsics.ResourceRef("MyVNet", "subnets")`, "not pairs of child type and name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var MyVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var Orders = (&storage.StorageAccount{Name: "orders", Location: "eastus"}).
+	WithPrivateEndpoint(` + tt.ref + `, "blob")
+`
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644))
+
+			_, err := DiscoverResources(tmpDir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestDiscoverResources_RealTimeServices tests discovery of SignalR, Web
+// PubSub and Azure Maps declarations
+func TestDiscoverResources_RealTimeServices(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/maps"
+	"github.com/lex00/wetwire-azure-go/resources/signalr"
+	"github.com/lex00/wetwire-azure-go/resources/webpubsub"
+)
+
+var chat = signalr.Service{
+	Name:     "chat-signalr",
+	Location: "eastus",
+	SKU:      signalr.SKU{Name: "Standard_S1", Capacity: 1},
+	Properties: signalr.ServiceProperties{
+		Features: []signalr.Feature{{Flag: "ServiceMode", Value: "Serverless"}},
+		Cors:     &signalr.CorsSettings{AllowedOrigins: []string{"https://app.contoso.com"}},
+	},
+}
+
+var events = webpubsub.Service{
+	Name:     "events-pubsub",
+	Location: "eastus",
+	SKU:      webpubsub.SKU{Name: "Free_F1"},
+}
+
+var fleet = maps.Account{Name: "fleet-maps", Location: "eastus", SKU: maps.SKU{Name: "G2"}}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "Microsoft.SignalRService/signalR", resources[0].Type)
+	assert.Equal(t, "Standard_S1", resources[0].SKU)
+	assert.Equal(t, "Microsoft.SignalRService/webPubSub", resources[1].Type)
+	assert.Equal(t, "Free_F1", resources[1].SKU)
+	assert.Equal(t, "Microsoft.Maps/accounts", resources[2].Type)
+	assert.Equal(t, "G2", resources[2].SKU)
+}
+
+// TestDiscoverResources_PrivateDNSZoneLink tests that a private DNS zone link
+// depends on its zone and its virtual network
+func TestDiscoverResources_PrivateDNSZoneLink(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var blobZone = network.PrivateDNSZone{Name: "privatelink.blob.core.windows.net", Location: "global"}
+
+var appVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+
+var appLink = network.PrivateDnsZoneVirtualNetworkLink{
+	Name:     blobZone.Name + "/app-vnet",
+	Location: "global",
+	Properties: network.PrivateDnsZoneVirtualNetworkLinkProperties{
+		VirtualNetwork: network.NewSubResource(appVNet.ID()),
+	},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "Microsoft.Network/privateDnsZones", resources[0].Type)
+	link := resources[2]
+	assert.Equal(t, "Microsoft.Network/privateDnsZones/virtualNetworkLinks", link.Type)
+	assert.ElementsMatch(t, []string{"blobZone", "appVNet"}, link.Dependencies)
+}
+
+// TestDiscoverResources_KeyVaultChildren tests that keys depend on their
+// vault, and that Managed HSM pools are discovered
+func TestDiscoverResources_KeyVaultChildren(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var corpVault = keyvault.Vault{
+	Name:     "corp-kv",
+	Location: "eastus",
+}
+
+var storageKey = keyvault.Key{
+	Name: corpVault.Name + "/storage-key",
+	Properties: keyvault.KeyProperties{
+		Kty:    "RSA",
+		KeyOps: []string{"wrapKey", "unwrapKey"},
+	},
+}
+
+var corpHSM = keyvault.ManagedHSM{
+	Name:     "corp-hsm",
+	Location: "eastus",
+	SKU:      keyvault.ManagedHSMSKU{Family: "B", Name: "Standard_B1"},
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	assert.Equal(t, "Microsoft.KeyVault/vaults/keys", resources[1].Type)
+	assert.Equal(t, []string{"corpVault"}, resources[1].Dependencies)
+	assert.Equal(t, "Microsoft.KeyVault/managedHSMs", resources[2].Type)
+	assert.Equal(t, "Standard_B1", resources[2].SKU)
+	assert.Empty(t, resources[2].Dependencies)
+}
@@ -1,10 +1,13 @@
 package discover
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/lex00/wetwire-azure-go/intrinsics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -325,3 +328,218 @@ var nic = network.NetworkInterface{
 	assert.Contains(t, nic.Dependencies, "vnet")
 	assert.Contains(t, nic.Dependencies, "subnet")
 }
+
+// writeManyResourceFiles writes n Go files, each declaring a storage account and
+// a virtual network that depends on it, across nested directories
+func writeManyResourceFiles(tb testing.TB, dir string, n int) {
+	tb.Helper()
+
+	for i := 0; i < n; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("pkg%d", i%5))
+		require.NoError(tb, os.MkdirAll(sub, 0755))
+
+		code := fmt.Sprintf(`package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var storage%[1]d = storage.StorageAccount{
+	Name:     "storage%[1]d",
+	Location: "eastus",
+}
+
+var vnet%[1]d = network.VirtualNetwork{
+	Name:     storage%[1]d.Name,
+	Location: "eastus",
+}
+`, i)
+		path := filepath.Join(sub, fmt.Sprintf("file%03d.go", i))
+		require.NoError(tb, os.WriteFile(path, []byte(code), 0644))
+	}
+}
+
+func TestDiscoverResources_ParallelMatchesSerial(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeManyResourceFiles(t, tmpDir, 50)
+
+	paths, err := collectGoFiles(tmpDir, nil)
+	require.NoError(t, err)
+
+	serial, err := parseFiles(paths, 1)
+	require.NoError(t, err)
+	require.Len(t, serial, 100)
+
+	for _, workers := range []int{2, 8, 64} {
+		parallel, err := parseFiles(paths, workers)
+		require.NoError(t, err)
+		assert.Equal(t, serial, parallel, "workers=%d", workers)
+	}
+
+	// Results are ordered by file, then line
+	for i := 1; i < len(serial); i++ {
+		prev, cur := serial[i-1], serial[i]
+		if prev.File == cur.File {
+			assert.Less(t, prev.Line, cur.Line)
+		} else {
+			assert.Less(t, prev.File, cur.File)
+		}
+	}
+}
+
+func TestDiscoverResources_ParallelParseErrorIsDeterministic(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeManyResourceFiles(t, tmpDir, 10)
+
+	bad := []string{"aaa_bad.go", "zzz_bad.go"}
+	for _, name := range bad {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\nvar ="), 0644))
+	}
+
+	for i := 0; i < 5; i++ {
+		_, err := DiscoverResources(tmpDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "aaa_bad.go")
+	}
+}
+
+func BenchmarkDiscoverResources(b *testing.B) {
+	tmpDir := b.TempDir()
+	writeManyResourceFiles(b, tmpDir, 200)
+
+	paths, err := collectGoFiles(tmpDir, nil)
+	require.NoError(b, err)
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := parseFiles(paths, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := DiscoverResources(tmpDir); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestDiscoverResources_RawResource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet"}
+
+var Bastion = template.RawResource{
+	Type:       "Microsoft.Network/bastionHosts",
+	APIVersion: "2023-09-01",
+	Name:       "corp-bastion",
+	Location:   "eastus",
+	SKU:        map[string]any{"name": "Standard"},
+	Zones:      []string{"1", "2"},
+	Properties: map[string]interface{}{
+		"scaleUnits":       2,
+		"enableFileCopy":   true,
+		"ratio":            -0.5,
+		"ipConfigurations": []any{map[string]any{"name": "ipconfig"}},
+		"dnsName":          nil,
+	},
+}
+
+var Linked = &template.RawResource{
+	Type:       "Microsoft.Network/bastionHosts",
+	APIVersion: "2023-09-01",
+	Name:       "linked-bastion",
+	Properties: map[string]any{
+		"vnetId": intrinsics.ResourceRef("AppVNet"),
+		"prefix": intrinsics.Parameters("prefix"),
+		"name":   intrinsics.Concat{Values: []any{intrinsics.Parameters("prefix"), "-bastion"}},
+		"key":    intrinsics.ListKeysProperty("[resourceId('Microsoft.Storage/storageAccounts', 'logs')]", "2023-01-01", "keys[0].value"),
+	},
+}
+
+var kind = "Microsoft.Network/bastionHosts"
+
+var Untyped = template.RawResource{Type: kind, Name: "untyped"}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644))
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+
+	bastion := resources[1]
+	assert.Equal(t, "Bastion", bastion.Name)
+	assert.Equal(t, "Microsoft.Network/bastionHosts", bastion.Type)
+	assert.Equal(t, "2023-09-01", bastion.APIVersion)
+	assert.Equal(t, "corp-bastion", bastion.ARMName)
+	require.NotNil(t, bastion.Raw)
+	assert.Equal(t, "eastus", bastion.Raw.Location)
+	assert.Equal(t, map[string]any{"name": "Standard"}, bastion.Raw.SKU)
+	assert.Equal(t, []string{"1", "2"}, bastion.Raw.Zones)
+	assert.Equal(t, map[string]any{
+		"scaleUnits":       2,
+		"enableFileCopy":   true,
+		"ratio":            -0.5,
+		"ipConfigurations": []any{map[string]any{"name": "ipconfig"}},
+		"dnsName":          nil,
+	}, bastion.Raw.Properties)
+
+	// References keep their marker for the builder to resolve, and other
+	// intrinsics become their ARM expressions
+	linked := resources[2]
+	assert.Equal(t, "Linked", linked.Name)
+	assert.Equal(t, "Microsoft.Network/bastionHosts", linked.Type)
+	assert.Equal(t, "linked-bastion", linked.ARMName)
+	require.NotNil(t, linked.Raw)
+	assert.Equal(t, map[string]any{
+		"vnetId": intrinsics.ResourceRef("AppVNet"),
+		"prefix": "[parameters('prefix')]",
+		"name":   "[concat(parameters('prefix'), '-bastion')]",
+		"key":    "[listKeys(resourceId('Microsoft.Storage/storageAccounts', 'logs'), '2023-01-01').keys[0].value]",
+	}, linked.Raw.Properties)
+	assert.Equal(t, []string{"AppVNet"}, linked.Dependencies)
+
+	var out bytes.Buffer
+	require.NoError(t, TraceResources(tmpDir, &out))
+	assert.Contains(t, out.String(), "var Untyped rejected: type template.RawResource has no string literal Type field\n")
+}
+
+// TestDiscoverResources_RawResourceUnsupported tests that a RawResource with
+// a value discovery cannot evaluate fails with its position instead of
+// losing its properties
+func TestDiscoverResources_RawResourceUnsupported(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet"}
+
+var Bastion = template.RawResource{
+	Type:       "Microsoft.Network/bastionHosts",
+	Name:       "corp-bastion",
+	Properties: map[string]any{"vnet": AppVNet.Name, "size": 3},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644))
+
+	_, err := DiscoverResources(tmpDir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "main.go:13:37: unsupported value in template.RawResource Bastion")
+}
@@ -0,0 +1,349 @@
+package discover
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+)
+
+// evaluateLiteral builds a value of type t from a declaration made only of
+// literals: composite literals, &T{...}, strings, numbers and booleans,
+// pointer helpers such as boolPtr(true), intrinsics.ResourceRef calls, which
+// become their marker, and, as the value of an any, intrinsics values, which
+// become their ARM expression. It returns the first part of expr that refers
+// to something else, such as another variable or any other function call,
+// since that part's value is not known.
+func evaluateLiteral(t reflect.Type, expr ast.Expr, imports map[string]string) (reflect.Value, ast.Expr) {
+	v := reflect.New(t).Elem()
+	e := &literalEvaluator{imports: imports}
+	if !e.set(v, expr) {
+		return reflect.Value{}, e.unsupported
+	}
+	return v, nil
+}
+
+// literalEvaluator sets values from literal expressions of a file
+type literalEvaluator struct {
+	// imports maps the file's import aliases to their paths
+	imports map[string]string
+
+	// unsupported is the innermost expression that could not be evaluated
+	unsupported ast.Expr
+}
+
+// set sets v from the literal expr, recording expr if it cannot
+func (e *literalEvaluator) set(v reflect.Value, expr ast.Expr) bool {
+	if e.setLiteral(v, expr) {
+		return true
+	}
+	if e.unsupported == nil {
+		e.unsupported = expr
+	}
+	return false
+}
+
+// setLiteral sets v from the literal expr
+func (e *literalEvaluator) setLiteral(v reflect.Value, expr ast.Expr) bool {
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		return e.setAny(v, expr)
+	}
+
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return e.set(v, x.X)
+
+	case *ast.UnaryExpr:
+		switch {
+		case x.Op == token.AND && v.Kind() == reflect.Ptr:
+			elem := reflect.New(v.Type().Elem())
+			if !e.set(elem.Elem(), x.X) {
+				return false
+			}
+			v.Set(elem)
+			return true
+		case x.Op == token.SUB:
+			if !e.set(v, x.X) {
+				return false
+			}
+			switch v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				v.SetInt(-v.Int())
+				return true
+			case reflect.Float32, reflect.Float64:
+				v.SetFloat(-v.Float())
+				return true
+			}
+		}
+		return false
+
+	case *ast.CallExpr:
+		if ref := resourceRefValue(x, e.imports); ref != "" && v.Kind() == reflect.String {
+			v.SetString(ref)
+			return true
+		}
+
+		// A call with one literal argument for a pointer to a basic type is
+		// taken to be a pointer helper, as Go has no literal for *bool or
+		// *string: boolPtr(true), to.Ptr("Hot")
+		if v.Kind() != reflect.Ptr || len(x.Args) != 1 || x.Ellipsis.IsValid() {
+			return false
+		}
+		switch v.Type().Elem().Kind() {
+		case reflect.Bool, reflect.String, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64:
+			elem := reflect.New(v.Type().Elem())
+			if !e.set(elem.Elem(), x.Args[0]) {
+				return false
+			}
+			v.Set(elem)
+			return true
+		}
+		return false
+
+	case *ast.CompositeLit:
+		return e.setComposite(v, x)
+
+	case *ast.BasicLit:
+		return setBasic(v, x)
+
+	case *ast.Ident:
+		if v.Kind() == reflect.Bool && (x.Name == "true" || x.Name == "false") {
+			v.SetBool(x.Name == "true")
+			return true
+		}
+		if v.Kind() == reflect.Ptr || v.Kind() == reflect.Slice || v.Kind() == reflect.Map || v.Kind() == reflect.Interface {
+			return x.Name == "nil"
+		}
+	}
+	return false
+}
+
+// setComposite sets v from a composite literal
+func (e *literalEvaluator) setComposite(v reflect.Value, lit *ast.CompositeLit) bool {
+	// Elided &T{...} in a slice or map of pointers
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if !e.setComposite(elem.Elem(), lit) {
+			return false
+		}
+		v.Set(elem)
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return false
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				return false
+			}
+			field := v.FieldByName(key.Name)
+			if !field.IsValid() || !field.CanSet() || !e.set(field, kv.Value) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(lit.Elts), len(lit.Elts))
+		for i, elt := range lit.Elts {
+			if _, ok := elt.(*ast.KeyValueExpr); ok || !e.set(slice.Index(i), elt) {
+				return false
+			}
+		}
+		v.Set(slice)
+		return true
+
+	case reflect.Map:
+		m := reflect.MakeMapWithSize(v.Type(), len(lit.Elts))
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return false
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			value := reflect.New(v.Type().Elem()).Elem()
+			if !e.set(key, kv.Key) || !e.set(value, kv.Value) {
+				return false
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+		return true
+	}
+	return false
+}
+
+// setBasic sets v from a string, integer or floating-point literal
+func setBasic(v reflect.Value, lit *ast.BasicLit) bool {
+	switch lit.Kind {
+	case token.STRING:
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil || v.Kind() != reflect.String {
+			return false
+		}
+		v.SetString(s)
+		return true
+
+	case token.INT, token.FLOAT:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(lit.Value, 0, 64)
+			if err != nil {
+				return false
+			}
+			v.SetInt(n)
+			return true
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(lit.Value, 64)
+			if err != nil {
+				return false
+			}
+			v.SetFloat(f)
+			return true
+		}
+	}
+	return false
+}
+
+// setAny sets v, an empty interface such as a value of map[string]any, from
+// the literal expr, giving it the type the literal has on its own: string,
+// int, float64 or bool, or the map or slice type the composite literal names.
+// An intrinsics.ResourceRef call is set as its marker, and an intrinsics
+// value, such as intrinsics.Parameters("prefix") or an
+// intrinsics.Concat literal, is set as its ARM expression.
+func (e *literalEvaluator) setAny(v reflect.Value, expr ast.Expr) bool {
+	if ident, ok := expr.(*ast.Ident); ok && ident.Name == "nil" {
+		return true
+	}
+	if ref := resourceRefValue(expr, e.imports); ref != "" {
+		v.Set(reflect.ValueOf(ref))
+		return true
+	}
+	if value, ok := e.intrinsic(expr); ok {
+		v.Set(reflect.ValueOf(value.ARMExpression()))
+		return true
+	}
+	t := literalType(expr)
+	if t == nil {
+		return false
+	}
+	value := reflect.New(t).Elem()
+	if !e.set(value, expr) {
+		return false
+	}
+	v.Set(value)
+	return true
+}
+
+// intrinsic evaluates expr if it is a call of an intrinsics function or a
+// literal of an intrinsics type, such as intrinsics.Concat{Values: ...}
+func (e *literalEvaluator) intrinsic(expr ast.Expr) (intrinsics.Intrinsic, bool) {
+	switch x := expr.(type) {
+	case *ast.CallExpr:
+		return intrinsicValue(x, e.imports)
+	case *ast.CompositeLit:
+		sel, ok := x.Type.(*ast.SelectorExpr)
+		if !ok {
+			return nil, false
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || !isIntrinsicsPackage(e.imports[pkg.Name]) {
+			return nil, false
+		}
+		t, ok := intrinsicTypes[sel.Sel.Name]
+		if !ok {
+			return nil, false
+		}
+		value := reflect.New(t).Elem()
+		if !e.setComposite(value, x) {
+			return nil, false
+		}
+		return value.Interface().(intrinsics.Intrinsic), true
+	}
+	return nil, false
+}
+
+// intrinsicTypes maps the names of the intrinsics types that discovery
+// evaluates from literals to their Go types
+var intrinsicTypes = map[string]reflect.Type{
+	"Concat":             reflect.TypeOf(intrinsics.Concat{}),
+	"FormatValue":        reflect.TypeOf(intrinsics.FormatValue{}),
+	"ListKeysValue":      reflect.TypeOf(intrinsics.ListKeysValue{}),
+	"Parameter":          reflect.TypeOf(intrinsics.Parameter{}),
+	"Reference":          reflect.TypeOf(intrinsics.Reference{}),
+	"ResourceGroupValue": reflect.TypeOf(intrinsics.ResourceGroupValue{}),
+	"ResourceID":         reflect.TypeOf(intrinsics.ResourceID{}),
+	"Subscription":       reflect.TypeOf(intrinsics.Subscription{}),
+	"UniqueString":       reflect.TypeOf(intrinsics.UniqueString{}),
+	"Variable":           reflect.TypeOf(intrinsics.Variable{}),
+}
+
+// literalType returns the default Go type of the literal expr, or nil if it
+// has none that discovery evaluates
+func literalType(expr ast.Expr) reflect.Type {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return literalType(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.SUB {
+			return literalType(e.X)
+		}
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return reflect.TypeOf("")
+		case token.INT:
+			return reflect.TypeOf(0)
+		case token.FLOAT:
+			return reflect.TypeOf(0.0)
+		}
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return reflect.TypeOf(false)
+		}
+	case *ast.CompositeLit:
+		return genericType(e.Type)
+	}
+	return nil
+}
+
+// genericType returns the Go type named by expr if it is built only from
+// basic types, any, maps and slices, such as map[string]any or []string
+func genericType(expr ast.Expr) reflect.Type {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		switch e.Name {
+		case "any":
+			return reflect.TypeOf((*any)(nil)).Elem()
+		case "string":
+			return reflect.TypeOf("")
+		case "int":
+			return reflect.TypeOf(0)
+		case "float64":
+			return reflect.TypeOf(0.0)
+		case "bool":
+			return reflect.TypeOf(false)
+		}
+	case *ast.InterfaceType:
+		if e.Methods == nil || len(e.Methods.List) == 0 {
+			return reflect.TypeOf((*any)(nil)).Elem()
+		}
+	case *ast.MapType:
+		key, value := genericType(e.Key), genericType(e.Value)
+		if key != nil && value != nil && key.Comparable() {
+			return reflect.MapOf(key, value)
+		}
+	case *ast.ArrayType:
+		if elem := genericType(e.Elt); elem != nil && e.Len == nil {
+			return reflect.SliceOf(elem)
+		}
+	}
+	return nil
+}
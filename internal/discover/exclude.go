@@ -0,0 +1,103 @@
+package discover
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Excludes is a set of glob patterns for files and directories to skip when
+// walking a source tree. A pattern without a slash, such as "generated_*.go",
+// matches a file or directory name at any depth. A pattern with a slash is
+// matched against the slash-separated path relative to the root of the walk,
+// where "**" matches any number of path elements, as in "testdata/**".
+type Excludes []string
+
+// Validate reports the first malformed pattern.
+func (e Excludes) Validate() error {
+	for _, pattern := range e {
+		for _, elem := range strings.Split(strings.Trim(pattern, "/"), "/") {
+			if _, err := path.Match(elem, ""); err != nil {
+				return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether rel, a path relative to the root of the walk,
+// matches any of the patterns. Malformed patterns never match.
+func (e Excludes) Match(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == "" {
+		return false
+	}
+	for _, pattern := range e {
+		pattern = strings.Trim(pattern, "/")
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if matchElems(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchElems matches path elements against pattern elements, where a "**"
+// element matches zero or more path elements
+func matchElems(pattern, elems []string) bool {
+	if len(pattern) == 0 {
+		return len(elems) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(elems); i++ {
+			if matchElems(pattern[1:], elems[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elems) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], elems[0]); !ok {
+		return false
+	}
+	return matchElems(pattern[1:], elems[1:])
+}
+
+// WalkGoFiles walks root recursively and calls fn for each Go source file,
+// skipping test files and the files and directories matched by exclude.
+func WalkGoFiles(root string, exclude Excludes, fn func(path string) error) error {
+	if err := exclude.Validate(); err != nil {
+		return err
+	}
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if exclude.Match(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Skip directories, non-Go files, and test files
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		return fn(path)
+	})
+}
@@ -0,0 +1,82 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcludes_Match(t *testing.T) {
+	tests := []struct {
+		patterns Excludes
+		rel      string
+		want     bool
+	}{
+		{Excludes{"generated_*.go"}, "generated_storage.go", true},
+		{Excludes{"generated_*.go"}, "infra/generated_storage.go", true},
+		{Excludes{"generated_*.go"}, "storage.go", false},
+		{Excludes{"testdata/**"}, "testdata", true},
+		{Excludes{"testdata/**"}, "testdata/fixtures/main.go", true},
+		{Excludes{"testdata/**"}, "infra/testdata/main.go", false},
+		{Excludes{"**/testdata"}, "infra/testdata", true},
+		{Excludes{"vendor"}, "vendor", true},
+		{Excludes{"infra/*.go"}, "infra/main.go", true},
+		{Excludes{"infra/*.go"}, "infra/network/main.go", false},
+		{Excludes{"generated_*.go", "vendor"}, "vendor", true},
+		{nil, "main.go", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.patterns.Match(tt.rel), "%v matching %s", tt.patterns, tt.rel)
+	}
+}
+
+func TestExcludes_Validate(t *testing.T) {
+	assert.NoError(t, Excludes{"generated_*.go", "testdata/**"}.Validate())
+
+	err := Excludes{"gen[*.go"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid exclude pattern "gen[*.go"`)
+}
+
+func TestDiscoverResources_Exclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	storageCode := func(name string) string {
+		return `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var ` + name + ` = storage.StorageAccount{Name: "x", Location: "eastus"}
+`
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "testdata"), 0755))
+	files := map[string]string{
+		"main.go":              storageCode("Kept"),
+		"generated_storage.go": storageCode("Generated"),
+		"main_test.go":         storageCode("FromTest"),
+		"testdata/fixture.go":  storageCode("Fixture"),
+	}
+	for name, code := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(code), 0644))
+	}
+
+	resources, err := DiscoverResources(tmpDir, "generated_*.go", "testdata/**")
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "Kept", resources[0].Name)
+
+	// Test files are skipped even without patterns
+	resources, err = DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	names := make([]string, len(resources))
+	for i, r := range resources {
+		names[i] = r.Name
+	}
+	assert.ElementsMatch(t, []string{"Kept", "Generated", "Fixture"}, names)
+
+	_, err = DiscoverResources(tmpDir, "[")
+	assert.Error(t, err)
+}
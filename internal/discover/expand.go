@@ -0,0 +1,384 @@
+package discover
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/resources/authorization"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/recoveryservices"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+// expander derives the additional ARM resources that a single declaration
+// stands for, such as the protected item of a backup-enabled VM. value is the
+// declaration's value expression, resource the discovered resource, and
+// imports the imports of its file.
+type expander func(value ast.Expr, resource DiscoveredResource, imports map[string]string) []DiscoveredResource
+
+// expanders maps Azure resource types to the expanders for their declarations
+var expanders = map[string][]expander{
+	"Microsoft.Compute/virtualMachines": {expandVMBackup},
+	"Microsoft.Storage/storageAccounts": {expandBlobServices, expandPrivateEndpoints, expandDeleteLock},
+}
+
+// expandResource returns the resources derived from the declaration of
+// resource. Resources in copy loops are not expanded.
+func expandResource(value ast.Expr, resource DiscoveredResource, imports map[string]string) []DiscoveredResource {
+	if value == nil || resource.Copy != nil {
+		return nil
+	}
+	var expanded []DiscoveredResource
+	for _, expand := range expanders[resource.Type] {
+		expanded = append(expanded, expand(value, resource, imports)...)
+	}
+	return expanded
+}
+
+// expandVMBackup emits the Recovery Services protected item for a VM declared
+// with EnableBackup("vault", "policy") or a Backup field literal. The item is
+// named after the VM variable with a "Backup" suffix and depends on the VM.
+func expandVMBackup(value ast.Expr, vm DiscoveredResource, _ map[string]string) []DiscoveredResource {
+	vaultName, policyName := vmBackupSettings(value)
+	if vaultName == "" || policyName == "" {
+		return nil
+	}
+
+	item := recoveryservices.NewVMProtectedItem(vaultName, policyName, vm.TemplateName())
+	return []DiscoveredResource{{
+		Name:         vm.Name + "Backup",
+		Type:         item.Type,
+		File:         vm.File,
+		Line:         vm.Line,
+		Dependencies: []string{vm.Name},
+		APIVersion:   item.APIVersion,
+		ARMName:      item.Name,
+		Properties: map[string]any{
+			"protectedItemType": item.Properties.ProtectedItemType,
+			"policyId":          item.Properties.PolicyID,
+			"sourceResourceId":  item.Properties.SourceResourceID,
+		},
+	}}
+}
+
+// vmBackupSettings returns the vault and policy names given to EnableBackup in
+// a method chain, or set in the Backup field of the VM literal.
+func vmBackupSettings(expr ast.Expr) (vaultName, policyName string) {
+	for _, call := range methodCalls(expr) {
+		if call.name == "EnableBackup" && len(call.args) == 2 {
+			return stringLiteral(call.args[0]), stringLiteral(call.args[1])
+		}
+	}
+
+	compLit, ok := unwrapLiteral(expr).(*ast.CompositeLit)
+	if !ok {
+		return "", ""
+	}
+	for _, elt := range compLit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Backup" {
+			continue
+		}
+		backup := kv.Value
+		if unary, ok := backup.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			backup = unary.X
+		}
+		return extractStringField(backup, "VaultName"), extractStringField(backup, "PolicyName")
+	}
+	return "", ""
+}
+
+// expandBlobServices emits the default blob service of a storage account
+// declared with WithBlobDataProtection(days) or a literal BlobServices field
+// in its properties. The service is named after the account variable with a
+// "BlobServices" suffix and depends on the account.
+func expandBlobServices(value ast.Expr, account DiscoveredResource, _ map[string]string) []DiscoveredResource {
+	settings := blobServiceSettings(value)
+	if settings == nil {
+		return nil
+	}
+
+	properties := armProperties(settings)
+	if properties == nil {
+		return nil
+	}
+
+	return []DiscoveredResource{{
+		Name:         account.Name + "BlobServices",
+		Type:         "Microsoft.Storage/storageAccounts/blobServices",
+		File:         account.File,
+		Line:         account.Line,
+		Dependencies: []string{account.Name},
+		APIVersion:   "2023-01-01",
+		ARMName:      account.TemplateName() + "/default",
+		Properties:   properties,
+	}}
+}
+
+// blobServiceSettings returns the blob service properties set with
+// WithBlobDataProtection in a method chain, or in the BlobServices field of
+// the account's properties literal. It returns nil if there are none, or if
+// they are not made only of literals.
+func blobServiceSettings(expr ast.Expr) *storage.BlobServiceProperties {
+	for _, call := range methodCalls(expr) {
+		if call.name != "WithBlobDataProtection" || len(call.args) != 1 {
+			continue
+		}
+		lit, ok := call.args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return nil
+		}
+		days, err := strconv.Atoi(lit.Value)
+		if err != nil {
+			return nil
+		}
+		account := &storage.StorageAccount{}
+		return account.WithBlobDataProtection(days).Properties.BlobServices
+	}
+
+	compLit, ok := unwrapLiteral(expr).(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	props, ok := unwrapLiteral(fieldValue(compLit, "Properties")).(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	blobServices := fieldValue(props, "BlobServices")
+	if blobServices == nil {
+		return nil
+	}
+	v, unsupported := evaluateLiteral(reflect.TypeOf(&storage.BlobServiceProperties{}), blobServices, nil)
+	if unsupported != nil {
+		return nil
+	}
+	return v.Interface().(*storage.BlobServiceProperties)
+}
+
+// expandPrivateEndpoints emits a private endpoint for each
+// WithPrivateEndpoint(subnetID, groupID) call on a storage account, or each
+// entry of a literal PrivateEndpoints field in its properties. An endpoint is
+// named after the account and its group, e.g. mystorage-blob-pe, with the
+// variable MyStorageBlobPrivateEndpoint, and depends on the account.
+func expandPrivateEndpoints(value ast.Expr, account DiscoveredResource, imports map[string]string) []DiscoveredResource {
+	var endpoints []DiscoveredResource
+	for _, settings := range privateEndpointSettings(value, imports) {
+		if settings.SubnetID == "" || settings.GroupID == "" {
+			continue
+		}
+
+		endpoint := network.NewPrivateEndpoint(account.TemplateName()+"-"+settings.GroupID+"-pe", "",
+			settings.SubnetID, account.ResourceID(), settings.GroupID)
+		properties := armProperties(endpoint.Properties)
+		if properties == nil {
+			continue
+		}
+
+		endpoints = append(endpoints, DiscoveredResource{
+			Name:         account.Name + groupName(settings.GroupID) + "PrivateEndpoint",
+			Type:         endpoint.Type,
+			File:         account.File,
+			Line:         account.Line,
+			Dependencies: []string{account.Name},
+			APIVersion:   endpoint.APIVersion,
+			ARMName:      endpoint.Name,
+			Properties:   properties,
+		})
+	}
+	return endpoints
+}
+
+// privateEndpointSettings returns the private endpoints added with
+// WithPrivateEndpoint in a method chain, or set in the PrivateEndpoints field
+// of the account's properties literal. The subnet may be given as an
+// intrinsics.ResourceRef call; calls whose arguments are otherwise not string
+// literals are skipped.
+func privateEndpointSettings(expr ast.Expr, imports map[string]string) []storage.PrivateEndpointSettings {
+	var settings []storage.PrivateEndpointSettings
+	for _, call := range methodCalls(expr) {
+		if call.name == "WithPrivateEndpoint" && len(call.args) == 2 {
+			settings = append(settings, storage.PrivateEndpointSettings{
+				SubnetID: stringOrResourceRef(call.args[0], imports),
+				GroupID:  stringLiteral(call.args[1]),
+			})
+		}
+	}
+
+	compLit, ok := unwrapLiteral(expr).(*ast.CompositeLit)
+	if !ok {
+		return settings
+	}
+	props, ok := unwrapLiteral(fieldValue(compLit, "Properties")).(*ast.CompositeLit)
+	if !ok {
+		return settings
+	}
+	endpoints := fieldValue(props, "PrivateEndpoints")
+	if endpoints == nil {
+		return settings
+	}
+	v, unsupported := evaluateLiteral(reflect.TypeOf([]storage.PrivateEndpointSettings{}), endpoints, imports)
+	if unsupported != nil {
+		return settings
+	}
+	return append(v.Interface().([]storage.PrivateEndpointSettings), settings...)
+}
+
+// expandDeleteLock emits a CanNotDelete management lock scoped to a storage
+// account declared with WithDeleteLock() or a literal DeleteLock: true field
+// in its properties. The lock is named after the account with a
+// "-delete-lock" suffix, e.g. logs-delete-lock, with the variable named
+// after the account variable with a "DeleteLock" suffix, and depends on the
+// account.
+func expandDeleteLock(value ast.Expr, account DiscoveredResource, _ map[string]string) []DiscoveredResource {
+	if !deleteLockSet(value) {
+		return nil
+	}
+
+	lock := authorization.NewManagementLock(account.TemplateName()+"-delete-lock", account.ResourceID(), authorization.LockLevelCanNotDelete).
+		WithNotes("Protects the storage account from deletion")
+	properties := armProperties(lock.Properties)
+	if properties == nil {
+		return nil
+	}
+
+	return []DiscoveredResource{{
+		Name:         account.Name + "DeleteLock",
+		Type:         lock.Type,
+		File:         account.File,
+		Line:         account.Line,
+		Dependencies: []string{account.Name},
+		APIVersion:   lock.APIVersion,
+		ARMName:      lock.Name,
+		Scope:        lock.Scope,
+		Properties:   properties,
+	}}
+}
+
+// deleteLockSet reports whether WithDeleteLock is called in a method chain,
+// or the DeleteLock field of the account's properties literal is true.
+func deleteLockSet(expr ast.Expr) bool {
+	for _, call := range methodCalls(expr) {
+		if call.name == "WithDeleteLock" && len(call.args) == 0 {
+			return true
+		}
+	}
+
+	compLit, ok := unwrapLiteral(expr).(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+	props, ok := unwrapLiteral(fieldValue(compLit, "Properties")).(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+	ident, ok := fieldValue(props, "DeleteLock").(*ast.Ident)
+	return ok && ident.Name == "true"
+}
+
+// stringOrResourceRef returns the value of a string literal or the reference
+// made by an intrinsics.ResourceRef call, or "" for anything else
+func stringOrResourceRef(expr ast.Expr, imports map[string]string) string {
+	if ref := resourceRefValue(expr, imports); ref != "" {
+		return ref
+	}
+	return stringLiteral(expr)
+}
+
+// groupName returns a private endpoint group ID such as blob or
+// blob_secondary in the form used in resource names (Blob, BlobSecondary)
+func groupName(groupID string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(groupID, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+// armProperties converts v to a map of ARM properties, round-tripping
+// through JSON so the properties use the ARM field names. It returns nil if v
+// cannot be converted.
+func armProperties(v any) map[string]any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var properties map[string]any
+	if err := json.Unmarshal(data, &properties); err != nil {
+		return nil
+	}
+	return properties
+}
+
+// fieldValue returns the value of the keyed field name in lit, or nil
+func fieldValue(lit *ast.CompositeLit, name string) ast.Expr {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == name {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// methodCall is a method called on a resource literal, e.g. EnableBackup in
+// (&compute.VirtualMachine{...}).EnableBackup("vault", "DefaultPolicy")
+type methodCall struct {
+	name string
+	args []ast.Expr
+}
+
+// methodCalls returns the method calls chained on expr, innermost first
+func methodCalls(expr ast.Expr) []methodCall {
+	var calls []methodCall
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			break
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		calls = append([]methodCall{{name: sel.Sel.Name, args: call.Args}}, calls...)
+		expr = sel.X
+	}
+	return calls
+}
+
+// unwrapLiteral returns the composite literal at the root of a method chain
+// such as (&compute.VirtualMachine{...}).EnableBackup(...), or expr itself
+// if it is not a call on a literal.
+func unwrapLiteral(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.CallExpr:
+			sel, ok := e.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return expr
+			}
+			expr = sel.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.UnaryExpr:
+			if e.Op != token.AND {
+				return expr
+			}
+			expr = e.X
+		default:
+			return expr
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package discover
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/naming"
+)
+
+// NamingImportPath is the import path of the naming package
+const NamingImportPath = "github.com/lex00/wetwire-azure-go/naming"
+
+// isNamingPackage reports whether importPath is the naming package
+func isNamingPackage(importPath string) bool {
+	return importPath == NamingImportPath || strings.HasSuffix(importPath, "/wetwire-azure-go/naming")
+}
+
+// uniqueNameValue returns the ARM expression of a naming.Unique call chain
+// with literal arguments, such as naming.Unique("logs").For("Microsoft.Storage/storageAccounts").String(),
+// or "" if expr is something else
+func uniqueNameValue(expr ast.Expr, imports map[string]string) string {
+	// Collect the methods chained on naming.Unique, outermost first
+	var chain []methodCall
+	for {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return ""
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return ""
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && isNamingPackage(imports[pkg.Name]) {
+			if sel.Sel.Name != "Unique" || len(call.Args) != 1 {
+				return ""
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return ""
+			}
+			prefix, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return ""
+			}
+			return applyNamingChain(naming.Unique(prefix), chain)
+		}
+		chain = append(chain, methodCall{name: sel.Sel.Name, args: call.Args})
+		expr = sel.X
+	}
+}
+
+// applyNamingChain applies the methods of chain, outermost first, to name
+// and returns its ARM expression, or "" if a method is unknown or has
+// arguments that are not literals
+func applyNamingChain(name naming.UniqueName, chain []methodCall) string {
+	for i := len(chain) - 1; i >= 0; i-- {
+		call := chain[i]
+		switch {
+		case call.name == "For" && len(call.args) == 1:
+			resourceType := stringLiteral(call.args[0])
+			if resourceType == "" {
+				return ""
+			}
+			name = name.For(resourceType)
+		case call.name == "WithMaxLength" && len(call.args) == 1:
+			lit, ok := call.args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.INT {
+				return ""
+			}
+			maxLength, err := strconv.Atoi(lit.Value)
+			if err != nil {
+				return ""
+			}
+			name = name.WithMaxLength(maxLength)
+		case (call.name == "String" || call.name == "ARMExpression") && len(call.args) == 0 && i == 0:
+		default:
+			return ""
+		}
+	}
+	return name.ARMExpression()
+}
@@ -0,0 +1,39 @@
+package discover
+
+import (
+	"go/ast"
+	"reflect"
+
+	"github.com/lex00/wetwire-azure-go/template"
+	coreast "github.com/lex00/wetwire-core-go/ast"
+)
+
+// TemplateImportPath is the import path of the package of template.RawResource
+const TemplateImportPath = "github.com/lex00/wetwire-azure-go/template"
+
+// isRawResource reports whether typeExpr is template.RawResource, whose
+// declarations give their Azure resource type in their Type field rather
+// than through the registry
+func isRawResource(typeExpr ast.Expr, imports map[string]string) bool {
+	if typeExpr == nil {
+		return false
+	}
+	typeName, pkgAlias := coreast.ExtractTypeName(typeExpr)
+	return typeName == "RawResource" && imports[pkgAlias] == TemplateImportPath
+}
+
+// evaluateRawResource returns the RawResource declared with the literal
+// expr, or nil if expr is not a composite literal. Properties and the other
+// objects may hold intrinsics.ResourceRef calls and intrinsics values; it
+// returns the first part of expr that is anything else but a literal.
+func evaluateRawResource(expr ast.Expr, imports map[string]string) (*template.RawResource, ast.Expr) {
+	if _, ok := expr.(*ast.CompositeLit); !ok {
+		return nil, nil
+	}
+	v, unsupported := evaluateLiteral(reflect.TypeOf(template.RawResource{}), expr, imports)
+	if unsupported != nil {
+		return nil, unsupported
+	}
+	raw := v.Interface().(template.RawResource)
+	return &raw, nil
+}
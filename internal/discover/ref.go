@@ -0,0 +1,235 @@
+package discover
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+)
+
+// resourceRefValue returns the reference made by an intrinsics.ResourceRef
+// call with string literal arguments, or "" if expr is something else
+func resourceRefValue(expr ast.Expr, imports map[string]string) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return ""
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "ResourceRef" {
+		return ""
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok || !isIntrinsicsPackage(imports[pkg.Name]) {
+		return ""
+	}
+
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		if args[i] = stringLiteral(arg); args[i] == "" {
+			return ""
+		}
+	}
+	return intrinsics.ResourceRef(args[0], args[1:]...)
+}
+
+// resourceRefs returns the variables that expr references with
+// intrinsics.ResourceRef calls
+func resourceRefs(expr ast.Expr, imports map[string]string) []string {
+	var refs []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if ref := resourceRefValue(call, imports); ref != "" {
+			name, _, _ := intrinsics.ParseResourceRef(ref)
+			refs = append(refs, name)
+			return false
+		}
+		return true
+	})
+	return refs
+}
+
+// appendDependencies appends the names missing from deps to a copy of deps
+func appendDependencies(deps []string, names ...string) []string {
+	result := append([]string(nil), deps...)
+	for _, name := range names {
+		found := false
+		for _, dep := range result {
+			if dep == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// linkResourceRefs makes each resource depend on the resources named by the
+// intrinsics.ResourceRef markers in its scope and properties, including those
+// of template.RawResource declarations, and checks that they name discovered
+// resources. The markers are left in place: the names they resolve to are
+// only final once the builder has named child resources under their parents,
+// and it then calls ResolveResourceRefs. Typed resources have no properties
+// here, since build does not write them; a reference in them only adds the
+// dependency.
+func linkResourceRefs(resources []DiscoveredResource) error {
+	byName := make(map[string]DiscoveredResource, len(resources))
+	for _, res := range resources {
+		byName[res.Name] = res
+	}
+
+	for i, res := range resources {
+		var refs []string
+		if _, err := res.resolveResourceRefs(byName, &refs); err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			resources[i].Dependencies = appendDependencies(res.Dependencies, refs...)
+		}
+	}
+	return nil
+}
+
+// ResolveResourceRefs returns the resource with the intrinsics.ResourceRef
+// markers in its scope and properties, including those of a
+// template.RawResource declaration, replaced with resourceId() expressions for
+// the resources of byName they name, with their names in generated
+// templates. The properties are copied rather than changed in place, since
+// cached discovery results share them.
+func (r DiscoveredResource) ResolveResourceRefs(byName map[string]DiscoveredResource) (DiscoveredResource, error) {
+	var refs []string
+	return r.resolveResourceRefs(byName, &refs)
+}
+
+// resolveResourceRefs resolves the references of r as ResolveResourceRefs
+// does, adding the names of the referenced resources to refs
+func (r DiscoveredResource) resolveResourceRefs(byName map[string]DiscoveredResource, refs *[]string) (DiscoveredResource, error) {
+	if r.Scope != "" {
+		scope, err := resolveRefValue(r.Scope, byName, refs)
+		if err != nil {
+			return r, fmt.Errorf("%s:%d: %s: scope: %w", r.File, r.Line, r.Name, err)
+		}
+		r.Scope = scope.(string)
+	}
+	if r.Properties != nil {
+		properties, err := resolveRefValue(r.Properties, byName, refs)
+		if err != nil {
+			return r, fmt.Errorf("%s:%d: %s: %w", r.File, r.Line, r.Name, err)
+		}
+		r.Properties = properties.(map[string]any)
+	}
+	if r.Raw != nil && r.Raw.Properties != nil {
+		properties, err := resolveRefValue(r.Raw.Properties, byName, refs)
+		if err != nil {
+			return r, fmt.Errorf("%s:%d: %s: %w", r.File, r.Line, r.Name, err)
+		}
+		raw := *r.Raw
+		raw.Properties = properties.(map[string]any)
+		r.Raw = &raw
+	}
+	return r, nil
+}
+
+// resolveRefValue returns v with the ResourceRef markers in it resolved,
+// adding the names of the referenced resources to refs. v is a property value
+// as decoded from JSON.
+func resolveRefValue(v any, byName map[string]DiscoveredResource, refs *[]string) (any, error) {
+	switch v := v.(type) {
+	case string:
+		name, subPath, ok := intrinsics.ParseResourceRef(v)
+		if !ok {
+			return v, nil
+		}
+		target, found := byName[name]
+		if !found {
+			return nil, fmt.Errorf("ResourceRef to unknown resource %s", name)
+		}
+		*refs = append(*refs, name)
+		return resourceIDExpression(target, subPath)
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for k, elem := range v {
+			r, err := resolveRefValue(elem, byName, refs)
+			if err != nil {
+				return nil, err
+			}
+			resolved[k] = r
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(v))
+		for i, elem := range v {
+			r, err := resolveRefValue(elem, byName, refs)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	default:
+		return v, nil
+	}
+}
+
+// TemplateName returns the name of the resource in generated templates: its
+// ARM name, or its variable name if it has none
+func (r DiscoveredResource) TemplateName() string {
+	if r.ARMName != "" {
+		return r.ARMName
+	}
+	return r.Name
+}
+
+// ResourceID returns the resourceId() expression for the resource, with its
+// name in generated templates. Extension resources with a scope, such as
+// locks, get an extensionResourceId() of their scope instead.
+func (r DiscoveredResource) ResourceID() string {
+	id, _ := resourceIDExpression(r, nil)
+	if r.Scope == "" {
+		return id
+	}
+	scope := quoteArgument(r.Scope)
+	if strings.HasPrefix(r.Scope, "[") && strings.HasSuffix(r.Scope, "]") {
+		// Nest the scope's expression rather than quoting it
+		scope = r.Scope[1 : len(r.Scope)-1]
+	}
+	return "[extensionResourceId(" + scope + ", " + strings.TrimPrefix(id, "[resourceId(")
+}
+
+// resourceIDExpression returns the resourceId() expression for target, or for
+// its child given as pairs of child type and name in subPath. The name of
+// target is its ARM name in generated templates, which for child resources
+// has a segment per level (e.g. "vnet/app").
+func resourceIDExpression(target DiscoveredResource, subPath []string) (string, error) {
+	if len(subPath)%2 != 0 {
+		return "", fmt.Errorf("ResourceRef to %s: sub-path %q is not pairs of child type and name", target.Name, strings.Join(subPath, "/"))
+	}
+
+	resourceType := target.Type
+	var args []string
+	if name := target.TemplateName(); strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]") {
+		// A name given as an expression, such as naming.Unique, is nested
+		args = append(args, name[1:len(name)-1])
+	} else {
+		for _, segment := range strings.Split(name, "/") {
+			args = append(args, quoteArgument(segment))
+		}
+	}
+	for i := 0; i < len(subPath); i += 2 {
+		resourceType += "/" + subPath[i]
+		args = append(args, quoteArgument(subPath[i+1]))
+	}
+
+	return "[resourceId('" + resourceType + "', " + strings.Join(args, ", ") + ")]", nil
+}
+
+// quoteArgument returns s as a string literal argument of an ARM function
+func quoteArgument(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
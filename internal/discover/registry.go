@@ -0,0 +1,65 @@
+package discover
+
+import (
+	"reflect"
+	"strings"
+
+	// The built-in resource packages register their structs from init
+	_ "github.com/lex00/wetwire-azure-go/resources/aks"
+	_ "github.com/lex00/wetwire-azure-go/resources/app"
+	_ "github.com/lex00/wetwire-azure-go/resources/authorization"
+	_ "github.com/lex00/wetwire-azure-go/resources/cognitiveservices"
+	_ "github.com/lex00/wetwire-azure-go/resources/compute"
+	_ "github.com/lex00/wetwire-azure-go/resources/containerinstance"
+	_ "github.com/lex00/wetwire-azure-go/resources/dashboard"
+	_ "github.com/lex00/wetwire-azure-go/resources/datafactory"
+	_ "github.com/lex00/wetwire-azure-go/resources/eventgrid"
+	_ "github.com/lex00/wetwire-azure-go/resources/insights"
+	_ "github.com/lex00/wetwire-azure-go/resources/keyvault"
+	_ "github.com/lex00/wetwire-azure-go/resources/logic"
+	_ "github.com/lex00/wetwire-azure-go/resources/managedidentity"
+	_ "github.com/lex00/wetwire-azure-go/resources/maps"
+	_ "github.com/lex00/wetwire-azure-go/resources/network"
+	_ "github.com/lex00/wetwire-azure-go/resources/policy"
+	_ "github.com/lex00/wetwire-azure-go/resources/recoveryservices"
+	_ "github.com/lex00/wetwire-azure-go/resources/signalr"
+	_ "github.com/lex00/wetwire-azure-go/resources/sql"
+	_ "github.com/lex00/wetwire-azure-go/resources/storage"
+	_ "github.com/lex00/wetwire-azure-go/resources/synapse"
+	_ "github.com/lex00/wetwire-azure-go/resources/web"
+	_ "github.com/lex00/wetwire-azure-go/resources/webpubsub"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+// ResourcesImportPath is the import path prefix of the built-in resource packages
+const ResourcesImportPath = "github.com/lex00/wetwire-azure-go/resources"
+
+func init() {
+	// containerregistry has no package in this tree to register its struct
+	template.RegisterResourceType(ResourcesImportPath+"/containerregistry", "Registry", "Microsoft.ContainerRegistry/registries")
+}
+
+// LookupResourceType returns the Azure resource type registered with
+// template.RegisterResourceType for the struct typeName in the package
+// importPath. Forks and vendored copies of the built-in packages resolve to
+// the originals.
+func LookupResourceType(importPath, typeName string) (string, bool) {
+	return template.LookupResourceType(canonicalImportPath(importPath), typeName)
+}
+
+// lookupValueType returns the Go type registered with
+// template.RegisterValueType for the struct typeName in the package
+// importPath, or nil if discovery does not evaluate it.
+func lookupValueType(importPath, typeName string) reflect.Type {
+	t, _ := template.LookupValueType(canonicalImportPath(importPath), typeName)
+	return t
+}
+
+// canonicalImportPath resolves forks and vendored copies of the built-in
+// packages to the originals
+func canonicalImportPath(importPath string) string {
+	if i := strings.Index(importPath, "wetwire-azure-go/resources/"); i != -1 {
+		return ResourcesImportPath + importPath[i+len("wetwire-azure-go/resources"):]
+	}
+	return importPath
+}
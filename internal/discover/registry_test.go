@@ -0,0 +1,93 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupResourceType_Builtin(t *testing.T) {
+	azureType, ok := LookupResourceType(ResourcesImportPath+"/storage", "StorageAccount")
+	require.True(t, ok)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts", azureType)
+
+	// Forked module paths resolve to the built-in packages
+	azureType, ok = LookupResourceType("github.com/someone/wetwire-azure-go/resources/network", "VirtualNetwork")
+	require.True(t, ok)
+	assert.Equal(t, "Microsoft.Network/virtualNetworks", azureType)
+
+	// Every built-in package registers its structs from init
+	azureType, ok = LookupResourceType(ResourcesImportPath+"/sql", "Database")
+	require.True(t, ok)
+	assert.Equal(t, "Microsoft.Sql/servers/databases", azureType)
+	azureType, ok = LookupResourceType(ResourcesImportPath+"/app", "ContainerApp")
+	require.True(t, ok)
+	assert.Equal(t, "Microsoft.App/containerApps", azureType)
+
+	_, ok = LookupResourceType(ResourcesImportPath+"/storage", "SKU")
+	assert.False(t, ok)
+	_, ok = LookupResourceType("example.com/storage", "StorageAccount")
+	assert.False(t, ok)
+}
+
+func TestRegisterResourceType_CustomType(t *testing.T) {
+	template.RegisterResourceType("example.com/contoso/widgets", "Widget", "Contoso.Widgets/widgets")
+
+	tmpDir := t.TempDir()
+	code := `package main
+
+import (
+	"example.com/contoso/widgets"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var widgetStorage = storage.StorageAccount{
+	Name: "widgetstorage",
+}
+
+var myWidget = widgets.Widget{
+	Name:    "my-widget",
+	Storage: widgetStorage.Name,
+}
+
+var notRegistered = widgets.Gadget{
+	Name: "my-gadget",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	widget := resources[1]
+	assert.Equal(t, "myWidget", widget.Name)
+	assert.Equal(t, "Contoso.Widgets/widgets", widget.Type)
+	assert.Equal(t, []string{"widgetStorage"}, widget.Dependencies)
+}
+
+// TestDiscoverResources_RenamedImport tests that types are matched by import path,
+// not by the name the package is imported under
+func TestDiscoverResources_RenamedImport(t *testing.T) {
+	tmpDir := t.TempDir()
+	code := `package main
+
+import st "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var aliased = st.StorageAccount{
+	Name: "aliased",
+}
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644)
+	require.NoError(t, err)
+
+	resources, err := DiscoverResources(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts", resources[0].Type)
+}
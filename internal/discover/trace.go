@@ -0,0 +1,100 @@
+package discover
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"strings"
+
+	coreast "github.com/lex00/wetwire-core-go/ast"
+)
+
+// traceFunc receives the outcome of discovery for a package-level variable
+type traceFunc func(pos token.Position, name, outcome string)
+
+// accept reports that the variable name was discovered as azureType
+func (t traceFunc) accept(pos token.Position, name, azureType string) {
+	if t != nil {
+		t(pos, name, "accepted as "+azureType)
+	}
+}
+
+// reject reports that the variable name is not a resource, and why
+func (t traceFunc) reject(pos token.Position, name, reason string) {
+	if t != nil {
+		t(pos, name, "rejected: "+reason)
+	}
+}
+
+// TraceResources writes a line to w for each package-level variable that
+// DiscoverResources consults in srcDir, saying whether it was accepted as a
+// resource or why it was rejected, such as
+//
+//	trace: main.go:12: var Logs accepted as Microsoft.Storage/storageAccounts
+//	trace: main.go:20: var settings rejected: type config.Settings is not a registered Azure resource type
+//
+// Files are traced one at a time in path order. A file that does not parse
+// is reported and skipped.
+func TraceResources(srcDir string, w io.Writer, exclude ...string) error {
+	paths, err := collectGoFiles(srcDir, exclude)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			rel = path
+		}
+		_, err = parseFileTraced(path, func(pos token.Position, name, outcome string) {
+			fmt.Fprintf(w, "trace: %s:%d: var %s %s\n", rel, pos.Line, name, outcome)
+		})
+		if err != nil {
+			fmt.Fprintf(w, "trace: %s: not discovered: %v\n", rel, err)
+		}
+	}
+	return nil
+}
+
+// rejectionReason explains why a variable declared with the type typeExpr,
+// if given, and the value expr is not a resource
+func rejectionReason(typeExpr, expr ast.Expr, imports map[string]string) string {
+	if typeExpr != nil {
+		return typeRejectionReason(typeExpr, imports)
+	}
+	switch e := expr.(type) {
+	case nil:
+		return "no value"
+	case *ast.CallExpr:
+		return fmt.Sprintf("value %s is a function call; declare resources as struct literals such as storage.StorageAccount{...}", types.ExprString(e.Fun))
+	case *ast.CompositeLit:
+		return "untyped literal"
+	default:
+		return fmt.Sprintf("value %s is not a struct literal", types.ExprString(e))
+	}
+}
+
+// typeRejectionReason explains why typeExpr is not a resource type
+func typeRejectionReason(typeExpr ast.Expr, imports map[string]string) string {
+	typeName, pkgAlias := coreast.ExtractTypeName(typeExpr)
+	typeString := types.ExprString(typeExpr)
+	switch {
+	case typeName == "":
+		return fmt.Sprintf("type %s is not a named type", typeString)
+	case pkgAlias == "":
+		return fmt.Sprintf("type %s is declared in this package, not a resources package", typeString)
+	}
+	importPath, ok := imports[pkgAlias]
+	if !ok {
+		return fmt.Sprintf("package %s of type %s is not imported", pkgAlias, typeString)
+	}
+	if isRawResource(typeExpr, imports) {
+		return fmt.Sprintf("type %s has no string literal Type field", typeString)
+	}
+	if !strings.Contains(importPath, "/wetwire-azure-go/resources/") {
+		return fmt.Sprintf("type %s is not from a wetwire-azure-go resources package (%s)", typeString, importPath)
+	}
+	return fmt.Sprintf("type %s is not a registered Azure resource type", typeString)
+}
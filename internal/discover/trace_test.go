@@ -0,0 +1,72 @@
+package discover
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceResources(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/compute"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+type Settings struct {
+	Name string
+}
+
+var Logs = storage.StorageAccount{
+	Name: "logs",
+}
+
+var VM = compute.NewVirtualMachine("vm", "eastus")
+
+var settings = Settings{Name: "test"}
+
+var count = 3
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, TraceResources(tmpDir, &out))
+
+	trace := out.String()
+	assert.Contains(t, trace, "trace: main.go:12: var Logs accepted as Microsoft.Storage/storageAccounts\n")
+	assert.Contains(t, trace, "trace: main.go:16: var VM rejected: value compute.NewVirtualMachine is a function call")
+	assert.Contains(t, trace, "trace: main.go:18: var settings rejected: type Settings is declared in this package, not a resources package\n")
+	assert.Contains(t, trace, "trace: main.go:20: var count rejected: value 3 is not a struct literal\n")
+}
+
+func TestTraceResources_UnregisteredType(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var props = storage.StorageAccountProperties{}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte(code), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, TraceResources(tmpDir, &out))
+	assert.Equal(t, "trace: main.go:5: var props rejected: type storage.StorageAccountProperties is not a registered Azure resource type\n", out.String())
+}
+
+func TestTraceResources_ParseError(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "bad.go"), []byte("package main\nvar x = \n"), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, TraceResources(tmpDir, &out))
+	assert.Contains(t, out.String(), "trace: bad.go: not discovered:")
+}
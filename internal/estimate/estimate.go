@@ -0,0 +1,76 @@
+// Package estimate assigns resources a rough relative cost tier from their
+// type and SKU, to point out expensive resources in review. The tiers are a
+// heuristic kept in a small embedded table, not billing data.
+package estimate
+
+import (
+	_ "embed"
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// Cost tiers, from cheapest to most expensive
+const (
+	Free   = "free"
+	Low    = "low"
+	Medium = "medium"
+	High   = "high"
+
+	// Unknown is the tier of resource types missing from the table
+	Unknown = "unknown"
+)
+
+// tiersJSON maps each resource type to its tier, with optional SKU rules
+// that override it. Rules are tried in order; the first whose match pattern
+// (see path.Match) matches the SKU name, ignoring case, wins.
+//
+//go:embed tiers.json
+var tiersJSON []byte
+
+// typeTier is the tier of a resource type
+type typeTier struct {
+	Tier string    `json:"tier"`
+	SKUs []skuRule `json:"skus,omitempty"`
+}
+
+// skuRule is the tier of the SKUs of a resource type matching a pattern
+type skuRule struct {
+	Match string `json:"match"`
+	Tier  string `json:"tier"`
+}
+
+// tiers is tiersJSON keyed by lowercased resource type
+var tiers = parseTiers(tiersJSON)
+
+// parseTiers decodes a tier table, panicking if it is malformed since the
+// table is embedded at build time
+func parseTiers(data []byte) map[string]typeTier {
+	var table map[string]typeTier
+	if err := json.Unmarshal(data, &table); err != nil {
+		panic("estimate: invalid tier table: " + err.Error())
+	}
+	byType := make(map[string]typeTier, len(table))
+	for resourceType, tier := range table {
+		byType[strings.ToLower(resourceType)] = tier
+	}
+	return byType
+}
+
+// Tier returns the cost tier of a resource of resourceType with the SKU (or
+// VM size) sku, which may be empty if it is not known. Resource types are
+// matched case-insensitively; types missing from the table are Unknown.
+func Tier(resourceType, sku string) string {
+	t, ok := tiers[strings.ToLower(resourceType)]
+	if !ok {
+		return Unknown
+	}
+	if sku != "" {
+		for _, rule := range t.SKUs {
+			if matched, _ := path.Match(strings.ToLower(rule.Match), strings.ToLower(sku)); matched {
+				return rule.Tier
+			}
+		}
+	}
+	return t.Tier
+}
@@ -0,0 +1,53 @@
+package estimate
+
+import (
+	"path"
+	"testing"
+)
+
+func TestTier(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType string
+		sku          string
+		want         string
+	}{
+		{"standard storage", "Microsoft.Storage/storageAccounts", "Standard_LRS", Low},
+		{"premium storage", "Microsoft.Storage/storageAccounts", "Premium_LRS", Medium},
+		{"storage without SKU", "Microsoft.Storage/storageAccounts", "", Low},
+		{"burstable VM", "Microsoft.Compute/virtualMachines", "Standard_B2s", Low},
+		{"general purpose VM", "Microsoft.Compute/virtualMachines", "Standard_D2s_v3", Medium},
+		{"GPU VM", "Microsoft.Compute/virtualMachines", "Standard_NC6s_v3", High},
+		{"SKU case ignored", "Microsoft.Compute/virtualMachines", "standard_b2s", Low},
+		{"type case ignored", "microsoft.network/virtualnetworks", "", Free},
+		{"free plan", "Microsoft.Web/serverfarms", "F1", Free},
+		{"unknown type", "Microsoft.Example/widgets", "Standard", Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tier(tt.resourceType, tt.sku); got != tt.want {
+				t.Errorf("Tier(%q, %q) = %q, want %q", tt.resourceType, tt.sku, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTierTable tests that every entry of the embedded table uses a known
+// tier and a valid SKU pattern
+func TestTierTable(t *testing.T) {
+	valid := map[string]bool{Free: true, Low: true, Medium: true, High: true}
+	for resourceType, tier := range tiers {
+		if !valid[tier.Tier] {
+			t.Errorf("%s: invalid tier %q", resourceType, tier.Tier)
+		}
+		for _, rule := range tier.SKUs {
+			if !valid[rule.Tier] {
+				t.Errorf("%s: SKU %s has invalid tier %q", resourceType, rule.Match, rule.Tier)
+			}
+			if _, err := path.Match(rule.Match, ""); err != nil {
+				t.Errorf("%s: invalid SKU pattern %q: %v", resourceType, rule.Match, err)
+			}
+		}
+	}
+}
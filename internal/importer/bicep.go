@@ -0,0 +1,1186 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BicepWarning describes a Bicep construct that ParseBicep skipped because
+// it is not supported yet.
+type BicepWarning struct {
+	Line    int
+	Message string
+
+	// Skipped is set if a whole resource was left out of the import
+	Skipped bool
+}
+
+// String returns the warning as "line N: message".
+func (w BicepWarning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// ParseBicep parses a Bicep file into the ARM template model used by
+// GenerateGoCode. It supports a subset of Bicep: param, var, resource and
+// output declarations, parameter decorators, and values built from literals,
+// objects, arrays, parameter and variable references, resource references
+// (sym, sym.id, sym.name), function calls and string interpolation, which
+// becomes an ARM format() call. Other constructs, such as operators, loops
+// and conditions, are skipped with a warning rather than failing the import.
+func ParseBicep(data []byte) (*ARMTemplate, []BicepWarning, error) {
+	tokens, err := lexBicep(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Bicep: %w", err)
+	}
+
+	p := &bicepParser{
+		tokens:  tokens,
+		params:  make(map[string]bool),
+		vars:    make(map[string]bool),
+		symbols: make(map[string]bicepSymbol),
+	}
+	p.collectDeclarations()
+
+	template := &ARMTemplate{
+		Schema:         "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		ContentVersion: "1.0.0.0",
+		Parameters:     make(map[string]interface{}),
+		Variables:      make(map[string]interface{}),
+		Outputs:        make(map[string]interface{}),
+	}
+	if err := p.parseFile(template); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Bicep: %w", err)
+	}
+	return template, p.warnings, nil
+}
+
+// bicepTokenKind classifies Bicep tokens.
+type bicepTokenKind int
+
+const (
+	bicepEOF bicepTokenKind = iota
+	bicepNewline
+	bicepIdent
+	bicepString
+	bicepNumber
+	bicepPunct
+)
+
+// bicepToken is a lexical token. For strings, text is the unescaped value.
+type bicepToken struct {
+	kind         bicepTokenKind
+	text         string
+	line         int
+	interpolated bool // string contains ${...}
+
+	// parts splits an interpolated string into literal text and expressions
+	parts []bicepStringPart
+}
+
+// bicepStringPart is a piece of an interpolated string: literal text, or the
+// source of an expression between ${ and }.
+type bicepStringPart struct {
+	text string
+	expr bool
+}
+
+// lexBicep splits Bicep source into tokens, dropping comments.
+func lexBicep(src string) ([]bicepToken, error) {
+	var tokens []bicepToken
+	line := 1
+	i := 0
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			tokens = append(tokens, bicepToken{kind: bicepNewline, line: line})
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+
+		case strings.HasPrefix(src[i:], "'''"):
+			// Multi-line strings are verbatim: no escapes or interpolation
+			end := strings.Index(src[i+3:], "'''")
+			if end == -1 {
+				return nil, fmt.Errorf("line %d: unterminated multi-line string", line)
+			}
+			text := strings.TrimPrefix(src[i+3:i+3+end], "\n")
+			tokens = append(tokens, bicepToken{kind: bicepString, text: text, line: line})
+			line += strings.Count(src[i:i+3+end], "\n")
+			i += end + 6
+
+		case c == '\'':
+			tok, n, err := lexBicepString(src[i:], line)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i += n
+
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, bicepToken{kind: bicepNumber, text: src[start:i], line: line})
+
+		case isBicepIdentStart(c):
+			start := i
+			for i < len(src) && (isBicepIdentStart(src[i]) || src[i] >= '0' && src[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, bicepToken{kind: bicepIdent, text: src[start:i], line: line})
+
+		default:
+			tokens = append(tokens, bicepToken{kind: bicepPunct, text: string(c), line: line})
+			i++
+		}
+	}
+
+	tokens = append(tokens, bicepToken{kind: bicepEOF, line: line})
+	return tokens, nil
+}
+
+// lexBicepString lexes a single-quoted string at the start of s and returns
+// the token and the number of bytes consumed.
+func lexBicepString(s string, line int) (bicepToken, int, error) {
+	var sb, part strings.Builder
+	tok := bicepToken{kind: bicepString, line: line}
+
+	i := 1 // opening quote
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '\'':
+			tok.text = sb.String()
+			if tok.interpolated && part.Len() > 0 {
+				tok.parts = append(tok.parts, bicepStringPart{text: part.String()})
+			}
+			return tok, i + 1, nil
+
+		case c == '\n':
+			return tok, 0, fmt.Errorf("line %d: unterminated string", line)
+
+		case c == '\\' && i+1 < len(s):
+			unescaped := s[i+1]
+			switch s[i+1] {
+			case 'n':
+				unescaped = '\n'
+			case 'r':
+				unescaped = '\r'
+			case 't':
+				unescaped = '\t'
+			}
+			// \\, \' and \$ escape themselves
+			sb.WriteByte(unescaped)
+			part.WriteByte(unescaped)
+			i += 2
+
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			// Keep the interpolation text, and its expression as a separate part
+			tok.interpolated = true
+			if part.Len() > 0 {
+				tok.parts = append(tok.parts, bicepStringPart{text: part.String()})
+				part.Reset()
+			}
+			start := i
+			depth := 0
+			for i < len(s) && s[i] != '\n' {
+				sb.WriteByte(s[i])
+				if s[i] == '{' {
+					depth++
+				} else if s[i] == '}' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+				i++
+			}
+			if i < len(s) && s[i] == '}' {
+				tok.parts = append(tok.parts, bicepStringPart{text: s[start+2 : i], expr: true})
+			}
+			i++
+
+		default:
+			sb.WriteByte(c)
+			part.WriteByte(c)
+			i++
+		}
+	}
+	return tok, 0, fmt.Errorf("line %d: unterminated string", line)
+}
+
+func isBicepIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// bicepSymbol is a resource declared under a symbolic name.
+type bicepSymbol struct {
+	resourceType string
+	name         string      // literal resource name, empty if not a literal
+	nameToken    *bicepToken // interpolated resource name, nil otherwise
+}
+
+// bicepValue is a parsed Bicep value: either a Go value (string, float64,
+// bool, nil, map or slice) or an ARM expression without surrounding brackets.
+type bicepValue struct {
+	value  interface{}
+	expr   string
+	isExpr bool
+}
+
+// toARM returns the value as it appears in an ARM template.
+func (v bicepValue) toARM() interface{} {
+	if v.isExpr {
+		return "[" + v.expr + "]"
+	}
+	return v.value
+}
+
+// toExpr returns the value as an ARM expression fragment, or false if it is
+// an object or array, which ARM expressions cannot contain literally.
+func (v bicepValue) toExpr() (string, bool) {
+	if v.isExpr {
+		return v.expr, true
+	}
+	switch val := v.value.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'", true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool:
+		if val {
+			return "true()", true
+		}
+		return "false()", true
+	case nil:
+		return "null()", true
+	}
+	return "", false
+}
+
+// bicepDecorator is a decorator such as @description('...').
+type bicepDecorator struct {
+	name string
+	args []bicepValue
+	line int
+}
+
+// bicepParser parses a token stream into an ARMTemplate.
+type bicepParser struct {
+	tokens   []bicepToken
+	pos      int
+	params   map[string]bool
+	vars     map[string]bool
+	symbols  map[string]bicepSymbol
+	warnings []BicepWarning
+}
+
+func (p *bicepParser) peek() bicepToken {
+	return p.tokens[p.pos]
+}
+
+func (p *bicepParser) next() bicepToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != bicepEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *bicepParser) isPunct(text string) bool {
+	tok := p.peek()
+	return tok.kind == bicepPunct && tok.text == text
+}
+
+func (p *bicepParser) expectPunct(text string) error {
+	tok := p.next()
+	if tok.kind != bicepPunct || tok.text != text {
+		return fmt.Errorf("line %d: expected %q", tok.line, text)
+	}
+	return nil
+}
+
+func (p *bicepParser) skipNewlines() {
+	for p.peek().kind == bicepNewline {
+		p.pos++
+	}
+}
+
+func (p *bicepParser) warn(line int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, BicepWarning{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// skip records a warning for a resource that is left out of the import.
+func (p *bicepParser) skip(line int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, BicepWarning{Line: line, Message: fmt.Sprintf(format, args...), Skipped: true})
+}
+
+// collectDeclarations records the names of all params, vars and resources
+// up front, since Bicep allows references before declarations.
+func (p *bicepParser) collectDeclarations() {
+	depth := 0
+	for i := 0; i < len(p.tokens)-1; i++ {
+		tok := p.tokens[i]
+		if tok.kind == bicepPunct {
+			switch tok.text {
+			case "{", "[", "(":
+				depth++
+			case "}", "]", ")":
+				depth--
+			}
+			continue
+		}
+		if depth != 0 || tok.kind != bicepIdent || p.tokens[i+1].kind != bicepIdent {
+			continue
+		}
+		if i > 0 && p.tokens[i-1].kind != bicepNewline {
+			continue
+		}
+
+		name := p.tokens[i+1].text
+		switch tok.text {
+		case "param":
+			p.params[name] = true
+		case "var":
+			p.vars[name] = true
+		case "resource":
+			if i+2 < len(p.tokens) && p.tokens[i+2].kind == bicepString {
+				resourceType := strings.SplitN(p.tokens[i+2].text, "@", 2)[0]
+				sym := bicepSymbol{resourceType: resourceType}
+				if nameTok := p.resourceNameToken(i + 3); nameTok != nil && nameTok.interpolated {
+					sym.nameToken = nameTok
+				} else if nameTok != nil {
+					sym.name = nameTok.text
+				}
+				p.symbols[name] = sym
+			}
+		}
+	}
+}
+
+// resourceNameToken finds the top-level name property of the resource body
+// starting at or after token index start, if it is a single string.
+func (p *bicepParser) resourceNameToken(start int) *bicepToken {
+	i := start
+	for i < len(p.tokens) && !(p.tokens[i].kind == bicepPunct && p.tokens[i].text == "{") {
+		if p.tokens[i].kind == bicepNewline || p.tokens[i].kind == bicepEOF {
+			return nil
+		}
+		i++
+	}
+
+	depth := 0
+	for ; i+3 < len(p.tokens); i++ {
+		tok := p.tokens[i]
+		if tok.kind == bicepPunct {
+			switch tok.text {
+			case "{", "[", "(":
+				depth++
+			case "}", "]", ")":
+				depth--
+				if depth == 0 {
+					return nil
+				}
+			}
+			continue
+		}
+		if depth == 1 && tok.kind == bicepIdent && tok.text == "name" &&
+			p.tokens[i+1].kind == bicepPunct && p.tokens[i+1].text == ":" &&
+			p.tokens[i+2].kind == bicepString &&
+			(p.tokens[i+3].kind == bicepNewline || p.tokens[i+3].kind == bicepPunct) {
+			return &p.tokens[i+2]
+		}
+	}
+	return nil
+}
+
+// parseFile parses all top-level statements into template.
+func (p *bicepParser) parseFile(template *ARMTemplate) error {
+	var decorators []bicepDecorator
+
+	for {
+		p.skipNewlines()
+		tok := p.peek()
+		if tok.kind == bicepEOF {
+			return nil
+		}
+
+		if tok.kind == bicepPunct && tok.text == "@" {
+			dec, err := p.parseDecorator()
+			if err != nil {
+				return err
+			}
+			decorators = append(decorators, dec)
+			continue
+		}
+
+		var err error
+		switch {
+		case tok.kind == bicepIdent && tok.text == "param":
+			err = p.parseParam(template, decorators)
+		case tok.kind == bicepIdent && tok.text == "var":
+			p.warnDecorators(decorators, "var")
+			err = p.parseVar(template)
+		case tok.kind == bicepIdent && tok.text == "resource":
+			p.warnDecorators(decorators, "resource")
+			err = p.parseResource(template)
+		case tok.kind == bicepIdent && tok.text == "output":
+			p.warnDecorators(decorators, "output")
+			err = p.parseOutput(template)
+		default:
+			p.warn(tok.line, "unsupported statement %q skipped", tok.text)
+			err = p.skipStatement()
+		}
+		if err != nil {
+			return err
+		}
+		decorators = nil
+	}
+}
+
+func (p *bicepParser) warnDecorators(decorators []bicepDecorator, kind string) {
+	for _, dec := range decorators {
+		p.warn(dec.line, "decorator @%s on %s is not supported", dec.name, kind)
+	}
+}
+
+// parseDecorator parses @name or @name(args); namespaced names such as
+// @sys.description keep only the last segment.
+func (p *bicepParser) parseDecorator() (bicepDecorator, error) {
+	at := p.next()
+	dec := bicepDecorator{line: at.line}
+
+	for {
+		tok := p.next()
+		if tok.kind != bicepIdent {
+			return dec, fmt.Errorf("line %d: expected decorator name", tok.line)
+		}
+		dec.name = tok.text
+		if !p.isPunct(".") {
+			break
+		}
+		p.next()
+	}
+
+	if !p.isPunct("(") {
+		return dec, nil
+	}
+	args, ok, err := p.parseArgs()
+	if err != nil {
+		return dec, err
+	}
+	if ok {
+		dec.args = args
+	}
+	return dec, nil
+}
+
+// bicepParamTypes maps Bicep parameter types to ARM parameter types.
+var bicepParamTypes = map[string]string{
+	"string": "string",
+	"int":    "int",
+	"bool":   "bool",
+	"object": "object",
+	"array":  "array",
+}
+
+// bicepParamDecorators maps parameter decorators to ARM parameter properties.
+var bicepParamDecorators = map[string]string{
+	"allowed":   "allowedValues",
+	"minValue":  "minValue",
+	"maxValue":  "maxValue",
+	"minLength": "minLength",
+	"maxLength": "maxLength",
+}
+
+// parseParam parses "param name type [= default]".
+func (p *bicepParser) parseParam(template *ARMTemplate, decorators []bicepDecorator) error {
+	keyword := p.next()
+	nameTok := p.next()
+	typeTok := p.next()
+	if nameTok.kind != bicepIdent || typeTok.kind != bicepIdent {
+		return fmt.Errorf("line %d: expected param name and type", keyword.line)
+	}
+
+	paramType, ok := bicepParamTypes[typeTok.text]
+	if !ok || p.isPunct("[") {
+		p.warn(keyword.line, "param %s has unsupported type; skipped", nameTok.text)
+		return p.skipStatement()
+	}
+
+	param := map[string]interface{}{"type": paramType}
+	if p.isPunct("=") {
+		p.next()
+		value, ok, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		if ok {
+			param["defaultValue"] = value.toARM()
+		}
+	}
+
+	for _, dec := range decorators {
+		switch dec.name {
+		case "secure":
+			if paramType != "string" && paramType != "object" {
+				p.warn(dec.line, "decorator @secure on %s param %s is not supported", paramType, nameTok.text)
+				continue
+			}
+			param["type"] = "secure" + strings.ToUpper(paramType[:1]) + paramType[1:]
+		case "description":
+			if len(dec.args) == 1 {
+				param["metadata"] = map[string]interface{}{"description": dec.args[0].toARM()}
+			}
+		default:
+			key, ok := bicepParamDecorators[dec.name]
+			if !ok || len(dec.args) != 1 {
+				p.warn(dec.line, "decorator @%s on param %s is not supported", dec.name, nameTok.text)
+				continue
+			}
+			param[key] = dec.args[0].toARM()
+		}
+	}
+
+	template.Parameters[nameTok.text] = param
+	return p.endStatement()
+}
+
+// parseVar parses "var name = value".
+func (p *bicepParser) parseVar(template *ARMTemplate) error {
+	keyword := p.next()
+	nameTok := p.next()
+	if nameTok.kind != bicepIdent || !p.isPunct("=") {
+		return fmt.Errorf("line %d: expected var name and '='", keyword.line)
+	}
+	p.next()
+
+	value, ok, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	if ok {
+		template.Variables[nameTok.text] = value.toARM()
+	}
+	return p.endStatement()
+}
+
+// parseOutput parses "output name type = value".
+func (p *bicepParser) parseOutput(template *ARMTemplate) error {
+	keyword := p.next()
+	nameTok := p.next()
+	typeTok := p.next()
+	if nameTok.kind != bicepIdent || typeTok.kind != bicepIdent || !p.isPunct("=") {
+		return fmt.Errorf("line %d: expected output name, type and '='", keyword.line)
+	}
+	p.next()
+
+	outputType, ok := bicepParamTypes[typeTok.text]
+	if !ok {
+		p.warn(keyword.line, "output %s has unsupported type; skipped", nameTok.text)
+		return p.skipStatement()
+	}
+
+	value, ok, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	if ok {
+		template.Outputs[nameTok.text] = map[string]interface{}{
+			"type":  outputType,
+			"value": value.toARM(),
+		}
+	}
+	return p.endStatement()
+}
+
+// parseResource parses "resource sym 'type@version' = { ... }".
+func (p *bicepParser) parseResource(template *ARMTemplate) error {
+	keyword := p.next()
+	symTok := p.next()
+	typeTok := p.next()
+	if symTok.kind != bicepIdent || typeTok.kind != bicepString {
+		return fmt.Errorf("line %d: expected resource symbol and type", keyword.line)
+	}
+
+	if p.peek().kind == bicepIdent && p.peek().text == "existing" {
+		p.warn(keyword.line, "existing resource %s is not imported", symTok.text)
+		return p.skipStatement()
+	}
+
+	resourceType, apiVersion, found := strings.Cut(typeTok.text, "@")
+	if !found {
+		p.skip(keyword.line, "resource %s has no API version; skipped", symTok.text)
+		return p.skipStatement()
+	}
+
+	if err := p.expectPunct("="); err != nil {
+		return err
+	}
+	if !p.isPunct("{") {
+		p.skip(keyword.line, "resource %s: conditional and loop resources are not supported; skipped", symTok.text)
+		return p.skipStatement()
+	}
+
+	body, err := p.parseObject()
+	if err != nil {
+		return err
+	}
+
+	res := ARMResource{Type: resourceType, APIVersion: apiVersion, Symbol: symTok.text}
+	p.applyResourceBody(&res, body, keyword.line)
+	if res.Name == "" {
+		p.skip(keyword.line, "resource %s has no supported name; skipped", symTok.text)
+		return p.endStatement()
+	}
+
+	template.Resources = append(template.Resources, res)
+	return p.endStatement()
+}
+
+// applyResourceBody copies the top-level properties of a resource body onto res.
+func (p *bicepParser) applyResourceBody(res *ARMResource, body map[string]interface{}, line int) {
+	keys := make([]string, 0, len(body))
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := body[key]
+		var ok bool
+		switch key {
+		case "name":
+			res.Name, ok = value.(string)
+		case "location":
+			res.Location, ok = value.(string)
+		case "kind":
+			res.Kind, ok = value.(string)
+		case "sku":
+			res.SKU, ok = value.(map[string]interface{})
+		case "properties":
+			res.Properties, ok = value.(map[string]interface{})
+		case "identity":
+			res.Identity, ok = value.(map[string]interface{})
+		case "plan":
+			res.Plan, ok = value.(map[string]interface{})
+		case "tags":
+			res.Tags, ok = toStringMap(value)
+		case "dependsOn":
+			res.DependsOn, ok = toStringSlice(value)
+		case "zones":
+			res.Zones, ok = toStringSlice(value)
+		default:
+			p.warn(line, "resource property %q is not supported", key)
+			continue
+		}
+		if !ok {
+			p.warn(line, "resource property %q has an unsupported value", key)
+		}
+	}
+}
+
+func toStringMap(v interface{}) (map[string]string, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make(map[string]string, len(m))
+	for k, item := range m {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		result[k] = s
+	}
+	return result, true
+}
+
+func toStringSlice(v interface{}) ([]string, bool) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, s)
+	}
+	return result, true
+}
+
+// endStatement requires the statement to end at a newline or end of file.
+func (p *bicepParser) endStatement() error {
+	tok := p.peek()
+	if tok.kind == bicepNewline || tok.kind == bicepEOF {
+		return nil
+	}
+	p.warn(tok.line, "unexpected %q; rest of statement skipped", tok.text)
+	return p.skipStatement()
+}
+
+// skipStatement skips tokens up to the next newline outside any brackets.
+func (p *bicepParser) skipStatement() error {
+	depth := 0
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case bicepEOF:
+			if depth > 0 {
+				return fmt.Errorf("line %d: unexpected end of file", tok.line)
+			}
+			return nil
+		case bicepNewline:
+			if depth == 0 {
+				return nil
+			}
+		case bicepPunct:
+			switch tok.text {
+			case "{", "[", "(":
+				depth++
+			case "}", "]", ")":
+				depth--
+			}
+		}
+		p.next()
+	}
+}
+
+// skipExpression skips the rest of an expression, stopping before a newline,
+// comma or closing bracket outside any brackets.
+func (p *bicepParser) skipExpression() error {
+	depth := 0
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case bicepEOF:
+			if depth > 0 {
+				return fmt.Errorf("line %d: unexpected end of file", tok.line)
+			}
+			return nil
+		case bicepNewline:
+			if depth == 0 {
+				return nil
+			}
+		case bicepPunct:
+			switch tok.text {
+			case "{", "[", "(":
+				depth++
+			case "}", "]", ")":
+				if depth == 0 {
+					return nil
+				}
+				depth--
+			case ",":
+				if depth == 0 {
+					return nil
+				}
+			}
+		}
+		p.next()
+	}
+}
+
+// atExpressionEnd reports whether the next token ends an expression.
+func (p *bicepParser) atExpressionEnd() bool {
+	tok := p.peek()
+	switch tok.kind {
+	case bicepEOF, bicepNewline:
+		return true
+	case bicepPunct:
+		switch tok.text {
+		case ",", "}", "]", ")":
+			return true
+		}
+	}
+	return false
+}
+
+// parseValue parses a complete expression. It returns false, after
+// recording a warning and skipping the expression, if it is not supported.
+func (p *bicepParser) parseValue() (bicepValue, bool, error) {
+	value, ok, err := p.parsePostfix()
+	if err != nil {
+		return bicepValue{}, false, err
+	}
+	if ok && !p.atExpressionEnd() {
+		p.warn(p.peek().line, "operator %q is not supported", p.peek().text)
+		ok = false
+	}
+	if !ok {
+		if err := p.skipExpression(); err != nil {
+			return bicepValue{}, false, err
+		}
+	}
+	return value, ok, nil
+}
+
+// parsePostfix parses a primary value followed by property accesses and indexes.
+func (p *bicepParser) parsePostfix() (bicepValue, bool, error) {
+	tok := p.peek()
+
+	// Resource references: sym, sym.id and sym.name
+	if sym, isSymbol := p.symbols[tok.text]; tok.kind == bicepIdent && isSymbol && !p.params[tok.text] && !p.vars[tok.text] {
+		return p.parseSymbolReference(sym)
+	}
+
+	value, ok, err := p.parsePrimary()
+	if err != nil || !ok {
+		return value, ok, err
+	}
+
+	for {
+		switch {
+		case p.isPunct("."):
+			p.next()
+			prop := p.next()
+			if prop.kind != bicepIdent {
+				return bicepValue{}, false, fmt.Errorf("line %d: expected property name after '.'", prop.line)
+			}
+			expr, ok := value.toExpr()
+			if !ok {
+				p.warn(prop.line, "property access on an object or array literal is not supported")
+				return bicepValue{}, false, nil
+			}
+			value = bicepValue{expr: expr + "." + prop.text, isExpr: true}
+
+		case p.isPunct("["):
+			open := p.next()
+			p.skipNewlines()
+			index, ok, err := p.parseValue()
+			if err != nil || !ok {
+				return bicepValue{}, false, err
+			}
+			p.skipNewlines()
+			if err := p.expectPunct("]"); err != nil {
+				return bicepValue{}, false, err
+			}
+			expr, exprOK := value.toExpr()
+			indexExpr, indexOK := index.toExpr()
+			if !exprOK || !indexOK {
+				p.warn(open.line, "indexing an object or array literal is not supported")
+				return bicepValue{}, false, nil
+			}
+			value = bicepValue{expr: expr + "[" + indexExpr + "]", isExpr: true}
+
+		default:
+			return value, true, nil
+		}
+	}
+}
+
+// parseSymbolReference converts a reference to a declared resource.
+func (p *bicepParser) parseSymbolReference(sym bicepSymbol) (bicepValue, bool, error) {
+	tok := p.next()
+	name := bicepValue{value: sym.name}
+	switch {
+	case sym.nameToken != nil:
+		var ok bool
+		if name, ok = p.interpolate(*sym.nameToken); !ok {
+			return bicepValue{}, false, nil
+		}
+	case sym.name == "":
+		p.warn(tok.line, "reference to resource %s, whose name is not a literal, is not supported", tok.text)
+		return bicepValue{}, false, nil
+	}
+
+	nameExpr, _ := name.toExpr()
+	id := bicepValue{
+		expr:   fmt.Sprintf("resourceId('%s', %s)", sym.resourceType, nameExpr),
+		isExpr: true,
+	}
+	if !p.isPunct(".") {
+		return id, true, nil
+	}
+
+	p.next()
+	prop := p.next()
+	switch prop.text {
+	case "id":
+		return id, true, nil
+	case "name":
+		return name, true, nil
+	}
+	p.warn(prop.line, "resource property access %s.%s is not supported", tok.text, prop.text)
+	return bicepValue{}, false, nil
+}
+
+// parsePrimary parses a literal, object, array, identifier or function call.
+func (p *bicepParser) parsePrimary() (bicepValue, bool, error) {
+	tok := p.peek()
+
+	switch tok.kind {
+	case bicepString:
+		p.next()
+		if tok.interpolated {
+			value, ok := p.interpolate(tok)
+			return value, ok, nil
+		}
+		return bicepValue{value: tok.text}, true, nil
+
+	case bicepNumber:
+		p.next()
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return bicepValue{}, false, fmt.Errorf("line %d: invalid number %q", tok.line, tok.text)
+		}
+		return bicepValue{value: n}, true, nil
+
+	case bicepIdent:
+		p.next()
+		switch {
+		case tok.text == "true" || tok.text == "false":
+			return bicepValue{value: tok.text == "true"}, true, nil
+		case tok.text == "null":
+			return bicepValue{value: nil}, true, nil
+		case p.isPunct("("):
+			return p.parseCall(tok.text)
+		case p.params[tok.text]:
+			return bicepValue{expr: fmt.Sprintf("parameters('%s')", tok.text), isExpr: true}, true, nil
+		case p.vars[tok.text]:
+			return bicepValue{expr: fmt.Sprintf("variables('%s')", tok.text), isExpr: true}, true, nil
+		}
+		p.warn(tok.line, "unknown identifier %q", tok.text)
+		return bicepValue{}, false, nil
+
+	case bicepPunct:
+		switch tok.text {
+		case "{":
+			obj, err := p.parseObject()
+			return bicepValue{value: obj}, err == nil, err
+		case "[":
+			return p.parseArray()
+		case ",", "}", "]", ")":
+			return bicepValue{}, false, fmt.Errorf("line %d: unexpected %q", tok.line, tok.text)
+		case "-":
+			p.next()
+			if p.peek().kind == bicepNumber {
+				value, ok, err := p.parsePrimary()
+				if ok {
+					value.value = -value.value.(float64)
+				}
+				return value, ok, err
+			}
+		}
+		p.warn(tok.line, "unsupported expression starting with %q", tok.text)
+		return bicepValue{}, false, nil
+	}
+
+	return bicepValue{}, false, fmt.Errorf("line %d: expected a value", tok.line)
+}
+
+// interpolate converts an interpolated string to an ARM format() call, with
+// one argument per ${...} expression. It returns false, after recording a
+// warning, if an expression is not supported.
+func (p *bicepParser) interpolate(tok bicepToken) (bicepValue, bool) {
+	var format strings.Builder
+	var args []string
+	for _, part := range tok.parts {
+		if !part.expr {
+			text := strings.NewReplacer("'", "''", "{", "{{", "}", "}}").Replace(part.text)
+			format.WriteString(text)
+			continue
+		}
+
+		tokens, err := lexBicep(part.text)
+		if err != nil {
+			p.warn(tok.line, "string interpolation ${%s} is not supported", part.text)
+			return bicepValue{}, false
+		}
+		for i := range tokens {
+			tokens[i].line = tok.line
+		}
+		sub := &bicepParser{tokens: tokens, params: p.params, vars: p.vars, symbols: p.symbols}
+		value, ok, err := sub.parseValue()
+		p.warnings = append(p.warnings, sub.warnings...)
+		if err == nil && !ok {
+			return bicepValue{}, false
+		}
+		if err != nil || sub.peek().kind != bicepEOF {
+			p.warn(tok.line, "string interpolation ${%s} is not supported", part.text)
+			return bicepValue{}, false
+		}
+		expr, ok := value.toExpr()
+		if !ok {
+			p.warn(tok.line, "object or array in string interpolation ${%s} is not supported", part.text)
+			return bicepValue{}, false
+		}
+
+		fmt.Fprintf(&format, "{%d}", len(args))
+		args = append(args, expr)
+	}
+	return bicepValue{
+		expr:   fmt.Sprintf("format('%s', %s)", format.String(), strings.Join(args, ", ")),
+		isExpr: true,
+	}, true
+}
+
+// parseCall parses the arguments of a function call and returns it as an ARM expression.
+func (p *bicepParser) parseCall(name string) (bicepValue, bool, error) {
+	line := p.peek().line
+	args, ok, err := p.parseArgs()
+	if err != nil || !ok {
+		return bicepValue{}, false, err
+	}
+
+	exprs := make([]string, 0, len(args))
+	for _, arg := range args {
+		expr, ok := arg.toExpr()
+		if !ok {
+			p.warn(line, "object or array arguments to %s() are not supported", name)
+			return bicepValue{}, false, nil
+		}
+		exprs = append(exprs, expr)
+	}
+	return bicepValue{expr: name + "(" + strings.Join(exprs, ", ") + ")", isExpr: true}, true, nil
+}
+
+// parseArgs parses a parenthesized, comma-separated argument list.
+func (p *bicepParser) parseArgs() ([]bicepValue, bool, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, false, err
+	}
+
+	var args []bicepValue
+	allOK := true
+	p.skipNewlines()
+	for !p.isPunct(")") {
+		arg, ok, err := p.parseValue()
+		if err != nil {
+			return nil, false, err
+		}
+		allOK = allOK && ok
+		args = append(args, arg)
+
+		p.skipNewlines()
+		if p.isPunct(",") {
+			p.next()
+			p.skipNewlines()
+		} else if !p.isPunct(")") {
+			return nil, false, fmt.Errorf("line %d: expected ',' or ')'", p.peek().line)
+		}
+	}
+	p.next()
+	return args, allOK, nil
+}
+
+// parseObject parses an object literal. Properties with unsupported values
+// are omitted.
+func (p *bicepParser) parseObject() (map[string]interface{}, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+
+	obj := make(map[string]interface{})
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.next()
+			return obj, nil
+		}
+
+		keyTok := p.next()
+		if keyTok.kind != bicepIdent && (keyTok.kind != bicepString || keyTok.interpolated) {
+			return nil, fmt.Errorf("line %d: expected property name", keyTok.line)
+		}
+		if !p.isPunct(":") {
+			if keyTok.kind == bicepIdent && keyTok.text == "resource" {
+				p.skip(keyTok.line, "nested resource declarations are not supported; skipped")
+			} else {
+				p.warn(keyTok.line, "unsupported %q in object skipped", keyTok.text)
+			}
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		p.next()
+
+		value, ok, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			obj[keyTok.text] = value.toARM()
+		}
+
+		if p.isPunct(",") {
+			p.next()
+		}
+	}
+}
+
+// parseArray parses an array literal. Items with unsupported values are omitted.
+func (p *bicepParser) parseArray() (bicepValue, bool, error) {
+	openPos := p.pos
+	open := p.next()
+
+	p.skipNewlines()
+	if p.peek().kind == bicepIdent && p.peek().text == "for" {
+		p.warn(open.line, "for-expressions are not supported")
+		// Skip the whole bracketed loop
+		p.pos = openPos
+		depth := 0
+		for {
+			tok := p.next()
+			if tok.kind == bicepEOF {
+				return bicepValue{}, false, fmt.Errorf("line %d: unterminated array", open.line)
+			}
+			if tok.kind == bicepPunct && (tok.text == "[" || tok.text == "{" || tok.text == "(") {
+				depth++
+			} else if tok.kind == bicepPunct && (tok.text == "]" || tok.text == "}" || tok.text == ")") {
+				depth--
+				if depth == 0 {
+					return bicepValue{}, false, nil
+				}
+			}
+		}
+	}
+
+	items := []interface{}{}
+	for {
+		p.skipNewlines()
+		if p.isPunct("]") {
+			p.next()
+			return bicepValue{value: items}, true, nil
+		}
+		if p.peek().kind == bicepEOF {
+			return bicepValue{}, false, fmt.Errorf("line %d: unterminated array", open.line)
+		}
+
+		value, ok, err := p.parseValue()
+		if err != nil {
+			return bicepValue{}, false, err
+		}
+		if ok {
+			items = append(items, value.toARM())
+		}
+
+		if p.isPunct(",") {
+			p.next()
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const vnetBicep = `// Simple virtual network
+@description('Location for all resources')
+param location string = resourceGroup().location
+
+@allowed([
+  '10.0.0.0/16'
+  '10.1.0.0/16'
+])
+param addressPrefix string = '10.0.0.0/16'
+
+var subnetName = 'default'
+
+resource vnet 'Microsoft.Network/virtualNetworks@2021-02-01' = {
+  name: 'myVNet'
+  location: location
+  tags: {
+    environment: 'dev'
+  }
+  properties: {
+    addressSpace: {
+      addressPrefixes: [
+        addressPrefix
+      ]
+    }
+    enableDdosProtection: false
+    subnets: [
+      {
+        name: subnetName
+        properties: {
+          addressPrefix: '10.0.0.0/24'
+        }
+      }
+    ]
+  }
+}
+
+output vnetId string = vnet.id
+`
+
+func TestParseBicep_VirtualNetwork(t *testing.T) {
+	template, warnings, err := ParseBicep([]byte(vnetBicep))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	require.Len(t, template.Resources, 1)
+	res := template.Resources[0]
+	assert.Equal(t, "Microsoft.Network/virtualNetworks", res.Type)
+	assert.Equal(t, "2021-02-01", res.APIVersion)
+	assert.Equal(t, "myVNet", res.Name)
+	assert.Equal(t, "[parameters('location')]", res.Location)
+	assert.Equal(t, map[string]string{"environment": "dev"}, res.Tags)
+
+	addressSpace := res.Properties["addressSpace"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"[parameters('addressPrefix')]"}, addressSpace["addressPrefixes"])
+	assert.Equal(t, false, res.Properties["enableDdosProtection"])
+
+	subnets := res.Properties["subnets"].([]interface{})
+	require.Len(t, subnets, 1)
+	subnet := subnets[0].(map[string]interface{})
+	assert.Equal(t, "[variables('subnetName')]", subnet["name"])
+
+	location := template.Parameters["location"].(map[string]interface{})
+	assert.Equal(t, "string", location["type"])
+	assert.Equal(t, "[resourceGroup().location]", location["defaultValue"])
+	assert.Equal(t, map[string]interface{}{"description": "Location for all resources"}, location["metadata"])
+
+	prefix := template.Parameters["addressPrefix"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"10.0.0.0/16", "10.1.0.0/16"}, prefix["allowedValues"])
+
+	assert.Equal(t, "default", template.Variables["subnetName"])
+	assert.Equal(t, map[string]interface{}{
+		"type":  "string",
+		"value": "[resourceId('Microsoft.Network/virtualNetworks', 'myVNet')]",
+	}, template.Outputs["vnetId"])
+}
+
+func TestParseBicep_GenerateGoCode(t *testing.T) {
+	template, _, err := ParseBicep([]byte(vnetBicep))
+	require.NoError(t, err)
+
+	code, err := GenerateGoCode(template, "main")
+	require.NoError(t, err)
+	assert.Contains(t, code, `"github.com/lex00/wetwire-azure-go/resources/network"`)
+	assert.Contains(t, code, "var VNet = network.VirtualNetwork{")
+	assert.Contains(t, code, `Name:     "myVNet"`)
+}
+
+func TestParseBicep_SymbolVarName(t *testing.T) {
+	input := `param vnetName string = 'vnet1'
+
+resource appVnet 'Microsoft.Network/virtualNetworks@2021-02-01' = {
+  name: vnetName
+  location: 'eastus'
+}
+`
+	template, warnings, err := ParseBicep([]byte(input))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	require.Len(t, template.Resources, 1)
+	assert.Equal(t, "appVnet", template.Resources[0].Symbol)
+
+	code, err := GenerateGoCode(template, "main")
+	require.NoError(t, err)
+	assert.Contains(t, code, "var AppVNet = network.VirtualNetwork{")
+	assert.Contains(t, code, `Name:     "[parameters('vnetName')]"`)
+}
+
+func TestParseBicep_SkippedResources(t *testing.T) {
+	input := `param prefix string
+
+resource sa 'Microsoft.Storage/storageAccounts@2021-04-01' = {
+  name: '${prefix}store'
+}
+
+resource vnet 'Microsoft.Network/virtualNetworks@2021-02-01' = {
+  name: 'myVNet'
+  resource subnet 'subnets' = {
+    name: 'default'
+  }
+}
+`
+	template, warnings, err := ParseBicep([]byte(input))
+	require.NoError(t, err)
+	require.Len(t, template.Resources, 2)
+
+	var skipped []int
+	for _, w := range warnings {
+		if w.Skipped {
+			skipped = append(skipped, w.Line)
+		}
+	}
+	assert.Equal(t, []int{9}, skipped)
+}
+
+func TestParseBicep_Interpolation(t *testing.T) {
+	input := `param prefix string
+var env = 'dev'
+
+resource sa 'Microsoft.Storage/storageAccounts@2021-04-01' = {
+  name: '${prefix}${env}store'
+  properties: {
+    label: 'it\'s {${toUpper(prefix)}}'
+  }
+}
+
+resource lock 'Microsoft.Authorization/locks@2020-05-01' = {
+  name: '${sa.name}-lock'
+  properties: {
+    target: sa.id
+  }
+}
+`
+	template, warnings, err := ParseBicep([]byte(input))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	require.Len(t, template.Resources, 2)
+
+	sa := template.Resources[0]
+	assert.Equal(t, "[format('{0}{1}store', parameters('prefix'), variables('env'))]", sa.Name)
+	assert.Equal(t, "[format('it''s {{{0}}}', toUpper(parameters('prefix')))]", sa.Properties["label"])
+
+	lock := template.Resources[1]
+	assert.Equal(t, "[format('{0}-lock', format('{0}{1}store', parameters('prefix'), variables('env')))]", lock.Name)
+	assert.Equal(t,
+		"[resourceId('Microsoft.Storage/storageAccounts', format('{0}{1}store', parameters('prefix'), variables('env')))]",
+		lock.Properties["target"])
+}
+
+func TestParseBicep_Values(t *testing.T) {
+	input := `resource sa 'Microsoft.Storage/storageAccounts@2021-04-01' = {
+  name: 'mystorage'
+  location: 'eastus'
+  kind: 'StorageV2'
+  sku: {
+    name: 'Standard_LRS'
+  }
+  properties: {
+    minimumTlsVersion: 'TLS1_2'
+    supportsHttpsTrafficOnly: true
+    retentionDays: 7
+    offset: -1
+    escaped: 'it\'s'
+    nothing: null
+    note: '''
+multi
+line'''
+  }
+}
+`
+	template, warnings, err := ParseBicep([]byte(input))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	require.Len(t, template.Resources, 1)
+	res := template.Resources[0]
+	assert.Equal(t, "StorageV2", res.Kind)
+	assert.Equal(t, map[string]interface{}{"name": "Standard_LRS"}, res.SKU)
+	assert.Equal(t, "TLS1_2", res.Properties["minimumTlsVersion"])
+	assert.Equal(t, true, res.Properties["supportsHttpsTrafficOnly"])
+	assert.Equal(t, float64(7), res.Properties["retentionDays"])
+	assert.Equal(t, float64(-1), res.Properties["offset"])
+	assert.Equal(t, "it's", res.Properties["escaped"])
+	assert.Contains(t, res.Properties, "nothing")
+	assert.Nil(t, res.Properties["nothing"])
+	assert.Equal(t, "multi\nline", res.Properties["note"])
+}
+
+func TestParseBicep_DependsOn(t *testing.T) {
+	input := `resource nsg 'Microsoft.Network/networkSecurityGroups@2021-02-01' = {
+  name: 'myNSG'
+  location: 'eastus'
+}
+
+resource vnet 'Microsoft.Network/virtualNetworks@2021-02-01' = {
+  name: 'myVNet'
+  location: 'eastus'
+  dependsOn: [
+    nsg
+  ]
+  properties: {
+    nsgName: nsg.name
+  }
+}
+`
+	template, warnings, err := ParseBicep([]byte(input))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	require.Len(t, template.Resources, 2)
+	vnet := template.Resources[1]
+	assert.Equal(t, []string{"[resourceId('Microsoft.Network/networkSecurityGroups', 'myNSG')]"}, vnet.DependsOn)
+	assert.Equal(t, "myNSG", vnet.Properties["nsgName"])
+	assert.Equal(t, "myNSG", ExtractDependencyName(vnet.DependsOn[0]))
+}
+
+func TestParseBicep_UnsupportedConstructsWarn(t *testing.T) {
+	input := `targetScope = 'resourceGroup'
+
+param prefix string
+
+module net './net.bicep' = {
+  name: 'net'
+}
+
+resource sa 'Microsoft.Storage/storageAccounts@2021-04-01' = {
+  name: 'mystorage'
+  location: 'eastus'
+  properties: {
+    label: '${prefix + 1}-storage'
+    count: 1 + 2
+    accessTier: 'Hot'
+  }
+}
+
+resource many 'Microsoft.Storage/storageAccounts@2021-04-01' = [for i in range(0, 2): {
+  name: 'storage${i}'
+}]
+
+resource existingVnet 'Microsoft.Network/virtualNetworks@2021-02-01' existing = {
+  name: 'shared'
+}
+`
+	template, warnings, err := ParseBicep([]byte(input))
+	require.NoError(t, err)
+
+	require.Len(t, template.Resources, 1)
+	props := template.Resources[0].Properties
+	assert.Equal(t, map[string]interface{}{"accessTier": "Hot"}, props)
+
+	var lines []int
+	for _, w := range warnings {
+		lines = append(lines, w.Line)
+	}
+	assert.Equal(t, []int{1, 5, 13, 14, 19, 23}, lines)
+}
+
+func TestParseBicep_SyntaxError(t *testing.T) {
+	_, _, err := ParseBicep([]byte("param name string = 'unterminated\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1")
+
+	_, _, err = ParseBicep([]byte("resource sa 'Microsoft.Storage/storageAccounts@2021-04-01' = {\n  name: 'x'\n"))
+	require.Error(t, err)
+}
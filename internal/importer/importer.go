@@ -5,9 +5,13 @@ package importer
 import (
 	"encoding/json"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
 )
 
 // ARMTemplate represents a parsed ARM template.
@@ -34,17 +38,155 @@ type ARMResource struct {
 	Identity   map[string]interface{} `json:"identity,omitempty"`
 	Zones      []string               `json:"zones,omitempty"`
 	Plan       map[string]interface{} `json:"plan,omitempty"`
+	Comments   string                 `json:"comments,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+
+	// TagsExpression is the ARM expression given for tags instead of an
+	// object, such as "[parameters('tags')]"; Tags is nil then
+	TagsExpression string `json:"-"`
+
+	// Symbol is the symbolic name the resource was declared under in a
+	// Bicep file, used for its Go variable name; empty for ARM JSON
+	Symbol string `json:"-"`
+}
+
+// UnmarshalJSON decodes a resource, accepting tag values that are not
+// strings, such as numbers, which are kept as their JSON text, and tags given
+// as an ARM expression, which is kept in TagsExpression.
+func (r *ARMResource) UnmarshalJSON(data []byte) error {
+	type plain ARMResource
+	aux := struct {
+		*plain
+		Tags json.RawMessage `json:"tags,omitempty"`
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	r.Tags, r.TagsExpression = nil, ""
+	if len(aux.Tags) == 0 || string(aux.Tags) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(aux.Tags, &r.TagsExpression); err == nil {
+		return nil
+	}
+	var tags map[string]json.RawMessage
+	if err := json.Unmarshal(aux.Tags, &tags); err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	r.Tags = make(map[string]string, len(tags))
+	for key, value := range tags {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		r.Tags[key] = s
+	}
+	return nil
+}
+
+// Description returns the metadata.description of the resource, or ""
+func (r ARMResource) Description() string {
+	description, _ := r.Metadata["description"].(string)
+	return description
+}
+
+// ResourceError describes a problem with one entry of a template's resources array.
+type ResourceError struct {
+	Index   int    // Index of the resource in the resources array
+	Name    string // Resource name, empty if not set
+	Field   string // JSON field at fault (e.g. "type", "apiVersion")
+	Message string
+}
+
+func (e *ResourceError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("resources[%d].%s: %s", e.Index, e.Field, e.Message)
+	}
+	return fmt.Sprintf("resources[%d] (%s).%s: %s", e.Index, e.Name, e.Field, e.Message)
 }
 
+// TemplateError collects every ResourceError found in a template, so that all
+// problems can be reported at once.
+type TemplateError struct {
+	Problems []*ResourceError
+}
+
+func (e *TemplateError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("invalid ARM template: %d problem(s)", len(e.Problems)))
+	for _, p := range e.Problems {
+		lines = append(lines, "  "+p.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the individual problems for use with errors.Is and errors.As.
+func (e *TemplateError) Unwrap() []error {
+	errs := make([]error, len(e.Problems))
+	for i, p := range e.Problems {
+		errs[i] = p
+	}
+	return errs
+}
+
+// apiVersionPattern matches ARM API versions such as 2021-04-01 or 2021-04-01-preview
+var apiVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(-[A-Za-z]+)?$`)
+
 // ParseARMTemplate parses an ARM JSON template from bytes.
+// Resources with a missing or malformed type or apiVersion are reported
+// together as a *TemplateError.
 func ParseARMTemplate(data []byte) (*ARMTemplate, error) {
 	var template ARMTemplate
 	if err := json.Unmarshal(data, &template); err != nil {
 		return nil, fmt.Errorf("failed to parse ARM template: %w", err)
 	}
+	if err := validateResources(template.Resources); err != nil {
+		return nil, err
+	}
 	return &template, nil
 }
 
+// validateResources checks the type and apiVersion of each resource and
+// returns a *TemplateError listing every problem, or nil.
+func validateResources(resources []ARMResource) error {
+	var problems []*ResourceError
+	for i, res := range resources {
+		problem := func(field, format string, args ...interface{}) {
+			problems = append(problems, &ResourceError{
+				Index:   i,
+				Name:    res.Name,
+				Field:   field,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		switch {
+		case res.Type == "":
+			problem("type", "missing resource type")
+		case !isExpression(res.Type) && !strings.Contains(res.Type, "/"):
+			problem("type", "invalid resource type %q, expected <namespace>/<type>", res.Type)
+		}
+
+		switch {
+		case res.APIVersion == "":
+			problem("apiVersion", "missing apiVersion")
+		case !isExpression(res.APIVersion) && !apiVersionPattern.MatchString(res.APIVersion):
+			problem("apiVersion", "invalid apiVersion %q, expected YYYY-MM-DD[-preview]", res.APIVersion)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &TemplateError{Problems: problems}
+}
+
+// isExpression reports whether s is an ARM template expression like [parameters('x')].
+func isExpression(s string) bool {
+	return strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]")
+}
+
 // acronyms maps lowercase acronym patterns to their uppercase versions.
 var acronyms = map[string]string{
 	"api":   "API",
@@ -175,14 +317,15 @@ func singularize(s string) string {
 
 // GenerateVarName generates a valid Go variable name from a resource name.
 // Converts kebab-case and snake_case to PascalCase, with acronym handling.
+// The parts of child resource names ("vault/key") are joined.
 func GenerateVarName(name string) string {
 	if name == "" {
 		return ""
 	}
 
-	// Split by hyphen and underscore
+	// Split by hyphen, underscore and the slash of child resource names
 	parts := strings.FieldsFunc(name, func(r rune) bool {
-		return r == '-' || r == '_'
+		return r == '-' || r == '_' || r == '/'
 	})
 
 	var result strings.Builder
@@ -221,8 +364,17 @@ func ExtractDependencyName(dependsOn string) string {
 
 // GenerateImports generates the import block for the given resource types.
 func GenerateImports(resourceTypes []string) string {
+	return generateImports(resourceTypes, false)
+}
+
+// generateImports generates the import block for the given resource types,
+// and for the template package if raw is set.
+func generateImports(resourceTypes []string, raw bool) string {
 	seen := make(map[string]bool)
 	var imports []string
+	if raw {
+		imports = append(imports, fmt.Sprintf("%q", discover.TemplateImportPath))
+	}
 
 	for _, rt := range resourceTypes {
 		pkgName, _ := ResourceTypeToPackage(rt)
@@ -241,8 +393,23 @@ func GenerateImports(resourceTypes []string) string {
 	return "import (\n\t" + strings.Join(imports, "\n\t") + "\n)"
 }
 
-// GenerateGoCode generates Go source code from an ARM template.
+// GenerateOptions control how GenerateGoCodeWithOptions declares resources.
+type GenerateOptions struct {
+	// Raw declares every resource as a template.RawResource with generic
+	// maps, rather than with the typed struct of its resources package
+	Raw bool
+}
+
+// GenerateGoCode generates Go source code from an ARM template. Resources
+// of types that have no Go type in the resources packages are declared as
+// template.RawResource.
 func GenerateGoCode(template *ARMTemplate, packageName string) (string, error) {
+	return GenerateGoCodeWithOptions(template, packageName, GenerateOptions{})
+}
+
+// GenerateGoCodeWithOptions generates Go source code from an ARM template
+// with the given options.
+func GenerateGoCodeWithOptions(template *ARMTemplate, packageName string, opts GenerateOptions) (string, error) {
 	var sb strings.Builder
 
 	// Package declaration
@@ -250,12 +417,19 @@ func GenerateGoCode(template *ARMTemplate, packageName string) (string, error) {
 
 	// Collect resource types for imports
 	var resourceTypes []string
-	for _, res := range template.Resources {
+	raw := make([]bool, len(template.Resources))
+	anyRaw := false
+	for i, res := range template.Resources {
+		raw[i] = opts.Raw || !IsModeledResourceType(res.Type)
+		if raw[i] {
+			anyRaw = true
+			continue
+		}
 		resourceTypes = append(resourceTypes, res.Type)
 	}
 
 	// Generate imports
-	imports := GenerateImports(resourceTypes)
+	imports := generateImports(resourceTypes, anyRaw)
 	if imports != "" {
 		sb.WriteString(imports)
 		sb.WriteString("\n\n")
@@ -264,7 +438,7 @@ func GenerateGoCode(template *ARMTemplate, packageName string) (string, error) {
 	// Build a map of resource names for dependency resolution
 	resourceMap := make(map[string]string) // ARM name -> Go var name
 	for _, res := range template.Resources {
-		resourceMap[res.Name] = GenerateVarName(res.Name)
+		resourceMap[res.Name] = resourceVarName(res)
 	}
 
 	// Generate each resource
@@ -273,22 +447,52 @@ func GenerateGoCode(template *ARMTemplate, packageName string) (string, error) {
 			sb.WriteString("\n")
 		}
 
-		code, err := generateResourceCode(res, resourceMap)
+		code, err := generateResourceCode(res, resourceMap, raw[i])
 		if err != nil {
 			return "", fmt.Errorf("failed to generate code for resource %s: %w", res.Name, err)
 		}
 		sb.WriteString(code)
 	}
 
-	return sb.String(), nil
+	// Names that are ARM expressions, for example, do not make Go identifiers
+	code := sb.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "", code, 0); err != nil {
+		return "", fmt.Errorf("generated code does not parse: %w", err)
+	}
+	return code, nil
+}
+
+// resourceVarName returns the Go variable name for res: its Bicep symbol if
+// it has one, otherwise its name.
+func resourceVarName(res ARMResource) string {
+	if res.Symbol != "" {
+		return GenerateVarName(res.Symbol)
+	}
+	return GenerateVarName(res.Name)
+}
+
+// IsModeledResourceType reports whether resourceType has a Go type in the
+// resources packages that GenerateGoCode can declare it with.
+func IsModeledResourceType(resourceType string) bool {
+	pkgName, typeName := ResourceTypeToPackage(resourceType)
+	if pkgName == "" {
+		return false
+	}
+	azureType, ok := discover.LookupResourceType(discover.ResourcesImportPath+"/"+pkgName, typeName)
+	return ok && strings.EqualFold(azureType, resourceType)
 }
 
-// generateResourceCode generates Go code for a single ARM resource.
-func generateResourceCode(res ARMResource, resourceMap map[string]string) (string, error) {
+// generateResourceCode generates Go code for a single ARM resource, as a
+// template.RawResource if raw is set.
+func generateResourceCode(res ARMResource, resourceMap map[string]string, raw bool) (string, error) {
 	var sb strings.Builder
 
 	pkgName, typeName := ResourceTypeToPackage(res.Type)
-	varName := GenerateVarName(res.Name)
+	varName := resourceVarName(res)
+
+	// The description and comments of the resource become its doc comment
+	doc := docComment(res)
+	sb.WriteString(doc)
 
 	// Generate dependsOn comments
 	if len(res.DependsOn) > 0 {
@@ -296,12 +500,22 @@ func generateResourceCode(res ARMResource, resourceMap map[string]string) (strin
 			depName := ExtractDependencyName(dep)
 			if depName != "" {
 				if goVarName, ok := resourceMap[depName]; ok {
+					if doc != "" {
+						// Keep the dependencies out of the doc paragraphs
+						sb.WriteString("//\n")
+						doc = ""
+					}
 					sb.WriteString(fmt.Sprintf("// DependsOn: %s\n", goVarName))
 				}
 			}
 		}
 	}
 
+	if raw {
+		sb.WriteString(generateRawResourceCode(res, varName))
+		return sb.String(), nil
+	}
+
 	// Start struct declaration
 	sb.WriteString(fmt.Sprintf("var %s = %s.%s{\n", varName, pkgName, typeName))
 
@@ -320,7 +534,64 @@ func generateResourceCode(res ARMResource, resourceMap map[string]string) (strin
 		sb.WriteString(fmt.Sprintf("\tSKU: %s,\n", skuCode))
 	}
 
-	// Add tags if present
+	writeTagsCode(&sb, res)
+
+	// Add properties if present
+	if len(res.Properties) > 0 {
+		propsCode := generatePropertiesCode(res.Properties, pkgName, typeName, 1)
+		sb.WriteString(fmt.Sprintf("\tProperties: %s,\n", propsCode))
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String(), nil
+}
+
+// generateRawResourceCode generates a template.RawResource declaration of
+// res, which keeps every field of the resource in generic maps.
+func generateRawResourceCode(res ARMResource, varName string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("var %s = template.RawResource{\n", varName))
+	sb.WriteString(fmt.Sprintf("\tType:       %q,\n", res.Type))
+	sb.WriteString(fmt.Sprintf("\tAPIVersion: %q,\n", res.APIVersion))
+	sb.WriteString(fmt.Sprintf("\tName:       %q,\n", res.Name))
+	if res.Location != "" {
+		sb.WriteString(fmt.Sprintf("\tLocation:   %q,\n", res.Location))
+	}
+	if res.Kind != "" {
+		sb.WriteString(fmt.Sprintf("\tKind:       %q,\n", res.Kind))
+	}
+	if len(res.SKU) > 0 {
+		sb.WriteString(fmt.Sprintf("\tSKU: %s,\n", generateMapCode(res.SKU, 1)))
+	}
+	if len(res.Identity) > 0 {
+		sb.WriteString(fmt.Sprintf("\tIdentity: %s,\n", generateMapCode(res.Identity, 1)))
+	}
+	if len(res.Plan) > 0 {
+		sb.WriteString(fmt.Sprintf("\tPlan: %s,\n", generateMapCode(res.Plan, 1)))
+	}
+	if len(res.Zones) > 0 {
+		sb.WriteString(fmt.Sprintf("\tZones: %#v,\n", res.Zones))
+	}
+
+	writeTagsCode(&sb, res)
+
+	if len(res.Properties) > 0 {
+		sb.WriteString(fmt.Sprintf("\tProperties: %s,\n", generateMapCode(res.Properties, 1)))
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// writeTagsCode writes the Tags field of res to sb, if it has tags
+func writeTagsCode(sb *strings.Builder, res ARMResource) {
+	// An expression cannot be written as a Go map
+	if res.TagsExpression != "" {
+		sb.WriteString(fmt.Sprintf("\t// Tags: %s (ARM expression, not imported)\n", res.TagsExpression))
+	}
 	if len(res.Tags) > 0 {
 		sb.WriteString("\tTags: map[string]string{\n")
 		// Sort keys for deterministic output
@@ -334,16 +605,35 @@ func generateResourceCode(res ARMResource, resourceMap map[string]string) (strin
 		}
 		sb.WriteString("\t},\n")
 	}
+}
 
-	// Add properties if present
-	if len(res.Properties) > 0 {
-		propsCode := generatePropertiesCode(res.Properties, pkgName, typeName, 1)
-		sb.WriteString(fmt.Sprintf("\tProperties: %s,\n", propsCode))
+// docComment returns the comment lines for the metadata.description and the
+// comments of res, separated by an empty comment line, or "" if it has neither.
+// Comments that repeat the description are left out.
+func docComment(res ARMResource) string {
+	var paragraphs []string
+	if description := strings.TrimSpace(res.Description()); description != "" {
+		paragraphs = append(paragraphs, description)
+	}
+	if comments := strings.TrimSpace(res.Comments); comments != "" && (len(paragraphs) == 0 || comments != paragraphs[0]) {
+		paragraphs = append(paragraphs, comments)
 	}
 
-	sb.WriteString("}\n")
-
-	return sb.String(), nil
+	var sb strings.Builder
+	for i, paragraph := range paragraphs {
+		if i > 0 {
+			sb.WriteString("//\n")
+		}
+		for _, line := range strings.Split(paragraph, "\n") {
+			line = strings.TrimRight(line, " \t\r")
+			if line == "" {
+				sb.WriteString("//\n")
+				continue
+			}
+			sb.WriteString("// " + line + "\n")
+		}
+	}
+	return sb.String()
 }
 
 // generateStructCode generates Go struct literal code from a map.
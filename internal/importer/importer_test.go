@@ -1,6 +1,9 @@
 package importer
 
 import (
+	"errors"
+	"go/parser"
+	"go/token"
 	"strings"
 	"testing"
 
@@ -166,6 +169,7 @@ func TestGenerateVarName(t *testing.T) {
 		{"my_storage_account", "MyStorageAccount"},
 		{"my-vm-01", "MyVM01"},
 		{"MyVM", "MyVM"},
+		{"corp-kv/storage-key", "CorpKvStorageKey"},
 	}
 
 	for _, tt := range tests {
@@ -390,6 +394,88 @@ func TestGenerateGoCode_WithTags(t *testing.T) {
 	assert.Contains(t, code, `"team": "platform"`)
 }
 
+// TestGenerateGoCode_DescriptionAndTags tests that metadata.description and
+// comments become the doc comment of the variable, and tags its Tags field,
+// including tag values that are not strings
+func TestGenerateGoCode_DescriptionAndTags(t *testing.T) {
+	input := `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"type": "Microsoft.Network/virtualNetworks",
+				"apiVersion": "2021-02-01",
+				"name": "corevnet",
+				"location": "eastus"
+			},
+			{
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"name": "auditlogs",
+				"location": "eastus",
+				"comments": "Retained for seven years.\nOwned by security.",
+				"metadata": {"description": "Archive of audit logs"},
+				"tags": {"environment": "production", "costCenter": 4200},
+				"dependsOn": ["[resourceId('Microsoft.Network/virtualNetworks', 'corevnet')]"]
+			}
+		]
+	}`
+
+	template, err := ParseARMTemplate([]byte(input))
+	require.NoError(t, err)
+	res := template.Resources[1]
+	assert.Equal(t, "Archive of audit logs", res.Description())
+	assert.Equal(t, map[string]string{"environment": "production", "costCenter": "4200"}, res.Tags)
+
+	code, err := GenerateGoCode(template, "infra")
+	require.NoError(t, err)
+
+	assert.Contains(t, code, `// Archive of audit logs
+//
+// Retained for seven years.
+// Owned by security.
+//
+// DependsOn: Corevnet
+var Auditlogs = storage.StorageAccount{`)
+	assert.Contains(t, code, `"costCenter": "4200"`)
+	assert.Contains(t, code, `"environment": "production"`)
+	// A resource without a description or comments gets no doc comment
+	assert.Contains(t, code, ")\n\nvar Corevnet = network.VirtualNetwork{")
+
+	_, err = parser.ParseFile(token.NewFileSet(), "generated.go", code, parser.ParseComments)
+	assert.NoError(t, err, "generated code should parse:\n%s", code)
+}
+
+// TestParseARMTemplate_TagsExpression tests that tags given as an ARM
+// expression are kept as a comment rather than failing the import
+func TestParseARMTemplate_TagsExpression(t *testing.T) {
+	input := `{
+		"resources": [
+			{
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"name": "auditlogs",
+				"comments": "Archive of audit logs",
+				"metadata": {"description": "Archive of audit logs"},
+				"tags": "[parameters('tags')]"
+			}
+		]
+	}`
+
+	template, err := ParseARMTemplate([]byte(input))
+	require.NoError(t, err)
+	res := template.Resources[0]
+	assert.Nil(t, res.Tags)
+	assert.Equal(t, "[parameters('tags')]", res.TagsExpression)
+
+	code, err := GenerateGoCode(template, "infra")
+	require.NoError(t, err)
+	assert.Contains(t, code, "// Tags: [parameters('tags')] (ARM expression, not imported)")
+	assert.NotContains(t, code, "Tags: map[string]string")
+	// A comment repeating the description is written once
+	assert.Equal(t, 1, strings.Count(code, "Archive of audit logs"))
+}
+
 func TestParseARMTemplate_InvalidJSON(t *testing.T) {
 	input := `{invalid json}`
 
@@ -397,6 +483,71 @@ func TestParseARMTemplate_InvalidJSON(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestParseARMTemplate_InvalidResources(t *testing.T) {
+	input := `{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources": [
+			{
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"name": "goodstorage",
+				"location": "eastus"
+			},
+			{
+				"apiVersion": "2021-04-01",
+				"name": "notype",
+				"location": "eastus"
+			},
+			{
+				"type": "Microsoft.Network/virtualNetworks",
+				"apiVersion": "April 2021",
+				"name": "badversion",
+				"location": "eastus"
+			}
+		]
+	}`
+
+	_, err := ParseARMTemplate([]byte(input))
+	require.Error(t, err)
+
+	var templateErr *TemplateError
+	require.True(t, errors.As(err, &templateErr))
+	require.Len(t, templateErr.Problems, 2)
+
+	assert.Equal(t, 1, templateErr.Problems[0].Index)
+	assert.Equal(t, "type", templateErr.Problems[0].Field)
+	assert.Equal(t, "resources[1] (notype).type: missing resource type", templateErr.Problems[0].Error())
+
+	assert.Equal(t, 2, templateErr.Problems[1].Index)
+	assert.Equal(t, "apiVersion", templateErr.Problems[1].Field)
+	assert.Contains(t, templateErr.Problems[1].Error(), `invalid apiVersion "April 2021"`)
+
+	// Every problem is listed on its own line
+	assert.Equal(t, 3, strings.Count(err.Error(), "\n")+1)
+}
+
+func TestParseARMTemplate_ExpressionFields(t *testing.T) {
+	input := `{
+		"resources": [
+			{
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "[variables('storageApiVersion')]",
+				"name": "mystorage"
+			},
+			{
+				"type": "Microsoft.Web/sites",
+				"apiVersion": "2022-03-01-preview",
+				"name": "mysite"
+			}
+		]
+	}`
+
+	template, err := ParseARMTemplate([]byte(input))
+	require.NoError(t, err)
+	assert.Len(t, template.Resources, 2)
+}
+
 func TestExtractDependencyName(t *testing.T) {
 	tests := []struct {
 		dependsOn string
@@ -475,3 +626,93 @@ func TestGenerateImports(t *testing.T) {
 	count := strings.Count(imports, "storage")
 	assert.Equal(t, 1, count)
 }
+
+func TestGenerateGoCode_RawResource(t *testing.T) {
+	input := `{
+		"resources": [
+			{
+				"type": "Microsoft.Storage/storageAccounts",
+				"apiVersion": "2021-04-01",
+				"name": "logs",
+				"location": "eastus"
+			},
+			{
+				"type": "Microsoft.HealthcareApis/workspaces/fhirservices",
+				"apiVersion": "2023-11-01",
+				"name": "health/fhir",
+				"location": "eastus",
+				"kind": "fhir-R4",
+				"identity": {"type": "SystemAssigned"},
+				"tags": {"env": "prod"},
+				"properties": {
+					"authenticationConfiguration": {
+						"authority": "[concat('https://login.microsoftonline.com/', subscription().tenantId)]",
+						"smartProxyEnabled": false
+					},
+					"corsConfiguration": {"origins": ["*"], "maxAge": 1440}
+				}
+			}
+		]
+	}`
+
+	template, err := ParseARMTemplate([]byte(input))
+	require.NoError(t, err)
+
+	code, err := GenerateGoCode(template, "infra")
+	require.NoError(t, err)
+
+	// Modeled types keep their typed struct; others become RawResources
+	assert.Contains(t, code, "var Logs = storage.StorageAccount{")
+	assert.Contains(t, code, "var HealthFhir = template.RawResource{")
+	assert.Contains(t, code, `"github.com/lex00/wetwire-azure-go/resources/storage"`)
+	assert.Contains(t, code, `"github.com/lex00/wetwire-azure-go/template"`)
+	assert.NotContains(t, code, "healthcareapis")
+	assert.Contains(t, code, `Type:       "Microsoft.HealthcareApis/workspaces/fhirservices",`)
+	assert.Contains(t, code, `APIVersion: "2023-11-01",`)
+	assert.Contains(t, code, `Kind:       "fhir-R4",`)
+	assert.Contains(t, code, `"smartProxyEnabled": false,`)
+	assert.Contains(t, code, `"maxAge": 1440,`)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "infra.go", code, 0)
+	require.NoError(t, err, code)
+}
+
+func TestGenerateGoCodeWithOptions_Raw(t *testing.T) {
+	input := `{
+		"resources": [
+			{"type": "Microsoft.Storage/storageAccounts", "apiVersion": "2021-04-01", "name": "logs", "sku": {"name": "Standard_LRS"}}
+		]
+	}`
+
+	template, err := ParseARMTemplate([]byte(input))
+	require.NoError(t, err)
+
+	code, err := GenerateGoCodeWithOptions(template, "infra", GenerateOptions{Raw: true})
+	require.NoError(t, err)
+
+	assert.Contains(t, code, "var Logs = template.RawResource{")
+	assert.Contains(t, code, `"name": "Standard_LRS",`)
+	assert.NotContains(t, code, "resources/storage")
+}
+
+func TestGenerateGoCode_UnparsableCode(t *testing.T) {
+	input := `{
+		"resources": [
+			{"type": "Microsoft.Storage/storageAccounts", "apiVersion": "2021-04-01", "name": "[parameters('storageName')]"}
+		]
+	}`
+
+	template, err := ParseARMTemplate([]byte(input))
+	require.NoError(t, err)
+
+	_, err = GenerateGoCode(template, "infra")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not parse")
+}
+
+func TestIsModeledResourceType(t *testing.T) {
+	assert.True(t, IsModeledResourceType("Microsoft.Storage/storageAccounts"))
+	assert.True(t, IsModeledResourceType("Microsoft.KeyVault/vaults/keys"))
+	assert.False(t, IsModeledResourceType("Microsoft.HealthcareApis/workspaces"))
+	assert.False(t, IsModeledResourceType("invalid"))
+}
@@ -0,0 +1,379 @@
+package importer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MergeConflict is an imported resource that the existing file already
+// declares with a different body, or whose variable name is already taken by
+// another resource.
+type MergeConflict struct {
+	// Var is the Go variable name of the imported resource
+	Var string
+	// Type is the Go type of the resource, e.g. storage.StorageAccount
+	Type string
+	// Name is the resource name
+	Name string
+	// Line is the line of the existing declaration
+	Line int
+	// Reason describes the conflict
+	Reason string
+}
+
+// Error implements the error interface.
+func (c MergeConflict) Error() string {
+	return fmt.Sprintf("%s %q (%s): %s", c.Type, c.Name, c.Var, c.Reason)
+}
+
+// MergeResult is the outcome of MergeGoCode.
+type MergeResult struct {
+	// Source is the merged file. It is only set when there are no conflicts.
+	Source []byte
+	// Added lists the variables appended to the file
+	Added []string
+	// Unchanged lists the imported resources already declared identically
+	Unchanged []string
+	// Conflicts lists the imported resources that could not be merged
+	Conflicts []MergeConflict
+}
+
+// declaredResource is a package-level resource variable found in a Go file
+type declaredResource struct {
+	varName string
+	// typeKey is the import path and type name, so that files using
+	// different import names for a package still compare equal
+	typeKey  string
+	typeName string
+	pkgIdent string
+	name     string
+	value    *ast.CompositeLit
+	decl     *ast.GenDecl
+	line     int
+}
+
+// MergeGoCode appends the resources declared in generated (the output of
+// GenerateGoCode) to the existing Go source. Resources are matched by type
+// and name: one already declared with the same body is left alone, and one
+// declared with a different body is reported as a conflict. The existing
+// package clause, imports, and declarations are kept; imports needed by the
+// new resources are added.
+func MergeGoCode(existing []byte, generated string) (*MergeResult, error) {
+	fset := token.NewFileSet()
+	existingFile, err := parser.ParseFile(fset, "existing.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse existing file: %w", err)
+	}
+	generatedFile, err := parser.ParseFile(fset, "generated.go", generated, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse generated code: %w", err)
+	}
+
+	current := collectResources(fset, existingFile)
+	byKey := make(map[string]declaredResource, len(current))
+	byVar := make(map[string]declaredResource, len(current))
+	for _, res := range current {
+		byKey[res.typeKey+"/"+res.name] = res
+		byVar[res.varName] = res
+	}
+	// Other package-level names, which new variables must not shadow
+	declared := packageNames(existingFile)
+
+	result := &MergeResult{}
+	var added []declaredResource
+	for _, res := range collectResources(fset, generatedFile) {
+		if prev, ok := byKey[res.typeKey+"/"+res.name]; ok {
+			if equalAST(reflect.ValueOf(prev.value.Elts), reflect.ValueOf(res.value.Elts)) {
+				result.Unchanged = append(result.Unchanged, prev.varName)
+				continue
+			}
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				Var:    prev.varName,
+				Type:   res.pkgIdent + "." + res.typeName,
+				Name:   res.name,
+				Line:   prev.line,
+				Reason: "already declared with a different body",
+			})
+			continue
+		}
+		if prev, ok := byVar[res.varName]; ok {
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				Var:    res.varName,
+				Type:   res.pkgIdent + "." + res.typeName,
+				Name:   res.name,
+				Line:   prev.line,
+				Reason: fmt.Sprintf("variable name already used by %q", prev.name),
+			})
+			continue
+		}
+		if declared[res.varName] {
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				Var:    res.varName,
+				Type:   res.pkgIdent + "." + res.typeName,
+				Name:   res.name,
+				Reason: "variable name already declared",
+			})
+			continue
+		}
+		added = append(added, res)
+		result.Added = append(result.Added, res.varName)
+	}
+	if len(result.Conflicts) > 0 || len(added) == 0 {
+		if len(result.Conflicts) == 0 {
+			result.Source = existing
+		}
+		return result, nil
+	}
+
+	newImports, err := missingImports(existingFile, generatedFile, added)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(insertImports(fset, existingFile, existing, newImports))
+	if !strings.HasSuffix(sb.String(), "\n") {
+		sb.WriteString("\n")
+	}
+	for _, res := range added {
+		start := res.decl.Pos()
+		if res.decl.Doc != nil {
+			start = res.decl.Doc.Pos()
+		}
+		sb.WriteString("\n")
+		sb.WriteString(generated[fset.Position(start).Offset:fset.Position(res.decl.End()).Offset])
+		sb.WriteString("\n")
+	}
+
+	source, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format merged file: %w", err)
+	}
+	result.Source = source
+	return result, nil
+}
+
+// collectResources returns the package-level variables in file that are
+// initialized with a composite literal of an imported type.
+func collectResources(fset *token.FileSet, file *ast.File) []declaredResource {
+	imports := importNames(file)
+
+	var resources []declaredResource
+	for _, d := range file.Decls {
+		decl, ok := d.(*ast.GenDecl)
+		if !ok || decl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) != 1 || len(vs.Values) != 1 {
+				continue
+			}
+			value := vs.Values[0]
+			if u, ok := value.(*ast.UnaryExpr); ok && u.Op == token.AND {
+				value = u.X
+			}
+			lit, ok := value.(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			sel, ok := lit.Type.(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+			pkg, ok := sel.X.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			importPath, ok := imports[pkg.Name]
+			if !ok {
+				continue
+			}
+
+			name := vs.Names[0].Name
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Name" {
+					if bl, ok := kv.Value.(*ast.BasicLit); ok && bl.Kind == token.STRING {
+						if s, err := strconv.Unquote(bl.Value); err == nil {
+							name = s
+						}
+					}
+				}
+			}
+
+			resources = append(resources, declaredResource{
+				varName:  vs.Names[0].Name,
+				typeKey:  importPath + "." + sel.Sel.Name,
+				typeName: sel.Sel.Name,
+				pkgIdent: pkg.Name,
+				name:     name,
+				value:    lit,
+				decl:     decl,
+				line:     fset.Position(vs.Pos()).Line,
+			})
+		}
+	}
+	return resources
+}
+
+// packageNames returns the names declared at package level in file
+func packageNames(file *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, d := range file.Decls {
+		switch decl := d.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				names[decl.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					for _, n := range spec.Names {
+						names[n.Name] = true
+					}
+				case *ast.TypeSpec:
+					names[spec.Name.Name] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+// importNames maps the names a file uses for its imports to their paths
+func importNames(file *ast.File) map[string]string {
+	names := make(map[string]string, len(file.Imports))
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		names[name] = importPath
+	}
+	return names
+}
+
+// missingImports returns the import specs, in the form `"path"` or
+// `name "path"`, that the existing file needs for the added resources.
+func missingImports(existing, generated *ast.File, added []declaredResource) ([]string, error) {
+	have := importNames(existing)
+	want := importNames(generated)
+
+	seen := make(map[string]bool)
+	var specs []string
+	for _, res := range added {
+		importPath := want[res.pkgIdent]
+		if current, ok := have[res.pkgIdent]; ok {
+			if current != importPath {
+				return nil, fmt.Errorf("cannot import %q: the existing file already imports %q as %s", importPath, current, res.pkgIdent)
+			}
+			continue
+		}
+		if seen[res.pkgIdent] {
+			continue
+		}
+		seen[res.pkgIdent] = true
+		if path.Base(importPath) == res.pkgIdent {
+			specs = append(specs, strconv.Quote(importPath))
+		} else {
+			specs = append(specs, res.pkgIdent+" "+strconv.Quote(importPath))
+		}
+	}
+	sort.Strings(specs)
+	return specs, nil
+}
+
+// insertImports returns src with specs added to its last import declaration,
+// or to a new one after the package clause if it has none.
+func insertImports(fset *token.FileSet, file *ast.File, src []byte, specs []string) string {
+	text := string(src)
+	if len(specs) == 0 {
+		return text
+	}
+
+	var last *ast.GenDecl
+	for _, d := range file.Decls {
+		if decl, ok := d.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
+			last = decl
+		}
+	}
+
+	switch {
+	case last == nil:
+		at := fset.Position(file.Name.End()).Offset
+		block := "\n\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)"
+		return text[:at] + block + text[at:]
+	case last.Rparen.IsValid():
+		at := fset.Position(last.Rparen).Offset
+		return text[:at] + "\t" + strings.Join(specs, "\n\t") + "\n" + text[at:]
+	default:
+		// A single unparenthesized import becomes a group
+		start, end := fset.Position(last.Pos()).Offset, fset.Position(last.End()).Offset
+		existing := text[fset.Position(last.Specs[0].Pos()).Offset:end]
+		return text[:start] + "import (\n\t" + existing + "\n\t" + strings.Join(specs, "\n\t") + "\n)" + text[end:]
+	}
+}
+
+var (
+	posType     = reflect.TypeOf(token.NoPos)
+	objectType  = reflect.TypeOf((*ast.Object)(nil))
+	commentType = reflect.TypeOf((*ast.CommentGroup)(nil))
+)
+
+// equalAST reports whether two syntax trees are the same, ignoring
+// positions, comments, and resolved objects.
+func equalAST(a, b reflect.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+		return equalAST(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			switch a.Type().Field(i).Type {
+			case posType, objectType, commentType:
+				continue
+			}
+			if !equalAST(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalAST(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Equal(b)
+	}
+}
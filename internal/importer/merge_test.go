@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mergeTemplate = `{
+	"resources": [
+		{
+			"type": "Microsoft.Storage/storageAccounts",
+			"apiVersion": "2023-01-01",
+			"name": "mystorage",
+			"location": "eastus",
+			"kind": "StorageV2",
+			"sku": {"name": "Standard_LRS"}
+		},
+		{
+			"type": "Microsoft.Network/networkSecurityGroups",
+			"apiVersion": "2021-05-01",
+			"name": "web-nsg",
+			"location": "eastus"
+		},
+		{
+			"type": "Microsoft.Network/virtualNetworks",
+			"apiVersion": "2021-05-01",
+			"name": "main-vnet",
+			"location": "eastus",
+			"properties": {"addressSpace": {"addressPrefixes": ["10.0.0.0/16"]}},
+			"dependsOn": ["[resourceId('Microsoft.Network/networkSecurityGroups', 'web-nsg')]"]
+		}
+	]
+}`
+
+// existingInfra declares the first two resources of mergeTemplate, laid out
+// differently from the generated code
+const existingInfra = `package infra
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+// Storage for application logs
+var Mystorage = storage.StorageAccount{
+	Name: "mystorage", Location: "eastus", Kind: "StorageV2",
+	SKU: storage.SKU{Name: "Standard_LRS"},
+}
+
+var WebNsg = network.NetworkSecurityGroup{Name: "web-nsg", Location: "eastus"}
+`
+
+func generateMergeTemplate(t *testing.T) string {
+	t.Helper()
+	template, err := ParseARMTemplate([]byte(mergeTemplate))
+	require.NoError(t, err)
+	code, err := GenerateGoCode(template, "main")
+	require.NoError(t, err)
+	return code
+}
+
+func TestMergeGoCode_AppendsNewResource(t *testing.T) {
+	result, err := MergeGoCode([]byte(existingInfra), generateMergeTemplate(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"MainVNet"}, result.Added)
+	assert.Equal(t, []string{"Mystorage", "WebNsg"}, result.Unchanged)
+	assert.Empty(t, result.Conflicts)
+
+	merged := string(result.Source)
+	// The existing file is kept as written
+	assert.True(t, strings.HasPrefix(merged, existingInfra), "existing code changed:\n%s", merged)
+	assert.Contains(t, merged, "// DependsOn: WebNsg\nvar MainVNet = network.VirtualNetwork{")
+	assert.Equal(t, 1, strings.Count(merged, "var Mystorage"))
+	assert.Equal(t, 1, strings.Count(merged, "var WebNsg"))
+
+	_, err = parser.ParseFile(token.NewFileSet(), "merged.go", result.Source, 0)
+	assert.NoError(t, err)
+}
+
+func TestMergeGoCode_AddsImports(t *testing.T) {
+	existing := `package infra
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Mystorage = storage.StorageAccount{
+	Name:     "mystorage",
+	Location: "eastus",
+	Kind:     "StorageV2",
+	SKU:      storage.SKU{Name: "Standard_LRS"},
+}
+`
+	result, err := MergeGoCode([]byte(existing), generateMergeTemplate(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"WebNsg", "MainVNet"}, result.Added)
+	merged := string(result.Source)
+	assert.Contains(t, merged, "package infra\n")
+	assert.Contains(t, merged, "import (\n\t\"github.com/lex00/wetwire-azure-go/resources/network\"\n\t\"github.com/lex00/wetwire-azure-go/resources/storage\"\n)")
+
+	file, err := parser.ParseFile(token.NewFileSet(), "merged.go", result.Source, 0)
+	require.NoError(t, err)
+	assert.Len(t, file.Imports, 2)
+}
+
+func TestMergeGoCode_Conflict(t *testing.T) {
+	existing := strings.Replace(existingInfra, `Location: "eastus"}`, `Location: "westus"}`, 1)
+
+	result, err := MergeGoCode([]byte(existing), generateMergeTemplate(t))
+	require.NoError(t, err)
+
+	require.Len(t, result.Conflicts, 1)
+	conflict := result.Conflicts[0]
+	assert.Equal(t, "WebNsg", conflict.Var)
+	assert.Equal(t, "web-nsg", conflict.Name)
+	assert.Equal(t, 14, conflict.Line)
+	assert.Contains(t, conflict.Error(), "already declared with a different body")
+	assert.Nil(t, result.Source, "a conflicting merge must not produce a file")
+}
+
+func TestMergeGoCode_VariableNameTaken(t *testing.T) {
+	existing := `package infra
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var MainVNet = network.VirtualNetwork{Name: "legacy-vnet"}
+`
+	result, err := MergeGoCode([]byte(existing), generateMergeTemplate(t))
+	require.NoError(t, err)
+
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "MainVNet", result.Conflicts[0].Var)
+	assert.Contains(t, result.Conflicts[0].Reason, `"legacy-vnet"`)
+}
@@ -3,10 +3,12 @@ package lint
 
 import (
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/lex00/wetwire-azure-go/internal/discover"
 	corelint "github.com/lex00/wetwire-core-go/lint"
 )
 
@@ -56,8 +58,15 @@ type FixableRule interface {
 type Options struct {
 	// DisabledRules specifies rules to disable by ID (e.g., "WAZ001", "WAZ002").
 	DisabledRules []string
+	// OnlyRules restricts the linter to these rule IDs (e.g., "WAZ301");
+	// empty runs all rules. DisabledRules still apply.
+	OnlyRules []string
 	// Fix automatically fixes fixable issues (reserved for future use).
 	Fix bool
+	// CheckGlobalNames enables WAZ402, which flags hardcoded names of
+	// resources whose names must be unique across Azure. It is off by
+	// default; naming WAZ402 in OnlyRules also enables it.
+	CheckGlobalNames bool
 }
 
 // Linter runs lint rules on Go files
@@ -78,21 +87,47 @@ func NewLinterWithOptions(opts Options) *Linter {
 		options: opts,
 	}
 
-	// Build disabled rules set
+	// Build disabled and allowed rules sets
 	disabled := make(map[string]bool)
 	for _, id := range opts.DisabledRules {
 		disabled[id] = true
 	}
+	only := make(map[string]bool)
+	for _, id := range opts.OnlyRules {
+		only[id] = true
+	}
 
-	// Register all default rules except disabled ones
-	for _, rule := range AllRules() {
-		if !disabled[rule.ID()] {
-			l.AddRule(rule)
+	// Register all default rules, and the opt-in ones enabled, that are
+	// allowed and not disabled
+	rules := AllRules()
+	if opts.CheckGlobalNames || only["WAZ402"] {
+		rules = append(rules, &WAZ402{})
+	}
+	for _, rule := range rules {
+		if disabled[rule.ID()] || (len(only) > 0 && !only[rule.ID()]) {
+			continue
 		}
+		l.AddRule(rule)
 	}
 	return l
 }
 
+// UnknownRuleIDs returns the IDs in ids that do not match any default rule.
+func UnknownRuleIDs(ids []string) []string {
+	known := make(map[string]bool)
+	for _, rule := range append(AllRules(), optInRules()...) {
+		known[rule.ID()] = true
+	}
+
+	var unknown []string
+	for _, id := range ids {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	return unknown
+}
+
 // AddRule adds a rule to the linter
 func (l *Linter) AddRule(rule Rule) {
 	l.rules = append(l.rules, rule)
@@ -124,8 +159,10 @@ func (l *Linter) CheckFile(file string) ([]LintResult, error) {
 	return allResults, nil
 }
 
-// CheckDirectory runs all lint rules on all Go files in a directory (recursively)
-func (l *Linter) CheckDirectory(dir string) ([]LintResult, error) {
+// CheckDirectory runs all lint rules on all Go files in a directory
+// (recursively). Test files and files matching the exclude patterns are
+// skipped, as in discovery (see discover.Excludes).
+func (l *Linter) CheckDirectory(dir string, exclude ...string) ([]LintResult, error) {
 	// Verify directory exists
 	if _, err := os.Stat(dir); err != nil {
 		return nil, fmt.Errorf("directory not found: %w", err)
@@ -134,21 +171,7 @@ func (l *Linter) CheckDirectory(dir string) ([]LintResult, error) {
 	var allResults []LintResult
 
 	// Walk through all Go files in the directory
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories and non-Go files
-		if info.IsDir() || !strings.HasSuffix(path, ".go") {
-			return nil
-		}
-
-		// Skip test files
-		if strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
+	err := discover.WalkGoFiles(dir, exclude, func(path string) error {
 		// Check the file
 		results, err := l.CheckFile(path)
 		if err != nil {
@@ -165,3 +188,93 @@ func (l *Linter) CheckDirectory(dir string) ([]LintResult, error) {
 
 	return allResults, nil
 }
+
+// FixFile applies every fixable rule to file and writes the result back if it
+// changed. It returns the issues that were fixed; issues that remain after a
+// rule's fix are not reported as fixed.
+func (l *Linter) FixFile(file string) ([]LintResult, error) {
+	if !strings.HasSuffix(file, ".go") {
+		return nil, nil
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %w", err)
+	}
+
+	var fixed []LintResult
+	for _, rule := range l.rules {
+		fixable, ok := rule.(FixableRule)
+		if !ok || !fixable.CanFix() {
+			continue
+		}
+
+		before, err := rule.Check(file)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s failed: %w", rule.ID(), err)
+		}
+		if len(before) == 0 {
+			continue
+		}
+
+		original, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fixable.Fix(file)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s fix failed: %w", rule.ID(), err)
+		}
+		if content == string(original) {
+			continue
+		}
+		if err := os.WriteFile(file, []byte(content), info.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("write %s: %w", file, err)
+		}
+
+		after, err := rule.Check(file)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s failed: %w", rule.ID(), err)
+		}
+		remaining := make(map[string]bool, len(after))
+		for _, issue := range after {
+			remaining[issue.Message] = true
+		}
+		for _, issue := range before {
+			if !remaining[issue.Message] {
+				fixed = append(fixed, issue)
+			}
+		}
+	}
+
+	return fixed, nil
+}
+
+// Format returns the content of file with every fixable rule applied and then
+// formatted with go/format, without modifying file. The fixes are applied to
+// a temporary copy, since rules read the files they fix.
+func (l *Linter) Format(file string) ([]byte, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "wetwire-fmt-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	scratch := filepath.Join(dir, filepath.Base(file))
+	if err := os.WriteFile(scratch, src, 0o600); err != nil {
+		return nil, err
+	}
+	if _, err := l.FixFile(scratch); err != nil {
+		return nil, err
+	}
+	fixed, err := os.ReadFile(scratch)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(fixed)
+}
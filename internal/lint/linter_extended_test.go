@@ -58,6 +58,31 @@ var TestStorage = storage.StorageAccount{
 	assert.Empty(t, results, "Test files should be skipped")
 }
 
+// TestLinterCheckDirectory_Exclude tests that excluded files and directories are not linted
+func TestLinterCheckDirectory_Exclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{
+	Location: "East US",
+}
+`
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "vendor", "gen"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "generated_storage.go"), []byte(content), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "vendor", "gen", "storage.go"), []byte(content), 0644))
+
+	linter := NewLinter()
+	results, err := linter.CheckDirectory(tmpDir)
+	require.NoError(t, err)
+	assert.NotEmpty(t, results)
+
+	results, err = linter.CheckDirectory(tmpDir, "generated_*.go", "vendor/**")
+	require.NoError(t, err)
+	assert.Empty(t, results, "Excluded files should be skipped")
+}
+
 // TestLinterCheckDirectory_SubdirError tests error handling when subdirectory has issues
 func TestLinterCheckDirectory_SubdirError(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -728,3 +753,57 @@ var MyStorage = storage.StorageAccount{
 		assert.NotEqual(t, "WAZ001", r.Rule, "WAZ001 should be disabled in directory check")
 	}
 }
+
+// TestLinterOptions_OnlyRules tests that only the allowed rules are registered and run
+func TestLinterOptions_OnlyRules(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// WAZ001 (invalid location format) and WAZ004 (duplicate names) both trigger
+	code := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorageaccount",
+	Location: "East US",
+}
+
+var MyStorage = storage.StorageAccount{
+	Name:     "duplicatename",
+	Location: "West US",
+}
+`
+	testFile := filepath.Join(tmpDir, "test.go")
+	err := os.WriteFile(testFile, []byte(code), 0644)
+	require.NoError(t, err)
+
+	linter := NewLinterWithOptions(Options{
+		OnlyRules: []string{"WAZ001", "WAZ301"},
+	})
+	require.Len(t, linter.rules, 2)
+	assert.Equal(t, "WAZ001", linter.rules[0].ID())
+	assert.Equal(t, "WAZ301", linter.rules[1].ID())
+
+	results, err := linter.CheckFile(testFile)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	for _, r := range results {
+		assert.Contains(t, []string{"WAZ001", "WAZ301"}, r.Rule)
+	}
+}
+
+// TestLinterOptions_OnlyAndDisabledRules tests that disabled rules win over allowed ones
+func TestLinterOptions_OnlyAndDisabledRules(t *testing.T) {
+	linter := NewLinterWithOptions(Options{
+		OnlyRules:     []string{"WAZ301", "WAZ302"},
+		DisabledRules: []string{"WAZ302"},
+	})
+	require.Len(t, linter.rules, 1)
+	assert.Equal(t, "WAZ301", linter.rules[0].ID())
+}
+
+// TestUnknownRuleIDs tests that rule IDs without a default rule are reported
+func TestUnknownRuleIDs(t *testing.T) {
+	assert.Equal(t, []string{"WAZ306", "NOPE"}, UnknownRuleIDs([]string{"WAZ301", "WAZ306", "NOPE"}))
+	assert.Empty(t, UnknownRuleIDs([]string{"WAZ001", "WAZ301"}))
+}
@@ -3,6 +3,7 @@ package lint
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -96,6 +97,94 @@ var MyStorage = storage.StorageAccount{
 	}
 }
 
+func TestLinterFixFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.go")
+	testContent := `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorageaccount",
+	Location: "East US",
+}
+`
+	if err := os.WriteFile(testFile, []byte(testContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := NewLinter()
+	fixed, err := linter.FixFile(testFile)
+	if err != nil {
+		t.Fatalf("FixFile() error: %v", err)
+	}
+	if len(fixed) != 1 || fixed[0].Rule != "WAZ001" {
+		t.Fatalf("expected one fixed WAZ001 issue, got %v", fixed)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `Location: "eastus"`) {
+		t.Errorf("expected location to be normalized, got:\n%s", content)
+	}
+
+	// A second pass has nothing left to fix
+	fixed, err = linter.FixFile(testFile)
+	if err != nil {
+		t.Fatalf("FixFile() error: %v", err)
+	}
+	if len(fixed) != 0 {
+		t.Errorf("expected no fixes on second pass, got %v", fixed)
+	}
+}
+
+func TestLinterFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.go")
+	testContent := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name: "mystorageaccount",
+	Location: "West Europe",
+}
+`
+	if err := os.WriteFile(testFile, []byte(testContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	formatted, err := NewLinter().Format(testFile)
+	if err != nil {
+		t.Fatalf("Format() error: %v", err)
+	}
+	want := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var MyStorage = storage.StorageAccount{
+	Name:     "mystorageaccount",
+	Location: "westeurope",
+}
+`
+	if string(formatted) != want {
+		t.Errorf("Format() =\n%s\nwant:\n%s", formatted, want)
+	}
+
+	// The file itself is left alone
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != testContent {
+		t.Errorf("expected file to be unchanged, got:\n%s", content)
+	}
+}
+
 func TestLinterCheckDirectory(t *testing.T) {
 	// Create a temporary directory with test files
 	tmpDir := t.TempDir()
@@ -14,9 +14,26 @@ func AllRules() []Rule {
 		&WAZ020{},
 		&WAZ021{},
 		&WAZ022{},
+		&WAZ104{},
 		&WAZ301{},
 		&WAZ302{},
 		&WAZ303{},
 		&WAZ304{},
+		&WAZ307{},
+		&WAZ309{},
+		&WAZ310{},
+		&WAZ311{},
+		&WAZ312{},
+		&WAZ313{},
+		&WAZ314{},
+		&WAZ315{},
+	}
+}
+
+// optInRules returns the rules that are not in AllRules because they only
+// run when enabled (see Options.CheckGlobalNames)
+func optInRules() []Rule {
+	return []Rule{
+		&WAZ402{},
 	}
 }
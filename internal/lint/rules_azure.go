@@ -5,7 +5,13 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+
+	coreast "github.com/lex00/wetwire-core-go/ast"
 )
 
 // WAZ301 checks that HTTPS-only is enabled for storage accounts
@@ -249,3 +255,1185 @@ func (r *WAZ304) Check(file string) ([]LintResult, error) {
 
 	return results, nil
 }
+
+// WAZ307 detects hardcoded VM admin passwords
+type WAZ307 struct{}
+
+func (r *WAZ307) ID() string {
+	return "WAZ307"
+}
+
+func (r *WAZ307) Description() string {
+	return "Require secureString parameters or SSH keys for VM admin passwords"
+}
+
+func (r *WAZ307) Severity() Severity {
+	return SeverityError
+}
+
+func (r *WAZ307) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []LintResult
+	stringValues := packageStrings(node)
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := kv.Key.(*ast.Ident)
+		if !ok || ident.Name != "AdminPassword" {
+			return true
+		}
+
+		// The value may be a literal or wrapped, e.g. ptr("..."), or refer to
+		// a package-level string, e.g. &adminPassword; any such string that
+		// is not an ARM expression is a hardcoded password
+		hardcoded := false
+		var visit func(ast.Node) bool
+		visit = func(v ast.Node) bool {
+			switch v := v.(type) {
+			case *ast.BasicLit:
+				if v.Kind == token.STRING && isHardcodedPassword(v.Value) {
+					hardcoded = true
+				}
+			case *ast.Ident:
+				if value, ok := stringValues[v.Name]; ok && isHardcodedPassword(value) {
+					hardcoded = true
+				}
+			case *ast.SelectorExpr:
+				// The selected field or package member is not one of this
+				// file's package-level strings, whatever its name
+				ast.Inspect(v.X, visit)
+				return false
+			}
+			return !hardcoded
+		}
+		ast.Inspect(kv.Value, visit)
+
+		if hardcoded {
+			pos := fset.Position(kv.Pos())
+			results = append(results, LintResult{
+				Rule:     r.ID(),
+				File:     file,
+				Line:     pos.Line,
+				Message:  "AdminPassword is hardcoded. Use a secureString parameter (\"[parameters('adminPassword')]\"), or OSProfile.WithSSHPublicKey or VirtualMachine.WithSSHKeyResource for Linux VMs",
+				Severity: r.Severity(),
+			})
+		}
+		return true
+	})
+
+	return results, nil
+}
+
+// isHardcodedPassword reports whether the Go string literal lit is a
+// password rather than empty or an ARM expression
+func isHardcodedPassword(lit string) bool {
+	value := strings.Trim(lit, "\"`")
+	return value != "" && !strings.HasPrefix(value, "[")
+}
+
+// packageStrings returns the string literal values, still quoted, of the
+// package-level constants and variables declared in node
+func packageStrings(node *ast.File) map[string]string {
+	values := make(map[string]string)
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || (genDecl.Tok != token.CONST && genDecl.Tok != token.VAR) {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok || len(valueSpec.Values) != len(valueSpec.Names) {
+				continue
+			}
+			for i, name := range valueSpec.Names {
+				if lit, ok := valueSpec.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					values[name.Name] = lit.Value
+				}
+			}
+		}
+	}
+	return values
+}
+
+// WAZ309 flags confidential storage accounts encrypted with platform-managed keys
+type WAZ309 struct{}
+
+func (r *WAZ309) ID() string {
+	return "WAZ309"
+}
+
+func (r *WAZ309) Description() string {
+	return "Require customer-managed keys for storage accounts tagged data-class: confidential"
+}
+
+func (r *WAZ309) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *WAZ309) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tags may be declared once as a package-level map and shared
+	maps := packageLiterals(node)
+
+	var results []LintResult
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for _, value := range vs.Values {
+			account, ok := inspectStorageAccount(value)
+			if !ok || account.customerManagedKey {
+				continue
+			}
+			if !hasTag(resolveLiteral(account.tags, maps), "data-class", "confidential") {
+				continue
+			}
+
+			pos := fset.Position(value.Pos())
+			results = append(results, LintResult{
+				Rule:     r.ID(),
+				File:     file,
+				Line:     pos.Line,
+				Message:  "Storage account tagged data-class: confidential uses platform-managed keys (Microsoft.Storage). Encrypt it with a Key Vault key using WithCustomerManagedKey",
+				Severity: r.Severity(),
+			})
+		}
+		return true
+	})
+
+	return results, nil
+}
+
+// WAZ310 flags production storage accounts without blob soft delete or versioning
+type WAZ310 struct{}
+
+func (r *WAZ310) ID() string {
+	return "WAZ310"
+}
+
+func (r *WAZ310) Description() string {
+	return "Require blob soft delete and versioning for storage accounts tagged environment: production"
+}
+
+func (r *WAZ310) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *WAZ310) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	maps := packageLiterals(node)
+
+	// Blob services may be declared apart from their account, in any file of
+	// the package
+	files, err := packageFiles(fset, file, node)
+	if err != nil {
+		return nil, err
+	}
+	blobServices := make(map[string]blobServiceExpr)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, value := range spec.(*ast.ValueSpec).Values {
+					if service, ok := inspectBlobService(value); ok && service.account != "" {
+						blobServices[service.account] = service
+					}
+				}
+			}
+		}
+	}
+
+	var results []LintResult
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, value := range vs.Values {
+			account, ok := inspectStorageAccount(value)
+			if !ok || !hasTag(resolveLiteral(account.tags, maps), "environment", "production") {
+				continue
+			}
+			if i < len(vs.Names) {
+				if service, ok := blobServices[vs.Names[i].Name]; ok {
+					account.blobSoftDelete = account.blobSoftDelete || service.softDelete
+					account.blobVersioning = account.blobVersioning || service.versioning
+				}
+			}
+
+			var missing []string
+			if !account.blobSoftDelete {
+				missing = append(missing, "blob soft delete")
+			}
+			if !account.blobVersioning {
+				missing = append(missing, "blob versioning")
+			}
+			if len(missing) == 0 {
+				continue
+			}
+
+			pos := fset.Position(value.Pos())
+			results = append(results, LintResult{
+				Rule:     r.ID(),
+				File:     file,
+				Line:     pos.Line,
+				Message:  fmt.Sprintf("Storage account tagged environment: production does not enable %s. Set Properties.BlobServices, use WithBlobDataProtection, or declare a storage.BlobService for it", strings.Join(missing, " or ")),
+				Severity: r.Severity(),
+			})
+		}
+		return true
+	})
+
+	return results, nil
+}
+
+// WAZ311 flags malformed NSG rule port ranges
+type WAZ311 struct{}
+
+func (r *WAZ311) ID() string {
+	return "WAZ311"
+}
+
+func (r *WAZ311) Description() string {
+	return "Require valid NSG rule port ranges (0-65535, low-high with low <= high, or *)"
+}
+
+func (r *WAZ311) Severity() Severity {
+	return SeverityError
+}
+
+func (r *WAZ311) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []LintResult
+
+	check := func(field string, lit *ast.BasicLit) {
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil || strings.HasPrefix(value, "[") {
+			return
+		}
+		if reason := checkPortRange(value); reason != "" {
+			pos := fset.Position(lit.Pos())
+			results = append(results, LintResult{
+				Rule:     r.ID(),
+				File:     file,
+				Line:     pos.Line,
+				Message:  fmt.Sprintf("Invalid NSG port range: %s='%s' %s. Use a port (0-65535), a range low-high with low <= high, or *", field, value, reason),
+				Severity: r.Severity(),
+			})
+		}
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		switch ident.Name {
+		case "SourcePortRange", "DestinationPortRange":
+			if lit, ok := kv.Value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				check(ident.Name, lit)
+			}
+		case "SourcePortRanges", "DestinationPortRanges":
+			if list, ok := kv.Value.(*ast.CompositeLit); ok {
+				for _, elt := range list.Elts {
+					if lit, ok := elt.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						check(ident.Name, lit)
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	return results, nil
+}
+
+// checkPortRange returns why value is not a valid NSG port range, or "" if
+// it is a single port, a low-high range or *
+func checkPortRange(value string) string {
+	if value == "*" {
+		return ""
+	}
+	lowText, highText, isRange := strings.Cut(value, "-")
+	low, err := parsePort(lowText)
+	if err != nil {
+		return err.Error()
+	}
+	if !isRange {
+		return ""
+	}
+	high, err := parsePort(highText)
+	if err != nil {
+		return err.Error()
+	}
+	if low > high {
+		return fmt.Sprintf("is inverted (%d > %d)", low, high)
+	}
+	return ""
+}
+
+// parsePort parses a port number between 0 and 65535
+func parsePort(text string) (int, error) {
+	if text == "" || strings.Trim(text, "0123456789") != "" {
+		return 0, fmt.Errorf("is not a port number")
+	}
+	port, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("is out of bounds (%s)", text)
+	}
+	if port < 0 || port > 65535 {
+		return 0, fmt.Errorf("is out of bounds (%d)", port)
+	}
+	return port, nil
+}
+
+// WAZ312 flags Key Vault access configurations that lock everyone out or
+// grant all permissions
+type WAZ312 struct{}
+
+func (r *WAZ312) ID() string {
+	return "WAZ312"
+}
+
+func (r *WAZ312) Description() string {
+	return "Require usable, least-privilege Key Vault access (RBAC or access policies without all permissions)"
+}
+
+func (r *WAZ312) Severity() Severity {
+	return SeverityError
+}
+
+func (r *WAZ312) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := coreast.ExtractImports(node)
+
+	var results []LintResult
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || resourceTypeOf(lit.Type, imports) != "Microsoft.KeyVault/vaults" {
+			return true
+		}
+
+		var props *ast.CompositeLit
+		switch p := unwrapAddr(compositeField(lit, "Properties")).(type) {
+		case nil:
+			props = &ast.CompositeLit{}
+		case *ast.CompositeLit:
+			props = p
+		default:
+			// Set elsewhere; nothing to inspect
+			return true
+		}
+
+		policies := compositeField(props, "AccessPolicies")
+		policyList, isList := policies.(*ast.CompositeLit)
+		noPolicies := policies == nil || (isList && len(policyList.Elts) == 0)
+		if noPolicies && !enabledFlag(compositeField(props, "EnableRBACAuthorization")) {
+			pos := fset.Position(lit.Pos())
+			results = append(results, LintResult{
+				Rule:     r.ID(),
+				File:     file,
+				Line:     pos.Line,
+				Message:  "Key Vault has RBAC authorization disabled and no access policies, so no one can use it. Set Properties.EnableRBACAuthorization or add Properties.AccessPolicies",
+				Severity: SeverityWarning,
+			})
+		}
+		if !isList {
+			return true
+		}
+
+		for _, elt := range policyList.Elts {
+			policy, ok := unwrapAddr(elt).(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			perms, ok := unwrapAddr(compositeField(policy, "Permissions")).(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			for _, kind := range []string{"Keys", "Secrets", "Certificates", "Storage"} {
+				list, ok := compositeField(perms, kind).(*ast.CompositeLit)
+				if !ok {
+					continue
+				}
+				for _, p := range list.Elts {
+					perm, ok := p.(*ast.BasicLit)
+					if !ok || perm.Kind != token.STRING || !strings.EqualFold(strings.Trim(perm.Value, "\"`"), "all") {
+						continue
+					}
+					pos := fset.Position(perm.Pos())
+					results = append(results, LintResult{
+						Rule:     r.ID(),
+						File:     file,
+						Line:     pos.Line,
+						Message:  fmt.Sprintf("Key Vault access policy grants all %s permissions. Grant only the operations the principal needs (e.g. get, list), or use RBAC authorization", strings.ToLower(kind)),
+						Severity: SeverityError,
+					})
+				}
+			}
+		}
+		return false
+	})
+
+	return results, nil
+}
+
+// WAZ313 flags overlapping address ranges within a virtual network
+type WAZ313 struct{}
+
+func (r *WAZ313) ID() string {
+	return "WAZ313"
+}
+
+func (r *WAZ313) Description() string {
+	return "Disallow overlapping address prefixes or subnets within a virtual network"
+}
+
+func (r *WAZ313) Severity() Severity {
+	return SeverityError
+}
+
+// Check reads every Go file of the package of file, since subnets may be
+// declared apart from their virtual network, and reports the overlaps whose
+// later range is declared in file
+func (r *WAZ313) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files come in name order, so that which of two overlapping ranges is
+	// the later one does not depend on the file being checked
+	files, err := packageFiles(fset, file, node)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the ranges by virtual network, in declaration order
+	var vnets []string
+	ranges := make(map[string][]addressRange)
+	add := func(vnet string, r addressRange) {
+		if _, ok := ranges[vnet]; !ok {
+			vnets = append(vnets, vnet)
+		}
+		ranges[vnet] = append(ranges[vnet], r)
+	}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			expr, ok := n.(ast.Expr)
+			if !ok {
+				return true
+			}
+			vnet, found := inspectVirtualNetwork(expr)
+			if !found {
+				return true
+			}
+			if vnet.name == "" {
+				// Name set elsewhere; key the network by its position
+				vnet.name = fset.Position(expr.Pos()).String()
+			}
+			for _, r := range vnet.ranges {
+				add(vnet.name, r)
+			}
+			return false
+		})
+	}
+
+	var results []LintResult
+	for _, vnet := range vnets {
+		list := ranges[vnet]
+		for i, later := range list {
+			if fset.Position(later.pos).Filename != file {
+				continue
+			}
+			for _, earlier := range list[:i] {
+				if earlier.subnet != later.subnet || !earlier.network.Contains(later.network.IP) && !later.network.Contains(earlier.network.IP) {
+					continue
+				}
+				message := fmt.Sprintf("Address prefixes %s and %s of virtual network %q overlap. Give each address prefix a distinct range", earlier.prefix, later.prefix, vnet)
+				if later.subnet {
+					message = fmt.Sprintf("Subnets %q (%s) and %q (%s) of virtual network %q overlap. Give each subnet a distinct range", earlier.name, earlier.prefix, later.name, later.prefix, vnet)
+				}
+				results = append(results, LintResult{
+					Rule:     r.ID(),
+					File:     file,
+					Line:     fset.Position(later.pos).Line,
+					Message:  message,
+					Severity: r.Severity(),
+				})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// addressRange is a CIDR range of a virtual network: one of its address
+// prefixes, or the address prefix of one of its subnets
+type addressRange struct {
+	// subnet is true for subnet prefixes, and name is the subnet name
+	subnet bool
+	name   string
+	prefix string
+	// network is the parsed prefix
+	network *net.IPNet
+	pos     token.Pos
+}
+
+// virtualNetworkExpr describes the address ranges of a virtual network
+// declared by a network.VirtualNetwork literal, a NewVirtualNetwork call with
+// any chained WithSubnet calls, or a standalone subnet
+type virtualNetworkExpr struct {
+	// name is the virtual network name, or "" if it is not a string literal
+	name   string
+	ranges []addressRange
+}
+
+// inspectVirtualNetwork reports whether expr declares a virtual network or a
+// standalone subnet, and describes its address ranges. Prefixes that are not
+// CIDR string literals, such as ARM expressions, are left out.
+func inspectVirtualNetwork(expr ast.Expr) (virtualNetworkExpr, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return inspectVirtualNetwork(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return inspectVirtualNetwork(e.X)
+		}
+	case *ast.CompositeLit:
+		sel, ok := e.Type.(*ast.SelectorExpr)
+		if !ok {
+			return virtualNetworkExpr{}, false
+		}
+		switch sel.Sel.Name {
+		case "VirtualNetwork":
+			vnet := virtualNetworkExpr{name: stringLiteral(compositeField(e, "Name"))}
+			props, ok := unwrapAddr(compositeField(e, "Properties")).(*ast.CompositeLit)
+			if !ok {
+				return vnet, true
+			}
+			if space, ok := compositeField(props, "AddressSpace").(*ast.CompositeLit); ok {
+				vnet.addPrefixes(compositeField(space, "AddressPrefixes"))
+			}
+			if subnets, ok := compositeField(props, "Subnets").(*ast.CompositeLit); ok {
+				for _, elt := range subnets.Elts {
+					vnet.addSubnet(subnetLiteral(elt))
+				}
+			}
+			return vnet, true
+		case "VirtualNetworkSubnet":
+			vnetName, subnetName, _ := strings.Cut(stringLiteral(compositeField(e, "Name")), "/")
+			vnet := virtualNetworkExpr{name: vnetName}
+			if props, ok := compositeField(e, "Properties").(*ast.CompositeLit); ok {
+				vnet.addSubnet(subnetName, compositeField(props, "AddressPrefix"))
+			}
+			return vnet, true
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return virtualNetworkExpr{}, false
+		}
+		switch sel.Sel.Name {
+		case "NewVirtualNetwork":
+			if len(e.Args) != 3 {
+				return virtualNetworkExpr{}, false
+			}
+			vnet := virtualNetworkExpr{name: stringLiteral(e.Args[0])}
+			vnet.addPrefixes(e.Args[2])
+			return vnet, true
+		case "NewVirtualNetworkSubnet":
+			if len(e.Args) != 2 {
+				return virtualNetworkExpr{}, false
+			}
+			vnet := virtualNetworkExpr{name: stringLiteral(e.Args[0])}
+			vnet.addSubnet(subnetCall(e.Args[1]))
+			return vnet, true
+		}
+		vnet, ok := inspectVirtualNetwork(sel.X)
+		if ok && sel.Sel.Name == "WithSubnet" && len(e.Args) == 2 {
+			vnet.addSubnet(stringLiteral(e.Args[0]), e.Args[1])
+		}
+		return vnet, ok
+	}
+	return virtualNetworkExpr{}, false
+}
+
+// addPrefixes adds the CIDR literals of a list of address prefixes
+func (v *virtualNetworkExpr) addPrefixes(expr ast.Expr) {
+	list, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	for _, elt := range list.Elts {
+		v.add(false, "", elt)
+	}
+}
+
+// addSubnet adds the address prefix of the named subnet if it is a CIDR literal
+func (v *virtualNetworkExpr) addSubnet(name string, prefix ast.Expr) {
+	v.add(true, name, prefix)
+}
+
+func (v *virtualNetworkExpr) add(subnet bool, name string, expr ast.Expr) {
+	prefix := stringLiteral(expr)
+	_, network, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return
+	}
+	v.ranges = append(v.ranges, addressRange{subnet: subnet, name: name, prefix: prefix, network: network, pos: expr.Pos()})
+}
+
+// subnetLiteral returns the name and address prefix of a network.Subnet literal
+func subnetLiteral(expr ast.Expr) (string, ast.Expr) {
+	lit, ok := unwrapAddr(expr).(*ast.CompositeLit)
+	if !ok {
+		return "", nil
+	}
+	props, ok := compositeField(lit, "Properties").(*ast.CompositeLit)
+	if !ok {
+		return "", nil
+	}
+	return stringLiteral(compositeField(lit, "Name")), compositeField(props, "AddressPrefix")
+}
+
+// subnetCall returns the name and address prefix of a NewSubnet call, with any
+// chained calls such as WithNSG, or of a network.Subnet literal
+func subnetCall(expr ast.Expr) (string, ast.Expr) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return subnetLiteral(expr)
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", nil
+	}
+	if sel.Sel.Name == "NewSubnet" && len(call.Args) == 2 {
+		return stringLiteral(call.Args[0]), call.Args[1]
+	}
+	return subnetCall(sel.X)
+}
+
+// stringLiteral returns the value of expr if it is a string literal, or ""
+func stringLiteral(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// WAZ314 flags confidential storage accounts whose network rules allow
+// public access by default
+type WAZ314 struct{}
+
+func (r *WAZ314) ID() string {
+	return "WAZ314"
+}
+
+func (r *WAZ314) Description() string {
+	return "Require network rules denying public access by default for storage accounts tagged data-class: confidential"
+}
+
+func (r *WAZ314) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *WAZ314) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	maps := packageLiterals(node)
+
+	var results []LintResult
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for _, value := range vs.Values {
+			account, ok := inspectStorageAccount(value)
+			if !ok || !account.publicNetworkDefaultAllow {
+				continue
+			}
+			if !hasTag(resolveLiteral(account.tags, maps), "data-class", "confidential") {
+				continue
+			}
+
+			pos := fset.Position(value.Pos())
+			results = append(results, LintResult{
+				Rule:     r.ID(),
+				File:     file,
+				Line:     pos.Line,
+				Message:  "Storage account tagged data-class: confidential allows public network access by default. Set Properties.NetworkRuleSet.DefaultAction to \"Deny\" and allow trusted networks with IPRules or VirtualNetworkRules",
+				Severity: r.Severity(),
+			})
+		}
+		return true
+	})
+
+	return results, nil
+}
+
+// storageAccountExpr describes a storage account declaration, either a
+// storage.StorageAccount literal or a NewStorageAccount call, with any
+// chained With* calls applied
+type storageAccountExpr struct {
+	// tags is the value given for Tags, or nil
+	tags ast.Expr
+	// customerManagedKey is true if the key source is not Microsoft.Storage,
+	// or cannot be determined from the source
+	customerManagedKey bool
+	// blobSoftDelete and blobVersioning are true if the blob service enables
+	// them, or if it cannot be determined from the source
+	blobSoftDelete bool
+	blobVersioning bool
+	// publicNetworkDefaultAllow is true if the network rules admit public
+	// traffic no rule matches: DefaultAction is Allow or the rule set is
+	// omitted, and PublicNetworkAccess is not Disabled. Settings that cannot
+	// be determined from the source are assumed to deny.
+	publicNetworkDefaultAllow bool
+}
+
+// inspectStorageAccount reports whether expr declares a storage account and describes it
+func inspectStorageAccount(expr ast.Expr) (storageAccountExpr, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return inspectStorageAccount(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return inspectStorageAccount(e.X)
+		}
+	case *ast.CompositeLit:
+		sel, ok := e.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "StorageAccount" {
+			return storageAccountExpr{}, false
+		}
+		account := storageAccountExpr{tags: compositeField(e, "Tags")}
+		propsExpr := compositeField(e, "Properties")
+		props, ok := unwrapAddr(propsExpr).(*ast.CompositeLit)
+		if !ok {
+			account.publicNetworkDefaultAllow = propsExpr == nil
+			return account, true
+		}
+		account.publicNetworkDefaultAllow = networkDefaultAllow(props)
+		if enc, ok := unwrapAddr(compositeField(props, "Encryption")).(*ast.CompositeLit); ok {
+			switch source := compositeField(enc, "KeySource").(type) {
+			case nil:
+			case *ast.BasicLit:
+				account.customerManagedKey = !strings.EqualFold(strings.Trim(source.Value, "\"`"), "Microsoft.Storage")
+			default:
+				// Set elsewhere; assume it is intended
+				account.customerManagedKey = true
+			}
+		}
+		if blob := compositeField(props, "BlobServices"); blob != nil {
+			account.blobSoftDelete, account.blobVersioning = blobDataProtection(blob)
+		}
+		return account, true
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return storageAccountExpr{}, false
+		}
+		if sel.Sel.Name == "NewStorageAccount" {
+			return storageAccountExpr{publicNetworkDefaultAllow: true}, true
+		}
+		account, ok := inspectStorageAccount(sel.X)
+		if !ok {
+			return account, false
+		}
+		switch sel.Sel.Name {
+		case "WithTags":
+			if len(e.Args) == 1 {
+				account.tags = e.Args[0]
+			}
+		case "WithCustomerManagedKey":
+			account.customerManagedKey = true
+		case "WithBlobDataProtection":
+			account.blobSoftDelete, account.blobVersioning = true, true
+		}
+		return account, true
+	}
+	return storageAccountExpr{}, false
+}
+
+// networkDefaultAllow reports whether the storage account properties props
+// admit public traffic that no network rule matches
+func networkDefaultAllow(props *ast.CompositeLit) bool {
+	if access := compositeField(props, "PublicNetworkAccess"); access != nil {
+		// A *string, usually given through a helper such as strPtr("Enabled")
+		if call, ok := access.(*ast.CallExpr); ok && len(call.Args) == 1 {
+			access = call.Args[0]
+		}
+		if ident, ok := access.(*ast.Ident); !ok || ident.Name != "nil" {
+			value := stringLiteral(access)
+			if value == "" || strings.EqualFold(value, "Disabled") {
+				// Disabled, or set elsewhere; assume it is intended
+				return false
+			}
+		}
+	}
+
+	rulesExpr := compositeField(props, "NetworkRuleSet")
+	if rulesExpr == nil {
+		return true
+	}
+	rules, ok := unwrapAddr(rulesExpr).(*ast.CompositeLit)
+	if !ok {
+		// nil keeps the default Allow; anything else is set elsewhere, so
+		// assume it is intended
+		ident, isIdent := rulesExpr.(*ast.Ident)
+		return isIdent && ident.Name == "nil"
+	}
+	switch action := compositeField(rules, "DefaultAction").(type) {
+	case nil:
+		return true
+	case *ast.BasicLit:
+		return !strings.EqualFold(stringLiteral(action), "Deny")
+	default:
+		return false
+	}
+}
+
+// blobServiceExpr describes a storage.BlobService declaration: the variable
+// of the account it belongs to, if its name is built from one, and its data
+// protection settings
+type blobServiceExpr struct {
+	account    string
+	softDelete bool
+	versioning bool
+}
+
+// inspectBlobService reports whether expr declares a blob service, as a
+// storage.BlobService literal or a NewBlobService call chain, and describes it
+func inspectBlobService(expr ast.Expr) (blobServiceExpr, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return inspectBlobService(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return inspectBlobService(e.X)
+		}
+	case *ast.CompositeLit:
+		sel, ok := e.Type.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "BlobService" {
+			return blobServiceExpr{}, false
+		}
+		service := blobServiceExpr{account: accountVariable(compositeField(e, "Name"))}
+		if props := compositeField(e, "Properties"); props != nil {
+			service.softDelete, service.versioning = blobDataProtection(props)
+		}
+		return service, true
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return blobServiceExpr{}, false
+		}
+		if sel.Sel.Name == "NewBlobService" {
+			if len(e.Args) != 1 {
+				return blobServiceExpr{}, true
+			}
+			return blobServiceExpr{account: accountVariable(e.Args[0])}, true
+		}
+		service, ok := inspectBlobService(sel.X)
+		if ok && sel.Sel.Name == "WithDataProtection" {
+			service.softDelete, service.versioning = true, true
+		}
+		return service, ok
+	}
+	return blobServiceExpr{}, false
+}
+
+// blobDataProtection reports whether the blob service properties expr enable
+// soft delete and versioning. Properties set elsewhere are assumed to.
+func blobDataProtection(expr ast.Expr) (softDelete, versioning bool) {
+	blob, ok := unwrapAddr(expr).(*ast.CompositeLit)
+	if !ok {
+		// Set elsewhere; assume it is intended
+		return true, true
+	}
+	if policy, ok := unwrapAddr(compositeField(blob, "DeleteRetentionPolicy")).(*ast.CompositeLit); ok {
+		softDelete = enabledFlag(compositeField(policy, "Enabled"))
+	} else {
+		softDelete = compositeField(blob, "DeleteRetentionPolicy") != nil
+	}
+	return softDelete, enabledFlag(compositeField(blob, "IsVersioningEnabled"))
+}
+
+// accountVariable returns the variable whose Name a child resource name is
+// built from, such as Logs in Logs.Name + "/default", or ""
+func accountVariable(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.BinaryExpr:
+		return accountVariable(e.X)
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok && e.Sel.Name == "Name" {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// hasTag reports whether tags is a map literal with key set to value (case-insensitive)
+func hasTag(tags ast.Expr, key, value string) bool {
+	lit, ok := tags.(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		k, ok := kv.Key.(*ast.BasicLit)
+		if !ok || strings.Trim(k.Value, "\"`") != key {
+			continue
+		}
+		if v, ok := kv.Value.(*ast.BasicLit); ok {
+			return strings.EqualFold(strings.Trim(v.Value, "\"`"), value)
+		}
+	}
+	return false
+}
+
+// WAZ315 flags NSG rules with a misspelled direction, access or protocol
+type WAZ315 struct{}
+
+// securityRuleEnums lists the values ARM accepts, case-sensitively, for the
+// enumerated fields of an NSG rule
+var securityRuleEnums = []struct {
+	field  string
+	values []string
+}{
+	{"Direction", []string{"Inbound", "Outbound"}},
+	{"Access", []string{"Allow", "Deny"}},
+	{"Protocol", []string{"Tcp", "Udp", "Icmp", "*", "Esp", "Ah"}},
+}
+
+// securityRuleMethods maps the NetworkSecurityGroup methods that add a rule
+// to the index of their direction argument, which access and protocol follow
+var securityRuleMethods = map[string]int{
+	"WithRule":                         2,
+	"WithApplicationSecurityGroupRule": 2,
+}
+
+func (r *WAZ315) ID() string {
+	return "WAZ315"
+}
+
+func (r *WAZ315) Description() string {
+	return "Require valid NSG rule directions (Inbound, Outbound), accesses (Allow, Deny) and protocols (Tcp, Udp, Icmp, *, Esp, Ah)"
+}
+
+func (r *WAZ315) Severity() Severity {
+	return SeverityError
+}
+
+func (r *WAZ315) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []LintResult
+
+	check := func(field string, allowed []string, expr ast.Expr) {
+		lit, ok := expr.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil || strings.HasPrefix(value, "[") || slices.Contains(allowed, value) {
+			return
+		}
+		pos := fset.Position(lit.Pos())
+		results = append(results, LintResult{
+			Rule:     r.ID(),
+			File:     file,
+			Line:     pos.Line,
+			Message:  fmt.Sprintf("Invalid NSG rule %s %q. Use one of: %s (case-sensitive)", field, value, strings.Join(allowed, ", ")),
+			Severity: r.Severity(),
+		})
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if !isSecurityRuleLiteral(n) {
+				return true
+			}
+			for _, enum := range securityRuleEnums {
+				check(enum.field, enum.values, compositeField(n, enum.field))
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			first, ok := securityRuleMethods[sel.Sel.Name]
+			if !ok || len(n.Args) < first+len(securityRuleEnums) {
+				return true
+			}
+			for i, enum := range securityRuleEnums {
+				check(enum.field, enum.values, n.Args[first+i])
+			}
+		}
+		return true
+	})
+
+	return results, nil
+}
+
+// isSecurityRuleLiteral reports whether lit is a network.SecurityRuleProperties literal
+func isSecurityRuleLiteral(lit *ast.CompositeLit) bool {
+	sel, ok := lit.Type.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "SecurityRuleProperties" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "network"
+}
+
+// enabledFlag reports whether the value given for a bool field may be true:
+// false only when the field is unset or the literal false
+func enabledFlag(expr ast.Expr) bool {
+	if expr == nil {
+		return false
+	}
+	ident, ok := expr.(*ast.Ident)
+	return !ok || ident.Name != "false"
+}
+
+// packageFiles parses the Go files of the package of file, node, in name
+// order. Test files and files that do not parse are skipped; node stands in
+// for file.
+func packageFiles(fset *token.FileSet, file string, node *ast.File) ([]*ast.File, error) {
+	var files []*ast.File
+	siblings, err := filepath.Glob(filepath.Join(filepath.Dir(file), "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	for _, sibling := range siblings {
+		if sibling == filepath.Clean(file) {
+			files = append(files, node)
+			continue
+		}
+		if strings.HasSuffix(sibling, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, sibling, nil, parser.PackageClauseOnly)
+		if err != nil || f.Name.Name != node.Name.Name {
+			continue
+		}
+		if f, err = parser.ParseFile(fset, sibling, nil, 0); err == nil {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// packageLiterals maps the package-level variables of file that are
+// initialized with a composite literal, such as shared tag maps, to the literal
+func packageLiterals(file *ast.File) map[string]*ast.CompositeLit {
+	literals := make(map[string]*ast.CompositeLit)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, value := range vs.Values {
+				if lit, ok := value.(*ast.CompositeLit); ok && i < len(vs.Names) {
+					literals[vs.Names[i].Name] = lit
+				}
+			}
+		}
+	}
+	return literals
+}
+
+// resolveLiteral returns the literal that expr names if it is one of the
+// package-level literals, or expr itself
+func resolveLiteral(expr ast.Expr, literals map[string]*ast.CompositeLit) ast.Expr {
+	if ident, ok := expr.(*ast.Ident); ok {
+		if lit, ok := literals[ident.Name]; ok {
+			return lit
+		}
+	}
+	return expr
+}
+
+// compositeField returns the value of the keyed field name in lit, or nil
+func compositeField(lit *ast.CompositeLit, name string) ast.Expr {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == name {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// unwrapAddr strips a leading & from expr
+func unwrapAddr(expr ast.Expr) ast.Expr {
+	if u, ok := expr.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		return u.X
+	}
+	return expr
+}
@@ -1,10 +1,14 @@
 package lint
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/lex00/wetwire-azure-go/resources/keyvault"
 )
 
 // TestWAZ301HTTPSRequired tests the HTTPS-only requirement for storage accounts
@@ -297,3 +301,1289 @@ var MyResource = struct {
 		})
 	}
 }
+
+func TestWAZ307HardcodedAdminPassword(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		expectIssue bool
+	}{
+		{
+			name: "hardcoded password",
+			content: `package main
+
+var MyProfile = struct {
+	AdminPassword string
+}{
+	AdminPassword: "P@ssw0rd123!",
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "wrapped hardcoded password",
+			content: `package main
+
+func ptr(s string) *string { return &s }
+
+var MyProfile = struct {
+	AdminPassword *string
+}{
+	AdminPassword: ptr("P@ssw0rd123!"),
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "secure parameter",
+			content: `package main
+
+func ptr(s string) *string { return &s }
+
+var MyProfile = struct {
+	AdminPassword *string
+}{
+	AdminPassword: ptr("[parameters('adminPassword')]"),
+}
+`,
+			expectIssue: false,
+		},
+		{
+			name: "address of a package-level variable",
+			content: `package main
+
+var (
+	adminUsername = "azureuser"
+	adminPassword = "P@ssw0rd1234!"
+)
+
+var MyProfile = struct {
+	AdminUsername *string
+	AdminPassword *string
+}{
+	AdminUsername: &adminUsername,
+	AdminPassword: &adminPassword,
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "package-level constant",
+			content: `package main
+
+const adminPassword = "P@ssw0rd1234!"
+
+func ptr(s string) *string { return &s }
+
+var MyProfile = struct {
+	AdminPassword *string
+}{
+	AdminPassword: ptr(adminPassword),
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "package-level secure parameter",
+			content: `package main
+
+var adminPassword = "[parameters('adminPassword')]"
+
+var MyProfile = struct {
+	AdminPassword *string
+}{
+	AdminPassword: &adminPassword,
+}
+`,
+			expectIssue: false,
+		},
+		{
+			name: "field named like a package-level string",
+			content: `package main
+
+var adminPassword = "P@ssw0rd1234!"
+
+var secrets = struct {
+	adminPassword *string
+}{}
+
+var MyProfile = struct {
+	AdminPassword *string
+}{
+	AdminPassword: secrets.adminPassword,
+}
+`,
+			expectIssue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ307{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.expectIssue && len(results) == 0 {
+				t.Error("expected lint issue but got none")
+			}
+			if !tt.expectIssue && len(results) > 0 {
+				t.Errorf("expected no lint issues but got %d", len(results))
+			}
+			for _, r := range results {
+				if !strings.Contains(r.Message, "WithSSHPublicKey") {
+					t.Errorf("expected message to suggest WithSSHPublicKey, got %q", r.Message)
+				}
+			}
+
+			if rule.ID() != "WAZ307" {
+				t.Errorf("expected ID WAZ307, got %s", rule.ID())
+			}
+			if rule.Severity() != SeverityError {
+				t.Errorf("expected SeverityError, got %s", rule.Severity())
+			}
+		})
+	}
+}
+
+func TestWAZ309ConfidentialStorageCMK(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		expectIssue bool
+	}{
+		{
+			name: "confidential platform-managed",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "confidential"},
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "confidential explicit Microsoft.Storage key source",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "Confidential"},
+	Properties: &storage.StorageAccountProperties{
+		Encryption: &storage.Encryption{KeySource: "Microsoft.Storage"},
+	},
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "confidential tags from a shared map",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var confidentialTags = map[string]string{"owner": "finance", "data-class": "confidential"}
+
+var Records = storage.NewStorageAccount("records", "eastus", "StorageV2", "Standard_LRS").WithTags(confidentialTags)
+`,
+			expectIssue: true,
+		},
+		{
+			name: "confidential with customer-managed key helper",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.NewStorageAccount("records", "eastus", "StorageV2", "Standard_LRS").
+	WithTags(map[string]string{"data-class": "confidential"}).
+	WithCustomerManagedKey("https://corp-kv.vault.azure.net", "records-key", "")
+`,
+			expectIssue: false,
+		},
+		{
+			name: "confidential with Key Vault key source",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "confidential"},
+	Properties: &storage.StorageAccountProperties{
+		Encryption: &storage.Encryption{KeySource: "Microsoft.Keyvault"},
+	},
+}
+`,
+			expectIssue: false,
+		},
+		{
+			name: "not confidential",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{
+	Name:     "logs",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "internal"},
+}
+`,
+			expectIssue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ309{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.expectIssue && len(results) != 1 {
+				t.Errorf("expected one lint issue, got %d", len(results))
+			}
+			if !tt.expectIssue && len(results) > 0 {
+				t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+			}
+			for _, r := range results {
+				if !strings.Contains(r.Message, "WithCustomerManagedKey") {
+					t.Errorf("expected message to suggest WithCustomerManagedKey, got %q", r.Message)
+				}
+			}
+
+			if rule.ID() != "WAZ309" {
+				t.Errorf("expected ID WAZ309, got %s", rule.ID())
+			}
+			if rule.Severity() != SeverityWarning {
+				t.Errorf("expected SeverityWarning, got %s", rule.Severity())
+			}
+		})
+	}
+}
+
+func TestWAZ310ProductionBlobDataProtection(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		wantMessage string
+	}{
+		{
+			name: "production without blob services",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Orders = storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+	Tags:     map[string]string{"environment": "production"},
+}
+`,
+			wantMessage: "does not enable blob soft delete or blob versioning",
+		},
+		{
+			name: "production missing versioning",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Orders = storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+	Tags:     map[string]string{"environment": "production"},
+	Properties: &storage.StorageAccountProperties{
+		BlobServices: &storage.BlobServiceProperties{
+			DeleteRetentionPolicy: &storage.DeleteRetentionPolicy{Enabled: true, Days: 14},
+		},
+	},
+}
+`,
+			wantMessage: "does not enable blob versioning",
+		},
+		{
+			name: "production with soft delete disabled",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var prodTags = map[string]string{"environment": "Production"}
+
+var Orders = storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+	Tags:     prodTags,
+	Properties: &storage.StorageAccountProperties{
+		BlobServices: &storage.BlobServiceProperties{
+			DeleteRetentionPolicy: &storage.DeleteRetentionPolicy{Enabled: false},
+			IsVersioningEnabled:   true,
+		},
+	},
+}
+`,
+			wantMessage: "does not enable blob soft delete",
+		},
+		{
+			name: "production with data protection helper",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Orders = storage.NewStorageAccount("orders", "eastus", "StorageV2", "Standard_GRS").
+	WithTags(map[string]string{"environment": "production"}).
+	WithBlobDataProtection(14)
+`,
+		},
+		{
+			name: "production with soft delete and versioning",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Orders = storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+	Tags:     map[string]string{"environment": "production"},
+	Properties: &storage.StorageAccountProperties{
+		BlobServices: &storage.BlobServiceProperties{
+			DeleteRetentionPolicy: &storage.DeleteRetentionPolicy{Enabled: true, Days: 14},
+			IsVersioningEnabled:   true,
+		},
+	},
+}
+`,
+		},
+		{
+			name: "not production",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Scratch = storage.StorageAccount{
+	Name:     "scratch",
+	Location: "eastus",
+	Tags:     map[string]string{"environment": "dev"},
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ310{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.wantMessage == "" {
+				if len(results) > 0 {
+					t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected one lint issue, got %d", len(results))
+			}
+			if !strings.Contains(results[0].Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, results[0].Message)
+			}
+			if results[0].Severity != SeverityWarning {
+				t.Errorf("expected SeverityWarning, got %s", results[0].Severity)
+			}
+		})
+	}
+}
+
+// TestWAZ310SeparateBlobService tests that a storage.BlobService declared
+// apart from a production account, in any file of the package, counts toward
+// its data protection
+func TestWAZ310SeparateBlobService(t *testing.T) {
+	account := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Orders = storage.StorageAccount{
+	Name:     "orders",
+	Location: "eastus",
+	Tags:     map[string]string{"environment": "production"},
+}
+`
+	tests := []struct {
+		name        string
+		services    string
+		wantMessage string
+	}{
+		{
+			name: "constructor with data protection",
+			services: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var OrdersBlobs = storage.NewBlobService(Orders.Name).WithDataProtection(14)
+`,
+		},
+		{
+			name: "literal with soft delete and versioning",
+			services: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var OrdersBlobs = storage.BlobService{
+	Name: Orders.Name + "/default",
+	Properties: storage.BlobServiceProperties{
+		DeleteRetentionPolicy: &storage.DeleteRetentionPolicy{Enabled: true, Days: 14},
+		IsVersioningEnabled:   true,
+	},
+}
+`,
+		},
+		{
+			name: "CORS only",
+			services: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var OrdersBlobs = storage.NewBlobService(Orders.Name).
+	WithCORSRule(storage.CORSRule{AllowedOrigins: []string{"https://shop.example.com"}, AllowedMethods: []string{"GET"}})
+`,
+			wantMessage: "does not enable blob soft delete or blob versioning",
+		},
+		{
+			name: "another account",
+			services: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var OtherBlobs = storage.NewBlobService(Other.Name).WithDataProtection(14)
+`,
+			wantMessage: "does not enable blob soft delete or blob versioning",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			accountFile := filepath.Join(tmpDir, "storage.go")
+			if err := os.WriteFile(accountFile, []byte(account), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, "services.go"), []byte(tt.services), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ310{}
+			results, err := rule.Check(accountFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.wantMessage == "" {
+				if len(results) > 0 {
+					t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected one lint issue, got %d", len(results))
+			}
+			if !strings.Contains(results[0].Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, results[0].Message)
+			}
+		})
+	}
+}
+
+// TestWAZ311NSGPortRange tests validation of NSG rule port ranges
+func TestWAZ311NSGPortRange(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		value       string
+		wantMessage string
+	}{
+		{name: "single port", value: `"443"`},
+		{name: "lowest port", value: `"0"`},
+		{name: "highest port", value: `"65535"`},
+		{name: "any port", value: `"*"`},
+		{name: "range", value: `"8080-8090"`},
+		{name: "single port range", value: `"80-80"`},
+		{name: "ARM expression", value: `"[parameters('port')]"`},
+		{name: "inverted range", value: `"8080-80"`, wantMessage: "is inverted (8080 > 80)"},
+		{name: "out of bounds port", value: `"70000"`, wantMessage: "is out of bounds (70000)"},
+		{name: "out of bounds range", value: `"1000-70000"`, wantMessage: "is out of bounds (70000)"},
+		{name: "not a number", value: `"http"`, wantMessage: "is not a port number"},
+		{name: "open range", value: `"80-"`, wantMessage: "is not a port number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AllowWeb = network.SecurityRuleProperties{
+	Protocol:             "Tcp",
+	SourcePortRange:      "*",
+	DestinationPortRange: ` + tt.value + `,
+}
+`
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ311{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.wantMessage == "" {
+				if len(results) > 0 {
+					t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected one lint issue, got %d", len(results))
+			}
+			if !strings.Contains(results[0].Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, results[0].Message)
+			}
+			if results[0].Line != 8 {
+				t.Errorf("expected line 8, got %d", results[0].Line)
+			}
+			if results[0].Severity != SeverityError {
+				t.Errorf("expected SeverityError, got %s", results[0].Severity)
+			}
+		})
+	}
+}
+
+// TestWAZ311NSGPortRanges tests validation of each entry of SourcePortRanges
+// and DestinationPortRanges
+func TestWAZ311NSGPortRanges(t *testing.T) {
+	content := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AllowWeb = network.SecurityRuleProperties{
+	Protocol:              "Tcp",
+	SourcePortRange:       "*",
+	DestinationPortRanges: []string{"80", "443", "9000-8000"},
+}
+`
+	testFile := filepath.Join(t.TempDir(), "nsg.go")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := (&WAZ311{}).Check(testFile)
+	if err != nil {
+		t.Fatalf("Check() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected one lint issue, got %d: %v", len(results), results)
+	}
+	if !strings.Contains(results[0].Message, "DestinationPortRanges='9000-8000'") {
+		t.Errorf("unexpected message %q", results[0].Message)
+	}
+}
+
+// TestWAZ312KeyVaultAccess tests detection of Key Vaults nobody can use and
+// of access policies granting all permissions
+func TestWAZ312KeyVaultAccess(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		content      string
+		wantMessage  string
+		wantSeverity Severity
+	}{
+		{
+			name: "no RBAC and no access policies",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var Secrets = keyvault.Vault{
+	Name:     "app-secrets",
+	Location: "eastus",
+	Properties: keyvault.VaultProperties{
+		TenantID:                "[subscription().tenantId]",
+		EnableRBACAuthorization: false,
+		AccessPolicies:          []keyvault.AccessPolicyEntry{},
+	},
+}
+`,
+			wantMessage:  "RBAC authorization disabled and no access policies",
+			wantSeverity: SeverityWarning,
+		},
+		{
+			name: "no properties",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var Secrets = keyvault.Vault{Name: "app-secrets", Location: "eastus"}
+`,
+			wantMessage:  "RBAC authorization disabled and no access policies",
+			wantSeverity: SeverityWarning,
+		},
+		{
+			name: "all permissions",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var Secrets = keyvault.Vault{
+	Name:     "app-secrets",
+	Location: "eastus",
+	Properties: keyvault.VaultProperties{
+		AccessPolicies: []keyvault.AccessPolicyEntry{{
+			ObjectID: "[parameters('appPrincipalId')]",
+			Permissions: keyvault.Permissions{
+				Secrets: []string{"get", "list"},
+				Keys:    []string{"All"},
+			},
+		}},
+	},
+}
+`,
+			wantMessage:  "grants all keys permissions",
+			wantSeverity: SeverityError,
+		},
+		{
+			name: "RBAC authorization",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var Secrets = keyvault.Vault{
+	Name:     "app-secrets",
+	Location: "eastus",
+	Properties: keyvault.VaultProperties{
+		EnableRBACAuthorization: true,
+	},
+}
+`,
+		},
+		{
+			name: "least privilege access policy",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var Secrets = keyvault.Vault{
+	Name:     "app-secrets",
+	Location: "eastus",
+	Properties: keyvault.VaultProperties{
+		AccessPolicies: []keyvault.AccessPolicyEntry{{
+			ObjectID:    "[parameters('appPrincipalId')]",
+			Permissions: keyvault.Permissions{Secrets: []string{"get", "list"}},
+		}},
+	},
+}
+`,
+		},
+		{
+			name: "aliased import",
+			content: `package main
+
+import kv "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var Secrets = kv.Vault{Name: "app-secrets", Location: "eastus"}
+`,
+			wantMessage:  "RBAC authorization disabled and no access policies",
+			wantSeverity: SeverityWarning,
+		},
+		{
+			name: "other package named keyvault",
+			content: `package main
+
+import "example.com/vaults/keyvault"
+
+var Secrets = keyvault.Vault{Name: "app-secrets"}
+`,
+		},
+		{
+			name: "recovery services vault",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/recoveryservices"
+
+var Backups = recoveryservices.Vault{Name: "backups", Location: "eastus"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ312{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.wantMessage == "" {
+				if len(results) > 0 {
+					t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected one lint issue, got %d: %v", len(results), results)
+			}
+			if !strings.Contains(results[0].Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, results[0].Message)
+			}
+			if results[0].Severity != tt.wantSeverity {
+				t.Errorf("expected %s, got %s", tt.wantSeverity, results[0].Severity)
+			}
+		})
+	}
+}
+
+// TestWAZ312KeyVaultFields tests that the keyvault.Vault fields WAZ312
+// inspects exist, so that the rule's test sources match the real type
+func TestWAZ312KeyVaultFields(t *testing.T) {
+	vault := reflect.TypeOf(keyvault.Vault{})
+	for _, path := range [][]string{
+		{"Properties", "EnableRBACAuthorization"},
+		{"Properties", "AccessPolicies", "Permissions", "Keys"},
+		{"Properties", "AccessPolicies", "Permissions", "Secrets"},
+		{"Properties", "AccessPolicies", "Permissions", "Certificates"},
+		{"Properties", "AccessPolicies", "Permissions", "Storage"},
+	} {
+		typ := vault
+		for _, name := range path {
+			for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+				typ = typ.Elem()
+			}
+			field, ok := typ.FieldByName(name)
+			if !ok {
+				t.Fatalf("keyvault.Vault has no field %s", strings.Join(path, "."))
+			}
+			typ = field.Type
+		}
+	}
+}
+
+// TestWAZ313AddressOverlap tests detection of overlapping address ranges
+// within a virtual network
+func TestWAZ313AddressOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantMessage string
+	}{
+		{
+			name: "overlapping subnets",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.VirtualNetwork{
+	Name:     "app-vnet",
+	Location: "eastus",
+	Properties: network.VirtualNetworkProperties{
+		AddressSpace: network.AddressSpace{AddressPrefixes: []string{"10.0.0.0/8"}},
+		Subnets: []network.Subnet{
+			{Name: "web", Properties: network.SubnetProperties{AddressPrefix: "10.0.1.0/24"}},
+			{Name: "app", Properties: network.SubnetProperties{AddressPrefix: "10.0.0.0/16"}},
+		},
+	},
+}
+`,
+			wantMessage: `Subnets "web" (10.0.1.0/24) and "app" (10.0.0.0/16) of virtual network "app-vnet" overlap`,
+		},
+		{
+			name: "distinct subnets",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.VirtualNetwork{
+	Name:     "app-vnet",
+	Location: "eastus",
+	Properties: network.VirtualNetworkProperties{
+		AddressSpace: network.AddressSpace{AddressPrefixes: []string{"10.0.0.0/16"}},
+		Subnets: []network.Subnet{
+			{Name: "web", Properties: network.SubnetProperties{AddressPrefix: "10.0.1.0/24"}},
+			{Name: "app", Properties: network.SubnetProperties{AddressPrefix: "10.0.2.0/24"}},
+		},
+	},
+}
+`,
+		},
+		{
+			name: "overlapping WithSubnet calls",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.NewVirtualNetwork("app-vnet", "eastus", []string{"10.0.0.0/16"}).
+	WithSubnet("web", "10.0.0.0/16").
+	WithSubnet("app", "10.0.1.0/24")
+`,
+			wantMessage: `Subnets "web" (10.0.0.0/16) and "app" (10.0.1.0/24) of virtual network "app-vnet" overlap`,
+		},
+		{
+			name: "overlapping address prefixes",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.NewVirtualNetwork("app-vnet", "eastus", []string{"10.0.0.0/16", "10.0.128.0/17"})
+`,
+			wantMessage: `Address prefixes 10.0.0.0/16 and 10.0.128.0/17 of virtual network "app-vnet" overlap`,
+		},
+		{
+			name: "overlapping IPv6 subnets",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.NewVirtualNetwork("app-vnet", "eastus", []string{"10.0.0.0/16", "fd00:db8::/48"}).
+	WithSubnet("web", "fd00:db8:0:1::/64").
+	WithSubnet("app", "fd00:db8::/56")
+`,
+			wantMessage: `Subnets "web" (fd00:db8:0:1::/64) and "app" (fd00:db8::/56) of virtual network "app-vnet" overlap`,
+		},
+		{
+			name: "IPv4 and IPv6 subnets",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.NewVirtualNetwork("app-vnet", "eastus", []string{"10.0.0.0/16", "fd00:db8::/48"}).
+	WithSubnet("web", "10.0.0.0/24").
+	WithSubnet("web-v6", "fd00:db8::/64")
+`,
+		},
+		{
+			name: "same ranges in different virtual networks",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var HubVNet = network.NewVirtualNetwork("hub-vnet", "eastus", []string{"10.0.0.0/16"}).
+	WithSubnet("web", "10.0.1.0/24")
+
+var SpokeVNet = network.NewVirtualNetwork("spoke-vnet", "westus", []string{"10.0.0.0/16"}).
+	WithSubnet("web", "10.0.1.0/24")
+`,
+		},
+		{
+			name: "ARM expression prefix",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.NewVirtualNetwork("app-vnet", "eastus", []string{"10.0.0.0/16"}).
+	WithSubnet("web", "[parameters('webPrefix')]").
+	WithSubnet("app", "10.0.1.0/24")
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "test.go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ313{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.wantMessage == "" {
+				if len(results) > 0 {
+					t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected one lint issue, got %d: %v", len(results), results)
+			}
+			if !strings.Contains(results[0].Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, results[0].Message)
+			}
+			if results[0].Severity != SeverityError {
+				t.Errorf("expected SeverityError, got %s", results[0].Severity)
+			}
+		})
+	}
+}
+
+// TestWAZ313AddressOverlapAcrossFiles tests that a standalone subnet is
+// checked against the inline subnets of its virtual network in another file
+// of the package, and that the overlap is reported once
+func TestWAZ313AddressOverlapAcrossFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"network.go": `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.NewVirtualNetwork("app-vnet", "eastus", []string{"10.0.0.0/16"}).
+	WithSubnet("web", "10.0.1.0/24")
+`,
+		"subnets.go": `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var DataSubnet = network.NewVirtualNetworkSubnet("app-vnet", network.NewSubnet("data", "10.0.0.0/16").WithNSG("nsg"))
+`,
+		"other.go": `package other
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var OtherSubnet = network.NewVirtualNetworkSubnet("app-vnet", network.NewSubnet("other", "10.0.1.0/24"))
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	linter := NewLinterWithOptions(Options{OnlyRules: []string{"WAZ313"}})
+	results, err := linter.CheckFile(filepath.Join(tmpDir, "subnets.go"))
+	if err != nil {
+		t.Fatalf("CheckFile() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected one lint issue, got %d: %v", len(results), results)
+	}
+	want := `Subnets "web" (10.0.1.0/24) and "data" (10.0.0.0/16) of virtual network "app-vnet" overlap`
+	if !strings.Contains(results[0].Message, want) {
+		t.Errorf("expected message to contain %q, got %q", want, results[0].Message)
+	}
+	if results[0].Line != 5 {
+		t.Errorf("expected line 5, got %d", results[0].Line)
+	}
+
+	results, err = linter.CheckFile(filepath.Join(tmpDir, "network.go"))
+	if err != nil {
+		t.Fatalf("CheckFile() error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected the overlap to be reported only in subnets.go, got %v", results)
+	}
+}
+
+// TestWAZ314ConfidentialStorageNetworkRules tests detection of confidential
+// storage accounts reachable from any network
+func TestWAZ314ConfidentialStorageNetworkRules(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		expectIssue bool
+	}{
+		{
+			name: "confidential default action Allow",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "confidential"},
+	Properties: &storage.StorageAccountProperties{
+		NetworkRuleSet: &storage.NetworkRuleSet{DefaultAction: "Allow"},
+	},
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "confidential without network rules",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var confidentialTags = map[string]string{"owner": "finance", "data-class": "confidential"}
+
+var Records = storage.NewStorageAccount("records", "eastus", "StorageV2", "Standard_LRS").WithTags(confidentialTags)
+`,
+			expectIssue: true,
+		},
+		{
+			name: "confidential network rules without default action",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "Confidential"},
+	Properties: &storage.StorageAccountProperties{
+		NetworkRuleSet: &storage.NetworkRuleSet{
+			IPRules: []storage.IPRule{{Value: "203.0.113.0/24"}},
+		},
+	},
+}
+`,
+			expectIssue: true,
+		},
+		{
+			name: "confidential default action Deny",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "confidential"},
+	Properties: &storage.StorageAccountProperties{
+		NetworkRuleSet: &storage.NetworkRuleSet{DefaultAction: "Deny"},
+	},
+}
+`,
+			expectIssue: false,
+		},
+		{
+			name: "confidential public network access disabled",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+func strPtr(s string) *string { return &s }
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "confidential"},
+	Properties: &storage.StorageAccountProperties{
+		PublicNetworkAccess: strPtr("Disabled"),
+	},
+}
+`,
+			expectIssue: false,
+		},
+		{
+			name: "confidential network rules set elsewhere",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Records = storage.StorageAccount{
+	Name:     "records",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "confidential"},
+	Properties: &storage.StorageAccountProperties{
+		NetworkRuleSet: privateRules,
+	},
+}
+`,
+			expectIssue: false,
+		},
+		{
+			name: "not confidential default action Allow",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{
+	Name:     "logs",
+	Location: "eastus",
+	Tags:     map[string]string{"data-class": "internal"},
+	Properties: &storage.StorageAccountProperties{
+		NetworkRuleSet: &storage.NetworkRuleSet{DefaultAction: "Allow"},
+	},
+}
+`,
+			expectIssue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ314{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.expectIssue && len(results) != 1 {
+				t.Errorf("expected one lint issue, got %d", len(results))
+			}
+			if !tt.expectIssue && len(results) > 0 {
+				t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+			}
+			for _, r := range results {
+				if !strings.Contains(r.Message, "DefaultAction") {
+					t.Errorf("expected message to suggest DefaultAction, got %q", r.Message)
+				}
+			}
+
+			if rule.ID() != "WAZ314" {
+				t.Errorf("expected ID WAZ314, got %s", rule.ID())
+			}
+			if rule.Severity() != SeverityWarning {
+				t.Errorf("expected SeverityWarning, got %s", rule.Severity())
+			}
+		})
+	}
+}
+
+// TestWAZ315NSGRuleEnums tests validation of NSG rule directions, accesses
+// and protocols
+func TestWAZ315NSGRuleEnums(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		direction   string
+		access      string
+		protocol    string
+		wantMessage string
+	}{
+		{name: "valid", direction: "Inbound", access: "Allow", protocol: "Tcp"},
+		{name: "valid outbound deny any", direction: "Outbound", access: "Deny", protocol: "*"},
+		{name: "valid esp", direction: "Inbound", access: "Allow", protocol: "Esp"},
+		{name: "ARM expression", direction: "[parameters('direction')]", access: "Allow", protocol: "Udp"},
+		{name: "misspelled direction", direction: "Inboud", access: "Allow", protocol: "Tcp", wantMessage: `Invalid NSG rule Direction "Inboud". Use one of: Inbound, Outbound`},
+		{name: "lowercase direction", direction: "outbound", access: "Allow", protocol: "Tcp", wantMessage: `Invalid NSG rule Direction "outbound"`},
+		{name: "invalid access", direction: "Inbound", access: "Permit", protocol: "Tcp", wantMessage: `Invalid NSG rule Access "Permit". Use one of: Allow, Deny`},
+		{name: "lowercase access", direction: "Inbound", access: "deny", protocol: "Tcp", wantMessage: `Invalid NSG rule Access "deny"`},
+		{name: "invalid protocol", direction: "Inbound", access: "Allow", protocol: "HTTP", wantMessage: `Invalid NSG rule Protocol "HTTP". Use one of: Tcp, Udp, Icmp, *, Esp, Ah`},
+		{name: "uppercase protocol", direction: "Inbound", access: "Allow", protocol: "TCP", wantMessage: `Invalid NSG rule Protocol "TCP"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := fmt.Sprintf(`package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var WebNSG = network.NetworkSecurityGroup{
+	Name: "web-nsg",
+	Properties: network.NetworkSecurityGroupProperties{
+		SecurityRules: []network.SecurityRule{
+			{
+				Name: "allow-https",
+				Properties: network.SecurityRuleProperties{
+					Priority:  100,
+					Direction: %q,
+					Access:    %q,
+					Protocol:  %q,
+				},
+			},
+		},
+	},
+}
+
+var AppNSG = network.NewNetworkSecurityGroup("app-nsg", "eastus").
+	WithRule("allow-https", 100, %q, %q, %q, "*", "443", "10.0.0.0/8", "*")
+`, tt.direction, tt.access, tt.protocol, tt.direction, tt.access, tt.protocol)
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ315{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.wantMessage == "" {
+				if len(results) > 0 {
+					t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+				}
+				return
+			}
+			// Once in the literal and once in the WithRule call
+			if len(results) != 2 {
+				t.Fatalf("expected two lint issues, got %d: %v", len(results), results)
+			}
+			for _, r := range results {
+				if !strings.Contains(r.Message, tt.wantMessage) {
+					t.Errorf("expected message to contain %q, got %q", tt.wantMessage, r.Message)
+				}
+				if r.Severity != SeverityError {
+					t.Errorf("expected SeverityError, got %s", r.Severity)
+				}
+			}
+			if results[1].Line != 23 {
+				t.Errorf("expected the WithRule issue on line 23, got %d", results[1].Line)
+			}
+		})
+	}
+}
+
+// TestWAZ315OtherProtocols tests that Protocol fields of other types, such
+// as load balancer probes, are not checked
+func TestWAZ315OtherProtocols(t *testing.T) {
+	content := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var Probe = network.Probe{Protocol: "Http"}
+`
+	testFile := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := (&WAZ315{}).Check(testFile)
+	if err != nil {
+		t.Fatalf("Check() error: %v", err)
+	}
+	if len(results) > 0 {
+		t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+	}
+}
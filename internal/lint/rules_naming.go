@@ -0,0 +1,109 @@
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/naming"
+	coreast "github.com/lex00/wetwire-core-go/ast"
+)
+
+// WAZ402 flags hardcoded names of resources whose names must be unique
+// across Azure, such as storage accounts and key vaults. It is off by
+// default (see Options.CheckGlobalNames).
+type WAZ402 struct{}
+
+func (r *WAZ402) ID() string {
+	return "WAZ402"
+}
+
+func (r *WAZ402) Description() string {
+	return "Use generated names (naming.Unique or uniqueString) for resources whose names are global"
+}
+
+func (r *WAZ402) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *WAZ402) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := coreast.ExtractImports(node)
+
+	var results []LintResult
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		var typeExpr, name ast.Expr
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			typeExpr, name = n.Type, compositeField(n, "Name")
+		case *ast.CallExpr:
+			// Constructors such as storage.NewStorageAccount take the name first
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || !strings.HasPrefix(sel.Sel.Name, "New") || len(n.Args) == 0 {
+				return true
+			}
+			typeExpr = &ast.SelectorExpr{X: sel.X, Sel: ast.NewIdent(strings.TrimPrefix(sel.Sel.Name, "New"))}
+			name = n.Args[0]
+		default:
+			return true
+		}
+
+		azureType := resourceTypeOf(typeExpr, imports)
+		if azureType == "" || !naming.GloballyUnique(azureType) {
+			return true
+		}
+		value, ok := staticName(name)
+		if !ok {
+			return true
+		}
+
+		pos := fset.Position(name.Pos())
+		results = append(results, LintResult{
+			Rule:     r.ID(),
+			File:     file,
+			Line:     pos.Line,
+			Message:  fmt.Sprintf("%s name %q is hardcoded, but these names are global and fixed ones collide across environments. Use naming.Unique(%q).For(%q).String()", azureType, value, value, azureType),
+			Severity: r.Severity(),
+		})
+		return true
+	})
+
+	return results, nil
+}
+
+// resourceTypeOf returns the Azure resource type of the Go type typeExpr,
+// such as storage.StorageAccount, or "" if it is not a resource type
+func resourceTypeOf(typeExpr ast.Expr, imports map[string]string) string {
+	typeName, pkgAlias := coreast.ExtractTypeName(typeExpr)
+	importPath, ok := imports[pkgAlias]
+	if typeName == "" || !ok {
+		return ""
+	}
+	azureType, _ := discover.LookupResourceType(importPath, typeName)
+	return azureType
+}
+
+// staticName returns the value of name if it is a string literal that is
+// the same in every deployment: not an ARM expression such as
+// "[parameters('name')]" or one using uniqueString()
+func staticName(name ast.Expr) (string, bool) {
+	lit, ok := name.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil || value == "" || strings.HasPrefix(value, "[") {
+		return "", false
+	}
+	return value, true
+}
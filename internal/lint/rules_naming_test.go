@@ -0,0 +1,163 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWAZ402GlobalNames tests detection of hardcoded names of resources
+// whose names are global
+func TestWAZ402GlobalNames(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		content     string
+		wantMessage string
+	}{
+		{
+			name: "static storage name",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{
+	Name:     "corplogs",
+	Location: "eastus",
+}
+`,
+			wantMessage: `Microsoft.Storage/storageAccounts name "corplogs" is hardcoded`,
+		},
+		{
+			name: "static key vault name in constructor",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+var Secrets = keyvault.NewVault("corp-kv", "eastus").WithPurgeProtection()
+`,
+			wantMessage: `Microsoft.KeyVault/vaults name "corp-kv" is hardcoded`,
+		},
+		{
+			name: "unique storage name",
+			content: `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/naming"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Logs = storage.StorageAccount{
+	Name:     naming.Unique("logs").For("Microsoft.Storage/storageAccounts").String(),
+	Location: "eastus",
+}
+`,
+		},
+		{
+			name: "uniqueString expression",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{
+	Name:     "[concat('logs', uniqueString(resourceGroup().id))]",
+	Location: "eastus",
+}
+`,
+		},
+		{
+			name: "parameterized name",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.NewStorageAccount("[parameters('logsName')]", "eastus", "StorageV2", "Standard_LRS")
+`,
+		},
+		{
+			name: "name that is not global",
+			content: `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/network"
+
+var AppVNet = network.VirtualNetwork{Name: "app-vnet", Location: "eastus"}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			rule := &WAZ402{}
+			results, err := rule.Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+
+			if tt.wantMessage == "" {
+				if len(results) > 0 {
+					t.Errorf("expected no lint issues but got %d: %v", len(results), results)
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("expected one lint issue, got %d: %v", len(results), results)
+			}
+			if !strings.Contains(results[0].Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, results[0].Message)
+			}
+			if results[0].Severity != SeverityWarning {
+				t.Errorf("expected warning, got %s", results[0].Severity)
+			}
+		})
+	}
+}
+
+// TestWAZ402OptIn tests that WAZ402 only runs when enabled
+func TestWAZ402OptIn(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "main.go")
+	content := `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{Name: "corplogs", Location: "eastus", Tags: map[string]string{"env": "dev"}}
+`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		opts Options
+		want bool
+	}{
+		{"default", Options{}, false},
+		{"check global names", Options{CheckGlobalNames: true}, true},
+		{"only", Options{OnlyRules: []string{"WAZ402"}}, true},
+		{"disabled", Options{CheckGlobalNames: true, DisabledRules: []string{"WAZ402"}}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := NewLinterWithOptions(tt.opts).CheckFile(testFile)
+			if err != nil {
+				t.Fatalf("CheckFile() error: %v", err)
+			}
+			got := false
+			for _, r := range results {
+				got = got || r.Rule == "WAZ402"
+			}
+			if got != tt.want {
+				t.Errorf("WAZ402 reported = %v, want %v: %v", got, tt.want, results)
+			}
+		})
+	}
+
+	if unknown := UnknownRuleIDs([]string{"WAZ402"}); len(unknown) != 0 {
+		t.Errorf("expected WAZ402 to be a known rule, got unknown %v", unknown)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	coreast "github.com/lex00/wetwire-core-go/ast"
 )
 
 // WAZ020 enforces top-level resource declarations
@@ -305,3 +308,76 @@ func (r *WAZ022) Check(file string) ([]LintResult, error) {
 
 	return results, nil
 }
+
+// WAZ104 flags intrinsics.ResourceRef calls in the Properties of typed
+// resource declarations, which build does not write to the template; those in
+// template.RawResource properties are written as resourceId() expressions
+type WAZ104 struct{}
+
+func (r *WAZ104) ID() string {
+	return "WAZ104"
+}
+
+func (r *WAZ104) Description() string {
+	return "Flag intrinsics.ResourceRef in resource properties that build does not write"
+}
+
+func (r *WAZ104) Severity() Severity {
+	return SeverityWarning
+}
+
+func (r *WAZ104) Check(file string) ([]LintResult, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := coreast.ExtractImports(node)
+
+	var results []LintResult
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		if resourceTypeOf(lit.Type, imports) == "" {
+			return true
+		}
+		typeName, _ := coreast.ExtractTypeName(lit.Type)
+		properties := compositeField(lit, "Properties")
+		if properties == nil {
+			return true
+		}
+
+		ast.Inspect(properties, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !isResourceRefCall(call, imports) {
+				return true
+			}
+			pos := fset.Position(call.Pos())
+			results = append(results, LintResult{
+				Rule:     r.ID(),
+				File:     file,
+				Line:     pos.Line,
+				Message:  fmt.Sprintf("intrinsics.ResourceRef in the Properties of %s only adds a dependsOn entry: build does not write the properties of typed resources to the template; declare the resource as a template.RawResource to write it", typeName),
+				Severity: r.Severity(),
+			})
+			return false
+		})
+		return true
+	})
+
+	return results, nil
+}
+
+// isResourceRefCall reports whether call is intrinsics.ResourceRef(...)
+func isResourceRefCall(call *ast.CallExpr, imports map[string]string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "ResourceRef" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && imports[pkg.Name] == discover.IntrinsicsImportPath
+}
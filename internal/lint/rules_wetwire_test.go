@@ -291,3 +291,92 @@ var MyStorage = storage.StorageAccount{
 		})
 	}
 }
+
+func TestWAZ104ResourceRefInProperties(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		content    string
+		wantIssues int
+	}{
+		{
+			name: "typed resource properties",
+			content: `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+)
+
+var AppNIC = network.NetworkInterface{
+	Name: "app-nic",
+	Properties: network.NetworkInterfaceProperties{
+		IPConfigurations: []network.IPConfiguration{{
+			Name: "ipconfig1",
+			Properties: network.IPConfigurationProperties{
+				Subnet: network.NewSubResource(intrinsics.ResourceRef("AppVNet", "subnets", "app")),
+			},
+		}},
+	},
+}
+`,
+			wantIssues: 1,
+		},
+		{
+			name: "raw resource properties",
+			content: `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/template"
+)
+
+var Workspace = template.RawResource{
+	Type:       "Microsoft.HealthcareApis/workspaces",
+	APIVersion: "2023-11-01",
+	Name:       "health",
+	Properties: map[string]any{"subnetId": intrinsics.ResourceRef("AppVNet", "subnets", "app")},
+}
+`,
+			wantIssues: 0,
+		},
+		{
+			name: "scope and private endpoint",
+			content: `package main
+
+import (
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/authorization"
+	"github.com/lex00/wetwire-azure-go/resources/storage"
+)
+
+var Orders = (&storage.StorageAccount{Name: "orders"}).
+	WithPrivateEndpoint(intrinsics.ResourceRef("AppVNet", "subnets", "pe"), "blob")
+
+var OrdersLock = authorization.ManagementLock{
+	Name:  "orders-lock",
+	Scope: intrinsics.ResourceRef("Orders"),
+}
+`,
+			wantIssues: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(tt.name, " ", "_")+".go")
+			if err := os.WriteFile(testFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := (&WAZ104{}).Check(testFile)
+			if err != nil {
+				t.Fatalf("Check() error: %v", err)
+			}
+			if len(results) != tt.wantIssues {
+				t.Errorf("expected %d issues, got %d: %+v", tt.wantIssues, len(results), results)
+			}
+		})
+	}
+}
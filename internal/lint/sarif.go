@@ -0,0 +1,167 @@
+package lint
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// SARIF 2.1.0 constants
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFLog is a SARIF 2.1.0 log, the format read by GitHub code scanning.
+// Only the subset of the format needed to report lint results is modeled.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is a single run of the linter
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the linter and its rules
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool component that produced the results
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a lint rule
+type SARIFRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     SARIFMessage           `json:"shortDescription"`
+	DefaultConfiguration SARIFRuleConfiguration `json:"defaultConfiguration"`
+}
+
+// SARIFRuleConfiguration holds the default level of a rule
+type SARIFRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFResult is a single lint issue
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFMessage is a plain text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFLocation is the location of a result
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a file and a region within it
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation identifies a file by URI
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is a line and optional column in a file
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIFLevel maps a lint severity to a SARIF level
+func SARIFLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// NewSARIFLog converts lint results into a SARIF log whose rules section
+// describes AllRules(). File paths under baseDir are reported relative to it,
+// as GitHub code scanning expects paths relative to the repository root.
+func NewSARIFLog(results []LintResult, toolVersion, baseDir string) *SARIFLog {
+	rules := AllRules()
+	driver := SARIFDriver{
+		Name:           "wetwire-azure",
+		Version:        toolVersion,
+		InformationURI: "https://github.com/lex00/wetwire-azure-go",
+		Rules:          make([]SARIFRule, 0, len(rules)),
+	}
+	ruleIndex := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		ruleIndex[rule.ID()] = i
+		driver.Rules = append(driver.Rules, SARIFRule{
+			ID:                   rule.ID(),
+			ShortDescription:     SARIFMessage{Text: rule.Description()},
+			DefaultConfiguration: SARIFRuleConfiguration{Level: SARIFLevel(rule.Severity())},
+		})
+	}
+
+	sarifResults := make([]SARIFResult, 0, len(results))
+	for _, r := range results {
+		result := SARIFResult{
+			RuleID:  r.Rule,
+			Level:   SARIFLevel(r.Severity),
+			Message: SARIFMessage{Text: r.Message},
+		}
+		if i, ok := ruleIndex[r.Rule]; ok {
+			result.RuleIndex = &i
+		}
+		if r.File != "" {
+			location := SARIFPhysicalLocation{
+				ArtifactLocation: SARIFArtifactLocation{URI: sarifURI(r.File, baseDir)},
+			}
+			if r.Line > 0 {
+				location.Region = &SARIFRegion{StartLine: r.Line, StartColumn: r.Column}
+			}
+			result.Locations = []SARIFLocation{{PhysicalLocation: location}}
+		}
+		sarifResults = append(sarifResults, result)
+	}
+
+	return &SARIFLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs: []SARIFRun{{
+			Tool:    SARIFTool{Driver: driver},
+			Results: sarifResults,
+		}},
+	}
+}
+
+// sarifURI returns file relative to baseDir with forward slashes, or an
+// absolute file URI if it lies outside baseDir.
+func sarifURI(file, baseDir string) string {
+	if baseDir != "" && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(baseDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	if !filepath.IsAbs(file) {
+		return filepath.ToSlash(file)
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(file)}).String()
+}
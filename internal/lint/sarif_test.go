@@ -0,0 +1,94 @@
+package lint
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSARIFLevel(t *testing.T) {
+	assert.Equal(t, "error", SARIFLevel(SeverityError))
+	assert.Equal(t, "warning", SARIFLevel(SeverityWarning))
+	assert.Equal(t, "note", SARIFLevel(SeverityInfo))
+}
+
+func TestNewSARIFLog(t *testing.T) {
+	baseDir := t.TempDir()
+	results := []LintResult{
+		{Rule: "WAZ004", File: filepath.Join(baseDir, "infra", "main.go"), Line: 12, Column: 5, Message: "duplicate", Severity: SeverityError},
+		{Rule: "WAZ301", File: filepath.Join(baseDir, "storage.go"), Line: 3, Message: "https", Severity: SeverityWarning},
+		{Rule: "WAZ001", File: "relative/net.go", Line: 7, Message: "location", Severity: SeverityInfo},
+	}
+
+	log := NewSARIFLog(results, "1.2.3", baseDir)
+
+	assert.Equal(t, SARIFVersion, log.Version)
+	assert.Equal(t, SARIFSchema, log.Schema)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	// The rules section describes every rule
+	driver := run.Tool.Driver
+	assert.Equal(t, "wetwire-azure", driver.Name)
+	assert.Equal(t, "1.2.3", driver.Version)
+	require.Len(t, driver.Rules, len(AllRules()))
+	for i, rule := range AllRules() {
+		assert.Equal(t, rule.ID(), driver.Rules[i].ID)
+		assert.Equal(t, rule.Description(), driver.Rules[i].ShortDescription.Text)
+		assert.Equal(t, SARIFLevel(rule.Severity()), driver.Rules[i].DefaultConfiguration.Level)
+	}
+
+	require.Len(t, run.Results, 3)
+
+	first := run.Results[0]
+	assert.Equal(t, "WAZ004", first.RuleID)
+	assert.Equal(t, "error", first.Level)
+	assert.Equal(t, "duplicate", first.Message.Text)
+	require.NotNil(t, first.RuleIndex)
+	assert.Equal(t, "WAZ004", driver.Rules[*first.RuleIndex].ID)
+	require.Len(t, first.Locations, 1)
+	location := first.Locations[0].PhysicalLocation
+	assert.Equal(t, "infra/main.go", location.ArtifactLocation.URI)
+	assert.Equal(t, &SARIFRegion{StartLine: 12, StartColumn: 5}, location.Region)
+
+	assert.Equal(t, "warning", run.Results[1].Level)
+	assert.Equal(t, "storage.go", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+
+	assert.Equal(t, "note", run.Results[2].Level)
+	assert.Equal(t, "relative/net.go", run.Results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestNewSARIFLog_JSON(t *testing.T) {
+	log := NewSARIFLog([]LintResult{
+		{Rule: "WAZ301", File: "/outside/storage.go", Line: 3, Message: "https", Severity: SeverityWarning},
+	}, "", "/repo")
+
+	data, err := json.Marshal(log)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	assert.Equal(t, "2.1.0", doc["version"])
+	assert.Contains(t, doc, "$schema")
+	run := doc["runs"].([]any)[0].(map[string]any)
+	driver := run["tool"].(map[string]any)["driver"].(map[string]any)
+	assert.NotContains(t, driver, "version")
+
+	result := run["results"].([]any)[0].(map[string]any)
+	assert.Equal(t, "WAZ301", result["ruleId"])
+	assert.Equal(t, "warning", result["level"])
+	assert.Equal(t, map[string]any{"text": "https"}, result["message"])
+	physical := result["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)
+	assert.Equal(t, map[string]any{"uri": "file:///outside/storage.go"}, physical["artifactLocation"])
+	assert.Equal(t, map[string]any{"startLine": float64(3)}, physical["region"])
+}
+
+func TestNewSARIFLog_NoResults(t *testing.T) {
+	data, err := json.Marshal(NewSARIFLog(nil, "dev", ""))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"results":[]`)
+}
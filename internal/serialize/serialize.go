@@ -10,10 +10,41 @@ import (
 
 // ToARMResource converts a Go struct resource to a map suitable for ARM template JSON.
 // It respects JSON struct tags and handles nested structures, arrays, and ARM intrinsics.
+// An intrinsics.Copy becomes its Resource with an ARM copy element.
 func ToARMResource(resource any) map[string]any {
+	switch loop := resource.(type) {
+	case intrinsics.Copy:
+		return copyToMap(loop)
+	case *intrinsics.Copy:
+		if loop == nil {
+			return nil
+		}
+		return copyToMap(*loop)
+	}
 	return structToMap(reflect.ValueOf(resource))
 }
 
+// copyToMap converts the resource of a copy loop and adds the copy element.
+func copyToMap(loop intrinsics.Copy) map[string]any {
+	result := structToMap(reflect.ValueOf(loop.Resource))
+	if result == nil {
+		return nil
+	}
+
+	element := map[string]any{
+		"name":  loop.Name,
+		"count": SerializeValue(loop.Count),
+	}
+	if loop.Mode != "" {
+		element["mode"] = loop.Mode
+	}
+	if loop.BatchSize != 0 {
+		element["batchSize"] = loop.BatchSize
+	}
+	result["copy"] = element
+	return result
+}
+
 // ToARMTemplate creates a complete ARM template with the given resources.
 func ToARMTemplate(resources []any) map[string]any {
 	armResources := make([]any, 0, len(resources))
@@ -134,7 +165,9 @@ func convertValue(v reflect.Value) any {
 		return structToMap(v)
 
 	case reflect.Slice, reflect.Array:
-		if v.IsNil() || v.Len() == 0 {
+		// Nil slices are omitted; empty slices are kept so free-form maps
+		// round-trip (structToMap still drops empty slice fields)
+		if v.Kind() == reflect.Slice && v.IsNil() {
 			return nil
 		}
 		result := make([]any, v.Len())
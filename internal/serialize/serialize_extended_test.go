@@ -395,18 +395,37 @@ func TestAllIntrinsicTypes(t *testing.T) {
 	}
 }
 
+// TestArithmeticAndArrayIntrinsics tests that function intrinsics serialize
+// with their arguments nested as expressions
+func TestArithmeticAndArrayIntrinsics(t *testing.T) {
+	assert.Equal(t, "[add(length(parameters('zones')), 1)]",
+		SerializeValue(intrinsics.Add(intrinsics.Length(intrinsics.Parameters("zones")), 1)))
+	assert.Equal(t, "[range(0, 3)]", SerializeValue(intrinsics.Range(0, 3)))
+
+	type resource struct {
+		Name string `json:"name"`
+	}
+	loop := intrinsics.Copy{
+		Name:     "zonal",
+		Count:    intrinsics.Length(intrinsics.Parameters("zones")),
+		Resource: resource{Name: "vm"},
+	}
+	result := ToARMResource(loop)
+	assert.Equal(t, map[string]any{"name": "zonal", "count": "[length(parameters('zones'))]"}, result["copy"])
+}
+
 // TestConcat tests Concat intrinsic serialization
 func TestConcat(t *testing.T) {
 	concat := intrinsics.Concat{Values: []any{"a", "b"}}
 	result := SerializeValue(concat)
-	assert.Equal(t, "[concat(...)]", result)
+	assert.Equal(t, "[concat('a', 'b')]", result)
 }
 
 // TestUniqueString tests UniqueString intrinsic serialization
 func TestUniqueString(t *testing.T) {
-	unique := intrinsics.UniqueString{Values: []string{"a", "b"}}
+	unique := intrinsics.UniqueString{Values: []string{"[resourceGroup().id]", "b"}}
 	result := SerializeValue(unique)
-	assert.Equal(t, "[uniqueString(...)]", result)
+	assert.Equal(t, "[uniqueString(resourceGroup().id, 'b')]", result)
 }
 
 // TestEmptyARMTemplate tests ARM template with no resources
@@ -5,8 +5,27 @@ import (
 	"testing"
 
 	"github.com/lex00/wetwire-azure-go/intrinsics"
+	"github.com/lex00/wetwire-azure-go/resources/aks"
+	"github.com/lex00/wetwire-azure-go/resources/app"
+	"github.com/lex00/wetwire-azure-go/resources/cognitiveservices"
 	"github.com/lex00/wetwire-azure-go/resources/compute"
+	"github.com/lex00/wetwire-azure-go/resources/containerinstance"
+	"github.com/lex00/wetwire-azure-go/resources/dashboard"
+	"github.com/lex00/wetwire-azure-go/resources/datafactory"
+	"github.com/lex00/wetwire-azure-go/resources/eventgrid"
+	"github.com/lex00/wetwire-azure-go/resources/insights"
+	"github.com/lex00/wetwire-azure-go/resources/keyvault"
+	"github.com/lex00/wetwire-azure-go/resources/logic"
+	"github.com/lex00/wetwire-azure-go/resources/managedidentity"
+	"github.com/lex00/wetwire-azure-go/resources/maps"
+	"github.com/lex00/wetwire-azure-go/resources/network"
+	"github.com/lex00/wetwire-azure-go/resources/policy"
+	"github.com/lex00/wetwire-azure-go/resources/recoveryservices"
+	"github.com/lex00/wetwire-azure-go/resources/signalr"
 	"github.com/lex00/wetwire-azure-go/resources/storage"
+	"github.com/lex00/wetwire-azure-go/resources/synapse"
+	"github.com/lex00/wetwire-azure-go/resources/web"
+	"github.com/lex00/wetwire-azure-go/resources/webpubsub"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -162,6 +181,25 @@ func TestIntrinsicReferenceProperty(t *testing.T) {
 	assert.Equal(t, "[reference('mystorageaccount', '2021-04-01').primaryEndpoints.blob]", result)
 }
 
+// TestIntrinsicReferenceFull tests serialization of the full reference form
+func TestIntrinsicReferenceFull(t *testing.T) {
+	ref := intrinsics.RefFull("myapp", "2022-03-01")
+
+	result := SerializeValue(ref)
+
+	assert.Equal(t, "[reference('myapp', '2022-03-01', 'Full')]", result)
+}
+
+// TestIntrinsicReferenceFullProperty tests access to a top-level field of
+// the full reference form
+func TestIntrinsicReferenceFullProperty(t *testing.T) {
+	ref := intrinsics.RefFullProperty("myapp", "2022-03-01", "identity.principalId")
+
+	result := SerializeValue(ref)
+
+	assert.Equal(t, "[reference('myapp', '2022-03-01', 'Full').identity.principalId]", result)
+}
+
 // TestIntrinsicParameter tests Parameter intrinsic serialization
 func TestIntrinsicParameter(t *testing.T) {
 	param := intrinsics.Parameters("location")
@@ -450,3 +488,792 @@ func TestStructWithIntrinsicInSlice(t *testing.T) {
 	assert.Equal(t, "[resourceId('Microsoft.Network/virtualNetworks', 'vnet2')]", ids[1])
 	assert.Equal(t, "/subscriptions/sub1/resourceGroups/rg1/providers/Microsoft.Network/virtualNetworks/vnet3", ids[2])
 }
+
+// TestPolicyAssignmentSerialization tests policy assignment serialization
+func TestPolicyAssignmentSerialization(t *testing.T) {
+	def := policy.NewPolicyDefinition("require-tag", "Indexed", map[string]interface{}{
+		"if":   map[string]interface{}{"field": "tags['env']", "exists": "false"},
+		"then": map[string]interface{}{"effect": "deny"},
+	})
+	assignment := policy.NewPolicyAssignment("require-env-tag", def.ID()).
+		WithParameter("tagName", "env")
+
+	result := ToARMResource(assignment)
+
+	assert.Equal(t, "require-env-tag", result["name"])
+	assert.Equal(t, "Microsoft.Authorization/policyAssignments", result["type"])
+	assert.NotContains(t, result, "location")
+
+	props, ok := result["properties"].(map[string]any)
+	require.True(t, ok, "properties should be a map")
+	assert.Equal(t, "[resourceId('Microsoft.Authorization/policyDefinitions', 'require-tag')]", props["policyDefinitionId"])
+
+	params, ok := props["parameters"].(map[string]any)
+	require.True(t, ok, "parameters should be a map")
+	assert.Contains(t, params, "tagName")
+}
+
+// TestWorkflowDefinitionPassthrough tests that free-form nested maps and slices serialize unchanged
+func TestWorkflowDefinitionPassthrough(t *testing.T) {
+	definition := map[string]any{
+		"contentVersion": "1.0.0.0",
+		"parameters":     map[string]any{},
+		"triggers": map[string]any{
+			"manual": map[string]any{
+				"type": "Request",
+				"kind": "Http",
+			},
+		},
+		"actions": map[string]any{
+			"notify": map[string]any{
+				"type":     "Http",
+				"runAfter": map[string]any{},
+				"inputs": map[string]any{
+					"method":  "POST",
+					"uri":     intrinsics.Parameters("webhookUrl"),
+					"headers": []any{"a", "b"},
+					"body":    []any{},
+				},
+			},
+		},
+	}
+	wf := logic.NewWorkflow("my-workflow", "eastus", definition)
+
+	result := ToARMResource(wf)
+
+	props, ok := result["properties"].(map[string]any)
+	require.True(t, ok, "properties should be a map")
+	def, ok := props["definition"].(map[string]any)
+	require.True(t, ok, "definition should be a map")
+
+	assert.Equal(t, "1.0.0.0", def["contentVersion"])
+	assert.Equal(t, map[string]any{}, def["parameters"])
+
+	actions := def["actions"].(map[string]any)
+	notify := actions["notify"].(map[string]any)
+	assert.Equal(t, map[string]any{}, notify["runAfter"])
+
+	inputs := notify["inputs"].(map[string]any)
+	assert.Equal(t, "[parameters('webhookUrl')]", inputs["uri"])
+	assert.Equal(t, []any{"a", "b"}, inputs["headers"])
+	assert.Equal(t, []any{}, inputs["body"], "empty arrays in free-form maps should be preserved")
+
+	// The serialized form should match plain JSON encoding of the definition
+	data, err := json.Marshal(def)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"body":[]`)
+}
+
+// TestContainerAppSerialization tests that the nested configuration and
+// template of a container app, including scale rules, serialize to ARM
+func TestContainerAppSerialization(t *testing.T) {
+	env := app.NewManagedEnvironment("apps-env", "eastus")
+	containerApp := app.NewContainerApp("api", "eastus", env.ID()).
+		AddContainer("api", "mcr.microsoft.com/k8se/quickstart:latest", 0.5, "1Gi").
+		AddContainer("worker", "busybox", 0.25, "0.5Gi").
+		WithEnvironmentVariable("api", "MODE", "production").
+		WithIngress(true, 80).
+		WithSecret("db-password", "[parameters('dbPassword')]").
+		WithScale(1, 5).
+		WithHTTPScaleRule("http-load", 100)
+
+	result := ToARMResource(containerApp)
+
+	assert.Equal(t, "Microsoft.App/containerApps", result["type"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "[resourceId('Microsoft.App/managedEnvironments', 'apps-env')]", props["environmentId"])
+
+	config := props["configuration"].(map[string]any)
+	ingress := config["ingress"].(map[string]any)
+	assert.Equal(t, true, ingress["external"])
+	assert.Equal(t, 80, ingress["targetPort"])
+	assert.Equal(t, []any{map[string]any{"latestRevision": true, "weight": 100}}, ingress["traffic"])
+	assert.Equal(t, []any{map[string]any{"name": "db-password", "value": "[parameters('dbPassword')]"}}, config["secrets"])
+
+	template := props["template"].(map[string]any)
+	containers := template["containers"].([]any)
+	require.Len(t, containers, 2)
+	api := containers[0].(map[string]any)
+	assert.Equal(t, "mcr.microsoft.com/k8se/quickstart:latest", api["image"])
+	assert.Equal(t, map[string]any{"cpu": 0.5, "memory": "1Gi"}, api["resources"])
+	assert.Equal(t, []any{map[string]any{"name": "MODE", "value": "production"}}, api["env"])
+	assert.NotContains(t, containers[1].(map[string]any), "env")
+
+	scale := template["scale"].(map[string]any)
+	assert.Equal(t, 1, scale["minReplicas"])
+	assert.Equal(t, 5, scale["maxReplicas"])
+	assert.Equal(t, []any{map[string]any{
+		"name": "http-load",
+		"http": map[string]any{"metadata": map[string]any{"concurrentRequests": "100"}},
+	}}, scale["rules"])
+}
+
+// TestManagementPolicySerialization tests storage lifecycle policy serialization
+func TestManagementPolicySerialization(t *testing.T) {
+	mp := storage.NewManagementPolicy("auditstorage").AddTierToCoolRule(30)
+
+	result := ToARMResource(mp)
+
+	assert.Equal(t, "auditstorage/default", result["name"])
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/managementPolicies", result["type"])
+
+	props := result["properties"].(map[string]any)
+	rules := props["policy"].(map[string]any)["rules"].([]any)
+	require.Len(t, rules, 1)
+
+	rule := rules[0].(map[string]any)
+	assert.Equal(t, true, rule["enabled"])
+	actions := rule["definition"].(map[string]any)["actions"].(map[string]any)
+	cool := actions["baseBlob"].(map[string]any)["tierToCool"].(map[string]any)
+	assert.Equal(t, 30, cool["daysAfterModificationGreaterThan"])
+}
+
+// TestFlowLogSerialization tests network watcher flow log serialization
+func TestFlowLogSerialization(t *testing.T) {
+	fl := network.NewFlowLog("watcher", "flowlog", "eastus",
+		intrinsics.ResourceId("Microsoft.Network/networkSecurityGroups", "web-nsg").ARMExpression(),
+		intrinsics.ResourceId("Microsoft.Storage/storageAccounts", "flowlogs").ARMExpression(),
+	).WithRetention(30)
+
+	result := ToARMResource(fl)
+
+	assert.Equal(t, "watcher/flowlog", result["name"])
+	assert.Equal(t, "Microsoft.Network/networkWatchers/flowLogs", result["type"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "[resourceId('Microsoft.Network/networkSecurityGroups', 'web-nsg')]", props["targetResourceId"])
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'flowlogs')]", props["storageId"])
+	assert.Equal(t, map[string]any{"days": 30, "enabled": true}, props["retentionPolicy"])
+}
+
+// TestStorageAccountIdentitySerialization tests that a storage account's
+// user-assigned identity is emitted at the resource top level, and the
+// customer-managed key references it
+func TestStorageAccountIdentitySerialization(t *testing.T) {
+	identityID := "[resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', 'storage-cmk')]"
+	sa := storage.NewStorageAccount("confidential", "eastus", "StorageV2", "Standard_LRS").
+		WithUserAssignedIdentity(identityID).
+		WithCustomerManagedKey("https://corp-kv.vault.azure.net", "storage-key", identityID)
+
+	result := ToARMResource(sa)
+
+	assert.Equal(t, map[string]any{
+		"type":                   "UserAssigned",
+		"userAssignedIdentities": map[string]any{identityID: map[string]any{}},
+	}, result["identity"])
+
+	props := result["properties"].(map[string]any)
+	assert.NotContains(t, props, "identity")
+	encryption := props["encryption"].(map[string]any)
+	assert.Equal(t, map[string]any{"userAssignedIdentity": identityID}, encryption["identity"])
+}
+
+// TestDataFactorySerialization tests data factory serialization with identity and encryption
+func TestDataFactorySerialization(t *testing.T) {
+	f := datafactory.NewFactory("my-factory", "eastus").
+		WithSystemAssignedIdentity().
+		WithPublicNetworkAccess("Disabled").
+		WithEncryption("https://my-vault.vault.azure.net", "adf-key")
+
+	result := ToARMResource(f)
+
+	assert.Equal(t, "my-factory", result["name"])
+	assert.Equal(t, "Microsoft.DataFactory/factories", result["type"])
+	assert.Equal(t, map[string]any{"type": "SystemAssigned"}, result["identity"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "Disabled", props["publicNetworkAccess"])
+	assert.Equal(t, map[string]any{
+		"vaultBaseUrl": "https://my-vault.vault.azure.net",
+		"keyName":      "adf-key",
+	}, props["encryption"])
+}
+
+// TestDataFactoryChildSerialization tests that linked service and pipeline properties pass through
+func TestDataFactoryChildSerialization(t *testing.T) {
+	ls := datafactory.NewLinkedService("my-factory", "blob", map[string]any{
+		"type": "AzureBlobStorage",
+		"typeProperties": map[string]any{
+			"serviceEndpoint": "https://mystorage.blob.core.windows.net",
+		},
+	})
+
+	result := ToARMResource(ls)
+
+	assert.Equal(t, "my-factory/blob", result["name"])
+	assert.Equal(t, "Microsoft.DataFactory/factories/linkedservices", result["type"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "AzureBlobStorage", props["type"])
+
+	pipeline := datafactory.NewPipeline("my-factory", "copy", map[string]any{
+		"activities": []any{
+			map[string]any{"name": "CopyBlob", "type": "Copy"},
+		},
+	})
+
+	result = ToARMResource(pipeline)
+
+	assert.Equal(t, "my-factory/copy", result["name"])
+	assert.Equal(t, "Microsoft.DataFactory/factories/pipelines", result["type"])
+	props = result["properties"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"name": "CopyBlob", "type": "Copy"}}, props["activities"])
+}
+
+// TestVirtualNetworkPeeringSerialization tests virtual network peering serialization
+func TestVirtualNetworkPeeringSerialization(t *testing.T) {
+	spoke := network.NewVirtualNetwork("spoke-vnet", "eastus", []string{"10.1.0.0/16"})
+	peering := network.NewVirtualNetworkPeering("hub-vnet", "hub-to-spoke", spoke.ID()).
+		WithGatewayTransit()
+
+	result := ToARMResource(peering)
+
+	assert.Equal(t, "hub-vnet/hub-to-spoke", result["name"])
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/virtualNetworkPeerings", result["type"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"id": "[resourceId('Microsoft.Network/virtualNetworks', 'spoke-vnet')]"}, props["remoteVirtualNetwork"])
+	assert.Equal(t, true, props["allowVirtualNetworkAccess"])
+	assert.Equal(t, true, props["allowGatewayTransit"])
+	assert.NotContains(t, props, "useRemoteGateways")
+}
+
+// TestSynapseWorkspaceSerialization tests Synapse workspace serialization with a Data Lake reference
+func TestSynapseWorkspaceSerialization(t *testing.T) {
+	lake := storage.NewStorageAccount("mylake", "eastus", "StorageV2", "Standard_LRS")
+	ws := synapse.NewWorkspace("my-synapse", "eastus", lake.DFSEndpoint(), "workspace").
+		WithDataLakeResourceID(lake.ID()).
+		WithSQLAdministrator("sqladmin", intrinsics.Parameters("sqlAdminPassword").ARMExpression()).
+		WithManagedVirtualNetwork()
+
+	result := ToARMResource(ws)
+
+	assert.Equal(t, "my-synapse", result["name"])
+	assert.Equal(t, "Microsoft.Synapse/workspaces", result["type"])
+	assert.Equal(t, map[string]any{"type": "SystemAssigned"}, result["identity"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"accountUrl": "[reference(resourceId('Microsoft.Storage/storageAccounts', 'mylake'), '2021-04-01').primaryEndpoints.dfs]",
+		"filesystem": "workspace",
+		"resourceId": "[resourceId('Microsoft.Storage/storageAccounts', 'mylake')]",
+	}, props["defaultDataLakeStorage"])
+	assert.Equal(t, "sqladmin", props["sqlAdministratorLogin"])
+	assert.Equal(t, "[parameters('sqlAdminPassword')]", props["sqlAdministratorLoginPassword"])
+	assert.Equal(t, "default", props["managedVirtualNetwork"])
+}
+
+// TestManagedIdentitySerialization tests user-assigned identity and federated credential serialization
+func TestManagedIdentitySerialization(t *testing.T) {
+	identity := managedidentity.NewUserAssignedIdentity("app-identity", "eastus")
+	result := ToARMResource(identity)
+
+	assert.Equal(t, "app-identity", result["name"])
+	assert.Equal(t, "Microsoft.ManagedIdentity/userAssignedIdentities", result["type"])
+	assert.Equal(t, "2023-01-31", result["apiVersion"])
+	assert.Equal(t, "eastus", result["location"])
+	assert.NotContains(t, result, "properties")
+
+	cred := managedidentity.NewFederatedCredential(identity.Name, "aks", "https://oidc.example.com/", "system:serviceaccount:default:app")
+	result = ToARMResource(cred)
+
+	assert.Equal(t, "app-identity/aks", result["name"])
+	assert.Equal(t, "Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials", result["type"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "https://oidc.example.com/", props["issuer"])
+	assert.Equal(t, "system:serviceaccount:default:app", props["subject"])
+	assert.Equal(t, []any{"api://AzureADTokenExchange"}, props["audiences"])
+}
+
+// TestMonitoringSerialization tests action group, metric alert and system topic serialization
+func TestMonitoringSerialization(t *testing.T) {
+	ag := insights.NewActionGroup("ops-team", "ops").AddEmailReceiver("oncall", "oncall@example.com")
+	result := ToARMResource(ag)
+
+	assert.Equal(t, "Microsoft.Insights/actionGroups", result["type"])
+	assert.Equal(t, "global", result["location"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "ops", props["groupShortName"])
+	assert.Equal(t, true, props["enabled"])
+	assert.Equal(t, []any{map[string]any{
+		"name":                 "oncall",
+		"emailAddress":         "oncall@example.com",
+		"useCommonAlertSchema": true,
+	}}, props["emailReceivers"])
+	assert.NotContains(t, props, "smsReceivers")
+
+	alert := insights.NewMetricAlert("high-cpu", "[resourceId('Microsoft.Compute/virtualMachines', 'myvm')]").
+		WithCriterion("Percentage CPU", "GreaterThan", 90, "Average").
+		WithActionGroup(ag.ID())
+	result = ToARMResource(alert)
+
+	assert.Equal(t, "Microsoft.Insights/metricAlerts", result["type"])
+	props = result["properties"].(map[string]any)
+	assert.Equal(t, 2, props["severity"])
+	assert.Equal(t, []any{"[resourceId('Microsoft.Compute/virtualMachines', 'myvm')]"}, props["scopes"])
+	criteria := props["criteria"].(map[string]any)
+	assert.Equal(t, "Microsoft.Azure.Monitor.SingleResourceMultipleMetricCriteria", criteria["odata.type"])
+	assert.Equal(t, []any{map[string]any{
+		"actionGroupId": "[resourceId('Microsoft.Insights/actionGroups', 'ops-team')]",
+	}}, props["actions"])
+
+	topic := eventgrid.NewSystemTopic("storage-events", "eastus",
+		"[resourceId('Microsoft.Storage/storageAccounts', 'mystorage')]", "Microsoft.Storage.StorageAccounts")
+	result = ToARMResource(topic)
+
+	assert.Equal(t, "Microsoft.EventGrid/systemTopics", result["type"])
+	props = result["properties"].(map[string]any)
+	assert.Equal(t, "Microsoft.Storage.StorageAccounts", props["topicType"])
+}
+
+// TestCopyLoopSerialization tests that an intrinsics.Copy serializes as its resource with a copy element
+func TestCopyLoopSerialization(t *testing.T) {
+	nic := network.NetworkInterface{
+		Name:     "[concat('web-nic-', copyIndex(1))]",
+		Type:     "Microsoft.Network/networkInterfaces",
+		Location: "eastus",
+	}
+
+	result := ToARMResource(&intrinsics.Copy{
+		Name:     "nicLoop",
+		Count:    intrinsics.Parameters("nicCount"),
+		Resource: nic,
+	})
+
+	assert.Equal(t, "[concat('web-nic-', copyIndex(1))]", result["name"])
+	assert.Equal(t, "Microsoft.Network/networkInterfaces", result["type"])
+	assert.Equal(t, map[string]any{
+		"name":  "nicLoop",
+		"count": "[parameters('nicCount')]",
+	}, result["copy"])
+
+	result = ToARMResource(intrinsics.Copy{Name: "nicLoop", Count: 3, Mode: "serial", BatchSize: 2, Resource: nic})
+	assert.Equal(t, map[string]any{
+		"name":      "nicLoop",
+		"count":     3,
+		"mode":      "serial",
+		"batchSize": 2,
+	}, result["copy"])
+}
+
+// TestVirtualNetworkGatewaySerialization tests virtual network gateway serialization
+func TestVirtualNetworkGatewaySerialization(t *testing.T) {
+	hub := network.NewVirtualNetwork("hub-vnet", "eastus", []string{"10.0.0.0/16"})
+	pip := network.NewPublicIPAddress("gw-pip", "eastus", "Static", "Standard")
+	gw := network.NewVirtualNetworkGateway("hub-gw", "eastus", "Vpn", "VpnGw1", hub.SubnetID("GatewaySubnet"), pip.ID()).
+		WithBGP()
+
+	result := ToARMResource(gw)
+
+	assert.Equal(t, "hub-gw", result["name"])
+	assert.Equal(t, "Microsoft.Network/virtualNetworkGateways", result["type"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "Vpn", props["gatewayType"])
+	assert.Equal(t, "RouteBased", props["vpnType"])
+	assert.Equal(t, true, props["enableBgp"])
+	assert.Equal(t, map[string]any{"name": "VpnGw1", "tier": "VpnGw1"}, props["sku"])
+	assert.NotContains(t, props, "activeActive")
+
+	ipConfigs := props["ipConfigurations"].([]any)
+	require.Len(t, ipConfigs, 1)
+	ipProps := ipConfigs[0].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'hub-vnet', 'GatewaySubnet')]"}, ipProps["subnet"])
+	assert.Equal(t, map[string]any{"id": "[resourceId('Microsoft.Network/publicIPAddresses', 'gw-pip')]"}, ipProps["publicIPAddress"])
+	assert.Equal(t, "Dynamic", ipProps["privateIPAllocationMethod"])
+}
+
+// TestExpressRouteCircuitSerialization tests ExpressRoute circuit serialization
+func TestExpressRouteCircuitSerialization(t *testing.T) {
+	circuit := network.NewExpressRouteCircuit("er-circuit", "eastus", "Equinix", "Silicon Valley", 1000, "Premium", "UnlimitedData")
+
+	result := ToARMResource(circuit)
+
+	assert.Equal(t, "Microsoft.Network/expressRouteCircuits", result["type"])
+	assert.Equal(t, map[string]any{"name": "Premium_UnlimitedData", "tier": "Premium", "family": "UnlimitedData"}, result["sku"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"serviceProviderName": "Equinix",
+		"peeringLocation":     "Silicon Valley",
+		"bandwidthInMbps":     1000,
+	}, props["serviceProviderProperties"])
+}
+
+// TestVMProtectedItemSerialization tests Recovery Services protected item serialization
+func TestVMProtectedItemSerialization(t *testing.T) {
+	vm := (&compute.VirtualMachine{Name: "web-vm", Location: "eastus"}).EnableBackup("backup-vault", "DefaultPolicy")
+	assert.NotContains(t, ToARMResource(vm), "backup")
+
+	result := ToARMResource(recoveryservices.NewVMProtectedItem(vm.Backup.VaultName, vm.Backup.PolicyName, vm.Name))
+
+	assert.Equal(t, "Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems", result["type"])
+	assert.NotContains(t, result, "location")
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"protectedItemType": "Microsoft.Compute/virtualMachines",
+		"policyId":          "[resourceId('Microsoft.RecoveryServices/vaults/backupPolicies', 'backup-vault', 'DefaultPolicy')]",
+		"sourceResourceId":  "[resourceId('Microsoft.Compute/virtualMachines', 'web-vm')]",
+	}, props)
+}
+
+// TestContainerGroupSerialization tests container group serialization with nested containers
+func TestContainerGroupSerialization(t *testing.T) {
+	group := containerinstance.NewContainerGroup("web", "eastus", "Linux").
+		AddContainer("app", "nginx", 1, 1.5, 80).
+		AddContainer("sidecar", "busybox", 0.5, 0.5).
+		WithEnvironmentVariable("app", "MODE", "production").
+		WithPublicIP("my-web", 80).
+		WithSystemAssignedIdentity()
+
+	result := ToARMResource(group)
+
+	assert.Equal(t, "Microsoft.ContainerInstance/containerGroups", result["type"])
+	assert.Equal(t, map[string]any{"type": "SystemAssigned"}, result["identity"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "Linux", props["osType"])
+
+	containers := props["containers"].([]any)
+	require.Len(t, containers, 2)
+	app := containers[0].(map[string]any)
+	assert.Equal(t, "app", app["name"])
+	appProps := app["properties"].(map[string]any)
+	assert.Equal(t, "nginx", appProps["image"])
+	assert.Equal(t, []any{map[string]any{"port": 80}}, appProps["ports"])
+	assert.Equal(t, []any{map[string]any{"name": "MODE", "value": "production"}}, appProps["environmentVariables"])
+	assert.Equal(t, "sidecar", containers[1].(map[string]any)["name"])
+
+	ip := props["ipAddress"].(map[string]any)
+	assert.Equal(t, "Public", ip["type"])
+	assert.Equal(t, "my-web", ip["dnsNameLabel"])
+}
+
+// TestApplicationSecurityGroupSerialization tests ASG serialization and NSG rules that reference ASGs
+func TestApplicationSecurityGroupSerialization(t *testing.T) {
+	web := network.NewApplicationSecurityGroup("web-asg", "eastus")
+	db := network.NewApplicationSecurityGroup("db-asg", "eastus")
+
+	result := ToARMResource(web)
+	assert.Equal(t, "Microsoft.Network/applicationSecurityGroups", result["type"])
+	assert.Equal(t, "eastus", result["location"])
+
+	nsg := network.NewNetworkSecurityGroup("app-nsg", "eastus").
+		WithApplicationSecurityGroupRule("web-to-db", 200, "Inbound", "Allow", "Tcp", "1433", web.ID(), db.ID())
+
+	props := ToARMResource(nsg)["properties"].(map[string]any)
+	rules := props["securityRules"].([]any)
+	require.Len(t, rules, 1)
+	ruleProps := rules[0].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"id": "[resourceId('Microsoft.Network/applicationSecurityGroups', 'web-asg')]"}}, ruleProps["sourceApplicationSecurityGroups"])
+	assert.Equal(t, []any{map[string]any{"id": "[resourceId('Microsoft.Network/applicationSecurityGroups', 'db-asg')]"}}, ruleProps["destinationApplicationSecurityGroups"])
+	assert.NotContains(t, ruleProps, "sourceAddressPrefix")
+}
+
+// TestDiagnosticSettingSerialization tests that the target resource becomes the ARM scope
+func TestDiagnosticSettingSerialization(t *testing.T) {
+	account := storage.NewStorageAccount("appdata", "eastus", "StorageV2", "Standard_LRS")
+	setting := insights.NewDiagnosticSetting("send-to-logs", account.ID()).
+		WithWorkspace("[resourceId('Microsoft.OperationalInsights/workspaces', 'logs')]").
+		WithLogCategoryGroup("allLogs").
+		WithMetrics("AllMetrics")
+
+	result := ToARMResource(setting)
+	assert.Equal(t, "Microsoft.Insights/diagnosticSettings", result["type"])
+	assert.Equal(t, "send-to-logs", result["name"])
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'appdata')]", result["scope"])
+	assert.NotContains(t, result, "location")
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "[resourceId('Microsoft.OperationalInsights/workspaces', 'logs')]", props["workspaceId"])
+	assert.NotContains(t, props, "storageAccountId")
+	assert.Equal(t, []any{map[string]any{"categoryGroup": "allLogs", "enabled": true}}, props["logs"])
+	assert.Equal(t, []any{map[string]any{"category": "AllMetrics", "enabled": true}}, props["metrics"])
+}
+
+// TestFrontDoorWAFPolicySerialization tests Front Door WAF policy serialization
+func TestFrontDoorWAFPolicySerialization(t *testing.T) {
+	policy := network.NewFrontDoorWebApplicationFirewallPolicy("edgewaf", "Premium_AzureFrontDoor", "Detection").
+		AddManagedRuleSet("Microsoft_DefaultRuleSet", "2.1", "Block").
+		AddRateLimitRule("throttleLogin", 100, 50, 1, "Block", network.FrontDoorMatchCondition{
+			MatchVariable: "RequestUri",
+			Operator:      "BeginsWith",
+			MatchValue:    []string{"/login"},
+		})
+
+	result := ToARMResource(policy)
+	assert.Equal(t, "Microsoft.Network/FrontDoorWebApplicationFirewallPolicies", result["type"])
+	assert.Equal(t, "Global", result["location"])
+	assert.Equal(t, map[string]any{"name": "Premium_AzureFrontDoor"}, result["sku"])
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"enabledState": "Enabled", "mode": "Detection"}, props["policySettings"])
+
+	rules := props["customRules"].(map[string]any)["rules"].([]any)
+	require.Len(t, rules, 1)
+	rule := rules[0].(map[string]any)
+	assert.Equal(t, "RateLimitRule", rule["ruleType"])
+	assert.Equal(t, 50, rule["rateLimitThreshold"])
+	assert.Equal(t, 1, rule["rateLimitDurationInMinutes"])
+	assert.Equal(t, []any{map[string]any{
+		"matchVariable": "RequestUri",
+		"operator":      "BeginsWith",
+		"matchValue":    []any{"/login"},
+	}}, rule["matchConditions"])
+
+	ruleSets := props["managedRules"].(map[string]any)["managedRuleSets"].([]any)
+	assert.Equal(t, map[string]any{"ruleSetType": "Microsoft_DefaultRuleSet", "ruleSetVersion": "2.1", "ruleSetAction": "Block"}, ruleSets[0])
+}
+
+// TestAgentPoolSerialization tests that a standalone agent pool serializes
+// to the child resource form
+func TestAgentPoolSerialization(t *testing.T) {
+	pool := aks.NewClusterAgentPool("app-aks", aks.NewAgentPool("user", "Standard_D4s_v5", 3).WithMode("User"))
+
+	result := ToARMResource(pool)
+	assert.Equal(t, "app-aks/user", result["name"])
+	assert.Equal(t, "Microsoft.ContainerService/managedClusters/agentPools", result["type"])
+	assert.NotContains(t, result, "location")
+
+	props := result["properties"].(map[string]any)
+	assert.NotContains(t, props, "name")
+	assert.Equal(t, "Standard_D4s_v5", props["vmSize"])
+	assert.Equal(t, 3, props["count"])
+	assert.Equal(t, "User", props["mode"])
+}
+
+// TestSSHPublicKeyResourceSerialization tests that an SSH public key resource
+// serializes with its key in properties
+func TestSSHPublicKeyResourceSerialization(t *testing.T) {
+	key := compute.NewSSHPublicKeyResource("deploy-key", "eastus", "ssh-ed25519 AAAAC3...")
+
+	result := ToARMResource(key)
+	assert.Equal(t, "deploy-key", result["name"])
+	assert.Equal(t, "Microsoft.Compute/sshPublicKeys", result["type"])
+	assert.Equal(t, "2023-03-01", result["apiVersion"])
+	assert.Equal(t, map[string]any{"publicKey": "ssh-ed25519 AAAAC3..."}, result["properties"])
+}
+
+// TestVirtualNetworkSubnetSerialization tests that a standalone subnet
+// serializes to the child resource form with its NSG association
+func TestVirtualNetworkSubnetSerialization(t *testing.T) {
+	nsg := network.NewNetworkSecurityGroup("web-nsg", "eastus")
+	subnet := network.NewVirtualNetworkSubnet("app-vnet", network.NewSubnet("web", "10.0.1.0/24").WithNSG(nsg.ID()))
+
+	result := ToARMResource(subnet)
+	assert.Equal(t, "app-vnet/web", result["name"])
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/subnets", result["type"])
+	assert.NotContains(t, result, "location")
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "10.0.1.0/24", props["addressPrefix"])
+	assert.Equal(t, map[string]any{"id": "[resourceId('Microsoft.Network/networkSecurityGroups', 'web-nsg')]"}, props["networkSecurityGroup"])
+}
+
+// TestCognitiveServicesSerialization tests that an OpenAI account and a model
+// deployment serialize with kind, SKU, and model
+func TestCognitiveServicesSerialization(t *testing.T) {
+	account := cognitiveservices.NewOpenAIAccount("my-openai", "eastus").WithNetworkAcls("203.0.113.0/24")
+
+	result := ToARMResource(account)
+	assert.Equal(t, "Microsoft.CognitiveServices/accounts", result["type"])
+	assert.Equal(t, "OpenAI", result["kind"])
+	assert.Equal(t, map[string]any{"name": "S0"}, result["sku"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "my-openai", props["customSubDomainName"])
+	assert.Equal(t, map[string]any{
+		"defaultAction": "Deny",
+		"ipRules":       []any{map[string]any{"value": "203.0.113.0/24"}},
+	}, props["networkAcls"])
+
+	deployment := cognitiveservices.NewDeployment("my-openai", "chat", "gpt-4o", "2024-08-06", 30)
+
+	result = ToARMResource(deployment)
+	assert.Equal(t, "my-openai/chat", result["name"])
+	assert.Equal(t, "Microsoft.CognitiveServices/accounts/deployments", result["type"])
+	assert.Equal(t, map[string]any{"name": "Standard", "capacity": 30}, result["sku"])
+}
+
+// TestMonitoringStackSerialization tests that an Azure Monitor workspace and a
+// Grafana instance integrating it serialize with SKU, identity, and integration
+func TestMonitoringStackSerialization(t *testing.T) {
+	workspace := insights.NewAzureMonitorWorkspace("prometheus", "eastus")
+
+	result := ToARMResource(workspace)
+	assert.Equal(t, "Microsoft.Monitor/accounts", result["type"])
+	assert.Equal(t, "eastus", result["location"])
+	assert.NotContains(t, result, "properties")
+
+	grafana := dashboard.NewGrafana("ops-grafana", "eastus").WithAzureMonitorWorkspace(workspace.ID())
+
+	result = ToARMResource(grafana)
+	assert.Equal(t, "Microsoft.Dashboard/grafana", result["type"])
+	assert.Equal(t, map[string]any{"name": "Standard"}, result["sku"])
+	assert.Equal(t, map[string]any{"type": "SystemAssigned"}, result["identity"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"azureMonitorWorkspaceIntegrations": []any{
+			map[string]any{"azureMonitorWorkspaceResourceId": "[resourceId('Microsoft.Monitor/accounts', 'prometheus')]"},
+		},
+	}, props["grafanaIntegrations"])
+}
+
+// TestWebSlotAndBindingSerialization tests serializing an app with a staging
+// slot and a custom domain secured by a managed certificate
+func TestWebSlotAndBindingSerialization(t *testing.T) {
+	planID := "[resourceId('Microsoft.Web/serverfarms', 'app-plan')]"
+	site := web.NewSite("shop-web", "eastus", planID)
+
+	result := ToARMResource(web.NewSiteSlot(site, "staging"))
+	assert.Equal(t, "Microsoft.Web/sites/slots", result["type"])
+	assert.Equal(t, "shop-web/staging", result["name"])
+	assert.Equal(t, map[string]any{"serverFarmId": planID, "httpsOnly": true}, result["properties"])
+
+	cert := web.NewManagedCertificate("shop-cert", "eastus", planID, "shop.example.com")
+	result = ToARMResource(cert)
+	assert.Equal(t, "Microsoft.Web/certificates", result["type"])
+	assert.Equal(t, map[string]any{"serverFarmId": planID, "canonicalName": "shop.example.com"}, result["properties"])
+
+	result = ToARMResource(web.NewHostNameBinding(site.Name, "shop.example.com").WithCertificate(cert))
+	assert.Equal(t, "Microsoft.Web/sites/hostNameBindings", result["type"])
+	assert.NotContains(t, result, "location")
+	assert.Equal(t, map[string]any{
+		"siteName":                    "shop-web",
+		"customHostNameDnsRecordType": "CName",
+		"sslState":                    "SniEnabled",
+		"thumbprint":                  cert.Thumbprint(),
+	}, result["properties"])
+}
+
+// TestStorageServicesSerialization tests serializing the blob, queue, and
+// table services of an account
+func TestStorageServicesSerialization(t *testing.T) {
+	rule := storage.CORSRule{
+		AllowedOrigins:  []string{"https://shop.example.com"},
+		AllowedMethods:  []string{"GET"},
+		AllowedHeaders:  []string{"*"},
+		ExposedHeaders:  []string{"*"},
+		MaxAgeInSeconds: 600,
+	}
+	cors := map[string]any{
+		"corsRules": []any{map[string]any{
+			"allowedOrigins":  []any{"https://shop.example.com"},
+			"allowedMethods":  []any{"GET"},
+			"allowedHeaders":  []any{"*"},
+			"exposedHeaders":  []any{"*"},
+			"maxAgeInSeconds": 600,
+		}},
+	}
+
+	result := ToARMResource(storage.NewBlobService("orders").WithDataProtection(7).WithCORSRule(rule))
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/blobServices", result["type"])
+	assert.Equal(t, "orders/default", result["name"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, true, props["isVersioningEnabled"])
+	assert.Equal(t, map[string]any{"enabled": true, "days": 7}, props["deleteRetentionPolicy"])
+	assert.Equal(t, cors, props["cors"])
+
+	result = ToARMResource(storage.NewQueueService("orders").WithCORSRule(rule))
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/queueServices", result["type"])
+	assert.Equal(t, map[string]any{"cors": cors}, result["properties"])
+
+	result = ToARMResource(storage.NewTableService("orders").WithCORSRule(rule))
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/tableServices", result["type"])
+	assert.Equal(t, map[string]any{"cors": cors}, result["properties"])
+}
+
+// TestVirtualWANSerialization tests serializing a virtual WAN, a hub, and a
+// hub virtual network connection
+func TestVirtualWANSerialization(t *testing.T) {
+	wan := network.NewVirtualWAN("corp-wan", "eastus")
+
+	result := ToARMResource(wan)
+	assert.Equal(t, "Microsoft.Network/virtualWans", result["type"])
+	assert.Equal(t, map[string]any{"type": "Standard"}, result["properties"])
+
+	hub := network.NewVirtualHub("eastus-hub", "eastus", wan.ID(), "10.100.0.0/23")
+	result = ToARMResource(hub)
+	assert.Equal(t, "Microsoft.Network/virtualHubs", result["type"])
+	assert.Equal(t, map[string]any{
+		"virtualWan":    map[string]any{"id": wan.ID()},
+		"addressPrefix": "10.100.0.0/23",
+		"sku":           "Standard",
+	}, result["properties"])
+
+	conn := network.NewHubVirtualNetworkConnection(hub.Name, "app-vnet", "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]")
+	result = ToARMResource(conn)
+	assert.Equal(t, "Microsoft.Network/virtualHubs/hubVirtualNetworkConnections", result["type"])
+	assert.Equal(t, "eastus-hub/app-vnet", result["name"])
+	assert.Equal(t, map[string]any{
+		"remoteVirtualNetwork": map[string]any{"id": "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"},
+	}, result["properties"])
+}
+
+// TestRealTimeServicesSerialization tests that SignalR and Web PubSub services
+// and an Azure Maps account serialize with kind, SKU, and properties
+func TestRealTimeServicesSerialization(t *testing.T) {
+	chat := signalr.NewService("chat-signalr", "eastus", "Standard_S1", 2).
+		WithServiceMode("Serverless").
+		WithAllowedOrigins("https://app.contoso.com")
+
+	result := ToARMResource(chat)
+	assert.Equal(t, "Microsoft.SignalRService/signalR", result["type"])
+	assert.Equal(t, "SignalR", result["kind"])
+	assert.Equal(t, map[string]any{"name": "Standard_S1", "capacity": 2}, result["sku"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"flag": "ServiceMode", "value": "Serverless"}}, props["features"])
+	assert.Equal(t, map[string]any{"allowedOrigins": []any{"https://app.contoso.com"}}, props["cors"])
+
+	events := webpubsub.NewService("events-pubsub", "eastus", "Free_F1", 1).WithPublicNetworkAccess("Disabled")
+
+	result = ToARMResource(events)
+	assert.Equal(t, "Microsoft.SignalRService/webPubSub", result["type"])
+	assert.Equal(t, "WebPubSub", result["kind"])
+	assert.Equal(t, map[string]any{"publicNetworkAccess": "Disabled"}, result["properties"])
+
+	result = ToARMResource(maps.NewAccount("fleet-maps", "eastus"))
+	assert.Equal(t, "Microsoft.Maps/accounts", result["type"])
+	assert.Equal(t, "Gen2", result["kind"])
+	assert.Equal(t, map[string]any{"name": "G2"}, result["sku"])
+}
+
+// TestPrivateDNSZoneSerialization tests serializing a private DNS zone and a
+// virtual network link to it
+func TestPrivateDNSZoneSerialization(t *testing.T) {
+	zone := network.NewPrivateDNSZone("privatelink.blob.core.windows.net")
+
+	result := ToARMResource(zone)
+	assert.Equal(t, "Microsoft.Network/privateDnsZones", result["type"])
+	assert.Equal(t, "global", result["location"])
+	assert.NotContains(t, result, "properties")
+
+	link := network.NewPrivateDnsZoneVirtualNetworkLink(zone.Name, "app-vnet", "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]")
+	result = ToARMResource(link)
+	assert.Equal(t, "Microsoft.Network/privateDnsZones/virtualNetworkLinks", result["type"])
+	assert.Equal(t, "privatelink.blob.core.windows.net/app-vnet", result["name"])
+	assert.Equal(t, "global", result["location"])
+	assert.Equal(t, map[string]any{
+		"virtualNetwork":      map[string]any{"id": "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"},
+		"registrationEnabled": false,
+	}, result["properties"])
+}
+
+// TestKeyVaultKeySerialization tests that a vault key serializes with its key
+// type, size, and operations, and a Managed HSM pool with its SKU
+func TestKeyVaultKeySerialization(t *testing.T) {
+	key := keyvault.NewKey("corp-kv", "storage-key", 4096)
+
+	result := ToARMResource(key)
+	assert.Equal(t, "corp-kv/storage-key", result["name"])
+	assert.Equal(t, "Microsoft.KeyVault/vaults/keys", result["type"])
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "RSA", props["kty"])
+	assert.Equal(t, 4096, props["keySize"])
+	assert.Equal(t, []any{"wrapKey", "unwrapKey"}, props["keyOps"])
+
+	hsm := keyvault.NewManagedHSM("corp-hsm", "eastus", "00000000-0000-0000-0000-000000000001")
+
+	result = ToARMResource(hsm)
+	assert.Equal(t, "Microsoft.KeyVault/managedHSMs", result["type"])
+	assert.Equal(t, map[string]any{"family": "B", "name": "Standard_B1"}, result["sku"])
+}
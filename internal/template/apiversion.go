@@ -0,0 +1,158 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+)
+
+// FallbackAPIVersion is used for resource types missing from DefaultAPIVersions
+const FallbackAPIVersion = "2021-04-01"
+
+// DefaultAPIVersions maps resource types to the API version used when a
+// resource does not set APIVersion explicitly.
+var DefaultAPIVersions = map[string]string{
+	"Microsoft.Storage/storageAccounts":                                                   "2021-04-01",
+	"Microsoft.Storage/storageAccounts/managementPolicies":                                "2021-04-01",
+	"Microsoft.Compute/virtualMachines":                                                   "2021-07-01",
+	"Microsoft.Network/virtualNetworks":                                                   "2021-02-01",
+	"Microsoft.Network/networkInterfaces":                                                 "2021-02-01",
+	"Microsoft.Network/publicIPAddresses":                                                 "2021-02-01",
+	"Microsoft.Network/networkSecurityGroups":                                             "2021-02-01",
+	"Microsoft.KeyVault/vaults":                                                           "2021-06-01",
+	"Microsoft.Sql/servers":                                                               "2021-11-01",
+	"Microsoft.Sql/servers/databases":                                                     "2021-11-01",
+	"Microsoft.Sql/servers/elasticPools":                                                  "2021-11-01",
+	"Microsoft.Sql/servers/failoverGroups":                                                "2021-11-01",
+	"Microsoft.Web/sites":                                                                 "2021-01-15",
+	"Microsoft.ContainerRegistry/registries":                                              "2021-06-01",
+	"Microsoft.ContainerService/managedClusters":                                          "2021-05-01",
+	"Microsoft.Authorization/policyDefinitions":                                           "2021-06-01",
+	"Microsoft.Authorization/policyAssignments":                                           "2021-06-01",
+	"Microsoft.Authorization/locks":                                                       "2020-05-01",
+	"Microsoft.Authorization/roleAssignments":                                             "2022-04-01",
+	"Microsoft.Logic/workflows":                                                           "2019-05-01",
+	"Microsoft.App/managedEnvironments":                                                   "2023-05-01",
+	"Microsoft.App/containerApps":                                                         "2023-05-01",
+	"Microsoft.Network/networkWatchers":                                                   "2021-05-01",
+	"Microsoft.Network/networkWatchers/flowLogs":                                          "2021-05-01",
+	"Microsoft.DataFactory/factories":                                                     "2018-06-01",
+	"Microsoft.DataFactory/factories/linkedservices":                                      "2018-06-01",
+	"Microsoft.DataFactory/factories/pipelines":                                           "2018-06-01",
+	"Microsoft.Network/virtualNetworks/virtualNetworkPeerings":                            "2021-02-01",
+	"Microsoft.Synapse/workspaces":                                                        "2021-06-01",
+	"Microsoft.ManagedIdentity/userAssignedIdentities":                                    "2023-01-31",
+	"Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials":       "2023-01-31",
+	"Microsoft.Insights/actionGroups":                                                     "2023-01-01",
+	"Microsoft.Insights/metricAlerts":                                                     "2018-03-01",
+	"Microsoft.Insights/scheduledQueryRules":                                              "2021-08-01",
+	"Microsoft.EventGrid/systemTopics":                                                    "2022-06-15",
+	"Microsoft.Network/virtualNetworkGateways":                                            "2023-04-01",
+	"Microsoft.Network/expressRouteCircuits":                                              "2023-04-01",
+	"Microsoft.RecoveryServices/vaults":                                                   "2023-04-01",
+	"Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems": "2023-04-01",
+	"Microsoft.ContainerInstance/containerGroups":                                         "2023-05-01",
+	"Microsoft.Network/applicationSecurityGroups":                                         "2021-05-01",
+	"Microsoft.Insights/diagnosticSettings":                                               "2021-05-01-preview",
+	"Microsoft.Network/FrontDoorWebApplicationFirewallPolicies":                           "2022-05-01",
+	"Microsoft.ContainerService/managedClusters/agentPools":                               "2023-05-01",
+	"Microsoft.ContainerService/managedClusters/maintenanceConfigurations":                "2023-10-01",
+	"Microsoft.Storage/storageAccounts/blobServices":                                      "2023-01-01",
+	"Microsoft.Compute/sshPublicKeys":                                                     "2023-03-01",
+	"Microsoft.Network/virtualNetworks/subnets":                                           "2021-02-01",
+	"Microsoft.CognitiveServices/accounts":                                                "2023-05-01",
+	"Microsoft.CognitiveServices/accounts/deployments":                                    "2023-05-01",
+	"Microsoft.Network/privateEndpoints":                                                  "2023-04-01",
+	"Microsoft.Monitor/accounts":                                                          "2023-04-03",
+	"Microsoft.Dashboard/grafana":                                                         "2023-09-01",
+	"Microsoft.Web/sites/slots":                                                           "2021-01-15",
+	"Microsoft.Web/sites/hostNameBindings":                                                "2021-01-15",
+	"Microsoft.Web/certificates":                                                          "2021-01-15",
+	"Microsoft.Storage/storageAccounts/queueServices":                                     "2023-01-01",
+	"Microsoft.Storage/storageAccounts/tableServices":                                     "2023-01-01",
+	"Microsoft.Network/virtualWans":                                                       "2023-04-01",
+	"Microsoft.Network/virtualHubs":                                                       "2023-04-01",
+	"Microsoft.Network/virtualHubs/hubVirtualNetworkConnections":                          "2023-04-01",
+	"Microsoft.SignalRService/signalR":                                                    "2023-02-01",
+	"Microsoft.SignalRService/webPubSub":                                                  "2023-02-01",
+	"Microsoft.Maps/accounts":                                                             "2023-06-01",
+	"Microsoft.Network/privateDnsZones":                                                   "2020-06-01",
+	"Microsoft.Network/privateDnsZones/virtualNetworkLinks":                               "2020-06-01",
+	"Microsoft.KeyVault/vaults/keys":                                                      "2021-06-01",
+	"Microsoft.KeyVault/managedHSMs":                                                      "2021-10-01",
+}
+
+// apiVersionPattern matches ARM API versions such as 2021-04-01 or 2021-04-01-preview
+var apiVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(-[A-Za-z]+)?$`)
+
+// RecommendedMinAPIVersion is the API version floor below which the
+// api-versions report flags a resource, matching the WAZ304 lint rule
+const RecommendedMinAPIVersion = "2021-01-01"
+
+// IsAPIVersion reports whether s is an ARM API version such as 2021-04-01 or
+// 2021-04-01-preview
+func IsAPIVersion(s string) bool {
+	return apiVersionPattern.MatchString(s)
+}
+
+// APIVersionBefore reports whether the date of version predates the date of
+// floor. Both must be valid API versions (see IsAPIVersion).
+func APIVersionBefore(version, floor string) bool {
+	// The date prefix of an API version sorts lexically
+	return version[:10] < floor[:10]
+}
+
+// DefaultAPIVersion returns the default API version for a resource type
+func DefaultAPIVersion(resourceType string) string {
+	if version, ok := DefaultAPIVersions[resourceType]; ok {
+		return version
+	}
+	return FallbackAPIVersion
+}
+
+// resolveAPIVersion returns the resource's explicit API version, or the default for its type
+func resolveAPIVersion(resource discover.DiscoveredResource) string {
+	if resource.APIVersion != "" {
+		return resource.APIVersion
+	}
+	return DefaultAPIVersion(resource.Type)
+}
+
+// WithMinAPIVersion rejects resources whose explicit API version predates
+// version (e.g. "2021-01-01"). Defaulted API versions are not checked.
+func (tb *TemplateBuilder) WithMinAPIVersion(version string) *TemplateBuilder {
+	tb.minAPIVersion = version
+	return tb
+}
+
+// validateAPIVersions checks explicit API versions against the minimum, if set
+func (tb *TemplateBuilder) validateAPIVersions() error {
+	if tb.minAPIVersion == "" {
+		return nil
+	}
+	if !IsAPIVersion(tb.minAPIVersion) {
+		return fmt.Errorf("invalid minimum API version %q (expected YYYY-MM-DD)", tb.minAPIVersion)
+	}
+
+	names := make([]string, 0, len(tb.resources))
+	for name := range tb.resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := tb.resources[name].APIVersion
+		if version == "" {
+			continue
+		}
+		if !IsAPIVersion(version) {
+			return fmt.Errorf("resource %s has invalid API version %q", name, version)
+		}
+		if APIVersionBefore(version, tb.minAPIVersion) {
+			return fmt.Errorf("resource %s uses API version %s, older than the minimum %s", name, version, tb.minAPIVersion)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/resources/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultAPIVersion(t *testing.T) {
+	assert.Equal(t, "2021-07-01", DefaultAPIVersion("Microsoft.Compute/virtualMachines"))
+	assert.Equal(t, FallbackAPIVersion, DefaultAPIVersion("Microsoft.Unknown/things"))
+}
+
+// TestDefaultAPIVersion_MatchesConstructors tests that declarations without
+// an APIVersion build with the version the resource package's constructors set
+func TestDefaultAPIVersion_MatchesConstructors(t *testing.T) {
+	server := sql.NewServer("app-sql", "eastus", "sqladmin", "[parameters('password')]")
+	database := sql.NewDatabase("app-sql", "appdb", "eastus")
+	pool := sql.NewElasticPool("app-sql", "app-pool", "eastus", "StandardPool", "Standard", 100)
+	group := sql.NewFailoverGroup("app-sql", "app-fog", "[parameters('partnerId')]")
+
+	assert.Equal(t, server.APIVersion, DefaultAPIVersion(server.Type))
+	assert.Equal(t, database.APIVersion, DefaultAPIVersion(database.Type))
+	assert.Equal(t, pool.APIVersion, DefaultAPIVersion(pool.Type))
+	assert.Equal(t, group.APIVersion, DefaultAPIVersion(group.Type))
+}
+
+func TestAPIVersionBefore(t *testing.T) {
+	assert.True(t, IsAPIVersion("2021-04-01"))
+	assert.True(t, IsAPIVersion("2021-04-01-preview"))
+	assert.False(t, IsAPIVersion("2021-4-1"))
+
+	assert.True(t, APIVersionBefore("2019-06-01", RecommendedMinAPIVersion))
+	assert.True(t, APIVersionBefore("2020-12-31-preview", "2021-01-01"))
+	assert.False(t, APIVersionBefore("2021-01-01", "2021-01-01"))
+	assert.False(t, APIVersionBefore("2021-01-01-preview", "2021-01-01"))
+}
+
+func TestBuild_APIVersionAutofill(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "defaulted",
+		Type: "Microsoft.Compute/virtualMachines",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:       "explicit",
+		Type:       "Microsoft.Storage/storageAccounts",
+		APIVersion: "2023-01-01",
+	}))
+
+	result, err := builder.Build()
+	require.NoError(t, err)
+
+	var template ARMTemplate
+	require.NoError(t, json.Unmarshal([]byte(result), &template))
+
+	versions := make(map[string]string)
+	for _, r := range template.Resources {
+		versions[r.Name] = r.APIVersion
+	}
+	assert.Equal(t, "2021-07-01", versions["defaulted"])
+	assert.Equal(t, "2023-01-01", versions["explicit"])
+}
+
+func TestBuild_MinAPIVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		minVersion string
+		wantErr    string
+	}{
+		{name: "no minimum", apiVersion: "2019-06-01", minVersion: ""},
+		{name: "explicit at minimum", apiVersion: "2021-01-01", minVersion: "2021-01-01"},
+		{name: "explicit preview after minimum", apiVersion: "2022-09-01-preview", minVersion: "2021-01-01"},
+		{name: "defaulted is not checked", apiVersion: "", minVersion: "2030-01-01"},
+		{name: "explicit older than minimum", apiVersion: "2019-06-01", minVersion: "2021-01-01", wantErr: "older than the minimum 2021-01-01"},
+		{name: "invalid minimum", apiVersion: "2021-04-01", minVersion: "latest", wantErr: "invalid minimum API version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewTemplateBuilder(ScopeResourceGroup).WithMinAPIVersion(tt.minVersion)
+			require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+				Name:       "myStorage",
+				Type:       "Microsoft.Storage/storageAccounts",
+				APIVersion: tt.apiVersion,
+			}))
+
+			_, err := builder.Build()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
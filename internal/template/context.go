@@ -0,0 +1,132 @@
+package template
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ResourceContext is the subscription and resource group that the resource
+// IDs in a template are deployed to. The builder applies it to what it
+// writes: resource scopes and the properties, identity, sku, and plan of
+// expanded resources and template.RawResource declarations. The properties
+// of typed resources are not written, so IDs in them are not replaced. Resource IDs written with
+// placeholders, such as
+// "/subscriptions/{sub}/resourceGroups/{rg}/providers/...", have the
+// placeholders replaced by the fields that are set. The placeholders of empty
+// fields become the deployment's subscription().subscriptionId and
+// resourceGroup().name where the template's scope has them, turning the ID
+// into a concat() expression, and are otherwise left in place.
+type ResourceContext struct {
+	// SubscriptionID replaces the subscription placeholder of resource IDs
+	SubscriptionID string
+
+	// ResourceGroup replaces the resource group placeholder of resource IDs
+	ResourceGroup string
+
+	// scope is the scope of the template, set by the builder, which decides
+	// the deployment functions that placeholders fall back to
+	scope Scope
+}
+
+// Placeholder segments of resource IDs: any {...} token following
+// /subscriptions/ or /resourceGroups/, such as {sub} or {subscription-id}
+var (
+	subscriptionPlaceholder  = regexp.MustCompile(`(?i)(/subscriptions/)\{[^/{}]*\}`)
+	resourceGroupPlaceholder = regexp.MustCompile(`(?i)(/resourceGroups/)\{[^/{}]*\}`)
+	placeholder              = regexp.MustCompile(`(?i)(/subscriptions/|/resourceGroups/)\{[^/{}]*\}`)
+)
+
+// WithResourceContext replaces the subscription and resource group
+// placeholders of the resource IDs the template is written with by those of
+// ctx
+func (tb *TemplateBuilder) WithResourceContext(ctx ResourceContext) *TemplateBuilder {
+	tb.context = ctx
+	return tb
+}
+
+// resolve returns s with the placeholders that c has values for replaced,
+// keeping the case of the segment names. The remaining placeholders of a
+// string that is not already an expression are replaced by the deployment
+// functions of c's scope.
+func (c ResourceContext) resolve(s string) string {
+	if c.SubscriptionID != "" {
+		s = subscriptionPlaceholder.ReplaceAllString(s, "${1}"+escapeTemplate(c.SubscriptionID))
+	}
+	if c.ResourceGroup != "" {
+		s = resourceGroupPlaceholder.ReplaceAllString(s, "${1}"+escapeTemplate(c.ResourceGroup))
+	}
+	if isExpression(s) {
+		return s
+	}
+
+	subscription, resourceGroup := c.scope.deploymentFunctions()
+	var args []string
+	literal := 0
+	for _, match := range placeholder.FindAllStringSubmatchIndex(s, -1) {
+		function := resourceGroup
+		if strings.EqualFold(s[match[2]:match[3]], "/subscriptions/") {
+			function = subscription
+		}
+		if function == "" {
+			continue
+		}
+		args = append(args, quoteString(s[literal:match[3]]), function)
+		literal = match[1]
+	}
+	if args == nil {
+		return s
+	}
+	if literal < len(s) {
+		args = append(args, quoteString(s[literal:]))
+	}
+	return "[concat(" + strings.Join(args, ", ") + ")]"
+}
+
+// deploymentFunctions returns the ARM functions for the subscription ID and
+// resource group name of a deployment at scope s, empty for those the scope
+// does not have
+func (s Scope) deploymentFunctions() (subscription, resourceGroup string) {
+	switch s {
+	case ScopeResourceGroup:
+		return "subscription().subscriptionId", "resourceGroup().name"
+	case ScopeSubscription:
+		return "subscription().subscriptionId", ""
+	default:
+		return "", ""
+	}
+}
+
+// quoteString returns s as an ARM string literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// escapeTemplate escapes s for use in a regexp replacement template
+func escapeTemplate(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// resolveValue returns v with the placeholders of every string in it
+// replaced, object keys included, since some objects are keyed by resource
+// ID, such as the userAssignedIdentities of an identity. v is a property
+// value as decoded from JSON: a string, map, slice, or scalar.
+func (c ResourceContext) resolveValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return c.resolve(v)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			resolved[c.resolve(k)] = c.resolveValue(elem)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, elem := range v {
+			resolved[i] = c.resolveValue(elem)
+		}
+		return resolved
+	default:
+		return v
+	}
+}
@@ -0,0 +1,172 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	rawtemplate "github.com/lex00/wetwire-azure-go/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResourceContext(t *testing.T) {
+	subnetID := "/subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/vnet/subnets/data"
+
+	tests := []struct {
+		name string
+		ctx  ResourceContext
+		want string
+	}{
+		{
+			name: "no context",
+			want: "[concat('/subscriptions/', subscription().subscriptionId, '/resourceGroups/', resourceGroup().name, '/providers/Microsoft.Network/virtualNetworks/vnet/subnets/data')]",
+		},
+		{
+			name: "subscription and resource group",
+			ctx:  ResourceContext{SubscriptionID: "00000000-0000-0000-0000-000000000001", ResourceGroup: "prod-rg"},
+			want: "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/prod-rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/data",
+		},
+		{
+			name: "resource group only",
+			ctx:  ResourceContext{ResourceGroup: "prod-rg"},
+			want: "[concat('/subscriptions/', subscription().subscriptionId, '/resourceGroups/prod-rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/data')]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewTemplateBuilder(ScopeResourceGroup).WithResourceContext(tt.ctx)
+			require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+				Name: "endpoint",
+				Type: "Microsoft.Network/privateEndpoints",
+				Properties: map[string]any{
+					"subnet": map[string]any{"id": subnetID},
+					"privateLinkServiceConnections": []any{
+						map[string]any{"properties": map[string]any{"groupIds": []any{"blob"}}},
+					},
+				},
+			}))
+
+			out, err := builder.Build()
+			require.NoError(t, err)
+
+			var tmpl struct {
+				Resources []struct {
+					Properties struct {
+						Subnet struct {
+							ID string `json:"id"`
+						} `json:"subnet"`
+						Connections []struct {
+							Properties struct {
+								GroupIDs []string `json:"groupIds"`
+							} `json:"properties"`
+						} `json:"privateLinkServiceConnections"`
+					} `json:"properties"`
+				} `json:"resources"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(out), &tmpl))
+			require.Len(t, tmpl.Resources, 1)
+			props := tmpl.Resources[0].Properties
+			assert.Equal(t, tt.want, props.Subnet.ID)
+			require.Len(t, props.Connections, 1)
+			assert.Equal(t, []string{"blob"}, props.Connections[0].Properties.GroupIDs)
+		})
+	}
+}
+
+func TestWithResourceContext_ScopeAndRawResource(t *testing.T) {
+	ctx := ResourceContext{SubscriptionID: "sub-id", ResourceGroup: "prod-rg"}
+	builder := NewTemplateBuilder(ScopeResourceGroup).WithResourceContext(ctx)
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:    "App",
+		Type:    "Microsoft.Web/sites",
+		ARMName: "app",
+		Raw: &rawtemplate.RawResource{
+			Identity: map[string]any{
+				"type": "UserAssigned",
+				"userAssignedIdentities": map[string]any{
+					"/subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.ManagedIdentity/userAssignedIdentities/app-id": map[string]any{},
+				},
+			},
+		},
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:    "AppLock",
+		Type:    "Microsoft.Authorization/locks",
+		ARMName: "app-lock",
+		Scope:   "/subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Web/sites/app",
+	}))
+
+	out, err := builder.BuildCompact()
+	require.NoError(t, err)
+	assert.Contains(t, out, `"/subscriptions/sub-id/resourceGroups/prod-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/app-id":{}`)
+	assert.Contains(t, out, `"scope":"/subscriptions/sub-id/resourceGroups/prod-rg/providers/Microsoft.Web/sites/app"`)
+	assert.NotContains(t, out, "{sub}")
+}
+
+func TestResourceContextResolve(t *testing.T) {
+	ctx := ResourceContext{SubscriptionID: "sub-id", ResourceGroup: "rg"}
+
+	assert.Equal(t, "/subscriptions/sub-id/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/logs",
+		ctx.resolve("/subscriptions/{subscription-id}/resourceGroups/{resource-group}/providers/Microsoft.Storage/storageAccounts/logs"))
+	assert.Equal(t, "/subscriptions/sub-id/resourcegroups/rg",
+		ctx.resolve("/subscriptions/{subscriptionId}/resourcegroups/{resourceGroupName}"))
+	// Only placeholders in the subscription and resource group segments are replaced
+	assert.Equal(t, "/providers/Microsoft.Network/virtualNetworks/{vnet}",
+		ctx.resolve("/providers/Microsoft.Network/virtualNetworks/{vnet}"))
+}
+
+func TestResourceContextResolve_Fallback(t *testing.T) {
+	id := "/subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/hub"
+
+	tests := []struct {
+		name string
+		ctx  ResourceContext
+		id   string
+		want string
+	}{
+		{
+			name: "resource group scope",
+			ctx:  ResourceContext{scope: ScopeResourceGroup},
+			id:   id,
+			want: "[concat('/subscriptions/', subscription().subscriptionId, '/resourceGroups/', resourceGroup().name, '/providers/Microsoft.Network/virtualNetworks/hub')]",
+		},
+		{
+			name: "subscription given",
+			ctx:  ResourceContext{SubscriptionID: "sub-id", scope: ScopeResourceGroup},
+			id:   id,
+			want: "[concat('/subscriptions/sub-id/resourceGroups/', resourceGroup().name, '/providers/Microsoft.Network/virtualNetworks/hub')]",
+		},
+		{
+			name: "subscription scope has no resource group",
+			ctx:  ResourceContext{scope: ScopeSubscription},
+			id:   id,
+			want: "[concat('/subscriptions/', subscription().subscriptionId, '/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/hub')]",
+		},
+		{
+			name: "tenant scope keeps placeholders",
+			ctx:  ResourceContext{scope: ScopeTenant},
+			id:   id,
+			want: id,
+		},
+		{
+			name: "quotes in the literal are escaped",
+			ctx:  ResourceContext{scope: ScopeResourceGroup},
+			id:   "/subscriptions/{sub}/providers/Contoso.Things/things/o'neil",
+			want: "[concat('/subscriptions/', subscription().subscriptionId, '/providers/Contoso.Things/things/o''neil')]",
+		},
+		{
+			name: "expressions are left as they are",
+			ctx:  ResourceContext{scope: ScopeResourceGroup},
+			id:   "[concat('/subscriptions/{sub}/', parameters('rest'))]",
+			want: "[concat('/subscriptions/{sub}/', parameters('rest'))]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.ctx.resolve(tt.id))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxDeploymentResources is the most resources ARM accepts in a single
+// deployment, counting each instance of a copy loop
+const MaxDeploymentResources = 800
+
+// ErrTooManyResources is returned when a template has more resources than
+// the builder's maximum (see WithMaxResources)
+var ErrTooManyResources = errors.New("too many resources")
+
+// WithMaxResources rejects templates with more than n resources, counting
+// each instance of a copy loop whose count is a literal. Zero means no limit.
+func (tb *TemplateBuilder) WithMaxResources(n int) *TemplateBuilder {
+	tb.maxResources = n
+	return tb
+}
+
+// validateResourceCount checks the number of resources against the maximum,
+// if set, before a runaway copy count or declaration generator produces a
+// template ARM would reject
+func (tb *TemplateBuilder) validateResourceCount() error {
+	if tb.maxResources <= 0 {
+		return nil
+	}
+
+	count := 0
+	for _, resource := range tb.resources {
+		count++
+		// Copy loops whose count is an expression are only counted once
+		if loop := resource.Copy; loop != nil {
+			if n, ok := loop.Count.(int); ok && n > 1 {
+				count += n - 1
+			}
+		}
+	}
+	if count > tb.maxResources {
+		return fmt.Errorf("%w: the template has %d resources, counting copy loop instances, over the maximum of %d; "+
+			"ARM deployments are limited to %d resources, so check copy counts and generated declarations, "+
+			"or split the resources across nested deployments",
+			ErrTooManyResources, count, tb.maxResources, MaxDeploymentResources)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package template
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxResources(t *testing.T) {
+	newBuilder := func(count any) *TemplateBuilder {
+		builder := NewTemplateBuilder(ScopeResourceGroup)
+		require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+			Name: "appVNet",
+			Type: "Microsoft.Network/virtualNetworks",
+		}))
+		require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+			Name: "webNICs",
+			Type: "Microsoft.Network/networkInterfaces",
+			Copy: &discover.CopyLoop{Name: "webNICs", Count: count},
+		}))
+		return builder
+	}
+
+	// 1 + 4 copy instances
+	_, err := newBuilder(4).WithMaxResources(5).Build()
+	assert.NoError(t, err)
+
+	_, err = newBuilder(5).WithMaxResources(5).Build()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTooManyResources))
+	assert.Contains(t, err.Error(), "the template has 6 resources")
+	assert.Contains(t, err.Error(), "over the maximum of 5")
+
+	// Counts given as expressions are not known until deployment
+	_, err = newBuilder("[parameters('nicCount')]").WithMaxResources(2).Build()
+	assert.NoError(t, err)
+
+	_, err = newBuilder(5000).Build()
+	assert.NoError(t, err, "no limit by default")
+}
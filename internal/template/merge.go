@@ -0,0 +1,249 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrMergeConflict is returned by MergeTemplates when the base and generated
+// templates cannot be combined, such as when both define a parameter
+// differently
+var ErrMergeConflict = errors.New("conflicts with the base template")
+
+// sectionOrder is the order of the top-level sections of a merged template.
+// Other sections of the base template follow in name order.
+var sectionOrder = []string{
+	"$schema", "contentVersion", "apiProfile", "metadata", "parameters",
+	"functions", "variables", "resources", "outputs",
+}
+
+// MergeTemplates merges the generated ARM template into base, a template
+// maintained elsewhere, so resources can move to wetwire incrementally.
+//
+// The generated resources are appended to those of base. A base resource with
+// the same type and name (compared case-insensitively, as ARM does) is
+// replaced, and listed in replaced as "<type>/<name>". The parameters,
+// variables, and outputs of both are kept; one defined differently in both is
+// a conflict. Generated metadata entries, such as the _generator stamp,
+// replace those of base. Both templates must target the same deployment scope.
+//
+// Every conflict is reported, wrapping ErrMergeConflict. The merged template
+// is returned as JSON in section order, with the content of each section as
+// written in its input.
+func MergeTemplates(base, generated []byte) (merged []byte, replaced []string, err error) {
+	var baseSections, generatedSections map[string]json.RawMessage
+	if err := json.Unmarshal(base, &baseSections); err != nil {
+		return nil, nil, fmt.Errorf("parse base template: %w", err)
+	}
+	if err := json.Unmarshal(generated, &generatedSections); err != nil {
+		return nil, nil, fmt.Errorf("parse generated template: %w", err)
+	}
+
+	var conflicts []error
+	if err := checkSchemas(baseSections["$schema"], generatedSections["$schema"]); err != nil {
+		conflicts = append(conflicts, err)
+	}
+
+	sections := make(map[string]json.RawMessage, len(baseSections))
+	for name, value := range baseSections {
+		sections[name] = value
+	}
+	if _, ok := sections["$schema"]; !ok {
+		sections["$schema"] = generatedSections["$schema"]
+	}
+	if _, ok := sections["contentVersion"]; !ok {
+		sections["contentVersion"] = generatedSections["contentVersion"]
+	}
+
+	for _, section := range []struct {
+		name, kind string
+		overwrite  bool
+	}{
+		{"metadata", "metadata entry", true},
+		{"parameters", "parameter", false},
+		{"variables", "variable", false},
+		{"outputs", "output", false},
+	} {
+		value, errs, err := mergeSection(section.kind, sections[section.name], generatedSections[section.name], section.overwrite)
+		if err != nil {
+			return nil, nil, err
+		}
+		conflicts = append(conflicts, errs...)
+		if value != nil {
+			sections[section.name] = value
+		}
+	}
+
+	resources, replaced, err := mergeResources(sections["resources"], generatedSections["resources"])
+	if err != nil {
+		return nil, nil, err
+	}
+	sections["resources"] = resources
+
+	if len(conflicts) > 0 {
+		return nil, nil, errors.Join(conflicts...)
+	}
+	return encodeSections(sections), replaced, nil
+}
+
+// checkSchemas reports a conflict if the base and generated $schema URLs
+// target different deployment scopes, which the file name of the schema
+// identifies (e.g. subscriptionDeploymentTemplate.json)
+func checkSchemas(base, generated json.RawMessage) error {
+	var baseSchema, generatedSchema string
+	if json.Unmarshal(base, &baseSchema) != nil || json.Unmarshal(generated, &generatedSchema) != nil {
+		return nil
+	}
+	baseFile := path.Base(strings.TrimSuffix(baseSchema, "#"))
+	generatedFile := path.Base(strings.TrimSuffix(generatedSchema, "#"))
+	if !strings.EqualFold(baseFile, generatedFile) {
+		return fmt.Errorf("deployment scope (%s) %w (%s)", generatedFile, ErrMergeConflict, baseFile)
+	}
+	return nil
+}
+
+// mergeSection merges the generated entries of an object section, such as
+// parameters, into the base entries. With overwrite, generated entries replace
+// base ones; otherwise an entry defined differently in both is a conflict.
+// It returns nil if neither template has the section.
+func mergeSection(kind string, base, generated json.RawMessage, overwrite bool) (json.RawMessage, []error, error) {
+	if base == nil && generated == nil {
+		return nil, nil, nil
+	}
+	entries := make(map[string]json.RawMessage)
+	if base != nil {
+		if err := json.Unmarshal(base, &entries); err != nil {
+			return nil, nil, fmt.Errorf("parse base template: %ss: %w", kind, err)
+		}
+	}
+	var generatedEntries map[string]json.RawMessage
+	if generated != nil {
+		if err := json.Unmarshal(generated, &generatedEntries); err != nil {
+			return nil, nil, fmt.Errorf("parse generated template: %ss: %w", kind, err)
+		}
+	}
+
+	names := make([]string, 0, len(generatedEntries))
+	for name := range generatedEntries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conflicts []error
+	for _, name := range names {
+		value := generatedEntries[name]
+		if existing, ok := entries[name]; ok && !overwrite && !sameJSON(existing, value) {
+			conflicts = append(conflicts, fmt.Errorf("%s %s %w", kind, name, ErrMergeConflict))
+			continue
+		}
+		entries[name] = value
+	}
+
+	merged, err := json.Marshal(entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged, conflicts, nil
+}
+
+// mergeResources appends the generated resources to the base ones, dropping
+// base resources with the same type and name, which are returned as replaced
+func mergeResources(base, generated json.RawMessage) (json.RawMessage, []string, error) {
+	var baseResources, generatedResources []json.RawMessage
+	if base != nil {
+		if err := json.Unmarshal(base, &baseResources); err != nil {
+			return nil, nil, fmt.Errorf("parse base template: resources: %w", err)
+		}
+	}
+	if err := json.Unmarshal(generated, &generatedResources); err != nil {
+		return nil, nil, fmt.Errorf("parse generated template: resources: %w", err)
+	}
+
+	generatedKeys := make(map[string]bool, len(generatedResources))
+	for _, resource := range generatedResources {
+		key, _ := resourceKey(resource)
+		generatedKeys[strings.ToLower(key)] = true
+	}
+
+	resources := make([]json.RawMessage, 0, len(baseResources)+len(generatedResources))
+	var replaced []string
+	for _, resource := range baseResources {
+		key, ok := resourceKey(resource)
+		if ok && generatedKeys[strings.ToLower(key)] {
+			replaced = append(replaced, key)
+			continue
+		}
+		resources = append(resources, resource)
+	}
+	resources = append(resources, generatedResources...)
+
+	merged, err := json.Marshal(resources)
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged, replaced, nil
+}
+
+// resourceKey returns "<type>/<name>" for a template resource, and false if
+// it has no type or name
+func resourceKey(resource json.RawMessage) (string, bool) {
+	var r struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(resource, &r) != nil || r.Type == "" || r.Name == "" {
+		return "", false
+	}
+	return r.Type + "/" + r.Name, true
+}
+
+// sameJSON reports whether a and b encode the same value, ignoring formatting
+// and key order
+func sameJSON(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// encodeSections writes the sections of a template as a JSON object in
+// sectionOrder, followed by any other sections in name order
+func encodeSections(sections map[string]json.RawMessage) []byte {
+	known := make(map[string]bool, len(sectionOrder))
+	names := make([]string, 0, len(sections))
+	for _, name := range sectionOrder {
+		known[name] = true
+		if _, ok := sections[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var others []string
+	for name := range sections {
+		if !known[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(sections[name])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
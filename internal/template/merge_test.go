@@ -0,0 +1,99 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseTemplate = `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "2.0.0.0",
+  "metadata": {"owner": "network-team"},
+  "parameters": {"location": {"type": "string", "defaultValue": "eastus"}},
+  "variables": {"vnetName": "hub-vnet"},
+  "resources": [
+    {"type": "Microsoft.Network/virtualNetworks", "apiVersion": "2021-05-01", "name": "hub-vnet", "location": "[parameters('location')]"},
+    {"type": "Microsoft.Storage/storageAccounts", "apiVersion": "2019-06-01", "name": "Logs"}
+  ],
+  "outputs": {"vnetId": {"type": "string", "value": "[resourceId('Microsoft.Network/virtualNetworks', 'hub-vnet')]"}}
+}`
+
+func TestMergeTemplates(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup).WithMetadata(map[string]interface{}{"_generator": "wetwire-azure"})
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{Name: "logs", Type: "Microsoft.Storage/storageAccounts"}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{Name: "data", Type: "Microsoft.Storage/storageAccounts"}))
+	generated, err := builder.BuildCompact()
+	require.NoError(t, err)
+
+	merged, replaced, err := MergeTemplates([]byte(baseTemplate), []byte(generated))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Microsoft.Storage/storageAccounts/Logs"}, replaced)
+
+	var result struct {
+		Schema         string                            `json:"$schema"`
+		ContentVersion string                            `json:"contentVersion"`
+		Metadata       map[string]interface{}            `json:"metadata"`
+		Parameters     map[string]map[string]interface{} `json:"parameters"`
+		Variables      map[string]interface{}            `json:"variables"`
+		Resources      []map[string]interface{}          `json:"resources"`
+		Outputs        map[string]interface{}            `json:"outputs"`
+	}
+	require.NoError(t, json.Unmarshal(merged, &result), string(merged))
+
+	assert.Equal(t, "2.0.0.0", result.ContentVersion)
+	assert.Equal(t, map[string]interface{}{"owner": "network-team", "_generator": "wetwire-azure"}, result.Metadata)
+	assert.Contains(t, result.Parameters, "location")
+	assert.Equal(t, "hub-vnet", result.Variables["vnetName"])
+	assert.Contains(t, result.Outputs, "vnetId")
+
+	// The base VNet is kept and the generated storage accounts are appended,
+	// replacing the base account of the same name
+	require.Len(t, result.Resources, 3)
+	assert.Equal(t, "hub-vnet", result.Resources[0]["name"])
+	assert.Equal(t, "Microsoft.Storage/storageAccounts", result.Resources[1]["type"])
+	assert.Equal(t, "data", result.Resources[1]["name"])
+	assert.Equal(t, "logs", result.Resources[2]["name"])
+
+	// Sections are written in template order
+	formatted, err := FormatJSON(merged, false, false)
+	require.NoError(t, err)
+	assert.Regexp(t, `^\{"\$schema":.*"contentVersion":.*"metadata":.*"parameters":.*"variables":.*"resources":.*"outputs":`, formatted)
+}
+
+func TestMergeTemplates_Conflicts(t *testing.T) {
+	generated := `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "parameters": {"location": {"type": "string", "defaultValue": "westus"}},
+  "variables": {"vnetName": "hub-vnet"},
+  "resources": [],
+  "outputs": {"vnetId": {"type": "string", "value": "other"}}
+}`
+
+	_, _, err := MergeTemplates([]byte(baseTemplate), []byte(generated))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrMergeConflict))
+	assert.Contains(t, err.Error(), "parameter location conflicts with the base template")
+	assert.Contains(t, err.Error(), "output vnetId conflicts with the base template")
+	// Identical definitions are not conflicts
+	assert.NotContains(t, err.Error(), "vnetName")
+}
+
+func TestMergeTemplates_Scope(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeSubscription)
+	generated, err := builder.Build()
+	require.NoError(t, err)
+
+	_, _, err = MergeTemplates([]byte(baseTemplate), []byte(generated))
+	require.ErrorIs(t, err, ErrMergeConflict)
+	assert.Contains(t, err.Error(), "deployment scope (subscriptionDeploymentTemplate.json) conflicts with the base template (deploymentTemplate.json)")
+
+	_, _, err = MergeTemplates([]byte("not json"), []byte(generated))
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrMergeConflict))
+}
@@ -0,0 +1,108 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+)
+
+// childResourceParents maps child resource types, which Azure rejects
+// without a parent, to the type of the parent they must be deployed under.
+var childResourceParents = map[string]string{
+	"Microsoft.CognitiveServices/accounts/deployments":                                    "Microsoft.CognitiveServices/accounts",
+	"Microsoft.ContainerService/managedClusters/agentPools":                               "Microsoft.ContainerService/managedClusters",
+	"Microsoft.ContainerService/managedClusters/maintenanceConfigurations":                "Microsoft.ContainerService/managedClusters",
+	"Microsoft.DataFactory/factories/linkedservices":                                      "Microsoft.DataFactory/factories",
+	"Microsoft.DataFactory/factories/pipelines":                                           "Microsoft.DataFactory/factories",
+	"Microsoft.KeyVault/vaults/keys":                                                      "Microsoft.KeyVault/vaults",
+	"Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials":       "Microsoft.ManagedIdentity/userAssignedIdentities",
+	"Microsoft.Network/networkWatchers/flowLogs":                                          "Microsoft.Network/networkWatchers",
+	"Microsoft.Network/privateDnsZones/virtualNetworkLinks":                               "Microsoft.Network/privateDnsZones",
+	"Microsoft.Network/virtualHubs/hubVirtualNetworkConnections":                          "Microsoft.Network/virtualHubs",
+	"Microsoft.Network/virtualNetworks/subnets":                                           "Microsoft.Network/virtualNetworks",
+	"Microsoft.Network/virtualNetworks/virtualNetworkPeerings":                            "Microsoft.Network/virtualNetworks",
+	"Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems": "Microsoft.RecoveryServices/vaults",
+	"Microsoft.Sql/servers/databases":                                                     "Microsoft.Sql/servers",
+	"Microsoft.Sql/servers/elasticPools":                                                  "Microsoft.Sql/servers",
+	"Microsoft.Sql/servers/failoverGroups":                                                "Microsoft.Sql/servers",
+	"Microsoft.Storage/storageAccounts/blobServices":                                      "Microsoft.Storage/storageAccounts",
+	"Microsoft.Storage/storageAccounts/blobServices/containers":                           "Microsoft.Storage/storageAccounts",
+	"Microsoft.Storage/storageAccounts/managementPolicies":                                "Microsoft.Storage/storageAccounts",
+	"Microsoft.Storage/storageAccounts/queueServices":                                     "Microsoft.Storage/storageAccounts",
+	"Microsoft.Storage/storageAccounts/tableServices":                                     "Microsoft.Storage/storageAccounts",
+	"Microsoft.Web/sites/hostNameBindings":                                                "Microsoft.Web/sites",
+	"Microsoft.Web/sites/slots":                                                           "Microsoft.Web/sites",
+}
+
+// validateParents checks that every resource of a known child type either
+// embeds its parent in its ARM name ("server/database") or references or
+// depends on a resource of the parent type. A child named without its parent
+// is renamed "<parent ARM name>/<name>", and a child whose name still has the
+// wrong number of segments for its type is rejected.
+func (tb *TemplateBuilder) validateParents() error {
+	names := make([]string, 0, len(tb.resources))
+	for name := range tb.resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		resource := tb.resources[name]
+		parentType, ok := childResourceParents[resource.Type]
+		if !ok {
+			continue
+		}
+		armName := resource.TemplateName()
+		segments := strings.Count(resource.Type, "/")
+		if strings.Contains(armName, "/") && (isExpression(armName) || nameSegments(armName) == segments) {
+			continue
+		}
+
+		var parent *discover.DiscoveredResource
+		for _, dep := range resource.Dependencies {
+			if depResource, exists := tb.resources[dep]; exists && depResource.Type == parentType {
+				parent = &depResource
+				break
+			}
+		}
+		if parent == nil {
+			return fmt.Errorf("resource %s of type %s has no parent: reference or depend on a %s resource", name, resource.Type, parentType)
+		}
+		resource.ARMName = childName(parent.TemplateName(), armName)
+		if !isExpression(resource.ARMName) && nameSegments(resource.ARMName) != segments {
+			return fmt.Errorf("resource %s of type %s is named %q: want %d name segments", name, resource.Type, resource.ARMName, segments)
+		}
+		tb.resources[name] = resource
+	}
+	return nil
+}
+
+// nameSegments returns the number of "/"-separated segments in an ARM name
+func nameSegments(name string) int {
+	return strings.Count(name, "/") + 1
+}
+
+// childName joins a parent and child ARM name, building a concat()
+// expression when either of them is an expression
+func childName(parent, child string) string {
+	if !isExpression(parent) && !isExpression(child) {
+		return parent + "/" + child
+	}
+	return "[concat(" + expressionArgument(parent) + ", '/', " + expressionArgument(child) + ")]"
+}
+
+// expressionArgument returns s as an argument of an ARM function, nesting an
+// expression and quoting a literal
+func expressionArgument(s string) string {
+	if isExpression(s) {
+		return s[1 : len(s)-1]
+	}
+	return quoteString(s)
+}
+
+// isExpression reports whether s is an ARM template expression
+func isExpression(s string) bool {
+	return strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]")
+}
@@ -0,0 +1,95 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+)
+
+// resolveResourceRefs replaces the intrinsics.ResourceRef markers in the
+// scopes and properties of the builder's resources with resourceId()
+// expressions. It runs after validateParents, so that a reference to a child
+// resource has a name segment per level, like the name the child is emitted
+// with.
+func (tb *TemplateBuilder) resolveResourceRefs() error {
+	names := make([]string, 0, len(tb.resources))
+	for name := range tb.resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Resolving only reads the names and types of the targets, so the
+	// resolved resources can replace the originals as they are built
+	for _, name := range names {
+		resolved, err := tb.resources[name].ResolveResourceRefs(tb.resources)
+		if err != nil {
+			return err
+		}
+		tb.resources[name] = resolved
+	}
+	return nil
+}
+
+// checkResourceRefs fails if an intrinsics.ResourceRef marker is left in the
+// template, which is not a valid ARM expression. The builder resolves the
+// references in resource scopes and properties; any other, such as one in a
+// variable or output, would reach the template as is.
+func checkResourceRefs(template ARMTemplate) error {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return fmt.Errorf("JSON serialization failed: %w", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("JSON serialization failed: %w", err)
+	}
+
+	resources, _ := decoded["resources"].([]interface{})
+	for i, resource := range resources {
+		if path, ref, ok := findResourceRef(resource, ""); ok {
+			return fmt.Errorf("resource %s: unresolved reference %s at %s; call intrinsics.ResourceRef with string literal arguments in a declaration that build resolves", template.Resources[i].Name, ref, path)
+		}
+	}
+	for _, section := range []string{"parameters", "variables", "outputs"} {
+		if path, ref, ok := findResourceRef(decoded[section], section); ok {
+			return fmt.Errorf("unresolved reference %s at %s", ref, path)
+		}
+	}
+	return nil
+}
+
+// findResourceRef returns the JSON path, below path, and the value of the
+// first ResourceRef marker in v, a value decoded from JSON. Object keys are
+// searched in sorted order, so the same marker is reported on every run.
+func findResourceRef(v interface{}, path string) (string, string, bool) {
+	switch v := v.(type) {
+	case string:
+		if _, _, ok := intrinsics.ParseResourceRef(v); ok {
+			return path, v, true
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			elemPath := key
+			if path != "" {
+				elemPath = path + "." + key
+			}
+			if p, ref, ok := findResourceRef(v[key], elemPath); ok {
+				return p, ref, true
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if p, ref, ok := findResourceRef(elem, fmt.Sprintf("%s[%d]", path, i)); ok {
+				return p, ref, true
+			}
+		}
+	}
+	return "", "", false
+}
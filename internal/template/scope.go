@@ -0,0 +1,121 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scope is the ARM deployment scope a template targets.
+type Scope string
+
+// Supported deployment scopes
+const (
+	ScopeResourceGroup   Scope = "resourceGroup"
+	ScopeSubscription    Scope = "subscription"
+	ScopeManagementGroup Scope = "managementGroup"
+	ScopeTenant          Scope = "tenant"
+)
+
+// schemaURLs maps each scope to its deployment template schema
+var schemaURLs = map[Scope]string{
+	ScopeResourceGroup:   "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+	ScopeSubscription:    "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+	ScopeManagementGroup: "https://schema.management.azure.com/schemas/2019-08-01/managementGroupDeploymentTemplate.json#",
+	ScopeTenant:          "https://schema.management.azure.com/schemas/2019-08-01/tenantDeploymentTemplate.json#",
+}
+
+// allScopes lists every scope, for resource types deployable anywhere
+var allScopes = []Scope{ScopeResourceGroup, ScopeSubscription, ScopeManagementGroup, ScopeTenant}
+
+// scopedResourceTypes lists resource types that may be deployed outside a
+// resource group. Types not listed here are only legal at resourceGroup scope.
+var scopedResourceTypes = map[string][]Scope{
+	"Microsoft.Authorization/policyAssignments":    {ScopeResourceGroup, ScopeSubscription, ScopeManagementGroup},
+	"Microsoft.Authorization/policyDefinitions":    {ScopeSubscription, ScopeManagementGroup},
+	"Microsoft.Authorization/policySetDefinitions": {ScopeSubscription, ScopeManagementGroup},
+	"Microsoft.Authorization/roleAssignments":      allScopes,
+	"Microsoft.Authorization/roleDefinitions":      {ScopeResourceGroup, ScopeSubscription, ScopeManagementGroup},
+	"Microsoft.Authorization/locks":                {ScopeResourceGroup, ScopeSubscription},
+	"Microsoft.Resources/deployments":              allScopes,
+	"Microsoft.Resources/resourceGroups":           {ScopeSubscription},
+	"Microsoft.Management/managementGroups":        {ScopeManagementGroup, ScopeTenant},
+	"Microsoft.Subscription/aliases":               {ScopeTenant},
+}
+
+// ParseScope converts a scope name to a Scope. An empty string yields
+// ScopeResourceGroup. Matching is case-insensitive.
+func ParseScope(s string) (Scope, error) {
+	if s == "" {
+		return ScopeResourceGroup, nil
+	}
+	for scope := range schemaURLs {
+		if strings.EqualFold(s, string(scope)) {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("unknown scope %q (must be resourceGroup, subscription, managementGroup, or tenant)", s)
+}
+
+// SchemaURL returns the $schema URL for templates deployed at this scope
+func (s Scope) SchemaURL() string {
+	if url, ok := schemaURLs[s]; ok {
+		return url
+	}
+	return schemaURLs[ScopeResourceGroup]
+}
+
+// Allows reports whether a resource type can be deployed at this scope
+func (s Scope) Allows(resourceType string) bool {
+	scopes, ok := scopedResourceTypes[resourceType]
+	if !ok {
+		return s == ScopeResourceGroup
+	}
+	for _, scope := range scopes {
+		if scope == s {
+			return true
+		}
+	}
+	return false
+}
+
+// globalResourceTypes are the resource types that Azure only accepts in the
+// "global" location
+var globalResourceTypes = map[string]bool{
+	"Microsoft.Network/privateDnsZones":                     true,
+	"Microsoft.Network/privateDnsZones/virtualNetworkLinks": true,
+}
+
+// locationlessResourceTypes are the resource types that have no location:
+// extension resources such as locks and diagnostic settings, which take the
+// location of the resource they apply to, settings of their parent such as
+// AKS maintenance configurations, and resources that span regions, such as
+// SQL failover groups
+var locationlessResourceTypes = map[string]bool{
+	"Microsoft.Authorization/locks":                                        true,
+	"Microsoft.ContainerService/managedClusters/maintenanceConfigurations": true,
+	"Microsoft.Insights/diagnosticSettings":                                true,
+	"Microsoft.Sql/servers/failoverGroups":                                 true,
+}
+
+// resourceLocation returns the location of a resource of resourceType at
+// this scope: "global" for global resource types, none for locationless
+// ones, and the default location expression otherwise
+func (s Scope) resourceLocation(resourceType string) string {
+	if globalResourceTypes[resourceType] {
+		return "global"
+	}
+	if locationlessResourceTypes[resourceType] {
+		return ""
+	}
+	return s.locationExpression()
+}
+
+// locationExpression returns the default location expression for resources
+// at this scope. resourceGroup() is unavailable outside resource group
+// deployments, so other scopes fall back to the deployment location.
+func (s Scope) locationExpression() string {
+	if s == ScopeResourceGroup {
+		return "[resourceGroup().location]"
+	}
+	return "[deployment().location]"
+}
@@ -0,0 +1,187 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Scope
+		wantErr bool
+	}{
+		{input: "", want: ScopeResourceGroup},
+		{input: "resourceGroup", want: ScopeResourceGroup},
+		{input: "subscription", want: ScopeSubscription},
+		{input: "ManagementGroup", want: ScopeManagementGroup},
+		{input: "tenant", want: ScopeTenant},
+		{input: "region", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseScope(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScopeAllows(t *testing.T) {
+	tests := []struct {
+		name         string
+		scope        Scope
+		resourceType string
+		want         bool
+	}{
+		{"storage at resource group", ScopeResourceGroup, "Microsoft.Storage/storageAccounts", true},
+		{"storage at subscription", ScopeSubscription, "Microsoft.Storage/storageAccounts", false},
+		{"policy assignment at subscription", ScopeSubscription, "Microsoft.Authorization/policyAssignments", true},
+		{"resource group at subscription", ScopeSubscription, "Microsoft.Resources/resourceGroups", true},
+		{"resource group at resource group", ScopeResourceGroup, "Microsoft.Resources/resourceGroups", false},
+		{"management group at tenant", ScopeTenant, "Microsoft.Management/managementGroups", true},
+		{"policy definition at management group", ScopeManagementGroup, "Microsoft.Authorization/policyDefinitions", true},
+		{"policy definition at resource group", ScopeResourceGroup, "Microsoft.Authorization/policyDefinitions", false},
+		{"policy definition at tenant", ScopeTenant, "Microsoft.Authorization/policyDefinitions", false},
+		{"policy set definition at tenant", ScopeTenant, "Microsoft.Authorization/policySetDefinitions", false},
+		{"policy assignment at tenant", ScopeTenant, "Microsoft.Authorization/policyAssignments", false},
+		{"role definition at tenant", ScopeTenant, "Microsoft.Authorization/roleDefinitions", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.scope.Allows(tt.resourceType))
+		})
+	}
+}
+
+func TestBuild_SubscriptionScope(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeSubscription)
+
+	err := builder.AddResource(discover.DiscoveredResource{
+		Name: "requireTags",
+		Type: "Microsoft.Authorization/policyAssignments",
+	})
+	require.NoError(t, err)
+
+	result, err := builder.Build()
+	require.NoError(t, err)
+
+	var template map[string]interface{}
+	err = json.Unmarshal([]byte(result), &template)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#", template["$schema"])
+
+	resources := template["resources"].([]interface{})
+	require.Len(t, resources, 1)
+	resource := resources[0].(map[string]interface{})
+	assert.Equal(t, "[deployment().location]", resource["location"])
+}
+
+func TestBuild_ScopeIncompatibleResource(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeSubscription)
+
+	err := builder.AddResource(discover.DiscoveredResource{
+		Name: "myStorage",
+		Type: "Microsoft.Storage/storageAccounts",
+	})
+	require.NoError(t, err)
+
+	_, err = builder.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "myStorage")
+	assert.Contains(t, err.Error(), "subscription scope")
+}
+
+func TestBuild_PolicyDefinitionAtTenantScope(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeTenant)
+
+	err := builder.AddResource(discover.DiscoveredResource{
+		Name: "requireTagsDefinition",
+		Type: "Microsoft.Authorization/policyDefinitions",
+	})
+	require.NoError(t, err)
+
+	_, err = builder.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requireTagsDefinition")
+	assert.Contains(t, err.Error(), "tenant scope")
+}
+
+func TestBuild_GlobalLocation(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "blobZone",
+		Type: "Microsoft.Network/privateDnsZones",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "appLink",
+		Type:         "Microsoft.Network/privateDnsZones/virtualNetworkLinks",
+		Dependencies: []string{"blobZone"},
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "appVNet",
+		Type: "Microsoft.Network/virtualNetworks",
+	}))
+
+	result, err := builder.Build()
+	require.NoError(t, err)
+
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result), &template))
+
+	locations := make(map[string]interface{})
+	for _, r := range template["resources"].([]interface{}) {
+		resource := r.(map[string]interface{})
+		locations[resource["name"].(string)] = resource["location"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"blobZone":         "global",
+		"blobZone/appLink": "global",
+		"appVNet":          "[resourceGroup().location]",
+	}, locations)
+}
+
+func TestBuild_ManagementLockScope(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "Logs",
+		Type: "Microsoft.Storage/storageAccounts",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "LogsDeleteLock",
+		Type:         "Microsoft.Authorization/locks",
+		Dependencies: []string{"Logs"},
+		ARMName:      "logs-delete-lock",
+		Scope:        "[resourceId('Microsoft.Storage/storageAccounts', 'Logs')]",
+		Properties:   map[string]any{"level": "CanNotDelete"},
+	}))
+
+	result, err := builder.Build()
+	require.NoError(t, err)
+
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result), &template))
+
+	resources := template["resources"].([]interface{})
+	require.Len(t, resources, 2)
+	lock := resources[1].(map[string]interface{})
+	assert.Equal(t, "logs-delete-lock", lock["name"])
+	assert.Equal(t, "2020-05-01", lock["apiVersion"])
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'Logs')]", lock["scope"])
+	assert.NotContains(t, lock, "location", "locks take the location of the locked resource")
+	assert.Equal(t, []interface{}{"[resourceId('Microsoft.Storage/storageAccounts', 'Logs')]"}, lock["dependsOn"])
+	assert.Equal(t, map[string]interface{}{"level": "CanNotDelete"}, lock["properties"])
+}
@@ -2,19 +2,31 @@
 package template
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 
 	"github.com/lex00/wetwire-azure-go/internal/discover"
+	rawtemplate "github.com/lex00/wetwire-azure-go/template"
 )
 
 // TemplateBuilder aggregates resources, parameters, variables, and outputs
 // to build a complete ARM template.
 type TemplateBuilder struct {
-	resources  map[string]discover.DiscoveredResource
-	parameters map[string]Parameter
-	variables  map[string]interface{}
-	outputs    map[string]Output
+	scope         Scope
+	minAPIVersion string
+	maxResources  int
+	sortKeys      bool
+	omitEmpty     bool
+	context       ResourceContext
+	metadata      map[string]interface{}
+	resources     map[string]discover.DiscoveredResource
+	parameters    map[string]Parameter
+	variables     map[string]interface{}
+	outputs       map[string]Output
+	transforms    []rawtemplate.Transform
 }
 
 // Parameter represents an ARM template parameter
@@ -35,45 +47,116 @@ type Output struct {
 type ARMTemplate struct {
 	Schema         string                 `json:"$schema"`
 	ContentVersion string                 `json:"contentVersion"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
 	Parameters     map[string]Parameter   `json:"parameters"`
 	Variables      map[string]interface{} `json:"variables"`
 	Resources      []ARMResource          `json:"resources"`
 	Outputs        map[string]Output      `json:"outputs"`
 }
 
+// armTemplateOmitEmpty is ARMTemplate with the optional parameters,
+// variables, and outputs sections omitted when they are empty
+type armTemplateOmitEmpty struct {
+	Schema         string                 `json:"$schema"`
+	ContentVersion string                 `json:"contentVersion"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Parameters     map[string]Parameter   `json:"parameters,omitempty"`
+	Variables      map[string]interface{} `json:"variables,omitempty"`
+	Resources      []ARMResource          `json:"resources"`
+	Outputs        map[string]Output      `json:"outputs,omitempty"`
+}
+
 // ARMResource represents a resource in the ARM template
 type ARMResource struct {
-	Name       string        `json:"name"`
-	Type       string        `json:"type"`
-	APIVersion string        `json:"apiVersion"`
-	Location   string        `json:"location,omitempty"`
-	DependsOn  []string      `json:"dependsOn,omitempty"`
-	Properties interface{}   `json:"properties,omitempty"`
-	Tags       interface{}   `json:"tags,omitempty"`
-	SKU        interface{}   `json:"sku,omitempty"`
-	Kind       string        `json:"kind,omitempty"`
-	Identity   interface{}   `json:"identity,omitempty"`
-	Zones      []string      `json:"zones,omitempty"`
-	Plan       interface{}   `json:"plan,omitempty"`
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	APIVersion string      `json:"apiVersion"`
+	Scope      string      `json:"scope,omitempty"`
+	Location   string      `json:"location,omitempty"`
+	DependsOn  []string    `json:"dependsOn,omitempty"`
+	Properties interface{} `json:"properties,omitempty"`
+	Tags       interface{} `json:"tags,omitempty"`
+	SKU        interface{} `json:"sku,omitempty"`
+	Kind       string      `json:"kind,omitempty"`
+	Identity   interface{} `json:"identity,omitempty"`
+	Zones      []string    `json:"zones,omitempty"`
+	Plan       interface{} `json:"plan,omitempty"`
+	Copy       *Copy       `json:"copy,omitempty"`
+}
+
+// Copy represents the copy element of a resource deployed in a loop
+type Copy struct {
+	Name      string      `json:"name"`
+	Count     interface{} `json:"count"`
+	Mode      string      `json:"mode,omitempty"`
+	BatchSize int         `json:"batchSize,omitempty"`
 }
 
-// NewTemplateBuilder creates a new TemplateBuilder instance
-func NewTemplateBuilder() *TemplateBuilder {
+// NewTemplateBuilder creates a new TemplateBuilder instance targeting the
+// given deployment scope. An empty scope is treated as ScopeResourceGroup.
+func NewTemplateBuilder(scope Scope) *TemplateBuilder {
+	if scope == "" {
+		scope = ScopeResourceGroup
+	}
 	return &TemplateBuilder{
+		scope:      scope,
 		resources:  make(map[string]discover.DiscoveredResource),
 		parameters: make(map[string]Parameter),
 		variables:  make(map[string]interface{}),
 		outputs:    make(map[string]Output),
+		transforms: rawtemplate.Transforms(),
 	}
 }
 
+// WithMetadata sets the template's top-level metadata section, such as the
+// _generator stamp. An empty map omits the section.
+func (tb *TemplateBuilder) WithMetadata(metadata map[string]interface{}) *TemplateBuilder {
+	tb.metadata = metadata
+	return tb
+}
+
+// WithTransforms replaces the transforms applied to the assembled template,
+// which are those registered with template.RegisterTransform by default.
+func (tb *TemplateBuilder) WithTransforms(transforms ...rawtemplate.Transform) *TemplateBuilder {
+	tb.transforms = transforms
+	return tb
+}
+
+// WithSortedKeys makes Build and BuildCompact emit the keys of every JSON
+// object in sorted order, including the fields of the template and its
+// resources. By default map keys are sorted, as encoding/json writes them,
+// but fields keep their declaration order, which is ARM's conventional
+// order ($schema first, name and type first in resources).
+func (tb *TemplateBuilder) WithSortedKeys(sorted bool) *TemplateBuilder {
+	tb.sortKeys = sorted
+	return tb
+}
+
+// WithEmptySections makes Build and BuildCompact emit the parameters,
+// variables, and outputs sections even when they are empty, which is the
+// default. With include false they are omitted, since ARM treats them as
+// optional.
+func (tb *TemplateBuilder) WithEmptySections(include bool) *TemplateBuilder {
+	tb.omitEmpty = !include
+	return tb
+}
+
 // AddResource adds a discovered resource to the template builder.
-// Returns an error if a resource with the same name already exists.
+// Returns an error if a resource with the same name already exists. If the
+// resource's Value, or a value nested in it, implements Validatable and
+// reports errors, the resource is still added and a *ValidationError holding
+// every error is returned.
 func (tb *TemplateBuilder) AddResource(resource discover.DiscoveredResource) error {
 	if _, exists := tb.resources[resource.Name]; exists {
 		return fmt.Errorf("resource with name %s already exists", resource.Name)
 	}
 	tb.resources[resource.Name] = resource
+
+	if resource.Value != nil {
+		if errs := validateValue(reflect.ValueOf(resource.Value)); len(errs) > 0 {
+			return &ValidationError{Resource: resource.Name, Errors: errs}
+		}
+	}
 	return nil
 }
 
@@ -131,32 +214,176 @@ func (tb *TemplateBuilder) AddOutput(name, outputType string, value interface{})
 	return nil
 }
 
-// Build executes the build pipeline and returns the ARM template JSON.
+// Build executes the build pipeline and returns the ARM template as indented JSON.
 // Pipeline stages: DISCOVER → VALIDATE → ORDER → SERIALIZE → EMIT
 func (tb *TemplateBuilder) Build() (string, error) {
+	template, err := tb.buildTemplate()
+	if err != nil {
+		return "", err
+	}
+
+	// EMIT - write output as indented JSON
+	return tb.emit(template, true)
+}
+
+// BuildCompact is like Build but emits the template as compact single-line JSON.
+func (tb *TemplateBuilder) BuildCompact() (string, error) {
+	template, err := tb.buildTemplate()
+	if err != nil {
+		return "", err
+	}
+
+	// EMIT - write output as compact JSON
+	return tb.emit(template, false)
+}
+
+// emit marshals the template, omitting empty sections and sorting object keys
+// if requested, and indents the result if indent is set.
+func (tb *TemplateBuilder) emit(template ARMTemplate, indent bool) (string, error) {
+	var value interface{} = template
+	if tb.omitEmpty {
+		value = armTemplateOmitEmpty(template)
+	}
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("JSON serialization failed: %w", err)
+	}
+	if len(tb.transforms) > 0 {
+		if jsonBytes, err = tb.transform(jsonBytes); err != nil {
+			return "", err
+		}
+	}
+	return FormatJSON(jsonBytes, indent, tb.sortKeys)
+}
+
+// transform applies the builder's transforms, in order, to the template
+// JSON data. Empty sections stay omitted or emitted as the builder is set.
+func (tb *TemplateBuilder) transform(data []byte) ([]byte, error) {
+	// Numbers are decoded as json.Number so they are written back unchanged
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var template rawtemplate.Template
+	if err := decoder.Decode(&template); err != nil {
+		return nil, fmt.Errorf("transform failed: %w", err)
+	}
+	for _, transform := range tb.transforms {
+		template = transform(template)
+	}
+
+	var value interface{} = template
+	if !tb.omitEmpty {
+		value = struct {
+			rawtemplate.Template
+			Parameters map[string]any `json:"parameters"`
+			Variables  map[string]any `json:"variables"`
+			Outputs    map[string]any `json:"outputs"`
+		}{template, emptyIfNil(template.Parameters), emptyIfNil(template.Variables), emptyIfNil(template.Outputs)}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("transform failed: %w", err)
+	}
+	return data, nil
+}
+
+// emptyIfNil returns m, or an empty map if m is nil
+func emptyIfNil(m map[string]any) map[string]any {
+	if m == nil {
+		return map[string]any{}
+	}
+	return m
+}
+
+// FormatJSON reformats the JSON document data, sorting the keys of every
+// object if sortKeys is set and indenting it if indent is set, as Build and
+// BuildCompact format templates.
+func FormatJSON(data []byte, indent, sortKeys bool) (string, error) {
+	jsonBytes := data
+	if sortKeys {
+		// Decoding into maps and encoding again sorts every object's keys
+		decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+		decoder.UseNumber()
+		var ordered interface{}
+		if err := decoder.Decode(&ordered); err != nil {
+			return "", fmt.Errorf("JSON serialization failed: %w", err)
+		}
+		var err error
+		if jsonBytes, err = json.Marshal(ordered); err != nil {
+			return "", fmt.Errorf("JSON serialization failed: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if !indent {
+		if err := json.Compact(&buf, jsonBytes); err != nil {
+			return "", fmt.Errorf("JSON serialization failed: %w", err)
+		}
+		return buf.String(), nil
+	}
+	if err := json.Indent(&buf, jsonBytes, "", "  "); err != nil {
+		return "", fmt.Errorf("JSON serialization failed: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// buildTemplate runs the pipeline up to and including SERIALIZE.
+func (tb *TemplateBuilder) buildTemplate() (ARMTemplate, error) {
 	// DISCOVER - resources are already discovered and added via AddResource
 
-	// VALIDATE - check references and detect cycles
+	// VALIDATE - check the resource count, scope, API versions, references,
+	// parents, and detect cycles
+	if err := tb.validateResourceCount(); err != nil {
+		return ARMTemplate{}, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := tb.validateScope(); err != nil {
+		return ARMTemplate{}, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := tb.validateAPIVersions(); err != nil {
+		return ARMTemplate{}, fmt.Errorf("validation failed: %w", err)
+	}
 	if err := tb.validateReferences(); err != nil {
-		return "", fmt.Errorf("validation failed: %w", err)
+		return ARMTemplate{}, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := tb.validateParents(); err != nil {
+		return ARMTemplate{}, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// RESOLVE - turn resource references into resourceId() expressions, now
+	// that child resources are named under their parents
+	if err := tb.resolveResourceRefs(); err != nil {
+		return ARMTemplate{}, fmt.Errorf("resolving references failed: %w", err)
 	}
 
 	// ORDER - topological sort by dependencies
 	orderedResources, err := tb.topologicalSort()
 	if err != nil {
-		return "", fmt.Errorf("ordering failed: %w", err)
+		return ARMTemplate{}, fmt.Errorf("ordering failed: %w", err)
 	}
 
-	// SERIALIZE - convert to ARM JSON format
+	// SERIALIZE - convert to ARM JSON format, which must not keep any
+	// reference that discovery left unresolved
 	template := tb.serialize(orderedResources)
+	if err := checkResourceRefs(template); err != nil {
+		return ARMTemplate{}, fmt.Errorf("serialization failed: %w", err)
+	}
+	return template, nil
+}
 
-	// EMIT - write output as JSON
-	jsonBytes, err := json.MarshalIndent(template, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("JSON serialization failed: %w", err)
+// validateScope checks that every resource type can be deployed at the builder's scope
+func (tb *TemplateBuilder) validateScope() error {
+	names := make([]string, 0, len(tb.resources))
+	for name := range tb.resources {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	return string(jsonBytes), nil
+	for _, name := range names {
+		resource := tb.resources[name]
+		if !tb.scope.Allows(resource.Type) {
+			return fmt.Errorf("resource %s of type %s cannot be deployed at %s scope", name, resource.Type, tb.scope)
+		}
+	}
+	return nil
 }
 
 // validateReferences checks that all referenced resources exist and detects cycles
@@ -205,24 +432,26 @@ func (tb *TemplateBuilder) validateReferences() error {
 	return nil
 }
 
-// topologicalSort performs a topological sort on resources using Kahn's algorithm
+// topologicalSort performs a topological sort on resources using Kahn's
+// algorithm. Resources that are ready at the same time are taken in name
+// order, so the result is the same on every run.
 func (tb *TemplateBuilder) topologicalSort() ([]discover.DiscoveredResource, error) {
-	// Build in-degree map
-	inDegree := make(map[string]int)
+	names := make([]string, 0, len(tb.resources))
 	for name := range tb.resources {
-		inDegree[name] = 0
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	for _, resource := range tb.resources {
-		for range resource.Dependencies {
-			inDegree[resource.Name]++
-		}
+	// Build in-degree map
+	inDegree := make(map[string]int)
+	for _, name := range names {
+		inDegree[name] = len(tb.resources[name].Dependencies)
 	}
 
 	// Initialize queue with resources that have no dependencies
 	queue := []string{}
-	for name, degree := range inDegree {
-		if degree == 0 {
+	for _, name := range names {
+		if inDegree[name] == 0 {
 			queue = append(queue, name)
 		}
 	}
@@ -237,8 +466,8 @@ func (tb *TemplateBuilder) topologicalSort() ([]discover.DiscoveredResource, err
 		sorted = append(sorted, tb.resources[current])
 
 		// Find all resources that depend on current
-		for name, resource := range tb.resources {
-			for _, dep := range resource.Dependencies {
+		for _, name := range names {
+			for _, dep := range tb.resources[name].Dependencies {
 				if dep == current {
 					inDegree[name]--
 					if inDegree[name] == 0 {
@@ -257,24 +486,89 @@ func (tb *TemplateBuilder) topologicalSort() ([]discover.DiscoveredResource, err
 	return sorted, nil
 }
 
+// applyRawResource copies the fields of a template.RawResource declaration
+// to its ARM resource as written, with the placeholders of the resource IDs
+// in its objects replaced. A declaration without a location gets none.
+func applyRawResource(armResource *ARMResource, raw *rawtemplate.RawResource, context ResourceContext) {
+	armResource.Location = raw.Location
+	armResource.Kind = raw.Kind
+	if raw.SKU != nil {
+		armResource.SKU = context.resolveValue(raw.SKU)
+	}
+	if raw.Identity != nil {
+		armResource.Identity = context.resolveValue(raw.Identity)
+	}
+	if raw.Plan != nil {
+		armResource.Plan = context.resolveValue(raw.Plan)
+	}
+	armResource.Zones = raw.Zones
+	if raw.Tags != nil {
+		armResource.Tags = raw.Tags
+	}
+	if raw.Properties != nil {
+		armResource.Properties = context.resolveValue(raw.Properties)
+	}
+}
+
+// copyName returns the name of the instances of a copy loop over a resource
+// named name: the name followed by the instance's index. A name that is an
+// expression, such as a naming.Unique name, is nested rather than quoted.
+func copyName(name string) string {
+	if isExpression(name) {
+		return "[concat(" + name[1:len(name)-1] + ", copyIndex())]"
+	}
+	return "[concat(" + quoteString(name) + ", copyIndex())]"
+}
+
 // serialize converts the ordered resources into an ARM template structure
 func (tb *TemplateBuilder) serialize(orderedResources []discover.DiscoveredResource) ARMTemplate {
 	armResources := make([]ARMResource, 0, len(orderedResources))
+	context := tb.context
+	context.scope = tb.scope
 
 	for _, resource := range orderedResources {
 		armResource := ARMResource{
-			Name:       resource.Name,
+			Name:       resource.TemplateName(),
 			Type:       resource.Type,
-			APIVersion: getAPIVersion(resource.Type),
-			Location:   "[resourceGroup().location]",
+			APIVersion: resolveAPIVersion(resource),
+			Location:   tb.scope.resourceLocation(resource.Type),
+		}
+		if resource.Scope != "" {
+			armResource.Scope = context.resolve(resource.Scope)
+		}
+		if resource.Properties != nil {
+			armResource.Properties = context.resolveValue(resource.Properties)
+		}
+		if raw := resource.Raw; raw != nil {
+			applyRawResource(&armResource, raw, context)
+		}
+
+		// Resources in a copy loop get one instance per iteration, each
+		// named with its index
+		if loop := resource.Copy; loop != nil {
+			armResource.Name = copyName(resource.TemplateName())
+			armResource.Copy = &Copy{
+				Name:      loop.Name,
+				Count:     loop.Count,
+				Mode:      loop.Mode,
+				BatchSize: loop.BatchSize,
+			}
 		}
 
 		// Add dependsOn if there are dependencies
 		if len(resource.Dependencies) > 0 {
-			dependsOn := make([]string, 0, len(resource.Dependencies))
-			for _, dep := range resource.Dependencies {
+			// Discovery does not order dependencies; sort them for stable output
+			deps := append([]string(nil), resource.Dependencies...)
+			sort.Strings(deps)
+			dependsOn := make([]string, 0, len(deps))
+			for _, dep := range deps {
 				depResource := tb.resources[dep]
-				dependsOn = append(dependsOn, fmt.Sprintf("[resourceId('%s', '%s')]", depResource.Type, dep))
+				if depResource.Copy != nil {
+					// Depending on the loop name waits for every instance
+					dependsOn = append(dependsOn, depResource.Copy.Name)
+					continue
+				}
+				dependsOn = append(dependsOn, depResource.ResourceID())
 			}
 			armResource.DependsOn = dependsOn
 		}
@@ -283,35 +577,12 @@ func (tb *TemplateBuilder) serialize(orderedResources []discover.DiscoveredResou
 	}
 
 	return ARMTemplate{
-		Schema:         "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		Schema:         tb.scope.SchemaURL(),
 		ContentVersion: "1.0.0.0",
+		Metadata:       tb.metadata,
 		Parameters:     tb.parameters,
 		Variables:      tb.variables,
 		Resources:      armResources,
 		Outputs:        tb.outputs,
 	}
 }
-
-// getAPIVersion returns the appropriate API version for a given resource type
-func getAPIVersion(resourceType string) string {
-	apiVersions := map[string]string{
-		"Microsoft.Storage/storageAccounts":          "2021-04-01",
-		"Microsoft.Compute/virtualMachines":          "2021-07-01",
-		"Microsoft.Network/virtualNetworks":          "2021-02-01",
-		"Microsoft.Network/networkInterfaces":        "2021-02-01",
-		"Microsoft.Network/publicIPAddresses":        "2021-02-01",
-		"Microsoft.Network/networkSecurityGroups":    "2021-02-01",
-		"Microsoft.KeyVault/vaults":                  "2021-06-01",
-		"Microsoft.Sql/servers":                      "2021-02-01",
-		"Microsoft.Sql/servers/databases":            "2021-02-01",
-		"Microsoft.Web/sites":                        "2021-01-15",
-		"Microsoft.ContainerRegistry/registries":     "2021-06-01",
-		"Microsoft.ContainerService/managedClusters": "2021-05-01",
-	}
-
-	if version, ok := apiVersions[resourceType]; ok {
-		return version
-	}
-
-	return "2021-04-01" // default version
-}
@@ -2,15 +2,18 @@ package template
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/intrinsics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestNewTemplateBuilder(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	assert.NotNil(t, builder)
 	assert.NotNil(t, builder.resources)
@@ -47,7 +50,7 @@ func TestAddResource(t *testing.T) {
 		},
 	}
 
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := builder.AddResource(tt.resource)
@@ -81,7 +84,7 @@ func TestAddParameter(t *testing.T) {
 		},
 	}
 
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := builder.AddParameter(tt.paramName, tt.paramType, nil)
@@ -115,7 +118,7 @@ func TestAddVariable(t *testing.T) {
 		},
 	}
 
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := builder.AddVariable(tt.varName, tt.varValue)
@@ -152,7 +155,7 @@ func TestAddOutput(t *testing.T) {
 		},
 	}
 
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := builder.AddOutput(tt.outputName, tt.outputType, tt.value)
@@ -166,7 +169,7 @@ func TestAddOutput(t *testing.T) {
 }
 
 func TestBuild_EmptyTemplate(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	result, err := builder.Build()
 	require.NoError(t, err)
@@ -186,7 +189,7 @@ func TestBuild_EmptyTemplate(t *testing.T) {
 }
 
 func TestBuild_WithResources(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	// Add resources
 	err := builder.AddResource(discover.DiscoveredResource{
@@ -215,7 +218,7 @@ func TestBuild_WithResources(t *testing.T) {
 }
 
 func TestBuild_WithDependencies(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	// Add storage account (no dependencies)
 	err := builder.AddResource(discover.DiscoveredResource{
@@ -262,8 +265,52 @@ func TestBuild_WithDependencies(t *testing.T) {
 	assert.Contains(t, dependsOn[0].(string), "myStorage")
 }
 
+// TestBuild_DependsOnARMName tests that dependsOn names the ARM resources
+// that raw and expanded resources become, not their variables
+func TestBuild_DependsOnARMName(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:    "Bastion",
+		Type:    "Microsoft.Network/bastionHosts",
+		ARMName: "my-bastion",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "Logs",
+		Type:         "Microsoft.Storage/storageAccounts",
+		Dependencies: []string{"Bastion"},
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "LogsDeleteLock",
+		Type:         "Microsoft.Authorization/locks",
+		Dependencies: []string{"Logs"},
+		ARMName:      "logs-delete-lock",
+		Scope:        "[resourceId('Microsoft.Storage/storageAccounts', 'Logs')]",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "Audit",
+		Type:         "Microsoft.Insights/actionGroups",
+		Dependencies: []string{"LogsDeleteLock"},
+	}))
+
+	result, err := builder.Build()
+	require.NoError(t, err)
+
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result), &template))
+
+	dependsOn := map[string]interface{}{}
+	for _, r := range template["resources"].([]interface{}) {
+		resource := r.(map[string]interface{})
+		dependsOn[resource["name"].(string)] = resource["dependsOn"]
+	}
+	assert.Contains(t, dependsOn, "my-bastion")
+	assert.Equal(t, []interface{}{"[resourceId('Microsoft.Network/bastionHosts', 'my-bastion')]"}, dependsOn["Logs"])
+	assert.Equal(t, []interface{}{"[extensionResourceId(resourceId('Microsoft.Storage/storageAccounts', 'Logs'), 'Microsoft.Authorization/locks', 'logs-delete-lock')]"}, dependsOn["Audit"])
+}
+
 func TestBuild_CyclicDependency(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	// Add resource A that depends on B
 	err := builder.AddResource(discover.DiscoveredResource{
@@ -292,7 +339,7 @@ func TestBuild_CyclicDependency(t *testing.T) {
 }
 
 func TestBuild_MissingDependency(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	// Add resource that depends on non-existent resource
 	err := builder.AddResource(discover.DiscoveredResource{
@@ -310,8 +357,203 @@ func TestBuild_MissingDependency(t *testing.T) {
 	assert.Contains(t, err.Error(), "nonExistentStorage")
 }
 
+func TestBuild_ResourceRefs(t *testing.T) {
+	newBuilder := func(ref string) *TemplateBuilder {
+		builder := NewTemplateBuilder(ScopeResourceGroup)
+		require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+			Name:    "AppVNet",
+			Type:    "Microsoft.Network/virtualNetworks",
+			ARMName: "app-vnet",
+		}))
+		require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+			Name:         "AppEndpoint",
+			Type:         "Microsoft.Network/privateEndpoints",
+			ARMName:      "app-pe",
+			Dependencies: []string{"AppVNet"},
+			Properties:   map[string]any{"subnet": map[string]any{"id": ref}},
+		}))
+		return builder
+	}
+
+	result, err := newBuilder(intrinsics.ResourceRef("AppVNet", "subnets", "pe")).BuildCompact()
+	require.NoError(t, err)
+	assert.Contains(t, result, `"properties":{"subnet":{"id":"[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'pe')]"}}`)
+
+	_, err = newBuilder(intrinsics.ResourceRef("OtherVNet")).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ResourceRef to unknown resource OtherVNet")
+
+	// A reference outside resource scopes and properties is not resolved
+	builder := newBuilder(intrinsics.ResourceRef("AppVNet"))
+	require.NoError(t, builder.AddOutput("subnetId", "string", intrinsics.ResourceRef("AppVNet", "subnets", "pe")))
+	_, err = builder.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unresolved reference [wetwire.resourceRef('AppVNet', 'subnets', 'pe')] at outputs.subnetId.value")
+}
+
+func TestBuild_ResourceRefToChild(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:    "AppVNet",
+		Type:    "Microsoft.Network/virtualNetworks",
+		ARMName: "app-vnet",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "PESubnet",
+		Type:         "Microsoft.Network/virtualNetworks/subnets",
+		ARMName:      "pe",
+		Dependencies: []string{"AppVNet"},
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "AppEndpoint",
+		Type:         "Microsoft.Network/privateEndpoints",
+		ARMName:      "app-pe",
+		Dependencies: []string{"PESubnet"},
+		Properties:   map[string]any{"subnet": map[string]any{"id": intrinsics.ResourceRef("PESubnet")}},
+	}))
+
+	// The reference is resolved with the name the subnet is emitted with
+	result, err := builder.BuildCompact()
+	require.NoError(t, err)
+	assert.Contains(t, result, `"name":"app-vnet/pe"`)
+	assert.Contains(t, result, `"properties":{"subnet":{"id":"[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'pe')]"}}`)
+}
+
+func TestBuild_ChildResourceParents(t *testing.T) {
+	sqlServer := discover.DiscoveredResource{
+		Name: "sqlServer",
+		Type: "Microsoft.Sql/servers",
+	}
+
+	tests := []struct {
+		name     string
+		database discover.DiscoveredResource
+		wantName string
+		wantErr  string
+	}{
+		{
+			name: "orphan database",
+			database: discover.DiscoveredResource{
+				Name: "appDB",
+				Type: "Microsoft.Sql/servers/databases",
+			},
+			wantErr: "resource appDB of type Microsoft.Sql/servers/databases has no parent: reference or depend on a Microsoft.Sql/servers resource",
+		},
+		{
+			name: "database referencing its server",
+			database: discover.DiscoveredResource{
+				Name:         "appDB",
+				Type:         "Microsoft.Sql/servers/databases",
+				ARMName:      "appdb",
+				Dependencies: []string{"sqlServer"},
+			},
+			wantName: "sqlServer/appdb",
+		},
+		{
+			name: "database with the server in its name",
+			database: discover.DiscoveredResource{
+				Name:    "appDB",
+				Type:    "Microsoft.Sql/servers/databases",
+				ARMName: "existing-server/appdb",
+			},
+			wantName: "existing-server/appdb",
+		},
+		{
+			name: "database with an expression name",
+			database: discover.DiscoveredResource{
+				Name:         "appDB",
+				Type:         "Microsoft.Sql/servers/databases",
+				ARMName:      "[parameters('dbName')]",
+				Dependencies: []string{"sqlServer"},
+			},
+			wantName: "[concat('sqlServer', '/', parameters('dbName'))]",
+		},
+		{
+			name: "database with too many name segments",
+			database: discover.DiscoveredResource{
+				Name:         "appDB",
+				Type:         "Microsoft.Sql/servers/databases",
+				ARMName:      "app/db/main",
+				Dependencies: []string{"sqlServer"},
+			},
+			wantErr: `resource appDB of type Microsoft.Sql/servers/databases is named "sqlServer/app/db/main": want 2 name segments`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewTemplateBuilder(ScopeResourceGroup)
+			require.NoError(t, builder.AddResource(sqlServer))
+			require.NoError(t, builder.AddResource(tt.database))
+
+			result, err := builder.Build()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var template ARMTemplate
+			require.NoError(t, json.Unmarshal([]byte(result), &template))
+			require.Len(t, template.Resources, 2)
+			for _, resource := range template.Resources {
+				if resource.Type == tt.database.Type {
+					assert.Equal(t, tt.wantName, resource.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestBuild_ChildResourceNames(t *testing.T) {
+	tests := []struct {
+		parent discover.DiscoveredResource
+		child  discover.DiscoveredResource
+		want   string
+	}{
+		{
+			parent: discover.DiscoveredResource{Name: "Cluster", Type: "Microsoft.ContainerService/managedClusters", ARMName: "app-aks"},
+			child:  discover.DiscoveredResource{Name: "UserPool", Type: "Microsoft.ContainerService/managedClusters/agentPools", ARMName: "user", Dependencies: []string{"Cluster"}},
+			want:   "app-aks/user",
+		},
+		{
+			parent: discover.DiscoveredResource{Name: "Cluster", Type: "Microsoft.ContainerService/managedClusters", ARMName: "app-aks"},
+			child:  discover.DiscoveredResource{Name: "Maintenance", Type: "Microsoft.ContainerService/managedClusters/maintenanceConfigurations", ARMName: "aksManagedAutoUpgradeSchedule", Dependencies: []string{"Cluster"}},
+			want:   "app-aks/aksManagedAutoUpgradeSchedule",
+		},
+		{
+			parent: discover.DiscoveredResource{Name: "Server", Type: "Microsoft.Sql/servers", ARMName: "app-sql"},
+			child:  discover.DiscoveredResource{Name: "Pool", Type: "Microsoft.Sql/servers/elasticPools", ARMName: "app-pool", Dependencies: []string{"Server"}},
+			want:   "app-sql/app-pool",
+		},
+		{
+			parent: discover.DiscoveredResource{Name: "Server", Type: "Microsoft.Sql/servers", ARMName: "app-sql"},
+			child:  discover.DiscoveredResource{Name: "Failover", Type: "Microsoft.Sql/servers/failoverGroups", ARMName: "app-fog", Dependencies: []string{"Server"}},
+			want:   "app-sql/app-fog",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.child.Type, func(t *testing.T) {
+			builder := NewTemplateBuilder(ScopeResourceGroup)
+			require.NoError(t, builder.AddResource(tt.parent))
+			require.NoError(t, builder.AddResource(tt.child))
+
+			result, err := builder.Build()
+			require.NoError(t, err)
+
+			var template ARMTemplate
+			require.NoError(t, json.Unmarshal([]byte(result), &template))
+			require.Len(t, template.Resources, 2)
+			assert.Equal(t, tt.want, template.Resources[1].Name)
+			assert.Equal(t, []string{"[resourceId('" + tt.parent.Type + "', '" + tt.parent.ARMName + "')]"}, template.Resources[1].DependsOn)
+		})
+	}
+}
+
 func TestBuild_ComplexDependencyGraph(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	// Create a complex dependency graph:
 	// A (no deps)
@@ -384,7 +626,7 @@ func TestBuild_ComplexDependencyGraph(t *testing.T) {
 }
 
 func TestBuild_WithParametersVariablesOutputs(t *testing.T) {
-	builder := NewTemplateBuilder()
+	builder := NewTemplateBuilder(ScopeResourceGroup)
 
 	// Add parameter
 	err := builder.AddParameter("location", "string", map[string]interface{}{
@@ -437,6 +679,207 @@ func TestBuild_WithParametersVariablesOutputs(t *testing.T) {
 	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'myStorage')]", storageIdOutput["value"])
 }
 
+func TestBuildCompact(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+
+	require.NoError(t, builder.AddVariable("storageAccountName", "[concat('storage', uniqueString(resourceGroup().id))]"))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "myStorage",
+		Type: "Microsoft.Storage/storageAccounts",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "myVM",
+		Type:         "Microsoft.Compute/virtualMachines",
+		Dependencies: []string{"myStorage"},
+	}))
+	require.NoError(t, builder.AddOutput("storageId", "string", "[resourceId('Microsoft.Storage/storageAccounts', 'myStorage')]"))
+
+	pretty, err := builder.Build()
+	require.NoError(t, err)
+	compact, err := builder.BuildCompact()
+	require.NoError(t, err)
+
+	assert.Contains(t, pretty, "\n")
+	assert.NotContains(t, compact, "\n")
+	assert.Less(t, len(compact), len(pretty))
+
+	// Intrinsic expressions are emitted verbatim
+	assert.Contains(t, compact, `"[concat('storage', uniqueString(resourceGroup().id))]"`)
+	assert.Contains(t, compact, `"[resourceId('Microsoft.Storage/storageAccounts', 'myStorage')]"`)
+
+	// Both outputs parse to the same template
+	var prettyTemplate, compactTemplate map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(pretty), &prettyTemplate))
+	require.NoError(t, json.Unmarshal([]byte(compact), &compactTemplate))
+	assert.Equal(t, prettyTemplate, compactTemplate)
+}
+
+func TestBuild_SortedKeys(t *testing.T) {
+	newBuilder := func() *TemplateBuilder {
+		builder := NewTemplateBuilder(ScopeResourceGroup)
+		require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+			Name:       "myStorage",
+			Type:       "Microsoft.Storage/storageAccounts",
+			Properties: map[string]any{"accessTier": "Hot"},
+		}))
+		return builder
+	}
+
+	unsorted, err := newBuilder().BuildCompact()
+	require.NoError(t, err)
+	assert.Contains(t, unsorted, `{"$schema":`)
+	assert.Contains(t, unsorted, `{"name":"myStorage","type":"Microsoft.Storage/storageAccounts","apiVersion":`)
+
+	sorted, err := newBuilder().WithSortedKeys(true).BuildCompact()
+	require.NoError(t, err)
+	assert.Contains(t, sorted, `{"apiVersion":"2021-04-01","location":"[resourceGroup().location]","name":"myStorage","properties":{"accessTier":"Hot"},"type":"Microsoft.Storage/storageAccounts"}`)
+	assert.JSONEq(t, unsorted, sorted)
+
+	indented, err := newBuilder().WithSortedKeys(true).Build()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(indented, "{\n  \"$schema\": "), indented)
+	assert.JSONEq(t, sorted, indented)
+}
+
+func TestBuild_EmptySections(t *testing.T) {
+	newBuilder := func() *TemplateBuilder {
+		builder := NewTemplateBuilder(ScopeResourceGroup)
+		require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+			Name: "myStorage",
+			Type: "Microsoft.Storage/storageAccounts",
+		}))
+		return builder
+	}
+
+	included, err := newBuilder().BuildCompact()
+	require.NoError(t, err)
+	assert.Contains(t, included, `"parameters":{}`)
+	assert.Contains(t, included, `"variables":{}`)
+	assert.Contains(t, included, `"outputs":{}`)
+
+	omitted, err := newBuilder().WithEmptySections(false).BuildCompact()
+	require.NoError(t, err)
+	assert.NotContains(t, omitted, `"parameters"`)
+	assert.NotContains(t, omitted, `"variables"`)
+	assert.NotContains(t, omitted, `"outputs"`)
+	assert.Contains(t, omitted, `"resources":[`)
+
+	// Sections with entries are kept, in both key orders
+	for _, sorted := range []bool{false, true} {
+		builder := newBuilder().WithEmptySections(false).WithSortedKeys(sorted)
+		require.NoError(t, builder.AddParameter("location", "string", nil))
+		result, err := builder.BuildCompact()
+		require.NoError(t, err)
+		assert.Contains(t, result, `"parameters":{"location":{"type":"string"}}`)
+		assert.NotContains(t, result, `"variables"`)
+	}
+}
+
+func TestBuild_DeterministicOrder(t *testing.T) {
+	build := func() string {
+		builder := NewTemplateBuilder(ScopeResourceGroup)
+		for _, name := range []string{"vnet", "nsg", "storage", "logs", "keyvault"} {
+			require.NoError(t, builder.AddResource(discover.DiscoveredResource{Name: name, Type: "Microsoft.Storage/storageAccounts"}))
+		}
+		require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+			Name:         "app",
+			Type:         "Microsoft.Web/sites",
+			Dependencies: []string{"storage", "keyvault", "logs"},
+		}))
+		result, err := builder.Build()
+		require.NoError(t, err)
+		return result
+	}
+
+	first := build()
+	for i := 0; i < 20; i++ {
+		require.Equal(t, first, build())
+	}
+
+	var template ARMTemplate
+	require.NoError(t, json.Unmarshal([]byte(first), &template))
+	var names []string
+	for _, r := range template.Resources {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"keyvault", "logs", "nsg", "storage", "vnet", "app"}, names)
+	assert.Equal(t, []string{
+		"[resourceId('Microsoft.Storage/storageAccounts', 'keyvault')]",
+		"[resourceId('Microsoft.Storage/storageAccounts', 'logs')]",
+		"[resourceId('Microsoft.Storage/storageAccounts', 'storage')]",
+	}, template.Resources[5].DependsOn)
+}
+
+func TestWithMetadata(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "myStorage",
+		Type: "Microsoft.Storage/storageAccounts",
+	}))
+
+	// Without metadata the section is omitted
+	output, err := builder.Build()
+	require.NoError(t, err)
+	assert.NotContains(t, output, `"metadata"`)
+
+	builder.WithMetadata(map[string]interface{}{
+		"_generator": map[string]string{"name": "wetwire-azure", "version": "1.2.3"},
+		"author":     "platform-team",
+	})
+	output, err = builder.Build()
+	require.NoError(t, err)
+
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &template))
+	assert.Equal(t, map[string]interface{}{
+		"_generator": map[string]interface{}{"name": "wetwire-azure", "version": "1.2.3"},
+		"author":     "platform-team",
+	}, template["metadata"])
+}
+
+func TestBuild_CopyLoop(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:    "webNICs",
+		Type:    "Microsoft.Network/networkInterfaces",
+		ARMName: "web-nic",
+		Copy:    &discover.CopyLoop{Name: "webNICs", Count: 3},
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name:         "webVMs",
+		Type:         "Microsoft.Compute/virtualMachines",
+		ARMName:      "[concat('web-vm-', uniqueString(resourceGroup().id))]",
+		Dependencies: []string{"webNICs"},
+		Copy:         &discover.CopyLoop{Name: "vmLoop", Count: "[parameters('vmCount')]", Mode: "serial", BatchSize: 1},
+	}))
+
+	result, err := builder.Build()
+	require.NoError(t, err)
+
+	var template ARMTemplate
+	require.NoError(t, json.Unmarshal([]byte(result), &template))
+	require.Len(t, template.Resources, 2)
+
+	nics := template.Resources[0]
+	// Instances are named after the resource's ARM name, not its variable
+	assert.Equal(t, "[concat('web-nic', copyIndex())]", nics.Name)
+	require.NotNil(t, nics.Copy)
+	assert.Equal(t, "webNICs", nics.Copy.Name)
+	assert.Equal(t, float64(3), nics.Copy.Count)
+	assert.Contains(t, result, `"copy": {
+        "name": "webNICs",
+        "count": 3
+      }`)
+
+	vms := template.Resources[1]
+	require.NotNil(t, vms.Copy)
+	assert.Equal(t, &Copy{Name: "vmLoop", Count: "[parameters('vmCount')]", Mode: "serial", BatchSize: 1}, vms.Copy)
+	assert.Equal(t, "[concat(concat('web-vm-', uniqueString(resourceGroup().id)), copyIndex())]", vms.Name)
+	// Dependencies on a copy loop name the loop, covering every instance
+	assert.Equal(t, []string{"webNICs"}, vms.DependsOn)
+}
+
 func TestValidateReferences(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -474,7 +917,7 @@ func TestValidateReferences(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			builder := NewTemplateBuilder()
+			builder := NewTemplateBuilder(ScopeResourceGroup)
 			for _, res := range tt.resources {
 				err := builder.AddResource(res)
 				require.NoError(t, err)
@@ -536,7 +979,7 @@ func TestTopologicalSort(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			builder := NewTemplateBuilder()
+			builder := NewTemplateBuilder(ScopeResourceGroup)
 			for _, res := range tt.resources {
 				err := builder.AddResource(res)
 				require.NoError(t, err)
@@ -573,3 +1016,49 @@ func TestTopologicalSort(t *testing.T) {
 		})
 	}
 }
+
+// validatedValue records that it was validated and reports errs
+type validatedValue struct {
+	errs []error
+}
+
+func (v *validatedValue) Validate() []error {
+	return v.errs
+}
+
+// resourceWithRules nests Validatable values in a slice, as security rules are
+type resourceWithRules struct {
+	Name  string
+	Rules []validatedValue
+	Extra *validatedValue
+}
+
+func TestAddResource_Validatable(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup)
+
+	value := &resourceWithRules{
+		Name: "app",
+		Rules: []validatedValue{
+			{errs: []error{errors.New("rule 1 is invalid")}},
+			{},
+			{errs: []error{errors.New("rule 3 is invalid")}},
+		},
+		Extra: &validatedValue{errs: []error{errors.New("extra is invalid")}},
+	}
+	err := builder.AddResource(discover.DiscoveredResource{Name: "app", Type: "Microsoft.Example/widgets", Value: value})
+
+	var invalid *ValidationError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "app", invalid.Resource)
+	require.Len(t, invalid.Errors, 3)
+	assert.EqualError(t, invalid.Errors[0], "rule 1 is invalid")
+	assert.EqualError(t, invalid.Errors[2], "extra is invalid")
+	assert.Contains(t, err.Error(), "resource app is invalid: rule 1 is invalid; rule 3 is invalid")
+
+	// The resource is added despite the errors
+	assert.Contains(t, builder.resources, "app")
+
+	// Valid values and resources without values add cleanly
+	assert.NoError(t, builder.AddResource(discover.DiscoveredResource{Name: "ok", Value: &resourceWithRules{Rules: []validatedValue{{}}}}))
+	assert.NoError(t, builder.AddResource(discover.DiscoveredResource{Name: "plain"}))
+}
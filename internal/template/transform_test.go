@@ -0,0 +1,110 @@
+package template
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	rawtemplate "github.com/lex00/wetwire-azure-go/template"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addOwnerTag tags every resource with owner: platform, keeping other tags
+func addOwnerTag(t rawtemplate.Template) rawtemplate.Template {
+	for _, resource := range t.Resources {
+		tags, _ := resource["tags"].(map[string]any)
+		if tags == nil {
+			tags = map[string]any{}
+		}
+		tags["owner"] = "platform"
+		resource["tags"] = tags
+	}
+	return t
+}
+
+func TestBuild_Transforms(t *testing.T) {
+	builder := NewTemplateBuilder(ScopeResourceGroup).WithTransforms(addOwnerTag)
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "logs",
+		Type: "Microsoft.Storage/storageAccounts",
+	}))
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "bastion",
+		Type: "Microsoft.Network/bastionHosts",
+		Raw: &rawtemplate.RawResource{
+			Type:       "Microsoft.Network/bastionHosts",
+			Name:       "bastion",
+			Tags:       map[string]string{"env": "prod"},
+			Properties: map[string]any{"scaleUnits": 2},
+		},
+	}))
+
+	result, err := builder.Build()
+	require.NoError(t, err)
+
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result), &template))
+	resources := template["resources"].([]interface{})
+	require.Len(t, resources, 2)
+	for _, r := range resources {
+		resource := r.(map[string]interface{})
+		tags := resource["tags"].(map[string]interface{})
+		assert.Equal(t, "platform", tags["owner"], resource["name"])
+	}
+
+	bastion := resources[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"env": "prod", "owner": "platform"}, bastion["tags"])
+	assert.Equal(t, map[string]interface{}{"scaleUnits": float64(2)}, bastion["properties"])
+
+	// The empty sections are kept
+	assert.Equal(t, map[string]interface{}{}, template["parameters"])
+	assert.Equal(t, map[string]interface{}{}, template["outputs"])
+}
+
+func TestBuild_TransformsInOrder(t *testing.T) {
+	var calls []string
+	rename := func(t rawtemplate.Template) rawtemplate.Template {
+		calls = append(calls, "rename")
+		for _, resource := range t.Resources {
+			resource["name"] = "corp-" + resource["name"].(string)
+		}
+		return t
+	}
+	addLock := func(t rawtemplate.Template) rawtemplate.Template {
+		calls = append(calls, "lock")
+		for _, resource := range t.Resources {
+			if resource["type"] == "Microsoft.Authorization/locks" {
+				return t
+			}
+		}
+		// Sees the name set by rename
+		t.Resources = append(t.Resources, map[string]any{
+			"type":       "Microsoft.Authorization/locks",
+			"apiVersion": "2020-05-01",
+			"name":       t.Resources[0]["name"].(string) + "-lock",
+			"properties": map[string]any{"level": "CanNotDelete"},
+		})
+		return t
+	}
+
+	builder := NewTemplateBuilder(ScopeResourceGroup).
+		WithTransforms(rename, addLock).
+		WithEmptySections(false)
+	require.NoError(t, builder.AddResource(discover.DiscoveredResource{
+		Name: "logs",
+		Type: "Microsoft.Storage/storageAccounts",
+	}))
+
+	result, err := builder.BuildCompact()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rename", "lock"}, calls)
+	assert.Contains(t, result, `"name":"corp-logs"`)
+	assert.Contains(t, result, `"name":"corp-logs-lock"`)
+	assert.NotContains(t, result, `"parameters"`)
+	assert.NotContains(t, result, `"outputs"`)
+}
+
+func TestNewTemplateBuilder_RegisteredTransforms(t *testing.T) {
+	assert.Len(t, NewTemplateBuilder(ScopeResourceGroup).transforms, len(rawtemplate.Transforms()))
+}
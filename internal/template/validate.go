@@ -0,0 +1,79 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validatable is implemented by resource types, and types nested in them,
+// that check their own constraints, such as name rules or value ranges.
+type Validatable interface {
+	// Validate returns every constraint the value violates
+	Validate() []error
+}
+
+// ValidationError reports the constraint violations of a resource.
+type ValidationError struct {
+	Resource string
+	Errors   []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("resource %s is invalid: %s", e.Resource, strings.Join(msgs, "; "))
+}
+
+// validateValue calls Validate on v and on every Validatable nested in its
+// fields, slices and maps, and returns the errors in field order.
+func validateValue(v reflect.Value) []error {
+	var errs []error
+
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if validatable, ok := v.Interface().(Validatable); ok && v.Kind() == reflect.Ptr {
+			errs = append(errs, validatable.Validate()...)
+			return append(errs, validateChildren(v.Elem())...)
+		}
+		return validateValue(v.Elem())
+	}
+
+	if v.CanAddr() {
+		if validatable, ok := v.Addr().Interface().(Validatable); ok {
+			errs = append(errs, validatable.Validate()...)
+		}
+	} else if v.CanInterface() {
+		if validatable, ok := v.Interface().(Validatable); ok {
+			errs = append(errs, validatable.Validate()...)
+		}
+	}
+	return append(errs, validateChildren(v)...)
+}
+
+// validateChildren validates the fields, elements or values of v
+func validateChildren(v reflect.Value) []error {
+	var errs []error
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				errs = append(errs, validateValue(v.Field(i))...)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateValue(v.Index(i))...)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			errs = append(errs, validateValue(iter.Value())...)
+		}
+	}
+	return errs
+}
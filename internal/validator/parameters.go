@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateParameters checks an ARM parameters file against a template. Every
+// parameter in the file must be declared in the template's parameters
+// section, and every template parameter without a defaultValue must be set
+// in the file. Parameter names are compared case-insensitively, as ARM does.
+func (v *Validator) ValidateParameters(templateData, paramsData []byte) ([]ValidationResult, error) {
+	var template struct {
+		Parameters map[string]map[string]interface{} `json:"parameters"`
+	}
+	if err := json.Unmarshal(templateData, &template); err != nil {
+		return nil, fmt.Errorf("invalid template JSON: %w", err)
+	}
+
+	var params struct {
+		Parameters map[string]interface{} `json:"parameters"`
+	}
+	if err := json.Unmarshal(paramsData, &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters JSON: %w", err)
+	}
+
+	declared := make(map[string]bool, len(template.Parameters))
+	for name := range template.Parameters {
+		declared[strings.ToLower(name)] = true
+	}
+	set := make(map[string]bool, len(params.Parameters))
+	for name := range params.Parameters {
+		set[strings.ToLower(name)] = true
+	}
+
+	var results []ValidationResult
+	for _, name := range sortedKeys(params.Parameters) {
+		if !declared[strings.ToLower(name)] {
+			results = append(results, ValidationResult{
+				Severity: SeverityError,
+				Field:    "parameters." + name,
+				Message:  "parameter is not declared in the template",
+				Code:     CodeUndeclaredParameter,
+			})
+		}
+	}
+	for _, name := range sortedKeys(template.Parameters) {
+		if _, ok := template.Parameters[name]["defaultValue"]; ok {
+			continue
+		}
+		if !set[strings.ToLower(name)] {
+			results = append(results, ValidationResult{
+				Severity: SeverityError,
+				Field:    "parameters." + name,
+				Message:  "required parameter has no value in the parameters file",
+				Code:     CodeMissingParameter,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
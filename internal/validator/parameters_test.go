@@ -0,0 +1,59 @@
+package validator
+
+import "testing"
+
+func TestValidateParameters(t *testing.T) {
+	template := []byte(`{
+		"parameters": {
+			"location": {"type": "string", "defaultValue": "eastus"},
+			"storageName": {"type": "string"},
+			"adminPassword": {"type": "securestring"}
+		},
+		"resources": []
+	}`)
+	params := []byte(`{
+		"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+		"contentVersion": "1.0.0.0",
+		"parameters": {
+			"StorageName": {"value": "mystorage"},
+			"sku": {"value": "Standard_LRS"}
+		}
+	}`)
+
+	results, err := NewValidator().ValidateParameters(template, params)
+	if err != nil {
+		t.Fatalf("ValidateParameters failed: %v", err)
+	}
+
+	want := []ValidationResult{
+		{Severity: SeverityError, Field: "parameters.sku", Message: "parameter is not declared in the template", Code: CodeUndeclaredParameter},
+		{Severity: SeverityError, Field: "parameters.adminPassword", Message: "required parameter has no value in the parameters file", Code: CodeMissingParameter},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d: %v", len(want), len(results), results)
+	}
+	for i, r := range results {
+		if r != want[i] {
+			t.Errorf("result %d = %v, want %v", i, r, want[i])
+		}
+	}
+}
+
+func TestValidateParameters_Match(t *testing.T) {
+	template := []byte(`{"parameters": {"name": {"type": "string"}}}`)
+	params := []byte(`{"parameters": {"name": {"value": "x"}}}`)
+
+	results, err := NewValidator().ValidateParameters(template, params)
+	if err != nil {
+		t.Fatalf("ValidateParameters failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %v", results)
+	}
+}
+
+func TestValidateParameters_InvalidJSON(t *testing.T) {
+	if _, err := NewValidator().ValidateParameters([]byte(`{}`), []byte(`{invalid`)); err == nil {
+		t.Fatal("Expected error for invalid parameters JSON")
+	}
+}
@@ -0,0 +1,415 @@
+package validator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// templateResources indexes the resources declared in a template by type and name.
+type templateResources struct {
+	// names maps a lowercased resource type to the lowercased full names declared for it
+	names map[string]map[string]bool
+
+	// dynamicTypes records types with at least one name given by an expression,
+	// which cannot be matched statically
+	dynamicTypes map[string]bool
+
+	// shortNames holds the last name segment of every literal resource name
+	shortNames map[string]bool
+}
+
+// newTemplateResources indexes resources, including nested child resources.
+func newTemplateResources(resources []interface{}) *templateResources {
+	tr := &templateResources{
+		names:        make(map[string]map[string]bool),
+		dynamicTypes: make(map[string]bool),
+		shortNames:   make(map[string]bool),
+	}
+	tr.add(resources, "", "")
+	return tr
+}
+
+func (tr *templateResources) add(resources []interface{}, parentType, parentName string) {
+	for _, res := range resources {
+		resMap, ok := res.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		resType, _ := resMap["type"].(string)
+		name, _ := resMap["name"].(string)
+		if resType == "" || name == "" {
+			continue
+		}
+
+		// Nested child resources use a short type and name relative to the parent
+		if parentType != "" && !strings.Contains(resType, "/") {
+			resType = parentType + "/" + resType
+			name = parentName + "/" + name
+		}
+
+		key := strings.ToLower(resType)
+		if isExpression(name) {
+			tr.dynamicTypes[key] = true
+		} else {
+			if tr.names[key] == nil {
+				tr.names[key] = make(map[string]bool)
+			}
+			tr.names[key][strings.ToLower(name)] = true
+			segments := strings.Split(name, "/")
+			tr.shortNames[strings.ToLower(segments[len(segments)-1])] = true
+		}
+
+		if children, ok := resMap["resources"].([]interface{}); ok {
+			tr.add(children, resType, name)
+		}
+		tr.addInline(resMap["properties"], resType, name)
+	}
+}
+
+// addInline indexes the child resources declared inline in the properties of
+// a resource, such as the subnets of a virtual network or the security rules
+// of a network security group: arrays of named objects, whose key is the
+// child type
+func (tr *templateResources) addInline(properties interface{}, parentType, parentName string) {
+	props, ok := properties.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, value := range props {
+		items, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		var children []interface{}
+		for _, item := range items {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := itemMap["name"].(string); !ok {
+				continue
+			}
+			child := map[string]interface{}{"type": key, "name": itemMap["name"]}
+			if itemProps, ok := itemMap["properties"]; ok {
+				child["properties"] = itemProps
+			}
+			children = append(children, child)
+		}
+		tr.add(children, parentType, parentName)
+	}
+}
+
+// hasResource reports whether a resource with the given type and full name is declared.
+func (tr *templateResources) hasResource(resType, name string) bool {
+	key := strings.ToLower(resType)
+	if tr.dynamicTypes[key] {
+		return true
+	}
+	return tr.names[key][strings.ToLower(name)]
+}
+
+// hasType reports whether any resource of the given type is declared.
+func (tr *templateResources) hasType(resType string) bool {
+	key := strings.ToLower(resType)
+	return tr.dynamicTypes[key] || len(tr.names[key]) > 0
+}
+
+// hasName reports whether any resource has the given (short) name.
+func (tr *templateResources) hasName(name string) bool {
+	if len(tr.dynamicTypes) > 0 {
+		return true
+	}
+	return tr.shortNames[strings.ToLower(name)]
+}
+
+// validateReferences reports every resourceId() or reference() call whose
+// literal arguments name a resource that is not in the template: a warning
+// if the template declares other resources of the type, which suggests a
+// misspelled name, and otherwise information, since the call may refer to a
+// resource that already exists, such as a vault's built-in backup policy.
+func (v *Validator) validateReferences(resources []interface{}) []ValidationResult {
+	declared := newTemplateResources(resources)
+
+	var results []ValidationResult
+	for i, res := range resources {
+		walkStrings(res, fmt.Sprintf("resources[%d]", i), func(path, value string) {
+			if !isExpression(value) {
+				return
+			}
+			calls, err := parseARMExpression(value[1 : len(value)-1])
+			if err != nil {
+				return
+			}
+			for _, call := range calls {
+				if msg, severity := checkReference(call, declared); msg != "" {
+					results = append(results, ValidationResult{
+						Severity: severity,
+						Field:    path,
+						Message:  msg,
+						Code:     CodeUnresolvedReference,
+					})
+				}
+			}
+		})
+	}
+	return results
+}
+
+// checkReference returns a message and its severity if call refers to an
+// undeclared resource.
+func checkReference(call *armCall, declared *templateResources) (string, Severity) {
+	switch strings.ToLower(call.name) {
+	case "resourceid":
+		resType, name, ok := resourceIDTarget(call)
+		if !ok || declared.hasResource(resType, name) {
+			return "", SeverityInfo
+		}
+		severity := SeverityInfo
+		if declared.hasType(resType) {
+			severity = SeverityWarning
+		}
+		return fmt.Sprintf("resourceId references %s '%s', which is not defined in the template", resType, name), severity
+
+	case "reference":
+		if len(call.args) == 0 || !call.args[0].isLiteral {
+			return "", SeverityInfo
+		}
+		name := call.args[0].literal
+		// Full resource IDs may point outside the template
+		if strings.Contains(name, "/") || declared.hasName(name) {
+			return "", SeverityInfo
+		}
+		return fmt.Sprintf("reference targets '%s', which is not defined in the template", name), SeverityWarning
+	}
+	return "", SeverityInfo
+}
+
+// resourceIDTarget extracts the resource type and full name from a
+// resourceId([subscriptionId], [resourceGroupName], type, name1, [name2], ...)
+// call. It returns false if the arguments are not all string literals, or if
+// the call names a subscription or resource group: such a resource is
+// usually deployed outside the template.
+func resourceIDTarget(call *armCall) (string, string, bool) {
+	typeIndex := -1
+	for i, arg := range call.args {
+		if !arg.isLiteral {
+			return "", "", false
+		}
+		if typeIndex == -1 && strings.Contains(arg.literal, "/") {
+			typeIndex = i
+		}
+	}
+	if typeIndex == -1 || typeIndex == len(call.args)-1 {
+		return "", "", false
+	}
+	// A subscription or resource group argument points outside the template
+	if typeIndex > 0 {
+		return "", "", false
+	}
+
+	resType := call.args[typeIndex].literal
+	names := make([]string, 0, len(call.args)-typeIndex-1)
+	for _, arg := range call.args[typeIndex+1:] {
+		names = append(names, arg.literal)
+	}
+	return resType, strings.Join(names, "/"), true
+}
+
+// walkStrings calls fn for every string value in v, with its JSON path.
+func walkStrings(v interface{}, path string, fn func(path, value string)) {
+	switch val := v.(type) {
+	case string:
+		fn(path, val)
+	case []interface{}:
+		for i, item := range val {
+			walkStrings(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkStrings(val[k], path+"."+k, fn)
+		}
+	}
+}
+
+// isExpression reports whether s is an ARM template expression ("[...]").
+// Strings starting with "[[" are escaped literals.
+func isExpression(s string) bool {
+	return len(s) >= 2 && s[0] == '[' && s[len(s)-1] == ']' && !strings.HasPrefix(s, "[[")
+}
+
+// armCall is a function call in an ARM template expression.
+type armCall struct {
+	name string
+	args []armArg
+}
+
+// armArg is a function argument: a string literal or any other expression.
+type armArg struct {
+	literal   string
+	isLiteral bool
+}
+
+// armParser is a minimal parser for ARM template expressions that records
+// every function call it encounters.
+type armParser struct {
+	input string
+	pos   int
+	calls []*armCall
+}
+
+// parseARMExpression parses the body of an ARM expression (without the
+// surrounding brackets) and returns all function calls, outermost first.
+func parseARMExpression(expr string) ([]*armCall, error) {
+	p := &armParser{input: expr}
+	if _, err := p.parseValue(); err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	return p.calls, nil
+}
+
+// parseValue parses a literal, number, or function call with any trailing
+// property accesses or indexes.
+func (p *armParser) parseValue() (armArg, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return armArg{}, fmt.Errorf("unexpected end of expression")
+	}
+
+	var arg armArg
+	c := p.input[p.pos]
+	switch {
+	case c == '\'':
+		s, err := p.parseString()
+		if err != nil {
+			return armArg{}, err
+		}
+		arg = armArg{literal: s, isLiteral: true}
+	case c == '-' || (c >= '0' && c <= '9'):
+		p.pos++
+		for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+	case isIdentChar(c):
+		if err := p.parseCall(); err != nil {
+			return armArg{}, err
+		}
+	default:
+		return armArg{}, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+
+	// Postfix property access (.name) and indexing ([value])
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) {
+			return arg, nil
+		}
+		switch p.input[p.pos] {
+		case '.':
+			p.pos++
+			p.parseIdent()
+			arg = armArg{}
+		case '[':
+			p.pos++
+			if _, err := p.parseValue(); err != nil {
+				return armArg{}, err
+			}
+			p.skipSpaces()
+			if p.pos >= len(p.input) || p.input[p.pos] != ']' {
+				return armArg{}, fmt.Errorf("expected ']' at position %d", p.pos)
+			}
+			p.pos++
+			arg = armArg{}
+		default:
+			return arg, nil
+		}
+	}
+}
+
+// parseCall parses name(arg, ...) and records the call.
+func (p *armParser) parseCall() error {
+	call := &armCall{name: p.parseIdent()}
+	p.calls = append(p.calls, call)
+
+	p.skipSpaces()
+	if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		return fmt.Errorf("expected '(' after %s", call.name)
+	}
+	p.pos++
+
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == ')' {
+		p.pos++
+		return nil
+	}
+
+	for {
+		arg, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		call.args = append(call.args, arg)
+
+		p.skipSpaces()
+		if p.pos >= len(p.input) {
+			return fmt.Errorf("unterminated call to %s", call.name)
+		}
+		switch p.input[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return nil
+		default:
+			return fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+		}
+	}
+}
+
+// parseString parses a single-quoted string; a doubled quote inside it is an escaped quote.
+func (p *armParser) parseString() (string, error) {
+	var sb strings.Builder
+	p.pos++ // opening quote
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		if c != '\'' {
+			sb.WriteByte(c)
+			continue
+		}
+		if p.pos < len(p.input) && p.input[p.pos] == '\'' {
+			sb.WriteByte('\'')
+			p.pos++
+			continue
+		}
+		return sb.String(), nil
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+func (p *armParser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.input) && isIdentChar(p.input[p.pos]) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *armParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
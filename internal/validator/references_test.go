@@ -0,0 +1,195 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+const referenceTemplate = `{
+	"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+	"contentVersion": "1.0.0.0",
+	"resources": [
+		{
+			"type": "Microsoft.Network/virtualNetworks",
+			"apiVersion": "2021-02-01",
+			"name": "myVNet",
+			"resources": [
+				{
+					"type": "subnets",
+					"apiVersion": "2021-02-01",
+					"name": "default"
+				}
+			]
+		},
+		{
+			"type": "Microsoft.Network/networkInterfaces",
+			"apiVersion": "2021-02-01",
+			"name": "myNIC",
+			"dependsOn": [
+				"[resourceId('Microsoft.Network/virtualNetworks', 'myVNet')]"
+			],
+			"properties": {
+				"ipConfigurations": [
+					{
+						"name": "ipconfig1",
+						"properties": {
+							"subnet": {
+								"id": "%s"
+							}
+						}
+					}
+				]
+			}
+		}
+	]
+}`
+
+func TestValidateReferences_Valid(t *testing.T) {
+	tests := []string{
+		"[resourceId('Microsoft.Network/virtualNetworks/subnets', 'myVNet', 'default')]",
+		"[reference('myVNet').subnets[0].id]",
+		"[reference(resourceId('Microsoft.Network/virtualNetworks', 'myVNet'), '2021-02-01').id]",
+		"[resourceId(parameters('vnetRg'), 'Microsoft.Network/virtualNetworks/subnets', parameters('vnet'), 'x')]",
+		"[resourceId('hub-rg', 'Microsoft.Network/virtualNetworks/subnets', 'hubVNet', 'shared')]",
+		"[resourceId('00000000-0000-0000-0000-000000000001', 'hub-rg', 'Microsoft.Network/virtualNetworks/subnets', 'hubVNet', 'shared')]",
+		"[concat(resourceId('Microsoft.Network/virtualNetworks', 'myVNet'), '/subnets/default')]",
+		"[[resourceId('Microsoft.Network/virtualNetworks', 'escaped')]",
+	}
+
+	for _, ref := range tests {
+		t.Run(ref, func(t *testing.T) {
+			data := []byte(strings.Replace(referenceTemplate, "%s", ref, 1))
+			results, err := NewValidator().ValidateTemplate(data)
+			if err != nil {
+				t.Fatalf("ValidateTemplate failed: %v", err)
+			}
+			if len(results) != 0 {
+				t.Errorf("Expected no validation results, got %v", results)
+			}
+		})
+	}
+}
+
+func TestValidateReferences_Dangling(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{
+			ref:  "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'myVNet', 'defualt')]",
+			want: "Microsoft.Network/virtualNetworks/subnets 'myVNet/defualt'",
+		},
+		{
+			ref:  "[reference('otherVNet').subnets[0].id]",
+			want: "'otherVNet'",
+		},
+		{
+			ref:  "[reference(resourceId('Microsoft.Network/virtualNetworks', 'otherVNet'), '2021-02-01').id]",
+			want: "Microsoft.Network/virtualNetworks 'otherVNet'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			data := []byte(strings.Replace(referenceTemplate, "%s", tt.ref, 1))
+			results, err := NewValidator().ValidateTemplate(data)
+			if err != nil {
+				t.Fatalf("ValidateTemplate failed: %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 validation result, got %v", results)
+			}
+
+			r := results[0]
+			if r.Severity != SeverityWarning {
+				t.Errorf("Expected warning severity, got %s", r.Severity)
+			}
+			if r.Field != "resources[1].properties.ipConfigurations[0].properties.subnet.id" {
+				t.Errorf("Unexpected field %q", r.Field)
+			}
+			if !strings.Contains(r.Message, tt.want) {
+				t.Errorf("Expected message to contain %q, got %q", tt.want, r.Message)
+			}
+		})
+	}
+}
+
+// TestValidateReferences_InlineChildren tests that child resources declared
+// inline in their parent's properties, such as subnets, can be referenced
+func TestValidateReferences_InlineChildren(t *testing.T) {
+	data := []byte(`{
+	"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+	"contentVersion": "1.0.0.0",
+	"resources": [
+		{
+			"type": "Microsoft.Network/virtualNetworks",
+			"apiVersion": "2021-02-01",
+			"name": "vnet",
+			"properties": {
+				"addressSpace": {"addressPrefixes": ["10.0.0.0/16"]},
+				"subnets": [{"name": "default", "properties": {"addressPrefix": "10.0.0.0/24"}}]
+			}
+		},
+		{
+			"type": "Microsoft.Network/networkInterfaces",
+			"apiVersion": "2021-02-01",
+			"name": "nic",
+			"properties": {
+				"ipConfigurations": [{
+					"name": "ipconfig1",
+					"properties": {
+						"subnet": {"id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'default')]"}
+					}
+				}]
+			}
+		}
+	]
+}`)
+	results, err := NewValidator().ValidateTemplate(data)
+	if err != nil {
+		t.Fatalf("ValidateTemplate failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no validation results, got %v", results)
+	}
+}
+
+// TestValidateReferences_ExistingResource tests that a reference to a
+// resource of a type the template does not declare, such as a vault's
+// built-in backup policy, is reported as information rather than a warning
+func TestValidateReferences_ExistingResource(t *testing.T) {
+	ref := "[resourceId('Microsoft.RecoveryServices/vaults/backupPolicies', 'backup-vault', 'DefaultPolicy')]"
+	data := []byte(strings.Replace(referenceTemplate, "%s", ref, 1))
+	results, err := NewValidator().ValidateTemplate(data)
+	if err != nil {
+		t.Fatalf("ValidateTemplate failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 validation result, got %v", results)
+	}
+	if results[0].Severity != SeverityInfo {
+		t.Errorf("Expected info severity, got %s", results[0].Severity)
+	}
+}
+
+func TestParseARMExpression(t *testing.T) {
+	calls, err := parseARMExpression("concat(resourceId('A/b', 'it''s'), reference('x').id, 1)")
+	if err != nil {
+		t.Fatalf("parseARMExpression failed: %v", err)
+	}
+
+	var names []string
+	for _, c := range calls {
+		names = append(names, c.name)
+	}
+	if strings.Join(names, ",") != "concat,resourceId,reference" {
+		t.Errorf("Unexpected calls %v", names)
+	}
+	if calls[1].args[1].literal != "it's" {
+		t.Errorf("Expected escaped quote to be unescaped, got %q", calls[1].args[1].literal)
+	}
+
+	if _, err := parseARMExpression("resourceId('unterminated"); err == nil {
+		t.Error("Expected error for unterminated string")
+	}
+}
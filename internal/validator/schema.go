@@ -0,0 +1,288 @@
+package validator
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// schemaFS holds the embedded resource schemas, laid out as
+// schemas/<namespace>/<type>/<apiVersion>.json (e.g.
+// schemas/Microsoft.Storage/storageAccounts/2021-04-01.json). Each schema
+// describes the "properties" object of a resource.
+//
+//go:embed schemas
+var schemaFS embed.FS
+
+// schema is the subset of JSON Schema used by the embedded resource schemas.
+type schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+// versionedSchema is a schema for one API version of a resource type.
+type versionedSchema struct {
+	apiVersion string
+	schema     *schema
+}
+
+var (
+	schemasOnce sync.Once
+	schemasErr  error
+	// schemas maps a lowercased resource type to its schemas, oldest version first
+	schemas map[string][]versionedSchema
+)
+
+// loadSchemas parses the embedded schemas once.
+func loadSchemas() (map[string][]versionedSchema, error) {
+	schemasOnce.Do(func() {
+		schemas, schemasErr = parseSchemas(schemaFS, "schemas")
+	})
+	return schemas, schemasErr
+}
+
+// parseSchemas reads every <type>/<apiVersion>.json file below root.
+func parseSchemas(fsys fs.FS, root string) (map[string][]versionedSchema, error) {
+	index := make(map[string][]versionedSchema)
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".json" {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		var s schema
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("parse schema %s: %w", p, err)
+		}
+
+		resType := strings.TrimPrefix(path.Dir(p), root+"/")
+		key := strings.ToLower(resType)
+		index[key] = append(index[key], versionedSchema{
+			apiVersion: strings.TrimSuffix(path.Base(p), ".json"),
+			schema:     &s,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, versions := range index {
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i].apiVersion < versions[j].apiVersion
+		})
+	}
+	return index, nil
+}
+
+// lookupSchema returns the schema for resType at apiVersion and whether it
+// is an exact match. Without one it falls back to the newest version older
+// than apiVersion, or the oldest version if all are newer. It returns nil for
+// unknown types.
+func lookupSchema(index map[string][]versionedSchema, resType, apiVersion string) (*schema, bool) {
+	versions := index[strings.ToLower(resType)]
+	if len(versions) == 0 {
+		return nil, false
+	}
+
+	nearest := versions[0]
+	for _, v := range versions {
+		if v.apiVersion > apiVersion {
+			break
+		}
+		nearest = v
+	}
+	return nearest.schema, nearest.apiVersion == apiVersion
+}
+
+// validateProperties checks the "properties" of a resource against the
+// embedded schema for its type and API version. Missing required properties
+// are errors; unknown properties and invalid values are warnings. Unknown
+// properties are only reported when the schema is for the exact API version,
+// since newer versions add properties an older schema does not list.
+func (v *Validator) validateProperties(resMap map[string]interface{}, index int) []ValidationResult {
+	resType, _ := resMap["type"].(string)
+	apiVersion, _ := resMap["apiVersion"].(string)
+	props, ok := resMap["properties"]
+	if resType == "" || !ok {
+		return nil
+	}
+
+	schemaIndex, err := loadSchemas()
+	if err != nil {
+		return []ValidationResult{{
+			Severity: SeverityInfo,
+			Field:    fmt.Sprintf("resources[%d].properties", index),
+			Message:  fmt.Sprintf("schema validation skipped: %v", err),
+			Code:     CodeSchemaSkipped,
+		}}
+	}
+	s, exact := lookupSchema(schemaIndex, resType, apiVersion)
+	if s == nil {
+		return nil
+	}
+	return s.validate(props, fmt.Sprintf("resources[%d].properties", index), exact)
+}
+
+// validate checks value against the schema, reporting findings under field.
+// Properties the schema does not list are reported only if strict is set.
+func (s *schema) validate(value interface{}, field string, strict bool) []ValidationResult {
+	// Expressions are evaluated at deployment time and cannot be checked statically
+	if str, ok := value.(string); ok && isExpression(str) {
+		return nil
+	}
+
+	if s.Type != "" && !matchesType(value, s.Type) {
+		return []ValidationResult{{
+			Severity: SeverityWarning,
+			Field:    field,
+			Message:  fmt.Sprintf("expected %s, got %s", s.Type, jsonType(value)),
+			Code:     CodeTypeMismatch,
+		}}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		return []ValidationResult{{
+			Severity: SeverityWarning,
+			Field:    field,
+			Message:  fmt.Sprintf("invalid value %s; expected one of %s", formatValue(value), formatEnum(s.Enum)),
+			Code:     CodeInvalidValue,
+		}}
+	}
+
+	var results []ValidationResult
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				results = append(results, ValidationResult{
+					Severity: SeverityError,
+					Field:    field + "." + name,
+					Message:  "missing required property",
+					Code:     CodeMissingProperty,
+				})
+			}
+		}
+
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := s.Properties[name]; ok {
+				results = append(results, prop.validate(val[name], field+"."+name, strict)...)
+			} else if strict && s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				results = append(results, ValidationResult{
+					Severity: SeverityWarning,
+					Field:    field + "." + name,
+					Message:  "unknown property",
+					Code:     CodeUnknownProperty,
+				})
+			}
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range val {
+				results = append(results, s.Items.validate(item, fmt.Sprintf("%s[%d]", field, i), strict)...)
+			}
+		}
+	}
+	return results
+}
+
+// matchesType reports whether value is of the JSON Schema type schemaType.
+func matchesType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	default:
+		return true
+	}
+}
+
+// jsonType returns the JSON type name of a decoded value.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// inEnum reports whether value is one of the allowed values. Strings are
+// compared case-insensitively, as ARM does for enum properties.
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, allowed := range enum {
+		if a, ok := allowed.(string); ok {
+			if s, ok := value.(string); ok && strings.EqualFold(a, s) {
+				return true
+			}
+			continue
+		}
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		values[i] = fmt.Sprint(v)
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,242 @@
+package validator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// validateResources validates a template holding the given resources.
+func validateResources(t *testing.T, resources ...map[string]interface{}) []ValidationResult {
+	t.Helper()
+	items := make([]interface{}, len(resources))
+	for i, r := range resources {
+		items[i] = r
+	}
+	template := map[string]interface{}{
+		"$schema":        "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"resources":      items,
+	}
+
+	jsonBytes, _ := json.Marshal(template)
+	results, err := NewValidator().ValidateTemplate(jsonBytes)
+	if err != nil {
+		t.Fatalf("ValidateTemplate failed: %v", err)
+	}
+	return results
+}
+
+func findResult(results []ValidationResult, field string) *ValidationResult {
+	for i := range results {
+		if results[i].Field == field {
+			return &results[i]
+		}
+	}
+	return nil
+}
+
+func storageAccount(apiVersion string, properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "Microsoft.Storage/storageAccounts",
+		"apiVersion": apiVersion,
+		"name":       "mystorage",
+		"properties": properties,
+	}
+}
+
+func TestValidateSchema_ValidStorageAccount(t *testing.T) {
+	results := validateResources(t, storageAccount("2021-04-01", map[string]interface{}{
+		"accessTier":               "Hot",
+		"minimumTlsVersion":        "TLS1_2",
+		"supportsHttpsTrafficOnly": true,
+		"networkAcls": map[string]interface{}{
+			"defaultAction": "Deny",
+			"ipRules":       []interface{}{map[string]interface{}{"value": "10.0.0.1"}},
+		},
+	}))
+
+	if len(results) != 0 {
+		t.Errorf("Expected no validation results, got %v", results)
+	}
+}
+
+func TestValidateSchema_InvalidAccessTier(t *testing.T) {
+	results := validateResources(t, storageAccount("2021-04-01", map[string]interface{}{
+		"accessTier": "Warm",
+	}))
+
+	r := findResult(results, "resources[0].properties.accessTier")
+	if r == nil {
+		t.Fatalf("Expected a result for accessTier, got %v", results)
+	}
+	if r.Severity != SeverityWarning {
+		t.Errorf("Expected warning, got %s", r.Severity)
+	}
+	if !strings.Contains(r.Message, `"Warm"`) || !strings.Contains(r.Message, "Hot, Cool") {
+		t.Errorf("Expected message to name the value and allowed values, got %q", r.Message)
+	}
+}
+
+func TestValidateSchema_UnknownAndMistypedProperties(t *testing.T) {
+	results := validateResources(t, storageAccount("2021-04-01", map[string]interface{}{
+		"accessTeir":               "Hot",
+		"supportsHttpsTrafficOnly": "yes",
+	}))
+
+	unknown := findResult(results, "resources[0].properties.accessTeir")
+	if unknown == nil || unknown.Severity != SeverityWarning || unknown.Message != "unknown property" {
+		t.Errorf("Expected unknown property warning, got %v", results)
+	}
+
+	mistyped := findResult(results, "resources[0].properties.supportsHttpsTrafficOnly")
+	if mistyped == nil || mistyped.Severity != SeverityWarning || mistyped.Message != "expected boolean, got string" {
+		t.Errorf("Expected type warning, got %v", results)
+	}
+}
+
+// TestValidateSchema_NewerAPIVersion tests that properties added after the
+// newest embedded schema are not reported as unknown, while the properties
+// the schema does list are still checked
+func TestValidateSchema_NewerAPIVersion(t *testing.T) {
+	results := validateResources(t, storageAccount("2023-01-01", map[string]interface{}{
+		"publicNetworkAccess": "Disabled",
+		"isSftpEnabled":       true,
+		"isLocalUserEnabled":  false,
+		"accessTier":          "Warm",
+	}))
+
+	for _, name := range []string{"publicNetworkAccess", "isSftpEnabled", "isLocalUserEnabled"} {
+		if r := findResult(results, "resources[0].properties."+name); r != nil {
+			t.Errorf("Expected no result for %s, got %v", name, *r)
+		}
+	}
+	if findResult(results, "resources[0].properties.accessTier") == nil {
+		t.Errorf("Expected accessTier to be checked against the older schema, got %v", results)
+	}
+}
+
+func TestValidateSchema_MissingRequiredProperty(t *testing.T) {
+	results := validateResources(t, storageAccount("2021-04-01", map[string]interface{}{
+		"networkAcls": map[string]interface{}{"bypass": "AzureServices"},
+	}))
+
+	r := findResult(results, "resources[0].properties.networkAcls.defaultAction")
+	if r == nil || r.Severity != SeverityError {
+		t.Fatalf("Expected missing defaultAction error, got %v", results)
+	}
+}
+
+func TestValidateSchema_NestedArrayItems(t *testing.T) {
+	results := validateResources(t, map[string]interface{}{
+		"type":       "Microsoft.Network/networkSecurityGroups",
+		"apiVersion": "2021-02-01",
+		"name":       "web-nsg",
+		"properties": map[string]interface{}{
+			"securityRules": []interface{}{
+				map[string]interface{}{
+					"name": "allow-http",
+					"properties": map[string]interface{}{
+						"access":    "Allow",
+						"direction": "Sideways",
+						"priority":  100.5,
+					},
+				},
+			},
+		},
+	})
+
+	prefix := "resources[0].properties.securityRules[0].properties."
+	if r := findResult(results, prefix+"direction"); r == nil || r.Severity != SeverityWarning {
+		t.Errorf("Expected invalid direction warning, got %v", results)
+	}
+	if r := findResult(results, prefix+"priority"); r == nil || r.Message != "expected integer, got number" {
+		t.Errorf("Expected priority type warning, got %v", results)
+	}
+	if r := findResult(results, prefix+"protocol"); r == nil || r.Severity != SeverityError {
+		t.Errorf("Expected missing protocol error, got %v", results)
+	}
+}
+
+func TestValidateSchema_SkipsExpressionsAndUnknownTypes(t *testing.T) {
+	results := validateResources(t,
+		storageAccount("2021-04-01", map[string]interface{}{
+			"accessTier": "[parameters('accessTier')]",
+		}),
+		map[string]interface{}{
+			"type":       "Microsoft.Example/widgets",
+			"apiVersion": "2021-01-01",
+			"name":       "widget",
+			"properties": map[string]interface{}{"anything": true},
+		},
+	)
+
+	if len(results) != 0 {
+		t.Errorf("Expected no validation results, got %v", results)
+	}
+}
+
+func TestValidateSchema_CaseInsensitiveEnum(t *testing.T) {
+	results := validateResources(t, storageAccount("2021-04-01", map[string]interface{}{
+		"accessTier": "cool",
+	}))
+
+	if len(results) != 0 {
+		t.Errorf("Expected no validation results, got %v", results)
+	}
+}
+
+func TestLookupSchema_NearestVersion(t *testing.T) {
+	older := &schema{Type: "object"}
+	newer := &schema{Type: "object"}
+	index := map[string][]versionedSchema{
+		"microsoft.storage/storageaccounts": {
+			{apiVersion: "2021-04-01", schema: older},
+			{apiVersion: "2023-01-01", schema: newer},
+		},
+	}
+
+	tests := []struct {
+		apiVersion string
+		want       *schema
+		exact      bool
+	}{
+		{"2021-04-01", older, true},
+		{"2022-09-01", older, false},
+		{"2023-01-01", newer, true},
+		{"2024-01-01-preview", newer, false},
+		{"2019-06-01", older, false},
+	}
+	for _, tt := range tests {
+		got, exact := lookupSchema(index, "Microsoft.Storage/storageAccounts", tt.apiVersion)
+		if got != tt.want {
+			t.Errorf("lookupSchema(%s) returned the wrong schema", tt.apiVersion)
+		}
+		if exact != tt.exact {
+			t.Errorf("lookupSchema(%s) exact = %v, want %v", tt.apiVersion, exact, tt.exact)
+		}
+	}
+
+	if s, _ := lookupSchema(index, "Microsoft.Example/widgets", "2021-04-01"); s != nil {
+		t.Error("Expected nil schema for an unknown type")
+	}
+}
+
+func TestLoadSchemas(t *testing.T) {
+	index, err := loadSchemas()
+	if err != nil {
+		t.Fatalf("loadSchemas failed: %v", err)
+	}
+
+	for _, resType := range []string{
+		"Microsoft.Storage/storageAccounts",
+		"Microsoft.Network/virtualNetworks",
+		"Microsoft.Network/networkSecurityGroups",
+		"Microsoft.Network/publicIPAddresses",
+		"Microsoft.Compute/virtualMachines",
+	} {
+		if len(index[strings.ToLower(resType)]) == 0 {
+			t.Errorf("Expected an embedded schema for %s", resType)
+		}
+	}
+}
@@ -31,11 +31,28 @@ func (s Severity) String() string {
 	}
 }
 
+// Codes identify the check that produced a ValidationResult, so findings
+// can be grouped and counted.
+const (
+	CodeMissingField        = "missing-field"
+	CodeUnrecognizedSchema  = "unrecognized-schema"
+	CodeInvalidResource     = "invalid-resource"
+	CodeUnresolvedReference = "unresolved-reference"
+	CodeSchemaSkipped       = "schema-skipped"
+	CodeTypeMismatch        = "type-mismatch"
+	CodeInvalidValue        = "invalid-value"
+	CodeMissingProperty     = "missing-property"
+	CodeUnknownProperty     = "unknown-property"
+	CodeUndeclaredParameter = "undeclared-parameter"
+	CodeMissingParameter    = "missing-parameter"
+)
+
 // ValidationResult represents a single validation finding.
 type ValidationResult struct {
 	Severity Severity
 	Message  string
 	Field    string
+	Code     string // the check that produced the finding, one of the Code constants
 }
 
 // String returns a formatted string representation of the validation result.
@@ -69,6 +86,7 @@ func (v *Validator) ValidateTemplate(data []byte) ([]ValidationResult, error) {
 			Severity: SeverityError,
 			Field:    "$schema",
 			Message:  "missing required field",
+			Code:     CodeMissingField,
 		})
 	} else {
 		// Validate schema URL
@@ -78,6 +96,7 @@ func (v *Validator) ValidateTemplate(data []byte) ([]ValidationResult, error) {
 				Severity: SeverityWarning,
 				Field:    "$schema",
 				Message:  "unrecognized schema URL",
+				Code:     CodeUnrecognizedSchema,
 			})
 		}
 	}
@@ -87,6 +106,7 @@ func (v *Validator) ValidateTemplate(data []byte) ([]ValidationResult, error) {
 			Severity: SeverityError,
 			Field:    "contentVersion",
 			Message:  "missing required field",
+			Code:     CodeMissingField,
 		})
 	}
 
@@ -95,6 +115,7 @@ func (v *Validator) ValidateTemplate(data []byte) ([]ValidationResult, error) {
 			Severity: SeverityError,
 			Field:    "resources",
 			Message:  "missing required field",
+			Code:     CodeMissingField,
 		})
 	} else {
 		// Validate resources array
@@ -104,6 +125,7 @@ func (v *Validator) ValidateTemplate(data []byte) ([]ValidationResult, error) {
 				resResults := v.validateResource(res, i)
 				results = append(results, resResults...)
 			}
+			results = append(results, v.validateReferences(resources)...)
 		}
 	}
 
@@ -120,6 +142,7 @@ func (v *Validator) validateResource(res interface{}, index int) []ValidationRes
 			Severity: SeverityError,
 			Field:    fmt.Sprintf("resources[%d]", index),
 			Message:  "resource must be an object",
+			Code:     CodeInvalidResource,
 		})
 		return results
 	}
@@ -130,6 +153,7 @@ func (v *Validator) validateResource(res interface{}, index int) []ValidationRes
 			Severity: SeverityError,
 			Field:    fmt.Sprintf("resources[%d].type", index),
 			Message:  "missing required field",
+			Code:     CodeMissingField,
 		})
 	}
 
@@ -138,6 +162,7 @@ func (v *Validator) validateResource(res interface{}, index int) []ValidationRes
 			Severity: SeverityError,
 			Field:    fmt.Sprintf("resources[%d].name", index),
 			Message:  "missing required field",
+			Code:     CodeMissingField,
 		})
 	}
 
@@ -146,9 +171,12 @@ func (v *Validator) validateResource(res interface{}, index int) []ValidationRes
 			Severity: SeverityError,
 			Field:    fmt.Sprintf("resources[%d].apiVersion", index),
 			Message:  "missing required field",
+			Code:     CodeMissingField,
 		})
 	}
 
+	results = append(results, v.validateProperties(resMap, index)...)
+
 	return results
 }
 
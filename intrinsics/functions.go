@@ -0,0 +1,139 @@
+package intrinsics
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Function represents a call of an ARM template function whose arguments may
+// themselves be intrinsics, such as add(length(parameters('zones')), 1).
+type Function struct {
+	// Name is the ARM function name (e.g. "add")
+	Name string
+
+	// Args are the arguments, in order. Intrinsics are nested as expressions,
+	// numbers and bools become literals, and slices become createArray calls.
+	// A string wrapped in brackets, such as "[parameters('zones')]", is an ARM
+	// expression; other strings are string literals.
+	Args []any
+}
+
+// ARMExpression returns the ARM expression for the function call.
+func (f Function) ARMExpression() string {
+	return "[" + f.Expression() + "]"
+}
+
+// Expression returns the function call without the enclosing brackets, for
+// nesting inside another ARM expression.
+func (f Function) Expression() string {
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = expression(arg)
+	}
+	return f.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// expression returns value as an argument of an ARM function.
+func expression(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null()"
+	case Intrinsic:
+		return armArgument(v.ARMExpression())
+	case string:
+		return armArgument(v)
+	case bool:
+		if v {
+			return "true()"
+		}
+		return "false()"
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return CreateArray(items...).Expression()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(value)
+	}
+	// ARM has no other literals; pass the value through json() as a string
+	return "json(" + armArgument(fmt.Sprint(value)) + ")"
+}
+
+// Add creates the add() ARM function, the sum of two integers.
+func Add(a, b any) Function {
+	return Function{Name: "add", Args: []any{a, b}}
+}
+
+// Sub creates the sub() ARM function, a minus b.
+func Sub(a, b any) Function {
+	return Function{Name: "sub", Args: []any{a, b}}
+}
+
+// Mul creates the mul() ARM function, the product of two integers.
+func Mul(a, b any) Function {
+	return Function{Name: "mul", Args: []any{a, b}}
+}
+
+// Div creates the div() ARM function, the integer division of a by b.
+func Div(a, b any) Function {
+	return Function{Name: "div", Args: []any{a, b}}
+}
+
+// Mod creates the mod() ARM function, the remainder of dividing a by b.
+func Mod(a, b any) Function {
+	return Function{Name: "mod", Args: []any{a, b}}
+}
+
+// Length creates the length() ARM function, the number of elements of an
+// array, characters of a string, or properties of an object.
+func Length(value any) Function {
+	return Function{Name: "length", Args: []any{value}}
+}
+
+// First creates the first() ARM function, the first element of an array or
+// character of a string.
+func First(value any) Function {
+	return Function{Name: "first", Args: []any{value}}
+}
+
+// Last creates the last() ARM function, the last element of an array or
+// character of a string.
+func Last(value any) Function {
+	return Function{Name: "last", Args: []any{value}}
+}
+
+// Skip creates the skip() ARM function, value without its first count
+// elements or characters.
+func Skip(value, count any) Function {
+	return Function{Name: "skip", Args: []any{value, count}}
+}
+
+// Take creates the take() ARM function, the first count elements or
+// characters of value.
+func Take(value, count any) Function {
+	return Function{Name: "take", Args: []any{value, count}}
+}
+
+// Union creates the union() ARM function, the distinct elements of arrays
+// or the merged properties of objects.
+func Union(values ...any) Function {
+	return Function{Name: "union", Args: values}
+}
+
+// CreateArray creates the createArray() ARM function, an array of items.
+func CreateArray(items ...any) Function {
+	return Function{Name: "createArray", Args: items}
+}
+
+// Range creates the range() ARM function, count consecutive integers from
+// start (e.g. Range(0, 3) is [0, 1, 2]).
+func Range(start, count any) Function {
+	return Function{Name: "range", Args: []any{start, count}}
+}
@@ -1,6 +1,11 @@
 // Package intrinsics provides ARM template function wrappers for use in resource declarations.
 package intrinsics
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Intrinsic represents an ARM template intrinsic function.
 // When serialized, these become ARM template expressions like "[resourceId(...)]".
 type Intrinsic interface {
@@ -30,19 +35,86 @@ func ResourceId(resourceType, resourceName string, segments ...string) ResourceI
 	}
 }
 
+// resourceRefPrefix starts the marker that ResourceRef returns; build
+// replaces the marker with a resourceId() expression
+const resourceRefPrefix = "[wetwire.resourceRef("
+
+// ResourceRef returns a reference to the resource declared as the Go variable
+// goVarName, or to a child of it given as pairs of child type and name in
+// subPath (e.g. ResourceRef("MyVNet", "subnets", "app") for the app subnet of
+// MyVNet). Build makes the referencing resource depend on it and, where it
+// writes the value to the template, resolves it to a resourceId() expression
+// with the ARM type and name of the resource. Build does not write the
+// properties of typed resources, so a reference there only adds the
+// dependency. The arguments must be string literals for discovery to see them.
+func ResourceRef(goVarName string, subPath ...string) string {
+	args := []string{armArgument(goVarName)}
+	for _, segment := range subPath {
+		args = append(args, armArgument(segment))
+	}
+	return resourceRefPrefix + strings.Join(args, ", ") + ")]"
+}
+
+// ParseResourceRef returns the variable name and sub-path of a reference
+// returned by ResourceRef, and false if s is not one.
+func ParseResourceRef(s string) (goVarName string, subPath []string, ok bool) {
+	if !strings.HasPrefix(s, resourceRefPrefix) || !strings.HasSuffix(s, ")]") {
+		return "", nil, false
+	}
+	rest := s[len(resourceRefPrefix) : len(s)-len(")]")]
+
+	var args []string
+	for {
+		if !strings.HasPrefix(rest, "'") {
+			return "", nil, false
+		}
+		// Find the closing quote, skipping escaped ('') quotes
+		end := 1
+		for {
+			i := strings.Index(rest[end:], "'")
+			if i < 0 {
+				return "", nil, false
+			}
+			end += i
+			if !strings.HasPrefix(rest[end:], "''") {
+				break
+			}
+			end += 2
+		}
+		args = append(args, strings.ReplaceAll(rest[1:end], "''", "'"))
+		rest = rest[end+1:]
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, ", ") {
+			return "", nil, false
+		}
+		rest = rest[len(", "):]
+	}
+	return args[0], args[1:], true
+}
+
 // Reference represents the reference() ARM function.
 type Reference struct {
 	ResourceName string
 	APIVersion   string
 	Property     string
+	// Full requests the full resource object, with top-level fields such as
+	// identity and sku, instead of only its properties
+	Full bool
 }
 
 // ARMExpression returns the ARM expression for reference.
 func (r Reference) ARMExpression() string {
+	call := "reference('" + r.ResourceName + "', '" + r.APIVersion + "'"
+	if r.Full {
+		call += ", 'Full'"
+	}
+	call += ")"
 	if r.Property != "" {
-		return "[reference('" + r.ResourceName + "', '" + r.APIVersion + "')." + r.Property + "]"
+		return "[" + call + "." + r.Property + "]"
 	}
-	return "[reference('" + r.ResourceName + "', '" + r.APIVersion + "')]"
+	return "[" + call + "]"
 }
 
 // Ref creates a Reference intrinsic for referencing another resource.
@@ -62,6 +134,65 @@ func RefProperty(resourceName, apiVersion, property string) Reference {
 	}
 }
 
+// RefFull creates a Reference intrinsic for the full resource object.
+func RefFull(resourceName, apiVersion string) Reference {
+	return Reference{
+		ResourceName: resourceName,
+		APIVersion:   apiVersion,
+		Full:         true,
+	}
+}
+
+// RefFullProperty creates a Reference intrinsic for a field of the full
+// resource object, such as "identity.principalId".
+func RefFullProperty(resourceName, apiVersion, property string) Reference {
+	return Reference{
+		ResourceName: resourceName,
+		APIVersion:   apiVersion,
+		Property:     property,
+		Full:         true,
+	}
+}
+
+// ListKeysValue represents the listKeys() ARM function.
+type ListKeysValue struct {
+	// ResourceID is a resource ID or an ARM expression for one, such as "[resourceId(...)]"
+	ResourceID string
+	APIVersion string
+	Property   string
+}
+
+// ARMExpression returns the ARM expression for listKeys.
+func (l ListKeysValue) ARMExpression() string {
+	resourceID := "'" + l.ResourceID + "'"
+	if strings.HasPrefix(l.ResourceID, "[") && strings.HasSuffix(l.ResourceID, "]") {
+		resourceID = l.ResourceID[1 : len(l.ResourceID)-1]
+	}
+	expr := "listKeys(" + resourceID + ", '" + l.APIVersion + "')"
+	if l.Property != "" {
+		expr += "." + l.Property
+	}
+	return "[" + expr + "]"
+}
+
+// ListKeys creates a ListKeysValue intrinsic for the access keys of a resource.
+func ListKeys(resourceID, apiVersion string) ListKeysValue {
+	return ListKeysValue{
+		ResourceID: resourceID,
+		APIVersion: apiVersion,
+	}
+}
+
+// ListKeysProperty creates a ListKeysValue intrinsic for a specific property
+// of the keys (e.g. "keys[0].value").
+func ListKeysProperty(resourceID, apiVersion, property string) ListKeysValue {
+	return ListKeysValue{
+		ResourceID: resourceID,
+		APIVersion: apiVersion,
+		Property:   property,
+	}
+}
+
 // Parameter represents the parameters() ARM function.
 type Parameter struct {
 	Name string
@@ -94,12 +225,14 @@ func Variables(name string) Variable {
 
 // Concat represents the concat() ARM function.
 type Concat struct {
+	// Values are concatenated in order. Intrinsics and strings wrapped in
+	// brackets are nested as expressions; other strings are string literals.
 	Values []any
 }
 
 // ARMExpression returns the ARM expression for concat.
 func (c Concat) ARMExpression() string {
-	return "[concat(...)]" // Simplified for now
+	return Function{Name: "concat", Args: c.Values}.ARMExpression()
 }
 
 // ResourceGroupValue represents resourceGroup() ARM function.
@@ -135,10 +268,92 @@ func (s Subscription) ARMExpression() string {
 
 // UniqueString represents uniqueString() ARM function.
 type UniqueString struct {
+	// Values are hashed into the string. A value wrapped in brackets, such as
+	// "[resourceGroup().id]", is an ARM expression; others are string literals.
 	Values []string
 }
 
 // ARMExpression returns the ARM expression for uniqueString.
 func (u UniqueString) ARMExpression() string {
-	return "[uniqueString(...)]" // Simplified for now
+	return "[" + u.Expression() + "]"
+}
+
+// Expression returns the uniqueString() call without the enclosing brackets,
+// for nesting inside another ARM expression.
+func (u UniqueString) Expression() string {
+	args := make([]string, len(u.Values))
+	for i, v := range u.Values {
+		args[i] = armArgument(v)
+	}
+	return "uniqueString(" + strings.Join(args, ", ") + ")"
+}
+
+// FormatValue represents the format() ARM function.
+type FormatValue struct {
+	// Format is the format string, with {0}, {1}, ... placeholders
+	Format string
+
+	// Args fill the placeholders in order. A value wrapped in brackets, such
+	// as "[listKeys(...).keys[0].value]", is an ARM expression; others are
+	// string literals.
+	Args []string
+}
+
+// ARMExpression returns the ARM expression for format.
+func (f FormatValue) ARMExpression() string {
+	args := []string{armArgument(f.Format)}
+	for _, a := range f.Args {
+		args = append(args, armArgument(a))
+	}
+	return "[format(" + strings.Join(args, ", ") + ")]"
+}
+
+// Format creates a FormatValue intrinsic that fills the placeholders of
+// format with args.
+func Format(format string, args ...string) FormatValue {
+	return FormatValue{Format: format, Args: args}
+}
+
+// armArgument returns value as an argument of an ARM function: the inner
+// expression of a bracketed value, or a quoted string literal.
+func armArgument(value string) string {
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		return value[1 : len(value)-1]
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// CopyIndexValue represents the copyIndex() ARM function.
+type CopyIndexValue struct {
+	Offset int
+}
+
+// ARMExpression returns the ARM expression for copyIndex.
+func (c CopyIndexValue) ARMExpression() string {
+	if c.Offset != 0 {
+		return fmt.Sprintf("[copyIndex(%d)]", c.Offset)
+	}
+	return "[copyIndex()]"
+}
+
+// CopyIndex creates a CopyIndexValue intrinsic for the current iteration of a
+// copy loop, starting at offset (e.g. CopyIndex(1) counts from 1).
+func CopyIndex(offset int) CopyIndexValue {
+	return CopyIndexValue{Offset: offset}
+}
+
+// Copy represents an ARM copy loop that deploys Count instances of Resource.
+// A package-level Copy is discovered as a resource of Resource's type and
+// built with a copy element; use CopyIndex to vary fields per instance.
+type Copy struct {
+	// Name is the name of the loop; discovery defaults it to the variable name
+	Name string
+	// Count is the number of instances: an int or an intrinsic such as Parameters("vmCount")
+	Count any
+	// Mode is "serial" or "parallel" (the ARM default when empty)
+	Mode string
+	// BatchSize is the number of instances deployed at a time in serial mode
+	BatchSize int
+	// Resource is the resource to repeat
+	Resource any
 }
@@ -75,6 +75,25 @@ func TestReference_ARMExpression(t *testing.T) {
 			},
 			expected: "[reference('myStorage', '2021-02-01').primaryEndpoints.blob]",
 		},
+		{
+			name: "full reference",
+			reference: Reference{
+				ResourceName: "myApp",
+				APIVersion:   "2022-03-01",
+				Full:         true,
+			},
+			expected: "[reference('myApp', '2022-03-01', 'Full')]",
+		},
+		{
+			name: "full reference with property",
+			reference: Reference{
+				ResourceName: "myApp",
+				APIVersion:   "2022-03-01",
+				Property:     "identity.principalId",
+				Full:         true,
+			},
+			expected: "[reference('myApp', '2022-03-01', 'Full').identity.principalId]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -115,6 +134,23 @@ func TestRefProperty_Constructor(t *testing.T) {
 	}
 }
 
+func TestRefFullProperty_Constructor(t *testing.T) {
+	ref := RefFullProperty("myApp", "2022-03-01", "identity.principalId")
+
+	if ref.ResourceName != "myApp" {
+		t.Errorf("ResourceName = %q, want %q", ref.ResourceName, "myApp")
+	}
+	if ref.Property != "identity.principalId" {
+		t.Errorf("Property = %q, want %q", ref.Property, "identity.principalId")
+	}
+	if !ref.Full {
+		t.Error("Full = false, want true")
+	}
+	if RefProperty("myApp", "2022-03-01", "identity.principalId").Full {
+		t.Error("RefProperty should not use the full form")
+	}
+}
+
 func TestParameter_ARMExpression(t *testing.T) {
 	param := Parameter{Name: "storageAccountName"}
 	expected := "[parameters('storageAccountName')]"
@@ -158,8 +194,8 @@ func TestVariables_Constructor(t *testing.T) {
 }
 
 func TestConcat_ARMExpression(t *testing.T) {
-	c := Concat{Values: []any{"a", "b", "c"}}
-	expected := "[concat(...)]"
+	c := Concat{Values: []any{"a", Parameters("b"), "c"}}
+	expected := "[concat('a', parameters('b'), 'c')]"
 
 	result := c.ARMExpression()
 	if result != expected {
@@ -248,16 +284,126 @@ func TestSubscription_ARMExpression(t *testing.T) {
 }
 
 func TestUniqueString_ARMExpression(t *testing.T) {
-	u := UniqueString{Values: []string{"resourceGroup().id", "storageAccountName"}}
-	expected := "[uniqueString(...)]"
+	tests := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{"expression", []string{"[resourceGroup().id]"}, "[uniqueString(resourceGroup().id)]"},
+		{"expression and literal", []string{"[resourceGroup().id]", "storageAccountName"}, "[uniqueString(resourceGroup().id, 'storageAccountName')]"},
+		{"literal with quote", []string{"o'brien"}, "[uniqueString('o''brien')]"},
+	}
 
-	result := u.ARMExpression()
-	if result != expected {
-		t.Errorf("ARMExpression() = %q, want %q", result, expected)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UniqueString{Values: tt.values}.ARMExpression()
+			if result != tt.expected {
+				t.Errorf("ARMExpression() = %q, want %q", result, tt.expected)
+			}
+		})
 	}
 
 	// Verify interface compliance
-	var _ Intrinsic = u
+	var _ Intrinsic = UniqueString{}
+}
+
+func TestFormat_ARMExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		args     []string
+		expected string
+	}{
+		{"literal", "{0}-app", []string{"prod"}, "[format('{0}-app', 'prod')]"},
+		{"expression and literal", "{0}/{1}", []string{"[resourceGroup().name]", "web"}, "[format('{0}/{1}', resourceGroup().name, 'web')]"},
+		{"no args", "static", nil, "[format('static')]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Format(tt.format, tt.args...).ARMExpression()
+			if result != tt.expected {
+				t.Errorf("ARMExpression() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCopyIndex_ARMExpression(t *testing.T) {
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "[copyIndex()]"},
+		{1, "[copyIndex(1)]"},
+		{10, "[copyIndex(10)]"},
+	}
+
+	for _, tt := range tests {
+		result := CopyIndex(tt.offset).ARMExpression()
+		if result != tt.expected {
+			t.Errorf("CopyIndex(%d).ARMExpression() = %q, want %q", tt.offset, result, tt.expected)
+		}
+	}
+}
+
+func TestListKeys_ARMExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		listKeys ListKeysValue
+		expected string
+	}{
+		{
+			name:     "resourceId expression",
+			listKeys: ListKeys(ResourceId("Microsoft.Storage/storageAccounts", "mystorage").ARMExpression(), "2021-04-01"),
+			expected: "[listKeys(resourceId('Microsoft.Storage/storageAccounts', 'mystorage'), '2021-04-01')]",
+		},
+		{
+			name:     "literal resource ID with property",
+			listKeys: ListKeysProperty("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/mystorage", "2021-04-01", "keys[0].value"),
+			expected: "[listKeys('/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/mystorage', '2021-04-01').keys[0].value]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.listKeys.ARMExpression()
+			if result != tt.expected {
+				t.Errorf("ARMExpression() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFunction_ARMExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		function Function
+		expected string
+	}{
+		{"add", Add(Length(Parameters("zones")), 1), "[add(length(parameters('zones')), 1)]"},
+		{"sub", Sub(Parameters("count"), 1), "[sub(parameters('count'), 1)]"},
+		{"mul", Mul(2, CopyIndex(0)), "[mul(2, copyIndex())]"},
+		{"div", Div("[variables('total')]", 2), "[div(variables('total'), 2)]"},
+		{"mod", Mod(CopyIndex(0), 3), "[mod(copyIndex(), 3)]"},
+		{"first", First(Parameters("zones")), "[first(parameters('zones'))]"},
+		{"last", Last("abc"), "[last('abc')]"},
+		{"skip", Skip(Parameters("zones"), 1), "[skip(parameters('zones'), 1)]"},
+		{"take", Take(Range(1, 3), 2), "[take(range(1, 3), 2)]"},
+		{"union", Union(Parameters("a"), []string{"1", "2"}), "[union(parameters('a'), createArray('1', '2'))]"},
+		{"createArray", CreateArray("a", 1, true, nil), "[createArray('a', 1, true(), null())]"},
+		{"range", Range(0, 3), "[range(0, 3)]"},
+		{"quoted literal", Length("it's"), "[length('it''s')]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.function.ARMExpression()
+			if result != tt.expected {
+				t.Errorf("ARMExpression() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
 }
 
 func TestIntrinsicInterface(t *testing.T) {
@@ -271,6 +417,10 @@ func TestIntrinsicInterface(t *testing.T) {
 		ResourceGroupValue{},
 		Subscription{},
 		UniqueString{},
+		CopyIndexValue{},
+		ListKeysValue{},
+		FormatValue{},
+		Function{},
 	}
 
 	for i, intrinsic := range intrinsics {
@@ -280,3 +430,41 @@ func TestIntrinsicInterface(t *testing.T) {
 		}
 	}
 }
+
+func TestResourceRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		goVar   string
+		subPath []string
+		want    string
+	}{
+		{"resource", "MyVNet", nil, "[wetwire.resourceRef('MyVNet')]"},
+		{"child", "MyVNet", []string{"subnets", "app"}, "[wetwire.resourceRef('MyVNet', 'subnets', 'app')]"},
+		{"quoted", "MyVNet", []string{"subnets", "it's"}, "[wetwire.resourceRef('MyVNet', 'subnets', 'it''s')]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := ResourceRef(tt.goVar, tt.subPath...)
+			if ref != tt.want {
+				t.Errorf("ResourceRef() = %q, want %q", ref, tt.want)
+			}
+
+			goVar, subPath, ok := ParseResourceRef(ref)
+			if !ok || goVar != tt.goVar || len(subPath) != len(tt.subPath) {
+				t.Fatalf("ParseResourceRef(%q) = %q, %q, %v", ref, goVar, subPath, ok)
+			}
+			for i := range subPath {
+				if subPath[i] != tt.subPath[i] {
+					t.Errorf("subPath[%d] = %q, want %q", i, subPath[i], tt.subPath[i])
+				}
+			}
+		})
+	}
+
+	for _, s := range []string{"", "MyVNet", "[resourceId('Microsoft.Network/virtualNetworks', 'MyVNet')]", "[wetwire.resourceRef(MyVNet)]", "[wetwire.resourceRef('MyVNet')x)]"} {
+		if _, _, ok := ParseResourceRef(s); ok {
+			t.Errorf("ParseResourceRef(%q) reported a reference", s)
+		}
+	}
+}
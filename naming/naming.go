@@ -0,0 +1,129 @@
+// Package naming provides helpers for globally unique Azure resource names.
+//
+// Names such as those of storage accounts and key vaults must be unique
+// across Azure. Unique builds a name from a readable prefix and a hash of the
+// resource group ID, so each deployment target gets a stable, distinct name:
+//
+//	var MyStorage = storage.StorageAccount{
+//		Name: naming.Unique("logs").For("Microsoft.Storage/storageAccounts").String(),
+//	}
+package naming
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+)
+
+// UniqueStringLength is the length of the value returned by the ARM
+// uniqueString() function
+const UniqueStringLength = 13
+
+// UniqueName is a name made of a prefix followed by uniqueString(resourceGroup().id).
+// It implements intrinsics.Intrinsic.
+type UniqueName struct {
+	// Prefix is the readable part of the name
+	Prefix string
+
+	// MaxLength is the maximum length of the whole name, 0 for no limit.
+	// The prefix is truncated to fit.
+	MaxLength int
+
+	// LowercaseAlphanumeric strips the prefix of characters other than
+	// lowercase letters and digits, as storage account names require
+	LowercaseAlphanumeric bool
+}
+
+// nameRule is the naming restriction of a resource type
+type nameRule struct {
+	maxLength             int
+	lowercaseAlphanumeric bool
+}
+
+// nameRules are the restrictions of resource types that need globally unique names
+var nameRules = map[string]nameRule{
+	"microsoft.storage/storageaccounts":              {maxLength: 24, lowercaseAlphanumeric: true},
+	"microsoft.keyvault/vaults":                      {maxLength: 24},
+	"microsoft.containerregistry/registries":         {maxLength: 50, lowercaseAlphanumeric: true},
+	"microsoft.sql/servers":                          {maxLength: 63},
+	"microsoft.web/sites":                            {maxLength: 60},
+	"microsoft.documentdb/databaseaccounts":          {maxLength: 44},
+	"microsoft.cache/redis":                          {maxLength: 63},
+	"microsoft.servicebus/namespaces":                {maxLength: 50},
+	"microsoft.eventhub/namespaces":                  {maxLength: 50},
+	"microsoft.appconfiguration/configurationstores": {maxLength: 50},
+}
+
+// GloballyUnique reports whether resources of resourceType need names that
+// are unique across Azure, such as storage accounts and key vaults
+func GloballyUnique(resourceType string) bool {
+	_, ok := nameRules[strings.ToLower(resourceType)]
+	return ok
+}
+
+// Unique returns a name that serializes to
+// [concat('<prefix>', uniqueString(resourceGroup().id))]. Use For to apply
+// the length limit of a resource type.
+func Unique(prefix string) UniqueName {
+	return UniqueName{Prefix: prefix}
+}
+
+// For applies the naming restrictions of resourceType, such as the 24
+// character limit of storage accounts. Types without known restrictions
+// leave the name unchanged.
+func (n UniqueName) For(resourceType string) UniqueName {
+	if rule, ok := nameRules[strings.ToLower(resourceType)]; ok {
+		n.MaxLength = rule.maxLength
+		n.LowercaseAlphanumeric = rule.lowercaseAlphanumeric
+	}
+	return n
+}
+
+// WithMaxLength limits the whole name to maxLength characters
+func (n UniqueName) WithMaxLength(maxLength int) UniqueName {
+	n.MaxLength = maxLength
+	return n
+}
+
+// ARMExpression returns the ARM expression for the name
+func (n UniqueName) ARMExpression() string {
+	unique := intrinsics.UniqueString{Values: []string{"[resourceGroup().id]"}}.Expression()
+
+	prefix := n.Prefix
+	if n.LowercaseAlphanumeric {
+		prefix = lowercaseAlphanumeric(prefix)
+	}
+
+	if n.MaxLength > 0 {
+		room := n.MaxLength - UniqueStringLength
+		if room <= 0 {
+			// Not even the hash fits; keep its first MaxLength characters
+			return fmt.Sprintf("[take(%s, %d)]", unique, n.MaxLength)
+		}
+		if len(prefix) > room {
+			prefix = prefix[:room]
+		}
+	}
+
+	if prefix == "" {
+		return "[" + unique + "]"
+	}
+	return "[concat('" + strings.ReplaceAll(prefix, "'", "''") + "', " + unique + ")]"
+}
+
+// String returns the ARM expression, for use in string fields such as Name
+func (n UniqueName) String() string {
+	return n.ARMExpression()
+}
+
+// lowercaseAlphanumeric lowercases s and drops characters other than a-z and 0-9
+func lowercaseAlphanumeric(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package naming
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-azure-go/intrinsics"
+)
+
+func TestUnique_ARMExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		unique   UniqueName
+		expected string
+	}{
+		{
+			name:     "no limit",
+			unique:   Unique("app-"),
+			expected: "[concat('app-', uniqueString(resourceGroup().id))]",
+		},
+		{
+			name:     "empty prefix",
+			unique:   Unique(""),
+			expected: "[uniqueString(resourceGroup().id)]",
+		},
+		{
+			name:     "key vault keeps hyphens",
+			unique:   Unique("kv-").For("Microsoft.KeyVault/vaults"),
+			expected: "[concat('kv-', uniqueString(resourceGroup().id))]",
+		},
+		{
+			name:     "unknown type is unrestricted",
+			unique:   Unique("a-very-long-prefix-").For("Microsoft.Example/widgets"),
+			expected: "[concat('a-very-long-prefix-', uniqueString(resourceGroup().id))]",
+		},
+		{
+			name:     "limit shorter than the hash",
+			unique:   Unique("x").WithMaxLength(8),
+			expected: "[take(uniqueString(resourceGroup().id), 8)]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.unique.ARMExpression(); result != tt.expected {
+				t.Errorf("ARMExpression() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	// Verify interface compliance
+	var _ intrinsics.Intrinsic = UniqueName{}
+}
+
+func TestUnique_StorageAccountTruncation(t *testing.T) {
+	name := Unique("Prod-Diagnostics-Logs").For("Microsoft.Storage/storageAccounts")
+
+	expected := "[concat('proddiagnos', uniqueString(resourceGroup().id))]"
+	if result := name.String(); result != expected {
+		t.Fatalf("String() = %q, want %q", result, expected)
+	}
+
+	// The deployed name is the prefix followed by the 13 character hash
+	prefix := strings.TrimSuffix(strings.TrimPrefix(expected, "[concat('"), "', uniqueString(resourceGroup().id))]")
+	if got := len(prefix) + UniqueStringLength; got > 24 {
+		t.Errorf("Deployed name length = %d, want at most 24", got)
+	}
+	if len(prefix)+UniqueStringLength != 24 {
+		t.Errorf("Expected the prefix to use all %d remaining characters, got %q", 24-UniqueStringLength, prefix)
+	}
+}
+
+func TestUnique_ShortStoragePrefix(t *testing.T) {
+	name := Unique("st").For("Microsoft.Storage/storageAccounts")
+
+	expected := "[concat('st', uniqueString(resourceGroup().id))]"
+	if result := name.ARMExpression(); result != expected {
+		t.Errorf("ARMExpression() = %q, want %q", result, expected)
+	}
+}
+
+func TestGloballyUnique(t *testing.T) {
+	for resourceType, want := range map[string]bool{
+		"Microsoft.Storage/storageAccounts":     true,
+		"microsoft.keyvault/vaults":             true,
+		"Microsoft.DocumentDB/databaseAccounts": true,
+		"Microsoft.Network/virtualNetworks":     false,
+		"Microsoft.KeyVault/vaults/keys":        false,
+	} {
+		if got := GloballyUnique(resourceType); got != want {
+			t.Errorf("GloballyUnique(%q) = %v, want %v", resourceType, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Package synth is the library API of wetwire-azure. It synthesizes an ARM
+// template from a directory of Go resource declarations, as the build command
+// does, so Go programs such as tests and CI tools can generate templates
+// without shelling out to the CLI.
+//
+//	tmpl, err := synth.Synthesize("./infra", synth.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	_, err = tmpl.WriteTo(os.Stdout)
+package synth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/lex00/wetwire-azure-go/internal/discover"
+	"github.com/lex00/wetwire-azure-go/internal/template"
+)
+
+// GeneratorMetadataKey is the template metadata entry identifying the tool
+// that generated the template. It cannot be set through Options.Metadata.
+const GeneratorMetadataKey = "_generator"
+
+// MaxDeploymentResources is the most resources ARM accepts in a single
+// deployment, counting each instance of a copy loop; a typical
+// Options.MaxResources
+const MaxDeploymentResources = template.MaxDeploymentResources
+
+// ErrNoResources is returned when the source directory declares no Azure resources
+var ErrNoResources = errors.New("no Azure resources found")
+
+// ErrMergeConflict is returned when Options.Base cannot be combined with the
+// generated template, such as when both define a parameter differently
+var ErrMergeConflict = template.ErrMergeConflict
+
+// ErrTooManyResources is returned when the template has more resources than
+// Options.MaxResources
+var ErrTooManyResources = template.ErrTooManyResources
+
+// ErrReservedMetadata is returned when Options.Metadata sets GeneratorMetadataKey
+var ErrReservedMetadata = fmt.Errorf("metadata key %s is reserved for the generator stamp", GeneratorMetadataKey)
+
+// Options configures Synthesize. The zero value builds an indented,
+// key-sorted resource group template, as build does by default.
+type Options struct {
+	// Scope is the ARM deployment scope (resourceGroup, subscription,
+	// managementGroup, tenant); empty means resourceGroup
+	Scope string
+
+	// MinAPIVersion rejects resources whose explicit APIVersion predates it
+	MinAPIVersion string
+
+	// MaxResources fails synthesis with ErrTooManyResources when the template
+	// has more resources, counting each instance of a copy loop with a
+	// literal count; zero means no limit. ARM deployments are limited to
+	// MaxDeploymentResources.
+	MaxResources int
+
+	// Compact emits single-line JSON instead of indented JSON
+	Compact bool
+
+	// SortKeys sorts the keys of every JSON object, including the fields of
+	// the template and its resources, instead of keeping their conventional
+	// ARM order
+	SortKeys bool
+
+	// OmitEmptySections leaves out the parameters, variables, and outputs
+	// sections when they are empty
+	OmitEmptySections bool
+
+	// Exclude holds glob patterns for files and directories to skip
+	Exclude []string
+
+	// Metadata holds extra entries for the top-level metadata of the
+	// template, alongside the _generator stamp
+	Metadata map[string]string
+
+	// Version is the generator version stamped in the template metadata;
+	// empty means "dev"
+	Version string
+
+	// SubscriptionID and ResourceGroup replace the {sub}-style placeholders
+	// of resource IDs in the generated resource properties (see
+	// template.ResourceContext); the placeholders of empty values become the
+	// deployment's subscription() and resourceGroup()
+	SubscriptionID string
+	ResourceGroup  string
+
+	// Base is an existing ARM template to merge the generated template into
+	// (see template.MergeTemplates); nil generates a standalone template
+	Base []byte
+
+	// Cache reuses the declarations parsed by earlier calls with the same
+	// Cache for files that have not changed since; nil parses every file
+	Cache *Cache
+}
+
+// Cache holds the parsed declarations of each source file across repeated
+// syntheses of the same tree, as in watch mode, so that only changed files
+// are parsed again. A Cache is safe for concurrent use.
+type Cache struct {
+	discovery *discover.Cache
+}
+
+// NewCache creates an empty synthesis cache
+func NewCache() *Cache {
+	return &Cache{discovery: discover.NewCache()}
+}
+
+// Template is a synthesized ARM template
+type Template struct {
+	// JSON is the ARM template
+	JSON []byte
+
+	// Resources are the resources declared in the source, ordered by file
+	// then line
+	Resources []Resource
+
+	// Replaced lists the resources of Options.Base replaced by generated
+	// resources of the same type and name, as "<type>/<name>"
+	Replaced []string
+}
+
+// Resource describes a resource declaration in the source
+type Resource struct {
+	// Name is the Go variable name of the resource
+	Name string
+
+	// Type is the Azure resource type (e.g. Microsoft.Storage/storageAccounts)
+	Type string
+
+	// File is the absolute path of the file declaring the resource
+	File string
+
+	// Line is the line of the declaration in File
+	Line int
+}
+
+// WriteTo writes the template JSON to w, implementing io.WriterTo
+func (t Template) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(t.JSON)
+	return int64(n), err
+}
+
+// ResourceError is a validation failure of one resource declaration
+type ResourceError struct {
+	// Resource is the Go variable name of the resource
+	Resource string
+
+	// File and Line locate the declaration
+	File string
+	Line int
+
+	// Err describes the failure
+	Err error
+}
+
+func (e ResourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Resource, e.Err)
+}
+
+// ValidationError is returned when resource declarations fail validation.
+// It lists every failure rather than only the first.
+type ValidationError struct {
+	Errors []ResourceError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "invalid resources: " + strings.Join(msgs, "; ")
+}
+
+// Synthesize discovers the Azure resources declared in the Go files under
+// path and generates their ARM template, merged into Options.Base if set. It
+// returns ErrNoResources if there are none, a *ValidationError if
+// declarations fail validation, errors wrapping ErrTooManyResources if there
+// are more than Options.MaxResources, and errors wrapping ErrMergeConflict if
+// the template cannot be merged.
+func Synthesize(path string, opts Options) (Template, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Template{}, fmt.Errorf("resolve path: %w", err)
+	}
+
+	discoverResources := discover.DiscoverResources
+	if opts.Cache != nil {
+		discoverResources = opts.Cache.discovery.DiscoverResources
+	}
+	resources, err := discoverResources(absPath, opts.Exclude...)
+	if err != nil {
+		return Template{}, fmt.Errorf("discovery failed: %w", err)
+	}
+	if len(resources) == 0 {
+		return Template{}, ErrNoResources
+	}
+
+	scope := template.ScopeResourceGroup
+	if opts.Scope != "" {
+		scope, err = template.ParseScope(opts.Scope)
+		if err != nil {
+			return Template{}, err
+		}
+	}
+	if _, ok := opts.Metadata[GeneratorMetadataKey]; ok {
+		return Template{}, ErrReservedMetadata
+	}
+
+	builder := template.NewTemplateBuilder(scope).
+		WithMinAPIVersion(opts.MinAPIVersion).
+		WithMaxResources(opts.MaxResources).
+		WithSortedKeys(opts.SortKeys).
+		WithEmptySections(!opts.OmitEmptySections).
+		WithMetadata(templateMetadata(opts)).
+		WithResourceContext(template.ResourceContext{
+			SubscriptionID: opts.SubscriptionID,
+			ResourceGroup:  opts.ResourceGroup,
+		})
+	var invalid ValidationError
+	for _, res := range resources {
+		if err := builder.AddResource(res); err != nil {
+			var verr *template.ValidationError
+			if !errors.As(err, &verr) {
+				return Template{}, fmt.Errorf("failed to add resource %s: %w", res.Name, err)
+			}
+			for _, e := range verr.Errors {
+				invalid.Errors = append(invalid.Errors, ResourceError{
+					Resource: res.Name,
+					File:     res.File,
+					Line:     res.Line,
+					Err:      e,
+				})
+			}
+		}
+	}
+	if len(invalid.Errors) > 0 {
+		return Template{}, &invalid
+	}
+
+	build := builder.Build
+	if opts.Compact {
+		build = builder.BuildCompact
+	}
+	templateJSON, err := build()
+	if err != nil {
+		return Template{}, fmt.Errorf("template build failed: %w", err)
+	}
+
+	var replaced []string
+	if opts.Base != nil {
+		var merged []byte
+		merged, replaced, err = template.MergeTemplates(opts.Base, []byte(templateJSON))
+		if err != nil {
+			return Template{}, fmt.Errorf("merge failed: %w", err)
+		}
+		if templateJSON, err = template.FormatJSON(merged, !opts.Compact, opts.SortKeys); err != nil {
+			return Template{}, fmt.Errorf("merge failed: %w", err)
+		}
+	}
+
+	tmpl := Template{
+		JSON:      []byte(templateJSON),
+		Resources: make([]Resource, len(resources)),
+		Replaced:  replaced,
+	}
+	for i, res := range resources {
+		tmpl.Resources[i] = Resource{Name: res.Name, Type: res.Type, File: res.File, Line: res.Line}
+	}
+	return tmpl, nil
+}
+
+// templateMetadata returns the top-level metadata of the template: the
+// _generator stamp and the entries of opts.Metadata
+func templateMetadata(opts Options) map[string]interface{} {
+	version := opts.Version
+	if version == "" {
+		version = "dev"
+	}
+	metadata := map[string]interface{}{
+		GeneratorMetadataKey: map[string]string{
+			"name":    "wetwire-azure",
+			"version": version,
+		},
+	}
+	for k, v := range opts.Metadata {
+		metadata[k] = v
+	}
+	return metadata
+}
@@ -0,0 +1,252 @@
+package synth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSource writes code as main.go in a new temporary directory
+func writeSource(t *testing.T, code string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// TestSynthesize tests synthesizing a template through the library API
+func TestSynthesize(t *testing.T) {
+	dir := writeSource(t, `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`)
+
+	tmpl, err := Synthesize(dir, Options{Version: "1.2.3", Metadata: map[string]string{"owner": "platform"}})
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+
+	var arm struct {
+		Schema    string `json:"$schema"`
+		Metadata  map[string]any
+		Resources []struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(tmpl.JSON, &arm); err != nil {
+		t.Fatalf("template is not valid JSON: %v\n%s", err, tmpl.JSON)
+	}
+	if !strings.Contains(arm.Schema, "deploymentTemplate.json") {
+		t.Errorf("$schema = %q, want a resource group schema", arm.Schema)
+	}
+	if len(arm.Resources) != 1 || arm.Resources[0].Type != "Microsoft.Storage/storageAccounts" {
+		t.Errorf("resources = %+v, want one storage account", arm.Resources)
+	}
+	generator, _ := arm.Metadata[GeneratorMetadataKey].(map[string]any)
+	if generator["version"] != "1.2.3" {
+		t.Errorf("generator stamp = %v, want version 1.2.3", arm.Metadata[GeneratorMetadataKey])
+	}
+	if arm.Metadata["owner"] != "platform" {
+		t.Errorf("metadata owner = %v, want platform", arm.Metadata["owner"])
+	}
+
+	if len(tmpl.Resources) != 1 {
+		t.Fatalf("Resources = %+v, want one resource", tmpl.Resources)
+	}
+	res := tmpl.Resources[0]
+	if res.Name != "Storage" || res.Type != "Microsoft.Storage/storageAccounts" ||
+		res.File != filepath.Join(dir, "main.go") || res.Line != 5 {
+		t.Errorf("Resources[0] = %+v", res)
+	}
+}
+
+// TestSynthesize_Options tests that options shape the generated JSON
+func TestSynthesize_Options(t *testing.T) {
+	dir := writeSource(t, `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`)
+
+	tmpl, err := Synthesize(dir, Options{Compact: true, OmitEmptySections: true})
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+	templateJSON := string(tmpl.JSON)
+	if strings.Contains(strings.TrimSpace(templateJSON), "\n") {
+		t.Errorf("Compact template spans several lines:\n%s", templateJSON)
+	}
+	if strings.Contains(templateJSON, `"outputs"`) {
+		t.Errorf("OmitEmptySections template has outputs:\n%s", templateJSON)
+	}
+
+	_, err = Synthesize(dir, Options{Scope: "subscription"})
+	if err == nil || !strings.Contains(err.Error(), "cannot be deployed at subscription scope") {
+		t.Errorf("Synthesize() at subscription scope error = %v, want a scope error", err)
+	}
+	if _, err := Synthesize(dir, Options{Scope: "galaxy"}); err == nil {
+		t.Error("expected an error for an unknown scope")
+	}
+}
+
+// TestSynthesize_ResourceContext tests that resource ID placeholders are
+// replaced when a subscription and resource group are given, and by the
+// deployment's otherwise
+func TestSynthesize_ResourceContext(t *testing.T) {
+	dir := writeSource(t, `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = (&storage.StorageAccount{Name: "mystorage", Location: "eastus"}).
+	WithPrivateEndpoint("/subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pe", "blob")
+`)
+
+	tmpl, err := Synthesize(dir, Options{SubscriptionID: "11111111-2222-3333-4444-555555555555", ResourceGroup: "prod-rg"})
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+	want := "/subscriptions/11111111-2222-3333-4444-555555555555/resourceGroups/prod-rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pe"
+	if !strings.Contains(string(tmpl.JSON), want) {
+		t.Errorf("template does not reference %s:\n%s", want, tmpl.JSON)
+	}
+	if strings.Contains(string(tmpl.JSON), "{sub}") || strings.Contains(string(tmpl.JSON), "{rg}") {
+		t.Errorf("template still has placeholders:\n%s", tmpl.JSON)
+	}
+
+	tmpl, err = Synthesize(dir, Options{})
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+	want = "[concat('/subscriptions/', subscription().subscriptionId, '/resourceGroups/', resourceGroup().name, '/providers/Microsoft.Network/virtualNetworks/vnet/subnets/pe')]"
+	if !strings.Contains(string(tmpl.JSON), want) {
+		t.Errorf("template without a context does not reference %s:\n%s", want, tmpl.JSON)
+	}
+}
+
+// TestSynthesize_Cache tests that a cached synthesis matches an uncached one
+// and picks up changed files
+func TestSynthesize_Cache(t *testing.T) {
+	const source = `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: %q, Location: "eastus"}
+`
+	dir := writeSource(t, fmt.Sprintf(source, "mystorage"))
+	cache := NewCache()
+
+	cached, err := Synthesize(dir, Options{Cache: cache})
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+	uncached, err := Synthesize(dir, Options{})
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+	if !bytes.Equal(cached.JSON, uncached.JSON) {
+		t.Errorf("cached template differs:\n%s\nwant:\n%s", cached.JSON, uncached.JSON)
+	}
+
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(source, "otherstorage")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, err = Synthesize(dir, Options{Cache: cache})
+	if err != nil {
+		t.Fatalf("Synthesize() error: %v", err)
+	}
+	if !strings.Contains(string(cached.JSON), `"name": "otherstorage"`) {
+		t.Errorf("template does not reflect the changed file:\n%s", cached.JSON)
+	}
+}
+
+// TestSynthesize_Errors tests the errors returned for sources that cannot
+// be synthesized
+func TestSynthesize_Errors(t *testing.T) {
+	empty := writeSource(t, "package main\n")
+	if _, err := Synthesize(empty, Options{}); !errors.Is(err, ErrNoResources) {
+		t.Errorf("Synthesize() error = %v, want ErrNoResources", err)
+	}
+
+	dir := writeSource(t, `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Storage = storage.StorageAccount{Name: "mystorage", Location: "eastus"}
+`)
+	_, err := Synthesize(dir, Options{Metadata: map[string]string{GeneratorMetadataKey: "me"}})
+	if !errors.Is(err, ErrReservedMetadata) {
+		t.Errorf("Synthesize() error = %v, want ErrReservedMetadata", err)
+	}
+
+	if _, err := Synthesize(dir, Options{MaxResources: 1}); err != nil {
+		t.Errorf("Synthesize() with one resource allowed error = %v", err)
+	}
+	tooManyDir := writeSource(t, `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var Logs = storage.StorageAccount{Name: "logs", Location: "eastus"}
+
+var Data = storage.StorageAccount{Name: "data", Location: "eastus"}
+`)
+	if _, err := Synthesize(tooManyDir, Options{MaxResources: 1}); !errors.Is(err, ErrTooManyResources) {
+		t.Errorf("Synthesize() error = %v, want ErrTooManyResources", err)
+	}
+
+	invalidDir := writeSource(t, `package main
+
+import "github.com/lex00/wetwire-azure-go/resources/storage"
+
+var BlobStore = storage.StorageAccount{
+	Name:     "blobstore",
+	Location: "eastus",
+	Kind:     "BlockBlobStorage",
+	SKU:      storage.SKU{Name: "Standard_LRS"},
+}
+`)
+	_, err = Synthesize(invalidDir, Options{})
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Synthesize() error = %v, want *ValidationError", err)
+	}
+	if len(invalid.Errors) != 1 {
+		t.Fatalf("Errors = %v, want one", invalid.Errors)
+	}
+	e := invalid.Errors[0]
+	if e.Resource != "BlobStore" || e.File != filepath.Join(invalidDir, "main.go") || e.Line != 5 ||
+		!strings.Contains(e.Error(), "BlobStore: kind BlockBlobStorage requires a Premium SKU") {
+		t.Errorf("Errors[0] = %+v (%v)", e, e)
+	}
+}
+
+// TestTemplate_WriteTo tests writing a template to an io.Writer
+func TestTemplate_WriteTo(t *testing.T) {
+	tmpl := Template{JSON: []byte(`{"resources":[]}`)}
+
+	var buf bytes.Buffer
+	n, err := tmpl.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+	if n != int64(len(tmpl.JSON)) || buf.String() != string(tmpl.JSON) {
+		t.Errorf("WriteTo() wrote %d bytes %q, want %q", n, buf.String(), tmpl.JSON)
+	}
+}
@@ -0,0 +1,43 @@
+package aks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AgentPool represents a Microsoft.ContainerService/managedClusters/agentPools
+// resource: a node pool managed separately from the cluster declaration.
+type AgentPool struct {
+	// Name is the name of the pool, in the form "<cluster>/<pool>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties holds the pool settings. Its Name is left empty; the pool
+	// is named by the resource Name.
+	Properties ManagedClusterAgentPoolProfile `json:"properties"`
+}
+
+// NewClusterAgentPool creates a standalone agent pool under the named
+// cluster from a profile built with NewAgentPool, such as
+// NewClusterAgentPool("aks", NewAgentPool("user", "Standard_D4s_v5", 3).WithMode("User"))
+func NewClusterAgentPool(clusterName string, profile ManagedClusterAgentPoolProfile) *AgentPool {
+	poolName := profile.Name
+	profile.Name = ""
+	return &AgentPool{
+		Name:       clusterName + "/" + poolName,
+		Type:       "Microsoft.ContainerService/managedClusters/agentPools",
+		APIVersion: "2023-05-01",
+		Properties: profile,
+	}
+}
+
+// ID returns the ARM resourceId expression for the agent pool
+func (p *AgentPool) ID() string {
+	cluster, pool, _ := strings.Cut(p.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.ContainerService/managedClusters/agentPools', '%s', '%s')]", cluster, pool)
+}
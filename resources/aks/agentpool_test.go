@@ -0,0 +1,56 @@
+package aks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClusterAgentPool(t *testing.T) {
+	pool := NewClusterAgentPool("app-aks", NewAgentPool("user", "Standard_D4s_v5", 3).WithMode("User"))
+
+	assert.Equal(t, "app-aks/user", pool.Name)
+	assert.Equal(t, "Microsoft.ContainerService/managedClusters/agentPools", pool.Type)
+	assert.Equal(t, "2023-05-01", pool.APIVersion)
+	assert.Empty(t, pool.Properties.Name)
+	assert.Equal(t, "User", *pool.Properties.Mode)
+	assert.Equal(t, 3, *pool.Properties.Count)
+	assert.Equal(t, "[resourceId('Microsoft.ContainerService/managedClusters/agentPools', 'app-aks', 'user')]", pool.ID())
+}
+
+func TestAgentPool_JSON(t *testing.T) {
+	pool := NewClusterAgentPool("app-aks", NewAgentPool("user", "Standard_D4s_v5", 3).WithAutoScaling(1, 5))
+
+	data, err := json.Marshal(pool)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "app-aks/user", result["name"])
+	props := result["properties"].(map[string]interface{})
+	assert.NotContains(t, props, "name")
+	assert.Equal(t, "Standard_D4s_v5", props["vmSize"])
+	assert.Equal(t, true, props["enableAutoScaling"])
+}
+
+func TestManagedCluster_ID(t *testing.T) {
+	cluster := NewManagedCluster("app-aks", "eastus", "app")
+	assert.Equal(t, "[resourceId('Microsoft.ContainerService/managedClusters', 'app-aks')]", cluster.ID())
+}
+
+func TestManagedCluster_WithAutoUpgradeChannel(t *testing.T) {
+	cluster := NewManagedCluster("app-aks", "eastus", "app").
+		WithAutoUpgradeChannel("stable").
+		WithNodeOSUpgradeChannel("NodeImage")
+
+	require.NotNil(t, cluster.Properties.AutoUpgradeProfile)
+	assert.Equal(t, "stable", *cluster.Properties.AutoUpgradeProfile.UpgradeChannel)
+	assert.Equal(t, "NodeImage", *cluster.Properties.AutoUpgradeProfile.NodeOSUpgradeChannel)
+
+	data, err := json.Marshal(cluster)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"autoUpgradeProfile":{"upgradeChannel":"stable","nodeOSUpgradeChannel":"NodeImage"}`)
+}
@@ -0,0 +1,165 @@
+package aks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the maintenance configurations AKS reads; a cluster has at most
+// one of each
+const (
+	// MaintenanceConfigurationDefault schedules AKS releases and weekly
+	// releases with TimeInWeek
+	MaintenanceConfigurationDefault = "default"
+
+	// MaintenanceConfigurationAutoUpgrade schedules the upgrades of the
+	// cluster's auto-upgrade channel with MaintenanceWindow
+	MaintenanceConfigurationAutoUpgrade = "aksManagedAutoUpgradeSchedule"
+
+	// MaintenanceConfigurationNodeOSUpgrade schedules the upgrades of the
+	// cluster's node OS upgrade channel with MaintenanceWindow
+	MaintenanceConfigurationNodeOSUpgrade = "aksManagedNodeOSUpgradeSchedule"
+)
+
+// MaintenanceConfiguration represents a
+// Microsoft.ContainerService/managedClusters/maintenanceConfigurations
+// resource: the time windows in which AKS may upgrade a cluster
+type MaintenanceConfiguration struct {
+	// Name is the name of the configuration, in the form "<cluster>/<name>",
+	// where the name is one of the MaintenanceConfiguration* constants
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the maintenance windows
+	Properties MaintenanceConfigurationProperties `json:"properties"`
+}
+
+// MaintenanceConfigurationProperties represents the maintenance windows of a
+// configuration: TimeInWeek for the default configuration, and
+// MaintenanceWindow for the upgrade schedules
+type MaintenanceConfigurationProperties struct {
+	// TimeInWeek are the days and hours in which maintenance may start
+	TimeInWeek []TimeInWeek `json:"timeInWeek,omitempty"`
+
+	// NotAllowedTime are the periods in which maintenance may not run
+	NotAllowedTime []TimeSpan `json:"notAllowedTime,omitempty"`
+
+	// MaintenanceWindow is the recurring window of an upgrade schedule
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+}
+
+// TimeInWeek is a day and the hours of it in which maintenance may start
+type TimeInWeek struct {
+	// Day is the day of the week (Sunday, Monday, ... Saturday)
+	Day string `json:"day"`
+
+	// HourSlots are the hours (0-23, UTC) in which maintenance may start
+	HourSlots []int `json:"hourSlots"`
+}
+
+// TimeSpan is a period given by its start and end date-times (RFC 3339)
+type TimeSpan struct {
+	// Start is the start of the period
+	Start string `json:"start"`
+
+	// End is the end of the period
+	End string `json:"end"`
+}
+
+// MaintenanceWindow is a recurring maintenance window
+type MaintenanceWindow struct {
+	// Schedule is how often the window recurs
+	Schedule MaintenanceSchedule `json:"schedule"`
+
+	// DurationHours is the length of the window (at least 4)
+	DurationHours int `json:"durationHours"`
+
+	// StartTime is the time of day the window starts (hh:mm)
+	StartTime string `json:"startTime"`
+
+	// UTCOffset is the offset of StartTime from UTC (e.g. +00:00, -08:00)
+	UTCOffset string `json:"utcOffset,omitempty"`
+
+	// StartDate is the date the schedule takes effect (YYYY-MM-DD); today if empty
+	StartDate string `json:"startDate,omitempty"`
+
+	// NotAllowedDates are date ranges in which the window does not recur
+	NotAllowedDates []DateSpan `json:"notAllowedDates,omitempty"`
+}
+
+// MaintenanceSchedule sets how often a maintenance window recurs; exactly
+// one of its fields is set
+type MaintenanceSchedule struct {
+	// Daily recurs the window every IntervalDays days
+	Daily *DailySchedule `json:"daily,omitempty"`
+
+	// Weekly recurs the window on a day of every IntervalWeeks weeks
+	Weekly *WeeklySchedule `json:"weekly,omitempty"`
+}
+
+// DailySchedule recurs a maintenance window every few days
+type DailySchedule struct {
+	// IntervalDays is the number of days between windows (1-7)
+	IntervalDays int `json:"intervalDays"`
+}
+
+// WeeklySchedule recurs a maintenance window on a day every few weeks
+type WeeklySchedule struct {
+	// IntervalWeeks is the number of weeks between windows (1-4)
+	IntervalWeeks int `json:"intervalWeeks"`
+
+	// DayOfWeek is the day of the window (Sunday, Monday, ... Saturday)
+	DayOfWeek string `json:"dayOfWeek"`
+}
+
+// DateSpan is a range of dates (YYYY-MM-DD), both included
+type DateSpan struct {
+	// Start is the first date of the range
+	Start string `json:"start"`
+
+	// End is the last date of the range
+	End string `json:"end"`
+}
+
+// NewMaintenanceConfiguration creates an empty maintenance configuration
+// under the named cluster, where name is one of the MaintenanceConfiguration*
+// constants; add windows with WithTimeInWeek or WithWeeklyWindow
+func NewMaintenanceConfiguration(clusterName, name string) *MaintenanceConfiguration {
+	return &MaintenanceConfiguration{
+		Name:       clusterName + "/" + name,
+		Type:       "Microsoft.ContainerService/managedClusters/maintenanceConfigurations",
+		APIVersion: "2023-10-01",
+	}
+}
+
+// WithTimeInWeek allows maintenance to start on day in the hours hourSlots
+// (UTC), for the default configuration
+func (c *MaintenanceConfiguration) WithTimeInWeek(day string, hourSlots ...int) *MaintenanceConfiguration {
+	c.Properties.TimeInWeek = append(c.Properties.TimeInWeek, TimeInWeek{Day: day, HourSlots: hourSlots})
+	return c
+}
+
+// WithWeeklyWindow sets a window of durationHours hours every week on
+// dayOfWeek from startTime (hh:mm, UTC), for the upgrade schedules
+func (c *MaintenanceConfiguration) WithWeeklyWindow(dayOfWeek, startTime string, durationHours int) *MaintenanceConfiguration {
+	c.Properties.MaintenanceWindow = &MaintenanceWindow{
+		Schedule: MaintenanceSchedule{
+			Weekly: &WeeklySchedule{IntervalWeeks: 1, DayOfWeek: dayOfWeek},
+		},
+		DurationHours: durationHours,
+		StartTime:     startTime,
+		UTCOffset:     "+00:00",
+	}
+	return c
+}
+
+// ID returns the ARM resourceId expression for the maintenance configuration
+func (c *MaintenanceConfiguration) ID() string {
+	cluster, name, _ := strings.Cut(c.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.ContainerService/managedClusters/maintenanceConfigurations', '%s', '%s')]", cluster, name)
+}
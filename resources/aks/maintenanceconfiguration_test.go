@@ -0,0 +1,55 @@
+package aks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaintenanceConfiguration_WeeklyWindow(t *testing.T) {
+	config := NewMaintenanceConfiguration("app-aks", MaintenanceConfigurationAutoUpgrade).
+		WithWeeklyWindow("Sunday", "02:00", 4)
+
+	assert.Equal(t, "app-aks/aksManagedAutoUpgradeSchedule", config.Name)
+	assert.Equal(t, "Microsoft.ContainerService/managedClusters/maintenanceConfigurations", config.Type)
+	assert.Equal(t, "2023-10-01", config.APIVersion)
+	assert.Equal(t, "[resourceId('Microsoft.ContainerService/managedClusters/maintenanceConfigurations', 'app-aks', 'aksManagedAutoUpgradeSchedule')]", config.ID())
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "app-aks/aksManagedAutoUpgradeSchedule",
+		"type": "Microsoft.ContainerService/managedClusters/maintenanceConfigurations",
+		"apiVersion": "2023-10-01",
+		"properties": {
+			"maintenanceWindow": {
+				"schedule": {"weekly": {"intervalWeeks": 1, "dayOfWeek": "Sunday"}},
+				"durationHours": 4,
+				"startTime": "02:00",
+				"utcOffset": "+00:00"
+			}
+		}
+	}`, string(data))
+}
+
+func TestNewMaintenanceConfiguration_TimeInWeek(t *testing.T) {
+	config := NewMaintenanceConfiguration("app-aks", MaintenanceConfigurationDefault).
+		WithTimeInWeek("Saturday", 1, 2, 3).
+		WithTimeInWeek("Sunday", 1)
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "app-aks/default",
+		"type": "Microsoft.ContainerService/managedClusters/maintenanceConfigurations",
+		"apiVersion": "2023-10-01",
+		"properties": {
+			"timeInWeek": [
+				{"day": "Saturday", "hourSlots": [1, 2, 3]},
+				{"day": "Sunday", "hourSlots": [1]}
+			]
+		}
+	}`, string(data))
+}
@@ -1,6 +1,8 @@
 // Package aks provides Azure Kubernetes Service (AKS) resource types
 package aks
 
+import "fmt"
+
 // ManagedCluster represents a Microsoft.ContainerService/managedClusters resource
 type ManagedCluster struct {
 	// Name is the name of the managed cluster
@@ -89,12 +91,28 @@ type ManagedClusterProperties struct {
 
 	// AzureMonitorProfile is the Azure Monitor configuration
 	AzureMonitorProfile *ManagedClusterAzureMonitorProfile `json:"azureMonitorProfile,omitempty"`
+
+	// AutoUpgradeProfile is the automatic upgrade configuration
+	AutoUpgradeProfile *ManagedClusterAutoUpgradeProfile `json:"autoUpgradeProfile,omitempty"`
+}
+
+// ManagedClusterAutoUpgradeProfile represents automatic upgrade configuration.
+// Upgrades run in the windows of the cluster's MaintenanceConfiguration
+// resources, if any.
+type ManagedClusterAutoUpgradeProfile struct {
+	// UpgradeChannel is the Kubernetes upgrade channel (rapid, stable, patch, node-image, none)
+	UpgradeChannel *string `json:"upgradeChannel,omitempty"`
+
+	// NodeOSUpgradeChannel is the node OS image upgrade channel (None,
+	// Unmanaged, SecurityPatch, NodeImage); requires API version 2023-06-01
+	// or later
+	NodeOSUpgradeChannel *string `json:"nodeOSUpgradeChannel,omitempty"`
 }
 
 // ManagedClusterAgentPoolProfile represents an agent pool configuration
 type ManagedClusterAgentPoolProfile struct {
-	// Name is the unique name of the agent pool
-	Name string `json:"name"`
+	// Name is the unique name of the agent pool; empty in the properties of a standalone AgentPool
+	Name string `json:"name,omitempty"`
 
 	// Count is the number of agents (VMs)
 	Count *int `json:"count,omitempty"`
@@ -680,6 +698,11 @@ func (m *ManagedCluster) WithTags(tags map[string]string) *ManagedCluster {
 	return m
 }
 
+// ID returns the ARM resourceId expression for the cluster
+func (m *ManagedCluster) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.ContainerService/managedClusters', '%s')]", m.Name)
+}
+
 // WithKubernetesVersion sets the Kubernetes version
 func (m *ManagedCluster) WithKubernetesVersion(version string) *ManagedCluster {
 	m.Properties.KubernetesVersion = &version
@@ -778,6 +801,26 @@ func (m *ManagedCluster) WithStandardTier() *ManagedCluster {
 	return m
 }
 
+// WithAutoUpgradeChannel sets the Kubernetes upgrade channel (rapid,
+// stable, patch, node-image, none)
+func (m *ManagedCluster) WithAutoUpgradeChannel(channel string) *ManagedCluster {
+	if m.Properties.AutoUpgradeProfile == nil {
+		m.Properties.AutoUpgradeProfile = &ManagedClusterAutoUpgradeProfile{}
+	}
+	m.Properties.AutoUpgradeProfile.UpgradeChannel = &channel
+	return m
+}
+
+// WithNodeOSUpgradeChannel sets the node OS image upgrade channel (None,
+// Unmanaged, SecurityPatch, NodeImage)
+func (m *ManagedCluster) WithNodeOSUpgradeChannel(channel string) *ManagedCluster {
+	if m.Properties.AutoUpgradeProfile == nil {
+		m.Properties.AutoUpgradeProfile = &ManagedClusterAutoUpgradeProfile{}
+	}
+	m.Properties.AutoUpgradeProfile.NodeOSUpgradeChannel = &channel
+	return m
+}
+
 // NewAgentPool creates a new agent pool profile
 func NewAgentPool(name, vmSize string, count int) ManagedClusterAgentPoolProfile {
 	return ManagedClusterAgentPoolProfile{
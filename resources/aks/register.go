@@ -0,0 +1,14 @@
+package aks
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/aks"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "ManagedCluster", "Microsoft.ContainerService/managedClusters")
+	template.RegisterResourceType(importPath, "AgentPool", "Microsoft.ContainerService/managedClusters/agentPools")
+	template.RegisterResourceType(importPath, "MaintenanceConfiguration", "Microsoft.ContainerService/managedClusters/maintenanceConfigurations")
+}
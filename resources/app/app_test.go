@@ -0,0 +1,92 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManagedEnvironment(t *testing.T) {
+	env := NewManagedEnvironment("apps-env", "eastus").
+		WithLogAnalytics("[reference('logs').customerId]", "[listKeys('logs', '2022-10-01').primarySharedKey]").
+		WithVnet("[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'apps')]", true)
+
+	assert.Equal(t, "Microsoft.App/managedEnvironments", env.Type)
+	assert.Equal(t, "2023-05-01", env.APIVersion)
+	assert.Equal(t, "eastus", env.Location)
+	require.NotNil(t, env.Properties.AppLogsConfiguration)
+	assert.Equal(t, "log-analytics", env.Properties.AppLogsConfiguration.Destination)
+	assert.Equal(t, "[reference('logs').customerId]", env.Properties.AppLogsConfiguration.LogAnalyticsConfiguration.CustomerID)
+	require.NotNil(t, env.Properties.VnetConfiguration)
+	assert.True(t, *env.Properties.VnetConfiguration.Internal)
+	assert.Equal(t, "[resourceId('Microsoft.App/managedEnvironments', 'apps-env')]", env.ID())
+}
+
+func TestNewContainerApp(t *testing.T) {
+	env := NewManagedEnvironment("apps-env", "eastus")
+	a := NewContainerApp("api", "eastus", env.ID())
+
+	assert.Equal(t, "Microsoft.App/containerApps", a.Type)
+	assert.Equal(t, "2023-05-01", a.APIVersion)
+	assert.Equal(t, env.ID(), a.Properties.EnvironmentID)
+	assert.Nil(t, a.Properties.Configuration)
+	require.NotNil(t, a.Properties.Template)
+	assert.Empty(t, a.Properties.Template.Containers)
+	assert.Nil(t, a.Properties.Template.Scale)
+	assert.Equal(t, "[resourceId('Microsoft.App/containerApps', 'api')]", a.ID())
+}
+
+func TestContainerApp_Builders(t *testing.T) {
+	a := NewContainerApp("api", "eastus", "[resourceId('Microsoft.App/managedEnvironments', 'apps-env')]").
+		AddContainer("api", "mcr.microsoft.com/k8se/quickstart:latest", 0.5, "1Gi").
+		WithEnvironmentVariable("api", "MODE", "production").
+		WithIngress(true, 8080).
+		WithSecret("db-password", "[parameters('dbPassword')]").
+		WithScale(0, 10).
+		WithHTTPScaleRule("http-load", 50).
+		WithSystemAssignedIdentity()
+
+	require.Len(t, a.Properties.Template.Containers, 1)
+	c := a.Properties.Template.Containers[0]
+	assert.Equal(t, &ContainerResources{CPU: 0.5, Memory: "1Gi"}, c.Resources)
+	require.Len(t, c.Env, 1)
+	assert.Equal(t, "production", *c.Env[0].Value)
+
+	cfg := a.Properties.Configuration
+	require.NotNil(t, cfg)
+	assert.True(t, cfg.Ingress.External)
+	assert.Equal(t, 8080, cfg.Ingress.TargetPort)
+	require.Len(t, cfg.Ingress.Traffic, 1)
+	assert.Equal(t, 100, cfg.Ingress.Traffic[0].Weight)
+	require.Len(t, cfg.Secrets, 1)
+	assert.Equal(t, "db-password", cfg.Secrets[0].Name)
+
+	scale := a.Properties.Template.Scale
+	require.NotNil(t, scale)
+	assert.Equal(t, 0, *scale.MinReplicas)
+	assert.Equal(t, 10, *scale.MaxReplicas)
+	require.Len(t, scale.Rules, 1)
+	assert.Equal(t, map[string]string{"concurrentRequests": "50"}, scale.Rules[0].HTTP.Metadata)
+	assert.Equal(t, "SystemAssigned", a.Identity.Type)
+}
+
+func TestContainerApp_JSONSerialization(t *testing.T) {
+	a := NewContainerApp("api", "eastus", "[resourceId('Microsoft.App/managedEnvironments', 'apps-env')]").
+		AddContainer("api", "nginx", 0.25, "0.5Gi").
+		WithScale(0, 3)
+
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]any)
+	assert.Equal(t, "[resourceId('Microsoft.App/managedEnvironments', 'apps-env')]", props["environmentId"])
+	assert.NotContains(t, props, "configuration")
+	template := props["template"].(map[string]any)
+	assert.Equal(t, map[string]any{"minReplicas": float64(0), "maxReplicas": float64(3)}, template["scale"])
+	assert.NotContains(t, result, "identity")
+}
@@ -0,0 +1,348 @@
+package app
+
+import "fmt"
+
+// ContainerApp represents a Microsoft.App/containerApps resource
+type ContainerApp struct {
+	// Name is the name of the container app (2-32 lowercase letters,
+	// numbers and hyphens)
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the app, the same as its environment's
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Identity defines the managed identity configuration for the app
+	Identity *Identity `json:"identity,omitempty"`
+
+	// Properties contains the properties of the app
+	Properties ContainerAppProperties `json:"properties"`
+}
+
+// ContainerAppProperties represents the properties of a container app
+type ContainerAppProperties struct {
+	// EnvironmentID is the resource ID of the app's environment, such as
+	// environment.ID()
+	EnvironmentID string `json:"environmentId"`
+
+	// Configuration holds the settings that are not versioned with revisions,
+	// such as ingress and secrets
+	Configuration *Configuration `json:"configuration,omitempty"`
+
+	// Template holds the versioned settings; changing it creates a revision
+	Template *Template `json:"template,omitempty"`
+}
+
+// Configuration represents the unversioned settings of a container app
+type Configuration struct {
+	// ActiveRevisionsMode is how many revisions are active (Single, Multiple)
+	ActiveRevisionsMode *string `json:"activeRevisionsMode,omitempty"`
+
+	// Ingress exposes the app over HTTP or TCP
+	Ingress *Ingress `json:"ingress,omitempty"`
+
+	// Secrets are the secrets the app's containers can reference
+	Secrets []Secret `json:"secrets,omitempty"`
+
+	// Registries are the credentials of private container registries
+	Registries []RegistryCredentials `json:"registries,omitempty"`
+}
+
+// Ingress exposes a container app over HTTP or TCP
+type Ingress struct {
+	// External makes the app reachable from outside its environment
+	External bool `json:"external"`
+
+	// TargetPort is the port the app's container listens on
+	TargetPort int `json:"targetPort"`
+
+	// Transport is the ingress protocol (auto, http, http2, tcp)
+	Transport *string `json:"transport,omitempty"`
+
+	// AllowInsecure allows HTTP connections instead of redirecting them to HTTPS
+	AllowInsecure *bool `json:"allowInsecure,omitempty"`
+
+	// Traffic splits requests across revisions
+	Traffic []TrafficWeight `json:"traffic,omitempty"`
+}
+
+// TrafficWeight assigns a share of a container app's traffic to a revision
+type TrafficWeight struct {
+	// LatestRevision assigns the weight to the latest revision
+	LatestRevision *bool `json:"latestRevision,omitempty"`
+
+	// RevisionName assigns the weight to a named revision
+	RevisionName *string `json:"revisionName,omitempty"`
+
+	// Weight is the percentage of traffic
+	Weight int `json:"weight"`
+}
+
+// Secret is a secret of a container app, given as a value or read from Key Vault
+type Secret struct {
+	// Name is the name containers reference the secret by
+	Name string `json:"name"`
+
+	// Value is the secret value, usually a secure parameter
+	Value *string `json:"value,omitempty"`
+
+	// KeyVaultURL is the URL of a Key Vault secret holding the value
+	KeyVaultURL *string `json:"keyVaultUrl,omitempty"`
+
+	// Identity is the managed identity that reads KeyVaultURL (a
+	// user-assigned identity's resource ID, or "system")
+	Identity *string `json:"identity,omitempty"`
+}
+
+// RegistryCredentials are the credentials of a private container registry
+type RegistryCredentials struct {
+	// Server is the registry login server (e.g. myregistry.azurecr.io)
+	Server string `json:"server"`
+
+	// Username is the registry user name
+	Username *string `json:"username,omitempty"`
+
+	// PasswordSecretRef is the name of the secret holding the password
+	PasswordSecretRef *string `json:"passwordSecretRef,omitempty"`
+
+	// Identity is the managed identity that pulls images instead of a password
+	Identity *string `json:"identity,omitempty"`
+}
+
+// Template represents the versioned settings of a container app
+type Template struct {
+	// Containers are the app's containers
+	Containers []Container `json:"containers"`
+
+	// Scale sets the replica bounds and scale rules
+	Scale *Scale `json:"scale,omitempty"`
+
+	// RevisionSuffix is appended to the names of the revisions created
+	RevisionSuffix *string `json:"revisionSuffix,omitempty"`
+}
+
+// Container represents a container of a container app
+type Container struct {
+	// Name is the name of the container
+	Name string `json:"name"`
+
+	// Image is the container image (e.g. mcr.microsoft.com/k8se/quickstart:latest)
+	Image string `json:"image"`
+
+	// Command overrides the image entrypoint
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments of the entrypoint
+	Args []string `json:"args,omitempty"`
+
+	// Env are the environment variables of the container
+	Env []EnvironmentVar `json:"env,omitempty"`
+
+	// Resources are the CPU and memory of the container
+	Resources *ContainerResources `json:"resources,omitempty"`
+}
+
+// EnvironmentVar is an environment variable of a container, given as a value
+// or a reference to a secret
+type EnvironmentVar struct {
+	// Name is the name of the variable
+	Name string `json:"name"`
+
+	// Value is the value of the variable
+	Value *string `json:"value,omitempty"`
+
+	// SecretRef is the name of the secret holding the value
+	SecretRef *string `json:"secretRef,omitempty"`
+}
+
+// ContainerResources are the CPU and memory of a container
+type ContainerResources struct {
+	// CPU is the number of CPU cores (e.g. 0.25, 0.5, 1)
+	CPU float64 `json:"cpu"`
+
+	// Memory is the memory, twice the CPU in Gi (e.g. 0.5Gi, 1Gi, 2Gi)
+	Memory string `json:"memory"`
+}
+
+// Scale sets the replica bounds and scale rules of a container app
+type Scale struct {
+	// MinReplicas is the least number of replicas; 0 scales the app to zero
+	MinReplicas *int `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the most replicas (up to 300)
+	MaxReplicas *int `json:"maxReplicas,omitempty"`
+
+	// Rules are the scale rules; HTTP scaling if none
+	Rules []ScaleRule `json:"rules,omitempty"`
+}
+
+// ScaleRule is a rule that scales a container app; exactly one of HTTP and
+// Custom is set
+type ScaleRule struct {
+	// Name is the name of the rule
+	Name string `json:"name"`
+
+	// HTTP scales on concurrent HTTP requests
+	HTTP *HTTPScaleRule `json:"http,omitempty"`
+
+	// Custom scales with a KEDA scaler, such as azure-servicebus
+	Custom *CustomScaleRule `json:"custom,omitempty"`
+}
+
+// HTTPScaleRule scales a container app on concurrent HTTP requests
+type HTTPScaleRule struct {
+	// Metadata are the rule settings (e.g. concurrentRequests)
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// CustomScaleRule scales a container app with a KEDA scaler
+type CustomScaleRule struct {
+	// Type is the KEDA scaler type (e.g. azure-servicebus, azure-queue)
+	Type string `json:"type"`
+
+	// Metadata are the scaler settings
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Identity represents the identity configuration
+type Identity struct {
+	// Type is the identity type (SystemAssigned, UserAssigned, SystemAssigned,UserAssigned)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// NewContainerApp creates a container app in the environment with the
+// resource ID environmentID, such as environment.ID(), with no containers;
+// add them with AddContainer
+func NewContainerApp(name, location, environmentID string) *ContainerApp {
+	return &ContainerApp{
+		Name:       name,
+		Type:       "Microsoft.App/containerApps",
+		APIVersion: apiVersion,
+		Location:   location,
+		Properties: ContainerAppProperties{
+			EnvironmentID: environmentID,
+			Template: &Template{
+				Containers: []Container{},
+			},
+		},
+	}
+}
+
+// AddContainer adds a container running image with cpu cores and memory
+// (e.g. 0.5 and "1Gi")
+func (a *ContainerApp) AddContainer(name, image string, cpu float64, memory string) *ContainerApp {
+	a.template().Containers = append(a.template().Containers, Container{
+		Name:      name,
+		Image:     image,
+		Resources: &ContainerResources{CPU: cpu, Memory: memory},
+	})
+	return a
+}
+
+// WithEnvironmentVariable sets an environment variable on the named container
+func (a *ContainerApp) WithEnvironmentVariable(containerName, name, value string) *ContainerApp {
+	for i := range a.template().Containers {
+		c := &a.Properties.Template.Containers[i]
+		if c.Name == containerName {
+			c.Env = append(c.Env, EnvironmentVar{Name: name, Value: &value})
+		}
+	}
+	return a
+}
+
+// WithIngress exposes targetPort of the app over HTTP, from outside its
+// environment if external is set, with all traffic on the latest revision
+func (a *ContainerApp) WithIngress(external bool, targetPort int) *ContainerApp {
+	latest := true
+	a.configuration().Ingress = &Ingress{
+		External:   external,
+		TargetPort: targetPort,
+		Traffic:    []TrafficWeight{{LatestRevision: &latest, Weight: 100}},
+	}
+	return a
+}
+
+// WithSecret adds a secret with value, usually a secure parameter expression
+func (a *ContainerApp) WithSecret(name, value string) *ContainerApp {
+	a.configuration().Secrets = append(a.configuration().Secrets, Secret{Name: name, Value: &value})
+	return a
+}
+
+// WithScale bounds the app to between minReplicas and maxReplicas replicas;
+// a minimum of 0 lets the app scale to zero
+func (a *ContainerApp) WithScale(minReplicas, maxReplicas int) *ContainerApp {
+	if a.template().Scale == nil {
+		a.Properties.Template.Scale = &Scale{}
+	}
+	a.Properties.Template.Scale.MinReplicas = &minReplicas
+	a.Properties.Template.Scale.MaxReplicas = &maxReplicas
+	return a
+}
+
+// WithHTTPScaleRule adds a rule adding a replica for every
+// concurrentRequests concurrent HTTP requests
+func (a *ContainerApp) WithHTTPScaleRule(name string, concurrentRequests int) *ContainerApp {
+	if a.template().Scale == nil {
+		a.Properties.Template.Scale = &Scale{}
+	}
+	a.Properties.Template.Scale.Rules = append(a.Properties.Template.Scale.Rules, ScaleRule{
+		Name: name,
+		HTTP: &HTTPScaleRule{
+			Metadata: map[string]string{"concurrentRequests": fmt.Sprint(concurrentRequests)},
+		},
+	})
+	return a
+}
+
+// WithSystemAssignedIdentity enables a system-assigned managed identity
+func (a *ContainerApp) WithSystemAssignedIdentity() *ContainerApp {
+	a.Identity = &Identity{Type: "SystemAssigned"}
+	return a
+}
+
+// WithTags adds tags to the app
+func (a *ContainerApp) WithTags(tags map[string]string) *ContainerApp {
+	a.Tags = tags
+	return a
+}
+
+// ID returns the ARM resourceId expression for the container app
+func (a *ContainerApp) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.App/containerApps', '%s')]", a.Name)
+}
+
+// template returns the app's template, creating it if needed
+func (a *ContainerApp) template() *Template {
+	if a.Properties.Template == nil {
+		a.Properties.Template = &Template{Containers: []Container{}}
+	}
+	return a.Properties.Template
+}
+
+// configuration returns the app's configuration, creating it if needed
+func (a *ContainerApp) configuration() *Configuration {
+	if a.Properties.Configuration == nil {
+		a.Properties.Configuration = &Configuration{}
+	}
+	return a.Properties.Configuration
+}
@@ -0,0 +1,118 @@
+// Package app provides Azure Container Apps resource types
+package app
+
+import "fmt"
+
+// apiVersion is the API version of the Container Apps resources
+const apiVersion = "2023-05-01"
+
+// ManagedEnvironment represents a Microsoft.App/managedEnvironments resource:
+// the network and logging boundary that container apps run in
+type ManagedEnvironment struct {
+	// Name is the name of the environment
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the environment will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the environment
+	Properties ManagedEnvironmentProperties `json:"properties"`
+}
+
+// ManagedEnvironmentProperties represents the properties of a Container Apps
+// environment
+type ManagedEnvironmentProperties struct {
+	// AppLogsConfiguration sets where the apps' console logs are sent
+	AppLogsConfiguration *AppLogsConfiguration `json:"appLogsConfiguration,omitempty"`
+
+	// VnetConfiguration places the environment in a virtual network
+	VnetConfiguration *VnetConfiguration `json:"vnetConfiguration,omitempty"`
+
+	// ZoneRedundant spreads the environment across availability zones; it
+	// requires VnetConfiguration
+	ZoneRedundant *bool `json:"zoneRedundant,omitempty"`
+}
+
+// AppLogsConfiguration sets where the console logs of an environment's apps
+// are sent
+type AppLogsConfiguration struct {
+	// Destination is the logs destination (log-analytics, azure-monitor, none)
+	Destination string `json:"destination"`
+
+	// LogAnalyticsConfiguration is the workspace of the log-analytics destination
+	LogAnalyticsConfiguration *LogAnalyticsConfiguration `json:"logAnalyticsConfiguration,omitempty"`
+}
+
+// LogAnalyticsConfiguration identifies a Log Analytics workspace
+type LogAnalyticsConfiguration struct {
+	// CustomerID is the workspace ID
+	CustomerID string `json:"customerId"`
+
+	// SharedKey is the workspace's shared key, usually a reference() or
+	// listKeys() expression
+	SharedKey string `json:"sharedKey"`
+}
+
+// VnetConfiguration places an environment in a virtual network
+type VnetConfiguration struct {
+	// InfrastructureSubnetID is the resource ID of the subnet of the
+	// environment's infrastructure
+	InfrastructureSubnetID string `json:"infrastructureSubnetId"`
+
+	// Internal makes the environment reachable only from the virtual network
+	Internal *bool `json:"internal,omitempty"`
+}
+
+// NewManagedEnvironment creates a Container Apps environment with no log
+// destination
+func NewManagedEnvironment(name, location string) *ManagedEnvironment {
+	return &ManagedEnvironment{
+		Name:       name,
+		Type:       "Microsoft.App/managedEnvironments",
+		APIVersion: apiVersion,
+		Location:   location,
+	}
+}
+
+// WithLogAnalytics sends the apps' console logs to the Log Analytics
+// workspace with the ID customerID and the shared key sharedKey
+func (e *ManagedEnvironment) WithLogAnalytics(customerID, sharedKey string) *ManagedEnvironment {
+	e.Properties.AppLogsConfiguration = &AppLogsConfiguration{
+		Destination: "log-analytics",
+		LogAnalyticsConfiguration: &LogAnalyticsConfiguration{
+			CustomerID: customerID,
+			SharedKey:  sharedKey,
+		},
+	}
+	return e
+}
+
+// WithVnet places the environment in the subnet with the resource ID
+// subnetID; an internal environment is only reachable from the network
+func (e *ManagedEnvironment) WithVnet(subnetID string, internal bool) *ManagedEnvironment {
+	e.Properties.VnetConfiguration = &VnetConfiguration{
+		InfrastructureSubnetID: subnetID,
+		Internal:               &internal,
+	}
+	return e
+}
+
+// WithTags adds tags to the environment
+func (e *ManagedEnvironment) WithTags(tags map[string]string) *ManagedEnvironment {
+	e.Tags = tags
+	return e
+}
+
+// ID returns the ARM resourceId expression for the environment
+func (e *ManagedEnvironment) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.App/managedEnvironments', '%s')]", e.Name)
+}
@@ -0,0 +1,13 @@
+package app
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/app"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "ManagedEnvironment", "Microsoft.App/managedEnvironments")
+	template.RegisterResourceType(importPath, "ContainerApp", "Microsoft.App/containerApps")
+}
@@ -0,0 +1,96 @@
+// Package authorization provides Azure authorization resource types
+package authorization
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lockAPIVersion is the API version of management locks
+const lockAPIVersion = "2020-05-01"
+
+// Lock levels
+const (
+	// LockLevelCanNotDelete lets authorized users read and modify the locked
+	// resource, but not delete it
+	LockLevelCanNotDelete = "CanNotDelete"
+
+	// LockLevelReadOnly lets authorized users read the locked resource, but
+	// not modify or delete it
+	LockLevelReadOnly = "ReadOnly"
+)
+
+// ManagementLock represents a Microsoft.Authorization/locks resource: a lock
+// that prevents deleting or changing a resource, or every resource in the
+// resource group it is deployed to
+type ManagementLock struct {
+	// Name is the name of the lock
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Scope is the resource ID of the resource the lock applies to, such as
+	// storageAccount.ID(); the lock applies to the resource group if empty
+	Scope string `json:"scope,omitempty"`
+
+	// Properties contains the properties of the lock
+	Properties ManagementLockProperties `json:"properties"`
+}
+
+// ManagementLockProperties represents the properties of a management lock
+type ManagementLockProperties struct {
+	// Level is the lock level (CanNotDelete, ReadOnly)
+	Level string `json:"level"`
+
+	// Notes explain why the lock was added (up to 512 characters)
+	Notes string `json:"notes,omitempty"`
+
+	// Owners are the principals that own the lock
+	Owners []ManagementLockOwner `json:"owners,omitempty"`
+}
+
+// ManagementLockOwner identifies an owner of a management lock
+type ManagementLockOwner struct {
+	// ApplicationID is the application ID of the owner
+	ApplicationID string `json:"applicationId"`
+}
+
+// NewManagementLock creates a lock at level (LockLevelCanNotDelete or
+// LockLevelReadOnly) on the resource with the ID targetResourceID, such as
+// storageAccount.ID(), or on the resource group if targetResourceID is empty
+func NewManagementLock(name, targetResourceID, level string) *ManagementLock {
+	return &ManagementLock{
+		Name:       name,
+		Type:       "Microsoft.Authorization/locks",
+		APIVersion: lockAPIVersion,
+		Scope:      targetResourceID,
+		Properties: ManagementLockProperties{
+			Level: level,
+		},
+	}
+}
+
+// WithNotes sets the notes explaining the lock
+func (l *ManagementLock) WithNotes(notes string) *ManagementLock {
+	l.Properties.Notes = notes
+	return l
+}
+
+// ID returns the ARM expression for the resource ID of the lock: an
+// extensionResourceId of its target resource, or a resourceId in the
+// resource group if it has no scope
+func (l *ManagementLock) ID() string {
+	if l.Scope == "" {
+		return fmt.Sprintf("[resourceId('Microsoft.Authorization/locks', '%s')]", l.Name)
+	}
+	scope := fmt.Sprintf("'%s'", l.Scope)
+	if strings.HasPrefix(l.Scope, "[") && strings.HasSuffix(l.Scope, "]") {
+		// Nest the scope's expression rather than quoting it
+		scope = l.Scope[1 : len(l.Scope)-1]
+	}
+	return fmt.Sprintf("[extensionResourceId(%s, 'Microsoft.Authorization/locks', '%s')]", scope, l.Name)
+}
@@ -0,0 +1,61 @@
+package authorization
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManagementLock(t *testing.T) {
+	lock := NewManagementLock("logs-lock", "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]", LockLevelCanNotDelete).
+		WithNotes("Holds audit logs")
+
+	assert.Equal(t, "logs-lock", lock.Name)
+	assert.Equal(t, "Microsoft.Authorization/locks", lock.Type)
+	assert.Equal(t, "2020-05-01", lock.APIVersion)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]", lock.Scope)
+	assert.Equal(t, "CanNotDelete", lock.Properties.Level)
+	assert.Equal(t, "Holds audit logs", lock.Properties.Notes)
+
+	data, err := json.Marshal(lock)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "logs-lock",
+		"type": "Microsoft.Authorization/locks",
+		"apiVersion": "2020-05-01",
+		"scope": "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]",
+		"properties": {"level": "CanNotDelete", "notes": "Holds audit logs"}
+	}`, string(data))
+}
+
+func TestManagementLock_ID(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope string
+		want  string
+	}{
+		{
+			name: "resource group",
+			want: "[resourceId('Microsoft.Authorization/locks', 'rg-lock')]",
+		},
+		{
+			name:  "expression scope",
+			scope: "[resourceId('Microsoft.Storage/storageAccounts', 'logs')]",
+			want:  "[extensionResourceId(resourceId('Microsoft.Storage/storageAccounts', 'logs'), 'Microsoft.Authorization/locks', 'rg-lock')]",
+		},
+		{
+			name:  "literal scope",
+			scope: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/logs",
+			want:  "[extensionResourceId('/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/logs', 'Microsoft.Authorization/locks', 'rg-lock')]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock := NewManagementLock("rg-lock", tt.scope, LockLevelReadOnly)
+			assert.Equal(t, tt.want, lock.ID())
+		})
+	}
+}
@@ -0,0 +1,265 @@
+// Package cognitiveservices provides Azure Cognitive Services and Azure OpenAI resource types
+package cognitiveservices
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Account represents a Microsoft.CognitiveServices/accounts resource, such as
+// an Azure OpenAI account
+type Account struct {
+	// Name is the name of the account
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the account will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Kind is the kind of service (OpenAI, CognitiveServices, TextAnalytics, FormRecognizer, ...)
+	Kind string `json:"kind"`
+
+	// SKU defines the pricing tier of the account (e.g. S0, F0)
+	SKU SKU `json:"sku"`
+
+	// Identity defines the managed identity configuration for the account
+	Identity *Identity `json:"identity,omitempty"`
+
+	// Properties contains the properties of the account
+	Properties AccountProperties `json:"properties"`
+}
+
+// SKU represents the SKU of a Cognitive Services account
+type SKU struct {
+	// Name is the SKU name (e.g. S0, F0)
+	Name string `json:"name"`
+}
+
+// AccountProperties represents the properties of a Cognitive Services account
+type AccountProperties struct {
+	// CustomSubDomainName is the subdomain of the account endpoint
+	// (https://<name>.openai.azure.com); required for Microsoft Entra ID
+	// authentication and private endpoints
+	CustomSubDomainName *string `json:"customSubDomainName,omitempty"`
+
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+
+	// NetworkAcls restricts the networks that can reach the account
+	NetworkAcls *NetworkRuleSet `json:"networkAcls,omitempty"`
+
+	// DisableLocalAuth disables API key authentication, leaving Microsoft Entra ID
+	DisableLocalAuth *bool `json:"disableLocalAuth,omitempty"`
+}
+
+// NetworkRuleSet represents the network rules of a Cognitive Services account
+type NetworkRuleSet struct {
+	// DefaultAction is the action for traffic matching no rule (Allow, Deny)
+	DefaultAction string `json:"defaultAction"`
+
+	// IPRules allow traffic from IP addresses or CIDR ranges
+	IPRules []IPRule `json:"ipRules,omitempty"`
+
+	// VirtualNetworkRules allow traffic from virtual network subnets
+	VirtualNetworkRules []VirtualNetworkRule `json:"virtualNetworkRules,omitempty"`
+}
+
+// IPRule represents an IP address or CIDR range allowed to reach the account
+type IPRule struct {
+	// Value is the IP address or CIDR range
+	Value string `json:"value"`
+}
+
+// VirtualNetworkRule represents a subnet allowed to reach the account
+type VirtualNetworkRule struct {
+	// ID is the resource ID of the subnet
+	ID string `json:"id"`
+
+	// IgnoreMissingVnetServiceEndpoint allows the rule before the subnet has
+	// the Microsoft.CognitiveServices service endpoint
+	IgnoreMissingVnetServiceEndpoint *bool `json:"ignoreMissingVnetServiceEndpoint,omitempty"`
+}
+
+// Identity represents the identity configuration
+type Identity struct {
+	// Type is the identity type (SystemAssigned, UserAssigned, SystemAssigned,UserAssigned)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// Deployment represents a Microsoft.CognitiveServices/accounts/deployments
+// resource: a model deployed to an account, such as gpt-4o on an Azure OpenAI account
+type Deployment struct {
+	// Name is the name of the deployment, in the form "<account>/<deployment>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// SKU defines the deployment type and capacity
+	SKU *DeploymentSKU `json:"sku,omitempty"`
+
+	// Properties contains the properties of the deployment
+	Properties DeploymentProperties `json:"properties"`
+}
+
+// DeploymentSKU represents the SKU of a model deployment
+type DeploymentSKU struct {
+	// Name is the deployment type (Standard, GlobalStandard, ProvisionedManaged)
+	Name string `json:"name"`
+
+	// Capacity is the quota in thousands of tokens per minute, or provisioned
+	// throughput units for ProvisionedManaged
+	Capacity int `json:"capacity,omitempty"`
+}
+
+// DeploymentProperties represents the properties of a model deployment
+type DeploymentProperties struct {
+	// Model is the model to deploy
+	Model DeploymentModel `json:"model"`
+
+	// RaiPolicyName is the name of the content filtering policy
+	RaiPolicyName *string `json:"raiPolicyName,omitempty"`
+
+	// VersionUpgradeOption controls automatic model version upgrades
+	// (OnceNewDefaultVersionAvailable, OnceCurrentVersionExpired, NoAutoUpgrade)
+	VersionUpgradeOption *string `json:"versionUpgradeOption,omitempty"`
+}
+
+// DeploymentModel identifies a model
+type DeploymentModel struct {
+	// Format is the model format (e.g. OpenAI)
+	Format string `json:"format"`
+
+	// Name is the model name (e.g. gpt-4o)
+	Name string `json:"name"`
+
+	// Version is the model version (e.g. 2024-08-06)
+	Version string `json:"version,omitempty"`
+}
+
+// NewAccount creates a new Cognitive Services account of the given kind and SKU
+func NewAccount(name, location, kind, skuName string) *Account {
+	return &Account{
+		Name:       name,
+		Type:       "Microsoft.CognitiveServices/accounts",
+		APIVersion: "2023-05-01",
+		Location:   location,
+		Kind:       kind,
+		SKU: SKU{
+			Name: skuName,
+		},
+	}
+}
+
+// NewOpenAIAccount creates a new Azure OpenAI account on the S0 SKU, using
+// name as its custom subdomain
+func NewOpenAIAccount(name, location string) *Account {
+	return NewAccount(name, location, "OpenAI", "S0").WithCustomSubDomainName(name)
+}
+
+// ID returns the ARM resourceId expression for the account
+func (a *Account) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.CognitiveServices/accounts', '%s')]", a.Name)
+}
+
+// Endpoint returns an ARM expression for the endpoint URL of the account
+func (a *Account) Endpoint() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.CognitiveServices/accounts', '%s'), '2023-05-01').endpoint]", a.Name)
+}
+
+// WithTags adds tags to the account
+func (a *Account) WithTags(tags map[string]string) *Account {
+	a.Tags = tags
+	return a
+}
+
+// WithSystemAssignedIdentity enables a system-assigned managed identity
+func (a *Account) WithSystemAssignedIdentity() *Account {
+	a.Identity = &Identity{Type: "SystemAssigned"}
+	return a
+}
+
+// WithCustomSubDomainName sets the subdomain of the account endpoint
+func (a *Account) WithCustomSubDomainName(subdomain string) *Account {
+	a.Properties.CustomSubDomainName = &subdomain
+	return a
+}
+
+// WithPublicNetworkAccess sets public network access (Enabled or Disabled)
+func (a *Account) WithPublicNetworkAccess(access string) *Account {
+	a.Properties.PublicNetworkAccess = &access
+	return a
+}
+
+// WithNetworkAcls denies traffic by default and allows only the given IP
+// addresses or CIDR ranges
+func (a *Account) WithNetworkAcls(allowedIPs ...string) *Account {
+	acls := &NetworkRuleSet{DefaultAction: "Deny"}
+	for _, ip := range allowedIPs {
+		acls.IPRules = append(acls.IPRules, IPRule{Value: ip})
+	}
+	a.Properties.NetworkAcls = acls
+	return a
+}
+
+// NewDeployment creates a deployment of an OpenAI model under the named
+// account, on the Standard SKU with capacity thousand tokens per minute
+func NewDeployment(accountName, name, modelName, modelVersion string, capacity int) *Deployment {
+	return &Deployment{
+		Name:       accountName + "/" + name,
+		Type:       "Microsoft.CognitiveServices/accounts/deployments",
+		APIVersion: "2023-05-01",
+		SKU: &DeploymentSKU{
+			Name:     "Standard",
+			Capacity: capacity,
+		},
+		Properties: DeploymentProperties{
+			Model: DeploymentModel{
+				Format:  "OpenAI",
+				Name:    modelName,
+				Version: modelVersion,
+			},
+		},
+	}
+}
+
+// ID returns the ARM resourceId expression for the deployment
+func (d *Deployment) ID() string {
+	account, deployment, _ := strings.Cut(d.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.CognitiveServices/accounts/deployments', '%s', '%s')]", account, deployment)
+}
+
+// WithSKU sets the deployment type (e.g. GlobalStandard) and capacity
+func (d *Deployment) WithSKU(name string, capacity int) *Deployment {
+	d.SKU = &DeploymentSKU{Name: name, Capacity: capacity}
+	return d
+}
+
+// WithRaiPolicy sets the content filtering policy of the deployment
+func (d *Deployment) WithRaiPolicy(name string) *Deployment {
+	d.Properties.RaiPolicyName = &name
+	return d
+}
@@ -0,0 +1,94 @@
+package cognitiveservices
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccount(t *testing.T) {
+	a := NewAccount("my-language", "eastus", "TextAnalytics", "S")
+
+	assert.Equal(t, "my-language", a.Name)
+	assert.Equal(t, "Microsoft.CognitiveServices/accounts", a.Type)
+	assert.Equal(t, "2023-05-01", a.APIVersion)
+	assert.Equal(t, "eastus", a.Location)
+	assert.Equal(t, "TextAnalytics", a.Kind)
+	assert.Equal(t, "S", a.SKU.Name)
+	assert.Nil(t, a.Identity)
+	assert.Nil(t, a.Properties.CustomSubDomainName)
+	assert.Equal(t, "[resourceId('Microsoft.CognitiveServices/accounts', 'my-language')]", a.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.CognitiveServices/accounts', 'my-language'), '2023-05-01').endpoint]", a.Endpoint())
+}
+
+func TestNewOpenAIAccount(t *testing.T) {
+	a := NewOpenAIAccount("my-openai", "eastus").
+		WithTags(map[string]string{"env": "prod"}).
+		WithSystemAssignedIdentity().
+		WithPublicNetworkAccess("Disabled").
+		WithNetworkAcls("203.0.113.0/24")
+
+	assert.Equal(t, "OpenAI", a.Kind)
+	assert.Equal(t, "S0", a.SKU.Name)
+	require.NotNil(t, a.Properties.CustomSubDomainName)
+	assert.Equal(t, "my-openai", *a.Properties.CustomSubDomainName)
+	assert.Equal(t, "prod", a.Tags["env"])
+	require.NotNil(t, a.Identity)
+	assert.Equal(t, "SystemAssigned", a.Identity.Type)
+	assert.Equal(t, "Disabled", *a.Properties.PublicNetworkAccess)
+	require.NotNil(t, a.Properties.NetworkAcls)
+	assert.Equal(t, "Deny", a.Properties.NetworkAcls.DefaultAction)
+	assert.Equal(t, []IPRule{{Value: "203.0.113.0/24"}}, a.Properties.NetworkAcls.IPRules)
+}
+
+func TestAccount_JSON(t *testing.T) {
+	a := NewOpenAIAccount("my-openai", "eastus")
+
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "OpenAI", result["kind"])
+	assert.Equal(t, map[string]interface{}{"name": "S0"}, result["sku"])
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "my-openai", props["customSubDomainName"])
+	assert.NotContains(t, props, "networkAcls")
+	assert.NotContains(t, result, "identity")
+}
+
+func TestNewDeployment(t *testing.T) {
+	d := NewDeployment("my-openai", "chat", "gpt-4o", "2024-08-06", 30).
+		WithRaiPolicy("Microsoft.DefaultV2")
+
+	assert.Equal(t, "my-openai/chat", d.Name)
+	assert.Equal(t, "Microsoft.CognitiveServices/accounts/deployments", d.Type)
+	assert.Equal(t, "2023-05-01", d.APIVersion)
+	require.NotNil(t, d.SKU)
+	assert.Equal(t, DeploymentSKU{Name: "Standard", Capacity: 30}, *d.SKU)
+	assert.Equal(t, DeploymentModel{Format: "OpenAI", Name: "gpt-4o", Version: "2024-08-06"}, d.Properties.Model)
+	assert.Equal(t, "Microsoft.DefaultV2", *d.Properties.RaiPolicyName)
+	assert.Equal(t, "[resourceId('Microsoft.CognitiveServices/accounts/deployments', 'my-openai', 'chat')]", d.ID())
+
+	d.WithSKU("GlobalStandard", 50)
+	assert.Equal(t, DeploymentSKU{Name: "GlobalStandard", Capacity: 50}, *d.SKU)
+}
+
+func TestDeployment_JSON(t *testing.T) {
+	d := NewDeployment("my-openai", "chat", "gpt-4o", "2024-08-06", 30)
+
+	data, err := json.Marshal(d)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "my-openai/chat", result["name"])
+	assert.NotContains(t, result, "location")
+	assert.Equal(t, map[string]interface{}{"name": "Standard", "capacity": float64(30)}, result["sku"])
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"format": "OpenAI", "name": "gpt-4o", "version": "2024-08-06"}, props["model"])
+}
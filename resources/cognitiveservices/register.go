@@ -0,0 +1,13 @@
+package cognitiveservices
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/cognitiveservices"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "Account", "Microsoft.CognitiveServices/accounts")
+	template.RegisterResourceType(importPath, "Deployment", "Microsoft.CognitiveServices/accounts/deployments")
+}
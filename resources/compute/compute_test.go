@@ -296,3 +296,63 @@ func TestDataDisk(t *testing.T) {
 	assert.Equal(t, float64(0), result["lun"])
 	assert.Equal(t, "Empty", result["createOption"])
 }
+
+func TestOSProfile_WithSSHPublicKey(t *testing.T) {
+	computerName := "my-vm"
+	adminPass := "P@ssw0rd123!"
+	profile := &OSProfile{
+		ComputerName:  &computerName,
+		AdminPassword: &adminPass,
+	}
+
+	profile.WithSSHPublicKey("azureuser", "ssh-ed25519 AAAAC3...")
+
+	assert.Nil(t, profile.AdminPassword)
+	require.NotNil(t, profile.AdminUsername)
+	assert.Equal(t, "azureuser", *profile.AdminUsername)
+	require.NotNil(t, profile.LinuxConfiguration)
+	require.NotNil(t, profile.LinuxConfiguration.DisablePasswordAuthentication)
+	assert.True(t, *profile.LinuxConfiguration.DisablePasswordAuthentication)
+	require.NotNil(t, profile.LinuxConfiguration.SSH)
+	require.Len(t, profile.LinuxConfiguration.SSH.PublicKeys, 1)
+	key := profile.LinuxConfiguration.SSH.PublicKeys[0]
+	assert.Equal(t, "/home/azureuser/.ssh/authorized_keys", *key.Path)
+	assert.Equal(t, "ssh-ed25519 AAAAC3...", *key.KeyData)
+
+	data, err := json.Marshal(profile)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.NotContains(t, result, "adminPassword")
+	linux := result["linuxConfiguration"].(map[string]interface{})
+	assert.Equal(t, true, linux["disablePasswordAuthentication"])
+}
+
+func TestOSProfile_WithSSHPublicKey_Multiple(t *testing.T) {
+	provisionAgent := true
+	profile := &OSProfile{
+		LinuxConfiguration: &LinuxConfiguration{ProvisionVMAgent: &provisionAgent},
+	}
+
+	profile.
+		WithSSHPublicKey("azureuser", "ssh-rsa AAAAB...").
+		WithSSHPublicKey("azureuser", "ssh-ed25519 AAAAC3...")
+
+	assert.True(t, *profile.LinuxConfiguration.ProvisionVMAgent)
+	assert.Len(t, profile.LinuxConfiguration.SSH.PublicKeys, 2)
+}
+
+func TestVirtualMachine_EnableBackup(t *testing.T) {
+	vm := (&VirtualMachine{Name: "web-vm", Location: "eastus"}).EnableBackup("backup-vault", "DefaultPolicy")
+
+	require.NotNil(t, vm.Backup)
+	assert.Equal(t, "backup-vault", vm.Backup.VaultName)
+	assert.Equal(t, "DefaultPolicy", vm.Backup.PolicyName)
+
+	// Backup belongs to a separate resource and is not serialized with the VM
+	data, err := json.Marshal(vm)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "backup-vault")
+}
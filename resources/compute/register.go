@@ -0,0 +1,13 @@
+package compute
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/compute"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "VirtualMachine", "Microsoft.Compute/virtualMachines")
+	template.RegisterResourceType(importPath, "SSHPublicKeyResource", "Microsoft.Compute/sshPublicKeys")
+}
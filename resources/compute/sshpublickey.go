@@ -0,0 +1,62 @@
+package compute
+
+import "fmt"
+
+// SSHPublicKeyResource represents a Microsoft.Compute/sshPublicKeys resource:
+// an SSH public key stored once and shared by the VMs that reference it with
+// VirtualMachine.WithSSHKeyResource. It is distinct from SSHPublicKey, the key
+// entry embedded in a VM's Linux configuration.
+type SSHPublicKeyResource struct {
+	// Name is the name of the SSH public key resource
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the resource will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the SSH public key
+	Properties SSHPublicKeyResourceProperties `json:"properties"`
+}
+
+// SSHPublicKeyResourceProperties represents the properties of an SSH public key resource
+type SSHPublicKeyResourceProperties struct {
+	// PublicKey is the SSH public key in ssh-rsa format
+	PublicKey string `json:"publicKey,omitempty"`
+}
+
+// NewSSHPublicKeyResource creates a new SSH public key resource holding publicKey
+func NewSSHPublicKeyResource(name, location, publicKey string) *SSHPublicKeyResource {
+	return &SSHPublicKeyResource{
+		Name:       name,
+		Type:       "Microsoft.Compute/sshPublicKeys",
+		APIVersion: "2023-03-01",
+		Location:   location,
+		Properties: SSHPublicKeyResourceProperties{
+			PublicKey: publicKey,
+		},
+	}
+}
+
+// ID returns the ARM resourceId expression for the SSH public key resource
+func (k *SSHPublicKeyResource) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Compute/sshPublicKeys', '%s')]", k.Name)
+}
+
+// PublicKey returns an ARM expression for the public key stored in the resource
+func (k *SSHPublicKeyResource) PublicKey() string {
+	return sshPublicKeyReference(k.Name)
+}
+
+// sshPublicKeyReference returns an ARM expression for the public key stored
+// in the Microsoft.Compute/sshPublicKeys resource name
+func sshPublicKeyReference(name string) string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.Compute/sshPublicKeys', '%s'), '2023-03-01').publicKey]", name)
+}
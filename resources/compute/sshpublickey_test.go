@@ -0,0 +1,59 @@
+package compute
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSSHPublicKeyResource(t *testing.T) {
+	key := NewSSHPublicKeyResource("deploy-key", "eastus", "ssh-ed25519 AAAAC3...")
+
+	assert.Equal(t, "deploy-key", key.Name)
+	assert.Equal(t, "Microsoft.Compute/sshPublicKeys", key.Type)
+	assert.Equal(t, "2023-03-01", key.APIVersion)
+	assert.Equal(t, "eastus", key.Location)
+	assert.Equal(t, "ssh-ed25519 AAAAC3...", key.Properties.PublicKey)
+	assert.Equal(t, "[resourceId('Microsoft.Compute/sshPublicKeys', 'deploy-key')]", key.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.Compute/sshPublicKeys', 'deploy-key'), '2023-03-01').publicKey]", key.PublicKey())
+}
+
+func TestSSHPublicKeyResource_JSON(t *testing.T) {
+	key := NewSSHPublicKeyResource("deploy-key", "eastus", "ssh-ed25519 AAAAC3...")
+
+	data, err := json.Marshal(key)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "Microsoft.Compute/sshPublicKeys", result["type"])
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "ssh-ed25519 AAAAC3...", props["publicKey"])
+}
+
+func TestVirtualMachine_WithSSHKeyResource(t *testing.T) {
+	admin := "ops"
+	vm := NewVirtualMachine("my-vm", "eastus", "Standard_B2s")
+	vm.Properties.OSProfile = &OSProfile{AdminUsername: &admin}
+
+	vm.WithSSHKeyResource("deploy-key")
+
+	linux := vm.Properties.OSProfile.LinuxConfiguration
+	require.NotNil(t, linux)
+	assert.True(t, *linux.DisablePasswordAuthentication)
+	require.Len(t, linux.SSH.PublicKeys, 1)
+	key := linux.SSH.PublicKeys[0]
+	assert.Equal(t, "/home/ops/.ssh/authorized_keys", *key.Path)
+	assert.Equal(t, "[reference(resourceId('Microsoft.Compute/sshPublicKeys', 'deploy-key'), '2023-03-01').publicKey]", *key.KeyData)
+}
+
+func TestVirtualMachine_WithSSHKeyResource_DefaultUser(t *testing.T) {
+	vm := NewVirtualMachine("my-vm", "eastus", "Standard_B2s").WithSSHKeyResource("deploy-key")
+
+	require.NotNil(t, vm.Properties.OSProfile)
+	assert.Equal(t, "azureuser", *vm.Properties.OSProfile.AdminUsername)
+	assert.Equal(t, "/home/azureuser/.ssh/authorized_keys", *vm.Properties.OSProfile.LinuxConfiguration.SSH.PublicKeys[0].Path)
+}
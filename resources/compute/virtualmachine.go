@@ -29,6 +29,19 @@ type VirtualMachine struct {
 
 	// Plan defines the marketplace image plan
 	Plan *Plan `json:"plan,omitempty"`
+
+	// Backup enables Azure Backup protection for the virtual machine. It is not
+	// part of the VM resource; discovery expands it into a protected item.
+	Backup *Backup `json:"-"`
+}
+
+// Backup identifies the Recovery Services vault and backup policy that protect a VM
+type Backup struct {
+	// VaultName is the name of the Recovery Services vault
+	VaultName string
+
+	// PolicyName is the name of the backup policy in the vault (e.g. DefaultPolicy)
+	PolicyName string
 }
 
 // VirtualMachineProperties represents the properties of a virtual machine
@@ -376,3 +389,52 @@ func (vm *VirtualMachine) WithNetworkInterface(id string, primary bool) *Virtual
 	)
 	return vm
 }
+
+// EnableBackup protects the virtual machine with the backup policy policyName
+// in the Recovery Services vault vaultName. When the VM is discovered, build
+// emits the matching protected item resource alongside it.
+func (vm *VirtualMachine) EnableBackup(vaultName, policyName string) *VirtualMachine {
+	vm.Backup = &Backup{VaultName: vaultName, PolicyName: policyName}
+	return vm
+}
+
+// WithSSHKeyResource configures SSH key authentication with the public key
+// stored in the Microsoft.Compute/sshPublicKeys resource name, for
+// OSProfile.AdminUsername ("azureuser" if unset). Like
+// OSProfile.WithSSHPublicKey, it disables password authentication, so it
+// applies to Linux VMs only. Pass the key's Name field, e.g.
+// WithSSHKeyResource(DeployKey.Name), so the VM depends on the key resource.
+func (vm *VirtualMachine) WithSSHKeyResource(name string) *VirtualMachine {
+	if vm.Properties.OSProfile == nil {
+		vm.Properties.OSProfile = &OSProfile{}
+	}
+	username := "azureuser"
+	if vm.Properties.OSProfile.AdminUsername != nil {
+		username = *vm.Properties.OSProfile.AdminUsername
+	}
+	vm.Properties.OSProfile.WithSSHPublicKey(username, sshPublicKeyReference(name))
+	return vm
+}
+
+// WithSSHPublicKey configures SSH key authentication for username, placing
+// keyData in the user's authorized_keys. It disables password authentication
+// and clears AdminPassword, so it applies to Linux VMs only.
+func (p *OSProfile) WithSSHPublicKey(username, keyData string) *OSProfile {
+	path := "/home/" + username + "/.ssh/authorized_keys"
+	disable := true
+
+	p.AdminUsername = &username
+	p.AdminPassword = nil
+	if p.LinuxConfiguration == nil {
+		p.LinuxConfiguration = &LinuxConfiguration{}
+	}
+	p.LinuxConfiguration.DisablePasswordAuthentication = &disable
+	if p.LinuxConfiguration.SSH == nil {
+		p.LinuxConfiguration.SSH = &SSHConfiguration{}
+	}
+	p.LinuxConfiguration.SSH.PublicKeys = append(p.LinuxConfiguration.SSH.PublicKeys, SSHPublicKey{
+		Path:    &path,
+		KeyData: &keyData,
+	})
+	return p
+}
@@ -0,0 +1,227 @@
+// Package containerinstance provides Azure Container Instances resource types
+package containerinstance
+
+import "fmt"
+
+// ContainerGroup represents a Microsoft.ContainerInstance/containerGroups resource
+type ContainerGroup struct {
+	// Name is the name of the container group
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the container group will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Identity defines the managed identity configuration for the container group
+	Identity *Identity `json:"identity,omitempty"`
+
+	// Properties contains the properties of the container group
+	Properties ContainerGroupProperties `json:"properties"`
+}
+
+// ContainerGroupProperties represents the properties of a container group
+type ContainerGroupProperties struct {
+	// Containers are the containers in the group
+	Containers []Container `json:"containers"`
+
+	// OSType is the operating system of the containers (Linux, Windows)
+	OSType string `json:"osType"`
+
+	// RestartPolicy controls container restarts (Always, OnFailure, Never)
+	RestartPolicy *string `json:"restartPolicy,omitempty"`
+
+	// IPAddress exposes the group's ports on a public or private IP address
+	IPAddress *IPAddress `json:"ipAddress,omitempty"`
+}
+
+// Container represents a container in a container group
+type Container struct {
+	// Name is the name of the container
+	Name string `json:"name"`
+
+	// Properties contains the properties of the container
+	Properties ContainerProperties `json:"properties"`
+}
+
+// ContainerProperties represents the properties of a container
+type ContainerProperties struct {
+	// Image is the container image (e.g. mcr.microsoft.com/azuredocs/aci-helloworld)
+	Image string `json:"image"`
+
+	// Command overrides the image entrypoint
+	Command []string `json:"command,omitempty"`
+
+	// Ports are the ports the container exposes
+	Ports []ContainerPort `json:"ports,omitempty"`
+
+	// EnvironmentVariables are set in the container
+	EnvironmentVariables []EnvironmentVariable `json:"environmentVariables,omitempty"`
+
+	// Resources are the CPU and memory requirements of the container
+	Resources ResourceRequirements `json:"resources"`
+}
+
+// ContainerPort represents a port exposed by a container
+type ContainerPort struct {
+	// Port is the port number
+	Port int `json:"port"`
+
+	// Protocol is the port protocol (TCP, UDP)
+	Protocol *string `json:"protocol,omitempty"`
+}
+
+// EnvironmentVariable represents an environment variable of a container
+type EnvironmentVariable struct {
+	// Name is the name of the variable
+	Name string `json:"name"`
+
+	// Value is the value of the variable
+	Value *string `json:"value,omitempty"`
+
+	// SecureValue is a value that is not shown in the container's properties
+	SecureValue *string `json:"secureValue,omitempty"`
+}
+
+// ResourceRequirements represents the resource requirements of a container
+type ResourceRequirements struct {
+	// Requests are the resources guaranteed to the container
+	Requests ResourceRequests `json:"requests"`
+}
+
+// ResourceRequests represents requested CPU cores and memory
+type ResourceRequests struct {
+	// CPU is the number of CPU cores (e.g. 1 or 0.5)
+	CPU float64 `json:"cpu"`
+
+	// MemoryInGB is the memory in gigabytes (e.g. 1.5)
+	MemoryInGB float64 `json:"memoryInGB"`
+}
+
+// IPAddress represents the IP address of a container group
+type IPAddress struct {
+	// Type is the address type (Public, Private)
+	Type string `json:"type"`
+
+	// Ports are the ports exposed on the address
+	Ports []Port `json:"ports"`
+
+	// DNSNameLabel is the DNS name label of a public address
+	DNSNameLabel *string `json:"dnsNameLabel,omitempty"`
+}
+
+// Port represents a port exposed on the IP address of a container group
+type Port struct {
+	// Port is the port number
+	Port int `json:"port"`
+
+	// Protocol is the port protocol (TCP, UDP)
+	Protocol *string `json:"protocol,omitempty"`
+}
+
+// Identity represents the identity configuration
+type Identity struct {
+	// Type is the identity type (SystemAssigned, UserAssigned, SystemAssigned,UserAssigned)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// NewContainerGroup creates a new container group with required fields and no
+// containers; add them with AddContainer
+func NewContainerGroup(name, location, osType string) *ContainerGroup {
+	return &ContainerGroup{
+		Name:       name,
+		Type:       "Microsoft.ContainerInstance/containerGroups",
+		APIVersion: "2023-05-01",
+		Location:   location,
+		Properties: ContainerGroupProperties{
+			Containers: []Container{},
+			OSType:     osType,
+		},
+	}
+}
+
+// AddContainer adds a container running image with the requested CPU cores
+// and memory, exposing ports over TCP
+func (g *ContainerGroup) AddContainer(name, image string, cpu, memoryInGB float64, ports ...int) *ContainerGroup {
+	container := Container{
+		Name: name,
+		Properties: ContainerProperties{
+			Image: image,
+			Resources: ResourceRequirements{
+				Requests: ResourceRequests{CPU: cpu, MemoryInGB: memoryInGB},
+			},
+		},
+	}
+	for _, port := range ports {
+		container.Properties.Ports = append(container.Properties.Ports, ContainerPort{Port: port})
+	}
+	g.Properties.Containers = append(g.Properties.Containers, container)
+	return g
+}
+
+// WithEnvironmentVariable sets an environment variable on the named container
+func (g *ContainerGroup) WithEnvironmentVariable(containerName, name, value string) *ContainerGroup {
+	for i := range g.Properties.Containers {
+		c := &g.Properties.Containers[i]
+		if c.Name == containerName {
+			c.Properties.EnvironmentVariables = append(c.Properties.EnvironmentVariables,
+				EnvironmentVariable{Name: name, Value: &value})
+		}
+	}
+	return g
+}
+
+// WithPublicIP exposes ports on a public IP address with an optional DNS name label
+func (g *ContainerGroup) WithPublicIP(dnsNameLabel string, ports ...int) *ContainerGroup {
+	ip := &IPAddress{Type: "Public", Ports: []Port{}}
+	if dnsNameLabel != "" {
+		ip.DNSNameLabel = &dnsNameLabel
+	}
+	for _, port := range ports {
+		ip.Ports = append(ip.Ports, Port{Port: port})
+	}
+	g.Properties.IPAddress = ip
+	return g
+}
+
+// WithRestartPolicy sets the restart policy (Always, OnFailure, Never)
+func (g *ContainerGroup) WithRestartPolicy(policy string) *ContainerGroup {
+	g.Properties.RestartPolicy = &policy
+	return g
+}
+
+// WithSystemAssignedIdentity enables a system-assigned managed identity
+func (g *ContainerGroup) WithSystemAssignedIdentity() *ContainerGroup {
+	g.Identity = &Identity{Type: "SystemAssigned"}
+	return g
+}
+
+// WithTags adds tags to the container group
+func (g *ContainerGroup) WithTags(tags map[string]string) *ContainerGroup {
+	g.Tags = tags
+	return g
+}
+
+// ID returns the ARM resourceId expression for the container group
+func (g *ContainerGroup) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.ContainerInstance/containerGroups', '%s')]", g.Name)
+}
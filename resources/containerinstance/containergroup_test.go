@@ -0,0 +1,71 @@
+package containerinstance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContainerGroup(t *testing.T) {
+	g := NewContainerGroup("hello-aci", "eastus", "Linux")
+
+	assert.Equal(t, "hello-aci", g.Name)
+	assert.Equal(t, "Microsoft.ContainerInstance/containerGroups", g.Type)
+	assert.Equal(t, "2023-05-01", g.APIVersion)
+	assert.Equal(t, "eastus", g.Location)
+	assert.Equal(t, "Linux", g.Properties.OSType)
+	assert.Empty(t, g.Properties.Containers)
+	assert.Nil(t, g.Identity)
+	assert.Equal(t, "[resourceId('Microsoft.ContainerInstance/containerGroups', 'hello-aci')]", g.ID())
+}
+
+func TestContainerGroup_AddContainer(t *testing.T) {
+	g := NewContainerGroup("web", "eastus", "Linux").
+		AddContainer("app", "mcr.microsoft.com/azuredocs/aci-helloworld", 1, 1.5, 80).
+		AddContainer("sidecar", "busybox", 0.5, 0.5).
+		WithEnvironmentVariable("app", "MODE", "production").
+		WithPublicIP("my-web", 80).
+		WithRestartPolicy("OnFailure").
+		WithSystemAssignedIdentity()
+
+	require.Len(t, g.Properties.Containers, 2)
+	app := g.Properties.Containers[0]
+	assert.Equal(t, "app", app.Name)
+	assert.Equal(t, 1.5, app.Properties.Resources.Requests.MemoryInGB)
+	assert.Equal(t, []ContainerPort{{Port: 80}}, app.Properties.Ports)
+	require.Len(t, app.Properties.EnvironmentVariables, 1)
+	assert.Equal(t, "production", *app.Properties.EnvironmentVariables[0].Value)
+	assert.Empty(t, g.Properties.Containers[1].Properties.EnvironmentVariables)
+
+	require.NotNil(t, g.Properties.IPAddress)
+	assert.Equal(t, "Public", g.Properties.IPAddress.Type)
+	assert.Equal(t, "my-web", *g.Properties.IPAddress.DNSNameLabel)
+	assert.Equal(t, "OnFailure", *g.Properties.RestartPolicy)
+	assert.Equal(t, "SystemAssigned", g.Identity.Type)
+}
+
+func TestContainerGroup_JSON(t *testing.T) {
+	g := NewContainerGroup("web", "eastus", "Linux").
+		AddContainer("app", "nginx", 1, 1.5, 80)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "Linux", props["osType"])
+	assert.NotContains(t, props, "restartPolicy")
+	assert.NotContains(t, props, "ipAddress")
+
+	containers := props["containers"].([]interface{})
+	require.Len(t, containers, 1)
+	container := containers[0].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, "nginx", container["image"])
+	assert.Equal(t, map[string]interface{}{
+		"requests": map[string]interface{}{"cpu": float64(1), "memoryInGB": 1.5},
+	}, container["resources"])
+}
@@ -0,0 +1,12 @@
+package containerinstance
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/containerinstance"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "ContainerGroup", "Microsoft.ContainerInstance/containerGroups")
+}
@@ -0,0 +1,147 @@
+// Package dashboard provides Azure Managed Grafana resource types
+package dashboard
+
+import "fmt"
+
+// Grafana represents a Microsoft.Dashboard/grafana resource: an Azure Managed
+// Grafana instance, typically reading Prometheus metrics from an Azure
+// Monitor workspace
+type Grafana struct {
+	// Name is the name of the Grafana instance
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the instance will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// SKU defines the pricing tier of the instance (Standard, Essential)
+	SKU SKU `json:"sku"`
+
+	// Identity defines the managed identity the instance uses to read its
+	// data sources, such as Azure Monitor workspaces and Log Analytics
+	// workspaces it is granted the Monitoring Reader role on
+	Identity *Identity `json:"identity,omitempty"`
+
+	// Properties contains the properties of the instance
+	Properties GrafanaProperties `json:"properties"`
+}
+
+// SKU represents the SKU of a Grafana instance
+type SKU struct {
+	// Name is the SKU name (Standard, Essential)
+	Name string `json:"name"`
+}
+
+// Identity represents the identity configuration
+type Identity struct {
+	// Type is the identity type (SystemAssigned, UserAssigned)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// GrafanaProperties represents the properties of a Grafana instance
+type GrafanaProperties struct {
+	// GrafanaMajorVersion is the major version of Grafana (e.g. "10")
+	GrafanaMajorVersion *string `json:"grafanaMajorVersion,omitempty"`
+
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+
+	// ZoneRedundancy spreads the instance across availability zones (Enabled, Disabled)
+	ZoneRedundancy *string `json:"zoneRedundancy,omitempty"`
+
+	// APIKey allows Grafana API keys and service accounts (Enabled, Disabled)
+	APIKey *string `json:"apiKey,omitempty"`
+
+	// DeterministicOutboundIP gives the instance fixed outbound IP addresses
+	// for data sources behind firewalls (Enabled, Disabled)
+	DeterministicOutboundIP *string `json:"deterministicOutboundIP,omitempty"`
+
+	// GrafanaIntegrations links the instance to Azure Monitor workspaces
+	GrafanaIntegrations *GrafanaIntegrations `json:"grafanaIntegrations,omitempty"`
+}
+
+// GrafanaIntegrations represents the Azure data sources integrated with a
+// Grafana instance
+type GrafanaIntegrations struct {
+	// AzureMonitorWorkspaceIntegrations add Azure Monitor workspaces as
+	// Prometheus data sources
+	AzureMonitorWorkspaceIntegrations []AzureMonitorWorkspaceIntegration `json:"azureMonitorWorkspaceIntegrations,omitempty"`
+}
+
+// AzureMonitorWorkspaceIntegration represents an Azure Monitor workspace
+// integrated with a Grafana instance
+type AzureMonitorWorkspaceIntegration struct {
+	// AzureMonitorWorkspaceResourceID is the resource ID of the workspace
+	AzureMonitorWorkspaceResourceID string `json:"azureMonitorWorkspaceResourceId"`
+}
+
+// NewGrafana creates a new Standard Grafana instance with a system-assigned
+// managed identity, which Grafana needs to read Azure data sources
+func NewGrafana(name, location string) *Grafana {
+	return &Grafana{
+		Name:       name,
+		Type:       "Microsoft.Dashboard/grafana",
+		APIVersion: "2023-09-01",
+		Location:   location,
+		SKU:        SKU{Name: "Standard"},
+		Identity:   &Identity{Type: "SystemAssigned"},
+	}
+}
+
+// WithTags adds tags to the Grafana instance
+func (g *Grafana) WithTags(tags map[string]string) *Grafana {
+	g.Tags = tags
+	return g
+}
+
+// WithAzureMonitorWorkspace adds an Azure Monitor workspace, given by its
+// resource ID (e.g. insights.AzureMonitorWorkspace.ID()), as a Prometheus
+// data source
+func (g *Grafana) WithAzureMonitorWorkspace(workspaceID string) *Grafana {
+	if g.Properties.GrafanaIntegrations == nil {
+		g.Properties.GrafanaIntegrations = &GrafanaIntegrations{}
+	}
+	g.Properties.GrafanaIntegrations.AzureMonitorWorkspaceIntegrations = append(
+		g.Properties.GrafanaIntegrations.AzureMonitorWorkspaceIntegrations,
+		AzureMonitorWorkspaceIntegration{AzureMonitorWorkspaceResourceID: workspaceID},
+	)
+	return g
+}
+
+// WithPublicNetworkAccess sets public network access (Enabled or Disabled)
+func (g *Grafana) WithPublicNetworkAccess(access string) *Grafana {
+	g.Properties.PublicNetworkAccess = &access
+	return g
+}
+
+// ID returns the ARM resourceId expression for the Grafana instance
+func (g *Grafana) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Dashboard/grafana', '%s')]", g.Name)
+}
+
+// PrincipalID returns an ARM expression for the principal ID of the
+// instance's system-assigned identity, for role assignments granting it
+// access to data sources
+func (g *Grafana) PrincipalID() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.Dashboard/grafana', '%s'), '2023-09-01', 'full').identity.principalId]", g.Name)
+}
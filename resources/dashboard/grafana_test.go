@@ -0,0 +1,49 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGrafana(t *testing.T) {
+	workspaceID := "[resourceId('Microsoft.Monitor/accounts', 'prometheus')]"
+	g := NewGrafana("ops-grafana", "eastus").
+		WithAzureMonitorWorkspace(workspaceID).
+		WithPublicNetworkAccess("Disabled")
+
+	assert.Equal(t, "Microsoft.Dashboard/grafana", g.Type)
+	assert.Equal(t, "2023-09-01", g.APIVersion)
+	assert.Equal(t, "Standard", g.SKU.Name)
+	require.NotNil(t, g.Identity)
+	assert.Equal(t, "SystemAssigned", g.Identity.Type)
+	require.NotNil(t, g.Properties.GrafanaIntegrations)
+	assert.Equal(t, []AzureMonitorWorkspaceIntegration{{AzureMonitorWorkspaceResourceID: workspaceID}},
+		g.Properties.GrafanaIntegrations.AzureMonitorWorkspaceIntegrations)
+
+	assert.Equal(t, "[resourceId('Microsoft.Dashboard/grafana', 'ops-grafana')]", g.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.Dashboard/grafana', 'ops-grafana'), '2023-09-01', 'full').identity.principalId]", g.PrincipalID())
+}
+
+func TestGrafana_JSON(t *testing.T) {
+	g := NewGrafana("ops-grafana", "eastus").
+		WithAzureMonitorWorkspace("[resourceId('Microsoft.Monitor/accounts', 'prometheus')]")
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, map[string]interface{}{"name": "Standard"}, result["sku"])
+	assert.Equal(t, map[string]interface{}{"type": "SystemAssigned"}, result["identity"])
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"azureMonitorWorkspaceIntegrations": []interface{}{
+			map[string]interface{}{"azureMonitorWorkspaceResourceId": "[resourceId('Microsoft.Monitor/accounts', 'prometheus')]"},
+		},
+	}, props["grafanaIntegrations"])
+	assert.NotContains(t, props, "publicNetworkAccess")
+}
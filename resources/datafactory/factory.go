@@ -0,0 +1,164 @@
+// Package datafactory provides Azure Data Factory resource types
+package datafactory
+
+// Factory represents a Microsoft.DataFactory/factories resource
+type Factory struct {
+	// Name is the name of the data factory (globally unique)
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the data factory will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Identity defines the managed identity configuration for the data factory
+	Identity *Identity `json:"identity,omitempty"`
+
+	// Properties contains the properties of the data factory
+	Properties FactoryProperties `json:"properties"`
+}
+
+// FactoryProperties represents the properties of a data factory
+type FactoryProperties struct {
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+
+	// Encryption configures customer-managed key encryption
+	Encryption *EncryptionConfiguration `json:"encryption,omitempty"`
+}
+
+// EncryptionConfiguration represents customer-managed key encryption settings
+type EncryptionConfiguration struct {
+	// VaultBaseURL is the URL of the Key Vault holding the key
+	VaultBaseURL string `json:"vaultBaseUrl"`
+
+	// KeyName is the name of the key in the Key Vault
+	KeyName string `json:"keyName"`
+
+	// KeyVersion is the version of the key; the latest version is used if unset
+	KeyVersion *string `json:"keyVersion,omitempty"`
+
+	// Identity is the user-assigned identity used to access the key
+	Identity *CMKIdentityDefinition `json:"identity,omitempty"`
+}
+
+// CMKIdentityDefinition represents the identity used for customer-managed keys
+type CMKIdentityDefinition struct {
+	// UserAssignedIdentity is the resource ID of the user-assigned identity
+	UserAssignedIdentity *string `json:"userAssignedIdentity,omitempty"`
+}
+
+// Identity represents the identity configuration
+type Identity struct {
+	// Type is the identity type (SystemAssigned, UserAssigned, SystemAssigned,UserAssigned)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// LinkedService represents a Microsoft.DataFactory/factories/linkedservices resource
+type LinkedService struct {
+	// Name is the name of the linked service, in the form "<factory>/<linkedservice>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties is the linked service definition (type, typeProperties,
+	// connectVia, ...), passed through as-is
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Pipeline represents a Microsoft.DataFactory/factories/pipelines resource
+type Pipeline struct {
+	// Name is the name of the pipeline, in the form "<factory>/<pipeline>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties is the pipeline definition (activities, parameters,
+	// variables, ...), passed through as-is
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// NewFactory creates a new data factory with required fields
+func NewFactory(name, location string) *Factory {
+	return &Factory{
+		Name:       name,
+		Type:       "Microsoft.DataFactory/factories",
+		APIVersion: "2018-06-01",
+		Location:   location,
+		Properties: FactoryProperties{},
+	}
+}
+
+// WithTags adds tags to the data factory
+func (f *Factory) WithTags(tags map[string]string) *Factory {
+	f.Tags = tags
+	return f
+}
+
+// WithSystemAssignedIdentity enables a system-assigned managed identity
+func (f *Factory) WithSystemAssignedIdentity() *Factory {
+	f.Identity = &Identity{Type: "SystemAssigned"}
+	return f
+}
+
+// WithPublicNetworkAccess sets public network access (Enabled or Disabled)
+func (f *Factory) WithPublicNetworkAccess(access string) *Factory {
+	f.Properties.PublicNetworkAccess = &access
+	return f
+}
+
+// WithEncryption encrypts the data factory with the named Key Vault key
+func (f *Factory) WithEncryption(vaultBaseURL, keyName string) *Factory {
+	f.Properties.Encryption = &EncryptionConfiguration{
+		VaultBaseURL: vaultBaseURL,
+		KeyName:      keyName,
+	}
+	return f
+}
+
+// NewLinkedService creates a new linked service under the named data factory
+func NewLinkedService(factoryName, name string, properties map[string]interface{}) *LinkedService {
+	return &LinkedService{
+		Name:       factoryName + "/" + name,
+		Type:       "Microsoft.DataFactory/factories/linkedservices",
+		APIVersion: "2018-06-01",
+		Properties: properties,
+	}
+}
+
+// NewPipeline creates a new pipeline under the named data factory
+func NewPipeline(factoryName, name string, properties map[string]interface{}) *Pipeline {
+	return &Pipeline{
+		Name:       factoryName + "/" + name,
+		Type:       "Microsoft.DataFactory/factories/pipelines",
+		APIVersion: "2018-06-01",
+		Properties: properties,
+	}
+}
@@ -0,0 +1,78 @@
+// Package datafactory provides Azure Data Factory resource types
+package datafactory
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFactory(t *testing.T) {
+	f := NewFactory("my-factory", "eastus")
+
+	assert.Equal(t, "my-factory", f.Name)
+	assert.Equal(t, "Microsoft.DataFactory/factories", f.Type)
+	assert.Equal(t, "2018-06-01", f.APIVersion)
+	assert.Equal(t, "eastus", f.Location)
+	assert.Nil(t, f.Identity)
+	assert.Nil(t, f.Properties.PublicNetworkAccess)
+}
+
+func TestFactory_With(t *testing.T) {
+	f := NewFactory("my-factory", "eastus").
+		WithTags(map[string]string{"env": "prod"}).
+		WithSystemAssignedIdentity().
+		WithPublicNetworkAccess("Disabled").
+		WithEncryption("https://my-vault.vault.azure.net", "adf-key")
+
+	assert.Equal(t, "prod", f.Tags["env"])
+	require.NotNil(t, f.Identity)
+	assert.Equal(t, "SystemAssigned", f.Identity.Type)
+	require.NotNil(t, f.Properties.PublicNetworkAccess)
+	assert.Equal(t, "Disabled", *f.Properties.PublicNetworkAccess)
+	require.NotNil(t, f.Properties.Encryption)
+	assert.Equal(t, "https://my-vault.vault.azure.net", f.Properties.Encryption.VaultBaseURL)
+	assert.Equal(t, "adf-key", f.Properties.Encryption.KeyName)
+}
+
+func TestNewLinkedService(t *testing.T) {
+	ls := NewLinkedService("my-factory", "blob", map[string]interface{}{
+		"type": "AzureBlobStorage",
+		"typeProperties": map[string]interface{}{
+			"connectionString": "DefaultEndpointsProtocol=https;AccountName=mystorage",
+		},
+	})
+
+	assert.Equal(t, "my-factory/blob", ls.Name)
+	assert.Equal(t, "Microsoft.DataFactory/factories/linkedservices", ls.Type)
+	assert.Equal(t, "2018-06-01", ls.APIVersion)
+	assert.Equal(t, "AzureBlobStorage", ls.Properties["type"])
+}
+
+func TestNewPipeline(t *testing.T) {
+	p := NewPipeline("my-factory", "copy", map[string]interface{}{
+		"activities": []interface{}{},
+	})
+
+	assert.Equal(t, "my-factory/copy", p.Name)
+	assert.Equal(t, "Microsoft.DataFactory/factories/pipelines", p.Type)
+	assert.Contains(t, p.Properties, "activities")
+}
+
+func TestFactory_JSON(t *testing.T) {
+	f := NewFactory("my-factory", "eastus").WithPublicNetworkAccess("Disabled")
+
+	data, err := json.Marshal(f)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "Microsoft.DataFactory/factories", result["type"])
+	assert.NotContains(t, result, "identity")
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "Disabled", props["publicNetworkAccess"])
+	assert.NotContains(t, props, "encryption")
+}
@@ -0,0 +1,14 @@
+package datafactory
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/datafactory"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "Factory", "Microsoft.DataFactory/factories")
+	template.RegisterResourceType(importPath, "LinkedService", "Microsoft.DataFactory/factories/linkedservices")
+	template.RegisterResourceType(importPath, "Pipeline", "Microsoft.DataFactory/factories/pipelines")
+}
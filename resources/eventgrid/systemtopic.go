@@ -0,0 +1,61 @@
+// Package eventgrid provides Azure Event Grid resource types
+package eventgrid
+
+import "fmt"
+
+// SystemTopic represents a Microsoft.EventGrid/systemTopics resource
+type SystemTopic struct {
+	// Name is the name of the system topic
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the system topic, which must match its source
+	// ("global" for subscription and resource group sources)
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the system topic
+	Properties SystemTopicProperties `json:"properties"`
+}
+
+// SystemTopicProperties represents the properties of a system topic
+type SystemTopicProperties struct {
+	// Source is the resource ID of the Azure resource that publishes the events
+	Source string `json:"source"`
+
+	// TopicType is the kind of events published (e.g. Microsoft.Storage.StorageAccounts)
+	TopicType string `json:"topicType"`
+}
+
+// NewSystemTopic creates a system topic for the events of the resource
+// identified by sourceID
+func NewSystemTopic(name, location, sourceID, topicType string) *SystemTopic {
+	return &SystemTopic{
+		Name:       name,
+		Type:       "Microsoft.EventGrid/systemTopics",
+		APIVersion: "2022-06-15",
+		Location:   location,
+		Properties: SystemTopicProperties{
+			Source:    sourceID,
+			TopicType: topicType,
+		},
+	}
+}
+
+// WithTags adds tags to the system topic
+func (s *SystemTopic) WithTags(tags map[string]string) *SystemTopic {
+	s.Tags = tags
+	return s
+}
+
+// ID returns the ARM resourceId expression for the system topic
+func (s *SystemTopic) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.EventGrid/systemTopics', '%s')]", s.Name)
+}
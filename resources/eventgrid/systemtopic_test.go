@@ -0,0 +1,23 @@
+// Package eventgrid provides Azure Event Grid resource types
+package eventgrid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSystemTopic(t *testing.T) {
+	topic := NewSystemTopic("storage-events", "eastus",
+		"[resourceId('Microsoft.Storage/storageAccounts', 'mystorage')]", "Microsoft.Storage.StorageAccounts").
+		WithTags(map[string]string{"env": "prod"})
+
+	assert.Equal(t, "storage-events", topic.Name)
+	assert.Equal(t, "Microsoft.EventGrid/systemTopics", topic.Type)
+	assert.Equal(t, "2022-06-15", topic.APIVersion)
+	assert.Equal(t, "eastus", topic.Location)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'mystorage')]", topic.Properties.Source)
+	assert.Equal(t, "Microsoft.Storage.StorageAccounts", topic.Properties.TopicType)
+	assert.Equal(t, "prod", topic.Tags["env"])
+	assert.Equal(t, "[resourceId('Microsoft.EventGrid/systemTopics', 'storage-events')]", topic.ID())
+}
@@ -0,0 +1,136 @@
+// Package insights provides Azure Monitor resource types
+package insights
+
+import "fmt"
+
+// ActionGroup represents a Microsoft.Insights/actionGroups resource
+type ActionGroup struct {
+	// Name is the name of the action group
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the action group; action groups are global
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the action group
+	Properties ActionGroupProperties `json:"properties"`
+}
+
+// ActionGroupProperties represents the properties of an action group
+type ActionGroupProperties struct {
+	// GroupShortName is the name used in SMS and email notifications (at most 12 characters)
+	GroupShortName string `json:"groupShortName"`
+
+	// Enabled controls whether the action group sends notifications
+	Enabled bool `json:"enabled"`
+
+	// EmailReceivers are the email addresses to notify
+	EmailReceivers []EmailReceiver `json:"emailReceivers,omitempty"`
+
+	// SMSReceivers are the phone numbers to notify by SMS
+	SMSReceivers []SMSReceiver `json:"smsReceivers,omitempty"`
+
+	// WebhookReceivers are the webhooks to call
+	WebhookReceivers []WebhookReceiver `json:"webhookReceivers,omitempty"`
+}
+
+// EmailReceiver represents an email receiver of an action group
+type EmailReceiver struct {
+	// Name is the name of the receiver, unique within the action group
+	Name string `json:"name"`
+
+	// EmailAddress is the address to notify
+	EmailAddress string `json:"emailAddress"`
+
+	// UseCommonAlertSchema sends the alert in the common alert schema
+	UseCommonAlertSchema *bool `json:"useCommonAlertSchema,omitempty"`
+}
+
+// SMSReceiver represents an SMS receiver of an action group
+type SMSReceiver struct {
+	// Name is the name of the receiver, unique within the action group
+	Name string `json:"name"`
+
+	// CountryCode is the country code of the phone number (e.g. "1")
+	CountryCode string `json:"countryCode"`
+
+	// PhoneNumber is the phone number to notify
+	PhoneNumber string `json:"phoneNumber"`
+}
+
+// WebhookReceiver represents a webhook receiver of an action group
+type WebhookReceiver struct {
+	// Name is the name of the receiver, unique within the action group
+	Name string `json:"name"`
+
+	// ServiceURI is the URI the alert is posted to
+	ServiceURI string `json:"serviceUri"`
+
+	// UseCommonAlertSchema sends the alert in the common alert schema
+	UseCommonAlertSchema *bool `json:"useCommonAlertSchema,omitempty"`
+}
+
+// NewActionGroup creates a new enabled action group with no receivers
+func NewActionGroup(name, shortName string) *ActionGroup {
+	return &ActionGroup{
+		Name:       name,
+		Type:       "Microsoft.Insights/actionGroups",
+		APIVersion: "2023-01-01",
+		Location:   "global",
+		Properties: ActionGroupProperties{
+			GroupShortName: shortName,
+			Enabled:        true,
+		},
+	}
+}
+
+// WithTags adds tags to the action group
+func (a *ActionGroup) WithTags(tags map[string]string) *ActionGroup {
+	a.Tags = tags
+	return a
+}
+
+// AddEmailReceiver adds an email receiver using the common alert schema
+func (a *ActionGroup) AddEmailReceiver(name, emailAddress string) *ActionGroup {
+	useCommonSchema := true
+	a.Properties.EmailReceivers = append(a.Properties.EmailReceivers, EmailReceiver{
+		Name:                 name,
+		EmailAddress:         emailAddress,
+		UseCommonAlertSchema: &useCommonSchema,
+	})
+	return a
+}
+
+// AddSMSReceiver adds an SMS receiver
+func (a *ActionGroup) AddSMSReceiver(name, countryCode, phoneNumber string) *ActionGroup {
+	a.Properties.SMSReceivers = append(a.Properties.SMSReceivers, SMSReceiver{
+		Name:        name,
+		CountryCode: countryCode,
+		PhoneNumber: phoneNumber,
+	})
+	return a
+}
+
+// AddWebhookReceiver adds a webhook receiver using the common alert schema
+func (a *ActionGroup) AddWebhookReceiver(name, serviceURI string) *ActionGroup {
+	useCommonSchema := true
+	a.Properties.WebhookReceivers = append(a.Properties.WebhookReceivers, WebhookReceiver{
+		Name:                 name,
+		ServiceURI:           serviceURI,
+		UseCommonAlertSchema: &useCommonSchema,
+	})
+	return a
+}
+
+// ID returns the ARM resourceId expression for the action group
+func (a *ActionGroup) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Insights/actionGroups', '%s')]", a.Name)
+}
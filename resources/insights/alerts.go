@@ -0,0 +1,277 @@
+package insights
+
+// MetricAlert represents a Microsoft.Insights/metricAlerts resource
+type MetricAlert struct {
+	// Name is the name of the alert rule
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the alert rule; metric alerts are global
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the alert rule
+	Properties MetricAlertProperties `json:"properties"`
+}
+
+// MetricAlertProperties represents the properties of a metric alert rule
+type MetricAlertProperties struct {
+	// Description is a description of the alert rule
+	Description *string `json:"description,omitempty"`
+
+	// Severity is the alert severity, from 0 (critical) to 4 (verbose)
+	Severity int `json:"severity"`
+
+	// Enabled controls whether the alert rule is evaluated
+	Enabled bool `json:"enabled"`
+
+	// Scopes are the resource IDs the alert rule monitors
+	Scopes []string `json:"scopes"`
+
+	// EvaluationFrequency is how often the rule is evaluated, as an ISO 8601 duration
+	EvaluationFrequency string `json:"evaluationFrequency"`
+
+	// WindowSize is the period over which metrics are aggregated, as an ISO 8601 duration
+	WindowSize string `json:"windowSize"`
+
+	// Criteria are the conditions that fire the alert
+	Criteria MetricAlertCriteria `json:"criteria"`
+
+	// AutoMitigate resolves the alert automatically once the condition clears
+	AutoMitigate *bool `json:"autoMitigate,omitempty"`
+
+	// Actions are the action groups notified when the alert fires
+	Actions []MetricAlertAction `json:"actions,omitempty"`
+}
+
+// MetricAlertCriteria represents the criteria of a single-resource metric alert
+type MetricAlertCriteria struct {
+	// ODataType is the criteria kind
+	// (e.g. Microsoft.Azure.Monitor.SingleResourceMultipleMetricCriteria)
+	ODataType string `json:"odata.type"`
+
+	// AllOf are the conditions that must all be met
+	AllOf []MetricCriterion `json:"allOf"`
+}
+
+// MetricCriterion represents a static threshold condition on a metric
+type MetricCriterion struct {
+	// CriterionType is the condition kind (StaticThresholdCriterion)
+	CriterionType string `json:"criterionType"`
+
+	// Name is the name of the condition
+	Name string `json:"name"`
+
+	// MetricName is the name of the metric (e.g. Percentage CPU)
+	MetricName string `json:"metricName"`
+
+	// MetricNamespace is the namespace of the metric, if not the resource's default
+	MetricNamespace *string `json:"metricNamespace,omitempty"`
+
+	// Operator compares the metric to the threshold (GreaterThan, LessThan, ...)
+	Operator string `json:"operator"`
+
+	// Threshold is the value the metric is compared to
+	Threshold float64 `json:"threshold"`
+
+	// TimeAggregation is the aggregation applied over the window (Average, Maximum, Total, ...)
+	TimeAggregation string `json:"timeAggregation"`
+}
+
+// MetricAlertAction represents an action group notified by a metric alert
+type MetricAlertAction struct {
+	// ActionGroupID is the resource ID of the action group
+	ActionGroupID string `json:"actionGroupId"`
+
+	// WebHookProperties are custom properties included in the alert payload
+	WebHookProperties map[string]string `json:"webHookProperties,omitempty"`
+}
+
+// ScheduledQueryRule represents a Microsoft.Insights/scheduledQueryRules resource
+type ScheduledQueryRule struct {
+	// Name is the name of the alert rule
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the alert rule, usually that of its scope
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the alert rule
+	Properties ScheduledQueryRuleProperties `json:"properties"`
+}
+
+// ScheduledQueryRuleProperties represents the properties of a log search alert rule
+type ScheduledQueryRuleProperties struct {
+	// DisplayName is the display name of the alert rule
+	DisplayName *string `json:"displayName,omitempty"`
+
+	// Description is a description of the alert rule
+	Description *string `json:"description,omitempty"`
+
+	// Severity is the alert severity, from 0 (critical) to 4 (verbose)
+	Severity int `json:"severity"`
+
+	// Enabled controls whether the alert rule is evaluated
+	Enabled bool `json:"enabled"`
+
+	// Scopes are the resource IDs the query runs against (e.g. a Log Analytics workspace)
+	Scopes []string `json:"scopes"`
+
+	// EvaluationFrequency is how often the query runs, as an ISO 8601 duration
+	EvaluationFrequency string `json:"evaluationFrequency"`
+
+	// WindowSize is the period of data the query covers, as an ISO 8601 duration
+	WindowSize string `json:"windowSize"`
+
+	// Criteria are the conditions that fire the alert
+	Criteria ScheduledQueryRuleCriteria `json:"criteria"`
+
+	// Actions are the action groups notified when the alert fires
+	Actions *ScheduledQueryRuleActions `json:"actions,omitempty"`
+}
+
+// ScheduledQueryRuleCriteria represents the criteria of a log search alert rule
+type ScheduledQueryRuleCriteria struct {
+	// AllOf are the conditions that must all be met
+	AllOf []QueryCondition `json:"allOf"`
+}
+
+// QueryCondition represents a threshold condition on the result of a log query
+type QueryCondition struct {
+	// Query is the Kusto query to run
+	Query string `json:"query"`
+
+	// TimeAggregation is the aggregation applied to the results (Count, Average, Total, ...)
+	TimeAggregation string `json:"timeAggregation"`
+
+	// MetricMeasureColumn is the column aggregated, required unless TimeAggregation is Count
+	MetricMeasureColumn *string `json:"metricMeasureColumn,omitempty"`
+
+	// Operator compares the aggregated value to the threshold (GreaterThan, LessThan, ...)
+	Operator string `json:"operator"`
+
+	// Threshold is the value the aggregated result is compared to
+	Threshold float64 `json:"threshold"`
+}
+
+// ScheduledQueryRuleActions represents the actions of a log search alert rule
+type ScheduledQueryRuleActions struct {
+	// ActionGroups are the resource IDs of the action groups to notify
+	ActionGroups []string `json:"actionGroups,omitempty"`
+}
+
+// NewMetricAlert creates an enabled metric alert of severity 2 on the resource
+// identified by targetID, evaluated every minute over a five minute window.
+// Add conditions with WithCriterion.
+func NewMetricAlert(name, targetID string) *MetricAlert {
+	return &MetricAlert{
+		Name:       name,
+		Type:       "Microsoft.Insights/metricAlerts",
+		APIVersion: "2018-03-01",
+		Location:   "global",
+		Properties: MetricAlertProperties{
+			Severity:            2,
+			Enabled:             true,
+			Scopes:              []string{targetID},
+			EvaluationFrequency: "PT1M",
+			WindowSize:          "PT5M",
+			Criteria: MetricAlertCriteria{
+				ODataType: "Microsoft.Azure.Monitor.SingleResourceMultipleMetricCriteria",
+			},
+		},
+	}
+}
+
+// WithTags adds tags to the metric alert
+func (m *MetricAlert) WithTags(tags map[string]string) *MetricAlert {
+	m.Tags = tags
+	return m
+}
+
+// WithSeverity sets the alert severity, from 0 (critical) to 4 (verbose)
+func (m *MetricAlert) WithSeverity(severity int) *MetricAlert {
+	m.Properties.Severity = severity
+	return m
+}
+
+// WithCriterion adds a static threshold condition on metricName
+func (m *MetricAlert) WithCriterion(metricName, operator string, threshold float64, timeAggregation string) *MetricAlert {
+	m.Properties.Criteria.AllOf = append(m.Properties.Criteria.AllOf, MetricCriterion{
+		CriterionType:   "StaticThresholdCriterion",
+		Name:            metricName,
+		MetricName:      metricName,
+		Operator:        operator,
+		Threshold:       threshold,
+		TimeAggregation: timeAggregation,
+	})
+	return m
+}
+
+// WithActionGroup notifies the action group identified by actionGroupID
+func (m *MetricAlert) WithActionGroup(actionGroupID string) *MetricAlert {
+	m.Properties.Actions = append(m.Properties.Actions, MetricAlertAction{ActionGroupID: actionGroupID})
+	return m
+}
+
+// NewScheduledQueryRule creates an enabled log search alert of severity 3 that
+// fires when query, run against the resource identified by scopeID every five
+// minutes, returns any rows
+func NewScheduledQueryRule(name, location, scopeID, query string) *ScheduledQueryRule {
+	return &ScheduledQueryRule{
+		Name:       name,
+		Type:       "Microsoft.Insights/scheduledQueryRules",
+		APIVersion: "2021-08-01",
+		Location:   location,
+		Properties: ScheduledQueryRuleProperties{
+			Severity:            3,
+			Enabled:             true,
+			Scopes:              []string{scopeID},
+			EvaluationFrequency: "PT5M",
+			WindowSize:          "PT5M",
+			Criteria: ScheduledQueryRuleCriteria{
+				AllOf: []QueryCondition{{
+					Query:           query,
+					TimeAggregation: "Count",
+					Operator:        "GreaterThan",
+					Threshold:       0,
+				}},
+			},
+		},
+	}
+}
+
+// WithTags adds tags to the scheduled query rule
+func (s *ScheduledQueryRule) WithTags(tags map[string]string) *ScheduledQueryRule {
+	s.Tags = tags
+	return s
+}
+
+// WithSeverity sets the alert severity, from 0 (critical) to 4 (verbose)
+func (s *ScheduledQueryRule) WithSeverity(severity int) *ScheduledQueryRule {
+	s.Properties.Severity = severity
+	return s
+}
+
+// WithActionGroup notifies the action group identified by actionGroupID
+func (s *ScheduledQueryRule) WithActionGroup(actionGroupID string) *ScheduledQueryRule {
+	if s.Properties.Actions == nil {
+		s.Properties.Actions = &ScheduledQueryRuleActions{}
+	}
+	s.Properties.Actions.ActionGroups = append(s.Properties.Actions.ActionGroups, actionGroupID)
+	return s
+}
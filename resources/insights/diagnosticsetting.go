@@ -0,0 +1,102 @@
+package insights
+
+// DiagnosticSetting represents a Microsoft.Insights/diagnosticSettings
+// resource. It is an extension resource: it has no location of its own and
+// applies to the resource identified by TargetResourceID.
+type DiagnosticSetting struct {
+	// Name is the name of the diagnostic setting, unique per target resource
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// TargetResourceID is the ID of the resource whose logs and metrics are
+	// collected; it is emitted as the ARM scope of the extension resource
+	TargetResourceID string `json:"scope,omitempty"`
+
+	// Properties contains the properties of the diagnostic setting
+	Properties DiagnosticSettingProperties `json:"properties"`
+}
+
+// DiagnosticSettingProperties represents the properties of a diagnostic setting
+type DiagnosticSettingProperties struct {
+	// WorkspaceID is the ID of the Log Analytics workspace to send data to
+	WorkspaceID string `json:"workspaceId,omitempty"`
+
+	// StorageAccountID is the ID of the storage account to archive data to
+	StorageAccountID string `json:"storageAccountId,omitempty"`
+
+	// Logs are the log categories to collect
+	Logs []LogSettings `json:"logs,omitempty"`
+
+	// Metrics are the metric categories to collect
+	Metrics []MetricSettings `json:"metrics,omitempty"`
+}
+
+// LogSettings enables a log category, or a group of categories such as
+// "allLogs" or "audit"
+type LogSettings struct {
+	// Category is the name of a log category; leave empty when CategoryGroup is set
+	Category string `json:"category,omitempty"`
+
+	// CategoryGroup is the name of a group of log categories
+	CategoryGroup string `json:"categoryGroup,omitempty"`
+
+	// Enabled controls whether the category is collected
+	Enabled bool `json:"enabled"`
+}
+
+// MetricSettings enables a metric category, usually "AllMetrics"
+type MetricSettings struct {
+	// Category is the name of the metric category
+	Category string `json:"category"`
+
+	// Enabled controls whether the category is collected
+	Enabled bool `json:"enabled"`
+}
+
+// NewDiagnosticSetting creates a diagnostic setting for the resource
+// identified by targetResourceID. Add destinations with WithWorkspace and
+// WithStorageAccount, and the data to collect with WithLogCategoryGroup,
+// WithLogCategory, and WithMetrics.
+func NewDiagnosticSetting(name, targetResourceID string) *DiagnosticSetting {
+	return &DiagnosticSetting{
+		Name:             name,
+		Type:             "Microsoft.Insights/diagnosticSettings",
+		APIVersion:       "2021-05-01-preview",
+		TargetResourceID: targetResourceID,
+	}
+}
+
+// WithWorkspace sends the collected data to the Log Analytics workspace identified by workspaceID
+func (d *DiagnosticSetting) WithWorkspace(workspaceID string) *DiagnosticSetting {
+	d.Properties.WorkspaceID = workspaceID
+	return d
+}
+
+// WithStorageAccount archives the collected data to the storage account identified by storageAccountID
+func (d *DiagnosticSetting) WithStorageAccount(storageAccountID string) *DiagnosticSetting {
+	d.Properties.StorageAccountID = storageAccountID
+	return d
+}
+
+// WithLogCategoryGroup collects a group of log categories, such as "allLogs"
+func (d *DiagnosticSetting) WithLogCategoryGroup(group string) *DiagnosticSetting {
+	d.Properties.Logs = append(d.Properties.Logs, LogSettings{CategoryGroup: group, Enabled: true})
+	return d
+}
+
+// WithLogCategory collects a single log category
+func (d *DiagnosticSetting) WithLogCategory(category string) *DiagnosticSetting {
+	d.Properties.Logs = append(d.Properties.Logs, LogSettings{Category: category, Enabled: true})
+	return d
+}
+
+// WithMetrics collects a metric category, usually "AllMetrics"
+func (d *DiagnosticSetting) WithMetrics(category string) *DiagnosticSetting {
+	d.Properties.Metrics = append(d.Properties.Metrics, MetricSettings{Category: category, Enabled: true})
+	return d
+}
@@ -0,0 +1,135 @@
+// Package insights provides Azure Monitor resource types
+package insights
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewActionGroup(t *testing.T) {
+	ag := NewActionGroup("ops-team", "ops").
+		AddEmailReceiver("oncall", "oncall@example.com").
+		AddSMSReceiver("pager", "1", "5555550100").
+		AddWebhookReceiver("chat", "https://hooks.example.com/alerts")
+
+	assert.Equal(t, "ops-team", ag.Name)
+	assert.Equal(t, "Microsoft.Insights/actionGroups", ag.Type)
+	assert.Equal(t, "2023-01-01", ag.APIVersion)
+	assert.Equal(t, "global", ag.Location)
+	assert.Equal(t, "ops", ag.Properties.GroupShortName)
+	assert.True(t, ag.Properties.Enabled)
+
+	require.Len(t, ag.Properties.EmailReceivers, 1)
+	assert.Equal(t, "oncall@example.com", ag.Properties.EmailReceivers[0].EmailAddress)
+	require.NotNil(t, ag.Properties.EmailReceivers[0].UseCommonAlertSchema)
+	assert.True(t, *ag.Properties.EmailReceivers[0].UseCommonAlertSchema)
+	require.Len(t, ag.Properties.SMSReceivers, 1)
+	assert.Equal(t, "5555550100", ag.Properties.SMSReceivers[0].PhoneNumber)
+	require.Len(t, ag.Properties.WebhookReceivers, 1)
+	assert.Equal(t, "https://hooks.example.com/alerts", ag.Properties.WebhookReceivers[0].ServiceURI)
+
+	assert.Equal(t, "[resourceId('Microsoft.Insights/actionGroups', 'ops-team')]", ag.ID())
+}
+
+func TestNewMetricAlert(t *testing.T) {
+	alert := NewMetricAlert("high-cpu", "[resourceId('Microsoft.Compute/virtualMachines', 'myvm')]").
+		WithSeverity(1).
+		WithCriterion("Percentage CPU", "GreaterThan", 90, "Average").
+		WithActionGroup("[resourceId('Microsoft.Insights/actionGroups', 'ops-team')]")
+
+	assert.Equal(t, "Microsoft.Insights/metricAlerts", alert.Type)
+	assert.Equal(t, "2018-03-01", alert.APIVersion)
+	assert.Equal(t, "global", alert.Location)
+	assert.Equal(t, 1, alert.Properties.Severity)
+	assert.Equal(t, []string{"[resourceId('Microsoft.Compute/virtualMachines', 'myvm')]"}, alert.Properties.Scopes)
+	assert.Equal(t, "Microsoft.Azure.Monitor.SingleResourceMultipleMetricCriteria", alert.Properties.Criteria.ODataType)
+
+	require.Len(t, alert.Properties.Criteria.AllOf, 1)
+	criterion := alert.Properties.Criteria.AllOf[0]
+	assert.Equal(t, "StaticThresholdCriterion", criterion.CriterionType)
+	assert.Equal(t, "Percentage CPU", criterion.MetricName)
+	assert.Equal(t, float64(90), criterion.Threshold)
+
+	require.Len(t, alert.Properties.Actions, 1)
+	assert.Equal(t, "[resourceId('Microsoft.Insights/actionGroups', 'ops-team')]", alert.Properties.Actions[0].ActionGroupID)
+}
+
+func TestNewScheduledQueryRule(t *testing.T) {
+	rule := NewScheduledQueryRule("failed-logins", "eastus", "[resourceId('Microsoft.OperationalInsights/workspaces', 'logs')]",
+		"SigninLogs | where ResultType != 0").
+		WithActionGroup("[resourceId('Microsoft.Insights/actionGroups', 'ops-team')]")
+
+	assert.Equal(t, "Microsoft.Insights/scheduledQueryRules", rule.Type)
+	assert.Equal(t, "2021-08-01", rule.APIVersion)
+	assert.Equal(t, "eastus", rule.Location)
+	assert.Equal(t, 3, rule.Properties.Severity)
+
+	require.Len(t, rule.Properties.Criteria.AllOf, 1)
+	condition := rule.Properties.Criteria.AllOf[0]
+	assert.Equal(t, "SigninLogs | where ResultType != 0", condition.Query)
+	assert.Equal(t, "Count", condition.TimeAggregation)
+	assert.Equal(t, "GreaterThan", condition.Operator)
+
+	require.NotNil(t, rule.Properties.Actions)
+	assert.Equal(t, []string{"[resourceId('Microsoft.Insights/actionGroups', 'ops-team')]"}, rule.Properties.Actions.ActionGroups)
+}
+
+func TestMetricAlert_JSON(t *testing.T) {
+	alert := NewMetricAlert("high-cpu", "vm-id").WithCriterion("Percentage CPU", "GreaterThan", 90, "Average")
+
+	data, err := json.Marshal(alert)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	assert.NotContains(t, props, "actions")
+	criteria := props["criteria"].(map[string]interface{})
+	assert.Equal(t, "Microsoft.Azure.Monitor.SingleResourceMultipleMetricCriteria", criteria["odata.type"])
+}
+
+func TestNewDiagnosticSetting(t *testing.T) {
+	target := "[resourceId('Microsoft.Storage/storageAccounts', 'appdata')]"
+	setting := NewDiagnosticSetting("send-to-logs", target).
+		WithWorkspace("[resourceId('Microsoft.OperationalInsights/workspaces', 'logs')]").
+		WithStorageAccount("[resourceId('Microsoft.Storage/storageAccounts', 'archive')]").
+		WithLogCategoryGroup("allLogs").
+		WithLogCategory("StorageRead").
+		WithMetrics("AllMetrics")
+
+	assert.Equal(t, "send-to-logs", setting.Name)
+	assert.Equal(t, "Microsoft.Insights/diagnosticSettings", setting.Type)
+	assert.Equal(t, "2021-05-01-preview", setting.APIVersion)
+	assert.Equal(t, target, setting.TargetResourceID)
+	assert.Equal(t, "[resourceId('Microsoft.OperationalInsights/workspaces', 'logs')]", setting.Properties.WorkspaceID)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'archive')]", setting.Properties.StorageAccountID)
+	assert.Equal(t, []LogSettings{
+		{CategoryGroup: "allLogs", Enabled: true},
+		{Category: "StorageRead", Enabled: true},
+	}, setting.Properties.Logs)
+	assert.Equal(t, []MetricSettings{{Category: "AllMetrics", Enabled: true}}, setting.Properties.Metrics)
+
+	data, err := json.Marshal(setting)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, target, decoded["scope"])
+	assert.NotContains(t, decoded, "location")
+}
+
+func TestNewAzureMonitorWorkspace(t *testing.T) {
+	ws := NewAzureMonitorWorkspace("prometheus", "eastus")
+
+	assert.Equal(t, "Microsoft.Monitor/accounts", ws.Type)
+	assert.Equal(t, "2023-04-03", ws.APIVersion)
+	assert.Nil(t, ws.Properties)
+	assert.Equal(t, "[resourceId('Microsoft.Monitor/accounts', 'prometheus')]", ws.ID())
+
+	data, err := json.Marshal(ws.WithPublicNetworkAccess("Disabled"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"properties":{"publicNetworkAccess":"Disabled"}`)
+}
@@ -0,0 +1,63 @@
+package insights
+
+import "fmt"
+
+// AzureMonitorWorkspace represents a Microsoft.Monitor/accounts resource: an
+// Azure Monitor workspace, which stores Prometheus metrics such as those
+// collected from AKS clusters with managed Prometheus
+type AzureMonitorWorkspace struct {
+	// Name is the name of the workspace
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the workspace
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the workspace
+	Properties *AzureMonitorWorkspaceProperties `json:"properties,omitempty"`
+}
+
+// AzureMonitorWorkspaceProperties represents the properties of an Azure
+// Monitor workspace
+type AzureMonitorWorkspaceProperties struct {
+	// PublicNetworkAccess controls ingestion and queries from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+}
+
+// NewAzureMonitorWorkspace creates a new Azure Monitor workspace
+func NewAzureMonitorWorkspace(name, location string) *AzureMonitorWorkspace {
+	return &AzureMonitorWorkspace{
+		Name:       name,
+		Type:       "Microsoft.Monitor/accounts",
+		APIVersion: "2023-04-03",
+		Location:   location,
+	}
+}
+
+// WithTags adds tags to the workspace
+func (w *AzureMonitorWorkspace) WithTags(tags map[string]string) *AzureMonitorWorkspace {
+	w.Tags = tags
+	return w
+}
+
+// WithPublicNetworkAccess sets public network access (Enabled or Disabled)
+func (w *AzureMonitorWorkspace) WithPublicNetworkAccess(access string) *AzureMonitorWorkspace {
+	if w.Properties == nil {
+		w.Properties = &AzureMonitorWorkspaceProperties{}
+	}
+	w.Properties.PublicNetworkAccess = &access
+	return w
+}
+
+// ID returns the ARM resourceId expression for the workspace
+func (w *AzureMonitorWorkspace) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Monitor/accounts', '%s')]", w.Name)
+}
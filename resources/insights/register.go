@@ -0,0 +1,16 @@
+package insights
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/insights"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "ActionGroup", "Microsoft.Insights/actionGroups")
+	template.RegisterResourceType(importPath, "MetricAlert", "Microsoft.Insights/metricAlerts")
+	template.RegisterResourceType(importPath, "ScheduledQueryRule", "Microsoft.Insights/scheduledQueryRules")
+	template.RegisterResourceType(importPath, "DiagnosticSetting", "Microsoft.Insights/diagnosticSettings")
+	template.RegisterResourceType(importPath, "AzureMonitorWorkspace", "Microsoft.Monitor/accounts")
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GroupVersion is the ASO API group and version for managed identity resources.
+const GroupVersion = "managedidentity.azure.com/v1"
+
+// WorkloadIdentityAudience is the token audience used by AKS workload identity.
+const WorkloadIdentityAudience = "api://AzureADTokenExchange"
+
+// NewUserAssignedIdentity creates a User Assigned Identity in namespace ns,
+// owned by resource group rg. The Azure name matches the Kubernetes name.
+func NewUserAssignedIdentity(name, ns, location, rg string) *UserAssignedIdentity {
+	return &UserAssignedIdentity{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: GroupVersion,
+			Kind:       "UserAssignedIdentity",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: UserAssignedIdentitySpec{
+			Owner:     &ResourceGroupReference{Name: rg},
+			AzureName: &name,
+			Location:  &location,
+		},
+	}
+}
+
+// WithTags sets the Azure tags on the identity.
+func (u *UserAssignedIdentity) WithTags(tags map[string]string) *UserAssignedIdentity {
+	u.Spec.Tags = tags
+	return u
+}
+
+// NewFederatedIdentityCredential creates a Federated Identity Credential in
+// namespace ns, owned by the User Assigned Identity named identity. The
+// audience defaults to WorkloadIdentityAudience.
+func NewFederatedIdentityCredential(name, ns, identity string) *FederatedIdentityCredential {
+	return &FederatedIdentityCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: GroupVersion,
+			Kind:       "FederatedIdentityCredential",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: FederatedIdentityCredentialSpec{
+			Owner:     &UserAssignedIdentityReference{Name: identity},
+			AzureName: &name,
+			Audiences: []string{WorkloadIdentityAudience},
+		},
+	}
+}
+
+// WithIssuer sets the OIDC issuer URL, typically the AKS cluster's OIDC issuer.
+func (f *FederatedIdentityCredential) WithIssuer(url string) *FederatedIdentityCredential {
+	f.Spec.Issuer = &url
+	return f
+}
+
+// WithSubject sets the subject claim.
+func (f *FederatedIdentityCredential) WithSubject(sub string) *FederatedIdentityCredential {
+	f.Spec.Subject = &sub
+	return f
+}
+
+// WithServiceAccount sets the subject claim for a Kubernetes service account.
+func (f *FederatedIdentityCredential) WithServiceAccount(namespace, serviceAccount string) *FederatedIdentityCredential {
+	return f.WithSubject(fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount))
+}
+
+// WithAudiences replaces the token audiences.
+func (f *FederatedIdentityCredential) WithAudiences(audiences ...string) *FederatedIdentityCredential {
+	f.Spec.Audiences = audiences
+	return f
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUserAssignedIdentity(t *testing.T) {
+	identity := NewUserAssignedIdentity("app-identity", "aso-system", "eastus", "my-rg").
+		WithTags(map[string]string{"env": "prod"})
+
+	assert.Equal(t, "managedidentity.azure.com/v1", identity.APIVersion)
+	assert.Equal(t, "UserAssignedIdentity", identity.Kind)
+	assert.Equal(t, "app-identity", identity.Name)
+	assert.Equal(t, "aso-system", identity.Namespace)
+	require.NotNil(t, identity.Spec.Owner)
+	assert.Equal(t, "my-rg", identity.Spec.Owner.Name)
+	require.NotNil(t, identity.Spec.AzureName)
+	assert.Equal(t, "app-identity", *identity.Spec.AzureName)
+	require.NotNil(t, identity.Spec.Location)
+	assert.Equal(t, "eastus", *identity.Spec.Location)
+	assert.Equal(t, "prod", identity.Spec.Tags["env"])
+}
+
+func TestNewFederatedIdentityCredential(t *testing.T) {
+	cred := NewFederatedIdentityCredential("app-fic", "aso-system", "app-identity").
+		WithIssuer("https://oidc.example.com/issuer").
+		WithSubject("system:serviceaccount:default:app").
+		WithAudiences("api://custom")
+
+	assert.Equal(t, "managedidentity.azure.com/v1", cred.APIVersion)
+	assert.Equal(t, "FederatedIdentityCredential", cred.Kind)
+	assert.Equal(t, "app-fic", cred.Name)
+	require.NotNil(t, cred.Spec.Owner)
+	assert.Equal(t, "app-identity", cred.Spec.Owner.Name)
+	require.NotNil(t, cred.Spec.Issuer)
+	assert.Equal(t, "https://oidc.example.com/issuer", *cred.Spec.Issuer)
+	require.NotNil(t, cred.Spec.Subject)
+	assert.Equal(t, "system:serviceaccount:default:app", *cred.Spec.Subject)
+	assert.Equal(t, []string{"api://custom"}, cred.Spec.Audiences)
+}
+
+func TestFederatedIdentityCredential_Defaults(t *testing.T) {
+	cred := NewFederatedIdentityCredential("app-fic", "aso-system", "app-identity").
+		WithServiceAccount("payments", "api")
+
+	assert.Equal(t, []string{WorkloadIdentityAudience}, cred.Spec.Audiences)
+	require.NotNil(t, cred.Spec.Subject)
+	assert.Equal(t, "system:serviceaccount:payments:api", *cred.Spec.Subject)
+}
+
+func TestFederatedIdentityCredential_JSON(t *testing.T) {
+	cred := NewFederatedIdentityCredential("app-fic", "aso-system", "app-identity").
+		WithIssuer("https://oidc.example.com/issuer").
+		WithServiceAccount("default", "app")
+
+	data, err := json.Marshal(cred)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "managedidentity.azure.com/v1", result["apiVersion"])
+	assert.Equal(t, "FederatedIdentityCredential", result["kind"])
+	spec := result["spec"].(map[string]interface{})
+	assert.Equal(t, "https://oidc.example.com/issuer", spec["issuer"])
+	assert.Equal(t, "system:serviceaccount:default:app", spec["subject"])
+	assert.Equal(t, []interface{}{WorkloadIdentityAudience}, spec["audiences"])
+}
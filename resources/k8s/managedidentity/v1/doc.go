@@ -20,4 +20,12 @@
 //			Owner:    &identityv1.ResourceGroupReference{Name: "my-rg"},
 //		},
 //	}
+//
+// Builders reduce the boilerplate for AKS workload identity:
+//
+//	var AppIdentity = identityv1.NewUserAssignedIdentity("app-identity", "aso-system", "eastus", "my-rg")
+//
+//	var AppCredential = identityv1.NewFederatedIdentityCredential("app-fic", "aso-system", "app-identity").
+//		WithIssuer(oidcIssuerURL).
+//		WithServiceAccount("default", "app")
 package v1
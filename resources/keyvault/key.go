@@ -0,0 +1,100 @@
+package keyvault
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Key represents a Microsoft.KeyVault/vaults/keys resource: a key created
+// in a vault, such as the key encrypting a storage account or the disks of a
+// Disk Encryption Set
+type Key struct {
+	// Name is the name of the key, in the form "<vault>/<key>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the key
+	Properties KeyProperties `json:"properties"`
+}
+
+// KeyProperties represents the properties of a Key Vault key
+type KeyProperties struct {
+	// Kty is the key type (RSA, RSA-HSM, EC, EC-HSM); HSM types need a
+	// premium vault
+	Kty string `json:"kty"`
+
+	// KeySize is the size of an RSA key in bits (2048, 3072, 4096)
+	KeySize *int `json:"keySize,omitempty"`
+
+	// CurveName is the elliptic curve of an EC key (P-256, P-384, P-521, P-256K)
+	CurveName *string `json:"curveName,omitempty"`
+
+	// KeyOps are the operations the key may be used for (encrypt, decrypt,
+	// sign, verify, wrapKey, unwrapKey); all operations if empty
+	KeyOps []string `json:"keyOps,omitempty"`
+
+	// Attributes are the key's enabled state and validity period
+	Attributes *KeyAttributes `json:"attributes,omitempty"`
+}
+
+// KeyAttributes represents the attributes of a Key Vault key
+type KeyAttributes struct {
+	// Enabled indicates whether the key can be used
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// NotBefore is the time, in seconds since the Unix epoch, before which
+	// the key cannot be used
+	NotBefore *int64 `json:"nbf,omitempty"`
+
+	// Expires is the time, in seconds since the Unix epoch, after which the
+	// key cannot be used
+	Expires *int64 `json:"exp,omitempty"`
+}
+
+// NewKey creates an RSA key of keySize bits in the named vault, usable to
+// wrap and unwrap keys as customer-managed keys need
+func NewKey(vaultName, name string, keySize int) *Key {
+	return &Key{
+		Name:       vaultName + "/" + name,
+		Type:       "Microsoft.KeyVault/vaults/keys",
+		APIVersion: apiVersion,
+		Properties: KeyProperties{
+			Kty:     "RSA",
+			KeySize: &keySize,
+			KeyOps:  []string{"wrapKey", "unwrapKey"},
+		},
+	}
+}
+
+// WithKeyOps sets the operations the key may be used for
+func (k *Key) WithKeyOps(ops ...string) *Key {
+	k.Properties.KeyOps = ops
+	return k
+}
+
+// WithTags adds tags to the key
+func (k *Key) WithTags(tags map[string]string) *Key {
+	k.Tags = tags
+	return k
+}
+
+// ID returns the ARM resourceId expression for the key
+func (k *Key) ID() string {
+	vault, name, _ := strings.Cut(k.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.KeyVault/vaults/keys', '%s', '%s')]", vault, name)
+}
+
+// KeyURIWithVersion returns an ARM expression for the versioned URI of the
+// key, as a Disk Encryption Set's active key needs
+func (k *Key) KeyURIWithVersion() string {
+	vault, name, _ := strings.Cut(k.Name, "/")
+	return fmt.Sprintf("[reference(resourceId('Microsoft.KeyVault/vaults/keys', '%s', '%s'), '%s').keyUriWithVersion]", vault, name, apiVersion)
+}
@@ -0,0 +1,81 @@
+package keyvault
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVault(t *testing.T) {
+	v := NewVault("corp-kv", "eastus").
+		WithTags(map[string]string{"env": "prod"}).
+		WithPurgeProtection()
+
+	assert.Equal(t, "corp-kv", v.Name)
+	assert.Equal(t, "Microsoft.KeyVault/vaults", v.Type)
+	assert.Equal(t, "2021-06-01", v.APIVersion)
+	assert.Equal(t, "eastus", v.Location)
+	assert.Equal(t, "prod", v.Tags["env"])
+	assert.Equal(t, "[subscription().tenantId]", v.Properties.TenantID)
+	assert.Equal(t, SKU{Family: "A", Name: "standard"}, v.Properties.SKU)
+	assert.True(t, v.Properties.EnableRBACAuthorization)
+	require.NotNil(t, v.Properties.EnablePurgeProtection)
+	assert.True(t, *v.Properties.EnablePurgeProtection)
+	assert.True(t, *v.Properties.EnableSoftDelete)
+	assert.Equal(t, "[resourceId('Microsoft.KeyVault/vaults', 'corp-kv')]", v.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.KeyVault/vaults', 'corp-kv'), '2021-06-01').vaultUri]", v.VaultURI())
+}
+
+func TestNewKey(t *testing.T) {
+	k := NewKey("corp-kv", "storage-key", 3072)
+
+	assert.Equal(t, "corp-kv/storage-key", k.Name)
+	assert.Equal(t, "Microsoft.KeyVault/vaults/keys", k.Type)
+	assert.Equal(t, "2021-06-01", k.APIVersion)
+	assert.Equal(t, "RSA", k.Properties.Kty)
+	require.NotNil(t, k.Properties.KeySize)
+	assert.Equal(t, 3072, *k.Properties.KeySize)
+	assert.Equal(t, []string{"wrapKey", "unwrapKey"}, k.Properties.KeyOps)
+	assert.Equal(t, "[resourceId('Microsoft.KeyVault/vaults/keys', 'corp-kv', 'storage-key')]", k.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.KeyVault/vaults/keys', 'corp-kv', 'storage-key'), '2021-06-01').keyUriWithVersion]", k.KeyURIWithVersion())
+
+	k.WithKeyOps("sign", "verify")
+	assert.Equal(t, []string{"sign", "verify"}, k.Properties.KeyOps)
+}
+
+func TestKey_JSON(t *testing.T) {
+	data, err := json.Marshal(NewKey("corp-kv", "storage-key", 2048))
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.NotContains(t, result, "location")
+	assert.Equal(t, map[string]interface{}{
+		"kty":     "RSA",
+		"keySize": float64(2048),
+		"keyOps":  []interface{}{"wrapKey", "unwrapKey"},
+	}, result["properties"])
+}
+
+func TestNewManagedHSM(t *testing.T) {
+	h := NewManagedHSM("corp-hsm", "eastus", "00000000-0000-0000-0000-000000000001").WithPurgeProtection()
+
+	assert.Equal(t, "Microsoft.KeyVault/managedHSMs", h.Type)
+	assert.Equal(t, "2021-10-01", h.APIVersion)
+	assert.Equal(t, ManagedHSMSKU{Family: "B", Name: "Standard_B1"}, h.SKU)
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000001"}, h.Properties.InitialAdminObjectIDs)
+	assert.True(t, *h.Properties.EnablePurgeProtection)
+	assert.Equal(t, "[resourceId('Microsoft.KeyVault/managedHSMs', 'corp-hsm')]", h.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.KeyVault/managedHSMs', 'corp-hsm'), '2021-10-01').hsmUri]", h.HSMURI())
+
+	data, err := json.Marshal(h)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, map[string]interface{}{"family": "B", "name": "Standard_B1"}, result["sku"])
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"00000000-0000-0000-0000-000000000001"}, props["initialAdminObjectIds"])
+}
@@ -0,0 +1,104 @@
+package keyvault
+
+import "fmt"
+
+// managedHSMAPIVersion is the API version of Managed HSM pools
+const managedHSMAPIVersion = "2021-10-01"
+
+// ManagedHSM represents a Microsoft.KeyVault/managedHSMs resource: a
+// single-tenant, FIPS 140-2 Level 3 HSM pool for keys
+type ManagedHSM struct {
+	// Name is the name of the HSM pool
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the HSM pool will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// SKU is the pricing tier of the HSM pool
+	SKU ManagedHSMSKU `json:"sku"`
+
+	// Properties contains the properties of the HSM pool
+	Properties ManagedHSMProperties `json:"properties"`
+}
+
+// ManagedHSMSKU represents the SKU of a Managed HSM pool
+type ManagedHSMSKU struct {
+	// Family is the SKU family (B)
+	Family string `json:"family"`
+
+	// Name is the SKU name (Standard_B1, Custom_B32)
+	Name string `json:"name"`
+}
+
+// ManagedHSMProperties represents the properties of a Managed HSM pool
+type ManagedHSMProperties struct {
+	// TenantID is the Microsoft Entra tenant that authenticates requests to the pool
+	TenantID string `json:"tenantId"`
+
+	// InitialAdminObjectIDs are the object IDs of the pool's first
+	// administrators, who activate it by downloading its security domain
+	InitialAdminObjectIDs []string `json:"initialAdminObjectIds"`
+
+	// EnableSoftDelete keeps deleted keys recoverable; it cannot be disabled
+	EnableSoftDelete *bool `json:"enableSoftDelete,omitempty"`
+
+	// SoftDeleteRetentionInDays is how long deleted keys are kept (7-90)
+	SoftDeleteRetentionInDays *int `json:"softDeleteRetentionInDays,omitempty"`
+
+	// EnablePurgeProtection prevents purging deleted keys during the
+	// retention period
+	EnablePurgeProtection *bool `json:"enablePurgeProtection,omitempty"`
+
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+}
+
+// NewManagedHSM creates a Standard_B1 Managed HSM pool in the deploying
+// subscription's tenant, administered by the principals adminObjectIDs
+func NewManagedHSM(name, location string, adminObjectIDs ...string) *ManagedHSM {
+	return &ManagedHSM{
+		Name:       name,
+		Type:       "Microsoft.KeyVault/managedHSMs",
+		APIVersion: managedHSMAPIVersion,
+		Location:   location,
+		SKU:        ManagedHSMSKU{Family: "B", Name: "Standard_B1"},
+		Properties: ManagedHSMProperties{
+			TenantID:              "[subscription().tenantId]",
+			InitialAdminObjectIDs: adminObjectIDs,
+		},
+	}
+}
+
+// WithPurgeProtection enables purge protection, which customer-managed keys
+// require
+func (h *ManagedHSM) WithPurgeProtection() *ManagedHSM {
+	enabled := true
+	h.Properties.EnablePurgeProtection = &enabled
+	return h
+}
+
+// WithTags adds tags to the HSM pool
+func (h *ManagedHSM) WithTags(tags map[string]string) *ManagedHSM {
+	h.Tags = tags
+	return h
+}
+
+// ID returns the ARM resourceId expression for the HSM pool
+func (h *ManagedHSM) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.KeyVault/managedHSMs', '%s')]", h.Name)
+}
+
+// HSMURI returns an ARM expression for the URI of the HSM pool (e.g.
+// https://<name>.managedhsm.azure.net/)
+func (h *ManagedHSM) HSMURI() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.KeyVault/managedHSMs', '%s'), '%s').hsmUri]", h.Name, managedHSMAPIVersion)
+}
@@ -0,0 +1,14 @@
+package keyvault
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/keyvault"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "Vault", "Microsoft.KeyVault/vaults")
+	template.RegisterResourceType(importPath, "Key", "Microsoft.KeyVault/vaults/keys")
+	template.RegisterResourceType(importPath, "ManagedHSM", "Microsoft.KeyVault/managedHSMs")
+}
@@ -0,0 +1,137 @@
+// Package keyvault provides Azure Key Vault resource types
+package keyvault
+
+import "fmt"
+
+// apiVersion is the API version of the Key Vault resources
+const apiVersion = "2021-06-01"
+
+// Vault represents a Microsoft.KeyVault/vaults resource
+type Vault struct {
+	// Name is the name of the vault (3-24 characters, globally unique)
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the vault will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the vault
+	Properties VaultProperties `json:"properties"`
+}
+
+// VaultProperties represents the properties of a Key Vault
+type VaultProperties struct {
+	// TenantID is the Microsoft Entra tenant that authenticates requests to the vault
+	TenantID string `json:"tenantId"`
+
+	// SKU is the pricing tier of the vault
+	SKU SKU `json:"sku"`
+
+	// EnableRBACAuthorization authorizes data plane access with Azure RBAC
+	// role assignments, ignoring AccessPolicies
+	EnableRBACAuthorization bool `json:"enableRbacAuthorization"`
+
+	// AccessPolicies grant principals data plane permissions when RBAC
+	// authorization is disabled
+	AccessPolicies []AccessPolicyEntry `json:"accessPolicies"`
+
+	// EnableSoftDelete keeps deleted vaults and objects recoverable
+	EnableSoftDelete *bool `json:"enableSoftDelete,omitempty"`
+
+	// SoftDeleteRetentionInDays is how long deleted objects are kept (7-90)
+	SoftDeleteRetentionInDays *int `json:"softDeleteRetentionInDays,omitempty"`
+
+	// EnablePurgeProtection prevents purging deleted vaults and objects
+	// during the retention period; required for customer-managed keys
+	EnablePurgeProtection *bool `json:"enablePurgeProtection,omitempty"`
+
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+}
+
+// SKU represents the SKU of a Key Vault
+type SKU struct {
+	// Family is the SKU family (A)
+	Family string `json:"family"`
+
+	// Name is the SKU name (standard, premium); premium supports HSM-protected keys
+	Name string `json:"name"`
+}
+
+// AccessPolicyEntry grants a principal permissions on the vault's data
+type AccessPolicyEntry struct {
+	// TenantID is the tenant of the principal; the vault's tenant if empty
+	TenantID string `json:"tenantId,omitempty"`
+
+	// ObjectID is the object ID of the user, group, or service principal
+	ObjectID string `json:"objectId"`
+
+	// Permissions are the operations the principal may perform
+	Permissions Permissions `json:"permissions"`
+}
+
+// Permissions lists the operations granted on each kind of vault object
+// (e.g. get, list, wrapKey, unwrapKey)
+type Permissions struct {
+	// Keys are the permissions on keys
+	Keys []string `json:"keys,omitempty"`
+
+	// Secrets are the permissions on secrets
+	Secrets []string `json:"secrets,omitempty"`
+
+	// Certificates are the permissions on certificates
+	Certificates []string `json:"certificates,omitempty"`
+
+	// Storage are the permissions on managed storage accounts
+	Storage []string `json:"storage,omitempty"`
+}
+
+// NewVault creates a standard Key Vault in the deploying subscription's
+// tenant, authorizing access with Azure RBAC
+func NewVault(name, location string) *Vault {
+	return &Vault{
+		Name:       name,
+		Type:       "Microsoft.KeyVault/vaults",
+		APIVersion: apiVersion,
+		Location:   location,
+		Properties: VaultProperties{
+			TenantID:                "[subscription().tenantId]",
+			SKU:                     SKU{Family: "A", Name: "standard"},
+			EnableRBACAuthorization: true,
+		},
+	}
+}
+
+// WithTags adds tags to the vault
+func (v *Vault) WithTags(tags map[string]string) *Vault {
+	v.Tags = tags
+	return v
+}
+
+// WithPurgeProtection enables soft delete and purge protection, which
+// customer-managed keys require
+func (v *Vault) WithPurgeProtection() *Vault {
+	enabled := true
+	v.Properties.EnableSoftDelete = &enabled
+	v.Properties.EnablePurgeProtection = &enabled
+	return v
+}
+
+// ID returns the ARM resourceId expression for the vault
+func (v *Vault) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.KeyVault/vaults', '%s')]", v.Name)
+}
+
+// VaultURI returns an ARM expression for the URI of the vault (e.g.
+// https://<name>.vault.azure.net/), as used by customer-managed keys
+func (v *Vault) VaultURI() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.KeyVault/vaults', '%s'), '%s').vaultUri]", v.Name, apiVersion)
+}
@@ -0,0 +1,117 @@
+// Package logic provides Azure Logic Apps resource types
+package logic
+
+// Workflow represents a Microsoft.Logic/workflows resource
+type Workflow struct {
+	// Name is the name of the workflow
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the workflow will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the workflow
+	Properties WorkflowProperties `json:"properties"`
+
+	// Identity defines the managed identity configuration for the workflow
+	Identity *Identity `json:"identity,omitempty"`
+}
+
+// WorkflowProperties represents the properties of a workflow
+type WorkflowProperties struct {
+	// State is the workflow state (Enabled, Disabled, Deleted, Suspended)
+	State *string `json:"state,omitempty"`
+
+	// Definition is the workflow definition in Workflow Definition Language JSON
+	// (with $schema, contentVersion, triggers, actions, outputs, ...)
+	Definition map[string]interface{} `json:"definition,omitempty"`
+
+	// Parameters are the values for the parameters declared by the definition
+	Parameters map[string]WorkflowParameter `json:"parameters,omitempty"`
+
+	// IntegrationAccount is the integration account used by the workflow
+	IntegrationAccount *ResourceReference `json:"integrationAccount,omitempty"`
+}
+
+// WorkflowParameter represents a workflow parameter value
+type WorkflowParameter struct {
+	// Type is the parameter type (String, SecureString, Int, Float, Bool, Array, Object, SecureObject)
+	Type *string `json:"type,omitempty"`
+
+	// Value is the value of the parameter
+	Value interface{} `json:"value"`
+}
+
+// ResourceReference represents a reference to another resource
+type ResourceReference struct {
+	// ID is the resource ID
+	ID string `json:"id"`
+}
+
+// Identity represents the identity configuration
+type Identity struct {
+	// Type is the identity type (SystemAssigned, UserAssigned, None)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// NewWorkflow creates a new enabled workflow with the given definition
+func NewWorkflow(name, location string, definition map[string]interface{}) *Workflow {
+	state := "Enabled"
+	return &Workflow{
+		Name:       name,
+		Type:       "Microsoft.Logic/workflows",
+		APIVersion: "2019-05-01",
+		Location:   location,
+		Properties: WorkflowProperties{
+			State:      &state,
+			Definition: definition,
+		},
+	}
+}
+
+// WithTags adds tags to the workflow
+func (w *Workflow) WithTags(tags map[string]string) *Workflow {
+	w.Tags = tags
+	return w
+}
+
+// WithState sets the workflow state (Enabled or Disabled)
+func (w *Workflow) WithState(state string) *Workflow {
+	w.Properties.State = &state
+	return w
+}
+
+// WithParameter sets the value of a workflow parameter
+func (w *Workflow) WithParameter(name string, value interface{}) *Workflow {
+	if w.Properties.Parameters == nil {
+		w.Properties.Parameters = make(map[string]WorkflowParameter)
+	}
+	w.Properties.Parameters[name] = WorkflowParameter{Value: value}
+	return w
+}
+
+// WithSystemAssignedIdentity enables a system-assigned managed identity
+func (w *Workflow) WithSystemAssignedIdentity() *Workflow {
+	w.Identity = &Identity{Type: "SystemAssigned"}
+	return w
+}
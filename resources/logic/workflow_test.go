@@ -0,0 +1,69 @@
+// Package logic provides Azure Logic Apps resource types
+package logic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recurrenceDefinition() map[string]interface{} {
+	return map[string]interface{}{
+		"$schema":        "https://schema.management.azure.com/providers/Microsoft.Logic/schemas/2016-06-01/workflowdefinition.json#",
+		"contentVersion": "1.0.0.0",
+		"triggers": map[string]interface{}{
+			"hourly": map[string]interface{}{
+				"type": "Recurrence",
+				"recurrence": map[string]interface{}{
+					"frequency": "Hour",
+					"interval":  1,
+				},
+			},
+		},
+		"actions": map[string]interface{}{},
+	}
+}
+
+func TestNewWorkflow(t *testing.T) {
+	wf := NewWorkflow("my-workflow", "eastus", recurrenceDefinition())
+
+	assert.Equal(t, "my-workflow", wf.Name)
+	assert.Equal(t, "Microsoft.Logic/workflows", wf.Type)
+	assert.Equal(t, "2019-05-01", wf.APIVersion)
+	assert.Equal(t, "eastus", wf.Location)
+	require.NotNil(t, wf.Properties.State)
+	assert.Equal(t, "Enabled", *wf.Properties.State)
+	assert.Contains(t, wf.Properties.Definition, "triggers")
+}
+
+func TestWorkflow_With(t *testing.T) {
+	wf := NewWorkflow("my-workflow", "eastus", recurrenceDefinition()).
+		WithTags(map[string]string{"env": "prod"}).
+		WithState("Disabled").
+		WithParameter("endpoint", "https://example.com").
+		WithSystemAssignedIdentity()
+
+	assert.Equal(t, "prod", wf.Tags["env"])
+	assert.Equal(t, "Disabled", *wf.Properties.State)
+	assert.Equal(t, "https://example.com", wf.Properties.Parameters["endpoint"].Value)
+	require.NotNil(t, wf.Identity)
+	assert.Equal(t, "SystemAssigned", wf.Identity.Type)
+}
+
+func TestWorkflow_JSON(t *testing.T) {
+	wf := NewWorkflow("my-workflow", "eastus", recurrenceDefinition())
+
+	data, err := json.Marshal(wf)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "Microsoft.Logic/workflows", result["type"])
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "Enabled", props["state"])
+	definition := props["definition"].(map[string]interface{})
+	assert.Equal(t, "1.0.0.0", definition["contentVersion"])
+}
@@ -0,0 +1,106 @@
+// Package managedidentity provides Azure Managed Identity resource types.
+// Unlike the Azure Service Operator CRDs in resources/k8s/managedidentity,
+// these types are deployed through ARM templates.
+package managedidentity
+
+import "fmt"
+
+// UserAssignedIdentity represents a Microsoft.ManagedIdentity/userAssignedIdentities resource
+type UserAssignedIdentity struct {
+	// Name is the name of the identity
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the identity will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// FederatedCredential represents a
+// Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials resource
+type FederatedCredential struct {
+	// Name is the name of the credential, in the form "<identity>/<credential>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the federated credential
+	Properties FederatedCredentialProperties `json:"properties"`
+}
+
+// FederatedCredentialProperties represents the properties of a federated identity credential
+type FederatedCredentialProperties struct {
+	// Issuer is the URL of the external identity provider (e.g. an AKS OIDC issuer)
+	Issuer string `json:"issuer"`
+
+	// Subject is the identifier of the external identity
+	// (e.g. system:serviceaccount:<namespace>:<name>)
+	Subject string `json:"subject"`
+
+	// Audiences are the audiences that can appear in the external token
+	Audiences []string `json:"audiences"`
+}
+
+// NewUserAssignedIdentity creates a new user-assigned identity with required fields
+func NewUserAssignedIdentity(name, location string) *UserAssignedIdentity {
+	return &UserAssignedIdentity{
+		Name:       name,
+		Type:       "Microsoft.ManagedIdentity/userAssignedIdentities",
+		APIVersion: "2023-01-31",
+		Location:   location,
+	}
+}
+
+// WithTags adds tags to the identity
+func (i *UserAssignedIdentity) WithTags(tags map[string]string) *UserAssignedIdentity {
+	i.Tags = tags
+	return i
+}
+
+// ID returns the ARM resourceId expression for the identity
+func (i *UserAssignedIdentity) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', '%s')]", i.Name)
+}
+
+// PrincipalID returns an ARM expression for the identity's service principal ID,
+// as used by role assignments
+func (i *UserAssignedIdentity) PrincipalID() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', '%s'), '2023-01-31').principalId]", i.Name)
+}
+
+// ClientID returns an ARM expression for the identity's application (client) ID
+func (i *UserAssignedIdentity) ClientID() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', '%s'), '2023-01-31').clientId]", i.Name)
+}
+
+// NewFederatedCredential creates a federated credential under the named identity
+// that trusts tokens from issuer for subject, with the Azure AD token exchange audience
+func NewFederatedCredential(identityName, name, issuer, subject string) *FederatedCredential {
+	return &FederatedCredential{
+		Name:       identityName + "/" + name,
+		Type:       "Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials",
+		APIVersion: "2023-01-31",
+		Properties: FederatedCredentialProperties{
+			Issuer:    issuer,
+			Subject:   subject,
+			Audiences: []string{"api://AzureADTokenExchange"},
+		},
+	}
+}
+
+// WithAudiences replaces the audiences accepted by the credential
+func (c *FederatedCredential) WithAudiences(audiences ...string) *FederatedCredential {
+	c.Properties.Audiences = audiences
+	return c
+}
@@ -0,0 +1,54 @@
+// Package managedidentity provides Azure Managed Identity resource types
+package managedidentity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUserAssignedIdentity(t *testing.T) {
+	id := NewUserAssignedIdentity("app-identity", "eastus").
+		WithTags(map[string]string{"env": "prod"})
+
+	assert.Equal(t, "app-identity", id.Name)
+	assert.Equal(t, "Microsoft.ManagedIdentity/userAssignedIdentities", id.Type)
+	assert.Equal(t, "2023-01-31", id.APIVersion)
+	assert.Equal(t, "eastus", id.Location)
+	assert.Equal(t, "prod", id.Tags["env"])
+}
+
+func TestUserAssignedIdentity_References(t *testing.T) {
+	id := NewUserAssignedIdentity("app-identity", "eastus")
+
+	assert.Equal(t, "[resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', 'app-identity')]", id.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', 'app-identity'), '2023-01-31').principalId]", id.PrincipalID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', 'app-identity'), '2023-01-31').clientId]", id.ClientID())
+}
+
+func TestNewFederatedCredential(t *testing.T) {
+	cred := NewFederatedCredential("app-identity", "aks", "https://oidc.example.com/", "system:serviceaccount:default:app")
+
+	assert.Equal(t, "app-identity/aks", cred.Name)
+	assert.Equal(t, "Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials", cred.Type)
+	assert.Equal(t, "https://oidc.example.com/", cred.Properties.Issuer)
+	assert.Equal(t, "system:serviceaccount:default:app", cred.Properties.Subject)
+	assert.Equal(t, []string{"api://AzureADTokenExchange"}, cred.Properties.Audiences)
+
+	cred.WithAudiences("api://custom")
+	assert.Equal(t, []string{"api://custom"}, cred.Properties.Audiences)
+}
+
+func TestUserAssignedIdentity_JSON(t *testing.T) {
+	data, err := json.Marshal(NewUserAssignedIdentity("app-identity", "eastus"))
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "Microsoft.ManagedIdentity/userAssignedIdentities", result["type"])
+	assert.NotContains(t, result, "tags")
+	assert.NotContains(t, result, "properties")
+}
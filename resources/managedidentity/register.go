@@ -0,0 +1,13 @@
+package managedidentity
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/managedidentity"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "UserAssignedIdentity", "Microsoft.ManagedIdentity/userAssignedIdentities")
+	template.RegisterResourceType(importPath, "FederatedCredential", "Microsoft.ManagedIdentity/userAssignedIdentities/federatedIdentityCredentials")
+}
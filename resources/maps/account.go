@@ -0,0 +1,70 @@
+// Package maps provides Azure Maps resource types
+package maps
+
+import "fmt"
+
+// Account represents a Microsoft.Maps/accounts resource: an Azure Maps
+// account for map rendering, search, routing, and geolocation APIs
+type Account struct {
+	// Name is the name of the account
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the account's data is stored
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Kind is the account generation (Gen2)
+	Kind string `json:"kind,omitempty"`
+
+	// SKU defines the pricing tier of the account (G2)
+	SKU SKU `json:"sku"`
+
+	// Properties contains the properties of the account
+	Properties AccountProperties `json:"properties"`
+}
+
+// SKU represents the SKU of an Azure Maps account
+type SKU struct {
+	// Name is the SKU name (G2)
+	Name string `json:"name"`
+}
+
+// AccountProperties represents the properties of an Azure Maps account
+type AccountProperties struct {
+	// DisableLocalAuth disables shared key and SAS authentication, leaving
+	// Microsoft Entra ID
+	DisableLocalAuth *bool `json:"disableLocalAuth,omitempty"`
+}
+
+// NewAccount creates a Gen2 Azure Maps account
+func NewAccount(name, location string) *Account {
+	return &Account{
+		Name:       name,
+		Type:       "Microsoft.Maps/accounts",
+		APIVersion: "2023-06-01",
+		Location:   location,
+		Kind:       "Gen2",
+		SKU: SKU{
+			Name: "G2",
+		},
+	}
+}
+
+// WithTags adds tags to the account
+func (a *Account) WithTags(tags map[string]string) *Account {
+	a.Tags = tags
+	return a
+}
+
+// ID returns the ARM resourceId expression for the account
+func (a *Account) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Maps/accounts', '%s')]", a.Name)
+}
@@ -0,0 +1,29 @@
+package maps
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccount(t *testing.T) {
+	a := NewAccount("fleet-maps", "eastus").WithTags(map[string]string{"env": "prod"})
+
+	assert.Equal(t, "fleet-maps", a.Name)
+	assert.Equal(t, "Microsoft.Maps/accounts", a.Type)
+	assert.Equal(t, "2023-06-01", a.APIVersion)
+	assert.Equal(t, "eastus", a.Location)
+	assert.Equal(t, "Gen2", a.Kind)
+	assert.Equal(t, "G2", a.SKU.Name)
+	assert.Equal(t, "prod", a.Tags["env"])
+	assert.Equal(t, "[resourceId('Microsoft.Maps/accounts', 'fleet-maps')]", a.ID())
+
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "Gen2", result["kind"])
+	assert.Equal(t, map[string]interface{}{"name": "G2"}, result["sku"])
+}
@@ -0,0 +1,71 @@
+package network
+
+import "fmt"
+
+// ApplicationSecurityGroup represents a Microsoft.Network/applicationSecurityGroups resource.
+// Network interfaces join the group through their IP configurations, and
+// security rules match its members instead of IP ranges.
+type ApplicationSecurityGroup struct {
+	// Name is the name of the application security group
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region, which must match the network interfaces in the group
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the application security group
+	Properties ApplicationSecurityGroupProperties `json:"properties"`
+}
+
+// ApplicationSecurityGroupProperties represents the properties of an application security group
+type ApplicationSecurityGroupProperties struct{}
+
+// NewApplicationSecurityGroup creates a new application security group
+func NewApplicationSecurityGroup(name, location string) *ApplicationSecurityGroup {
+	return &ApplicationSecurityGroup{
+		Name:       name,
+		Type:       "Microsoft.Network/applicationSecurityGroups",
+		APIVersion: "2021-05-01",
+		Location:   location,
+		Properties: ApplicationSecurityGroupProperties{},
+	}
+}
+
+// WithTags adds tags to the application security group
+func (a *ApplicationSecurityGroup) WithTags(tags map[string]string) *ApplicationSecurityGroup {
+	a.Tags = tags
+	return a
+}
+
+// ID returns the ARM resourceId expression for the application security group
+func (a *ApplicationSecurityGroup) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/applicationSecurityGroups', '%s')]", a.Name)
+}
+
+// WithApplicationSecurityGroupRule adds a security rule from the members of
+// the source application security group to those of the destination group on
+// dstPort. The groups are given by ID, e.g. webASG.ID().
+func (n *NetworkSecurityGroup) WithApplicationSecurityGroupRule(name string, priority int, direction, access, protocol, dstPort, srcASGID, dstASGID string) *NetworkSecurityGroup {
+	n.Properties.SecurityRules = append(n.Properties.SecurityRules, SecurityRule{
+		Name: name,
+		Properties: SecurityRuleProperties{
+			Priority:                             priority,
+			Direction:                            direction,
+			Access:                               access,
+			Protocol:                             protocol,
+			SourcePortRange:                      "*",
+			DestinationPortRange:                 dstPort,
+			SourceApplicationSecurityGroups:      []SubResource{*NewSubResource(srcASGID)},
+			DestinationApplicationSecurityGroups: []SubResource{*NewSubResource(dstASGID)},
+		},
+	})
+	return n
+}
@@ -0,0 +1,212 @@
+package network
+
+import "fmt"
+
+// FrontDoorWebApplicationFirewallPolicy represents a
+// Microsoft.Network/FrontDoorWebApplicationFirewallPolicies resource. Front
+// Door security policies attach it to endpoints by ID.
+type FrontDoorWebApplicationFirewallPolicy struct {
+	// Name is the name of the policy (letters and digits only, starting with a letter)
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is always "Global"; Front Door policies are not regional
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// SKU is the Front Door tier the policy is used with
+	SKU FrontDoorWAFSKU `json:"sku"`
+
+	// Properties contains the properties of the policy
+	Properties FrontDoorWAFPolicyProperties `json:"properties"`
+}
+
+// FrontDoorWAFSKU represents the SKU of a Front Door WAF policy
+type FrontDoorWAFSKU struct {
+	// Name is the SKU name (Classic_AzureFrontDoor, Standard_AzureFrontDoor, Premium_AzureFrontDoor);
+	// managed rule sets require Premium_AzureFrontDoor
+	Name string `json:"name"`
+}
+
+// FrontDoorWAFPolicyProperties represents the properties of a Front Door WAF policy
+type FrontDoorWAFPolicyProperties struct {
+	// PolicySettings holds the mode and state of the policy
+	PolicySettings FrontDoorWAFPolicySettings `json:"policySettings"`
+
+	// CustomRules are evaluated before the managed rules
+	CustomRules *FrontDoorCustomRuleList `json:"customRules,omitempty"`
+
+	// ManagedRules are the managed rule sets, such as Microsoft_DefaultRuleSet
+	ManagedRules *FrontDoorManagedRuleSetList `json:"managedRules,omitempty"`
+}
+
+// FrontDoorWAFPolicySettings represents the settings of a Front Door WAF policy
+type FrontDoorWAFPolicySettings struct {
+	// EnabledState is Enabled or Disabled
+	EnabledState string `json:"enabledState"`
+
+	// Mode is Prevention, which blocks matching requests, or Detection, which only logs them
+	Mode string `json:"mode"`
+
+	// CustomBlockResponseStatusCode is the status code returned for blocked requests
+	CustomBlockResponseStatusCode *int `json:"customBlockResponseStatusCode,omitempty"`
+
+	// RedirectURL is the URL that Redirect actions send clients to
+	RedirectURL *string `json:"redirectUrl,omitempty"`
+}
+
+// FrontDoorCustomRuleList holds the custom rules of a policy
+type FrontDoorCustomRuleList struct {
+	// Rules are the custom rules, evaluated in priority order
+	Rules []FrontDoorCustomRule `json:"rules"`
+}
+
+// FrontDoorCustomRule represents a custom WAF rule
+type FrontDoorCustomRule struct {
+	// Name is the name of the rule, unique within the policy
+	Name string `json:"name"`
+
+	// Priority orders the rules; lower values are evaluated first
+	Priority int `json:"priority"`
+
+	// EnabledState is Enabled or Disabled
+	EnabledState string `json:"enabledState"`
+
+	// RuleType is MatchRule or RateLimitRule
+	RuleType string `json:"ruleType"`
+
+	// RateLimitDurationInMinutes is the window for rate limit rules (1 or 5)
+	RateLimitDurationInMinutes int `json:"rateLimitDurationInMinutes,omitempty"`
+
+	// RateLimitThreshold is the number of requests a client may make in the window
+	RateLimitThreshold int `json:"rateLimitThreshold,omitempty"`
+
+	// MatchConditions must all match for the rule to apply
+	MatchConditions []FrontDoorMatchCondition `json:"matchConditions"`
+
+	// Action is Allow, Block, Log, or Redirect
+	Action string `json:"action"`
+}
+
+// FrontDoorMatchCondition represents a condition of a custom WAF rule
+type FrontDoorMatchCondition struct {
+	// MatchVariable is the part of the request to inspect (e.g. RemoteAddr, RequestUri, RequestHeader)
+	MatchVariable string `json:"matchVariable"`
+
+	// Selector is the header, cookie, or argument name for variables that need one
+	Selector *string `json:"selector,omitempty"`
+
+	// Operator is the comparison (e.g. IPMatch, Contains, BeginsWith, GeoMatch, Any)
+	Operator string `json:"operator"`
+
+	// NegateCondition inverts the result of the comparison
+	NegateCondition bool `json:"negateCondition,omitempty"`
+
+	// MatchValue are the values to compare against
+	MatchValue []string `json:"matchValue"`
+
+	// Transforms are applied to the value before comparing (e.g. Lowercase, UrlDecode)
+	Transforms []string `json:"transforms,omitempty"`
+}
+
+// FrontDoorManagedRuleSetList holds the managed rule sets of a policy
+type FrontDoorManagedRuleSetList struct {
+	// ManagedRuleSets are the rule sets to apply
+	ManagedRuleSets []FrontDoorManagedRuleSet `json:"managedRuleSets"`
+}
+
+// FrontDoorManagedRuleSet represents a managed rule set
+type FrontDoorManagedRuleSet struct {
+	// RuleSetType is the rule set (e.g. Microsoft_DefaultRuleSet, Microsoft_BotManagerRuleSet)
+	RuleSetType string `json:"ruleSetType"`
+
+	// RuleSetVersion is the rule set version (e.g. 2.1)
+	RuleSetVersion string `json:"ruleSetVersion"`
+
+	// RuleSetAction is the action for matching requests (Block, Log, or Redirect)
+	RuleSetAction string `json:"ruleSetAction,omitempty"`
+}
+
+// NewFrontDoorWebApplicationFirewallPolicy creates an enabled WAF policy for
+// the Front Door tier skuName in the given mode (Prevention or Detection)
+func NewFrontDoorWebApplicationFirewallPolicy(name, skuName, mode string) *FrontDoorWebApplicationFirewallPolicy {
+	return &FrontDoorWebApplicationFirewallPolicy{
+		Name:       name,
+		Type:       "Microsoft.Network/FrontDoorWebApplicationFirewallPolicies",
+		APIVersion: "2022-05-01",
+		Location:   "Global",
+		SKU:        FrontDoorWAFSKU{Name: skuName},
+		Properties: FrontDoorWAFPolicyProperties{
+			PolicySettings: FrontDoorWAFPolicySettings{
+				EnabledState: "Enabled",
+				Mode:         mode,
+			},
+		},
+	}
+}
+
+// WithTags adds tags to the policy
+func (p *FrontDoorWebApplicationFirewallPolicy) WithTags(tags map[string]string) *FrontDoorWebApplicationFirewallPolicy {
+	p.Tags = tags
+	return p
+}
+
+// AddCustomRule adds an enabled custom rule
+func (p *FrontDoorWebApplicationFirewallPolicy) AddCustomRule(rule FrontDoorCustomRule) *FrontDoorWebApplicationFirewallPolicy {
+	if rule.EnabledState == "" {
+		rule.EnabledState = "Enabled"
+	}
+	if p.Properties.CustomRules == nil {
+		p.Properties.CustomRules = &FrontDoorCustomRuleList{}
+	}
+	p.Properties.CustomRules.Rules = append(p.Properties.CustomRules.Rules, rule)
+	return p
+}
+
+// AddRateLimitRule adds a rule that applies action (usually Block) to a
+// client IP that sends more than threshold matching requests in
+// durationInMinutes (1 or 5). Without conditions the rule counts every request.
+func (p *FrontDoorWebApplicationFirewallPolicy) AddRateLimitRule(name string, priority, threshold, durationInMinutes int, action string, conditions ...FrontDoorMatchCondition) *FrontDoorWebApplicationFirewallPolicy {
+	if len(conditions) == 0 {
+		conditions = []FrontDoorMatchCondition{{
+			MatchVariable: "RequestUri",
+			Operator:      "Any",
+			MatchValue:    []string{},
+		}}
+	}
+	return p.AddCustomRule(FrontDoorCustomRule{
+		Name:                       name,
+		Priority:                   priority,
+		RuleType:                   "RateLimitRule",
+		RateLimitDurationInMinutes: durationInMinutes,
+		RateLimitThreshold:         threshold,
+		MatchConditions:            conditions,
+		Action:                     action,
+	})
+}
+
+// AddManagedRuleSet applies a managed rule set, such as
+// ("Microsoft_DefaultRuleSet", "2.1", "Block")
+func (p *FrontDoorWebApplicationFirewallPolicy) AddManagedRuleSet(ruleSetType, ruleSetVersion, action string) *FrontDoorWebApplicationFirewallPolicy {
+	if p.Properties.ManagedRules == nil {
+		p.Properties.ManagedRules = &FrontDoorManagedRuleSetList{}
+	}
+	p.Properties.ManagedRules.ManagedRuleSets = append(p.Properties.ManagedRules.ManagedRuleSets, FrontDoorManagedRuleSet{
+		RuleSetType:    ruleSetType,
+		RuleSetVersion: ruleSetVersion,
+		RuleSetAction:  action,
+	})
+	return p
+}
+
+// ID returns the ARM resourceId expression for the policy
+func (p *FrontDoorWebApplicationFirewallPolicy) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/FrontDoorWebApplicationFirewallPolicies', '%s')]", p.Name)
+}
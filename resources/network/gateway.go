@@ -0,0 +1,203 @@
+package network
+
+import "fmt"
+
+// VirtualNetworkGateway represents a Microsoft.Network/virtualNetworkGateways resource
+type VirtualNetworkGateway struct {
+	// Name is the name of the gateway
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the resource will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the gateway
+	Properties VirtualNetworkGatewayProperties `json:"properties"`
+}
+
+// VirtualNetworkGatewayProperties represents the properties of a virtual network gateway
+type VirtualNetworkGatewayProperties struct {
+	// GatewayType is the kind of gateway (Vpn or ExpressRoute)
+	GatewayType string `json:"gatewayType"`
+
+	// VpnType is the routing type of a VPN gateway (RouteBased or PolicyBased)
+	VpnType *string `json:"vpnType,omitempty"`
+
+	// SKU is the gateway SKU
+	SKU VirtualNetworkGatewaySKU `json:"sku"`
+
+	// IPConfigurations attach the gateway to the GatewaySubnet and its public IP
+	IPConfigurations []IPConfiguration `json:"ipConfigurations"`
+
+	// EnableBgp enables BGP on a VPN gateway
+	EnableBgp *bool `json:"enableBgp,omitempty"`
+
+	// ActiveActive runs two active gateway instances; each needs its own IP configuration
+	ActiveActive *bool `json:"activeActive,omitempty"`
+}
+
+// VirtualNetworkGatewaySKU represents the SKU of a virtual network gateway
+type VirtualNetworkGatewaySKU struct {
+	// Name is the SKU name (e.g. VpnGw1, VpnGw2AZ, Standard, UltraPerformance, ErGw1AZ)
+	Name string `json:"name"`
+
+	// Tier is the SKU tier, the same as the name
+	Tier string `json:"tier"`
+}
+
+// ExpressRouteCircuit represents a Microsoft.Network/expressRouteCircuits resource
+type ExpressRouteCircuit struct {
+	// Name is the name of the circuit
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the resource will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// SKU is the circuit SKU
+	SKU ExpressRouteCircuitSKU `json:"sku"`
+
+	// Properties contains the properties of the circuit
+	Properties ExpressRouteCircuitProperties `json:"properties"`
+}
+
+// ExpressRouteCircuitSKU represents the SKU of an ExpressRoute circuit
+type ExpressRouteCircuitSKU struct {
+	// Name is the SKU name, in the form "<tier>_<family>" (e.g. Standard_MeteredData)
+	Name string `json:"name"`
+
+	// Tier is the SKU tier (Local, Standard, or Premium)
+	Tier string `json:"tier"`
+
+	// Family is the billing family (MeteredData or UnlimitedData)
+	Family string `json:"family"`
+}
+
+// ExpressRouteCircuitProperties represents the properties of an ExpressRoute circuit
+type ExpressRouteCircuitProperties struct {
+	// ServiceProviderProperties identify the connectivity provider and bandwidth
+	ServiceProviderProperties *ExpressRouteServiceProviderProperties `json:"serviceProviderProperties,omitempty"`
+
+	// AllowClassicOperations allows classic (ASM) virtual networks to use the circuit
+	AllowClassicOperations *bool `json:"allowClassicOperations,omitempty"`
+}
+
+// ExpressRouteServiceProviderProperties represents the provider of an ExpressRoute circuit
+type ExpressRouteServiceProviderProperties struct {
+	// ServiceProviderName is the connectivity provider (e.g. Equinix)
+	ServiceProviderName string `json:"serviceProviderName"`
+
+	// PeeringLocation is the peering location (e.g. Silicon Valley)
+	PeeringLocation string `json:"peeringLocation"`
+
+	// BandwidthInMbps is the circuit bandwidth
+	BandwidthInMbps int `json:"bandwidthInMbps"`
+}
+
+// NewVirtualNetworkGateway creates a gateway of gatewayType (Vpn or ExpressRoute)
+// in the GatewaySubnet identified by subnetID, using the public IP address
+// identified by publicIPID. VPN gateways are route-based.
+func NewVirtualNetworkGateway(name, location, gatewayType, skuName, subnetID, publicIPID string) *VirtualNetworkGateway {
+	allocation := "Dynamic"
+	gateway := &VirtualNetworkGateway{
+		Name:       name,
+		Type:       "Microsoft.Network/virtualNetworkGateways",
+		APIVersion: "2023-04-01",
+		Location:   location,
+		Properties: VirtualNetworkGatewayProperties{
+			GatewayType: gatewayType,
+			SKU: VirtualNetworkGatewaySKU{
+				Name: skuName,
+				Tier: skuName,
+			},
+			IPConfigurations: []IPConfiguration{{
+				Name: "default",
+				Properties: IPConfigurationProperties{
+					Subnet:                    NewSubResource(subnetID),
+					PublicIPAddress:           NewSubResource(publicIPID),
+					PrivateIPAllocationMethod: &allocation,
+				},
+			}},
+		},
+	}
+	if gatewayType == "Vpn" {
+		vpnType := "RouteBased"
+		gateway.Properties.VpnType = &vpnType
+	}
+	return gateway
+}
+
+// WithTags adds tags to the gateway
+func (g *VirtualNetworkGateway) WithTags(tags map[string]string) *VirtualNetworkGateway {
+	g.Tags = tags
+	return g
+}
+
+// WithVpnType sets the routing type of a VPN gateway (RouteBased or PolicyBased)
+func (g *VirtualNetworkGateway) WithVpnType(vpnType string) *VirtualNetworkGateway {
+	g.Properties.VpnType = &vpnType
+	return g
+}
+
+// WithBGP enables BGP on a VPN gateway
+func (g *VirtualNetworkGateway) WithBGP() *VirtualNetworkGateway {
+	enable := true
+	g.Properties.EnableBgp = &enable
+	return g
+}
+
+// ID returns the ARM resourceId expression for the gateway
+func (g *VirtualNetworkGateway) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/virtualNetworkGateways', '%s')]", g.Name)
+}
+
+// NewExpressRouteCircuit creates a circuit of bandwidthInMbps through the
+// connectivity provider at peeringLocation, billed as tier and family
+// (e.g. "Standard", "MeteredData")
+func NewExpressRouteCircuit(name, location, providerName, peeringLocation string, bandwidthInMbps int, tier, family string) *ExpressRouteCircuit {
+	return &ExpressRouteCircuit{
+		Name:       name,
+		Type:       "Microsoft.Network/expressRouteCircuits",
+		APIVersion: "2023-04-01",
+		Location:   location,
+		SKU: ExpressRouteCircuitSKU{
+			Name:   tier + "_" + family,
+			Tier:   tier,
+			Family: family,
+		},
+		Properties: ExpressRouteCircuitProperties{
+			ServiceProviderProperties: &ExpressRouteServiceProviderProperties{
+				ServiceProviderName: providerName,
+				PeeringLocation:     peeringLocation,
+				BandwidthInMbps:     bandwidthInMbps,
+			},
+		},
+	}
+}
+
+// WithTags adds tags to the circuit
+func (c *ExpressRouteCircuit) WithTags(tags map[string]string) *ExpressRouteCircuit {
+	c.Tags = tags
+	return c
+}
+
+// ID returns the ARM resourceId expression for the circuit
+func (c *ExpressRouteCircuit) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/expressRouteCircuits', '%s')]", c.Name)
+}
@@ -1,6 +1,8 @@
 // Package network provides Azure network resource types
 package network
 
+import "fmt"
+
 // VirtualNetwork represents a Microsoft.Network/virtualNetworks resource
 type VirtualNetwork struct {
 	// Name is the name of the virtual network
@@ -321,16 +323,37 @@ type SecurityRuleProperties struct {
 	// DestinationAddressPrefixes is the list of destination address prefixes
 	DestinationAddressPrefixes []string `json:"destinationAddressPrefixes,omitempty"`
 
+	// SourceApplicationSecurityGroups matches traffic from members of these
+	// application security groups, in place of a source address prefix
+	SourceApplicationSecurityGroups []SubResource `json:"sourceApplicationSecurityGroups,omitempty"`
+
+	// DestinationApplicationSecurityGroups matches traffic to members of these
+	// application security groups, in place of a destination address prefix
+	DestinationApplicationSecurityGroups []SubResource `json:"destinationApplicationSecurityGroups,omitempty"`
+
 	// Description is a description of the rule
 	Description *string `json:"description,omitempty"`
 }
 
+// Validate checks that the priority is in the range 100-4096
+func (p *SecurityRuleProperties) Validate() []error {
+	if p.Priority < 100 || p.Priority > 4096 {
+		return []error{fmt.Errorf("security rule priority %d is outside the range 100-4096", p.Priority)}
+	}
+	return nil
+}
+
 // SubResource represents a reference to another resource
 type SubResource struct {
 	// ID is the resource ID
 	ID *string `json:"id,omitempty"`
 }
 
+// NewSubResource creates a reference to the resource with the given ID
+func NewSubResource(id string) *SubResource {
+	return &SubResource{ID: &id}
+}
+
 // NewVirtualNetwork creates a new virtual network with required fields
 func NewVirtualNetwork(name, location string, addressPrefixes []string) *VirtualNetwork {
 	return &VirtualNetwork{
@@ -346,6 +369,16 @@ func NewVirtualNetwork(name, location string, addressPrefixes []string) *Virtual
 	}
 }
 
+// ID returns the ARM resourceId expression for the virtual network
+func (v *VirtualNetwork) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/virtualNetworks', '%s')]", v.Name)
+}
+
+// SubnetID returns the ARM resourceId expression for the named subnet of the virtual network
+func (v *VirtualNetwork) SubnetID(name string) string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/virtualNetworks/subnets', '%s', '%s')]", v.Name, name)
+}
+
 // WithTags adds tags to the virtual network
 func (v *VirtualNetwork) WithTags(tags map[string]string) *VirtualNetwork {
 	v.Tags = tags
@@ -490,6 +523,11 @@ func (p *PublicIPAddress) WithZones(zones []string) *PublicIPAddress {
 	return p
 }
 
+// ID returns the ARM resourceId expression for the public IP address
+func (p *PublicIPAddress) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/publicIPAddresses', '%s')]", p.Name)
+}
+
 // NewNetworkSecurityGroup creates a new network security group with required fields
 func NewNetworkSecurityGroup(name, location string) *NetworkSecurityGroup {
 	return &NetworkSecurityGroup{
@@ -501,6 +539,11 @@ func NewNetworkSecurityGroup(name, location string) *NetworkSecurityGroup {
 	}
 }
 
+// ID returns the ARM resourceId expression for the network security group
+func (n *NetworkSecurityGroup) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/networkSecurityGroups', '%s')]", n.Name)
+}
+
 // WithTags adds tags to the network security group
 func (n *NetworkSecurityGroup) WithTags(tags map[string]string) *NetworkSecurityGroup {
 	n.Tags = tags
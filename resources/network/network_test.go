@@ -168,3 +168,341 @@ func TestNetworkSecurityGroup_JSON(t *testing.T) {
 	rules := props["securityRules"].([]interface{})
 	assert.Len(t, rules, 2)
 }
+
+func TestNewNetworkWatcher(t *testing.T) {
+	watcher := NewNetworkWatcher("NetworkWatcher_eastus", "eastus").
+		WithTags(map[string]string{"env": "prod"})
+
+	assert.Equal(t, "NetworkWatcher_eastus", watcher.Name)
+	assert.Equal(t, "Microsoft.Network/networkWatchers", watcher.Type)
+	assert.Equal(t, "2021-05-01", watcher.APIVersion)
+	assert.Equal(t, "eastus", watcher.Location)
+	assert.Equal(t, "prod", watcher.Tags["env"])
+}
+
+func TestNewFlowLog(t *testing.T) {
+	nsgID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/networkSecurityGroups/web-nsg"
+	storageID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/flowlogs"
+
+	flowLog := NewFlowLog("NetworkWatcher_eastus", "web-nsg-flowlog", "eastus", nsgID, storageID).
+		WithRetention(90).
+		WithFormatVersion(2)
+
+	assert.Equal(t, "NetworkWatcher_eastus/web-nsg-flowlog", flowLog.Name)
+	assert.Equal(t, "Microsoft.Network/networkWatchers/flowLogs", flowLog.Type)
+	assert.Equal(t, "eastus", flowLog.Location)
+	assert.Equal(t, nsgID, flowLog.Properties.TargetResourceID)
+	assert.Equal(t, storageID, flowLog.Properties.StorageID)
+	require.NotNil(t, flowLog.Properties.Enabled)
+	assert.True(t, *flowLog.Properties.Enabled)
+	require.NotNil(t, flowLog.Properties.RetentionPolicy)
+	assert.Equal(t, 90, flowLog.Properties.RetentionPolicy.Days)
+	assert.True(t, flowLog.Properties.RetentionPolicy.Enabled)
+	require.NotNil(t, flowLog.Properties.Format)
+	assert.Equal(t, "JSON", flowLog.Properties.Format.Type)
+	assert.Equal(t, 2, *flowLog.Properties.Format.Version)
+}
+
+func TestFlowLog_JSON(t *testing.T) {
+	flowLog := NewFlowLog("watcher", "flowlog", "eastus", "nsg-id", "storage-id").WithRetention(30)
+
+	data, err := json.Marshal(flowLog)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "nsg-id", props["targetResourceId"])
+	assert.Equal(t, "storage-id", props["storageId"])
+	assert.Equal(t, true, props["enabled"])
+	assert.Equal(t, map[string]interface{}{"days": float64(30), "enabled": true}, props["retentionPolicy"])
+	assert.NotContains(t, props, "format")
+}
+
+func TestVirtualNetwork_ID(t *testing.T) {
+	vnet := NewVirtualNetwork("hub-vnet", "eastus", []string{"10.0.0.0/16"})
+
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualNetworks', 'hub-vnet')]", vnet.ID())
+}
+
+func TestNewVirtualNetworkPeering(t *testing.T) {
+	spoke := NewVirtualNetwork("spoke-vnet", "eastus", []string{"10.1.0.0/16"})
+
+	peering := NewVirtualNetworkPeering("hub-vnet", "hub-to-spoke", spoke.ID())
+
+	assert.Equal(t, "hub-vnet/hub-to-spoke", peering.Name)
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/virtualNetworkPeerings", peering.Type)
+	assert.Equal(t, "2021-05-01", peering.APIVersion)
+	require.NotNil(t, peering.Properties.RemoteVirtualNetwork)
+	assert.Equal(t, spoke.ID(), *peering.Properties.RemoteVirtualNetwork.ID)
+	require.NotNil(t, peering.Properties.AllowVirtualNetworkAccess)
+	assert.True(t, *peering.Properties.AllowVirtualNetworkAccess)
+	assert.Nil(t, peering.Properties.AllowForwardedTraffic)
+	assert.Nil(t, peering.Properties.AllowGatewayTransit)
+	assert.Nil(t, peering.Properties.UseRemoteGateways)
+}
+
+func TestVirtualNetworkPeering_HubAndSpoke(t *testing.T) {
+	hub := NewVirtualNetwork("hub-vnet", "eastus", []string{"10.0.0.0/16"})
+	spoke := NewVirtualNetwork("spoke-vnet", "eastus", []string{"10.1.0.0/16"})
+
+	hubToSpoke := NewVirtualNetworkPeering(hub.Name, "hub-to-spoke", spoke.ID()).
+		WithForwardedTraffic().
+		WithGatewayTransit()
+	spokeToHub := NewVirtualNetworkPeering(spoke.Name, "spoke-to-hub", hub.ID()).
+		WithForwardedTraffic().
+		WithRemoteGateways()
+
+	assert.True(t, *hubToSpoke.Properties.AllowForwardedTraffic)
+	assert.True(t, *hubToSpoke.Properties.AllowGatewayTransit)
+	assert.Nil(t, hubToSpoke.Properties.UseRemoteGateways)
+
+	assert.True(t, *spokeToHub.Properties.UseRemoteGateways)
+	assert.Nil(t, spokeToHub.Properties.AllowGatewayTransit)
+}
+
+func TestVirtualNetworkPeering_JSON(t *testing.T) {
+	peering := NewVirtualNetworkPeering("hub-vnet", "hub-to-spoke", "[resourceId('Microsoft.Network/virtualNetworks', 'spoke-vnet')]")
+
+	data, err := json.Marshal(peering)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": "[resourceId('Microsoft.Network/virtualNetworks', 'spoke-vnet')]"}, props["remoteVirtualNetwork"])
+	assert.Equal(t, true, props["allowVirtualNetworkAccess"])
+	assert.NotContains(t, props, "useRemoteGateways")
+}
+
+func TestNewVirtualNetworkGateway(t *testing.T) {
+	hub := NewVirtualNetwork("hub-vnet", "eastus", []string{"10.0.0.0/16"}).
+		WithSubnet("GatewaySubnet", "10.0.255.0/27")
+	pip := NewPublicIPAddress("gw-pip", "eastus", "Static", "Standard")
+
+	gw := NewVirtualNetworkGateway("hub-gw", "eastus", "Vpn", "VpnGw1", hub.SubnetID("GatewaySubnet"), pip.ID())
+
+	assert.Equal(t, "hub-gw", gw.Name)
+	assert.Equal(t, "Microsoft.Network/virtualNetworkGateways", gw.Type)
+	assert.Equal(t, "2023-04-01", gw.APIVersion)
+	assert.Equal(t, "Vpn", gw.Properties.GatewayType)
+	require.NotNil(t, gw.Properties.VpnType)
+	assert.Equal(t, "RouteBased", *gw.Properties.VpnType)
+	assert.Equal(t, VirtualNetworkGatewaySKU{Name: "VpnGw1", Tier: "VpnGw1"}, gw.Properties.SKU)
+
+	require.Len(t, gw.Properties.IPConfigurations, 1)
+	ipConfig := gw.Properties.IPConfigurations[0].Properties
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'hub-vnet', 'GatewaySubnet')]", *ipConfig.Subnet.ID)
+	assert.Equal(t, "[resourceId('Microsoft.Network/publicIPAddresses', 'gw-pip')]", *ipConfig.PublicIPAddress.ID)
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualNetworkGateways', 'hub-gw')]", gw.ID())
+}
+
+func TestNewVirtualNetworkGateway_ExpressRoute(t *testing.T) {
+	gw := NewVirtualNetworkGateway("er-gw", "eastus", "ExpressRoute", "ErGw1AZ", "subnet-id", "pip-id")
+
+	assert.Equal(t, "ExpressRoute", gw.Properties.GatewayType)
+	assert.Nil(t, gw.Properties.VpnType)
+
+	gw = NewVirtualNetworkGateway("vpn-gw", "eastus", "Vpn", "VpnGw2", "subnet-id", "pip-id").
+		WithVpnType("PolicyBased").
+		WithBGP()
+	assert.Equal(t, "PolicyBased", *gw.Properties.VpnType)
+	assert.True(t, *gw.Properties.EnableBgp)
+}
+
+func TestNewExpressRouteCircuit(t *testing.T) {
+	circuit := NewExpressRouteCircuit("er-circuit", "eastus", "Equinix", "Silicon Valley", 1000, "Standard", "MeteredData")
+
+	assert.Equal(t, "Microsoft.Network/expressRouteCircuits", circuit.Type)
+	assert.Equal(t, "2023-04-01", circuit.APIVersion)
+	assert.Equal(t, ExpressRouteCircuitSKU{Name: "Standard_MeteredData", Tier: "Standard", Family: "MeteredData"}, circuit.SKU)
+	require.NotNil(t, circuit.Properties.ServiceProviderProperties)
+	assert.Equal(t, "Equinix", circuit.Properties.ServiceProviderProperties.ServiceProviderName)
+	assert.Equal(t, "Silicon Valley", circuit.Properties.ServiceProviderProperties.PeeringLocation)
+	assert.Equal(t, 1000, circuit.Properties.ServiceProviderProperties.BandwidthInMbps)
+	assert.Equal(t, "[resourceId('Microsoft.Network/expressRouteCircuits', 'er-circuit')]", circuit.ID())
+}
+
+func TestNewApplicationSecurityGroup(t *testing.T) {
+	asg := NewApplicationSecurityGroup("web-asg", "eastus").WithTags(map[string]string{"tier": "web"})
+
+	assert.Equal(t, "web-asg", asg.Name)
+	assert.Equal(t, "Microsoft.Network/applicationSecurityGroups", asg.Type)
+	assert.Equal(t, "2021-05-01", asg.APIVersion)
+	assert.Equal(t, "eastus", asg.Location)
+	assert.Equal(t, "web", asg.Tags["tier"])
+	assert.Equal(t, "[resourceId('Microsoft.Network/applicationSecurityGroups', 'web-asg')]", asg.ID())
+}
+
+func TestNetworkSecurityGroup_WithApplicationSecurityGroupRule(t *testing.T) {
+	web := NewApplicationSecurityGroup("web-asg", "eastus")
+	db := NewApplicationSecurityGroup("db-asg", "eastus")
+
+	nsg := NewNetworkSecurityGroup("app-nsg", "eastus").
+		WithApplicationSecurityGroupRule("web-to-db", 200, "Inbound", "Allow", "Tcp", "1433", web.ID(), db.ID())
+
+	require.Len(t, nsg.Properties.SecurityRules, 1)
+	props := nsg.Properties.SecurityRules[0].Properties
+	assert.Empty(t, props.SourceAddressPrefix)
+	require.Len(t, props.SourceApplicationSecurityGroups, 1)
+	assert.Equal(t, web.ID(), *props.SourceApplicationSecurityGroups[0].ID)
+	require.Len(t, props.DestinationApplicationSecurityGroups, 1)
+	assert.Equal(t, db.ID(), *props.DestinationApplicationSecurityGroups[0].ID)
+
+	data, err := json.Marshal(nsg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"sourceApplicationSecurityGroups":[{"id":"[resourceId('Microsoft.Network/applicationSecurityGroups', 'web-asg')]"}]`)
+	assert.NotContains(t, string(data), "sourceAddressPrefix")
+}
+
+func TestSecurityRuleProperties_Validate(t *testing.T) {
+	for _, priority := range []int{100, 300, 4096} {
+		p := SecurityRuleProperties{Priority: priority}
+		assert.Empty(t, p.Validate(), "priority %d", priority)
+	}
+	for _, priority := range []int{0, 99, 4097} {
+		p := SecurityRuleProperties{Priority: priority}
+		errs := p.Validate()
+		require.Len(t, errs, 1, "priority %d", priority)
+		assert.Contains(t, errs[0].Error(), "outside the range 100-4096")
+	}
+}
+
+func TestNewFrontDoorWebApplicationFirewallPolicy(t *testing.T) {
+	policy := NewFrontDoorWebApplicationFirewallPolicy("edgewaf", "Premium_AzureFrontDoor", "Prevention").
+		AddManagedRuleSet("Microsoft_DefaultRuleSet", "2.1", "Block").
+		AddRateLimitRule("throttleLogin", 100, 50, 1, "Block", FrontDoorMatchCondition{
+			MatchVariable: "RequestUri",
+			Operator:      "BeginsWith",
+			MatchValue:    []string{"/login"},
+			Transforms:    []string{"Lowercase"},
+		}).
+		AddRateLimitRule("throttleAll", 200, 1000, 5, "Block")
+
+	assert.Equal(t, "edgewaf", policy.Name)
+	assert.Equal(t, "Microsoft.Network/FrontDoorWebApplicationFirewallPolicies", policy.Type)
+	assert.Equal(t, "2022-05-01", policy.APIVersion)
+	assert.Equal(t, "Global", policy.Location)
+	assert.Equal(t, "Premium_AzureFrontDoor", policy.SKU.Name)
+	assert.Equal(t, FrontDoorWAFPolicySettings{EnabledState: "Enabled", Mode: "Prevention"}, policy.Properties.PolicySettings)
+
+	require.NotNil(t, policy.Properties.ManagedRules)
+	assert.Equal(t, []FrontDoorManagedRuleSet{{RuleSetType: "Microsoft_DefaultRuleSet", RuleSetVersion: "2.1", RuleSetAction: "Block"}},
+		policy.Properties.ManagedRules.ManagedRuleSets)
+
+	require.NotNil(t, policy.Properties.CustomRules)
+	rules := policy.Properties.CustomRules.Rules
+	require.Len(t, rules, 2)
+	assert.Equal(t, "RateLimitRule", rules[0].RuleType)
+	assert.Equal(t, "Enabled", rules[0].EnabledState)
+	assert.Equal(t, 50, rules[0].RateLimitThreshold)
+	assert.Equal(t, 1, rules[0].RateLimitDurationInMinutes)
+	assert.Equal(t, []string{"/login"}, rules[0].MatchConditions[0].MatchValue)
+	// Without conditions every request is counted
+	assert.Equal(t, []FrontDoorMatchCondition{{MatchVariable: "RequestUri", Operator: "Any", MatchValue: []string{}}}, rules[1].MatchConditions)
+
+	assert.Equal(t, "[resourceId('Microsoft.Network/FrontDoorWebApplicationFirewallPolicies', 'edgewaf')]", policy.ID())
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"policySettings":{"enabledState":"Enabled","mode":"Prevention"}`)
+	assert.Contains(t, string(data), `"customRules":{"rules":[{"name":"throttleLogin","priority":100`)
+}
+
+func TestNewVirtualNetworkSubnet(t *testing.T) {
+	nsg := NewNetworkSecurityGroup("web-nsg", "eastus")
+
+	subnet := NewVirtualNetworkSubnet("app-vnet", NewSubnet("web", "10.0.1.0/24").WithNSG(nsg.ID()))
+
+	assert.Equal(t, "app-vnet/web", subnet.Name)
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/subnets", subnet.Type)
+	assert.Equal(t, "2021-05-01", subnet.APIVersion)
+	assert.Equal(t, "10.0.1.0/24", subnet.Properties.AddressPrefix)
+	require.NotNil(t, subnet.Properties.NetworkSecurityGroup)
+	assert.Equal(t, "[resourceId('Microsoft.Network/networkSecurityGroups', 'web-nsg')]", *subnet.Properties.NetworkSecurityGroup.ID)
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'app-vnet', 'web')]", subnet.ID())
+}
+
+func TestVirtualNetworkSubnet_JSON(t *testing.T) {
+	subnet := NewVirtualNetworkSubnet("app-vnet", NewSubnet("web", "10.0.1.0/24"))
+
+	data, err := json.Marshal(subnet)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "app-vnet/web", result["name"])
+	assert.Equal(t, "Microsoft.Network/virtualNetworks/subnets", result["type"])
+	assert.NotContains(t, result, "location")
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "10.0.1.0/24", props["addressPrefix"])
+}
+
+func TestNewPrivateEndpoint(t *testing.T) {
+	subnetID := "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'pe')]"
+	accountID := "[resourceId('Microsoft.Storage/storageAccounts', 'orders')]"
+	pe := NewPrivateEndpoint("orders-blob-pe", "eastus", subnetID, accountID, "blob")
+
+	assert.Equal(t, "Microsoft.Network/privateEndpoints", pe.Type)
+	assert.Equal(t, "2023-04-01", pe.APIVersion)
+	assert.Equal(t, "[resourceId('Microsoft.Network/privateEndpoints', 'orders-blob-pe')]", pe.ID())
+
+	data, err := json.Marshal(pe)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"subnet":{"id":"[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'pe')]"}`)
+	assert.Contains(t, string(data), `"privateLinkServiceConnections":[{"name":"orders-blob-pe","properties":{"privateLinkServiceId":"[resourceId('Microsoft.Storage/storageAccounts', 'orders')]","groupIds":["blob"]}}]`)
+}
+
+func TestNewVirtualWANAndHub(t *testing.T) {
+	wan := NewVirtualWAN("corp-wan", "eastus")
+	assert.Equal(t, "Microsoft.Network/virtualWans", wan.Type)
+	assert.Equal(t, "Standard", *wan.Properties.Type)
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualWans', 'corp-wan')]", wan.ID())
+
+	hub := NewVirtualHub("eastus-hub", "eastus", wan.ID(), "10.100.0.0/23")
+	assert.Equal(t, "Microsoft.Network/virtualHubs", hub.Type)
+	require.NotNil(t, hub.Properties.VirtualWan)
+	assert.Equal(t, wan.ID(), *hub.Properties.VirtualWan.ID)
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualHubs', 'eastus-hub')]", hub.ID())
+
+	data, err := json.Marshal(hub)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"properties":{"virtualWan":{"id":"[resourceId('Microsoft.Network/virtualWans', 'corp-wan')]"},"addressPrefix":"10.100.0.0/23","sku":"Standard"}`)
+}
+
+func TestNewHubVirtualNetworkConnection(t *testing.T) {
+	vnetID := "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"
+	conn := NewHubVirtualNetworkConnection("eastus-hub", "app-vnet", vnetID).WithInternetSecurity()
+
+	assert.Equal(t, "eastus-hub/app-vnet", conn.Name)
+	assert.Equal(t, "Microsoft.Network/virtualHubs/hubVirtualNetworkConnections", conn.Type)
+	assert.Equal(t, "[resourceId('Microsoft.Network/virtualHubs/hubVirtualNetworkConnections', 'eastus-hub', 'app-vnet')]", conn.ID())
+
+	data, err := json.Marshal(conn)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"properties":{"remoteVirtualNetwork":{"id":"[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"},"enableInternetSecurity":true}`)
+	assert.NotContains(t, string(data), "location")
+}
+
+func TestNewPrivateDnsZoneVirtualNetworkLink(t *testing.T) {
+	zone := NewPrivateDNSZone("privatelink.blob.core.windows.net")
+	assert.Equal(t, "Microsoft.Network/privateDnsZones", zone.Type)
+	assert.Equal(t, "global", zone.Location)
+	assert.Equal(t, "[resourceId('Microsoft.Network/privateDnsZones', 'privatelink.blob.core.windows.net')]", zone.ID())
+
+	vnetID := "[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"
+	link := NewPrivateDnsZoneVirtualNetworkLink(zone.Name, "app-vnet", vnetID)
+	assert.Equal(t, "privatelink.blob.core.windows.net/app-vnet", link.Name)
+	assert.Equal(t, "Microsoft.Network/privateDnsZones/virtualNetworkLinks", link.Type)
+	assert.Equal(t, "global", link.Location)
+	require.NotNil(t, link.Properties.RegistrationEnabled)
+	assert.False(t, *link.Properties.RegistrationEnabled)
+	assert.Equal(t, "[resourceId('Microsoft.Network/privateDnsZones/virtualNetworkLinks', 'privatelink.blob.core.windows.net', 'app-vnet')]", link.ID())
+
+	data, err := json.Marshal(link.WithRegistration())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"properties":{"virtualNetwork":{"id":"[resourceId('Microsoft.Network/virtualNetworks', 'app-vnet')]"},"registrationEnabled":true}`)
+}
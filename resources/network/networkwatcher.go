@@ -0,0 +1,140 @@
+package network
+
+// NetworkWatcher represents a Microsoft.Network/networkWatchers resource
+type NetworkWatcher struct {
+	// Name is the name of the network watcher
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region being monitored
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the network watcher
+	Properties NetworkWatcherProperties `json:"properties"`
+}
+
+// NetworkWatcherProperties represents the properties of a network watcher
+type NetworkWatcherProperties struct{}
+
+// FlowLog represents a Microsoft.Network/networkWatchers/flowLogs resource
+type FlowLog struct {
+	// Name is the name of the flow log, in the form "<watcher>/<flowlog>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region, which must match the network watcher's
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the flow log
+	Properties FlowLogProperties `json:"properties"`
+}
+
+// FlowLogProperties represents the properties of a flow log
+type FlowLogProperties struct {
+	// TargetResourceID is the ID of the network security group to log
+	TargetResourceID string `json:"targetResourceId"`
+
+	// StorageID is the ID of the storage account that receives the logs
+	StorageID string `json:"storageId"`
+
+	// Enabled indicates whether flow logging is enabled
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// RetentionPolicy defines how long logs are kept
+	RetentionPolicy *RetentionPolicyParameters `json:"retentionPolicy,omitempty"`
+
+	// Format defines the log format
+	Format *FlowLogFormatParameters `json:"format,omitempty"`
+}
+
+// RetentionPolicyParameters represents the retention policy of a flow log
+type RetentionPolicyParameters struct {
+	// Days is the number of days to retain logs (0 keeps them indefinitely)
+	Days int `json:"days"`
+
+	// Enabled indicates whether the retention policy is enabled
+	Enabled bool `json:"enabled"`
+}
+
+// FlowLogFormatParameters represents the format of a flow log
+type FlowLogFormatParameters struct {
+	// Type is the file type of the flow log (JSON)
+	Type string `json:"type"`
+
+	// Version is the flow log format version (1 or 2)
+	Version *int `json:"version,omitempty"`
+}
+
+// NewNetworkWatcher creates a new network watcher with required fields
+func NewNetworkWatcher(name, location string) *NetworkWatcher {
+	return &NetworkWatcher{
+		Name:       name,
+		Type:       "Microsoft.Network/networkWatchers",
+		APIVersion: "2021-05-01",
+		Location:   location,
+		Properties: NetworkWatcherProperties{},
+	}
+}
+
+// WithTags adds tags to the network watcher
+func (n *NetworkWatcher) WithTags(tags map[string]string) *NetworkWatcher {
+	n.Tags = tags
+	return n
+}
+
+// NewFlowLog creates a new enabled flow log under the named network watcher,
+// logging the NSG identified by nsgID to the storage account identified by storageID
+func NewFlowLog(watcherName, name, location, nsgID, storageID string) *FlowLog {
+	enabled := true
+	return &FlowLog{
+		Name:       watcherName + "/" + name,
+		Type:       "Microsoft.Network/networkWatchers/flowLogs",
+		APIVersion: "2021-05-01",
+		Location:   location,
+		Properties: FlowLogProperties{
+			TargetResourceID: nsgID,
+			StorageID:        storageID,
+			Enabled:          &enabled,
+		},
+	}
+}
+
+// WithTags adds tags to the flow log
+func (f *FlowLog) WithTags(tags map[string]string) *FlowLog {
+	f.Tags = tags
+	return f
+}
+
+// WithRetention keeps logs for the given number of days
+func (f *FlowLog) WithRetention(days int) *FlowLog {
+	f.Properties.RetentionPolicy = &RetentionPolicyParameters{
+		Days:    days,
+		Enabled: true,
+	}
+	return f
+}
+
+// WithFormatVersion sets the JSON flow log format version (1 or 2)
+func (f *FlowLog) WithFormatVersion(version int) *FlowLog {
+	f.Properties.Format = &FlowLogFormatParameters{
+		Type:    "JSON",
+		Version: &version,
+	}
+	return f
+}
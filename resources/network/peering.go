@@ -0,0 +1,71 @@
+package network
+
+// VirtualNetworkPeering represents a Microsoft.Network/virtualNetworks/virtualNetworkPeerings resource
+type VirtualNetworkPeering struct {
+	// Name is the name of the peering, in the form "<vnet>/<peering>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the peering
+	Properties VirtualNetworkPeeringProperties `json:"properties"`
+}
+
+// VirtualNetworkPeeringProperties represents the properties of a virtual network peering
+type VirtualNetworkPeeringProperties struct {
+	// RemoteVirtualNetwork is the virtual network to peer with
+	RemoteVirtualNetwork *SubResource `json:"remoteVirtualNetwork"`
+
+	// AllowVirtualNetworkAccess allows VMs in the local network to reach VMs in the remote network
+	AllowVirtualNetworkAccess *bool `json:"allowVirtualNetworkAccess,omitempty"`
+
+	// AllowForwardedTraffic allows traffic forwarded by VMs in the remote network
+	AllowForwardedTraffic *bool `json:"allowForwardedTraffic,omitempty"`
+
+	// AllowGatewayTransit lets the remote network use this network's gateway
+	AllowGatewayTransit *bool `json:"allowGatewayTransit,omitempty"`
+
+	// UseRemoteGateways routes traffic through the remote network's gateway.
+	// It cannot be set together with AllowGatewayTransit on the same peering.
+	UseRemoteGateways *bool `json:"useRemoteGateways,omitempty"`
+}
+
+// NewVirtualNetworkPeering creates a peering from the named virtual network to
+// the virtual network identified by remoteVNetID, with virtual network access allowed
+func NewVirtualNetworkPeering(vnetName, name, remoteVNetID string) *VirtualNetworkPeering {
+	allowAccess := true
+	return &VirtualNetworkPeering{
+		Name:       vnetName + "/" + name,
+		Type:       "Microsoft.Network/virtualNetworks/virtualNetworkPeerings",
+		APIVersion: "2021-05-01",
+		Properties: VirtualNetworkPeeringProperties{
+			RemoteVirtualNetwork:      NewSubResource(remoteVNetID),
+			AllowVirtualNetworkAccess: &allowAccess,
+		},
+	}
+}
+
+// WithForwardedTraffic allows traffic forwarded from the remote network
+func (p *VirtualNetworkPeering) WithForwardedTraffic() *VirtualNetworkPeering {
+	allow := true
+	p.Properties.AllowForwardedTraffic = &allow
+	return p
+}
+
+// WithGatewayTransit lets the remote network use this network's gateway (hub side)
+func (p *VirtualNetworkPeering) WithGatewayTransit() *VirtualNetworkPeering {
+	allow := true
+	p.Properties.AllowGatewayTransit = &allow
+	return p
+}
+
+// WithRemoteGateways routes traffic through the remote network's gateway (spoke side)
+func (p *VirtualNetworkPeering) WithRemoteGateways() *VirtualNetworkPeering {
+	use := true
+	p.Properties.UseRemoteGateways = &use
+	return p
+}
@@ -0,0 +1,122 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+)
+
+// privateDNSAPIVersion is the API version of the private DNS resources
+const privateDNSAPIVersion = "2020-06-01"
+
+// PrivateDNSZone represents a Microsoft.Network/privateDnsZones resource: a
+// DNS zone resolvable only from the virtual networks linked to it, such as
+// privatelink.blob.core.windows.net for the private endpoints of blob storage
+type PrivateDNSZone struct {
+	// Name is the zone name (e.g. "privatelink.blob.core.windows.net")
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is always "global"
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// NewPrivateDNSZone creates a private DNS zone
+func NewPrivateDNSZone(name string) *PrivateDNSZone {
+	return &PrivateDNSZone{
+		Name:       name,
+		Type:       "Microsoft.Network/privateDnsZones",
+		APIVersion: privateDNSAPIVersion,
+		Location:   "global",
+	}
+}
+
+// WithTags adds tags to the zone
+func (z *PrivateDNSZone) WithTags(tags map[string]string) *PrivateDNSZone {
+	z.Tags = tags
+	return z
+}
+
+// ID returns the ARM resourceId expression for the zone
+func (z *PrivateDNSZone) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/privateDnsZones', '%s')]", z.Name)
+}
+
+// PrivateDnsZoneVirtualNetworkLink represents a
+// Microsoft.Network/privateDnsZones/virtualNetworkLinks resource: a virtual
+// network linked to a private DNS zone, whose machines can then resolve the
+// zone's records
+type PrivateDnsZoneVirtualNetworkLink struct {
+	// Name is the name of the link, in the form "<zone>/<link>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is always "global"
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the link
+	Properties PrivateDnsZoneVirtualNetworkLinkProperties `json:"properties"`
+}
+
+// PrivateDnsZoneVirtualNetworkLinkProperties represents the properties of a
+// private DNS zone virtual network link
+type PrivateDnsZoneVirtualNetworkLinkProperties struct {
+	// VirtualNetwork references the linked virtual network
+	VirtualNetwork *SubResource `json:"virtualNetwork"`
+
+	// RegistrationEnabled registers the DNS records of the network's virtual
+	// machines in the zone automatically; a network can have one
+	// registration link
+	RegistrationEnabled *bool `json:"registrationEnabled,omitempty"`
+}
+
+// NewPrivateDnsZoneVirtualNetworkLink links the virtual network identified by
+// vnetID to the named private DNS zone, without auto-registration
+func NewPrivateDnsZoneVirtualNetworkLink(zoneName, name, vnetID string) *PrivateDnsZoneVirtualNetworkLink {
+	registration := false
+	return &PrivateDnsZoneVirtualNetworkLink{
+		Name:       zoneName + "/" + name,
+		Type:       "Microsoft.Network/privateDnsZones/virtualNetworkLinks",
+		APIVersion: privateDNSAPIVersion,
+		Location:   "global",
+		Properties: PrivateDnsZoneVirtualNetworkLinkProperties{
+			VirtualNetwork:      NewSubResource(vnetID),
+			RegistrationEnabled: &registration,
+		},
+	}
+}
+
+// WithRegistration registers the DNS records of the linked network's virtual
+// machines in the zone
+func (l *PrivateDnsZoneVirtualNetworkLink) WithRegistration() *PrivateDnsZoneVirtualNetworkLink {
+	enabled := true
+	l.Properties.RegistrationEnabled = &enabled
+	return l
+}
+
+// WithTags adds tags to the link
+func (l *PrivateDnsZoneVirtualNetworkLink) WithTags(tags map[string]string) *PrivateDnsZoneVirtualNetworkLink {
+	l.Tags = tags
+	return l
+}
+
+// ID returns the ARM resourceId expression for the link
+func (l *PrivateDnsZoneVirtualNetworkLink) ID() string {
+	zone, name, _ := strings.Cut(l.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Network/privateDnsZones/virtualNetworkLinks', '%s', '%s')]", zone, name)
+}
@@ -0,0 +1,87 @@
+package network
+
+import "fmt"
+
+// PrivateEndpoint represents a Microsoft.Network/privateEndpoints resource: a
+// network interface in a subnet that connects privately to a sub-resource
+// (group) of another resource, such as the blob service of a storage account
+type PrivateEndpoint struct {
+	// Name is the name of the private endpoint
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region, which must match the virtual network of the subnet
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the private endpoint
+	Properties PrivateEndpointProperties `json:"properties"`
+}
+
+// PrivateEndpointProperties represents the properties of a private endpoint
+type PrivateEndpointProperties struct {
+	// Subnet is the subnet the endpoint's network interface is placed in
+	Subnet *SubResource `json:"subnet,omitempty"`
+
+	// PrivateLinkServiceConnections connect the endpoint to the target resource
+	PrivateLinkServiceConnections []PrivateLinkServiceConnection `json:"privateLinkServiceConnections,omitempty"`
+}
+
+// PrivateLinkServiceConnection represents the connection of a private
+// endpoint to a resource
+type PrivateLinkServiceConnection struct {
+	// Name is the name of the connection
+	Name string `json:"name"`
+
+	// Properties contains the properties of the connection
+	Properties PrivateLinkServiceConnectionProperties `json:"properties"`
+}
+
+// PrivateLinkServiceConnectionProperties represents the properties of a
+// private link service connection
+type PrivateLinkServiceConnectionProperties struct {
+	// PrivateLinkServiceID is the resource ID of the target resource
+	PrivateLinkServiceID string `json:"privateLinkServiceId"`
+
+	// GroupIDs are the sub-resources to connect to (e.g. blob, file, vault, sqlServer)
+	GroupIDs []string `json:"groupIds,omitempty"`
+}
+
+// NewPrivateEndpoint creates a private endpoint in the subnet subnetID that
+// connects to the groupID sub-resource of the resource resourceID
+func NewPrivateEndpoint(name, location, subnetID, resourceID, groupID string) *PrivateEndpoint {
+	return &PrivateEndpoint{
+		Name:       name,
+		Type:       "Microsoft.Network/privateEndpoints",
+		APIVersion: "2023-04-01",
+		Location:   location,
+		Properties: PrivateEndpointProperties{
+			Subnet: NewSubResource(subnetID),
+			PrivateLinkServiceConnections: []PrivateLinkServiceConnection{{
+				Name: name,
+				Properties: PrivateLinkServiceConnectionProperties{
+					PrivateLinkServiceID: resourceID,
+					GroupIDs:             []string{groupID},
+				},
+			}},
+		},
+	}
+}
+
+// WithTags adds tags to the private endpoint
+func (p *PrivateEndpoint) WithTags(tags map[string]string) *PrivateEndpoint {
+	p.Tags = tags
+	return p
+}
+
+// ID returns the ARM resourceId expression for the private endpoint
+func (p *PrivateEndpoint) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/privateEndpoints', '%s')]", p.Name)
+}
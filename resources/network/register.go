@@ -0,0 +1,33 @@
+package network
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/network"
+
+// init registers the resource structs of this package for discovery, and
+// the ones discovery evaluates for validation
+func init() {
+	template.RegisterResourceType(importPath, "VirtualNetwork", "Microsoft.Network/virtualNetworks")
+	template.RegisterResourceType(importPath, "VirtualNetworkPeering", "Microsoft.Network/virtualNetworks/virtualNetworkPeerings")
+	template.RegisterResourceType(importPath, "VirtualNetworkSubnet", "Microsoft.Network/virtualNetworks/subnets")
+	template.RegisterResourceType(importPath, "NetworkInterface", "Microsoft.Network/networkInterfaces")
+	template.RegisterResourceType(importPath, "Subnet", "Microsoft.Network/subnets")
+	template.RegisterResourceType(importPath, "PublicIPAddress", "Microsoft.Network/publicIPAddresses")
+	template.RegisterResourceType(importPath, "NetworkSecurityGroup", "Microsoft.Network/networkSecurityGroups")
+	template.RegisterResourceType(importPath, "ApplicationSecurityGroup", "Microsoft.Network/applicationSecurityGroups")
+	template.RegisterResourceType(importPath, "FrontDoorWebApplicationFirewallPolicy", "Microsoft.Network/FrontDoorWebApplicationFirewallPolicies")
+	template.RegisterResourceType(importPath, "NetworkWatcher", "Microsoft.Network/networkWatchers")
+	template.RegisterResourceType(importPath, "FlowLog", "Microsoft.Network/networkWatchers/flowLogs")
+	template.RegisterResourceType(importPath, "VirtualNetworkGateway", "Microsoft.Network/virtualNetworkGateways")
+	template.RegisterResourceType(importPath, "ExpressRouteCircuit", "Microsoft.Network/expressRouteCircuits")
+	template.RegisterResourceType(importPath, "PrivateEndpoint", "Microsoft.Network/privateEndpoints")
+	template.RegisterResourceType(importPath, "VirtualWAN", "Microsoft.Network/virtualWans")
+	template.RegisterResourceType(importPath, "VirtualHub", "Microsoft.Network/virtualHubs")
+	template.RegisterResourceType(importPath, "HubVirtualNetworkConnection", "Microsoft.Network/virtualHubs/hubVirtualNetworkConnections")
+	template.RegisterResourceType(importPath, "PrivateDNSZone", "Microsoft.Network/privateDnsZones")
+	template.RegisterResourceType(importPath, "PrivateDnsZoneVirtualNetworkLink", "Microsoft.Network/privateDnsZones/virtualNetworkLinks")
+
+	template.RegisterValueType(importPath, "NetworkSecurityGroup", NetworkSecurityGroup{})
+}
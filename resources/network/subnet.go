@@ -0,0 +1,43 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VirtualNetworkSubnet represents a Microsoft.Network/virtualNetworks/subnets
+// resource: a subnet declared separately from its virtual network rather than
+// inline in VirtualNetworkProperties.Subnets. Use it when the subnet and the
+// virtual network are managed apart; a subnet declared both ways is replaced
+// on every deployment of the virtual network.
+type VirtualNetworkSubnet struct {
+	// Name is the name of the subnet, in the form "<vnet>/<subnet>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the subnet
+	Properties SubnetProperties `json:"properties"`
+}
+
+// NewVirtualNetworkSubnet creates a standalone subnet of the named virtual
+// network from a subnet built with NewSubnet, such as
+// NewVirtualNetworkSubnet("app-vnet", NewSubnet("web", "10.0.1.0/24").WithNSG(nsg.ID()))
+func NewVirtualNetworkSubnet(vnetName string, subnet *Subnet) *VirtualNetworkSubnet {
+	return &VirtualNetworkSubnet{
+		Name:       vnetName + "/" + subnet.Name,
+		Type:       "Microsoft.Network/virtualNetworks/subnets",
+		APIVersion: "2021-05-01",
+		Properties: subnet.Properties,
+	}
+}
+
+// ID returns the ARM resourceId expression for the subnet
+func (s *VirtualNetworkSubnet) ID() string {
+	vnet, subnet, _ := strings.Cut(s.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Network/virtualNetworks/subnets', '%s', '%s')]", vnet, subnet)
+}
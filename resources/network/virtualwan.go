@@ -0,0 +1,185 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VirtualWAN represents a Microsoft.Network/virtualWans resource: a
+// Microsoft-managed hub-and-spoke network spanning regions, whose hubs
+// connect virtual networks, branches, and users
+type VirtualWAN struct {
+	// Name is the name of the virtual WAN
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the resource will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the virtual WAN
+	Properties VirtualWANProperties `json:"properties"`
+}
+
+// VirtualWANProperties represents the properties of a virtual WAN
+type VirtualWANProperties struct {
+	// Type is the WAN type (Basic or Standard); hub virtual network
+	// connections and hub-to-hub transit need Standard
+	Type *string `json:"type,omitempty"`
+
+	// DisableVpnEncryption turns off encryption of VPN traffic
+	DisableVpnEncryption *bool `json:"disableVpnEncryption,omitempty"`
+
+	// AllowBranchToBranchTraffic routes traffic between branches through the WAN
+	AllowBranchToBranchTraffic *bool `json:"allowBranchToBranchTraffic,omitempty"`
+}
+
+// VirtualHub represents a Microsoft.Network/virtualHubs resource: a regional
+// hub of a virtual WAN
+type VirtualHub struct {
+	// Name is the name of the hub
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the hub
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the hub
+	Properties VirtualHubProperties `json:"properties"`
+}
+
+// VirtualHubProperties represents the properties of a virtual hub
+type VirtualHubProperties struct {
+	// VirtualWan references the virtual WAN the hub belongs to
+	VirtualWan *SubResource `json:"virtualWan,omitempty"`
+
+	// AddressPrefix is the hub's address range in CIDR notation; at least a
+	// /24, not overlapping the connected virtual networks
+	AddressPrefix string `json:"addressPrefix,omitempty"`
+
+	// SKU is the hub SKU (Basic or Standard)
+	SKU *string `json:"sku,omitempty"`
+}
+
+// HubVirtualNetworkConnection represents a
+// Microsoft.Network/virtualHubs/hubVirtualNetworkConnections resource: a
+// virtual network connected to a virtual hub
+type HubVirtualNetworkConnection struct {
+	// Name is the name of the connection, in the form "<hub>/<connection>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the connection
+	Properties HubVirtualNetworkConnectionProperties `json:"properties"`
+}
+
+// HubVirtualNetworkConnectionProperties represents the properties of a hub
+// virtual network connection
+type HubVirtualNetworkConnectionProperties struct {
+	// RemoteVirtualNetwork references the connected virtual network
+	RemoteVirtualNetwork *SubResource `json:"remoteVirtualNetwork,omitempty"`
+
+	// EnableInternetSecurity routes the network's internet traffic through
+	// the hub's security provider
+	EnableInternetSecurity *bool `json:"enableInternetSecurity,omitempty"`
+}
+
+// NewVirtualWAN creates a Standard virtual WAN
+func NewVirtualWAN(name, location string) *VirtualWAN {
+	wanType := "Standard"
+	return &VirtualWAN{
+		Name:       name,
+		Type:       "Microsoft.Network/virtualWans",
+		APIVersion: "2023-04-01",
+		Location:   location,
+		Properties: VirtualWANProperties{
+			Type: &wanType,
+		},
+	}
+}
+
+// WithTags adds tags to the virtual WAN
+func (w *VirtualWAN) WithTags(tags map[string]string) *VirtualWAN {
+	w.Tags = tags
+	return w
+}
+
+// ID returns the ARM resourceId expression for the virtual WAN
+func (w *VirtualWAN) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/virtualWans', '%s')]", w.Name)
+}
+
+// NewVirtualHub creates a Standard hub of the virtual WAN identified by
+// wanID, using the address range addressPrefix
+func NewVirtualHub(name, location, wanID, addressPrefix string) *VirtualHub {
+	sku := "Standard"
+	return &VirtualHub{
+		Name:       name,
+		Type:       "Microsoft.Network/virtualHubs",
+		APIVersion: "2023-04-01",
+		Location:   location,
+		Properties: VirtualHubProperties{
+			VirtualWan:    NewSubResource(wanID),
+			AddressPrefix: addressPrefix,
+			SKU:           &sku,
+		},
+	}
+}
+
+// WithTags adds tags to the hub
+func (h *VirtualHub) WithTags(tags map[string]string) *VirtualHub {
+	h.Tags = tags
+	return h
+}
+
+// ID returns the ARM resourceId expression for the hub
+func (h *VirtualHub) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Network/virtualHubs', '%s')]", h.Name)
+}
+
+// NewHubVirtualNetworkConnection connects the virtual network identified by
+// vnetID to the named hub
+func NewHubVirtualNetworkConnection(hubName, name, vnetID string) *HubVirtualNetworkConnection {
+	return &HubVirtualNetworkConnection{
+		Name:       hubName + "/" + name,
+		Type:       "Microsoft.Network/virtualHubs/hubVirtualNetworkConnections",
+		APIVersion: "2023-04-01",
+		Properties: HubVirtualNetworkConnectionProperties{
+			RemoteVirtualNetwork: NewSubResource(vnetID),
+		},
+	}
+}
+
+// WithInternetSecurity routes the connected network's internet traffic
+// through the hub's security provider, such as Azure Firewall
+func (c *HubVirtualNetworkConnection) WithInternetSecurity() *HubVirtualNetworkConnection {
+	enable := true
+	c.Properties.EnableInternetSecurity = &enable
+	return c
+}
+
+// ID returns the ARM resourceId expression for the connection
+func (c *HubVirtualNetworkConnection) ID() string {
+	hub, name, _ := strings.Cut(c.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Network/virtualHubs/hubVirtualNetworkConnections', '%s', '%s')]", hub, name)
+}
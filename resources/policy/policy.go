@@ -0,0 +1,209 @@
+// Package policy provides Azure Policy resource types
+package policy
+
+import "fmt"
+
+// PolicyDefinition represents a Microsoft.Authorization/policyDefinitions resource
+type PolicyDefinition struct {
+	// Name is the name of the policy definition
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the policy definition
+	Properties PolicyDefinitionProperties `json:"properties"`
+}
+
+// PolicyDefinitionProperties represents the properties of a policy definition
+type PolicyDefinitionProperties struct {
+	// PolicyType is the type of policy definition (NotSpecified, BuiltIn, Custom, Static)
+	PolicyType *string `json:"policyType,omitempty"`
+
+	// Mode is the policy definition mode (All, Indexed)
+	Mode *string `json:"mode,omitempty"`
+
+	// DisplayName is the display name of the policy definition
+	DisplayName *string `json:"displayName,omitempty"`
+
+	// Description is the description of the policy definition
+	Description *string `json:"description,omitempty"`
+
+	// PolicyRule is the policy rule, containing the if/then condition and effect
+	PolicyRule map[string]interface{} `json:"policyRule,omitempty"`
+
+	// Metadata is the policy definition metadata
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Parameters are the parameter definitions for parameters used in the policy rule
+	Parameters map[string]ParameterDefinition `json:"parameters,omitempty"`
+}
+
+// ParameterDefinition represents a parameter declared by a policy definition
+type ParameterDefinition struct {
+	// Type is the data type of the parameter (String, Array, Object, Boolean, Integer, Float, DateTime)
+	Type string `json:"type"`
+
+	// DefaultValue is the default value for the parameter if no value is provided
+	DefaultValue interface{} `json:"defaultValue,omitempty"`
+
+	// AllowedValues are the allowed values for the parameter
+	AllowedValues []interface{} `json:"allowedValues,omitempty"`
+
+	// Metadata is the general metadata for the parameter
+	Metadata *ParameterMetadata `json:"metadata,omitempty"`
+}
+
+// ParameterMetadata represents metadata for a policy parameter
+type ParameterMetadata struct {
+	// DisplayName is the display name for the parameter
+	DisplayName *string `json:"displayName,omitempty"`
+
+	// Description is the description of the parameter
+	Description *string `json:"description,omitempty"`
+}
+
+// PolicyAssignment represents a Microsoft.Authorization/policyAssignments resource
+type PolicyAssignment struct {
+	// Name is the name of the policy assignment
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the assignment (required when Identity is set)
+	Location string `json:"location,omitempty"`
+
+	// Properties contains the properties of the policy assignment
+	Properties PolicyAssignmentProperties `json:"properties"`
+
+	// Identity defines the managed identity used for remediation
+	Identity *Identity `json:"identity,omitempty"`
+}
+
+// PolicyAssignmentProperties represents the properties of a policy assignment
+type PolicyAssignmentProperties struct {
+	// PolicyDefinitionID is the ID of the policy definition or policy set definition being assigned
+	PolicyDefinitionID string `json:"policyDefinitionId"`
+
+	// Scope is the scope for the policy assignment
+	Scope *string `json:"scope,omitempty"`
+
+	// NotScopes are the policy's excluded scopes
+	NotScopes []string `json:"notScopes,omitempty"`
+
+	// DisplayName is the display name of the policy assignment
+	DisplayName *string `json:"displayName,omitempty"`
+
+	// Description is the description of the policy assignment
+	Description *string `json:"description,omitempty"`
+
+	// Parameters are the parameter values for the assigned policy rule
+	Parameters map[string]ParameterValue `json:"parameters,omitempty"`
+
+	// EnforcementMode is the policy assignment enforcement mode (Default, DoNotEnforce)
+	EnforcementMode *string `json:"enforcementMode,omitempty"`
+}
+
+// ParameterValue represents the value of a parameter passed to an assigned policy
+type ParameterValue struct {
+	// Value is the value of the parameter
+	Value interface{} `json:"value"`
+}
+
+// Identity represents the identity configuration of a policy assignment
+type Identity struct {
+	// Type is the identity type (SystemAssigned, UserAssigned, None)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// NewPolicyDefinition creates a new custom policy definition with required fields
+func NewPolicyDefinition(name, mode string, policyRule map[string]interface{}) *PolicyDefinition {
+	policyType := "Custom"
+	return &PolicyDefinition{
+		Name:       name,
+		Type:       "Microsoft.Authorization/policyDefinitions",
+		APIVersion: "2021-06-01",
+		Properties: PolicyDefinitionProperties{
+			PolicyType: &policyType,
+			Mode:       &mode,
+			PolicyRule: policyRule,
+		},
+	}
+}
+
+// ID returns an ARM expression resolving to the resource ID of the policy definition
+func (p *PolicyDefinition) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Authorization/policyDefinitions', '%s')]", p.Name)
+}
+
+// WithDisplayName sets the display name of the policy definition
+func (p *PolicyDefinition) WithDisplayName(displayName string) *PolicyDefinition {
+	p.Properties.DisplayName = &displayName
+	return p
+}
+
+// WithParameter declares a parameter used by the policy rule
+func (p *PolicyDefinition) WithParameter(name, paramType string, defaultValue interface{}) *PolicyDefinition {
+	if p.Properties.Parameters == nil {
+		p.Properties.Parameters = make(map[string]ParameterDefinition)
+	}
+	p.Properties.Parameters[name] = ParameterDefinition{
+		Type:         paramType,
+		DefaultValue: defaultValue,
+	}
+	return p
+}
+
+// NewPolicyAssignment creates a new policy assignment with required fields
+func NewPolicyAssignment(name, policyDefinitionID string) *PolicyAssignment {
+	return &PolicyAssignment{
+		Name:       name,
+		Type:       "Microsoft.Authorization/policyAssignments",
+		APIVersion: "2021-06-01",
+		Properties: PolicyAssignmentProperties{
+			PolicyDefinitionID: policyDefinitionID,
+		},
+	}
+}
+
+// WithScope sets the scope the policy is assigned to
+func (p *PolicyAssignment) WithScope(scope string) *PolicyAssignment {
+	p.Properties.Scope = &scope
+	return p
+}
+
+// WithParameter sets the value of a policy parameter
+func (p *PolicyAssignment) WithParameter(name string, value interface{}) *PolicyAssignment {
+	if p.Properties.Parameters == nil {
+		p.Properties.Parameters = make(map[string]ParameterValue)
+	}
+	p.Properties.Parameters[name] = ParameterValue{Value: value}
+	return p
+}
+
+// WithSystemAssignedIdentity enables a system-assigned identity for remediation.
+// Azure requires a location on assignments that have an identity.
+func (p *PolicyAssignment) WithSystemAssignedIdentity(location string) *PolicyAssignment {
+	p.Location = location
+	p.Identity = &Identity{Type: "SystemAssigned"}
+	return p
+}
@@ -0,0 +1,97 @@
+// Package policy provides Azure Policy resource types
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireTagRule() map[string]interface{} {
+	return map[string]interface{}{
+		"if": map[string]interface{}{
+			"field":  "[concat('tags[', parameters('tagName'), ']')]",
+			"exists": "false",
+		},
+		"then": map[string]interface{}{
+			"effect": "deny",
+		},
+	}
+}
+
+func TestNewPolicyDefinition(t *testing.T) {
+	def := NewPolicyDefinition("require-tag", "Indexed", requireTagRule())
+
+	assert.Equal(t, "require-tag", def.Name)
+	assert.Equal(t, "Microsoft.Authorization/policyDefinitions", def.Type)
+	assert.Equal(t, "2021-06-01", def.APIVersion)
+	require.NotNil(t, def.Properties.PolicyType)
+	assert.Equal(t, "Custom", *def.Properties.PolicyType)
+	require.NotNil(t, def.Properties.Mode)
+	assert.Equal(t, "Indexed", *def.Properties.Mode)
+	assert.Contains(t, def.Properties.PolicyRule, "if")
+}
+
+func TestPolicyDefinition_ID(t *testing.T) {
+	def := NewPolicyDefinition("require-tag", "Indexed", requireTagRule())
+
+	assert.Equal(t, "[resourceId('Microsoft.Authorization/policyDefinitions', 'require-tag')]", def.ID())
+}
+
+func TestPolicyDefinition_WithParameter(t *testing.T) {
+	def := NewPolicyDefinition("require-tag", "Indexed", requireTagRule()).
+		WithDisplayName("Require a tag").
+		WithParameter("tagName", "String", "env")
+
+	require.NotNil(t, def.Properties.DisplayName)
+	assert.Equal(t, "Require a tag", *def.Properties.DisplayName)
+	require.Contains(t, def.Properties.Parameters, "tagName")
+	assert.Equal(t, "String", def.Properties.Parameters["tagName"].Type)
+	assert.Equal(t, "env", def.Properties.Parameters["tagName"].DefaultValue)
+}
+
+func TestNewPolicyAssignment(t *testing.T) {
+	def := NewPolicyDefinition("require-tag", "Indexed", requireTagRule())
+	assignment := NewPolicyAssignment("require-env-tag", def.ID())
+
+	assert.Equal(t, "require-env-tag", assignment.Name)
+	assert.Equal(t, "Microsoft.Authorization/policyAssignments", assignment.Type)
+	assert.Equal(t, "2021-06-01", assignment.APIVersion)
+	assert.Equal(t, def.ID(), assignment.Properties.PolicyDefinitionID)
+}
+
+func TestPolicyAssignment_With(t *testing.T) {
+	assignment := NewPolicyAssignment("require-env-tag", "/providers/Microsoft.Authorization/policyDefinitions/abc").
+		WithScope("/subscriptions/00000000-0000-0000-0000-000000000000").
+		WithParameter("tagName", "env").
+		WithSystemAssignedIdentity("eastus")
+
+	require.NotNil(t, assignment.Properties.Scope)
+	assert.Equal(t, "/subscriptions/00000000-0000-0000-0000-000000000000", *assignment.Properties.Scope)
+	assert.Equal(t, "env", assignment.Properties.Parameters["tagName"].Value)
+	require.NotNil(t, assignment.Identity)
+	assert.Equal(t, "SystemAssigned", assignment.Identity.Type)
+	assert.Equal(t, "eastus", assignment.Location)
+}
+
+func TestPolicyAssignment_JSON(t *testing.T) {
+	assignment := NewPolicyAssignment("require-env-tag", "/providers/Microsoft.Authorization/policyDefinitions/abc").
+		WithParameter("tagName", "env")
+
+	data, err := json.Marshal(assignment)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "Microsoft.Authorization/policyAssignments", result["type"])
+	assert.NotContains(t, result, "location")
+	assert.NotContains(t, result, "identity")
+
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "/providers/Microsoft.Authorization/policyDefinitions/abc", props["policyDefinitionId"])
+	params := props["parameters"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"value": "env"}, params["tagName"])
+}
@@ -0,0 +1,13 @@
+package policy
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/policy"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "PolicyDefinition", "Microsoft.Authorization/policyDefinitions")
+	template.RegisterResourceType(importPath, "PolicyAssignment", "Microsoft.Authorization/policyAssignments")
+}
@@ -0,0 +1,44 @@
+package recoveryservices
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVault(t *testing.T) {
+	vault := NewVault("backup-vault", "eastus")
+
+	assert.Equal(t, "backup-vault", vault.Name)
+	assert.Equal(t, "Microsoft.RecoveryServices/vaults", vault.Type)
+	assert.Equal(t, "2023-04-01", vault.APIVersion)
+	assert.Equal(t, "RS0", vault.SKU.Name)
+	assert.Equal(t, "Standard", vault.SKU.Tier)
+	assert.Equal(t, "[resourceId('Microsoft.RecoveryServices/vaults', 'backup-vault')]", vault.ID())
+	assert.Equal(t,
+		"[resourceId('Microsoft.RecoveryServices/vaults/backupPolicies', 'backup-vault', 'DefaultPolicy')]",
+		vault.PolicyID("DefaultPolicy"))
+}
+
+func TestNewVMProtectedItem(t *testing.T) {
+	item := NewVMProtectedItem("backup-vault", "DefaultPolicy", "webVM")
+
+	assert.Equal(t, "Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems", item.Type)
+	assert.Equal(t, "2023-04-01", item.APIVersion)
+	assert.Equal(t,
+		"[concat('backup-vault/Azure/iaasvmcontainer;iaasvmcontainerv2;', resourceGroup().name, ';webVM/vm;iaasvmcontainerv2;', resourceGroup().name, ';webVM')]",
+		item.Name)
+
+	data, err := json.Marshal(item)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, "Microsoft.Compute/virtualMachines", props["protectedItemType"])
+	assert.Equal(t, "[resourceId('Microsoft.RecoveryServices/vaults/backupPolicies', 'backup-vault', 'DefaultPolicy')]", props["policyId"])
+	assert.Equal(t, "[resourceId('Microsoft.Compute/virtualMachines', 'webVM')]", props["sourceResourceId"])
+}
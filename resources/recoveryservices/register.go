@@ -0,0 +1,13 @@
+package recoveryservices
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/recoveryservices"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "Vault", "Microsoft.RecoveryServices/vaults")
+	template.RegisterResourceType(importPath, "ProtectedItem", "Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems")
+}
@@ -0,0 +1,131 @@
+// Package recoveryservices provides Azure Recovery Services (Backup) resource types
+package recoveryservices
+
+import "fmt"
+
+// Vault represents a Microsoft.RecoveryServices/vaults resource
+type Vault struct {
+	// Name is the name of the vault
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the vault, which must match the protected VMs
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// SKU is the vault SKU
+	SKU VaultSKU `json:"sku"`
+
+	// Properties contains the properties of the vault
+	Properties VaultProperties `json:"properties"`
+}
+
+// VaultSKU represents the SKU of a Recovery Services vault
+type VaultSKU struct {
+	// Name is the SKU name (RS0 or Standard)
+	Name string `json:"name"`
+
+	// Tier is the SKU tier (Standard)
+	Tier string `json:"tier"`
+}
+
+// VaultProperties represents the properties of a Recovery Services vault
+type VaultProperties struct {
+	// PublicNetworkAccess controls access from public networks (Enabled or Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+}
+
+// ProtectedItem represents a
+// Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems resource
+type ProtectedItem struct {
+	// Name is the name of the item, in the form
+	// "<vault>/<fabric>/<container>/<item>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the protected item
+	Properties ProtectedItemProperties `json:"properties"`
+}
+
+// ProtectedItemProperties represents the properties of a protected item
+type ProtectedItemProperties struct {
+	// ProtectedItemType is the kind of item (Microsoft.Compute/virtualMachines for VMs)
+	ProtectedItemType string `json:"protectedItemType"`
+
+	// PolicyID is the resource ID of the backup policy
+	PolicyID string `json:"policyId"`
+
+	// SourceResourceID is the resource ID of the protected resource
+	SourceResourceID string `json:"sourceResourceId"`
+}
+
+// NewVault creates a Recovery Services vault with the standard SKU
+func NewVault(name, location string) *Vault {
+	return &Vault{
+		Name:       name,
+		Type:       "Microsoft.RecoveryServices/vaults",
+		APIVersion: "2023-04-01",
+		Location:   location,
+		SKU: VaultSKU{
+			Name: "RS0",
+			Tier: "Standard",
+		},
+	}
+}
+
+// WithTags adds tags to the vault
+func (v *Vault) WithTags(tags map[string]string) *Vault {
+	v.Tags = tags
+	return v
+}
+
+// ID returns the ARM resourceId expression for the vault
+func (v *Vault) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.RecoveryServices/vaults', '%s')]", v.Name)
+}
+
+// PolicyID returns the ARM resourceId expression for the named backup policy
+// of the vault, such as the built-in "DefaultPolicy"
+func (v *Vault) PolicyID(policyName string) string {
+	return PolicyID(v.Name, policyName)
+}
+
+// PolicyID returns the ARM resourceId expression for a backup policy
+func PolicyID(vaultName, policyName string) string {
+	return fmt.Sprintf("[resourceId('Microsoft.RecoveryServices/vaults/backupPolicies', '%s', '%s')]", vaultName, policyName)
+}
+
+// VMProtectedItemName returns the name of the protected item for a VM in the
+// deployment's resource group, as an ARM expression. Azure Backup names the
+// container and the item after the resource group and the VM.
+func VMProtectedItemName(vaultName, vmName string) string {
+	return fmt.Sprintf("[concat('%s/Azure/iaasvmcontainer;iaasvmcontainerv2;', resourceGroup().name, ';%s/vm;iaasvmcontainerv2;', resourceGroup().name, ';%s')]",
+		vaultName, vmName, vmName)
+}
+
+// NewVMProtectedItem creates the protected item that backs up the named VM in
+// vaultName with policyName
+func NewVMProtectedItem(vaultName, policyName, vmName string) *ProtectedItem {
+	return &ProtectedItem{
+		Name:       VMProtectedItemName(vaultName, vmName),
+		Type:       "Microsoft.RecoveryServices/vaults/backupFabrics/protectionContainers/protectedItems",
+		APIVersion: "2023-04-01",
+		Properties: ProtectedItemProperties{
+			ProtectedItemType: "Microsoft.Compute/virtualMachines",
+			PolicyID:          PolicyID(vaultName, policyName),
+			SourceResourceID:  fmt.Sprintf("[resourceId('Microsoft.Compute/virtualMachines', '%s')]", vmName),
+		},
+	}
+}
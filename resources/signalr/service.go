@@ -0,0 +1,131 @@
+// Package signalr provides Azure SignalR Service resource types
+package signalr
+
+import "fmt"
+
+// apiVersion is the Microsoft.SignalRService API version of the service
+const apiVersion = "2023-02-01"
+
+// Service represents a Microsoft.SignalRService/signalR resource: a managed
+// SignalR endpoint that pushes real-time messages to connected clients
+type Service struct {
+	// Name is the name of the service, which is also its host name prefix
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the service will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Kind is the kind of service (SignalR, RawWebSockets)
+	Kind string `json:"kind,omitempty"`
+
+	// SKU defines the pricing tier and number of units of the service
+	SKU SKU `json:"sku"`
+
+	// Properties contains the properties of the service
+	Properties ServiceProperties `json:"properties"`
+}
+
+// SKU represents the SKU of a SignalR service
+type SKU struct {
+	// Name is the SKU name (Free_F1, Standard_S1, Premium_P1)
+	Name string `json:"name"`
+
+	// Capacity is the number of units; Free_F1 allows only 1
+	Capacity int `json:"capacity,omitempty"`
+}
+
+// ServiceProperties represents the properties of a SignalR service
+type ServiceProperties struct {
+	// Features are the feature flags of the service, such as ServiceMode
+	Features []Feature `json:"features,omitempty"`
+
+	// Cors restricts the origins browsers may connect from
+	Cors *CorsSettings `json:"cors,omitempty"`
+
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+
+	// DisableLocalAuth disables access key authentication, leaving Microsoft Entra ID
+	DisableLocalAuth *bool `json:"disableLocalAuth,omitempty"`
+}
+
+// Feature represents a feature flag of a SignalR service
+type Feature struct {
+	// Flag is the feature (ServiceMode, EnableConnectivityLogs,
+	// EnableMessagingLogs, EnableLiveTrace)
+	Flag string `json:"flag"`
+
+	// Value is the setting of the feature: Default, Serverless, or Classic
+	// for ServiceMode, and True or False for the others
+	Value string `json:"value"`
+}
+
+// CorsSettings represents the CORS settings of a SignalR service
+type CorsSettings struct {
+	// AllowedOrigins are the origins allowed to connect, or "*" for all
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+}
+
+// NewService creates a SignalR service with capacity units of the SKU skuName
+func NewService(name, location, skuName string, capacity int) *Service {
+	return &Service{
+		Name:       name,
+		Type:       "Microsoft.SignalRService/signalR",
+		APIVersion: apiVersion,
+		Location:   location,
+		Kind:       "SignalR",
+		SKU: SKU{
+			Name:     skuName,
+			Capacity: capacity,
+		},
+	}
+}
+
+// WithTags adds tags to the service
+func (s *Service) WithTags(tags map[string]string) *Service {
+	s.Tags = tags
+	return s
+}
+
+// WithFeature sets the feature flag to value, replacing any earlier setting
+func (s *Service) WithFeature(flag, value string) *Service {
+	for i := range s.Properties.Features {
+		if s.Properties.Features[i].Flag == flag {
+			s.Properties.Features[i].Value = value
+			return s
+		}
+	}
+	s.Properties.Features = append(s.Properties.Features, Feature{Flag: flag, Value: value})
+	return s
+}
+
+// WithServiceMode sets the service mode: Default for hub servers, Serverless
+// for Azure Functions and REST clients, or Classic
+func (s *Service) WithServiceMode(mode string) *Service {
+	return s.WithFeature("ServiceMode", mode)
+}
+
+// WithAllowedOrigins restricts browser connections to the given origins
+func (s *Service) WithAllowedOrigins(origins ...string) *Service {
+	s.Properties.Cors = &CorsSettings{AllowedOrigins: origins}
+	return s
+}
+
+// ID returns the ARM resourceId expression for the service
+func (s *Service) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.SignalRService/signalR', '%s')]", s.Name)
+}
+
+// HostName returns an ARM expression for the host name of the service
+func (s *Service) HostName() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.SignalRService/signalR', '%s'), '%s').hostName]", s.Name, apiVersion)
+}
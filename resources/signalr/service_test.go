@@ -0,0 +1,60 @@
+package signalr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService(t *testing.T) {
+	s := NewService("chat-signalr", "eastus", "Standard_S1", 2)
+
+	assert.Equal(t, "chat-signalr", s.Name)
+	assert.Equal(t, "Microsoft.SignalRService/signalR", s.Type)
+	assert.Equal(t, "2023-02-01", s.APIVersion)
+	assert.Equal(t, "eastus", s.Location)
+	assert.Equal(t, "SignalR", s.Kind)
+	assert.Equal(t, SKU{Name: "Standard_S1", Capacity: 2}, s.SKU)
+	assert.Empty(t, s.Properties.Features)
+	assert.Nil(t, s.Properties.Cors)
+	assert.Equal(t, "[resourceId('Microsoft.SignalRService/signalR', 'chat-signalr')]", s.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.SignalRService/signalR', 'chat-signalr'), '2023-02-01').hostName]", s.HostName())
+}
+
+func TestService_Features(t *testing.T) {
+	s := NewService("chat-signalr", "eastus", "Standard_S1", 1).
+		WithServiceMode("Default").
+		WithFeature("EnableConnectivityLogs", "True").
+		WithServiceMode("Serverless").
+		WithAllowedOrigins("https://app.contoso.com").
+		WithTags(map[string]string{"env": "prod"})
+
+	assert.Equal(t, []Feature{
+		{Flag: "ServiceMode", Value: "Serverless"},
+		{Flag: "EnableConnectivityLogs", Value: "True"},
+	}, s.Properties.Features)
+	require.NotNil(t, s.Properties.Cors)
+	assert.Equal(t, []string{"https://app.contoso.com"}, s.Properties.Cors.AllowedOrigins)
+	assert.Equal(t, "prod", s.Tags["env"])
+}
+
+func TestService_JSON(t *testing.T) {
+	s := NewService("chat-signalr", "eastus", "Free_F1", 1).WithServiceMode("Serverless")
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "SignalR", result["kind"])
+	sku := result["sku"].(map[string]interface{})
+	assert.Equal(t, "Free_F1", sku["name"])
+	assert.Equal(t, float64(1), sku["capacity"])
+	props := result["properties"].(map[string]interface{})
+	features := props["features"].([]interface{})
+	require.Len(t, features, 1)
+	assert.Equal(t, map[string]interface{}{"flag": "ServiceMode", "value": "Serverless"}, features[0])
+	assert.NotContains(t, props, "cors")
+}
@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ElasticPool represents a Microsoft.Sql/servers/elasticPools resource: a
+// pool of compute and storage shared by the databases placed in it
+type ElasticPool struct {
+	// Name is the name of the pool, in the form "<server>/<pool>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the pool, the same as its server's
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// SKU is the pricing tier and size of the pool (e.g. StandardPool with
+	// 100 eDTUs, or GP_Gen5 with 2 vCores)
+	SKU SKU `json:"sku"`
+
+	// Properties contains the properties of the pool
+	Properties ElasticPoolProperties `json:"properties"`
+}
+
+// ElasticPoolProperties represents the properties of an elastic pool
+type ElasticPoolProperties struct {
+	// PerDatabaseSettings bound the capacity each database in the pool uses
+	PerDatabaseSettings *PerDatabaseSettings `json:"perDatabaseSettings,omitempty"`
+
+	// MaxSizeBytes is the storage limit of the pool in bytes
+	MaxSizeBytes *int64 `json:"maxSizeBytes,omitempty"`
+
+	// ZoneRedundant spreads the pool's replicas across availability zones
+	ZoneRedundant *bool `json:"zoneRedundant,omitempty"`
+}
+
+// PerDatabaseSettings bound the capacity, in eDTUs or vCores, that each
+// database in an elastic pool uses
+type PerDatabaseSettings struct {
+	// MinCapacity is the capacity reserved for each database
+	MinCapacity *float64 `json:"minCapacity,omitempty"`
+
+	// MaxCapacity is the most capacity any one database may use
+	MaxCapacity *float64 `json:"maxCapacity,omitempty"`
+}
+
+// NewElasticPool creates an elastic pool on the named server with the SKU
+// skuName (e.g. StandardPool, GP_Gen5) in tier (e.g. Standard,
+// GeneralPurpose) and capacity eDTUs or vCores
+func NewElasticPool(serverName, name, location, skuName, tier string, capacity int) *ElasticPool {
+	return &ElasticPool{
+		Name:       serverName + "/" + name,
+		Type:       "Microsoft.Sql/servers/elasticPools",
+		APIVersion: apiVersion,
+		Location:   location,
+		SKU: SKU{
+			Name:     skuName,
+			Tier:     tier,
+			Capacity: &capacity,
+		},
+	}
+}
+
+// WithPerDatabaseSettings limits each database in the pool to between
+// minCapacity and maxCapacity eDTUs or vCores
+func (p *ElasticPool) WithPerDatabaseSettings(minCapacity, maxCapacity float64) *ElasticPool {
+	p.Properties.PerDatabaseSettings = &PerDatabaseSettings{
+		MinCapacity: &minCapacity,
+		MaxCapacity: &maxCapacity,
+	}
+	return p
+}
+
+// WithTags adds tags to the pool
+func (p *ElasticPool) WithTags(tags map[string]string) *ElasticPool {
+	p.Tags = tags
+	return p
+}
+
+// ID returns the ARM resourceId expression for the pool
+func (p *ElasticPool) ID() string {
+	server, name, _ := strings.Cut(p.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Sql/servers/elasticPools', '%s', '%s')]", server, name)
+}
@@ -0,0 +1,117 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Failover policies of failover group endpoints
+const (
+	// FailoverPolicyAutomatic fails over when an outage exceeds the grace period
+	FailoverPolicyAutomatic = "Automatic"
+
+	// FailoverPolicyManual only fails over when requested
+	FailoverPolicyManual = "Manual"
+)
+
+// FailoverGroup represents a Microsoft.Sql/servers/failoverGroups resource:
+// databases on a primary server replicated to a partner server in another
+// region, behind listener endpoints that follow a failover
+type FailoverGroup struct {
+	// Name is the name of the failover group, in the form "<server>/<group>";
+	// the group name is part of the listener DNS names, so it is globally unique
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the failover group
+	Properties FailoverGroupProperties `json:"properties"`
+}
+
+// FailoverGroupProperties represents the properties of a failover group
+type FailoverGroupProperties struct {
+	// ReadWriteEndpoint sets how the read-write listener fails over
+	ReadWriteEndpoint ReadWriteEndpoint `json:"readWriteEndpoint"`
+
+	// ReadOnlyEndpoint sets how the read-only listener fails over
+	ReadOnlyEndpoint *ReadOnlyEndpoint `json:"readOnlyEndpoint,omitempty"`
+
+	// PartnerServers are the secondary servers of the group
+	PartnerServers []PartnerServer `json:"partnerServers"`
+
+	// Databases are the resource IDs of the primary server's databases in
+	// the group, such as db.ID()
+	Databases []string `json:"databases,omitempty"`
+}
+
+// ReadWriteEndpoint sets how the read-write listener of a failover group
+// fails over
+type ReadWriteEndpoint struct {
+	// FailoverPolicy is the failover policy (Automatic, Manual)
+	FailoverPolicy string `json:"failoverPolicy"`
+
+	// FailoverWithDataLossGracePeriodMinutes is how long an outage lasts
+	// before an automatic failover, which may lose data; required with the
+	// Automatic policy
+	FailoverWithDataLossGracePeriodMinutes *int `json:"failoverWithDataLossGracePeriodMinutes,omitempty"`
+}
+
+// ReadOnlyEndpoint sets how the read-only listener of a failover group
+// fails over
+type ReadOnlyEndpoint struct {
+	// FailoverPolicy is the failover policy (Enabled, Disabled)
+	FailoverPolicy string `json:"failoverPolicy"`
+}
+
+// PartnerServer identifies a secondary server of a failover group
+type PartnerServer struct {
+	// ID is the resource ID of the server, such as server.ID()
+	ID string `json:"id"`
+}
+
+// NewFailoverGroup creates a failover group on the named primary server
+// that replicates the databases with the resource IDs databaseIDs to the
+// server with the resource ID partnerServerID, failing over automatically
+// after a one hour outage
+func NewFailoverGroup(serverName, name, partnerServerID string, databaseIDs ...string) *FailoverGroup {
+	gracePeriod := 60
+	return &FailoverGroup{
+		Name:       serverName + "/" + name,
+		Type:       "Microsoft.Sql/servers/failoverGroups",
+		APIVersion: apiVersion,
+		Properties: FailoverGroupProperties{
+			ReadWriteEndpoint: ReadWriteEndpoint{
+				FailoverPolicy:                         FailoverPolicyAutomatic,
+				FailoverWithDataLossGracePeriodMinutes: &gracePeriod,
+			},
+			PartnerServers: []PartnerServer{{ID: partnerServerID}},
+			Databases:      databaseIDs,
+		},
+	}
+}
+
+// WithManualFailover makes the read-write listener fail over only when
+// requested
+func (g *FailoverGroup) WithManualFailover() *FailoverGroup {
+	g.Properties.ReadWriteEndpoint = ReadWriteEndpoint{FailoverPolicy: FailoverPolicyManual}
+	return g
+}
+
+// WithTags adds tags to the failover group
+func (g *FailoverGroup) WithTags(tags map[string]string) *FailoverGroup {
+	g.Tags = tags
+	return g
+}
+
+// ID returns the ARM resourceId expression for the failover group
+func (g *FailoverGroup) ID() string {
+	server, name, _ := strings.Cut(g.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Sql/servers/failoverGroups', '%s', '%s')]", server, name)
+}
@@ -0,0 +1,15 @@
+package sql
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/sql"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "Server", "Microsoft.Sql/servers")
+	template.RegisterResourceType(importPath, "Database", "Microsoft.Sql/servers/databases")
+	template.RegisterResourceType(importPath, "ElasticPool", "Microsoft.Sql/servers/elasticPools")
+	template.RegisterResourceType(importPath, "FailoverGroup", "Microsoft.Sql/servers/failoverGroups")
+}
@@ -0,0 +1,173 @@
+// Package sql provides Azure SQL Database resource types
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// apiVersion is the API version of the Azure SQL resources
+const apiVersion = "2021-11-01"
+
+// Server represents a Microsoft.Sql/servers resource: a logical server that
+// hosts databases and elastic pools
+type Server struct {
+	// Name is the name of the server (1-63 characters, globally unique)
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the server will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the server
+	Properties ServerProperties `json:"properties"`
+}
+
+// ServerProperties represents the properties of a SQL server
+type ServerProperties struct {
+	// AdministratorLogin is the SQL administrator login name
+	AdministratorLogin string `json:"administratorLogin,omitempty"`
+
+	// AdministratorLoginPassword is the SQL administrator password, usually
+	// a secure parameter
+	AdministratorLoginPassword string `json:"administratorLoginPassword,omitempty"`
+
+	// Version is the server version (12.0)
+	Version string `json:"version,omitempty"`
+
+	// MinimalTLSVersion is the minimum TLS version of connections (1.0, 1.1, 1.2)
+	MinimalTLSVersion *string `json:"minimalTlsVersion,omitempty"`
+
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+}
+
+// Database represents a Microsoft.Sql/servers/databases resource
+type Database struct {
+	// Name is the name of the database, in the form "<server>/<database>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the database, the same as its server's
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// SKU is the pricing tier of the database; databases in an elastic pool
+	// take theirs from the pool
+	SKU *SKU `json:"sku,omitempty"`
+
+	// Properties contains the properties of the database
+	Properties DatabaseProperties `json:"properties"`
+}
+
+// DatabaseProperties represents the properties of a SQL database
+type DatabaseProperties struct {
+	// ElasticPoolID is the resource ID of the elastic pool the database is
+	// in, such as pool.ID()
+	ElasticPoolID string `json:"elasticPoolId,omitempty"`
+
+	// Collation is the collation of the database (e.g. SQL_Latin1_General_CP1_CI_AS)
+	Collation string `json:"collation,omitempty"`
+
+	// MaxSizeBytes is the maximum size of the database in bytes
+	MaxSizeBytes *int64 `json:"maxSizeBytes,omitempty"`
+
+	// ZoneRedundant spreads the database's replicas across availability zones
+	ZoneRedundant *bool `json:"zoneRedundant,omitempty"`
+}
+
+// SKU represents the SKU of a SQL database or elastic pool
+type SKU struct {
+	// Name is the SKU name (e.g. Basic, S0, GP_Gen5_2 for databases;
+	// StandardPool, GP_Gen5 for elastic pools)
+	Name string `json:"name"`
+
+	// Tier is the service tier (Basic, Standard, Premium, GeneralPurpose,
+	// BusinessCritical)
+	Tier string `json:"tier,omitempty"`
+
+	// Family is the hardware generation of vCore SKUs (Gen5)
+	Family string `json:"family,omitempty"`
+
+	// Capacity is the DTUs or vCores of the SKU
+	Capacity *int `json:"capacity,omitempty"`
+}
+
+// NewServer creates a SQL server (version 12.0) with the administrator
+// login adminLogin and the password adminPassword, usually a secure
+// parameter expression such as "[parameters('sqlAdminPassword')]"
+func NewServer(name, location, adminLogin, adminPassword string) *Server {
+	return &Server{
+		Name:       name,
+		Type:       "Microsoft.Sql/servers",
+		APIVersion: apiVersion,
+		Location:   location,
+		Properties: ServerProperties{
+			AdministratorLogin:         adminLogin,
+			AdministratorLoginPassword: adminPassword,
+			Version:                    "12.0",
+		},
+	}
+}
+
+// WithTags adds tags to the server
+func (s *Server) WithTags(tags map[string]string) *Server {
+	s.Tags = tags
+	return s
+}
+
+// ID returns the ARM resourceId expression for the server
+func (s *Server) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Sql/servers', '%s')]", s.Name)
+}
+
+// NewDatabase creates a database on the named server
+func NewDatabase(serverName, name, location string) *Database {
+	return &Database{
+		Name:       serverName + "/" + name,
+		Type:       "Microsoft.Sql/servers/databases",
+		APIVersion: apiVersion,
+		Location:   location,
+	}
+}
+
+// WithSKU sets the pricing tier of the database
+func (d *Database) WithSKU(name, tier string) *Database {
+	d.SKU = &SKU{Name: name, Tier: tier}
+	return d
+}
+
+// WithElasticPool places the database in the elastic pool with the resource
+// ID poolID, such as pool.ID(). The database then uses the pool's SKU.
+func (d *Database) WithElasticPool(poolID string) *Database {
+	d.Properties.ElasticPoolID = poolID
+	d.SKU = nil
+	return d
+}
+
+// WithTags adds tags to the database
+func (d *Database) WithTags(tags map[string]string) *Database {
+	d.Tags = tags
+	return d
+}
+
+// ID returns the ARM resourceId expression for the database
+func (d *Database) ID() string {
+	server, name, _ := strings.Cut(d.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Sql/servers/databases', '%s', '%s')]", server, name)
+}
@@ -0,0 +1,79 @@
+package sql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServer(t *testing.T) {
+	server := NewServer("orders-sql", "eastus", "sqladmin", "[parameters('sqlAdminPassword')]")
+
+	assert.Equal(t, "orders-sql", server.Name)
+	assert.Equal(t, "Microsoft.Sql/servers", server.Type)
+	assert.Equal(t, "2021-11-01", server.APIVersion)
+	assert.Equal(t, "sqladmin", server.Properties.AdministratorLogin)
+	assert.Equal(t, "12.0", server.Properties.Version)
+	assert.Equal(t, "[resourceId('Microsoft.Sql/servers', 'orders-sql')]", server.ID())
+}
+
+func TestNewDatabase_WithElasticPool(t *testing.T) {
+	pool := NewElasticPool("orders-sql", "orders-pool", "eastus", "StandardPool", "Standard", 100)
+	db := NewDatabase("orders-sql", "orders", "eastus").
+		WithSKU("S0", "Standard").
+		WithElasticPool(pool.ID())
+
+	assert.Equal(t, "orders-sql/orders", db.Name)
+	assert.Equal(t, "Microsoft.Sql/servers/databases", db.Type)
+	assert.Nil(t, db.SKU, "databases in a pool use the pool's SKU")
+	assert.Equal(t, "[resourceId('Microsoft.Sql/servers/elasticPools', 'orders-sql', 'orders-pool')]", db.Properties.ElasticPoolID)
+	assert.Equal(t, "[resourceId('Microsoft.Sql/servers/databases', 'orders-sql', 'orders')]", db.ID())
+}
+
+func TestNewElasticPool(t *testing.T) {
+	pool := NewElasticPool("orders-sql", "orders-pool", "eastus", "GP_Gen5", "GeneralPurpose", 2).
+		WithPerDatabaseSettings(0, 1)
+
+	assert.Equal(t, "orders-sql/orders-pool", pool.Name)
+	assert.Equal(t, "Microsoft.Sql/servers/elasticPools", pool.Type)
+	assert.Equal(t, "2021-11-01", pool.APIVersion)
+
+	data, err := json.Marshal(pool)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "orders-sql/orders-pool",
+		"type": "Microsoft.Sql/servers/elasticPools",
+		"apiVersion": "2021-11-01",
+		"location": "eastus",
+		"sku": {"name": "GP_Gen5", "tier": "GeneralPurpose", "capacity": 2},
+		"properties": {"perDatabaseSettings": {"minCapacity": 0, "maxCapacity": 1}}
+	}`, string(data))
+}
+
+func TestNewFailoverGroup(t *testing.T) {
+	secondary := NewServer("orders-sql-west", "westus", "sqladmin", "[parameters('sqlAdminPassword')]")
+	db := NewDatabase("orders-sql", "orders", "eastus")
+	group := NewFailoverGroup("orders-sql", "orders-fog", secondary.ID(), db.ID())
+
+	assert.Equal(t, "orders-sql/orders-fog", group.Name)
+	assert.Equal(t, "Microsoft.Sql/servers/failoverGroups", group.Type)
+	assert.Equal(t, "[resourceId('Microsoft.Sql/servers/failoverGroups', 'orders-sql', 'orders-fog')]", group.ID())
+
+	data, err := json.Marshal(group)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "orders-sql/orders-fog",
+		"type": "Microsoft.Sql/servers/failoverGroups",
+		"apiVersion": "2021-11-01",
+		"properties": {
+			"readWriteEndpoint": {"failoverPolicy": "Automatic", "failoverWithDataLossGracePeriodMinutes": 60},
+			"partnerServers": [{"id": "[resourceId('Microsoft.Sql/servers', 'orders-sql-west')]"}],
+			"databases": ["[resourceId('Microsoft.Sql/servers/databases', 'orders-sql', 'orders')]"]
+		}
+	}`, string(data))
+
+	group.WithManualFailover()
+	assert.Equal(t, ReadWriteEndpoint{FailoverPolicy: FailoverPolicyManual}, group.Properties.ReadWriteEndpoint)
+}
@@ -0,0 +1,162 @@
+package storage
+
+// ManagementPolicy represents a Microsoft.Storage/storageAccounts/managementPolicies resource.
+// A storage account has a single management policy, named "<account>/default".
+type ManagementPolicy struct {
+	// Name is the name of the policy, in the form "<account>/default"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the management policy
+	Properties ManagementPolicyProperties `json:"properties"`
+}
+
+// ManagementPolicyProperties represents the properties of a management policy
+type ManagementPolicyProperties struct {
+	// Policy is the lifecycle management policy
+	Policy ManagementPolicySchema `json:"policy"`
+}
+
+// ManagementPolicySchema represents the lifecycle rules of a management policy
+type ManagementPolicySchema struct {
+	// Rules are the lifecycle management rules
+	Rules []ManagementPolicyRule `json:"rules"`
+}
+
+// ManagementPolicyRule represents a single lifecycle management rule
+type ManagementPolicyRule struct {
+	// Name is the name of the rule (unique within the policy)
+	Name string `json:"name"`
+
+	// Enabled indicates whether the rule is enabled
+	Enabled bool `json:"enabled"`
+
+	// Type is the rule type (Lifecycle)
+	Type string `json:"type"`
+
+	// Definition defines which blobs the rule applies to and what it does
+	Definition ManagementPolicyDefinition `json:"definition"`
+}
+
+// ManagementPolicyDefinition represents the filters and actions of a rule
+type ManagementPolicyDefinition struct {
+	// Filters limit the rule to a subset of blobs
+	Filters *ManagementPolicyFilter `json:"filters,omitempty"`
+
+	// Actions are the actions applied to matching blobs
+	Actions ManagementPolicyAction `json:"actions"`
+}
+
+// ManagementPolicyFilter represents the blob filter of a rule
+type ManagementPolicyFilter struct {
+	// BlobTypes are the blob types the rule applies to (blockBlob, appendBlob)
+	BlobTypes []string `json:"blobTypes"`
+
+	// PrefixMatch are blob name prefixes, starting with the container name
+	PrefixMatch []string `json:"prefixMatch,omitempty"`
+}
+
+// ManagementPolicyAction represents the actions of a rule
+type ManagementPolicyAction struct {
+	// BaseBlob defines actions on the current version of a blob
+	BaseBlob *ManagementPolicyBaseBlob `json:"baseBlob,omitempty"`
+
+	// Snapshot defines actions on blob snapshots
+	Snapshot *ManagementPolicySnapshot `json:"snapshot,omitempty"`
+}
+
+// ManagementPolicyBaseBlob represents actions on the current version of a blob
+type ManagementPolicyBaseBlob struct {
+	// TierToCool moves blobs to the cool tier
+	TierToCool *DateAfterModification `json:"tierToCool,omitempty"`
+
+	// TierToArchive moves blobs to the archive tier
+	TierToArchive *DateAfterModification `json:"tierToArchive,omitempty"`
+
+	// Delete deletes blobs
+	Delete *DateAfterModification `json:"delete,omitempty"`
+}
+
+// ManagementPolicySnapshot represents actions on blob snapshots
+type ManagementPolicySnapshot struct {
+	// Delete deletes snapshots
+	Delete *DateAfterCreation `json:"delete,omitempty"`
+}
+
+// DateAfterModification is an age condition based on last modification time
+type DateAfterModification struct {
+	// DaysAfterModificationGreaterThan is the number of days since the blob was last modified
+	DaysAfterModificationGreaterThan *int `json:"daysAfterModificationGreaterThan,omitempty"`
+}
+
+// DateAfterCreation is an age condition based on creation time
+type DateAfterCreation struct {
+	// DaysAfterCreationGreaterThan is the number of days since the snapshot was created
+	DaysAfterCreationGreaterThan int `json:"daysAfterCreationGreaterThan"`
+}
+
+// NewManagementPolicy creates an empty lifecycle management policy for the named storage account
+func NewManagementPolicy(accountName string) *ManagementPolicy {
+	return &ManagementPolicy{
+		Name:       accountName + "/default",
+		Type:       "Microsoft.Storage/storageAccounts/managementPolicies",
+		APIVersion: "2021-04-01",
+		Properties: ManagementPolicyProperties{
+			Policy: ManagementPolicySchema{
+				Rules: []ManagementPolicyRule{},
+			},
+		},
+	}
+}
+
+// AddRule appends a lifecycle rule to the policy
+func (m *ManagementPolicy) AddRule(rule ManagementPolicyRule) *ManagementPolicy {
+	m.Properties.Policy.Rules = append(m.Properties.Policy.Rules, rule)
+	return m
+}
+
+// AddTierToCoolRule adds a rule moving block blobs to the cool tier
+// daysAfterModification days after they were last modified
+func (m *ManagementPolicy) AddTierToCoolRule(daysAfterModification int) *ManagementPolicy {
+	return m.AddRule(newBaseBlobRule("tier-to-cool", &ManagementPolicyBaseBlob{
+		TierToCool: &DateAfterModification{DaysAfterModificationGreaterThan: &daysAfterModification},
+	}))
+}
+
+// AddTierToArchiveRule adds a rule moving block blobs to the archive tier
+// daysAfterModification days after they were last modified
+func (m *ManagementPolicy) AddTierToArchiveRule(daysAfterModification int) *ManagementPolicy {
+	return m.AddRule(newBaseBlobRule("tier-to-archive", &ManagementPolicyBaseBlob{
+		TierToArchive: &DateAfterModification{DaysAfterModificationGreaterThan: &daysAfterModification},
+	}))
+}
+
+// AddDeleteRule adds a rule deleting block blobs daysAfterModification days
+// after they were last modified
+func (m *ManagementPolicy) AddDeleteRule(daysAfterModification int) *ManagementPolicy {
+	return m.AddRule(newBaseBlobRule("delete", &ManagementPolicyBaseBlob{
+		Delete: &DateAfterModification{DaysAfterModificationGreaterThan: &daysAfterModification},
+	}))
+}
+
+// newBaseBlobRule creates an enabled lifecycle rule for block blobs
+func newBaseBlobRule(name string, baseBlob *ManagementPolicyBaseBlob) ManagementPolicyRule {
+	return ManagementPolicyRule{
+		Name:    name,
+		Enabled: true,
+		Type:    "Lifecycle",
+		Definition: ManagementPolicyDefinition{
+			Filters: &ManagementPolicyFilter{
+				BlobTypes: []string{"blockBlob"},
+			},
+			Actions: ManagementPolicyAction{
+				BaseBlob: baseBlob,
+			},
+		},
+	}
+}
@@ -0,0 +1,19 @@
+package storage
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/storage"
+
+// init registers the resource structs of this package for discovery, and
+// the ones discovery evaluates for validation
+func init() {
+	template.RegisterResourceType(importPath, "StorageAccount", "Microsoft.Storage/storageAccounts")
+	template.RegisterResourceType(importPath, "ManagementPolicy", "Microsoft.Storage/storageAccounts/managementPolicies")
+	template.RegisterResourceType(importPath, "BlobService", "Microsoft.Storage/storageAccounts/blobServices")
+	template.RegisterResourceType(importPath, "QueueService", "Microsoft.Storage/storageAccounts/queueServices")
+	template.RegisterResourceType(importPath, "TableService", "Microsoft.Storage/storageAccounts/tableServices")
+
+	template.RegisterValueType(importPath, "StorageAccount", StorageAccount{})
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// servicesAPIVersion is the API version of the storage service resources
+const servicesAPIVersion = "2023-01-01"
+
+// CORSRules represents the CORS configuration of a storage service
+type CORSRules struct {
+	// CORSRules are the rules, evaluated in order; at most five per service
+	CORSRules []CORSRule `json:"corsRules"`
+}
+
+// CORSRule represents a CORS rule allowing browsers on other origins to call
+// a storage service. ARM requires every list to have an entry; empty lists
+// are left out of generated templates, so use []string{"*"} for any header.
+type CORSRule struct {
+	// AllowedOrigins are the allowed origin domains, or "*" for all
+	AllowedOrigins []string `json:"allowedOrigins"`
+
+	// AllowedMethods are the allowed HTTP methods (e.g. GET, PUT)
+	AllowedMethods []string `json:"allowedMethods"`
+
+	// AllowedHeaders are the request headers the origins may send
+	AllowedHeaders []string `json:"allowedHeaders"`
+
+	// ExposedHeaders are the response headers exposed to the origins
+	ExposedHeaders []string `json:"exposedHeaders"`
+
+	// MaxAgeInSeconds is how long browsers may cache the preflight response
+	MaxAgeInSeconds int `json:"maxAgeInSeconds"`
+}
+
+// addCORSRule appends rule to cors and returns the updated configuration
+func addCORSRule(cors *CORSRules, rule CORSRule) *CORSRules {
+	if cors == nil {
+		cors = &CORSRules{}
+	}
+	cors.CORSRules = append(cors.CORSRules, rule)
+	return cors
+}
+
+// BlobService represents a Microsoft.Storage/storageAccounts/blobServices
+// resource: the default blob service of a storage account, declared apart
+// from the account. Declare it either this way or with the account's
+// Properties.BlobServices, not both.
+type BlobService struct {
+	// Name is the name of the service, in the form "<account>/default"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the blob service
+	Properties BlobServiceProperties `json:"properties"`
+}
+
+// NewBlobService creates the default blob service of the named account
+func NewBlobService(accountName string) *BlobService {
+	return &BlobService{
+		Name:       accountName + "/default",
+		Type:       "Microsoft.Storage/storageAccounts/blobServices",
+		APIVersion: servicesAPIVersion,
+	}
+}
+
+// WithCORSRule adds a CORS rule to the blob service
+func (s *BlobService) WithCORSRule(rule CORSRule) *BlobService {
+	s.Properties.Cors = addCORSRule(s.Properties.Cors, rule)
+	return s
+}
+
+// WithDataProtection enables blob versioning and soft delete of blobs and
+// containers, retaining deleted data for days
+func (s *BlobService) WithDataProtection(days int) *BlobService {
+	s.Properties.DeleteRetentionPolicy = &DeleteRetentionPolicy{Enabled: true, Days: days}
+	s.Properties.ContainerDeleteRetentionPolicy = &DeleteRetentionPolicy{Enabled: true, Days: days}
+	s.Properties.IsVersioningEnabled = true
+	return s
+}
+
+// ID returns the ARM resourceId expression for the blob service
+func (s *BlobService) ID() string {
+	return serviceID(s.Type, s.Name)
+}
+
+// QueueService represents a Microsoft.Storage/storageAccounts/queueServices
+// resource: the default queue service of a storage account
+type QueueService struct {
+	// Name is the name of the service, in the form "<account>/default"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the queue service
+	Properties QueueServiceProperties `json:"properties"`
+}
+
+// QueueServiceProperties represents the properties of a storage account's
+// queue service
+type QueueServiceProperties struct {
+	// Cors configures cross-origin requests to the queue service
+	Cors *CORSRules `json:"cors,omitempty"`
+}
+
+// NewQueueService creates the default queue service of the named account
+func NewQueueService(accountName string) *QueueService {
+	return &QueueService{
+		Name:       accountName + "/default",
+		Type:       "Microsoft.Storage/storageAccounts/queueServices",
+		APIVersion: servicesAPIVersion,
+	}
+}
+
+// WithCORSRule adds a CORS rule to the queue service
+func (s *QueueService) WithCORSRule(rule CORSRule) *QueueService {
+	s.Properties.Cors = addCORSRule(s.Properties.Cors, rule)
+	return s
+}
+
+// ID returns the ARM resourceId expression for the queue service
+func (s *QueueService) ID() string {
+	return serviceID(s.Type, s.Name)
+}
+
+// TableService represents a Microsoft.Storage/storageAccounts/tableServices
+// resource: the default table service of a storage account
+type TableService struct {
+	// Name is the name of the service, in the form "<account>/default"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the table service
+	Properties TableServiceProperties `json:"properties"`
+}
+
+// TableServiceProperties represents the properties of a storage account's
+// table service
+type TableServiceProperties struct {
+	// Cors configures cross-origin requests to the table service
+	Cors *CORSRules `json:"cors,omitempty"`
+}
+
+// NewTableService creates the default table service of the named account
+func NewTableService(accountName string) *TableService {
+	return &TableService{
+		Name:       accountName + "/default",
+		Type:       "Microsoft.Storage/storageAccounts/tableServices",
+		APIVersion: servicesAPIVersion,
+	}
+}
+
+// WithCORSRule adds a CORS rule to the table service
+func (s *TableService) WithCORSRule(rule CORSRule) *TableService {
+	s.Properties.Cors = addCORSRule(s.Properties.Cors, rule)
+	return s
+}
+
+// ID returns the ARM resourceId expression for the table service
+func (s *TableService) ID() string {
+	return serviceID(s.Type, s.Name)
+}
+
+// serviceID returns the resourceId expression of the service resourceType
+// named "<account>/<service>"
+func serviceID(resourceType, name string) string {
+	account, service, _ := strings.Cut(name, "/")
+	return fmt.Sprintf("[resourceId('%s', '%s', '%s')]", resourceType, account, service)
+}
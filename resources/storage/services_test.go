@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBlobService(t *testing.T) {
+	service := NewBlobService("orders").
+		WithDataProtection(14).
+		WithCORSRule(CORSRule{
+			AllowedOrigins:  []string{"https://shop.example.com"},
+			AllowedMethods:  []string{"GET", "PUT"},
+			MaxAgeInSeconds: 3600,
+		})
+
+	assert.Equal(t, "orders/default", service.Name)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/blobServices", service.Type)
+	assert.Equal(t, "2023-01-01", service.APIVersion)
+	assert.True(t, service.Properties.IsVersioningEnabled)
+	assert.Equal(t, &DeleteRetentionPolicy{Enabled: true, Days: 14}, service.Properties.DeleteRetentionPolicy)
+	assert.Equal(t, &DeleteRetentionPolicy{Enabled: true, Days: 14}, service.Properties.ContainerDeleteRetentionPolicy)
+	require.NotNil(t, service.Properties.Cors)
+	require.Len(t, service.Properties.Cors.CORSRules, 1)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts/blobServices', 'orders', 'default')]", service.ID())
+}
+
+func TestQueueAndTableServices(t *testing.T) {
+	rule := CORSRule{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}
+
+	queues := NewQueueService("orders").WithCORSRule(rule)
+	assert.Equal(t, "orders/default", queues.Name)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/queueServices", queues.Type)
+	require.NotNil(t, queues.Properties.Cors)
+	assert.Len(t, queues.Properties.Cors.CORSRules, 1)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts/queueServices', 'orders', 'default')]", queues.ID())
+
+	tables := NewTableService("orders").WithCORSRule(rule).WithCORSRule(rule)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/tableServices", tables.Type)
+	require.NotNil(t, tables.Properties.Cors)
+	assert.Len(t, tables.Properties.Cors.CORSRules, 2)
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts/tableServices', 'orders', 'default')]", tables.ID())
+}
+
+func TestWithCORSRule_JSON(t *testing.T) {
+	service := NewQueueService("orders").WithCORSRule(CORSRule{
+		AllowedOrigins:  []string{"https://shop.example.com"},
+		AllowedMethods:  []string{"GET"},
+		AllowedHeaders:  []string{"*"},
+		ExposedHeaders:  []string{"x-ms-meta-*"},
+		MaxAgeInSeconds: 600,
+	})
+
+	data, err := json.Marshal(service)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, map[string]interface{}{
+		"cors": map[string]interface{}{
+			"corsRules": []interface{}{
+				map[string]interface{}{
+					"allowedOrigins":  []interface{}{"https://shop.example.com"},
+					"allowedMethods":  []interface{}{"GET"},
+					"allowedHeaders":  []interface{}{"*"},
+					"exposedHeaders":  []interface{}{"x-ms-meta-*"},
+					"maxAgeInSeconds": float64(600),
+				},
+			},
+		},
+	}, result["properties"])
+
+	data, err = json.Marshal(NewTableService("orders"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"orders/default","type":"Microsoft.Storage/storageAccounts/tableServices","apiVersion":"2023-01-01","properties":{}}`, string(data))
+}
@@ -175,3 +175,284 @@ func TestIdentity(t *testing.T) {
 
 	assert.Equal(t, "SystemAssigned", result["type"])
 }
+
+func TestNewManagementPolicy(t *testing.T) {
+	policy := NewManagementPolicy("auditstorage")
+
+	assert.Equal(t, "auditstorage/default", policy.Name)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts/managementPolicies", policy.Type)
+	assert.Equal(t, "2021-04-01", policy.APIVersion)
+	assert.Empty(t, policy.Properties.Policy.Rules)
+}
+
+func TestManagementPolicy_AddTierToCoolRule(t *testing.T) {
+	policy := NewManagementPolicy("auditstorage").
+		AddTierToCoolRule(30).
+		AddTierToArchiveRule(90).
+		AddDeleteRule(365)
+
+	require.Len(t, policy.Properties.Policy.Rules, 3)
+
+	cool := policy.Properties.Policy.Rules[0]
+	assert.Equal(t, "tier-to-cool", cool.Name)
+	assert.True(t, cool.Enabled)
+	assert.Equal(t, "Lifecycle", cool.Type)
+	require.NotNil(t, cool.Definition.Filters)
+	assert.Equal(t, []string{"blockBlob"}, cool.Definition.Filters.BlobTypes)
+	require.NotNil(t, cool.Definition.Actions.BaseBlob)
+	require.NotNil(t, cool.Definition.Actions.BaseBlob.TierToCool)
+	assert.Equal(t, 30, *cool.Definition.Actions.BaseBlob.TierToCool.DaysAfterModificationGreaterThan)
+
+	archive := policy.Properties.Policy.Rules[1].Definition.Actions.BaseBlob
+	require.NotNil(t, archive.TierToArchive)
+	assert.Equal(t, 90, *archive.TierToArchive.DaysAfterModificationGreaterThan)
+
+	del := policy.Properties.Policy.Rules[2].Definition.Actions.BaseBlob
+	require.NotNil(t, del.Delete)
+	assert.Equal(t, 365, *del.Delete.DaysAfterModificationGreaterThan)
+}
+
+func TestManagementPolicy_JSON(t *testing.T) {
+	policy := NewManagementPolicy("auditstorage").AddTierToCoolRule(30)
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	rules := props["policy"].(map[string]interface{})["rules"].([]interface{})
+	require.Len(t, rules, 1)
+
+	rule := rules[0].(map[string]interface{})
+	actions := rule["definition"].(map[string]interface{})["actions"].(map[string]interface{})
+	baseBlob := actions["baseBlob"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"daysAfterModificationGreaterThan": float64(30)}, baseBlob["tierToCool"])
+	assert.NotContains(t, baseBlob, "delete")
+}
+
+func TestStorageAccount_References(t *testing.T) {
+	sa := NewStorageAccount("mylake", "eastus", "StorageV2", "Standard_LRS")
+
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'mylake')]", sa.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.Storage/storageAccounts', 'mylake'), '2021-04-01').primaryEndpoints.dfs]", sa.DFSEndpoint())
+}
+
+func TestStorageAccount_Secrets(t *testing.T) {
+	sa := NewStorageAccount("mystorage", "eastus", "StorageV2", "Standard_LRS")
+
+	assert.Equal(t, "[listKeys(resourceId('Microsoft.Storage/storageAccounts', 'mystorage'), '2021-04-01').keys[0].value]", sa.PrimaryKey())
+	assert.Equal(t, "[concat('DefaultEndpointsProtocol=https;AccountName=', 'mystorage', ';AccountKey=', "+
+		"listKeys(resourceId('Microsoft.Storage/storageAccounts', 'mystorage'), '2021-04-01').keys[0].value, "+
+		"';EndpointSuffix=', environment().suffixes.storage)]", sa.ConnectionString())
+	assert.Equal(t, "[listAccountSas(resourceId('Microsoft.Storage/storageAccounts', 'mystorage'), '2021-04-01', "+
+		"createObject('signedServices', 'b', 'signedResourceTypes', 'sco', 'signedPermission', 'rl', "+
+		"'signedProtocol', 'https', 'signedExpiry', '2030-01-01T00:00:00Z')).accountSasToken]",
+		sa.AccountSAS("b", "sco", "rl", "2030-01-01T00:00:00Z"))
+}
+
+func TestStorageAccount_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		account *StorageAccount
+		wantErr []string
+	}{
+		{"valid", NewStorageAccount("mystorage", "eastus", "StorageV2", "Standard_LRS"), nil},
+		{"premium file storage", NewStorageAccount("myfiles", "eastus", "FileStorage", "Premium_ZRS"), nil},
+		{"expression name", NewStorageAccount("[parameters('name')]", "eastus", "StorageV2", "Standard_LRS"), nil},
+		{"unset kind", &StorageAccount{Name: "mystorage", SKU: SKU{Name: "Premium_ZRS"}}, nil},
+		{"uppercase name", NewStorageAccount("MyStorage", "eastus", "StorageV2", "Standard_LRS"),
+			[]string{"only lowercase letters and numbers"}},
+		{"long name with hyphen", NewStorageAccount("my-storage-account-for-logs", "eastus", "StorageV2", "Standard_LRS"),
+			[]string{"3-24 characters", "only lowercase letters and numbers"}},
+		{"standard block blob storage", NewStorageAccount("myblobs", "eastus", "BlockBlobStorage", "Standard_LRS"),
+			[]string{"kind BlockBlobStorage requires a Premium SKU"}},
+		{"zone-redundant blob storage", NewStorageAccount("myblobs", "eastus", "BlobStorage", "Standard_ZRS"),
+			[]string{"kind BlobStorage supports"}},
+		{"premium ZRS general purpose", NewStorageAccount("mystorage", "eastus", "StorageV2", "Premium_ZRS"),
+			[]string{"does not support Premium_ZRS"}},
+		{"GZRS on v1", NewStorageAccount("mystorage", "eastus", "Storage", "Standard_GZRS"),
+			[]string{"use StorageV2"}},
+		{"access tier on v2", withAccessTier("StorageV2", "Cool"), nil},
+		{"access tier on v1", withAccessTier("Storage", "Hot"),
+			[]string{"kind Storage does not support an access tier"}},
+		{"access tier on block blob storage", withAccessTier("BlockBlobStorage", "Hot"),
+			[]string{"kind BlockBlobStorage does not support an access tier"}},
+		{"SFTP with HNS", withFeatures(true, true, false, nil), nil},
+		{"NFSv3 with HNS and HTTP", withFeatures(true, false, true, boolPtr(false)), nil},
+		{"SFTP and NFSv3 disabled", withFeatures(false, false, false, nil), nil},
+		{"SFTP without HNS", withFeatures(false, true, false, nil),
+			[]string{"SFTP (IsSftpEnabled) requires hierarchical namespace"}},
+		{"NFSv3 with HTTPS-only default", withFeatures(true, false, true, nil),
+			[]string{"NFSv3 (IsNfsV3Enabled) requires HTTPS-only traffic to be off"}},
+		{"NFSv3 without HNS and with HTTPS-only", withFeatures(false, false, true, boolPtr(true)),
+			[]string{"NFSv3 (IsNfsV3Enabled) requires hierarchical namespace", "requires HTTPS-only traffic to be off"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.account.Validate()
+			require.Len(t, errs, len(tt.wantErr), "errors: %v", errs)
+			for i, want := range tt.wantErr {
+				assert.Contains(t, errs[i].Error(), want)
+			}
+		})
+	}
+}
+
+// withAccessTier returns an account of kind with the access tier set and a
+// SKU offered for the kind
+func withAccessTier(kind, tier string) *StorageAccount {
+	sku := "Standard_LRS"
+	if premiumOnlyKinds[kind] {
+		sku = "Premium_LRS"
+	}
+	sa := NewStorageAccount("mystorage", "eastus", kind, sku)
+	sa.Properties = &StorageAccountProperties{AccessTier: &tier}
+	return sa
+}
+
+// withFeatures returns a StorageV2 account with hierarchical namespace, SFTP
+// and NFSv3 set as given, and HTTPS-only traffic set if httpsOnly is not nil
+func withFeatures(hns, sftp, nfsV3 bool, httpsOnly *bool) *StorageAccount {
+	sa := NewStorageAccount("datalake", "eastus", "StorageV2", "Standard_LRS")
+	sa.Properties = &StorageAccountProperties{
+		IsHnsEnabled:           &hns,
+		IsSftpEnabled:          &sftp,
+		IsNfsV3Enabled:         &nfsV3,
+		EnableHTTPSTrafficOnly: httpsOnly,
+	}
+	return sa
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestStorageAccount_WithCustomerManagedKey(t *testing.T) {
+	identityID := "[resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', 'storage-cmk')]"
+	sa := NewStorageAccount("confidential", "eastus", "StorageV2", "Standard_LRS").
+		WithCustomerManagedKey("https://corp-kv.vault.azure.net", "storage-key", identityID)
+
+	require.NotNil(t, sa.Properties)
+	enc := sa.Properties.Encryption
+	require.NotNil(t, enc)
+	assert.Equal(t, "Microsoft.Keyvault", enc.KeySource)
+	require.NotNil(t, enc.KeyVaultProperties)
+	assert.Equal(t, "https://corp-kv.vault.azure.net", *enc.KeyVaultProperties.KeyVaultURI)
+	assert.Equal(t, "storage-key", *enc.KeyVaultProperties.KeyName)
+	assert.Nil(t, enc.KeyVaultProperties.KeyVersion)
+	assert.True(t, enc.Services.Blob.Enabled)
+	assert.True(t, enc.Services.File.Enabled)
+	require.NotNil(t, enc.Identity)
+	assert.Equal(t, identityID, enc.Identity.UserAssignedIdentity)
+
+	require.NotNil(t, sa.Identity)
+	assert.Equal(t, "UserAssigned", sa.Identity.Type)
+	assert.Contains(t, sa.Identity.UserAssignedIdentities, identityID)
+
+	data, err := json.Marshal(sa)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	encryption := decoded["properties"].(map[string]any)["encryption"].(map[string]any)
+	assert.Equal(t, "Microsoft.Keyvault", encryption["keySource"])
+	assert.Equal(t, map[string]any{
+		"keyname":     "storage-key",
+		"keyvaulturi": "https://corp-kv.vault.azure.net",
+	}, encryption["keyvaultproperties"])
+	assert.Equal(t, map[string]any{"userAssignedIdentity": identityID}, encryption["identity"])
+
+	// Without a user-assigned identity the system-assigned identity reads the key
+	sa = NewStorageAccount("confidential", "eastus", "StorageV2", "Standard_LRS")
+	sa.Identity = &Identity{Type: "UserAssigned", UserAssignedIdentities: map[string]UserAssignedIdentity{"other": {}}}
+	sa.WithCustomerManagedKey("https://corp-kv.vault.azure.net", "storage-key", "")
+	assert.Equal(t, "SystemAssigned,UserAssigned", sa.Identity.Type)
+	assert.Nil(t, sa.Properties.Encryption.Identity)
+}
+
+func TestStorageAccount_WithUserAssignedIdentity(t *testing.T) {
+	first := "[resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', 'first')]"
+	second := "[resourceId('Microsoft.ManagedIdentity/userAssignedIdentities', 'second')]"
+
+	sa := NewStorageAccount("orders", "eastus", "StorageV2", "Standard_LRS").
+		WithUserAssignedIdentity(first).
+		WithUserAssignedIdentity(second)
+	require.NotNil(t, sa.Identity)
+	assert.Equal(t, "UserAssigned", sa.Identity.Type)
+	assert.Equal(t, map[string]UserAssignedIdentity{first: {}, second: {}}, sa.Identity.UserAssignedIdentities)
+	assert.Nil(t, sa.Properties, "an identity alone does not set encryption")
+
+	// A system-assigned identity is kept
+	sa = NewStorageAccount("orders", "eastus", "StorageV2", "Standard_LRS")
+	sa.Identity = &Identity{Type: "SystemAssigned"}
+	sa.WithUserAssignedIdentity(first)
+	assert.Equal(t, "SystemAssigned,UserAssigned", sa.Identity.Type)
+
+	// The identity is emitted at the top level of the resource
+	data, err := json.Marshal(sa)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]any{
+		"type":                   "SystemAssigned,UserAssigned",
+		"userAssignedIdentities": map[string]any{first: map[string]any{}},
+	}, decoded["identity"])
+}
+
+func TestStorageAccount_WithBlobDataProtection(t *testing.T) {
+	sa := NewStorageAccount("orders", "eastus", "StorageV2", "Standard_GRS").WithBlobDataProtection(14)
+
+	require.NotNil(t, sa.Properties.BlobServices)
+	blob := sa.Properties.BlobServices
+	assert.True(t, blob.IsVersioningEnabled)
+	assert.Equal(t, &DeleteRetentionPolicy{Enabled: true, Days: 14}, blob.DeleteRetentionPolicy)
+	assert.Equal(t, &DeleteRetentionPolicy{Enabled: true, Days: 14}, blob.ContainerDeleteRetentionPolicy)
+
+	// The blob service is a separate resource, not part of the account
+	data, err := json.Marshal(sa)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "deleteRetentionPolicy")
+
+	data, err = json.Marshal(blob)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"deleteRetentionPolicy": {"enabled": true, "days": 14},
+		"containerDeleteRetentionPolicy": {"enabled": true, "days": 14},
+		"isVersioningEnabled": true
+	}`, string(data))
+}
+
+func TestStorageAccount_WithDeleteLock(t *testing.T) {
+	sa := (&StorageAccount{Name: "logs", Location: "eastus"}).WithDeleteLock()
+
+	require.NotNil(t, sa.Properties)
+	assert.True(t, sa.Properties.DeleteLock)
+
+	// The lock is a separate resource, not part of the account
+	data, err := json.Marshal(sa)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "DeleteLock")
+}
+
+func TestStorageAccount_WithPrivateEndpoint(t *testing.T) {
+	subnetID := "[resourceId('Microsoft.Network/virtualNetworks/subnets', 'vnet', 'pe')]"
+	sa := NewStorageAccount("orders", "eastus", "StorageV2", "Standard_LRS").
+		WithPrivateEndpoint(subnetID, "blob").
+		WithPrivateEndpoint(subnetID, "file")
+
+	require.NotNil(t, sa.Properties.PublicNetworkAccess)
+	assert.Equal(t, "Disabled", *sa.Properties.PublicNetworkAccess)
+	assert.Equal(t, []PrivateEndpointSettings{
+		{SubnetID: subnetID, GroupID: "blob"},
+		{SubnetID: subnetID, GroupID: "file"},
+	}, sa.Properties.PrivateEndpoints)
+
+	// The endpoints are separate resources, not part of the account
+	data, err := json.Marshal(sa)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"publicNetworkAccess":"Disabled"`)
+	assert.NotContains(t, string(data), "PrivateEndpoints")
+	assert.NotContains(t, string(data), subnetID)
+}
@@ -1,6 +1,11 @@
 // Package storage provides Azure storage resource types
 package storage
 
+import (
+	"fmt"
+	"strings"
+)
+
 // StorageAccount represents a Microsoft.Storage/storageAccounts resource
 type StorageAccount struct {
 	// Name is the name of the storage account (3-24 characters, lowercase letters and numbers only)
@@ -60,14 +65,74 @@ type StorageAccountProperties struct {
 	// NetworkRuleSet defines network access rules
 	NetworkRuleSet *NetworkRuleSet `json:"networkAcls,omitempty"`
 
+	// PublicNetworkAccess controls access from public networks (Enabled or Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+
 	// Encryption defines the encryption settings
 	Encryption *Encryption `json:"encryption,omitempty"`
 
 	// IsHnsEnabled indicates whether hierarchical namespace is enabled (for Data Lake Gen2)
 	IsHnsEnabled *bool `json:"isHnsEnabled,omitempty"`
 
+	// IsSftpEnabled enables the SFTP endpoint for blob storage (API version
+	// 2021-08-01 or later); requires IsHnsEnabled
+	IsSftpEnabled *bool `json:"isSftpEnabled,omitempty"`
+
+	// IsNfsV3Enabled enables the NFS 3.0 protocol for blob storage; requires
+	// IsHnsEnabled and EnableHTTPSTrafficOnly set to false, since NFS does
+	// not use HTTPS
+	IsNfsV3Enabled *bool `json:"isNfsV3Enabled,omitempty"`
+
 	// LargeFileSharesState indicates whether large file shares are enabled
 	LargeFileSharesState *string `json:"largeFileSharesState,omitempty"`
+
+	// BlobServices configures data protection for the account's blobs. It is
+	// not part of the account resource; discovery expands it into the
+	// account's default blob service (Microsoft.Storage/storageAccounts/blobServices).
+	BlobServices *BlobServiceProperties `json:"-"`
+
+	// PrivateEndpoints connect the account to subnets. They are not part of
+	// the account resource; discovery expands each into a
+	// Microsoft.Network/privateEndpoints resource.
+	PrivateEndpoints []PrivateEndpointSettings `json:"-"`
+
+	// DeleteLock protects the account from deletion. It is not part of the
+	// account resource; discovery expands it into a CanNotDelete
+	// Microsoft.Authorization/locks resource scoped to the account.
+	DeleteLock bool `json:"-"`
+}
+
+// PrivateEndpointSettings describes a private endpoint for one storage service
+type PrivateEndpointSettings struct {
+	// SubnetID is the resource ID of the subnet the endpoint is placed in
+	SubnetID string
+
+	// GroupID is the storage sub-resource to connect (blob, file, queue, table, web, dfs)
+	GroupID string
+}
+
+// BlobServiceProperties represents the properties of a storage account's blob service
+type BlobServiceProperties struct {
+	// DeleteRetentionPolicy keeps deleted blobs recoverable (blob soft delete)
+	DeleteRetentionPolicy *DeleteRetentionPolicy `json:"deleteRetentionPolicy,omitempty"`
+
+	// ContainerDeleteRetentionPolicy keeps deleted containers recoverable
+	ContainerDeleteRetentionPolicy *DeleteRetentionPolicy `json:"containerDeleteRetentionPolicy,omitempty"`
+
+	// IsVersioningEnabled keeps previous versions of blobs when they are overwritten
+	IsVersioningEnabled bool `json:"isVersioningEnabled,omitempty"`
+
+	// Cors configures cross-origin requests to the blob service
+	Cors *CORSRules `json:"cors,omitempty"`
+}
+
+// DeleteRetentionPolicy represents a soft delete retention policy
+type DeleteRetentionPolicy struct {
+	// Enabled indicates whether soft delete is enabled
+	Enabled bool `json:"enabled"`
+
+	// Days is how long deleted data is retained (1-365)
+	Days int `json:"days,omitempty"`
 }
 
 // NetworkRuleSet represents network access control rules
@@ -116,6 +181,16 @@ type Encryption struct {
 
 	// KeyVaultProperties defines Key Vault properties for customer-managed keys
 	KeyVaultProperties *KeyVaultProperties `json:"keyvaultproperties,omitempty"`
+
+	// Identity is the identity used to access the Key Vault; when unset the
+	// account's system-assigned identity is used
+	Identity *EncryptionIdentity `json:"identity,omitempty"`
+}
+
+// EncryptionIdentity selects the managed identity used for customer-managed keys
+type EncryptionIdentity struct {
+	// UserAssignedIdentity is the resource ID of a user-assigned identity of the account
+	UserAssignedIdentity string `json:"userAssignedIdentity,omitempty"`
 }
 
 // EncryptionServices represents encryption settings for storage services
@@ -186,6 +261,41 @@ func NewStorageAccount(name, location, kind, skuName string) *StorageAccount {
 	}
 }
 
+// ID returns the ARM resourceId expression for the storage account
+func (s *StorageAccount) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Storage/storageAccounts', '%s')]", s.Name)
+}
+
+// DFSEndpoint returns an ARM expression for the account's Data Lake Storage
+// (dfs) endpoint, which requires hierarchical namespace to be enabled
+func (s *StorageAccount) DFSEndpoint() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.Storage/storageAccounts', '%s'), '2021-04-01').primaryEndpoints.dfs]", s.Name)
+}
+
+// PrimaryKey returns an ARM expression for the account's first access key
+func (s *StorageAccount) PrimaryKey() string {
+	return fmt.Sprintf("[listKeys(resourceId('Microsoft.Storage/storageAccounts', '%s'), '2021-04-01').keys[0].value]", s.Name)
+}
+
+// ConnectionString returns an ARM expression for the account's connection
+// string, built from its first access key. It contains a secret, so use it as
+// the value of a securestring output or a secure app setting.
+func (s *StorageAccount) ConnectionString() string {
+	return fmt.Sprintf("[concat('DefaultEndpointsProtocol=https;AccountName=', '%s', ';AccountKey=', "+
+		"listKeys(resourceId('Microsoft.Storage/storageAccounts', '%s'), '2021-04-01').keys[0].value, "+
+		"';EndpointSuffix=', environment().suffixes.storage)]", s.Name, s.Name)
+}
+
+// AccountSAS returns an ARM expression for an account SAS token granting
+// permissions (e.g. "rl") on services (e.g. "b" for blob) and resourceTypes
+// (e.g. "sco") until expiry, an ISO 8601 timestamp
+func (s *StorageAccount) AccountSAS(services, resourceTypes, permissions, expiry string) string {
+	return fmt.Sprintf("[listAccountSas(resourceId('Microsoft.Storage/storageAccounts', '%s'), '2021-04-01', "+
+		"createObject('signedServices', '%s', 'signedResourceTypes', '%s', 'signedPermission', '%s', "+
+		"'signedProtocol', 'https', 'signedExpiry', '%s')).accountSasToken]",
+		s.Name, services, resourceTypes, permissions, expiry)
+}
+
 // WithTags adds tags to the storage account
 func (s *StorageAccount) WithTags(tags map[string]string) *StorageAccount {
 	s.Tags = tags
@@ -209,3 +319,163 @@ func (s *StorageAccount) WithMinTLSVersion(version string) *StorageAccount {
 	s.Properties.MinimumTLSVersion = &version
 	return s
 }
+
+// WithBlobDataProtection enables blob versioning and soft delete of blobs
+// and containers, retaining deleted data for days
+func (s *StorageAccount) WithBlobDataProtection(days int) *StorageAccount {
+	if s.Properties == nil {
+		s.Properties = &StorageAccountProperties{}
+	}
+	s.Properties.BlobServices = &BlobServiceProperties{
+		DeleteRetentionPolicy:          &DeleteRetentionPolicy{Enabled: true, Days: days},
+		ContainerDeleteRetentionPolicy: &DeleteRetentionPolicy{Enabled: true, Days: days},
+		IsVersioningEnabled:            true,
+	}
+	return s
+}
+
+// WithPrivateEndpoint connects the groupID service (e.g. blob or file) of the
+// account to the subnet subnetID through a private endpoint, and disables
+// public network access. Discovery emits the endpoint as a
+// Microsoft.Network/privateEndpoints resource that depends on the account.
+func (s *StorageAccount) WithPrivateEndpoint(subnetID, groupID string) *StorageAccount {
+	if s.Properties == nil {
+		s.Properties = &StorageAccountProperties{}
+	}
+	disabled := "Disabled"
+	s.Properties.PublicNetworkAccess = &disabled
+	s.Properties.PrivateEndpoints = append(s.Properties.PrivateEndpoints, PrivateEndpointSettings{
+		SubnetID: subnetID,
+		GroupID:  groupID,
+	})
+	return s
+}
+
+// WithDeleteLock protects the account from deletion, such as by a
+// mistaken teardown of its resource group. Discovery emits the lock as a
+// CanNotDelete Microsoft.Authorization/locks resource on the account; the
+// account can still be changed, and deleted once the lock is removed.
+func (s *StorageAccount) WithDeleteLock() *StorageAccount {
+	if s.Properties == nil {
+		s.Properties = &StorageAccountProperties{}
+	}
+	s.Properties.DeleteLock = true
+	return s
+}
+
+// WithCustomerManagedKey encrypts blob and file data with the key keyName in
+// the Key Vault at keyVaultURI (key source Microsoft.Keyvault). The account
+// reads the key with the user-assigned identity identityID, which is added to
+// the account's identities, or with a system-assigned identity if identityID
+// is empty. The latest key version is used.
+func (s *StorageAccount) WithCustomerManagedKey(keyVaultURI, keyName, identityID string) *StorageAccount {
+	if s.Properties == nil {
+		s.Properties = &StorageAccountProperties{}
+	}
+	keyType := "Account"
+	s.Properties.Encryption = &Encryption{
+		KeySource: "Microsoft.Keyvault",
+		Services: &EncryptionServices{
+			Blob: &EncryptionService{Enabled: true, KeyType: &keyType},
+			File: &EncryptionService{Enabled: true, KeyType: &keyType},
+		},
+		KeyVaultProperties: &KeyVaultProperties{
+			KeyName:     &keyName,
+			KeyVaultURI: &keyVaultURI,
+		},
+	}
+
+	if identityID == "" {
+		s.addIdentityType("SystemAssigned")
+		return s
+	}
+	s.WithUserAssignedIdentity(identityID)
+	s.Properties.Encryption.Identity = &EncryptionIdentity{UserAssignedIdentity: identityID}
+	return s
+}
+
+// WithUserAssignedIdentity adds the user-assigned identity identityID to the
+// account's identities, keeping a system-assigned identity if the account
+// has one
+func (s *StorageAccount) WithUserAssignedIdentity(identityID string) *StorageAccount {
+	s.addIdentityType("UserAssigned")
+	if s.Identity.UserAssignedIdentities == nil {
+		s.Identity.UserAssignedIdentities = make(map[string]UserAssignedIdentity)
+	}
+	s.Identity.UserAssignedIdentities[identityID] = UserAssignedIdentity{}
+	return s
+}
+
+// addIdentityType enables identityType (SystemAssigned or UserAssigned) on
+// the account, keeping any identity type already enabled
+func (s *StorageAccount) addIdentityType(identityType string) {
+	switch {
+	case s.Identity == nil || s.Identity.Type == "" || s.Identity.Type == "None":
+		if s.Identity == nil {
+			s.Identity = &Identity{}
+		}
+		s.Identity.Type = identityType
+	case !strings.Contains(s.Identity.Type, identityType):
+		s.Identity.Type = "SystemAssigned,UserAssigned"
+	}
+}
+
+// premiumOnlyKinds are the storage account kinds that require a Premium SKU
+var premiumOnlyKinds = map[string]bool{"FileStorage": true, "BlockBlobStorage": true}
+
+// accessTierKinds are the storage account kinds that accept an access tier
+var accessTierKinds = map[string]bool{"StorageV2": true, "BlobStorage": true, "FileStorage": true}
+
+// Validate checks the storage account name rules, that the SKU and access
+// tier are offered for the kind, and that SFTP and NFSv3 have the features
+// they depend on. Names given as ARM expressions, and unset fields, are not
+// checked.
+func (s *StorageAccount) Validate() []error {
+	var errs []error
+
+	if s.Name != "" && !strings.HasPrefix(s.Name, "[") {
+		if len(s.Name) < 3 || len(s.Name) > 24 {
+			errs = append(errs, fmt.Errorf("name %q must be 3-24 characters long", s.Name))
+		}
+		if strings.IndexFunc(s.Name, func(r rune) bool { return (r < 'a' || r > 'z') && (r < '0' || r > '9') }) != -1 {
+			errs = append(errs, fmt.Errorf("name %q may contain only lowercase letters and numbers", s.Name))
+		}
+	}
+
+	if p := s.Properties; p != nil {
+		if p.AccessTier != nil && s.Kind != "" && !strings.HasPrefix(s.Kind, "[") && !accessTierKinds[s.Kind] {
+			errs = append(errs, fmt.Errorf("kind %s does not support an access tier (AccessTier); use StorageV2, BlobStorage or FileStorage", s.Kind))
+		}
+
+		hns := p.IsHnsEnabled != nil && *p.IsHnsEnabled
+		if p.IsSftpEnabled != nil && *p.IsSftpEnabled && !hns {
+			errs = append(errs, fmt.Errorf("SFTP (IsSftpEnabled) requires hierarchical namespace; set IsHnsEnabled"))
+		}
+		if p.IsNfsV3Enabled != nil && *p.IsNfsV3Enabled {
+			if !hns {
+				errs = append(errs, fmt.Errorf("NFSv3 (IsNfsV3Enabled) requires hierarchical namespace; set IsHnsEnabled"))
+			}
+			// HTTPS-only is on by default, so it must be turned off explicitly
+			if p.EnableHTTPSTrafficOnly == nil || *p.EnableHTTPSTrafficOnly {
+				errs = append(errs, fmt.Errorf("NFSv3 (IsNfsV3Enabled) requires HTTPS-only traffic to be off; set EnableHTTPSTrafficOnly to false"))
+			}
+		}
+	}
+
+	sku, kind := s.SKU.Name, s.Kind
+	if sku == "" || kind == "" || strings.HasPrefix(sku, "[") || strings.HasPrefix(kind, "[") {
+		return errs
+	}
+	premium := strings.HasPrefix(sku, "Premium_")
+	switch {
+	case premiumOnlyKinds[kind] && !premium:
+		errs = append(errs, fmt.Errorf("kind %s requires a Premium SKU (Premium_LRS or Premium_ZRS), got %s", kind, sku))
+	case kind == "BlobStorage" && (premium || strings.HasSuffix(sku, "ZRS")):
+		errs = append(errs, fmt.Errorf("kind BlobStorage supports Standard_LRS, Standard_GRS and Standard_RAGRS, got %s", sku))
+	case (kind == "Storage" || kind == "StorageV2") && sku == "Premium_ZRS":
+		errs = append(errs, fmt.Errorf("kind %s does not support Premium_ZRS; use FileStorage or BlockBlobStorage", kind))
+	case kind == "Storage" && strings.HasSuffix(sku, "GZRS"):
+		errs = append(errs, fmt.Errorf("kind Storage does not support %s; use StorageV2", sku))
+	}
+	return errs
+}
@@ -0,0 +1,131 @@
+// Package synapse provides Azure Synapse Analytics resource types
+package synapse
+
+// Workspace represents a Microsoft.Synapse/workspaces resource
+type Workspace struct {
+	// Name is the name of the workspace (globally unique)
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the workspace will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Identity defines the managed identity configuration for the workspace
+	Identity *Identity `json:"identity,omitempty"`
+
+	// Properties contains the properties of the workspace
+	Properties WorkspaceProperties `json:"properties"`
+}
+
+// WorkspaceProperties represents the properties of a Synapse workspace
+type WorkspaceProperties struct {
+	// DefaultDataLakeStorage is the primary Data Lake Storage Gen2 account of the workspace
+	DefaultDataLakeStorage DataLakeStorageAccountDetails `json:"defaultDataLakeStorage"`
+
+	// SQLAdministratorLogin is the login of the SQL administrator
+	SQLAdministratorLogin *string `json:"sqlAdministratorLogin,omitempty"`
+
+	// SQLAdministratorLoginPassword is the password of the SQL administrator
+	SQLAdministratorLoginPassword *string `json:"sqlAdministratorLoginPassword,omitempty"`
+
+	// ManagedVirtualNetwork is set to "default" to deploy the workspace in a managed virtual network
+	ManagedVirtualNetwork *string `json:"managedVirtualNetwork,omitempty"`
+
+	// ManagedResourceGroupName is the name of the resource group for workspace-managed resources
+	ManagedResourceGroupName *string `json:"managedResourceGroupName,omitempty"`
+
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+}
+
+// DataLakeStorageAccountDetails represents a Data Lake Storage Gen2 account used by a workspace
+type DataLakeStorageAccountDetails struct {
+	// AccountURL is the dfs endpoint of the storage account
+	AccountURL string `json:"accountUrl"`
+
+	// Filesystem is the name of the filesystem (container) in the storage account
+	Filesystem string `json:"filesystem"`
+
+	// ResourceID is the ID of the storage account
+	ResourceID string `json:"resourceId,omitempty"`
+
+	// CreateManagedPrivateEndpoint creates a managed private endpoint to the account
+	CreateManagedPrivateEndpoint *bool `json:"createManagedPrivateEndpoint,omitempty"`
+}
+
+// Identity represents the identity configuration
+type Identity struct {
+	// Type is the identity type (SystemAssigned, SystemAssigned,UserAssigned, None)
+	Type string `json:"type"`
+
+	// UserAssignedIdentities contains user-assigned managed identities
+	UserAssignedIdentities map[string]UserAssignedIdentity `json:"userAssignedIdentities,omitempty"`
+}
+
+// UserAssignedIdentity represents a user-assigned managed identity
+type UserAssignedIdentity struct {
+	// ClientID is the client ID of the identity
+	ClientID *string `json:"clientId,omitempty"`
+
+	// PrincipalID is the principal ID of the identity
+	PrincipalID *string `json:"principalId,omitempty"`
+}
+
+// NewWorkspace creates a new workspace with a system-assigned identity, using
+// the filesystem of the Data Lake Storage account at accountURL (for example
+// storage.StorageAccount.DFSEndpoint()) as its default storage
+func NewWorkspace(name, location, accountURL, filesystem string) *Workspace {
+	return &Workspace{
+		Name:       name,
+		Type:       "Microsoft.Synapse/workspaces",
+		APIVersion: "2021-06-01",
+		Location:   location,
+		Identity:   &Identity{Type: "SystemAssigned"},
+		Properties: WorkspaceProperties{
+			DefaultDataLakeStorage: DataLakeStorageAccountDetails{
+				AccountURL: accountURL,
+				Filesystem: filesystem,
+			},
+		},
+	}
+}
+
+// WithTags adds tags to the workspace
+func (w *Workspace) WithTags(tags map[string]string) *Workspace {
+	w.Tags = tags
+	return w
+}
+
+// WithDataLakeResourceID sets the ID of the default Data Lake Storage account
+func (w *Workspace) WithDataLakeResourceID(id string) *Workspace {
+	w.Properties.DefaultDataLakeStorage.ResourceID = id
+	return w
+}
+
+// WithSQLAdministrator sets the SQL administrator login and password
+func (w *Workspace) WithSQLAdministrator(login, password string) *Workspace {
+	w.Properties.SQLAdministratorLogin = &login
+	w.Properties.SQLAdministratorLoginPassword = &password
+	return w
+}
+
+// WithManagedVirtualNetwork deploys the workspace in a managed virtual network
+func (w *Workspace) WithManagedVirtualNetwork() *Workspace {
+	managed := "default"
+	w.Properties.ManagedVirtualNetwork = &managed
+	return w
+}
+
+// WithPublicNetworkAccess sets public network access (Enabled or Disabled)
+func (w *Workspace) WithPublicNetworkAccess(access string) *Workspace {
+	w.Properties.PublicNetworkAccess = &access
+	return w
+}
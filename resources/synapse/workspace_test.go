@@ -0,0 +1,57 @@
+// Package synapse provides Azure Synapse Analytics resource types
+package synapse
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWorkspace(t *testing.T) {
+	ws := NewWorkspace("my-synapse", "eastus", "https://mylake.dfs.core.windows.net", "workspace")
+
+	assert.Equal(t, "my-synapse", ws.Name)
+	assert.Equal(t, "Microsoft.Synapse/workspaces", ws.Type)
+	assert.Equal(t, "2021-06-01", ws.APIVersion)
+	assert.Equal(t, "eastus", ws.Location)
+	require.NotNil(t, ws.Identity)
+	assert.Equal(t, "SystemAssigned", ws.Identity.Type)
+	assert.Equal(t, "https://mylake.dfs.core.windows.net", ws.Properties.DefaultDataLakeStorage.AccountURL)
+	assert.Equal(t, "workspace", ws.Properties.DefaultDataLakeStorage.Filesystem)
+	assert.Nil(t, ws.Properties.ManagedVirtualNetwork)
+}
+
+func TestWorkspace_With(t *testing.T) {
+	ws := NewWorkspace("my-synapse", "eastus", "https://mylake.dfs.core.windows.net", "workspace").
+		WithTags(map[string]string{"env": "prod"}).
+		WithDataLakeResourceID("[resourceId('Microsoft.Storage/storageAccounts', 'mylake')]").
+		WithSQLAdministrator("sqladmin", "[parameters('sqlAdminPassword')]").
+		WithManagedVirtualNetwork().
+		WithPublicNetworkAccess("Disabled")
+
+	assert.Equal(t, "prod", ws.Tags["env"])
+	assert.Equal(t, "[resourceId('Microsoft.Storage/storageAccounts', 'mylake')]", ws.Properties.DefaultDataLakeStorage.ResourceID)
+	assert.Equal(t, "sqladmin", *ws.Properties.SQLAdministratorLogin)
+	assert.Equal(t, "[parameters('sqlAdminPassword')]", *ws.Properties.SQLAdministratorLoginPassword)
+	assert.Equal(t, "default", *ws.Properties.ManagedVirtualNetwork)
+	assert.Equal(t, "Disabled", *ws.Properties.PublicNetworkAccess)
+}
+
+func TestWorkspace_JSON(t *testing.T) {
+	ws := NewWorkspace("my-synapse", "eastus", "https://mylake.dfs.core.windows.net", "workspace")
+
+	data, err := json.Marshal(ws)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"accountUrl": "https://mylake.dfs.core.windows.net",
+		"filesystem": "workspace",
+	}, props["defaultDataLakeStorage"])
+	assert.NotContains(t, props, "sqlAdministratorLogin")
+}
@@ -0,0 +1,156 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Certificate represents a Microsoft.Web/certificates resource: a TLS
+// certificate available to the apps of an App Service plan, either an App
+// Service managed certificate or one imported from Key Vault
+type Certificate struct {
+	// Name is the name of the certificate
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region, which must match the App Service plan
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the certificate
+	Properties CertificateProperties `json:"properties"`
+}
+
+// CertificateProperties represents the properties of a certificate
+type CertificateProperties struct {
+	// ServerFarmID is the resource ID of the App Service plan that can use
+	// the certificate
+	ServerFarmID string `json:"serverFarmId"`
+
+	// CanonicalName is the host name of a managed certificate, which must
+	// already be bound to an app of the plan
+	CanonicalName *string `json:"canonicalName,omitempty"`
+
+	// KeyVaultID is the resource ID of the Key Vault holding the certificate
+	KeyVaultID *string `json:"keyVaultId,omitempty"`
+
+	// KeyVaultSecretName is the name of the certificate's secret in the vault
+	KeyVaultSecretName *string `json:"keyVaultSecretName,omitempty"`
+}
+
+// NewManagedCertificate creates a free App Service managed certificate for
+// hostName, which must be bound to an app of the plan serverFarmID
+func NewManagedCertificate(name, location, serverFarmID, hostName string) *Certificate {
+	return &Certificate{
+		Name:       name,
+		Type:       "Microsoft.Web/certificates",
+		APIVersion: apiVersion,
+		Location:   location,
+		Properties: CertificateProperties{
+			ServerFarmID:  serverFarmID,
+			CanonicalName: &hostName,
+		},
+	}
+}
+
+// NewKeyVaultCertificate creates a certificate imported from the secret
+// secretName of the Key Vault keyVaultID
+func NewKeyVaultCertificate(name, location, serverFarmID, keyVaultID, secretName string) *Certificate {
+	return &Certificate{
+		Name:       name,
+		Type:       "Microsoft.Web/certificates",
+		APIVersion: apiVersion,
+		Location:   location,
+		Properties: CertificateProperties{
+			ServerFarmID:       serverFarmID,
+			KeyVaultID:         &keyVaultID,
+			KeyVaultSecretName: &secretName,
+		},
+	}
+}
+
+// WithTags adds tags to the certificate
+func (c *Certificate) WithTags(tags map[string]string) *Certificate {
+	c.Tags = tags
+	return c
+}
+
+// ID returns the ARM resourceId expression for the certificate
+func (c *Certificate) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Web/certificates', '%s')]", c.Name)
+}
+
+// Thumbprint returns an ARM expression for the thumbprint of the certificate,
+// which host name bindings use to select it
+func (c *Certificate) Thumbprint() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.Web/certificates', '%s'), '%s').thumbprint]", c.Name, apiVersion)
+}
+
+// HostNameBinding represents a Microsoft.Web/sites/hostNameBindings resource:
+// a custom domain of an app, optionally secured with a certificate
+type HostNameBinding struct {
+	// Name is the name of the binding, in the form "<site>/<host name>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Properties contains the properties of the binding
+	Properties HostNameBindingProperties `json:"properties"`
+}
+
+// HostNameBindingProperties represents the properties of a host name binding
+type HostNameBindingProperties struct {
+	// SiteName is the name of the app
+	SiteName string `json:"siteName,omitempty"`
+
+	// HostNameType is the type of host name (Verified, Managed)
+	HostNameType string `json:"hostNameType,omitempty"`
+
+	// CustomHostNameDNSRecordType is the DNS record type pointing the host
+	// name at the app (CName, A)
+	CustomHostNameDNSRecordType string `json:"customHostNameDnsRecordType,omitempty"`
+
+	// SSLState is the TLS binding type (Disabled, SniEnabled, IpBasedEnabled)
+	SSLState string `json:"sslState,omitempty"`
+
+	// Thumbprint is the thumbprint of the certificate used for TLS
+	Thumbprint string `json:"thumbprint,omitempty"`
+}
+
+// NewHostNameBinding binds the custom domain hostName to the app siteName,
+// verified through a CNAME record
+func NewHostNameBinding(siteName, hostName string) *HostNameBinding {
+	return &HostNameBinding{
+		Name:       siteName + "/" + hostName,
+		Type:       "Microsoft.Web/sites/hostNameBindings",
+		APIVersion: apiVersion,
+		Properties: HostNameBindingProperties{
+			SiteName:                    siteName,
+			CustomHostNameDNSRecordType: "CName",
+		},
+	}
+}
+
+// WithCertificate secures the binding with SNI TLS using cert
+func (b *HostNameBinding) WithCertificate(cert *Certificate) *HostNameBinding {
+	b.Properties.SSLState = "SniEnabled"
+	b.Properties.Thumbprint = cert.Thumbprint()
+	return b
+}
+
+// ID returns the ARM resourceId expression for the binding
+func (b *HostNameBinding) ID() string {
+	site, hostName, _ := strings.Cut(b.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Web/sites/hostNameBindings', '%s', '%s')]", site, hostName)
+}
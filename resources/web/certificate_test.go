@@ -0,0 +1,51 @@
+package web
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManagedCertificate(t *testing.T) {
+	planID := "[resourceId('Microsoft.Web/serverfarms', 'app-plan')]"
+	cert := NewManagedCertificate("shop-cert", "eastus", planID, "shop.example.com")
+
+	assert.Equal(t, "Microsoft.Web/certificates", cert.Type)
+	assert.Equal(t, planID, cert.Properties.ServerFarmID)
+	require.NotNil(t, cert.Properties.CanonicalName)
+	assert.Equal(t, "shop.example.com", *cert.Properties.CanonicalName)
+	assert.Nil(t, cert.Properties.KeyVaultID)
+	assert.Equal(t, "[resourceId('Microsoft.Web/certificates', 'shop-cert')]", cert.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.Web/certificates', 'shop-cert'), '2021-01-15').thumbprint]", cert.Thumbprint())
+}
+
+func TestNewKeyVaultCertificate(t *testing.T) {
+	vaultID := "[resourceId('Microsoft.KeyVault/vaults', 'shop-kv')]"
+	cert := NewKeyVaultCertificate("shop-cert", "eastus", "[resourceId('Microsoft.Web/serverfarms', 'app-plan')]", vaultID, "shop-tls")
+
+	data, err := json.Marshal(cert)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, vaultID, props["keyVaultId"])
+	assert.Equal(t, "shop-tls", props["keyVaultSecretName"])
+	assert.NotContains(t, props, "canonicalName")
+}
+
+func TestNewHostNameBinding(t *testing.T) {
+	cert := NewManagedCertificate("shop-cert", "eastus", "[resourceId('Microsoft.Web/serverfarms', 'app-plan')]", "shop.example.com")
+	binding := NewHostNameBinding("shop-web", "shop.example.com").WithCertificate(cert)
+
+	assert.Equal(t, "shop-web/shop.example.com", binding.Name)
+	assert.Equal(t, "Microsoft.Web/sites/hostNameBindings", binding.Type)
+	assert.Equal(t, "shop-web", binding.Properties.SiteName)
+	assert.Equal(t, "CName", binding.Properties.CustomHostNameDNSRecordType)
+	assert.Equal(t, "SniEnabled", binding.Properties.SSLState)
+	assert.Equal(t, cert.Thumbprint(), binding.Properties.Thumbprint)
+	assert.Equal(t, "[resourceId('Microsoft.Web/sites/hostNameBindings', 'shop-web', 'shop.example.com')]", binding.ID())
+}
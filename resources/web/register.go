@@ -0,0 +1,15 @@
+package web
+
+import "github.com/lex00/wetwire-azure-go/template"
+
+// importPath is the import path this package's resource structs are
+// registered under
+const importPath = "github.com/lex00/wetwire-azure-go/resources/web"
+
+// init registers the resource structs of this package for discovery
+func init() {
+	template.RegisterResourceType(importPath, "Site", "Microsoft.Web/sites")
+	template.RegisterResourceType(importPath, "SiteSlot", "Microsoft.Web/sites/slots")
+	template.RegisterResourceType(importPath, "HostNameBinding", "Microsoft.Web/sites/hostNameBindings")
+	template.RegisterResourceType(importPath, "Certificate", "Microsoft.Web/certificates")
+}
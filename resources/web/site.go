@@ -0,0 +1,158 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+)
+
+// apiVersion is the Microsoft.Web API version of the App Service resources
+const apiVersion = "2021-01-15"
+
+// Site represents a Microsoft.Web/sites resource: a web app, API app, or
+// function app hosted on an App Service plan
+type Site struct {
+	// Name is the name of the app, which is also the default host name prefix
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the app will be created
+	Location string `json:"location"`
+
+	// Kind is the kind of app (e.g. "app", "app,linux", "functionapp")
+	Kind string `json:"kind,omitempty"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the app
+	Properties SiteProperties `json:"properties"`
+}
+
+// SiteProperties represents the properties of an app or deployment slot
+type SiteProperties struct {
+	// ServerFarmID is the resource ID of the App Service plan hosting the app
+	ServerFarmID string `json:"serverFarmId,omitempty"`
+
+	// HTTPSOnly redirects HTTP requests to HTTPS
+	HTTPSOnly *bool `json:"httpsOnly,omitempty"`
+
+	// SiteConfig contains the runtime configuration of the app
+	SiteConfig *SiteConfig `json:"siteConfig,omitempty"`
+}
+
+// SiteConfig represents the runtime configuration of an app
+type SiteConfig struct {
+	// AppSettings are the environment variables of the app
+	AppSettings []NameValuePair `json:"appSettings,omitempty"`
+
+	// AlwaysOn keeps the app loaded when idle
+	AlwaysOn *bool `json:"alwaysOn,omitempty"`
+
+	// LinuxFxVersion is the runtime stack of a Linux app (e.g. "NODE|18-lts")
+	LinuxFxVersion *string `json:"linuxFxVersion,omitempty"`
+
+	// MinTLSVersion is the minimum TLS version for requests (e.g. "1.2")
+	MinTLSVersion *string `json:"minTlsVersion,omitempty"`
+
+	// AutoSwapSlotName is the slot a deployment slot swaps into once warmed
+	// up, usually "production"
+	AutoSwapSlotName *string `json:"autoSwapSlotName,omitempty"`
+}
+
+// NameValuePair represents an app setting
+type NameValuePair struct {
+	// Name is the name of the setting
+	Name string `json:"name"`
+
+	// Value is the value of the setting
+	Value string `json:"value"`
+}
+
+// NewSite creates a new HTTPS-only app on the App Service plan serverFarmID
+func NewSite(name, location, serverFarmID string) *Site {
+	httpsOnly := true
+	return &Site{
+		Name:       name,
+		Type:       "Microsoft.Web/sites",
+		APIVersion: apiVersion,
+		Location:   location,
+		Properties: SiteProperties{
+			ServerFarmID: serverFarmID,
+			HTTPSOnly:    &httpsOnly,
+		},
+	}
+}
+
+// WithTags adds tags to the app
+func (s *Site) WithTags(tags map[string]string) *Site {
+	s.Tags = tags
+	return s
+}
+
+// ID returns the ARM resourceId expression for the app
+func (s *Site) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.Web/sites', '%s')]", s.Name)
+}
+
+// SiteSlot represents a Microsoft.Web/sites/slots resource: a deployment slot
+// of an app, for staging releases and swapping them into production
+// (blue/green deployments)
+type SiteSlot struct {
+	// Name is the name of the slot, in the form "<site>/<slot>"
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region of the slot, which must match its app
+	Location string `json:"location"`
+
+	// Kind is the kind of app (e.g. "app", "app,linux", "functionapp")
+	Kind string `json:"kind,omitempty"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties contains the properties of the slot
+	Properties SiteProperties `json:"properties"`
+}
+
+// NewSiteSlot creates a deployment slot of site, on the same App Service plan
+// and with the same kind and HTTPS setting
+func NewSiteSlot(site *Site, slotName string) *SiteSlot {
+	return &SiteSlot{
+		Name:       site.Name + "/" + slotName,
+		Type:       "Microsoft.Web/sites/slots",
+		APIVersion: apiVersion,
+		Location:   site.Location,
+		Kind:       site.Kind,
+		Properties: SiteProperties{
+			ServerFarmID: site.Properties.ServerFarmID,
+			HTTPSOnly:    site.Properties.HTTPSOnly,
+		},
+	}
+}
+
+// WithAutoSwap makes the slot swap into targetSlot (usually "production")
+// after each deployment completes and warms up
+func (s *SiteSlot) WithAutoSwap(targetSlot string) *SiteSlot {
+	if s.Properties.SiteConfig == nil {
+		s.Properties.SiteConfig = &SiteConfig{}
+	}
+	s.Properties.SiteConfig.AutoSwapSlotName = &targetSlot
+	return s
+}
+
+// ID returns the ARM resourceId expression for the slot
+func (s *SiteSlot) ID() string {
+	site, slot, _ := strings.Cut(s.Name, "/")
+	return fmt.Sprintf("[resourceId('Microsoft.Web/sites/slots', '%s', '%s')]", site, slot)
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSite(t *testing.T) {
+	planID := "[resourceId('Microsoft.Web/serverfarms', 'app-plan')]"
+	site := NewSite("shop-web", "eastus", planID)
+
+	assert.Equal(t, "Microsoft.Web/sites", site.Type)
+	assert.Equal(t, "2021-01-15", site.APIVersion)
+	assert.Equal(t, planID, site.Properties.ServerFarmID)
+	require.NotNil(t, site.Properties.HTTPSOnly)
+	assert.True(t, *site.Properties.HTTPSOnly)
+	assert.Equal(t, "[resourceId('Microsoft.Web/sites', 'shop-web')]", site.ID())
+}
+
+func TestNewSiteSlot(t *testing.T) {
+	site := NewSite("shop-web", "eastus", "[resourceId('Microsoft.Web/serverfarms', 'app-plan')]")
+	site.Kind = "app,linux"
+
+	slot := NewSiteSlot(site, "staging").WithAutoSwap("production")
+
+	assert.Equal(t, "shop-web/staging", slot.Name)
+	assert.Equal(t, "Microsoft.Web/sites/slots", slot.Type)
+	assert.Equal(t, "eastus", slot.Location)
+	assert.Equal(t, "app,linux", slot.Kind)
+	assert.Equal(t, site.Properties.ServerFarmID, slot.Properties.ServerFarmID)
+	require.NotNil(t, slot.Properties.SiteConfig)
+	assert.Equal(t, "production", *slot.Properties.SiteConfig.AutoSwapSlotName)
+	assert.Equal(t, "[resourceId('Microsoft.Web/sites/slots', 'shop-web', 'staging')]", slot.ID())
+}
+
+func TestSiteSlot_JSON(t *testing.T) {
+	site := NewSite("shop-web", "eastus", "[resourceId('Microsoft.Web/serverfarms', 'app-plan')]")
+
+	data, err := json.Marshal(NewSiteSlot(site, "staging"))
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, "shop-web/staging", result["name"])
+	assert.NotContains(t, result, "kind")
+	props := result["properties"].(map[string]interface{})
+	assert.Equal(t, true, props["httpsOnly"])
+	assert.NotContains(t, props, "siteConfig")
+}
@@ -0,0 +1,24 @@
+// Package web provides helpers for Azure App Service and Azure Functions
+package web
+
+import "github.com/lex00/wetwire-azure-go/intrinsics"
+
+// storageKeysAPIVersion is the Microsoft.Storage API version used to list
+// storage account keys
+const storageKeysAPIVersion = "2021-04-01"
+
+// FunctionStorageConnectionString returns an ARM expression for the
+// connection string of the storage account accountName, built from its first
+// access key with listKeys. Use it as the value of the AzureWebJobsStorage
+// app setting of a function app. It contains a secret, so do not expose it
+// as a plain output.
+func FunctionStorageConnectionString(accountName string) string {
+	accountID := intrinsics.ResourceId("Microsoft.Storage/storageAccounts", accountName)
+	key := intrinsics.ListKeysProperty(accountID.ARMExpression(), storageKeysAPIVersion, "keys[0].value")
+	return intrinsics.Format(
+		"DefaultEndpointsProtocol=https;AccountName={0};AccountKey={1};EndpointSuffix={2}",
+		accountName,
+		key.ARMExpression(),
+		"[environment().suffixes.storage]",
+	).ARMExpression()
+}
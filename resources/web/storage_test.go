@@ -0,0 +1,16 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionStorageConnectionString(t *testing.T) {
+	want := "[format('DefaultEndpointsProtocol=https;AccountName={0};AccountKey={1};EndpointSuffix={2}', " +
+		"'funcstorage', " +
+		"listKeys(resourceId('Microsoft.Storage/storageAccounts', 'funcstorage'), '2021-04-01').keys[0].value, " +
+		"environment().suffixes.storage)]"
+
+	assert.Equal(t, want, FunctionStorageConnectionString("funcstorage"))
+}
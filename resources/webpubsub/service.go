@@ -0,0 +1,91 @@
+// Package webpubsub provides Azure Web PubSub resource types
+package webpubsub
+
+import "fmt"
+
+// apiVersion is the Microsoft.SignalRService API version of the service
+const apiVersion = "2023-02-01"
+
+// Service represents a Microsoft.SignalRService/webPubSub resource: a managed
+// WebSocket endpoint for publish-subscribe messaging with clients
+type Service struct {
+	// Name is the name of the service, which is also its host name prefix
+	Name string `json:"name"`
+
+	// Type is the resource type
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Location is the Azure region where the service will be created
+	Location string `json:"location"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Kind is the kind of service (WebPubSub, SocketIO)
+	Kind string `json:"kind,omitempty"`
+
+	// SKU defines the pricing tier and number of units of the service
+	SKU SKU `json:"sku"`
+
+	// Properties contains the properties of the service
+	Properties ServiceProperties `json:"properties"`
+}
+
+// SKU represents the SKU of a Web PubSub service
+type SKU struct {
+	// Name is the SKU name (Free_F1, Standard_S1, Premium_P1)
+	Name string `json:"name"`
+
+	// Capacity is the number of units; Free_F1 allows only 1
+	Capacity int `json:"capacity,omitempty"`
+}
+
+// ServiceProperties represents the properties of a Web PubSub service
+type ServiceProperties struct {
+	// PublicNetworkAccess controls access from public networks (Enabled, Disabled)
+	PublicNetworkAccess *string `json:"publicNetworkAccess,omitempty"`
+
+	// DisableLocalAuth disables access key authentication, leaving Microsoft Entra ID
+	DisableLocalAuth *bool `json:"disableLocalAuth,omitempty"`
+}
+
+// NewService creates a Web PubSub service with capacity units of the SKU
+// skuName
+func NewService(name, location, skuName string, capacity int) *Service {
+	return &Service{
+		Name:       name,
+		Type:       "Microsoft.SignalRService/webPubSub",
+		APIVersion: apiVersion,
+		Location:   location,
+		Kind:       "WebPubSub",
+		SKU: SKU{
+			Name:     skuName,
+			Capacity: capacity,
+		},
+	}
+}
+
+// WithTags adds tags to the service
+func (s *Service) WithTags(tags map[string]string) *Service {
+	s.Tags = tags
+	return s
+}
+
+// WithPublicNetworkAccess sets access from public networks (Enabled, Disabled)
+func (s *Service) WithPublicNetworkAccess(access string) *Service {
+	s.Properties.PublicNetworkAccess = &access
+	return s
+}
+
+// ID returns the ARM resourceId expression for the service
+func (s *Service) ID() string {
+	return fmt.Sprintf("[resourceId('Microsoft.SignalRService/webPubSub', '%s')]", s.Name)
+}
+
+// HostName returns an ARM expression for the host name of the service
+func (s *Service) HostName() string {
+	return fmt.Sprintf("[reference(resourceId('Microsoft.SignalRService/webPubSub', '%s'), '%s').hostName]", s.Name, apiVersion)
+}
@@ -0,0 +1,38 @@
+package webpubsub
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewService(t *testing.T) {
+	s := NewService("events-pubsub", "westeurope", "Standard_S1", 1).
+		WithPublicNetworkAccess("Disabled").
+		WithTags(map[string]string{"env": "prod"})
+
+	assert.Equal(t, "events-pubsub", s.Name)
+	assert.Equal(t, "Microsoft.SignalRService/webPubSub", s.Type)
+	assert.Equal(t, "2023-02-01", s.APIVersion)
+	assert.Equal(t, "westeurope", s.Location)
+	assert.Equal(t, "WebPubSub", s.Kind)
+	assert.Equal(t, SKU{Name: "Standard_S1", Capacity: 1}, s.SKU)
+	require.NotNil(t, s.Properties.PublicNetworkAccess)
+	assert.Equal(t, "Disabled", *s.Properties.PublicNetworkAccess)
+	assert.Equal(t, "prod", s.Tags["env"])
+	assert.Equal(t, "[resourceId('Microsoft.SignalRService/webPubSub', 'events-pubsub')]", s.ID())
+	assert.Equal(t, "[reference(resourceId('Microsoft.SignalRService/webPubSub', 'events-pubsub'), '2023-02-01').hostName]", s.HostName())
+}
+
+func TestService_JSON(t *testing.T) {
+	data, err := json.Marshal(NewService("events-pubsub", "westeurope", "Free_F1", 1))
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "Microsoft.SignalRService/webPubSub", result["type"])
+	assert.Equal(t, map[string]interface{}{"name": "Free_F1", "capacity": float64(1)}, result["sku"])
+	assert.Empty(t, result["properties"])
+}
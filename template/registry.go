@@ -0,0 +1,60 @@
+package template
+
+import (
+	"reflect"
+	"sync"
+)
+
+// resourceTypes maps "<import path>.<struct name>" to Azure resource types
+var resourceTypes = struct {
+	sync.RWMutex
+	types map[string]string
+}{types: make(map[string]string)}
+
+// valueTypes maps "<import path>.<struct name>" to the Go types discovery
+// evaluates
+var valueTypes = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+}{types: make(map[string]reflect.Type)}
+
+// RegisterResourceType makes discovery recognize package-level variables of
+// the struct typeName from the package importPath as resources of azureType.
+// Registering the same struct again replaces its resource type. The built-in
+// resource packages register their structs from init; packages declaring
+// custom resource structs do the same. Discovery parses source and never runs
+// it, so the registering package must be linked into the binary that runs
+// discovery.
+func RegisterResourceType(importPath, typeName, azureType string) {
+	resourceTypes.Lock()
+	defer resourceTypes.Unlock()
+	resourceTypes.types[importPath+"."+typeName] = azureType
+}
+
+// LookupResourceType returns the Azure resource type registered for the
+// struct typeName in the package importPath
+func LookupResourceType(importPath, typeName string) (string, bool) {
+	resourceTypes.RLock()
+	defer resourceTypes.RUnlock()
+	azureType, ok := resourceTypes.types[importPath+"."+typeName]
+	return azureType, ok
+}
+
+// RegisterValueType makes discovery evaluate literal declarations of the
+// struct typeName from the package importPath into values of the type of
+// value, a zero value of the struct, so that the template builder can run its
+// Validate method. Register the struct with RegisterResourceType too.
+func RegisterValueType(importPath, typeName string, value any) {
+	valueTypes.Lock()
+	defer valueTypes.Unlock()
+	valueTypes.types[importPath+"."+typeName] = reflect.TypeOf(value)
+}
+
+// LookupValueType returns the Go type registered with RegisterValueType for
+// the struct typeName in the package importPath
+func LookupValueType(importPath, typeName string) (reflect.Type, bool) {
+	valueTypes.RLock()
+	defer valueTypes.RUnlock()
+	t, ok := valueTypes.types[importPath+"."+typeName]
+	return t, ok
+}
@@ -0,0 +1,35 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterResourceType(t *testing.T) {
+	if _, ok := LookupResourceType("example.com/contoso/replaced", "Thing"); ok {
+		t.Fatal("expected an unregistered struct not to be found")
+	}
+
+	RegisterResourceType("example.com/contoso/replaced", "Thing", "Contoso.Things/v1")
+	RegisterResourceType("example.com/contoso/replaced", "Thing", "Contoso.Things/v2")
+
+	azureType, ok := LookupResourceType("example.com/contoso/replaced", "Thing")
+	if !ok || azureType != "Contoso.Things/v2" {
+		t.Errorf("expected the second registration to replace the first, got %q, %v", azureType, ok)
+	}
+}
+
+func TestRegisterValueType(t *testing.T) {
+	type thing struct{ Name string }
+
+	if _, ok := LookupValueType("example.com/contoso/values", "Thing"); ok {
+		t.Fatal("expected an unregistered struct not to be found")
+	}
+
+	RegisterValueType("example.com/contoso/values", "Thing", thing{})
+
+	valueType, ok := LookupValueType("example.com/contoso/values", "Thing")
+	if !ok || valueType != reflect.TypeOf(thing{}) {
+		t.Errorf("expected the registered type, got %v, %v", valueType, ok)
+	}
+}
@@ -0,0 +1,75 @@
+// Package template provides resource declarations that are serialized into
+// the ARM template as written, for resource types that have no dedicated Go
+// type in the resources packages.
+//
+// A RawResource names its ARM type and API version itself and gives its
+// properties as generic maps:
+//
+//	var Bastion = template.RawResource{
+//		Type:       "Microsoft.Network/bastionHosts",
+//		APIVersion: "2023-09-01",
+//		Name:       "corp-bastion",
+//		Location:   "eastus",
+//		Properties: map[string]any{
+//			"scaleUnits": 2,
+//		},
+//	}
+//
+// RegisterTransform adds functions that rewrite every template the builder
+// assembles, such as to apply an organization's tagging policy.
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RawResource is an ARM resource of any type, written to the template
+// verbatim. Discovery reads its type from the Type field, which must be a
+// string literal, and the builder evaluates it only when it is made entirely
+// of literals; otherwise only its name, type and API version are kept.
+type RawResource struct {
+	// Type is the Azure resource type (e.g. "Microsoft.Network/bastionHosts")
+	Type string `json:"type"`
+
+	// APIVersion is the API version to use for this resource
+	APIVersion string `json:"apiVersion"`
+
+	// Name is the name of the resource
+	Name string `json:"name"`
+
+	// Location is the Azure region of the resource; the deployment's
+	// location if empty
+	Location string `json:"location,omitempty"`
+
+	// Kind is the kind of the resource, for types that have kinds
+	Kind string `json:"kind,omitempty"`
+
+	// SKU is the sku object of the resource
+	SKU map[string]any `json:"sku,omitempty"`
+
+	// Identity is the managed identity object of the resource
+	Identity map[string]any `json:"identity,omitempty"`
+
+	// Plan is the marketplace plan object of the resource
+	Plan map[string]any `json:"plan,omitempty"`
+
+	// Zones are the availability zones of the resource
+	Zones []string `json:"zones,omitempty"`
+
+	// Tags are key-value pairs to organize resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Properties are the ARM properties of the resource
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+// ID returns the ARM resourceId expression for the resource. Names of child
+// resources, such as "parent/child", give one resourceId segment per part.
+func (r *RawResource) ID() string {
+	id := fmt.Sprintf("[resourceId('%s'", r.Type)
+	for _, segment := range strings.Split(r.Name, "/") {
+		id += fmt.Sprintf(", '%s'", segment)
+	}
+	return id + ")]"
+}
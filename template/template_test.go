@@ -0,0 +1,25 @@
+package template
+
+import "testing"
+
+func TestRawResource_ID(t *testing.T) {
+	tests := []struct {
+		resource RawResource
+		expected string
+	}{
+		{
+			resource: RawResource{Type: "Microsoft.HealthcareApis/workspaces", Name: "health"},
+			expected: "[resourceId('Microsoft.HealthcareApis/workspaces', 'health')]",
+		},
+		{
+			resource: RawResource{Type: "Microsoft.HealthcareApis/workspaces/fhirservices", Name: "health/fhir"},
+			expected: "[resourceId('Microsoft.HealthcareApis/workspaces/fhirservices', 'health', 'fhir')]",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.resource.ID(); got != tt.expected {
+			t.Errorf("ID() = %q, want %q", got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package template
+
+import "sync"
+
+// Template is an ARM template as the builder assembles it, before it is
+// written out. Transforms registered with RegisterTransform receive and
+// return it. Resources are generic maps of the ARM resource JSON, so
+// transforms can set any field, such as "tags", or append whole resources.
+// Numbers in the template are json.Number values, so they are written back
+// exactly as built.
+type Template struct {
+	Schema         string           `json:"$schema"`
+	ContentVersion string           `json:"contentVersion"`
+	Metadata       map[string]any   `json:"metadata,omitempty"`
+	Parameters     map[string]any   `json:"parameters,omitempty"`
+	Variables      map[string]any   `json:"variables,omitempty"`
+	Resources      []map[string]any `json:"resources"`
+	Outputs        map[string]any   `json:"outputs,omitempty"`
+}
+
+// Transform rewrites an assembled template, such as to inject tags required
+// by an organization's policy or to add a resource lock
+type Transform func(Template) Template
+
+// transforms holds the registered transforms in registration order
+var transforms = struct {
+	sync.RWMutex
+	funcs []Transform
+}{}
+
+// RegisterTransform adds transform to those the builder applies to every
+// template it assembles, after resources are ordered and serialized and
+// before the template is formatted or merged into a base template.
+// Transforms run in registration order, each receiving the result of the
+// previous one, so a package registering from init runs in package
+// initialization order. The registering package must be linked into the
+// binary that runs the build.
+//
+// The template a transform receives is a fresh copy that it may modify in
+// place. A transform should be idempotent, leaving a template it has
+// already transformed unchanged (for example, by checking for a resource it
+// adds before adding it), since the same declarations may be built more than
+// once, as the watch command does.
+func RegisterTransform(transform Transform) {
+	transforms.Lock()
+	defer transforms.Unlock()
+	transforms.funcs = append(transforms.funcs, transform)
+}
+
+// Transforms returns the registered transforms in registration order
+func Transforms() []Transform {
+	transforms.RLock()
+	defer transforms.RUnlock()
+	return append([]Transform(nil), transforms.funcs...)
+}
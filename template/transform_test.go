@@ -0,0 +1,32 @@
+package template
+
+import "testing"
+
+func TestRegisterTransform(t *testing.T) {
+	defer func(saved []Transform) { transforms.funcs = saved }(transforms.funcs)
+	transforms.funcs = nil
+
+	var order []string
+	RegisterTransform(func(t Template) Template {
+		order = append(order, "first")
+		t.ContentVersion = "2.0.0.0"
+		return t
+	})
+	RegisterTransform(func(t Template) Template {
+		order = append(order, "second:"+t.ContentVersion)
+		return t
+	})
+
+	registered := Transforms()
+	if len(registered) != 2 {
+		t.Fatalf("expected 2 transforms, got %d", len(registered))
+	}
+
+	tmpl := Template{ContentVersion: "1.0.0.0"}
+	for _, transform := range registered {
+		tmpl = transform(tmpl)
+	}
+	if len(order) != 2 || order[0] != "first" || order[1] != "second:2.0.0.0" {
+		t.Errorf("expected transforms in registration order, got %v", order)
+	}
+}